@@ -0,0 +1,78 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#include "crypto_utils.h"
+#include <cryptopp/aes.h>
+#include <cryptopp/filters.h>
+#include <cryptopp/hex.h>
+#include <cryptopp/osrng.h>
+#include <cryptopp/sha.h>
+
+std::string HexString(const std::string& s) {
+  std::string value;
+
+  CryptoPP::StringSource ss(
+      s, true /* PumpAll */,
+      new CryptoPP::HexEncoder(new CryptoPP::StringSink(value), false /* uppercase */));
+
+  return value;
+}
+
+std::string RandomBytes(size_t length) {
+  CryptoPP::SecByteBlock data(length);
+  CryptoPP::AutoSeededRandomPool prng;
+
+  prng.GenerateBlock(data, data.size());
+  return std::string(reinterpret_cast<const char*>(data.data()), data.size());
+}
+
+std::string SHA256Hex(const std::string& s) {
+  std::string digest;
+  CryptoPP::SHA256 hash;
+
+  CryptoPP::StringSource ss(
+      s, true /* PumpAll */,
+      new CryptoPP::HashFilter(hash, new CryptoPP::HexEncoder(new CryptoPP::StringSink(digest),
+                                                              false /* uppercase */)));
+
+  return digest;
+}
+
+namespace {
+
+class AESEncryptCipher : public rocksdb::BlockCipher {
+ public:
+  explicit AESEncryptCipher(const std::string& key) {
+    enc_.SetKey(reinterpret_cast<const unsigned char*>(key.data()), key.size());
+  }
+
+  virtual ~AESEncryptCipher() {}
+
+  // Blocksize is the AES block size: always 16 bytes.
+  virtual size_t BlockSize() override { return CryptoPP::AES::BLOCKSIZE; }
+
+  // Encrypt a block of data in place. Length of 'data' is BlockSize().
+  virtual rocksdb::Status Encrypt(char* data) override {
+    enc_.ProcessBlock(reinterpret_cast<unsigned char*>(data));
+    return rocksdb::Status::OK();
+  }
+
+  // Decrypt is not implemented.
+  virtual rocksdb::Status Decrypt(char* data) override {
+    return rocksdb::Status::NotSupported("AES decryption is not implemented");
+  }
+
+ private:
+  CryptoPP::AES::Encryption enc_;
+};
+
+}  // namespace
+
+rocksdb::BlockCipher* NewAESEncryptCipher(const std::string& key) {
+  return new AESEncryptCipher(key);
+}
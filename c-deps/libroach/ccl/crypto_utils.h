@@ -0,0 +1,30 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#ifndef ROACHLIBCCL_CRYPTO_UTILS_H
+#define ROACHLIBCCL_CRYPTO_UTILS_H
+
+#include <string>
+#include <rocksdb/env_encryption.h>
+
+// HexString returns the hex-encoded version of 's'.
+std::string HexString(const std::string& s);
+
+// RandomBytes returns 'length' bytes from a cryptographically secure
+// random number generator.
+std::string RandomBytes(size_t length);
+
+// SHA256Hex returns the hex-encoded SHA-256 digest of 's'.
+std::string SHA256Hex(const std::string& s);
+
+// NewAESEncryptCipher returns a BlockCipher performing AES encryption with
+// 'key'. Only Encrypt is implemented since CTR mode never needs to decrypt
+// blocks. The key must be 16, 24, or 32 bytes long.
+rocksdb::BlockCipher* NewAESEncryptCipher(const std::string& key);
+
+#endif // ROACHLIBCCL_CRYPTO_UTILS_H
@@ -0,0 +1,103 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#include "ctr_stream.h"
+#include <cstring>
+#include "crypto_utils.h"
+
+// kNonceSize is the size of the nonce in bytes. Combined with the 4 byte
+// counter, it makes up the 16 byte AES block.
+static const size_t kNonceSize = 12;
+
+rocksdb::Status CTRCipherStreamCreator::InitSettingsAndCreateCipherStream(
+    std::string* settings, std::unique_ptr<rocksdb::BlockAccessCipherStream>* result) {
+  auto key = key_manager_->CurrentKey();
+  if (key == nullptr || key->info().encryption_type() == enginepbccl::Plaintext) {
+    // No key or plaintext key: the file is written in plaintext.
+    result->reset();
+    return rocksdb::Status::OK();
+  }
+
+  // Create the nonce and initial counter.
+  std::string nonce = RandomBytes(kNonceSize);
+  std::string counter_bytes = RandomBytes(4);
+  uint32_t counter;
+  memcpy(&counter, counter_bytes.data(), sizeof(counter));
+
+  enginepbccl::EncryptionSettings enc_settings;
+  enc_settings.set_encryption_type(key->info().encryption_type());
+  enc_settings.set_key_id(key->info().key_id());
+  enc_settings.set_nonce(nonce);
+  enc_settings.set_counter(counter);
+
+  if (!enc_settings.SerializeToString(settings)) {
+    return rocksdb::Status::InvalidArgument("failed to serialize encryption settings");
+  }
+
+  result->reset(new CTRCipherStream(NewAESEncryptCipher(key->key()), nonce, counter));
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status CTRCipherStreamCreator::CreateCipherStreamFromSettings(
+    const std::string& settings, std::unique_ptr<rocksdb::BlockAccessCipherStream>* result) {
+  enginepbccl::EncryptionSettings enc_settings;
+  if (!enc_settings.ParseFromString(settings)) {
+    return rocksdb::Status::InvalidArgument("failed to parse encryption settings");
+  }
+
+  if (enc_settings.encryption_type() == enginepbccl::Plaintext) {
+    result->reset();
+    return rocksdb::Status::OK();
+  }
+
+  auto key = key_manager_->GetKey(enc_settings.key_id());
+  if (key == nullptr) {
+    return rocksdb::Status::InvalidArgument("key_manager does not have a key with ID " +
+                                            enc_settings.key_id());
+  }
+  if (enc_settings.nonce().size() != kNonceSize) {
+    return rocksdb::Status::InvalidArgument("nonce has wrong size, expected " +
+                                            std::to_string(kNonceSize) + " bytes");
+  }
+
+  result->reset(
+      new CTRCipherStream(NewAESEncryptCipher(key->key()), enc_settings.nonce(), enc_settings.counter()));
+  return rocksdb::Status::OK();
+}
+
+void CTRCipherStream::AllocateScratch(std::string& scratch) {
+  auto blockSize = cipher_->BlockSize();
+  scratch.resize(blockSize);
+}
+
+rocksdb::Status CTRCipherStream::EncryptBlock(uint64_t blockIndex, char* data, char* scratch) {
+  // Build the counter block: nonce followed by the big-endian counter.
+  auto blockSize = cipher_->BlockSize();
+  memcpy(scratch, nonce_.data(), kNonceSize);
+  uint32_t block_counter = counter_ + (uint32_t)blockIndex;
+  scratch[kNonceSize] = (char)(block_counter >> 24);
+  scratch[kNonceSize + 1] = (char)(block_counter >> 16);
+  scratch[kNonceSize + 2] = (char)(block_counter >> 8);
+  scratch[kNonceSize + 3] = (char)(block_counter);
+
+  // Encrypt the counter block to get the key stream.
+  rocksdb::Status status = cipher_->Encrypt(scratch);
+  if (!status.ok()) {
+    return status;
+  }
+
+  // XOR the data with the key stream.
+  for (size_t i = 0; i < blockSize; i++) {
+    data[i] = data[i] ^ scratch[i];
+  }
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status CTRCipherStream::DecryptBlock(uint64_t blockIndex, char* data, char* scratch) {
+  return EncryptBlock(blockIndex, data, scratch);
+}
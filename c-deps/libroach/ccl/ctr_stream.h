@@ -0,0 +1,76 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#ifndef ROACHLIBCCL_CTR_STREAM_H
+#define ROACHLIBCCL_CTR_STREAM_H
+
+#include <memory>
+#include <string>
+
+#include <rocksdb/env_encryption.h>
+#include "../encrypted_env.h"
+#include "key_manager.h"
+
+// CTRCipherStreamCreator creates CTR cipher streams using keys from a
+// KeyManager. New files are encrypted with the key manager's current key.
+class CTRCipherStreamCreator : public CipherStreamCreator {
+ public:
+  // The creator takes ownership of 'key_manager'.
+  CTRCipherStreamCreator(KeyManager* key_manager, enginepb::EnvType env_type)
+      : key_manager_(key_manager), env_type_(env_type) {}
+
+  virtual ~CTRCipherStreamCreator() {}
+
+  virtual rocksdb::Status InitSettingsAndCreateCipherStream(
+      std::string* settings, std::unique_ptr<rocksdb::BlockAccessCipherStream>* result) override;
+
+  virtual rocksdb::Status CreateCipherStreamFromSettings(
+      const std::string& settings, std::unique_ptr<rocksdb::BlockAccessCipherStream>* result) override;
+
+  virtual enginepb::EnvType GetEnvType() override { return env_type_; }
+
+ private:
+  std::unique_ptr<KeyManager> key_manager_;
+  enginepb::EnvType env_type_;
+};
+
+// CTRCipherStream implements AES in CTR mode. The counter block for block
+// index 'i' is the 12 byte nonce followed by the big-endian 32 bit value
+// 'counter + i'.
+class CTRCipherStream : public rocksdb::BlockAccessCipherStream {
+ public:
+  // The stream takes ownership of 'cipher'.
+  CTRCipherStream(rocksdb::BlockCipher* cipher, const std::string& nonce, uint32_t counter)
+      : cipher_(cipher), nonce_(nonce), counter_(counter) {}
+
+  virtual ~CTRCipherStream() {}
+
+  // BlockSize returns the size of each block supported by this cipher stream.
+  virtual size_t BlockSize() override { return cipher_->BlockSize(); }
+
+ protected:
+  // AllocateScratch allocates scratch space which is passed to EncryptBlock
+  // and DecryptBlock.
+  virtual void AllocateScratch(std::string&) override;
+
+  // EncryptBlock encrypts a block of data at the given block index.
+  // Length of data is equal to BlockSize().
+  virtual rocksdb::Status EncryptBlock(uint64_t blockIndex, char* data, char* scratch) override;
+
+  // DecryptBlock decrypts a block of data at the given block index.
+  // Length of data is equal to BlockSize(). In CTR mode this is identical
+  // to EncryptBlock.
+  virtual rocksdb::Status DecryptBlock(uint64_t blockIndex, char* data, char* scratch) override;
+
+ private:
+  std::unique_ptr<rocksdb::BlockCipher> cipher_;
+  const std::string nonce_;
+  const uint32_t counter_;
+};
+
+#endif // ROACHLIBCCL_CTR_STREAM_H
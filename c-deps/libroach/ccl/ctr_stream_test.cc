@@ -0,0 +1,143 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+
+#include "ctr_stream.h"
+#include "../testutils.h"
+#include "crypto_utils.h"
+#include "testutils.h"
+
+namespace {
+
+std::string FromHex(const std::string& hex) {
+  std::string result;
+  for (size_t i = 0; i + 1 < hex.size(); i += 2) {
+    result.push_back((char)std::stoi(hex.substr(i, 2), nullptr, 16));
+  }
+  return result;
+}
+
+}  // namespace
+
+// Known answer test using the AES-128 CTR vectors from NIST SP 800-38A, F.5.1.
+TEST(CTRStream, NISTVectors) {
+  const std::string key = FromHex("2b7e151628aed2a6abf7158809cf4f3c");
+  // The initial counter block is f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff: a 12 byte
+  // nonce followed by the 4 byte counter.
+  const std::string nonce = FromHex("f0f1f2f3f4f5f6f7f8f9fafb");
+  const uint32_t counter = 0xfcfdfeff;
+  const std::string plaintext = FromHex(
+      "6bc1bee22e409f96e93d7e117393172a"
+      "ae2d8a571e03ac9c9eb76fac45af8e51"
+      "30c81c46a35ce411e5fbc1191a0a52ef"
+      "f69f2445df4f9b17ad2b417be66c3710");
+  const std::string ciphertext = FromHex(
+      "874d6191b620e3261bef6864990db6ce"
+      "9806f66b7970fdff8617187bb9fffdff"
+      "5ae4df3edbd5d35e5b4f09020db03eab"
+      "1e031dda2fbe03d1792170a0f3009cee");
+
+  CTRCipherStream stream(NewAESEncryptCipher(key), nonce, counter);
+  EXPECT_EQ(16, stream.BlockSize());
+
+  std::string data = plaintext;
+  ASSERT_OK(stream.Encrypt(0, &data[0], data.size()));
+  EXPECT_EQ(HexString(ciphertext), HexString(data));
+
+  ASSERT_OK(stream.Decrypt(0, &data[0], data.size()));
+  EXPECT_EQ(HexString(plaintext), HexString(data));
+
+  // Decrypting a range which doesn't start or end on a block boundary.
+  data = ciphertext.substr(5, 40);
+  ASSERT_OK(stream.Decrypt(5, &data[0], data.size()));
+  EXPECT_EQ(HexString(plaintext.substr(5, 40)), HexString(data));
+}
+
+TEST(CTRStream, RoundTrip) {
+  auto key_manager = new testutils::MemKeyManager();
+  key_manager->AddKey(
+      testutils::MakeAESKey(enginepbccl::AES256_CTR, "key1", RandomBytes(32)));
+  CTRCipherStreamCreator creator(key_manager, enginepb::Data);
+  EXPECT_EQ(enginepb::Data, creator.GetEnvType());
+
+  std::string settings;
+  std::unique_ptr<rocksdb::BlockAccessCipherStream> stream;
+  ASSERT_OK(creator.InitSettingsAndCreateCipherStream(&settings, &stream));
+  ASSERT_TRUE(stream != nullptr);
+
+  enginepbccl::EncryptionSettings enc_settings;
+  ASSERT_TRUE(enc_settings.ParseFromString(settings));
+  EXPECT_EQ(enginepbccl::AES256_CTR, enc_settings.encryption_type());
+  EXPECT_EQ("key1", enc_settings.key_id());
+  EXPECT_EQ(12, enc_settings.nonce().size());
+
+  // Encrypt data which doesn't fill a whole number of blocks.
+  const std::string plaintext = "the quick brown fox jumps over the lazy dog, twice over";
+  std::string data = plaintext;
+  ASSERT_OK(stream->Encrypt(0, &data[0], data.size()));
+  EXPECT_NE(plaintext, data);
+
+  // Rotate the key: new streams use the new key, and the settings of
+  // existing ones still find the old key.
+  key_manager->AddKey(
+      testutils::MakeAESKey(enginepbccl::AES128_CTR, "key2", RandomBytes(16)));
+  std::string new_settings;
+  std::unique_ptr<rocksdb::BlockAccessCipherStream> new_stream;
+  ASSERT_OK(creator.InitSettingsAndCreateCipherStream(&new_settings, &new_stream));
+  ASSERT_TRUE(enc_settings.ParseFromString(new_settings));
+  EXPECT_EQ("key2", enc_settings.key_id());
+
+  std::unique_ptr<rocksdb::BlockAccessCipherStream> read_stream;
+  ASSERT_OK(creator.CreateCipherStreamFromSettings(settings, &read_stream));
+  ASSERT_TRUE(read_stream != nullptr);
+  ASSERT_OK(read_stream->Decrypt(0, &data[0], data.size()));
+  EXPECT_EQ(plaintext, data);
+
+  // Streams with the same key use distinct nonces, so the same data
+  // encrypts differently.
+  std::string other_settings;
+  std::unique_ptr<rocksdb::BlockAccessCipherStream> other_stream;
+  ASSERT_OK(creator.InitSettingsAndCreateCipherStream(&other_settings, &other_stream));
+  std::string data1 = plaintext, data2 = plaintext;
+  ASSERT_OK(new_stream->Encrypt(0, &data1[0], data1.size()));
+  ASSERT_OK(other_stream->Encrypt(0, &data2[0], data2.size()));
+  EXPECT_NE(data1, data2);
+}
+
+TEST(CTRStream, PlaintextAndUnknownKeys) {
+  auto key_manager = new testutils::MemKeyManager();
+  CTRCipherStreamCreator creator(key_manager, enginepb::Data);
+
+  // Without a key, files are written in plaintext.
+  std::string settings;
+  std::unique_ptr<rocksdb::BlockAccessCipherStream> stream;
+  ASSERT_OK(creator.InitSettingsAndCreateCipherStream(&settings, &stream));
+  EXPECT_TRUE(stream == nullptr);
+
+  // Same with a plaintext key.
+  key_manager->AddKey(testutils::MakeAESKey(enginepbccl::Plaintext, kPlainKeyID, ""));
+  ASSERT_OK(creator.InitSettingsAndCreateCipherStream(&settings, &stream));
+  EXPECT_TRUE(stream == nullptr);
+
+  // Settings referring to an unknown key can't be used.
+  enginepbccl::EncryptionSettings enc_settings;
+  enc_settings.set_encryption_type(enginepbccl::AES128_CTR);
+  enc_settings.set_key_id("unknown");
+  enc_settings.set_nonce(RandomBytes(12));
+  ASSERT_TRUE(enc_settings.SerializeToString(&settings));
+  rocksdb::Status status = creator.CreateCipherStreamFromSettings(settings, &stream);
+  EXPECT_TRUE(status.IsInvalidArgument()) << status.ToString();
+
+  // Nor can settings with a bad nonce.
+  key_manager->AddKey(testutils::MakeAESKey(enginepbccl::AES128_CTR, "key1", RandomBytes(16)));
+  enc_settings.set_key_id("key1");
+  enc_settings.set_nonce(RandomBytes(8));
+  ASSERT_TRUE(enc_settings.SerializeToString(&settings));
+  status = creator.CreateCipherStreamFromSettings(settings, &stream);
+  EXPECT_TRUE(status.IsInvalidArgument()) << status.ToString();
+}
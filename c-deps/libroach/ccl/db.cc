@@ -13,9 +13,90 @@
 #include <rocksdb/utilities/write_batch_with_index.h>
 #include <libroachccl.h>
 #include "../db.h"
+#include "../encrypted_env.h"
+#include "../env_manager.h"
+#include "../protosccl/ccl/baseccl/encryption_options.pb.h"
+#include "ctr_stream.h"
+#include "key_manager.h"
 
 const DBStatus kSuccess = { NULL, 0 };
 
+namespace baseccl = cockroach::ccl::baseccl;
+
+// DBOpenHook parses the extra_options field of DBOptions and initializes
+// encryption objects if needed. It overrides the weak OSS implementation.
+rocksdb::Status DBOpenHook(const std::string& db_dir, const DBOptions db_opts,
+                           EnvManager* env_mgr) {
+  DBSlice options = db_opts.extra_options;
+  if (options.len == 0) {
+    return rocksdb::Status::OK();
+  }
+
+  // Encryption of in-memory engines is not supported.
+  if (db_dir == "") {
+    return rocksdb::Status::InvalidArgument("encryption is not supported for in-memory stores");
+  }
+
+  // We have encryption options. Parse them.
+  baseccl::EncryptionOptions opts;
+  if (!opts.ParseFromArray(options.data, options.len)) {
+    return rocksdb::Status::InvalidArgument("failed to parse extra options");
+  }
+
+  if (opts.key_source() != baseccl::KeyFiles) {
+    return rocksdb::Status::InvalidArgument("unknown encryption key source");
+  }
+
+  // Initialize and load the file registry.
+  std::unique_ptr<FileRegistry> file_registry(new FileRegistry(env_mgr->base_env, db_dir));
+  rocksdb::Status status = file_registry->Load();
+  if (!status.ok()) {
+    return status;
+  }
+
+  // Create a file-based key manager for the store keys.
+  FileKeyManager* store_key_manager = new FileKeyManager(
+      env_mgr->base_env, opts.key_files().current_key(), opts.key_files().old_key());
+  std::unique_ptr<CipherStreamCreator> store_stream(
+      new CTRCipherStreamCreator(store_key_manager, enginepb::Store));
+  status = store_key_manager->LoadKeys();
+  if (!status.ok()) {
+    return status;
+  }
+
+  // Create an encrypted env for the data keys registry, which is encrypted
+  // using the store key.
+  rocksdb::Env* store_keyed_env =
+      NewEncryptedEnv(env_mgr->base_env, file_registry.get(), store_stream.release());
+  env_mgr->TakeEnvOwnership(store_keyed_env);
+
+  // Create a data key manager using the store-key encrypted env.
+  DataKeyManager* data_key_manager =
+      new DataKeyManager(store_keyed_env, db_dir, opts.data_key_rotation_period());
+  std::unique_ptr<CipherStreamCreator> data_stream(
+      new CTRCipherStreamCreator(data_key_manager, enginepb::Data));
+  status = data_key_manager->LoadKeys();
+  if (!status.ok()) {
+    return status;
+  }
+
+  // Record the active store key. This generates a new data key if the store
+  // key has changed.
+  status = data_key_manager->SetActiveStoreKeyInfo(store_key_manager->CurrentKeyInfo());
+  if (!status.ok()) {
+    return status;
+  }
+
+  // Everything else is encrypted using the data keys.
+  rocksdb::Env* data_keyed_env =
+      NewEncryptedEnv(env_mgr->base_env, file_registry.get(), data_stream.release());
+  env_mgr->TakeEnvOwnership(data_keyed_env);
+  env_mgr->db_env = data_keyed_env;
+
+  env_mgr->file_registry.swap(file_registry);
+  return rocksdb::Status::OK();
+}
+
 DBStatus DBBatchReprVerify(
   DBSlice repr, DBKey start, DBKey end, int64_t now_nanos, MVCCStatsResult* stats
 ) {
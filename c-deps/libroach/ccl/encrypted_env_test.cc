@@ -0,0 +1,175 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+
+#include "../encrypted_env.h"
+#include "../testutils.h"
+#include "crypto_utils.h"
+#include "ctr_stream.h"
+#include "testutils.h"
+
+namespace {
+
+// ReadFile reads the contents of 'fname' using a sequential file, as well as
+// a random access file, and checks that they match.
+rocksdb::Status ReadFile(rocksdb::Env* env, const std::string& fname, std::string* contents) {
+  rocksdb::Status status = rocksdb::ReadFileToString(env, fname, contents);
+  if (!status.ok()) {
+    return status;
+  }
+
+  std::unique_ptr<rocksdb::RandomAccessFile> file;
+  status = env->NewRandomAccessFile(fname, &file, rocksdb::EnvOptions());
+  if (!status.ok()) {
+    return status;
+  }
+  // Read a range which doesn't start on a block boundary.
+  const size_t offset = 7;
+  if (contents->size() <= offset) {
+    return rocksdb::Status::OK();
+  }
+  std::string scratch(contents->size() - offset, '\0');
+  rocksdb::Slice result;
+  status = file->Read(offset, scratch.size(), &result, &scratch[0]);
+  if (!status.ok()) {
+    return status;
+  }
+  if (result.ToString() != contents->substr(offset)) {
+    return rocksdb::Status::Corruption("random access read mismatch for " + fname);
+  }
+  return rocksdb::Status::OK();
+}
+
+}  // namespace
+
+TEST(EncryptedEnv, ReadWrite) {
+  testutils::TempDirHandler dir;
+  ASSERT_NE("", dir.Path());
+  rocksdb::Env* base_env = rocksdb::Env::Default();
+
+  FileRegistry registry(base_env, dir.Path());
+  ASSERT_OK(registry.Load());
+
+  auto key_manager = new testutils::MemKeyManager();
+  key_manager->AddKey(testutils::MakeAESKey(enginepbccl::AES128_CTR, "key1", RandomBytes(16)));
+  std::unique_ptr<rocksdb::Env> env(NewEncryptedEnv(
+      base_env, &registry, new CTRCipherStreamCreator(key_manager, enginepb::Data)));
+
+  const std::string contents = "some data which spans more than a single sixteen byte block";
+  const std::string fname = dir.Join("foo");
+
+  // Write the file in two steps, the second one through a reopened file.
+  {
+    std::unique_ptr<rocksdb::WritableFile> file;
+    ASSERT_OK(env->NewWritableFile(fname, &file, rocksdb::EnvOptions()));
+    ASSERT_OK(file->Append(contents.substr(0, 21)));
+    ASSERT_OK(file->Close());
+  }
+  {
+    std::unique_ptr<rocksdb::WritableFile> file;
+    ASSERT_OK(env->ReopenWritableFile(fname, &file, rocksdb::EnvOptions()));
+    ASSERT_OK(file->Append(contents.substr(21)));
+    ASSERT_OK(file->Close());
+  }
+
+  // The file is encrypted on disk, and recorded in the registry.
+  std::string raw;
+  ASSERT_OK(rocksdb::ReadFileToString(base_env, fname, &raw));
+  EXPECT_EQ(contents.size(), raw.size());
+  EXPECT_NE(contents, raw);
+  auto entry = registry.GetFileEntry(fname);
+  ASSERT_TRUE(entry != nullptr);
+  EXPECT_EQ(enginepb::Data, entry->env_type());
+
+  std::string read;
+  ASSERT_OK(ReadFile(env.get(), fname, &read));
+  EXPECT_EQ(contents, read);
+
+  // After a key rotation, existing files are still readable, and new files
+  // use the new key.
+  key_manager->AddKey(testutils::MakeAESKey(enginepbccl::AES256_CTR, "key2", RandomBytes(32)));
+  ASSERT_OK(ReadFile(env.get(), fname, &read));
+  EXPECT_EQ(contents, read);
+  const std::string fname2 = dir.Join("bar");
+  ASSERT_OK(rocksdb::WriteStringToFile(env.get(), contents, fname2));
+  ASSERT_OK(ReadFile(env.get(), fname2, &read));
+  EXPECT_EQ(contents, read);
+  enginepbccl::EncryptionSettings settings;
+  ASSERT_TRUE(settings.ParseFromString(registry.GetFileEntry(fname2)->encryption_settings()));
+  EXPECT_EQ("key2", settings.key_id());
+
+  // Renaming and linking keep the files readable.
+  const std::string renamed = dir.Join("foo-renamed");
+  ASSERT_OK(env->RenameFile(fname, renamed));
+  EXPECT_TRUE(registry.GetFileEntry(fname) == nullptr);
+  ASSERT_OK(ReadFile(env.get(), renamed, &read));
+  EXPECT_EQ(contents, read);
+  const std::string linked = dir.Join("foo-linked");
+  ASSERT_OK(env->LinkFile(renamed, linked));
+  ASSERT_OK(ReadFile(env.get(), linked, &read));
+  EXPECT_EQ(contents, read);
+
+  // Deleting a file removes its registry entry.
+  ASSERT_OK(env->DeleteFile(renamed));
+  EXPECT_TRUE(registry.GetFileEntry(renamed) == nullptr);
+  EXPECT_TRUE(registry.GetFileEntry(linked) != nullptr);
+
+  // The registry survives a restart.
+  FileRegistry registry2(base_env, dir.Path());
+  ASSERT_OK(registry2.Load());
+  ASSERT_TRUE(registry2.GetFileEntry(linked) != nullptr);
+  ASSERT_TRUE(registry2.GetFileEntry(fname2) != nullptr);
+  auto key_manager2 = new testutils::MemKeyManager();
+  key_manager2->AddKey(testutils::MakeAESKey(enginepbccl::AES128_CTR, "key1",
+                                             key_manager->GetKey("key1")->key()));
+  key_manager2->AddKey(testutils::MakeAESKey(enginepbccl::AES256_CTR, "key2",
+                                             key_manager->GetKey("key2")->key()));
+  std::unique_ptr<rocksdb::Env> env2(NewEncryptedEnv(
+      base_env, &registry2, new CTRCipherStreamCreator(key_manager2, enginepb::Data)));
+  ASSERT_OK(ReadFile(env2.get(), linked, &read));
+  EXPECT_EQ(contents, read);
+  ASSERT_OK(ReadFile(env2.get(), fname2, &read));
+  EXPECT_EQ(contents, read);
+}
+
+TEST(EncryptedEnv, EnvTypes) {
+  testutils::TempDirHandler dir;
+  ASSERT_NE("", dir.Path());
+  rocksdb::Env* base_env = rocksdb::Env::Default();
+
+  FileRegistry registry(base_env, dir.Path());
+  ASSERT_OK(registry.Load());
+
+  auto store_keys = new testutils::MemKeyManager();
+  store_keys->AddKey(testutils::MakeAESKey(enginepbccl::AES128_CTR, "store", RandomBytes(16)));
+  std::unique_ptr<rocksdb::Env> store_env(NewEncryptedEnv(
+      base_env, &registry, new CTRCipherStreamCreator(store_keys, enginepb::Store)));
+
+  // No data key: files are written in plaintext and not recorded.
+  auto data_keys = new testutils::MemKeyManager();
+  std::unique_ptr<rocksdb::Env> data_env(NewEncryptedEnv(
+      base_env, &registry, new CTRCipherStreamCreator(data_keys, enginepb::Data)));
+
+  const std::string contents = "contents";
+  ASSERT_OK(rocksdb::WriteStringToFile(store_env.get(), contents, dir.Join("store-file")));
+  ASSERT_OK(rocksdb::WriteStringToFile(data_env.get(), contents, dir.Join("plain-file")));
+  EXPECT_TRUE(registry.GetFileEntry(dir.Join("plain-file")) == nullptr);
+
+  std::string read;
+  ASSERT_OK(rocksdb::ReadFileToString(base_env, dir.Join("plain-file"), &read));
+  EXPECT_EQ(contents, read);
+  ASSERT_OK(rocksdb::ReadFileToString(data_env.get(), dir.Join("plain-file"), &read));
+  EXPECT_EQ(contents, read);
+
+  // A file can only be read by the env type that wrote it.
+  ASSERT_OK(rocksdb::ReadFileToString(store_env.get(), dir.Join("store-file"), &read));
+  EXPECT_EQ(contents, read);
+  rocksdb::Status status =
+      rocksdb::ReadFileToString(data_env.get(), dir.Join("store-file"), &read);
+  EXPECT_TRUE(status.IsInvalidArgument()) << status.ToString();
+}
@@ -0,0 +1,311 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#include "key_manager.h"
+#include "crypto_utils.h"
+
+namespace {
+
+// KeyLengthForType returns the key length in bytes for the given encryption
+// type, or 0 for plaintext and unknown types.
+size_t KeyLengthForType(enginepbccl::EncryptionType type) {
+  switch (type) {
+    case enginepbccl::AES128_CTR:
+      return 16;
+    case enginepbccl::AES192_CTR:
+      return 24;
+    case enginepbccl::AES256_CTR:
+      return 32;
+    default:
+      return 0;
+  }
+}
+
+// KeyFromFile loads a raw key from 'path' using 'env'. The special path
+// "plain" returns a plaintext key.
+rocksdb::Status KeyFromFile(rocksdb::Env* env, const std::string& path,
+                            std::unique_ptr<enginepbccl::SecretKey>* result) {
+  std::unique_ptr<enginepbccl::SecretKey> key(new enginepbccl::SecretKey());
+  enginepbccl::KeyInfo* info = key->mutable_info();
+
+  if (path == kPlainKeyID) {
+    info->set_encryption_type(enginepbccl::Plaintext);
+    info->set_key_id(kPlainKeyID);
+    info->set_source(kPlainKeyID);
+    result->swap(key);
+    return rocksdb::Status::OK();
+  }
+
+  std::string contents;
+  rocksdb::Status status = rocksdb::ReadFileToString(env, path, &contents);
+  if (!status.ok()) {
+    return status;
+  }
+
+  switch (contents.size()) {
+    case 16:
+      info->set_encryption_type(enginepbccl::AES128_CTR);
+      break;
+    case 24:
+      info->set_encryption_type(enginepbccl::AES192_CTR);
+      break;
+    case 32:
+      info->set_encryption_type(enginepbccl::AES256_CTR);
+      break;
+    default:
+      return rocksdb::Status::InvalidArgument(
+          "key file " + path + " has " + std::to_string(contents.size()) +
+          " bytes, expected 16, 24, or 32 bytes for AES-128, AES-192, or AES-256");
+  }
+
+  uint64_t mod_time;
+  status = env->GetFileModificationTime(path, &mod_time);
+  if (!status.ok()) {
+    return status;
+  }
+
+  info->set_key_id(SHA256Hex(contents));
+  info->set_creation_time(mod_time);
+  info->set_source(path);
+  key->set_key(contents);
+
+  result->swap(key);
+  return rocksdb::Status::OK();
+}
+
+}  // namespace
+
+rocksdb::Status FileKeyManager::LoadKeys() {
+  rocksdb::Status status = KeyFromFile(env_, active_key_path_, &active_key_);
+  if (!status.ok()) {
+    return status;
+  }
+  if (old_key_path_ == "") {
+    return rocksdb::Status::OK();
+  }
+  return KeyFromFile(env_, old_key_path_, &old_key_);
+}
+
+std::unique_ptr<enginepbccl::KeyInfo> FileKeyManager::CurrentKeyInfo() {
+  if (active_key_ == nullptr) {
+    return nullptr;
+  }
+  return std::unique_ptr<enginepbccl::KeyInfo>(new enginepbccl::KeyInfo(active_key_->info()));
+}
+
+std::unique_ptr<enginepbccl::SecretKey> FileKeyManager::CurrentKey() {
+  if (active_key_ == nullptr) {
+    return nullptr;
+  }
+  return std::unique_ptr<enginepbccl::SecretKey>(new enginepbccl::SecretKey(*active_key_));
+}
+
+std::unique_ptr<enginepbccl::SecretKey> FileKeyManager::GetKey(const std::string& id) {
+  if (active_key_ != nullptr && active_key_->info().key_id() == id) {
+    return std::unique_ptr<enginepbccl::SecretKey>(new enginepbccl::SecretKey(*active_key_));
+  }
+  if (old_key_ != nullptr && old_key_->info().key_id() == id) {
+    return std::unique_ptr<enginepbccl::SecretKey>(new enginepbccl::SecretKey(*old_key_));
+  }
+  return nullptr;
+}
+
+rocksdb::Status DataKeyManager::LoadKeys() {
+  std::unique_lock<std::mutex> l(mu_);
+
+  rocksdb::Status status = env_->FileExists(registry_path_);
+  if (status.IsNotFound()) {
+    return rocksdb::Status::OK();
+  } else if (!status.ok()) {
+    return status;
+  }
+
+  std::string contents;
+  status = rocksdb::ReadFileToString(env_, registry_path_, &contents);
+  if (!status.ok()) {
+    return status;
+  }
+
+  if (!registry_.ParseFromString(contents)) {
+    return rocksdb::Status::InvalidArgument("failed to parse data keys registry");
+  }
+
+  current_key_.reset();
+  if (registry_.active_data_key_id() == "") {
+    return rocksdb::Status::OK();
+  }
+  for (int i = 0; i < registry_.data_keys_size(); i++) {
+    if (registry_.data_keys(i).info().key_id() == registry_.active_data_key_id()) {
+      current_key_.reset(new enginepbccl::SecretKey(registry_.data_keys(i)));
+      return rocksdb::Status::OK();
+    }
+  }
+  return rocksdb::Status::InvalidArgument("active data key " + registry_.active_data_key_id() +
+                                          " not found in data keys registry");
+}
+
+rocksdb::Status DataKeyManager::SetActiveStoreKeyInfo(
+    std::unique_ptr<enginepbccl::KeyInfo> store_info) {
+  std::unique_lock<std::mutex> l(mu_);
+
+  if (registry_.active_store_key_id() == store_info->key_id()) {
+    // The store key has not changed. Rotate the data key if needed.
+    return MaybeRotateKeyLocked();
+  }
+
+  // Make sure we are not reusing an old store key: its data keys would be
+  // readable using a key the operator considers retired.
+  for (int i = 0; i < registry_.store_keys_size(); i++) {
+    if (registry_.store_keys(i).key_id() == store_info->key_id() &&
+        store_info->key_id() != kPlainKeyID) {
+      return rocksdb::Status::InvalidArgument("new active store key ID " + store_info->key_id() +
+                                              " already exists as an inactive key");
+    }
+  }
+
+  if (store_info->encryption_type() == enginepbccl::Plaintext) {
+    // Switching to plaintext exposes all existing data keys.
+    for (int i = 0; i < registry_.data_keys_size(); i++) {
+      registry_.mutable_data_keys(i)->mutable_info()->set_was_exposed(true);
+    }
+  }
+
+  bool found = false;
+  for (int i = 0; i < registry_.store_keys_size(); i++) {
+    if (registry_.store_keys(i).key_id() == store_info->key_id()) {
+      found = true;
+      break;
+    }
+  }
+  if (!found) {
+    *registry_.add_store_keys() = *store_info;
+  }
+  registry_.set_active_store_key_id(store_info->key_id());
+
+  return RotateDataKeyLocked();
+}
+
+std::unique_ptr<enginepbccl::KeyInfo> DataKeyManager::GetActiveStoreKeyInfo() {
+  std::unique_lock<std::mutex> l(mu_);
+  for (int i = 0; i < registry_.store_keys_size(); i++) {
+    if (registry_.store_keys(i).key_id() == registry_.active_store_key_id()) {
+      return std::unique_ptr<enginepbccl::KeyInfo>(
+          new enginepbccl::KeyInfo(registry_.store_keys(i)));
+    }
+  }
+  return nullptr;
+}
+
+std::unique_ptr<enginepbccl::KeyInfo> DataKeyManager::CurrentKeyInfo() {
+  std::unique_lock<std::mutex> l(mu_);
+  if (current_key_ == nullptr) {
+    return nullptr;
+  }
+  return std::unique_ptr<enginepbccl::KeyInfo>(new enginepbccl::KeyInfo(current_key_->info()));
+}
+
+std::unique_ptr<enginepbccl::SecretKey> DataKeyManager::CurrentKey() {
+  std::unique_lock<std::mutex> l(mu_);
+  rocksdb::Status status = MaybeRotateKeyLocked();
+  if (!status.ok() || current_key_ == nullptr) {
+    return nullptr;
+  }
+  return std::unique_ptr<enginepbccl::SecretKey>(new enginepbccl::SecretKey(*current_key_));
+}
+
+std::unique_ptr<enginepbccl::SecretKey> DataKeyManager::GetKey(const std::string& id) {
+  std::unique_lock<std::mutex> l(mu_);
+  for (int i = 0; i < registry_.data_keys_size(); i++) {
+    if (registry_.data_keys(i).info().key_id() == id) {
+      return std::unique_ptr<enginepbccl::SecretKey>(
+          new enginepbccl::SecretKey(registry_.data_keys(i)));
+    }
+  }
+  return nullptr;
+}
+
+rocksdb::Status DataKeyManager::MaybeRotateKeyLocked() {
+  if (current_key_ == nullptr) {
+    // No store key has been set yet.
+    return rocksdb::Status::OK();
+  }
+  if (current_key_->info().encryption_type() == enginepbccl::Plaintext) {
+    // Plaintext keys are never rotated.
+    return rocksdb::Status::OK();
+  }
+  int64_t now = env_->NowMicros() / 1000000;
+  if (now - current_key_->info().creation_time() < rotation_period_) {
+    return rocksdb::Status::OK();
+  }
+  return RotateDataKeyLocked();
+}
+
+rocksdb::Status DataKeyManager::RotateDataKeyLocked() {
+  const enginepbccl::KeyInfo* store_info = nullptr;
+  for (int i = 0; i < registry_.store_keys_size(); i++) {
+    if (registry_.store_keys(i).key_id() == registry_.active_store_key_id()) {
+      store_info = &registry_.store_keys(i);
+      break;
+    }
+  }
+  if (store_info == nullptr) {
+    return rocksdb::Status::InvalidArgument("active store key " + registry_.active_store_key_id() +
+                                            " not found in data keys registry");
+  }
+
+  // Data keys use the same cipher as the store key. A plaintext store key
+  // means plaintext data keys.
+  std::unique_ptr<enginepbccl::SecretKey> key(new enginepbccl::SecretKey());
+  enginepbccl::KeyInfo* info = key->mutable_info();
+  info->set_encryption_type(store_info->encryption_type());
+  info->set_creation_time(env_->NowMicros() / 1000000);
+  info->set_source("data key manager");
+  info->set_parent_key_id(store_info->key_id());
+
+  if (store_info->encryption_type() == enginepbccl::Plaintext) {
+    info->set_key_id(kPlainKeyID);
+    info->set_was_exposed(true);
+  } else {
+    info->set_key_id(HexString(RandomBytes(32)));
+    key->set_key(RandomBytes(KeyLengthForType(store_info->encryption_type())));
+  }
+
+  bool found = false;
+  for (int i = 0; i < registry_.data_keys_size(); i++) {
+    if (registry_.data_keys(i).info().key_id() == info->key_id()) {
+      found = true;
+      break;
+    }
+  }
+  if (!found) {
+    *registry_.add_data_keys() = *key;
+  }
+  registry_.set_active_data_key_id(info->key_id());
+
+  rocksdb::Status status = PersistRegistryLocked();
+  if (!status.ok()) {
+    return status;
+  }
+  current_key_.swap(key);
+  return status;
+}
+
+rocksdb::Status DataKeyManager::PersistRegistryLocked() {
+  std::string contents;
+  if (!registry_.SerializeToString(&contents)) {
+    return rocksdb::Status::InvalidArgument("failed to serialize data keys registry");
+  }
+
+  std::string tmpFilename = registry_path_ + ".tmp";
+  rocksdb::Status status =
+      rocksdb::WriteStringToFile(env_, contents, tmpFilename, true /* should_sync */);
+  if (!status.ok()) {
+    return status;
+  }
+  return env_->RenameFile(tmpFilename, registry_path_);
+}
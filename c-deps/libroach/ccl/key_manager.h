@@ -0,0 +1,130 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#ifndef ROACHLIBCCL_KEY_MANAGER_H
+#define ROACHLIBCCL_KEY_MANAGER_H
+
+#include <memory>
+#include <mutex>
+#include <string>
+#include <unordered_map>
+
+#include <rocksdb/env.h>
+#include <rocksdb/status.h>
+#include "../protosccl/ccl/storageccl/engineccl/enginepbccl/key_registry.pb.h"
+
+namespace enginepbccl = cockroach::ccl::storageccl::engineccl::enginepbccl;
+
+// kPlainKeyID is the key id used for the plaintext store key.
+static const std::string kPlainKeyID = "plain";
+
+// kDataKeysRegistryFilename is the name of the data keys registry, stored at
+// the root of the rocksdb directory and written using the store key.
+static const std::string kDataKeysRegistryFilename = "COCKROACHDB_DATA_KEYS";
+
+// KeyManager is the interface used by cipher stream creators to obtain keys.
+class KeyManager {
+ public:
+  virtual ~KeyManager() {}
+
+  // CurrentKeyInfo returns information about the active key, or nullptr if
+  // there is none. Intended for status reporting: it does not rotate keys.
+  virtual std::unique_ptr<enginepbccl::KeyInfo> CurrentKeyInfo() = 0;
+
+  // CurrentKey returns the active key. Used to encrypt new files. It returns
+  // nullptr if no key is available.
+  virtual std::unique_ptr<enginepbccl::SecretKey> CurrentKey() = 0;
+
+  // GetKey returns the key with the given id, or nullptr if it is unknown.
+  // Used to read existing files.
+  virtual std::unique_ptr<enginepbccl::SecretKey> GetKey(const std::string& id) = 0;
+};
+
+// FileKeyManager loads raw keys from files. The current and old key files
+// contain only the raw key bytes: 16, 24, or 32 bytes for AES-128, AES-192,
+// and AES-256 respectively. A path of "plain" means no encryption.
+// Keys are loaded once and never rotated.
+class FileKeyManager : public KeyManager {
+ public:
+  // 'env' is used to read the key files. It should be the plaintext Env.
+  FileKeyManager(rocksdb::Env* env, const std::string& active_key_path,
+                 const std::string& old_key_path)
+      : env_(env), active_key_path_(active_key_path), old_key_path_(old_key_path) {}
+
+  virtual ~FileKeyManager() {}
+
+  // LoadKeys reads the key files. It must be called before any other method.
+  rocksdb::Status LoadKeys();
+
+  virtual std::unique_ptr<enginepbccl::KeyInfo> CurrentKeyInfo() override;
+  virtual std::unique_ptr<enginepbccl::SecretKey> CurrentKey() override;
+  virtual std::unique_ptr<enginepbccl::SecretKey> GetKey(const std::string& id) override;
+
+ private:
+  rocksdb::Env* env_;
+  const std::string active_key_path_;
+  const std::string old_key_path_;
+
+  std::unique_ptr<enginepbccl::SecretKey> active_key_;
+  std::unique_ptr<enginepbccl::SecretKey> old_key_;
+};
+
+// DataKeyManager manages the data keys. Data keys are generated randomly and
+// persisted to kDataKeysRegistryFilename, which must be written using an Env
+// encrypted with the active store key. A new data key is generated whenever
+// the active store key changes or the current data key is older than the
+// rotation period.
+class DataKeyManager : public KeyManager {
+ public:
+  // 'env' is used to read and write the data keys registry. It should be the
+  // store-key encrypted Env. 'rotation_period' is in seconds.
+  DataKeyManager(rocksdb::Env* env, const std::string& db_dir, int64_t rotation_period)
+      : env_(env),
+        registry_path_(db_dir + "/" + kDataKeysRegistryFilename),
+        rotation_period_(rotation_period) {}
+
+  virtual ~DataKeyManager() {}
+
+  // LoadKeys reads the data keys registry. A missing registry is not an
+  // error. It must be called before any other method.
+  rocksdb::Status LoadKeys();
+
+  // SetActiveStoreKeyInfo records the active store key. If it differs from
+  // the previous one, a new data key is generated and the registry persisted.
+  rocksdb::Status SetActiveStoreKeyInfo(std::unique_ptr<enginepbccl::KeyInfo> store_info);
+
+  // GetActiveStoreKeyInfo returns information about the active store key.
+  std::unique_ptr<enginepbccl::KeyInfo> GetActiveStoreKeyInfo();
+
+  virtual std::unique_ptr<enginepbccl::KeyInfo> CurrentKeyInfo() override;
+  virtual std::unique_ptr<enginepbccl::SecretKey> CurrentKey() override;
+  virtual std::unique_ptr<enginepbccl::SecretKey> GetKey(const std::string& id) override;
+
+ private:
+  // MaybeRotateKeyLocked generates a new data key if the active one is too
+  // old. mu_ must be held.
+  rocksdb::Status MaybeRotateKeyLocked();
+  // RotateDataKeyLocked generates a new data key based on the active store
+  // key and persists the registry. mu_ must be held.
+  rocksdb::Status RotateDataKeyLocked();
+  // PersistRegistryLocked writes the registry to disk. mu_ must be held.
+  rocksdb::Status PersistRegistryLocked();
+
+  rocksdb::Env* env_;
+  const std::string registry_path_;
+  const int64_t rotation_period_;
+
+  std::mutex mu_;
+  // The registry is the source of truth for all keys. Protected by mu_.
+  enginepbccl::DataKeysRegistry registry_;
+  // current_key_ is a copy of the active data key, or nullptr if there is
+  // none. Protected by mu_.
+  std::unique_ptr<enginepbccl::SecretKey> current_key_;
+};
+
+#endif // ROACHLIBCCL_KEY_MANAGER_H
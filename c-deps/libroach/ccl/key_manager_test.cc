@@ -0,0 +1,196 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+
+#include "key_manager.h"
+#include <vector>
+#include "../testutils.h"
+#include "crypto_utils.h"
+
+TEST(FileKeyManager, LoadKeys) {
+  testutils::TempDirHandler dir;
+  ASSERT_NE("", dir.Path());
+  rocksdb::Env* env = rocksdb::Env::Default();
+
+  struct KeyFile {
+    std::string name;
+    std::string contents;
+  };
+  std::vector<KeyFile> key_files = {
+      {"16.key", std::string(16, 'a')},
+      {"24.key", std::string(24, 'b')},
+      {"32.key", std::string(32, 'c')},
+      {"bad.key", std::string(20, 'd')},
+  };
+  for (auto& f : key_files) {
+    ASSERT_OK(rocksdb::WriteStringToFile(env, f.contents, dir.Join(f.name)));
+  }
+
+  struct TestCase {
+    std::string active;
+    std::string old;
+    std::string error;
+    enginepbccl::EncryptionType active_type;
+    std::string active_contents;
+  };
+  std::vector<TestCase> test_cases = {
+      {"16.key", "", "", enginepbccl::AES128_CTR, std::string(16, 'a')},
+      {"24.key", "16.key", "", enginepbccl::AES192_CTR, std::string(24, 'b')},
+      {"32.key", "plain", "", enginepbccl::AES256_CTR, std::string(32, 'c')},
+      {"plain", "32.key", "", enginepbccl::Plaintext, ""},
+      {"bad.key", "", "has 20 bytes", enginepbccl::Plaintext, ""},
+      {"16.key", "bad.key", "has 20 bytes", enginepbccl::Plaintext, ""},
+      {"missing.key", "", "No such file", enginepbccl::Plaintext, ""},
+  };
+  for (auto& t : test_cases) {
+    std::string active = t.active == kPlainKeyID ? t.active : dir.Join(t.active);
+    std::string old = t.old == "" || t.old == kPlainKeyID ? t.old : dir.Join(t.old);
+    FileKeyManager km(env, active, old);
+    rocksdb::Status status = km.LoadKeys();
+    if (t.error != "") {
+      EXPECT_FALSE(status.ok()) << t.active << " " << t.old;
+      EXPECT_NE(std::string::npos, status.ToString().find(t.error)) << status.ToString();
+      continue;
+    }
+    ASSERT_OK(status);
+
+    auto info = km.CurrentKeyInfo();
+    ASSERT_TRUE(info != nullptr);
+    EXPECT_EQ(t.active_type, info->encryption_type());
+    auto key = km.CurrentKey();
+    ASSERT_TRUE(key != nullptr);
+    EXPECT_EQ(t.active_contents, key->key());
+    if (t.active == kPlainKeyID) {
+      EXPECT_EQ(kPlainKeyID, info->key_id());
+    } else {
+      // Key IDs are the hash of the key, which doesn't change if the key is
+      // reloaded under another name.
+      EXPECT_EQ(SHA256Hex(t.active_contents), info->key_id());
+      EXPECT_EQ(active, info->source());
+    }
+
+    // Both keys can be looked up by ID.
+    auto same = km.GetKey(info->key_id());
+    ASSERT_TRUE(same != nullptr);
+    EXPECT_EQ(t.active_contents, same->key());
+    if (t.old != "") {
+      FileKeyManager old_km(env, old, "");
+      ASSERT_OK(old_km.LoadKeys());
+      auto old_info = old_km.CurrentKeyInfo();
+      auto old_key = km.GetKey(old_info->key_id());
+      ASSERT_TRUE(old_key != nullptr);
+      EXPECT_EQ(old_info->encryption_type(), old_key->info().encryption_type());
+    }
+    EXPECT_TRUE(km.GetKey("unknown") == nullptr);
+  }
+}
+
+namespace {
+
+std::unique_ptr<enginepbccl::KeyInfo> MakeStoreKeyInfo(enginepbccl::EncryptionType type,
+                                                       const std::string& id) {
+  std::unique_ptr<enginepbccl::KeyInfo> info(new enginepbccl::KeyInfo());
+  info->set_encryption_type(type);
+  info->set_key_id(id);
+  return info;
+}
+
+}  // namespace
+
+TEST(DataKeyManager, StoreKeyRotation) {
+  testutils::TempDirHandler dir;
+  ASSERT_NE("", dir.Path());
+  rocksdb::Env* env = rocksdb::Env::Default();
+
+  DataKeyManager km(env, dir.Path(), 3600 /* rotation_period */);
+  ASSERT_OK(km.LoadKeys());
+  // No store key yet, so no data key.
+  EXPECT_TRUE(km.CurrentKey() == nullptr);
+  EXPECT_TRUE(km.GetActiveStoreKeyInfo() == nullptr);
+
+  ASSERT_OK(km.SetActiveStoreKeyInfo(MakeStoreKeyInfo(enginepbccl::AES128_CTR, "store1")));
+  auto key1 = km.CurrentKey();
+  ASSERT_TRUE(key1 != nullptr);
+  EXPECT_EQ(enginepbccl::AES128_CTR, key1->info().encryption_type());
+  EXPECT_EQ(16, key1->key().size());
+  EXPECT_EQ("store1", key1->info().parent_key_id());
+  EXPECT_FALSE(key1->info().was_exposed());
+  EXPECT_EQ("store1", km.GetActiveStoreKeyInfo()->key_id());
+
+  // Setting the same store key again doesn't generate a new data key.
+  ASSERT_OK(km.SetActiveStoreKeyInfo(MakeStoreKeyInfo(enginepbccl::AES128_CTR, "store1")));
+  EXPECT_EQ(key1->info().key_id(), km.CurrentKey()->info().key_id());
+
+  // A new store key generates a new data key of the same type.
+  ASSERT_OK(km.SetActiveStoreKeyInfo(MakeStoreKeyInfo(enginepbccl::AES256_CTR, "store2")));
+  auto key2 = km.CurrentKey();
+  ASSERT_TRUE(key2 != nullptr);
+  EXPECT_NE(key1->info().key_id(), key2->info().key_id());
+  EXPECT_EQ(enginepbccl::AES256_CTR, key2->info().encryption_type());
+  EXPECT_EQ(32, key2->key().size());
+  EXPECT_EQ("store2", key2->info().parent_key_id());
+  // The old data key is still available to read existing files.
+  auto old = km.GetKey(key1->info().key_id());
+  ASSERT_TRUE(old != nullptr);
+  EXPECT_EQ(key1->key(), old->key());
+
+  // An old store key can't be made active again.
+  rocksdb::Status status =
+      km.SetActiveStoreKeyInfo(MakeStoreKeyInfo(enginepbccl::AES128_CTR, "store1"));
+  EXPECT_TRUE(status.IsInvalidArgument()) << status.ToString();
+
+  // The registry is persisted: a new manager finds the same keys.
+  DataKeyManager km2(env, dir.Path(), 3600 /* rotation_period */);
+  ASSERT_OK(km2.LoadKeys());
+  EXPECT_EQ(key2->info().key_id(), km2.CurrentKeyInfo()->key_id());
+  EXPECT_EQ("store2", km2.GetActiveStoreKeyInfo()->key_id());
+  ASSERT_TRUE(km2.GetKey(key1->info().key_id()) != nullptr);
+  EXPECT_EQ(key2->key(), km2.GetKey(key2->info().key_id())->key());
+
+  // Switching to plaintext marks all data keys as exposed.
+  ASSERT_OK(km2.SetActiveStoreKeyInfo(MakeStoreKeyInfo(enginepbccl::Plaintext, kPlainKeyID)));
+  auto plain = km2.CurrentKey();
+  ASSERT_TRUE(plain != nullptr);
+  EXPECT_EQ(enginepbccl::Plaintext, plain->info().encryption_type());
+  EXPECT_EQ(kPlainKeyID, plain->info().key_id());
+  EXPECT_TRUE(km2.GetKey(key1->info().key_id())->info().was_exposed());
+  EXPECT_TRUE(km2.GetKey(key2->info().key_id())->info().was_exposed());
+}
+
+TEST(DataKeyManager, DataKeyRotation) {
+  testutils::TempDirHandler dir;
+  ASSERT_NE("", dir.Path());
+  rocksdb::Env* env = rocksdb::Env::Default();
+
+  // With a zero rotation period, every use of the current key rotates it.
+  DataKeyManager km(env, dir.Path(), 0 /* rotation_period */);
+  ASSERT_OK(km.LoadKeys());
+  ASSERT_OK(km.SetActiveStoreKeyInfo(MakeStoreKeyInfo(enginepbccl::AES128_CTR, "store1")));
+
+  std::vector<std::string> ids;
+  for (int i = 0; i < 3; i++) {
+    auto key = km.CurrentKey();
+    ASSERT_TRUE(key != nullptr);
+    for (auto& id : ids) {
+      EXPECT_NE(id, key->info().key_id());
+    }
+    ids.push_back(key->info().key_id());
+  }
+
+  // All the rotated keys are kept, and persisted.
+  DataKeyManager km2(env, dir.Path(), 3600 /* rotation_period */);
+  ASSERT_OK(km2.LoadKeys());
+  for (auto& id : ids) {
+    EXPECT_TRUE(km2.GetKey(id) != nullptr) << id;
+  }
+  // Without rotation, the current key is stable.
+  auto current = km2.CurrentKeyInfo();
+  ASSERT_TRUE(current != nullptr);
+  EXPECT_EQ(current->key_id(), km2.CurrentKey()->info().key_id());
+  EXPECT_EQ(current->key_id(), km2.CurrentKey()->info().key_id());
+}
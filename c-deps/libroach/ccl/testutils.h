@@ -0,0 +1,68 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+
+#ifndef ROACHLIBCCL_TESTUTILS_H
+#define ROACHLIBCCL_TESTUTILS_H
+
+#include <memory>
+#include <string>
+#include <vector>
+#include "key_manager.h"
+
+namespace testutils {
+
+// MakeAESKey returns a secret key of the given type and raw key.
+inline enginepbccl::SecretKey MakeAESKey(enginepbccl::EncryptionType type, const std::string& id,
+                                         const std::string& key) {
+  enginepbccl::SecretKey secret;
+  secret.mutable_info()->set_encryption_type(type);
+  secret.mutable_info()->set_key_id(id);
+  secret.set_key(key);
+  return secret;
+}
+
+// MemKeyManager is a KeyManager holding keys in memory. The last key added
+// is the active one.
+class MemKeyManager : public KeyManager {
+ public:
+  MemKeyManager() {}
+  virtual ~MemKeyManager() {}
+
+  void AddKey(const enginepbccl::SecretKey& key) { keys_.push_back(key); }
+
+  virtual std::unique_ptr<enginepbccl::KeyInfo> CurrentKeyInfo() override {
+    if (keys_.empty()) {
+      return nullptr;
+    }
+    return std::unique_ptr<enginepbccl::KeyInfo>(new enginepbccl::KeyInfo(keys_.back().info()));
+  }
+
+  virtual std::unique_ptr<enginepbccl::SecretKey> CurrentKey() override {
+    if (keys_.empty()) {
+      return nullptr;
+    }
+    return std::unique_ptr<enginepbccl::SecretKey>(new enginepbccl::SecretKey(keys_.back()));
+  }
+
+  virtual std::unique_ptr<enginepbccl::SecretKey> GetKey(const std::string& id) override {
+    for (auto& k : keys_) {
+      if (k.info().key_id() == id) {
+        return std::unique_ptr<enginepbccl::SecretKey>(new enginepbccl::SecretKey(k));
+      }
+    }
+    return nullptr;
+  }
+
+ private:
+  std::vector<enginepbccl::SecretKey> keys_;
+};
+
+}  // namespace testutils
+
+#endif  // ROACHLIBCCL_TESTUTILS_H
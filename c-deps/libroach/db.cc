@@ -33,6 +33,7 @@
 #include "protos/storage/engine/enginepb/mvcc.pb.h"
 #include "db.h"
 #include "encoding.h"
+#include "env_manager.h"
 #include "eventlistener.h"
 #include "keys.h"
 
@@ -52,6 +53,17 @@ void __attribute__((weak)) rocksDBLog(char*, int) { die_missing_symbol(__func__)
 char* __attribute__((weak)) prettyPrintKey(DBKey) { die_missing_symbol(__func__); }
 }  // extern "C"
 
+// DBOpenHook in OSS mode only verifies that no extra options are specified.
+// The CCL build replaces it with an implementation that sets up encryption.
+__attribute__((weak)) rocksdb::Status DBOpenHook(const std::string& db_dir, const DBOptions opts,
+                                                 EnvManager* env_mgr) {
+  if (opts.extra_options.len != 0) {
+    return rocksdb::Status::InvalidArgument(
+        "DBOptions has extra_options, but OSS code cannot handle them");
+  }
+  return rocksdb::Status::OK();
+}
+
 #if defined(COMPILER_GCC) || defined(__clang__)
 #define WARN_UNUSED_RESULT __attribute__((warn_unused_result))
 #else
@@ -89,18 +101,18 @@ struct DBEngine {
 };
 
 struct DBImpl : public DBEngine {
-  std::unique_ptr<rocksdb::Env> memenv;
+  std::unique_ptr<EnvManager> env_mgr;
   std::unique_ptr<rocksdb::DB> rep_deleter;
   std::shared_ptr<rocksdb::Cache> block_cache;
   std::shared_ptr<DBEventListener> event_listener;
 
-  // Construct a new DBImpl from the specified DB and Env. Both the DB
-  // and Env will be deleted when the DBImpl is deleted. It is ok to
-  // pass NULL for the Env.
-  DBImpl(rocksdb::DB* r, rocksdb::Env* m, std::shared_ptr<rocksdb::Cache> bc,
+  // Construct a new DBImpl from the specified DB and EnvManager. Both
+  // the DB and the EnvManager will be deleted when the DBImpl is
+  // deleted.
+  DBImpl(rocksdb::DB* r, EnvManager* m, std::shared_ptr<rocksdb::Cache> bc,
     std::shared_ptr<DBEventListener> event_listener)
       : DBEngine(r),
-        memenv(m),
+        env_mgr(m),
         rep_deleter(r),
         block_cache(bc),
         event_listener(event_listener) {
@@ -129,6 +141,7 @@ struct DBImpl : public DBEngine {
 
 struct DBBatch : public DBEngine {
   int updates;
+  bool has_delete_range;
   rocksdb::WriteBatchWithIndex batch;
 
   DBBatch(DBEngine* db);
@@ -1458,6 +1471,7 @@ DBString DBEngine::GetUserProperties() {
 DBBatch::DBBatch(DBEngine* db)
     : DBEngine(db->rep),
       updates(0),
+      has_delete_range(false),
       batch(&kComparator) {
 }
 
@@ -1691,18 +1705,44 @@ DBStatus DBOpen(DBEngine **db, DBSlice dir, DBOptions db_opts) {
   std::shared_ptr<DBEventListener> event_listener(new DBEventListener);
   options.listeners.emplace_back(event_listener);
 
-  std::unique_ptr<rocksdb::Env> memenv;
+  const std::string db_dir = ToString(dir);
+
+  // Setup the env manager. In-memory engines use a memenv, everything
+  // else uses the default Env.
+  std::unique_ptr<EnvManager> env_mgr;
   if (dir.len == 0) {
-    memenv.reset(rocksdb::NewMemEnv(rocksdb::Env::Default()));
-    options.env = memenv.get();
+    rocksdb::Env* memenv = rocksdb::NewMemEnv(rocksdb::Env::Default());
+    env_mgr.reset(new EnvManager(memenv));
+    env_mgr->TakeEnvOwnership(memenv);
+  } else {
+    env_mgr.reset(new EnvManager(rocksdb::Env::Default()));
+  }
+
+  // Give the CCL hook a chance to setup encryption. It may replace
+  // env_mgr->db_env and set env_mgr->file_registry.
+  rocksdb::Status hook_status = DBOpenHook(db_dir, db_opts, env_mgr.get());
+  if (!hook_status.ok()) {
+    return ToDBStatus(hook_status);
   }
 
+  if (env_mgr->file_registry == nullptr && dir.len != 0) {
+    // Encryption is not in use. Make sure it was not used in the past
+    // either, otherwise we would be unable to read the data.
+    FileRegistry registry(env_mgr->base_env, db_dir);
+    rocksdb::Status registry_status = registry.CheckNoRegistryFile();
+    if (!registry_status.ok()) {
+      return ToDBStatus(registry_status);
+    }
+  }
+
+  options.env = env_mgr->db_env;
+
   rocksdb::DB *db_ptr;
-  rocksdb::Status status = rocksdb::DB::Open(options, ToString(dir), &db_ptr);
+  rocksdb::Status status = rocksdb::DB::Open(options, db_dir, &db_ptr);
   if (!status.ok()) {
     return ToDBStatus(status);
   }
-  *db = new DBImpl(db_ptr, memenv.release(),
+  *db = new DBImpl(db_ptr, env_mgr.release(),
       db_opts.cache != nullptr ? db_opts.cache->rep : nullptr,
       event_listener);
   return kSuccess;
@@ -1813,6 +1853,9 @@ DBStatus DBImpl::Get(DBKey key, DBString* value) {
 }
 
 DBStatus DBBatch::Get(DBKey key, DBString* value) {
+  if (has_delete_range) {
+    return FmtStatus("cannot read from a batch containing delete range entries");
+  }
   rocksdb::ReadOptions read_opts;
   DBGetter base(rep, read_opts, EncodeKey(key));
   if (updates == 0) {
@@ -1867,9 +1910,12 @@ DBStatus DBImpl::DeleteRange(DBKey start, DBKey end) {
 
 DBStatus DBBatch::DeleteRange(DBKey start, DBKey end) {
   // TODO(peter): We don't support iteration on a batch containing a
-  // range tombstone, so prohibit such tombstones from behing added to
-  // a readable batch.
-  return FmtStatus("unsupported");
+  // range tombstone, so the batch can no longer be read from once one
+  // has been added (see DBBatch::Get and DBBatch::NewIter).
+  ++updates;
+  has_delete_range = true;
+  batch.GetWriteBatch()->DeleteRange(EncodeKey(start), EncodeKey(end));
+  return kSuccess;
 }
 
 DBStatus DBWriteOnlyBatch::DeleteRange(DBKey start, DBKey end) {
@@ -2023,6 +2069,11 @@ DBIterator* DBImpl::NewIter(rocksdb::ReadOptions* read_opts) {
 }
 
 DBIterator* DBBatch::NewIter(rocksdb::ReadOptions* read_opts) {
+  if (has_delete_range) {
+    // TODO(peter): We don't support iterators when the batch contains
+    // delete range entries.
+    abort();
+  }
   DBIterator* iter = new DBIterator;
   rocksdb::Iterator* base = rep->NewIterator(*read_opts);
   rocksdb::WBWIIterator* delta = batch.NewIterator();
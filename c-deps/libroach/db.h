@@ -17,6 +17,7 @@
 #include <rocksdb/write_batch.h>
 #include <rocksdb/write_batch_base.h>
 #include <libroach.h>
+#include "env_manager.h"
 
 // ToString returns a c++ string with the contents of a DBSlice.
 std::string ToString(DBSlice s);
@@ -26,6 +27,13 @@ std::string ToString(DBSlice s);
 // ordering as these keys do not sort lexicographically correctly.
 std::string EncodeKey(DBKey k);
 
+// DBOpenHook is called by DBOpen before the database is opened. It may
+// set up encryption by modifying the EnvManager. The OSS version
+// errors if any extra options are specified; the CCL build supplies
+// its own implementation.
+rocksdb::Status DBOpenHook(const std::string& db_dir, const DBOptions opts,
+                           EnvManager* env_mgr);
+
 // ToDBStatus converts a rocksdb Status to a DBStatus.
 DBStatus ToDBStatus(const rocksdb::Status& status);
 
@@ -0,0 +1,404 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+#include "encrypted_env.h"
+
+namespace {
+
+// EncryptedSequentialFile decrypts data read from an underlying
+// SequentialFile.
+class EncryptedSequentialFile : public rocksdb::SequentialFile {
+ public:
+  EncryptedSequentialFile(std::unique_ptr<rocksdb::SequentialFile> f,
+                          std::unique_ptr<rocksdb::BlockAccessCipherStream> s)
+      : file_(std::move(f)), stream_(std::move(s)), offset_(0) {}
+
+  virtual rocksdb::Status Read(size_t n, rocksdb::Slice* result, char* scratch) override {
+    rocksdb::Status status = file_->Read(n, result, scratch);
+    if (!status.ok()) {
+      return status;
+    }
+    status = stream_->Decrypt(offset_, (char*)result->data(), result->size());
+    offset_ += result->size();
+    return status;
+  }
+
+  virtual rocksdb::Status Skip(uint64_t n) override {
+    rocksdb::Status status = file_->Skip(n);
+    if (!status.ok()) {
+      return status;
+    }
+    offset_ += n;
+    return status;
+  }
+
+  virtual rocksdb::Status PositionedRead(uint64_t offset, size_t n, rocksdb::Slice* result,
+                                         char* scratch) override {
+    rocksdb::Status status = file_->PositionedRead(offset, n, result, scratch);
+    if (!status.ok()) {
+      return status;
+    }
+    offset_ = offset + result->size();
+    return stream_->Decrypt(offset, (char*)result->data(), result->size());
+  }
+
+  virtual bool use_direct_io() const override { return file_->use_direct_io(); }
+
+  virtual size_t GetRequiredBufferAlignment() const override {
+    return file_->GetRequiredBufferAlignment();
+  }
+
+  virtual rocksdb::Status InvalidateCache(size_t offset, size_t length) override {
+    return file_->InvalidateCache(offset, length);
+  }
+
+ private:
+  std::unique_ptr<rocksdb::SequentialFile> file_;
+  std::unique_ptr<rocksdb::BlockAccessCipherStream> stream_;
+  uint64_t offset_;
+};
+
+// EncryptedRandomAccessFile decrypts data read from an underlying
+// RandomAccessFile.
+class EncryptedRandomAccessFile : public rocksdb::RandomAccessFile {
+ public:
+  EncryptedRandomAccessFile(std::unique_ptr<rocksdb::RandomAccessFile> f,
+                            std::unique_ptr<rocksdb::BlockAccessCipherStream> s)
+      : file_(std::move(f)), stream_(std::move(s)) {}
+
+  virtual rocksdb::Status Read(uint64_t offset, size_t n, rocksdb::Slice* result,
+                               char* scratch) const override {
+    rocksdb::Status status = file_->Read(offset, n, result, scratch);
+    if (!status.ok()) {
+      return status;
+    }
+    return stream_->Decrypt(offset, (char*)result->data(), result->size());
+  }
+
+  virtual rocksdb::Status Prefetch(uint64_t offset, size_t n) override {
+    return file_->Prefetch(offset, n);
+  }
+
+  virtual size_t GetUniqueId(char* id, size_t max_size) const override {
+    return file_->GetUniqueId(id, max_size);
+  }
+
+  virtual void Hint(AccessPattern pattern) override { file_->Hint(pattern); }
+
+  virtual bool use_direct_io() const override { return file_->use_direct_io(); }
+
+  virtual size_t GetRequiredBufferAlignment() const override {
+    return file_->GetRequiredBufferAlignment();
+  }
+
+  virtual rocksdb::Status InvalidateCache(size_t offset, size_t length) override {
+    return file_->InvalidateCache(offset, length);
+  }
+
+ private:
+  std::unique_ptr<rocksdb::RandomAccessFile> file_;
+  std::unique_ptr<rocksdb::BlockAccessCipherStream> stream_;
+};
+
+// EncryptedWritableFile encrypts data before appending it to an underlying
+// WritableFile. 'offset' is the current size of the file.
+class EncryptedWritableFile : public rocksdb::WritableFile {
+ public:
+  EncryptedWritableFile(std::unique_ptr<rocksdb::WritableFile> f,
+                        std::unique_ptr<rocksdb::BlockAccessCipherStream> s, uint64_t offset)
+      : file_(std::move(f)), stream_(std::move(s)), offset_(offset) {}
+
+  virtual rocksdb::Status Append(const rocksdb::Slice& data) override {
+    // The input slice is const, encrypt a copy.
+    std::string buf(data.data(), data.size());
+    rocksdb::Status status = stream_->Encrypt(offset_, &buf[0], buf.size());
+    if (!status.ok()) {
+      return status;
+    }
+    status = file_->Append(rocksdb::Slice(buf));
+    if (!status.ok()) {
+      return status;
+    }
+    offset_ += buf.size();
+    return status;
+  }
+
+  virtual rocksdb::Status PositionedAppend(const rocksdb::Slice& data, uint64_t offset) override {
+    std::string buf(data.data(), data.size());
+    rocksdb::Status status = stream_->Encrypt(offset, &buf[0], buf.size());
+    if (!status.ok()) {
+      return status;
+    }
+    status = file_->PositionedAppend(rocksdb::Slice(buf), offset);
+    if (!status.ok()) {
+      return status;
+    }
+    offset_ = offset + buf.size();
+    return status;
+  }
+
+  virtual rocksdb::Status Truncate(uint64_t size) override { return file_->Truncate(size); }
+  virtual rocksdb::Status Close() override { return file_->Close(); }
+  virtual rocksdb::Status Flush() override { return file_->Flush(); }
+  virtual rocksdb::Status Sync() override { return file_->Sync(); }
+  virtual rocksdb::Status Fsync() override { return file_->Fsync(); }
+  virtual bool IsSyncThreadSafe() const override { return file_->IsSyncThreadSafe(); }
+  virtual bool use_direct_io() const override { return file_->use_direct_io(); }
+
+  virtual size_t GetRequiredBufferAlignment() const override {
+    return file_->GetRequiredBufferAlignment();
+  }
+
+  virtual uint64_t GetFileSize() override { return file_->GetFileSize(); }
+
+  virtual void SetPreallocationBlockSize(size_t size) override {
+    file_->SetPreallocationBlockSize(size);
+  }
+
+  virtual void GetPreallocationStatus(size_t* block_size, size_t* last_allocated_block) override {
+    file_->GetPreallocationStatus(block_size, last_allocated_block);
+  }
+
+  virtual rocksdb::Status InvalidateCache(size_t offset, size_t length) override {
+    return file_->InvalidateCache(offset, length);
+  }
+
+  virtual rocksdb::Status RangeSync(uint64_t offset, uint64_t nbytes) override {
+    return file_->RangeSync(offset, nbytes);
+  }
+
+  virtual void PrepareWrite(size_t offset, size_t len) override {
+    file_->PrepareWrite(offset, len);
+  }
+
+  virtual rocksdb::Status Allocate(uint64_t offset, uint64_t len) override {
+    return file_->Allocate(offset, len);
+  }
+
+ private:
+  std::unique_ptr<rocksdb::WritableFile> file_;
+  std::unique_ptr<rocksdb::BlockAccessCipherStream> stream_;
+  uint64_t offset_;
+};
+
+// EncryptedEnv wraps a base Env, encrypting files using cipher streams from
+// a CipherStreamCreator and recording their settings in a FileRegistry.
+class EncryptedEnv : public rocksdb::EnvWrapper {
+ public:
+  EncryptedEnv(rocksdb::Env* base_env, FileRegistry* file_registry, CipherStreamCreator* creator)
+      : rocksdb::EnvWrapper(base_env), file_registry_(file_registry), stream_creator_(creator) {}
+
+  virtual rocksdb::Status NewSequentialFile(const std::string& fname,
+                                            std::unique_ptr<rocksdb::SequentialFile>* result,
+                                            const rocksdb::EnvOptions& options) override {
+    std::unique_ptr<rocksdb::SequentialFile> underlying;
+    rocksdb::Status status = target()->NewSequentialFile(fname, &underlying, options);
+    if (!status.ok()) {
+      return status;
+    }
+
+    std::unique_ptr<rocksdb::BlockAccessCipherStream> stream;
+    status = OpenCipherStream(fname, &stream);
+    if (!status.ok()) {
+      return status;
+    }
+
+    if (stream == nullptr) {
+      result->swap(underlying);
+    } else {
+      result->reset(new EncryptedSequentialFile(std::move(underlying), std::move(stream)));
+    }
+    return status;
+  }
+
+  virtual rocksdb::Status NewRandomAccessFile(const std::string& fname,
+                                              std::unique_ptr<rocksdb::RandomAccessFile>* result,
+                                              const rocksdb::EnvOptions& options) override {
+    std::unique_ptr<rocksdb::RandomAccessFile> underlying;
+    rocksdb::Status status = target()->NewRandomAccessFile(fname, &underlying, options);
+    if (!status.ok()) {
+      return status;
+    }
+
+    std::unique_ptr<rocksdb::BlockAccessCipherStream> stream;
+    status = OpenCipherStream(fname, &stream);
+    if (!status.ok()) {
+      return status;
+    }
+
+    if (stream == nullptr) {
+      result->swap(underlying);
+    } else {
+      result->reset(new EncryptedRandomAccessFile(std::move(underlying), std::move(stream)));
+    }
+    return status;
+  }
+
+  virtual rocksdb::Status NewWritableFile(const std::string& fname,
+                                          std::unique_ptr<rocksdb::WritableFile>* result,
+                                          const rocksdb::EnvOptions& options) override {
+    std::unique_ptr<rocksdb::WritableFile> underlying;
+    rocksdb::Status status = target()->NewWritableFile(fname, &underlying, options);
+    if (!status.ok()) {
+      return status;
+    }
+
+    std::unique_ptr<rocksdb::BlockAccessCipherStream> stream;
+    status = CreateCipherStream(fname, &stream);
+    if (!status.ok()) {
+      return status;
+    }
+
+    if (stream == nullptr) {
+      result->swap(underlying);
+    } else {
+      result->reset(new EncryptedWritableFile(std::move(underlying), std::move(stream), 0));
+    }
+    return status;
+  }
+
+  virtual rocksdb::Status ReopenWritableFile(const std::string& fname,
+                                             std::unique_ptr<rocksdb::WritableFile>* result,
+                                             const rocksdb::EnvOptions& options) override {
+    // Determine the current size before reopening: encryption of appended
+    // data must continue at the end of the existing file.
+    uint64_t size = 0;
+    rocksdb::Status status = target()->FileExists(fname);
+    bool exists = status.ok();
+    if (exists) {
+      status = target()->GetFileSize(fname, &size);
+      if (!status.ok()) {
+        return status;
+      }
+    } else if (!status.IsNotFound()) {
+      return status;
+    }
+
+    std::unique_ptr<rocksdb::WritableFile> underlying;
+    status = target()->ReopenWritableFile(fname, &underlying, options);
+    if (!status.ok()) {
+      return status;
+    }
+
+    std::unique_ptr<rocksdb::BlockAccessCipherStream> stream;
+    if (exists) {
+      status = OpenCipherStream(fname, &stream);
+    } else {
+      status = CreateCipherStream(fname, &stream);
+    }
+    if (!status.ok()) {
+      return status;
+    }
+
+    if (stream == nullptr) {
+      result->swap(underlying);
+    } else {
+      result->reset(new EncryptedWritableFile(std::move(underlying), std::move(stream), size));
+    }
+    return status;
+  }
+
+  virtual rocksdb::Status ReuseWritableFile(const std::string& fname,
+                                            const std::string& old_fname,
+                                            std::unique_ptr<rocksdb::WritableFile>* result,
+                                            const rocksdb::EnvOptions& options) override {
+    // Reusing a file discards its contents. Rename it through this Env so the
+    // registry is kept in sync, then create it anew with fresh settings.
+    rocksdb::Status status = RenameFile(old_fname, fname);
+    if (!status.ok()) {
+      return status;
+    }
+    return NewWritableFile(fname, result, options);
+  }
+
+  virtual rocksdb::Status NewRandomRWFile(const std::string& fname,
+                                          std::unique_ptr<rocksdb::RandomRWFile>* result,
+                                          const rocksdb::EnvOptions& options) override {
+    return rocksdb::Status::NotSupported("RandomRWFile is not supported by the encrypted env");
+  }
+
+  virtual rocksdb::Status DeleteFile(const std::string& fname) override {
+    rocksdb::Status status = target()->DeleteFile(fname);
+    if (!status.ok()) {
+      return status;
+    }
+    return file_registry_->MaybeDeleteEntry(fname);
+  }
+
+  virtual rocksdb::Status RenameFile(const std::string& src, const std::string& target) override {
+    rocksdb::Status status = this->target()->RenameFile(src, target);
+    if (!status.ok()) {
+      return status;
+    }
+    return file_registry_->MaybeRenameEntry(src, target);
+  }
+
+  virtual rocksdb::Status LinkFile(const std::string& src, const std::string& target) override {
+    rocksdb::Status status = this->target()->LinkFile(src, target);
+    if (!status.ok()) {
+      return status;
+    }
+    return file_registry_->MaybeLinkEntry(src, target);
+  }
+
+ private:
+  // CreateCipherStream initializes new encryption settings for 'fname' and
+  // records them in the file registry. A nullptr stream means the file is
+  // plaintext, in which case any stale registry entry is removed.
+  rocksdb::Status CreateCipherStream(const std::string& fname,
+                                     std::unique_ptr<rocksdb::BlockAccessCipherStream>* result) {
+    std::string settings;
+    rocksdb::Status status = stream_creator_->InitSettingsAndCreateCipherStream(&settings, result);
+    if (!status.ok()) {
+      return status;
+    }
+
+    if (*result == nullptr) {
+      return file_registry_->MaybeDeleteEntry(fname);
+    }
+
+    std::unique_ptr<enginepb::FileEntry> entry(new enginepb::FileEntry());
+    entry->set_env_type(stream_creator_->GetEnvType());
+    entry->set_encryption_settings(settings);
+    return file_registry_->SetFileEntry(fname, std::move(entry));
+  }
+
+  // OpenCipherStream looks up the encryption settings for 'fname' in the
+  // file registry and returns the matching cipher stream. Files without a
+  // registry entry are plaintext and result in a nullptr stream.
+  rocksdb::Status OpenCipherStream(const std::string& fname,
+                                   std::unique_ptr<rocksdb::BlockAccessCipherStream>* result) {
+    auto entry = file_registry_->GetFileEntry(fname);
+    if (entry == nullptr) {
+      result->reset();
+      return rocksdb::Status::OK();
+    }
+
+    if (entry->env_type() != stream_creator_->GetEnvType()) {
+      return rocksdb::Status::InvalidArgument("file " + fname +
+                                              " was written using a different env type");
+    }
+    return stream_creator_->CreateCipherStreamFromSettings(entry->encryption_settings(), result);
+  }
+
+  FileRegistry* file_registry_;
+  std::unique_ptr<CipherStreamCreator> stream_creator_;
+};
+
+}  // namespace
+
+rocksdb::Env* NewEncryptedEnv(rocksdb::Env* base_env, FileRegistry* file_registry,
+                              CipherStreamCreator* creator) {
+  return new EncryptedEnv(base_env, file_registry, creator);
+}
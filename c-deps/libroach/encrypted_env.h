@@ -0,0 +1,58 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+#ifndef ROACHLIB_ENCRYPTED_ENV_H
+#define ROACHLIB_ENCRYPTED_ENV_H
+
+#include <memory>
+#include <string>
+
+#include <rocksdb/env.h>
+#include <rocksdb/env_encryption.h>
+#include <rocksdb/status.h>
+#include "file_registry.h"
+
+// CipherStreamCreator creates the cipher streams used by an encrypted Env.
+// Implementations decide which key (if any) is used for new files, and how
+// to find the key for existing files from their encryption settings.
+class CipherStreamCreator {
+ public:
+  virtual ~CipherStreamCreator() {}
+
+  // InitSettingsAndCreateCipherStream is called when creating a new file. It
+  // fills 'settings' with the serialized encryption settings to be stored in
+  // the file registry and 'result' with the cipher stream to use. A nullptr
+  // stream means the file is written in plaintext.
+  virtual rocksdb::Status InitSettingsAndCreateCipherStream(
+      std::string* settings, std::unique_ptr<rocksdb::BlockAccessCipherStream>* result) = 0;
+
+  // CreateCipherStreamFromSettings returns the cipher stream for an existing
+  // file given its serialized encryption settings. A nullptr stream means
+  // the file is plaintext.
+  virtual rocksdb::Status CreateCipherStreamFromSettings(
+      const std::string& settings, std::unique_ptr<rocksdb::BlockAccessCipherStream>* result) = 0;
+
+  // GetEnvType returns the type of Env using this creator. It is recorded
+  // in the file registry for every file written.
+  virtual enginepb::EnvType GetEnvType() = 0;
+};
+
+// NewEncryptedEnv returns an Env wrapping 'base_env' that encrypts files using
+// cipher streams obtained from 'creator'. Encryption settings for each file
+// are recorded in 'file_registry', which must outlive the returned Env.
+// The returned Env takes ownership of 'creator'.
+rocksdb::Env* NewEncryptedEnv(rocksdb::Env* base_env, FileRegistry* file_registry,
+                              CipherStreamCreator* creator);
+
+#endif // ROACHLIB_ENCRYPTED_ENV_H
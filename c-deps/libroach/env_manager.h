@@ -0,0 +1,44 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+#ifndef ROACHLIB_ENV_MANAGER_H
+#define ROACHLIB_ENV_MANAGER_H
+
+#include <memory>
+#include <vector>
+
+#include <rocksdb/env.h>
+#include "file_registry.h"
+
+// EnvManager holds the Envs used by a single rocksdb instance, as well as the
+// file registry describing how each file was written.
+//
+// base_env is the Env used for plaintext files and is not owned by the
+// EnvManager. db_env is the Env handed to rocksdb. It defaults to base_env
+// but may be replaced by an encrypted Env during DBOpenHook.
+struct EnvManager {
+  EnvManager(rocksdb::Env* env) : base_env(env), db_env(env) {}
+  ~EnvManager() {}
+
+  // TakeEnvOwnership transfers ownership of 'env' to the EnvManager. It will
+  // be deleted when the EnvManager is.
+  void TakeEnvOwnership(rocksdb::Env* env) { envs.push_back(std::unique_ptr<rocksdb::Env>(env)); }
+
+  rocksdb::Env* base_env;
+  rocksdb::Env* db_env;
+  std::unique_ptr<FileRegistry> file_registry;
+  std::vector<std::unique_ptr<rocksdb::Env>> envs;
+};
+
+#endif // ROACHLIB_ENV_MANAGER_H
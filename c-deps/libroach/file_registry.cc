@@ -0,0 +1,187 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+#include <algorithm>
+#include <vector>
+#include "file_registry.h"
+
+FileRegistry::FileRegistry(rocksdb::Env* env, const std::string& db_dir)
+    : env_(env), db_dir_(db_dir), registry_path_(db_dir_ + "/" + kFileRegistryFilename) {}
+
+rocksdb::Status FileRegistry::CheckNoRegistryFile() {
+  rocksdb::Status status = env_->FileExists(registry_path_);
+  if (status.ok()) {
+    return rocksdb::Status::InvalidArgument(
+        "encryption was used on this store before, but no encryption flags specified. You need a "
+        "CCL build and must fully specify the --enterprise-encryption flag");
+  } else if (status.IsNotFound()) {
+    return rocksdb::Status::OK();
+  }
+  return status;
+}
+
+rocksdb::Status FileRegistry::Load() {
+  std::unique_lock<std::mutex> l(mu_);
+
+  rocksdb::Status status = env_->FileExists(registry_path_);
+  if (status.IsNotFound()) {
+    files_.clear();
+    return rocksdb::Status::OK();
+  } else if (!status.ok()) {
+    return status;
+  }
+
+  std::string contents;
+  status = rocksdb::ReadFileToString(env_, registry_path_, &contents);
+  if (!status.ok()) {
+    return status;
+  }
+
+  enginepb::FileRegistry registry;
+  if (!registry.ParseFromString(contents)) {
+    return rocksdb::Status::InvalidArgument("failed to parse file registry");
+  }
+  if (registry.version() != enginepb::Base) {
+    return rocksdb::Status::InvalidArgument("unknown file registry version");
+  }
+
+  files_.clear();
+  for (int i = 0; i < registry.files_size(); i++) {
+    const enginepb::FileEntry& entry = registry.files(i);
+    files_[entry.filename()] = entry;
+  }
+  return rocksdb::Status::OK();
+}
+
+std::string FileRegistry::TransformPath(const std::string& filename) {
+  // Rocksdb sometimes passes paths with duplicate slashes, so strip the
+  // prefix and any leading slashes that follow it.
+  if (filename.compare(0, db_dir_.size(), db_dir_) != 0) {
+    return filename;
+  }
+  size_t pos = db_dir_.size();
+  if (pos < filename.size() && filename[pos] != '/') {
+    // A sibling directory sharing db_dir_ as a prefix.
+    return filename;
+  }
+  while (pos < filename.size() && filename[pos] == '/') {
+    pos++;
+  }
+  return filename.substr(pos);
+}
+
+std::unique_ptr<enginepb::FileEntry> FileRegistry::GetFileEntry(const std::string& filename) {
+  std::string newName = TransformPath(filename);
+
+  std::unique_lock<std::mutex> l(mu_);
+  auto it = files_.find(newName);
+  if (it == files_.end()) {
+    return nullptr;
+  }
+  return std::unique_ptr<enginepb::FileEntry>(new enginepb::FileEntry(it->second));
+}
+
+rocksdb::Status FileRegistry::SetFileEntry(const std::string& filename,
+                                           std::unique_ptr<enginepb::FileEntry> entry) {
+  std::string newName = TransformPath(filename);
+  entry->set_filename(newName);
+
+  std::unique_lock<std::mutex> l(mu_);
+  files_[newName] = *entry;
+  return PersistRegistryLocked();
+}
+
+rocksdb::Status FileRegistry::MaybeDeleteEntry(const std::string& filename) {
+  std::string newName = TransformPath(filename);
+
+  std::unique_lock<std::mutex> l(mu_);
+  if (files_.erase(newName) == 0) {
+    return rocksdb::Status::OK();
+  }
+  return PersistRegistryLocked();
+}
+
+rocksdb::Status FileRegistry::MaybeRenameEntry(const std::string& src, const std::string& target) {
+  std::string srcName = TransformPath(src);
+  std::string targetName = TransformPath(target);
+  if (srcName == targetName) {
+    return rocksdb::Status::OK();
+  }
+
+  std::unique_lock<std::mutex> l(mu_);
+  auto it = files_.find(srcName);
+  if (it == files_.end()) {
+    if (files_.erase(targetName) == 0) {
+      return rocksdb::Status::OK();
+    }
+    return PersistRegistryLocked();
+  }
+
+  enginepb::FileEntry entry = it->second;
+  entry.set_filename(targetName);
+  files_.erase(it);
+  files_[targetName] = entry;
+  return PersistRegistryLocked();
+}
+
+rocksdb::Status FileRegistry::MaybeLinkEntry(const std::string& src, const std::string& target) {
+  std::string srcName = TransformPath(src);
+  std::string targetName = TransformPath(target);
+  if (srcName == targetName) {
+    return rocksdb::Status::OK();
+  }
+
+  std::unique_lock<std::mutex> l(mu_);
+  auto it = files_.find(srcName);
+  if (it == files_.end()) {
+    if (files_.erase(targetName) == 0) {
+      return rocksdb::Status::OK();
+    }
+    return PersistRegistryLocked();
+  }
+
+  enginepb::FileEntry entry = it->second;
+  entry.set_filename(targetName);
+  files_[targetName] = entry;
+  return PersistRegistryLocked();
+}
+
+rocksdb::Status FileRegistry::PersistRegistryLocked() {
+  // Write entries in sorted order so the registry contents are deterministic.
+  std::vector<std::string> names;
+  names.reserve(files_.size());
+  for (auto it = files_.begin(); it != files_.end(); ++it) {
+    names.push_back(it->first);
+  }
+  std::sort(names.begin(), names.end());
+
+  enginepb::FileRegistry registry;
+  registry.set_version(enginepb::Base);
+  for (auto it = names.begin(); it != names.end(); ++it) {
+    *registry.add_files() = files_[*it];
+  }
+
+  std::string contents;
+  if (!registry.SerializeToString(&contents)) {
+    return rocksdb::Status::InvalidArgument("failed to serialize file registry");
+  }
+
+  std::string tmpFilename = registry_path_ + ".tmp";
+  rocksdb::Status status =
+      rocksdb::WriteStringToFile(env_, contents, tmpFilename, true /* should_sync */);
+  if (!status.ok()) {
+    return status;
+  }
+  return env_->RenameFile(tmpFilename, registry_path_);
+}
@@ -0,0 +1,92 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+#ifndef ROACHLIB_FILE_REGISTRY_H
+#define ROACHLIB_FILE_REGISTRY_H
+
+#include <memory>
+#include <mutex>
+#include <string>
+#include <unordered_map>
+
+#include <rocksdb/env.h>
+#include <rocksdb/status.h>
+#include "protos/storage/engine/enginepb/file_registry.pb.h"
+
+namespace enginepb = cockroach::storage::engine::enginepb;
+
+// kFileRegistryFilename is the name of the registry file, stored at the root
+// of the rocksdb directory.
+static const std::string kFileRegistryFilename = "COCKROACHDB_REGISTRY";
+
+// FileRegistry keeps track of the Env used to write each file in the rocksdb
+// directory, along with any encryption settings needed to read it back.
+//
+// Files written using the plaintext Env are not recorded, so a missing entry
+// means the file is plaintext. All filenames are stored relative to the
+// rocksdb directory.
+//
+// The registry is persisted to disk on every modification. It is written to a
+// temporary file and renamed into place so that a crash never leaves a
+// partially written registry behind.
+class FileRegistry {
+ public:
+  FileRegistry(rocksdb::Env* env, const std::string& db_dir);
+  ~FileRegistry() {}
+
+  // CheckNoRegistryFile returns an error if the registry file exists. It is
+  // used when opening a store without encryption.
+  rocksdb::Status CheckNoRegistryFile();
+
+  // Load reads the registry file from disk. A missing file is not an error
+  // and results in an empty registry.
+  rocksdb::Status Load();
+
+  // GetFileEntry returns a copy of the entry for 'filename', or nullptr if it
+  // does not exist.
+  std::unique_ptr<enginepb::FileEntry> GetFileEntry(const std::string& filename);
+
+  // SetFileEntry inserts or replaces the entry for 'filename' and persists
+  // the registry.
+  rocksdb::Status SetFileEntry(const std::string& filename, std::unique_ptr<enginepb::FileEntry> entry);
+
+  // MaybeDeleteEntry removes the entry for 'filename' if one exists.
+  rocksdb::Status MaybeDeleteEntry(const std::string& filename);
+
+  // MaybeRenameEntry moves the entry for 'src' to 'target'. If 'src' has no
+  // entry, any existing entry for 'target' is removed.
+  rocksdb::Status MaybeRenameEntry(const std::string& src, const std::string& target);
+
+  // MaybeLinkEntry copies the entry for 'src' to 'target'. If 'src' has no
+  // entry, any existing entry for 'target' is removed.
+  rocksdb::Status MaybeLinkEntry(const std::string& src, const std::string& target);
+
+  // TransformPath returns 'filename' relative to the rocksdb directory. Paths
+  // outside the rocksdb directory are returned unchanged.
+  std::string TransformPath(const std::string& filename);
+
+ private:
+  // PersistRegistryLocked writes the registry to disk. mu_ must be held.
+  rocksdb::Status PersistRegistryLocked();
+
+  rocksdb::Env* env_;
+  const std::string db_dir_;
+  const std::string registry_path_;
+
+  std::mutex mu_;
+  // Entries keyed by path relative to db_dir_. Protected by mu_.
+  std::unordered_map<std::string, enginepb::FileEntry> files_;
+};
+
+#endif // ROACHLIB_FILE_REGISTRY_H
@@ -0,0 +1,108 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+
+#include "file_registry.h"
+#include <vector>
+#include "testutils.h"
+
+namespace {
+
+std::unique_ptr<enginepb::FileEntry> MakeEntry(enginepb::EnvType env_type,
+                                               const std::string& settings) {
+  std::unique_ptr<enginepb::FileEntry> entry(new enginepb::FileEntry());
+  entry->set_env_type(env_type);
+  entry->set_encryption_settings(settings);
+  return entry;
+}
+
+}  // namespace
+
+TEST(FileRegistry, TransformPath) {
+  FileRegistry reg(rocksdb::Env::Default(), "/base");
+
+  struct TestCase {
+    std::string path;
+    std::string expected;
+  };
+  std::vector<TestCase> test_cases = {
+      {"/base/foo", "foo"},
+      {"/base/foo/bar", "foo/bar"},
+      {"/base//foo", "foo"},
+      {"/base/", ""},
+      {"/base", ""},
+      {"/basement/foo", "/basement/foo"},
+      {"/other/foo", "/other/foo"},
+      {"foo", "foo"},
+  };
+  for (auto t : test_cases) {
+    EXPECT_EQ(t.expected, reg.TransformPath(t.path)) << t.path;
+  }
+}
+
+TEST(FileRegistry, Persistence) {
+  testutils::TempDirHandler dir;
+  ASSERT_NE("", dir.Path());
+  rocksdb::Env* env = rocksdb::Env::Default();
+
+  // A store without a registry file.
+  FileRegistry reg(env, dir.Path());
+  ASSERT_OK(reg.CheckNoRegistryFile());
+  ASSERT_OK(reg.Load());
+  EXPECT_TRUE(reg.GetFileEntry(dir.Join("foo")) == nullptr);
+
+  ASSERT_OK(reg.SetFileEntry(dir.Join("foo"), MakeEntry(enginepb::Data, "foo-settings")));
+  ASSERT_OK(reg.SetFileEntry(dir.Join("bar"), MakeEntry(enginepb::Store, "bar-settings")));
+  ASSERT_OK(reg.SetFileEntry(dir.Join("baz"), MakeEntry(enginepb::Data, "baz-settings")));
+  // The registry file now exists.
+  EXPECT_FALSE(reg.CheckNoRegistryFile().ok());
+
+  // Renames and links move or copy the entries, and overwrite the target.
+  ASSERT_OK(reg.MaybeRenameEntry(dir.Join("foo"), dir.Join("foo2")));
+  ASSERT_OK(reg.MaybeLinkEntry(dir.Join("bar"), dir.Join("bar2")));
+  ASSERT_OK(reg.MaybeRenameEntry(dir.Join("unknown"), dir.Join("baz")));
+  ASSERT_OK(reg.MaybeDeleteEntry(dir.Join("bar")));
+  ASSERT_OK(reg.MaybeDeleteEntry(dir.Join("unknown")));
+
+  // checkEntries verifies the contents of a registry against the operations
+  // above.
+  auto checkEntries = [&dir](FileRegistry* r) {
+    EXPECT_TRUE(r->GetFileEntry(dir.Join("foo")) == nullptr);
+    EXPECT_TRUE(r->GetFileEntry(dir.Join("bar")) == nullptr);
+    EXPECT_TRUE(r->GetFileEntry(dir.Join("baz")) == nullptr);
+
+    auto foo2 = r->GetFileEntry(dir.Join("foo2"));
+    ASSERT_TRUE(foo2 != nullptr);
+    EXPECT_EQ("foo2", foo2->filename());
+    EXPECT_EQ(enginepb::Data, foo2->env_type());
+    EXPECT_EQ("foo-settings", foo2->encryption_settings());
+
+    auto bar2 = r->GetFileEntry(dir.Join("bar2"));
+    ASSERT_TRUE(bar2 != nullptr);
+    EXPECT_EQ("bar2", bar2->filename());
+    EXPECT_EQ(enginepb::Store, bar2->env_type());
+    EXPECT_EQ("bar-settings", bar2->encryption_settings());
+  };
+  checkEntries(&reg);
+
+  // A new registry loads the persisted entries.
+  FileRegistry reg2(env, dir.Path());
+  ASSERT_OK(reg2.Load());
+  checkEntries(&reg2);
+
+  // A corrupted registry file fails to load.
+  ASSERT_OK(rocksdb::WriteStringToFile(env, "not a registry", dir.Join(kFileRegistryFilename)));
+  FileRegistry reg3(env, dir.Path());
+  EXPECT_FALSE(reg3.Load().ok());
+}
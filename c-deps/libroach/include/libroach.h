@@ -71,6 +71,9 @@ typedef struct {
   bool logging_enabled;
   int num_cpu;
   int max_open_files;
+  // extra_options holds serialized options understood only by the CCL
+  // build (eg: encryption settings).
+  DBSlice extra_options;
 } DBOptions;
 
 // Create a new cache with the specified size.
@@ -0,0 +1,47 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+
+#include "testutils.h"
+#include <ftw.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <vector>
+
+namespace testutils {
+
+namespace {
+
+int removeEntry(const char* path, const struct stat*, int, struct FTW*) { return remove(path); }
+
+}  // namespace
+
+TempDirHandler::TempDirHandler() {
+  const char* tmpdir = getenv("TMPDIR");
+  std::string tmpl = std::string(tmpdir != nullptr ? tmpdir : "/tmp") + "/roachlib-test.XXXXXX";
+  std::vector<char> buf(tmpl.begin(), tmpl.end());
+  buf.push_back('\0');
+  if (mkdtemp(buf.data()) != nullptr) {
+    path_ = buf.data();
+  }
+}
+
+TempDirHandler::~TempDirHandler() {
+  if (path_ != "") {
+    // Remove the directory's contents before the directory itself.
+    nftw(path_.c_str(), removeEntry, 16, FTW_DEPTH | FTW_PHYS);
+  }
+}
+
+}  // namespace testutils
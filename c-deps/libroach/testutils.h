@@ -0,0 +1,49 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+
+#ifndef ROACHLIB_TESTUTILS_H
+#define ROACHLIB_TESTUTILS_H
+
+#include <string>
+#include <gtest/gtest.h>
+#include <rocksdb/status.h>
+
+// Assert or expect that a rocksdb::Status is OK, printing it otherwise.
+#define ASSERT_OK(s) ASSERT_TRUE((s).ok()) << (s).ToString()
+#define EXPECT_OK(s) EXPECT_TRUE((s).ok()) << (s).ToString()
+
+namespace testutils {
+
+// TempDirHandler creates a temporary directory on construction and removes
+// it, along with its contents, on destruction.
+class TempDirHandler {
+ public:
+  TempDirHandler();
+  ~TempDirHandler();
+
+  // Path returns the path of the temporary directory, which is empty if it
+  // could not be created.
+  const std::string& Path() const { return path_; }
+
+  // Join returns the path of 'name' within the temporary directory.
+  std::string Join(const std::string& name) const { return path_ + "/" + name; }
+
+ private:
+  std::string path_;
+};
+
+}  // namespace testutils
+
+#endif  // ROACHLIB_TESTUTILS_H
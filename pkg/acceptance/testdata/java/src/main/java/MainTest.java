@@ -157,4 +157,21 @@ public class MainTest extends CockroachDBTest {
         String[] result = (String[])rs.getArray(1).getArray();
         Assert.assertArrayEquals(new String[]{"123", "hello", "\"hello\""}, result);
     }
+
+    @Test
+    public void testFetchSize() throws Exception {
+        // The driver only pages through results with a fetch size inside of
+        // an explicit transaction.
+        conn.setAutoCommit(false);
+        PreparedStatement stmt = conn.prepareStatement("SELECT generate_series(1, 10)");
+        stmt.setFetchSize(3);
+        ResultSet rs = stmt.executeQuery();
+        for (int i = 1; i <= 10; i++) {
+            Assert.assertTrue(rs.next());
+            Assert.assertEquals(i, rs.getInt(1));
+        }
+        Assert.assertFalse(rs.next());
+        conn.commit();
+        conn.setAutoCommit(true);
+    }
 }
@@ -51,6 +51,9 @@ type StoreSpec struct {
 	SizePercent float64
 	InMemory    bool
 	Attributes  roachpb.Attributes
+	// ExtraOptions is a serialized protobuf set by Go CCL code and passed
+	// through to C CCL code.
+	ExtraOptions []byte
 }
 
 // String returns a fully parsable version of the store spec.
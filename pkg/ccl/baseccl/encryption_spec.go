@@ -0,0 +1,224 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package baseccl
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// DefaultRotationPeriod is the rotation period used if not specified.
+const DefaultRotationPeriod = time.Hour * 24 * 7 // 1 week, give or take time changes.
+
+// plaintextFieldValue is the value of the key fields meaning "no encryption".
+const plaintextFieldValue = "plain"
+
+// StoreEncryptionSpec contains the details that can be specified in the
+// cli via the --enterprise-encryption flag.
+type StoreEncryptionSpec struct {
+	Path           string
+	KeyPath        string
+	OldKeyPath     string
+	RotationPeriod time.Duration
+}
+
+// toEncryptionOptions converts a StoreEncryptionSpec to a serialized
+// EncryptionOptions protobuf, as passed to the C++ layer.
+func (es StoreEncryptionSpec) toEncryptionOptions() ([]byte, error) {
+	opts := EncryptionOptions{
+		KeySource: EncryptionKeySource_KeyFiles,
+		KeyFiles: &EncryptionKeyFiles{
+			CurrentKey: es.KeyPath,
+			OldKey:     es.OldKeyPath,
+		},
+		DataKeyRotationPeriod: int64(es.RotationPeriod / time.Second),
+	}
+
+	return protoutil.Marshal(&opts)
+}
+
+// String returns a fully parsable version of the encryption spec.
+func (es StoreEncryptionSpec) String() string {
+	// All fields are set.
+	return fmt.Sprintf("path=%s,key=%s,old-key=%s,rotation-period=%s",
+		es.Path, es.KeyPath, es.OldKeyPath, es.RotationPeriod)
+}
+
+// NewStoreEncryptionSpec parses the string passed in and returns a new
+// StoreEncryptionSpec if parsing succeeds.
+// The following fields are supported, comma separated:
+// - path=xxx The path of the store to apply encryption to. Required.
+// - key=xxx The path to the active key file, or "plain". Required.
+// - old-key=xxx The path to the previous key file, or "plain". Required.
+// - rotation-period=xxx The data key rotation period, as a duration. Optional,
+//   defaults to one week.
+func NewStoreEncryptionSpec(value string) (StoreEncryptionSpec, error) {
+	var es StoreEncryptionSpec
+	es.RotationPeriod = DefaultRotationPeriod
+
+	used := make(map[string]struct{})
+	for _, split := range strings.Split(value, ",") {
+		if len(split) == 0 {
+			continue
+		}
+		subSplits := strings.SplitN(split, "=", 2)
+		if len(subSplits) == 1 {
+			return StoreEncryptionSpec{}, fmt.Errorf("field not in the form <key>=<value>: %s", split)
+		}
+		field := strings.ToLower(subSplits[0])
+		value := subSplits[1]
+		if _, ok := used[field]; ok {
+			return StoreEncryptionSpec{}, fmt.Errorf("%s field was used twice in encryption definition", field)
+		}
+		used[field] = struct{}{}
+
+		if len(field) == 0 {
+			return StoreEncryptionSpec{}, fmt.Errorf("empty field")
+		}
+		if len(value) == 0 {
+			return StoreEncryptionSpec{}, fmt.Errorf("no value specified for %s", field)
+		}
+
+		switch field {
+		case "path":
+			var err error
+			es.Path, err = filepath.Abs(value)
+			if err != nil {
+				return StoreEncryptionSpec{}, errors.Wrapf(err, "could not find absolute path for %s ", value)
+			}
+		case "key":
+			if value == plaintextFieldValue {
+				es.KeyPath = plaintextFieldValue
+			} else {
+				var err error
+				es.KeyPath, err = filepath.Abs(value)
+				if err != nil {
+					return StoreEncryptionSpec{}, errors.Wrapf(err, "could not find absolute path for %s ", value)
+				}
+			}
+		case "old-key":
+			if value == plaintextFieldValue {
+				es.OldKeyPath = plaintextFieldValue
+			} else {
+				var err error
+				es.OldKeyPath, err = filepath.Abs(value)
+				if err != nil {
+					return StoreEncryptionSpec{}, errors.Wrapf(err, "could not find absolute path for %s ", value)
+				}
+			}
+		case "rotation-period":
+			var err error
+			es.RotationPeriod, err = time.ParseDuration(value)
+			if err != nil {
+				return StoreEncryptionSpec{}, errors.Wrapf(err, "could not parse rotation-duration value: %s", value)
+			}
+			if es.RotationPeriod < time.Second {
+				return StoreEncryptionSpec{}, fmt.Errorf("rotation-period must be at least one second: %s", value)
+			}
+		default:
+			return StoreEncryptionSpec{}, fmt.Errorf("%s is not a valid enterprise-encryption field", field)
+		}
+	}
+
+	// Check that all fields are set.
+	if es.Path == "" {
+		return StoreEncryptionSpec{}, fmt.Errorf("no path specified")
+	}
+	if es.KeyPath == "" {
+		return StoreEncryptionSpec{}, fmt.Errorf("no key specified")
+	}
+	if es.OldKeyPath == "" {
+		return StoreEncryptionSpec{}, fmt.Errorf("no old-key specified")
+	}
+
+	return es, nil
+}
+
+// StoreEncryptionSpecList contains a slice of StoreEncryptionSpecs that
+// implements pflag's value interface.
+type StoreEncryptionSpecList struct {
+	Specs []StoreEncryptionSpec
+}
+
+var _ pflag.Value = &StoreEncryptionSpecList{}
+
+// String returns a string representation of all the StoreEncryptionSpecs.
+// This is part of pflag's value interface.
+func (encl StoreEncryptionSpecList) String() string {
+	var buffer bytes.Buffer
+	for _, ss := range encl.Specs {
+		fmt.Fprintf(&buffer, "--enterprise-encryption=%s ", ss)
+	}
+	// Trim the extra space from the end if it exists.
+	if l := buffer.Len(); l > 0 {
+		buffer.Truncate(l - 1)
+	}
+	return buffer.String()
+}
+
+// Type returns the underlying type in string form. This is part of pflag's
+// value interface.
+func (encl *StoreEncryptionSpecList) Type() string {
+	return "StoreEncryptionSpec"
+}
+
+// Set adds a new value to the StoreEncryptionSpecList. It is the important
+// part of pflag's value interface.
+func (encl *StoreEncryptionSpecList) Set(value string) error {
+	spec, err := NewStoreEncryptionSpec(value)
+	if err != nil {
+		return err
+	}
+	encl.Specs = append(encl.Specs, spec)
+	return nil
+}
+
+// PopulateStoreSpecWithEncryption iterates through the StoreEncryptionSpecList
+// and looks for matching paths in the StoreSpecList. Any unmatched
+// StoreEncryptionSpec causes an error. Matching stores have their
+// ExtraOptions field set to the serialized encryption options.
+func PopulateStoreSpecWithEncryption(
+	storeSpecs base.StoreSpecList, encryptionSpecs StoreEncryptionSpecList,
+) error {
+	for _, es := range encryptionSpecs.Specs {
+		found := false
+		for i := range storeSpecs.Specs {
+			if storeSpecs.Specs[i].Path != es.Path {
+				continue
+			}
+
+			// Found a matching path.
+			if storeSpecs.Specs[i].ExtraOptions != nil {
+				return fmt.Errorf("store with path %s already has an encryption setting",
+					storeSpecs.Specs[i].Path)
+			}
+
+			opts, err := es.toEncryptionOptions()
+			if err != nil {
+				return err
+			}
+			storeSpecs.Specs[i].ExtraOptions = opts
+			found = true
+			break
+		}
+		if !found {
+			return fmt.Errorf("no store with path %s found for encryption setting: %v", es.Path, es)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package baseccl
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// TestNewStoreEncryptionSpec verifies that the --enterprise-encryption
+// arguments are correctly parsed into StoreEncryptionSpecs.
+func TestNewStoreEncryptionSpec(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		value       string
+		expectedErr string
+		expected    StoreEncryptionSpec
+	}{
+		// path
+		{",", "no path specified", StoreEncryptionSpec{}},
+		{"", "no path specified", StoreEncryptionSpec{}},
+		{"/mnt/hda1", "field not in the form <key>=<value>: /mnt/hda1", StoreEncryptionSpec{}},
+		{"path=", "no value specified for path", StoreEncryptionSpec{}},
+		{"path=/mnt/hda1,path=/mnt/hda2", "path field was used twice in encryption definition", StoreEncryptionSpec{}},
+
+		// keys
+		{"path=/data", "no key specified", StoreEncryptionSpec{}},
+		{"path=/data,key=/keys.key", "no old-key specified", StoreEncryptionSpec{}},
+		{"path=/data,key=/foo.key,old-key=/bar.key", "",
+			StoreEncryptionSpec{Path: "/data", KeyPath: "/foo.key", OldKeyPath: "/bar.key", RotationPeriod: DefaultRotationPeriod}},
+		{"path=/data,key=plain,old-key=/bar.key", "",
+			StoreEncryptionSpec{Path: "/data", KeyPath: "plain", OldKeyPath: "/bar.key", RotationPeriod: DefaultRotationPeriod}},
+		{"path=/data,key=/foo.key,old-key=plain", "",
+			StoreEncryptionSpec{Path: "/data", KeyPath: "/foo.key", OldKeyPath: "plain", RotationPeriod: DefaultRotationPeriod}},
+
+		// rotation period
+		{"path=/data,key=/foo.key,old-key=/bar.key,rotation-period=1h", "",
+			StoreEncryptionSpec{Path: "/data", KeyPath: "/foo.key", OldKeyPath: "/bar.key", RotationPeriod: time.Hour}},
+		{"path=/data,key=/foo.key,old-key=/bar.key,rotation-period=1", "could not parse rotation-duration value: 1: time: missing unit in duration 1",
+			StoreEncryptionSpec{}},
+		{"path=/data,key=/foo.key,old-key=/bar.key,rotation-period=1ms", "rotation-period must be at least one second: 1ms",
+			StoreEncryptionSpec{}},
+
+		// other
+		{"path=/data,key=/foo.key,old-key=/bar.key,something=abc", "something is not a valid enterprise-encryption field",
+			StoreEncryptionSpec{}},
+	}
+
+	for i, testCase := range testCases {
+		spec, err := NewStoreEncryptionSpec(testCase.value)
+		if err != nil {
+			if len(testCase.expectedErr) == 0 {
+				t.Errorf("%d(%s): no expected error, got %s", i, testCase.value, err)
+			}
+			if testCase.expectedErr != fmt.Sprint(err) {
+				t.Errorf("%d(%s): expected error \"%s\" does not match actual \"%s\"", i, testCase.value,
+					testCase.expectedErr, err)
+			}
+			continue
+		}
+		if len(testCase.expectedErr) > 0 {
+			t.Errorf("%d(%s): expected error %s but there was none", i, testCase.value, testCase.expectedErr)
+			continue
+		}
+		if testCase.expected == (StoreEncryptionSpec{}) {
+			// Only checking for parse success.
+			continue
+		}
+		if !reflect.DeepEqual(testCase.expected, spec) {
+			t.Errorf("%d(%s): actual doesn't match expected\nactual:   %+v\nexpected: %+v", i,
+				testCase.value, spec, testCase.expected)
+		}
+
+		// Now test String() to make sure the result can be parsed.
+		specString := spec.String()
+		spec2, err := NewStoreEncryptionSpec(specString)
+		if err != nil {
+			t.Errorf("%d(%s): error parsing String() result: %s", i, testCase.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(spec, spec2) {
+			t.Errorf("%d(%s): actual doesn't match expected\nactual:   %#+v\nexpected: %#+v", i,
+				testCase.value, spec, spec2)
+		}
+	}
+}
+
+// TestPopulateStoreSpecWithEncryption verifies that encryption specs are
+// matched to store specs by path.
+func TestPopulateStoreSpecWithEncryption(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	newSpecs := func() base.StoreSpecList {
+		return base.StoreSpecList{Specs: []base.StoreSpec{{Path: "/a"}, {Path: "/b"}}}
+	}
+	encSpec := StoreEncryptionSpec{
+		Path: "/b", KeyPath: "/foo.key", OldKeyPath: "plain", RotationPeriod: time.Hour,
+	}
+
+	stores := newSpecs()
+	if err := PopulateStoreSpecWithEncryption(
+		stores, StoreEncryptionSpecList{Specs: []StoreEncryptionSpec{encSpec}},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if stores.Specs[0].ExtraOptions != nil {
+		t.Errorf("expected no options for store /a, got %v", stores.Specs[0].ExtraOptions)
+	}
+	var opts EncryptionOptions
+	if err := protoutil.Unmarshal(stores.Specs[1].ExtraOptions, &opts); err != nil {
+		t.Fatal(err)
+	}
+	expected := EncryptionOptions{
+		KeySource:             EncryptionKeySource_KeyFiles,
+		KeyFiles:              &EncryptionKeyFiles{CurrentKey: "/foo.key", OldKey: "plain"},
+		DataKeyRotationPeriod: 3600,
+	}
+	if !reflect.DeepEqual(expected, opts) {
+		t.Errorf("expected %+v, got %+v", expected, opts)
+	}
+
+	// Unknown paths and duplicate specs are errors.
+	for _, tc := range []struct {
+		specs       []StoreEncryptionSpec
+		expectedErr string
+	}{
+		{[]StoreEncryptionSpec{{Path: "/c"}}, "no store with path /c found for encryption setting"},
+		{[]StoreEncryptionSpec{encSpec, encSpec}, "store with path /b already has an encryption setting"},
+	} {
+		err := PopulateStoreSpecWithEncryption(newSpecs(), StoreEncryptionSpecList{Specs: tc.specs})
+		if !testutils.IsError(err, tc.expectedErr) {
+			t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+		}
+	}
+}
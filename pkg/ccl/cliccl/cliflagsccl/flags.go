@@ -0,0 +1,40 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package cliflagsccl
+
+import "github.com/cockroachdb/cockroach/pkg/cli/cliflags"
+
+// EnterpriseEncryption and others store the static information for CLI flags.
+var (
+	EnterpriseEncryption = cliflags.FlagInfo{
+		Name: "enterprise-encryption",
+		Description: `
+Specify encryption options for one of the stores on a node. If multiple
+stores exist, the flag must be specified for each store. Store data is
+encrypted using AES in counter mode with automatically rotated data keys,
+which are themselves encrypted using the key given in the "key" field. The
+key file contains the raw key: 16, 24, or 32 bytes for AES-128, AES-192, or
+AES-256 respectively.
+
+Valid fields:
+<PRE>
+
+* path    (required): must match the path of one of the stores
+* key     (required): path to the current key file, or "plain"
+* old-key (required): path to the previous key file, or "plain"
+* rotation-period   : amount of time after which data keys should be rotated
+
+</PRE>
+example:
+<PRE>
+  --enterprise-encryption=path=cockroach-data,key=/keys/aes-128.key,old-key=plain
+</PRE>
+`,
+	}
+)
@@ -0,0 +1,77 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package cliccl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/cli"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// fileRegistryFilename is the name of the file registry in the store
+// directory. It must match kFileRegistryFilename in libroach.
+const fileRegistryFilename = "COCKROACHDB_REGISTRY"
+
+func init() {
+	encryptionStatusCmd := &cobra.Command{
+		Use:   "encryption-status <directory>",
+		Short: "show encryption status of a store",
+		Long: `
+Shows the encryption status of the store located in <directory>: for each
+file written using encryption, the env type, encryption type and the ID of
+the key used to encrypt it. Files not listed are plaintext.
+`,
+		RunE: cli.MaybeDecorateGRPCError(runEncryptionStatus),
+	}
+
+	cli.DebugCmd.AddCommand(encryptionStatusCmd)
+}
+
+func runEncryptionStatus(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("one argument required: dir")
+	}
+	dir := args[0]
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, fileRegistryFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Printf("no file registry found in %s: encryption has never been used\n", dir)
+			return nil
+		}
+		return err
+	}
+
+	var registry enginepb.FileRegistry
+	if err := protoutil.Unmarshal(data, &registry); err != nil {
+		return errors.Wrapf(err, "could not parse file registry in %s", dir)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 2, 1, 2, ' ', 0)
+	fmt.Fprintf(tw, "filename\tenv type\tencryption type\tkey ID\n")
+	for _, entry := range registry.Files {
+		var settings enginepbccl.EncryptionSettings
+		if err := protoutil.Unmarshal(entry.EncryptionSettings, &settings); err != nil {
+			return errors.Wrapf(err, "could not parse encryption settings for %s", entry.Filename)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			entry.Filename, entry.EnvType, settings.EncryptionType, settings.KeyId)
+	}
+	return tw.Flush()
+}
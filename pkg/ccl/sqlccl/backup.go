@@ -909,6 +909,9 @@ func showBackupPlanHook(
 				if _, ok := descs[database.ID]; !ok {
 					descs[database.ID] = database.Name
 				}
+				for _, schema := range database.Schemas {
+					descs[schema.ID] = schema.Name
+				}
 			}
 		}
 		descSizes := make(map[sqlbase.ID]roachpb.BulkOpSummary)
@@ -933,9 +936,13 @@ func showBackupPlanHook(
 		for _, descriptor := range desc.Descriptors {
 			if table := descriptor.GetTable(); table != nil {
 				dbName := descs[table.ParentID]
+				tableName := table.Name
+				if table.ParentSchemaID != 0 {
+					tableName = descs[table.ParentSchemaID] + "." + tableName
+				}
 				resultsCh <- tree.Datums{
 					tree.NewDString(dbName),
-					tree.NewDString(table.Name),
+					tree.NewDString(tableName),
 					start,
 					tree.MakeDTimestamp(timeutil.Unix(0, desc.EndTime.WallTime), time.Nanosecond),
 					tree.NewDInt(tree.DInt(descSizes[table.ID].DataSize)),
@@ -1959,6 +1959,81 @@ func TestRestoreInto(t *testing.T) {
 	sqlDB.CheckQueryResults(`SELECT * FROM "data 2".bank`, expected)
 }
 
+func TestBackupRestoreUserSchemas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 1
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+	args := base.TestServerArgs{ExternalIODir: dir}
+
+	sqlDB.Exec(`CREATE SCHEMA data.s`)
+	sqlDB.Exec(`CREATE TABLE data.s.bank (id INT PRIMARY KEY, balance INT)`)
+	sqlDB.Exec(`INSERT INTO data.s.bank VALUES (1, 10), (2, 20)`)
+	sqlDB.Exec(`CREATE USER someone`)
+	sqlDB.Exec(`GRANT CREATE ON SCHEMA data.s TO someone`)
+
+	expected := sqlDB.QueryStr(`SELECT * FROM data.s.bank`)
+	expectedPublic := sqlDB.QueryStr(`SELECT * FROM data.bank`)
+
+	sqlDB.Exec(`BACKUP DATABASE data TO $1`, localFoo)
+
+	t.Run("database", func(t *testing.T) {
+		tc := testcluster.StartTestCluster(t, singleNode, base.TestClusterArgs{ServerArgs: args})
+		defer tc.Stopper().Stop(context.TODO())
+		sqlDBRestore := sqlutils.MakeSQLRunner(t, tc.Conns[0])
+		sqlDBRestore.Exec(`RESTORE DATABASE data FROM $1`, localFoo)
+		sqlDBRestore.CheckQueryResults(`SELECT * FROM data.s.bank`, expected)
+		sqlDBRestore.CheckQueryResults(`SELECT * FROM data.bank`, expectedPublic)
+		// Like the privileges of databases, the privileges of schemas aren't
+		// restored.
+		sqlDBRestore.CheckQueryResults(`SHOW GRANTS ON SCHEMA data.s`, [][]string{
+			{"data", "s", "root", "ALL"},
+		})
+		// The restored schema can be used like any other.
+		sqlDBRestore.Exec(`CREATE TABLE data.s.other (a INT PRIMARY KEY)`)
+		sqlDBRestore.Exec(`DROP SCHEMA data.s CASCADE`)
+	})
+
+	t.Run("tables", func(t *testing.T) {
+		tc := testcluster.StartTestCluster(t, singleNode, base.TestClusterArgs{ServerArgs: args})
+		defer tc.Stopper().Stop(context.TODO())
+		sqlDBRestore := sqlutils.MakeSQLRunner(t, tc.Conns[0])
+		sqlDBRestore.Exec(`CREATE DATABASE data`)
+
+		if _, err := sqlDBRestore.DB.Exec(`RESTORE data.s.bank FROM $1`, localFoo); !testutils.IsError(
+			err, `a schema named "s" needs to exist in database "data"`,
+		) {
+			t.Fatal(err)
+		}
+
+		sqlDBRestore.Exec(`CREATE SCHEMA data.s`)
+		sqlDBRestore.Exec(`CREATE USER someone`)
+		sqlDBRestore.Exec(`GRANT SELECT ON SCHEMA data.s TO someone`)
+		sqlDBRestore.Exec(`RESTORE data.s.bank FROM $1`, localFoo)
+		sqlDBRestore.CheckQueryResults(`SELECT * FROM data.s.bank`, expected)
+		// The privileges of the restored table are copied from its schema.
+		sqlDBRestore.CheckQueryResults(`SHOW GRANTS ON data.s.bank`, [][]string{
+			{"data", "s.bank", "root", "ALL"},
+			{"data", "s.bank", "someone", "SELECT"},
+		})
+
+		if _, err := sqlDBRestore.DB.Exec(`RESTORE data.s.bank FROM $1`, localFoo); !testutils.IsError(
+			err, "already exists",
+		) {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("into_db", func(t *testing.T) {
+		sqlDB.Exec(`CREATE DATABASE data2`)
+		sqlDB.Exec(`CREATE SCHEMA data2.s`)
+		sqlDB.Exec(`RESTORE data.* FROM $1 WITH into_db = 'data2'`, localFoo)
+		sqlDB.CheckQueryResults(`SELECT * FROM data2.s.bank`, expected)
+		sqlDB.CheckQueryResults(`SELECT * FROM data2.bank`, expectedPublic)
+	})
+}
+
 func TestBackupRestorePermissions(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
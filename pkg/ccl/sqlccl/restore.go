@@ -43,6 +43,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
+// tableRewriteMap maps the ID of a descriptor in the backup to its rewrite.
+// Databases and user-defined schemas are also assigned an entry, whose
+// TableID field holds their new ID.
 type tableRewriteMap map[sqlbase.ID]*jobs.RestoreDetails_TableRewrite
 
 const (
@@ -177,6 +180,23 @@ func allocateTableRewrites(
 		}
 
 		for _, table := range tablesByID {
+			// The schema of the table, if any, is identified by its name in
+			// the target database.
+			var schemaName string
+			if table.ParentSchemaID != 0 {
+				database, ok := databasesByID[table.ParentID]
+				if !ok {
+					return errors.Errorf("no database with ID %d in backup for table %q",
+						table.ParentID, table.Name)
+				}
+				schema, ok := database.FindSchemaByID(table.ParentSchemaID)
+				if !ok {
+					return errors.Errorf("no schema with ID %d in backup for table %q",
+						table.ParentSchemaID, table.Name)
+				}
+				schemaName = schema.Name
+			}
+
 			var targetDB string
 			if override, ok := opts[restoreOptIntoDB]; ok {
 				targetDB = override
@@ -210,10 +230,28 @@ func allocateTableRewrites(
 					parentID = sqlbase.ID(newParentID)
 				}
 
+				parentDB, err := sqlbase.GetDatabaseDescFromID(ctx, txn, parentID)
+				if err != nil {
+					return errors.Wrapf(err, "failed to lookup parent DB %d", parentID)
+				}
+
+				// Tables of user-defined schemas are restored into the schema with
+				// the same name in the target database.
+				namespaceParentID := parentID
+				if schemaName != "" {
+					schema, ok := parentDB.FindSchema(schemaName)
+					if !ok {
+						return errors.Errorf("a schema named %q needs to exist in database %q to restore table %q",
+							schemaName, targetDB, table.Name)
+					}
+					namespaceParentID = schema.ID
+					tableRewrites[table.ParentSchemaID] = &jobs.RestoreDetails_TableRewrite{TableID: schema.ID}
+				}
+
 				// Check that the table name is _not_ in use.
 				// This would fail the CPut later anyway, but this yields a prettier error.
 				{
-					nameKey := sqlbase.MakeNameMetadataKey(parentID, table.Name)
+					nameKey := sqlbase.MakeNameMetadataKey(namespaceParentID, table.Name)
 					res, err := txn.Get(ctx, nameKey)
 					if err != nil {
 						return err
@@ -226,12 +264,11 @@ func allocateTableRewrites(
 				// Check privileges. These will be checked again in the transaction
 				// that actually writes the new table descriptors.
 				{
-					parentDB, err := sqlbase.GetDatabaseDescFromID(ctx, txn, parentID)
-					if err != nil {
-						return errors.Wrapf(err, "failed to lookup parent DB %d", parentID)
+					var target sqlbase.DescriptorProto = parentDB
+					if schema, ok := parentDB.FindSchema(schemaName); ok {
+						target = schema
 					}
-
-					if err := p.CheckPrivilege(parentDB, privilege.CREATE); err != nil {
+					if err := p.CheckPrivilege(target, privilege.CREATE); err != nil {
 						return err
 					}
 				}
@@ -267,6 +304,17 @@ func allocateTableRewrites(
 		for _, tableID := range needsNewParentIDs[db.Name] {
 			tableRewrites[tableID] = &jobs.RestoreDetails_TableRewrite{ParentID: newID}
 		}
+		for _, schema := range db.Schemas {
+			if sql.IsTempSchemaName(schema.Name) {
+				// Temporary schemas are not restored; see restore().
+				continue
+			}
+			newSchemaID, err := sql.GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+			if err != nil {
+				return nil, err
+			}
+			tableRewrites[schema.ID] = &jobs.RestoreDetails_TableRewrite{TableID: newSchemaID}
+		}
 	}
 
 	tables := make([]*sqlbase.TableDescriptor, 0, len(tablesByID))
@@ -296,6 +344,13 @@ func rewriteTableDescs(tables []*sqlbase.TableDescriptor, tableRewrites tableRew
 		}
 		table.ID = tableRewrite.TableID
 		table.ParentID = tableRewrite.ParentID
+		if table.ParentSchemaID != 0 {
+			schemaRewrite, ok := tableRewrites[table.ParentSchemaID]
+			if !ok {
+				return errors.Errorf("missing schema rewrite for table %q", table.Name)
+			}
+			table.ParentSchemaID = schemaRewrite.TableID
+		}
 
 		if err := table.ForeachNonDropIndex(func(index *sqlbase.IndexDescriptor) error {
 			// Verify that for any interleaved index being restored, the interleave
@@ -717,24 +772,35 @@ func restoreTableDescs(
 		for _, desc := range databases {
 			// TODO(dt): support restoring privs.
 			desc.Privileges = sqlbase.NewDefaultPrivilegeDescriptor()
+			for i := range desc.Schemas {
+				desc.Schemas[i].Privileges = sqlbase.NewDefaultPrivilegeDescriptor()
+			}
 			wroteDBs[desc.ID] = desc
 			b.CPut(sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(desc), nil)
 			b.CPut(sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, desc.Name), desc.ID, nil)
 		}
 		for _, table := range tables {
+			// The privileges of the tables of user-defined schemas are copied
+			// from their schema instead.
+			privilegeParent := func(db *sqlbase.DatabaseDescriptor) sqlbase.DescriptorProto {
+				if schema, ok := db.FindSchemaByID(table.ParentSchemaID); ok {
+					return schema
+				}
+				return db
+			}
 			if wrote, ok := wroteDBs[table.ParentID]; ok {
-				table.Privileges = wrote.GetPrivileges()
+				table.Privileges = privilegeParent(wrote).GetPrivileges()
 			} else {
 				parentDB, err := sqlbase.GetDatabaseDescFromID(ctx, txn, table.ParentID)
 				if err != nil {
 					return errors.Wrapf(err, "failed to lookup parent DB %d", table.ParentID)
 				}
-				if err := sql.CheckPrivilege(user, parentDB, privilege.CREATE); err != nil {
+				if err := sql.CheckPrivilege(user, privilegeParent(parentDB), privilege.CREATE); err != nil {
 					return err
 				}
 				// Default is to copy privs from restoring parent db, like CREATE TABLE.
 				// TODO(dt): Make this more configurable.
-				table.Privileges = parentDB.GetPrivileges()
+				table.Privileges = privilegeParent(parentDB).GetPrivileges()
 			}
 			b.CPut(table.GetDescMetadataKey(), sqlbase.WrapDescriptor(table), nil)
 			b.CPut(table.GetNameMetadataKey(), table.ID, nil)
@@ -810,6 +876,19 @@ func restore(
 		if dbDesc := desc.GetDatabase(); dbDesc != nil {
 			if rewrite, ok := tableRewrites[dbDesc.ID]; ok {
 				dbDesc.ID = rewrite.TableID
+				// The temporary schemas of the database belong to sessions of
+				// the backed up cluster, and are thus left out.
+				schemas := dbDesc.Schemas[:0]
+				for _, schema := range dbDesc.Schemas {
+					if sql.IsTempSchemaName(schema.Name) {
+						continue
+					}
+					if rewrite, ok := tableRewrites[schema.ID]; ok {
+						schema.ID = rewrite.TableID
+					}
+					schemas = append(schemas, schema)
+				}
+				dbDesc.Schemas = schemas
 				databases = append(databases, dbDesc)
 			}
 		}
@@ -9,6 +9,7 @@
 package sqlccl
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/pkg/errors"
@@ -43,10 +44,19 @@ func descriptorsMatchingTargets(
 	}
 
 	type table struct {
+		// schema is empty for the public schema.
+		schema   string
 		name     string
 		validity validity
 	}
 
+	dbNames := make(map[string]struct{})
+	for _, desc := range descriptors {
+		if dbDesc := desc.GetDatabase(); dbDesc != nil {
+			dbNames[dbDesc.Name] = struct{}{}
+		}
+	}
+
 	tablesByDatabase := make(map[string][]table, len(targets.Tables))
 	for _, pattern := range targets.Tables {
 		var err error
@@ -62,8 +72,19 @@ func descriptorsMatchingTargets(
 					return nil, nil, err
 				}
 			}
-			db := string(p.DatabaseName)
+			db, schema := string(p.DatabaseName), ""
+			if p.PrefixOriginallySpecified {
+				// The name is of the form <database>.<schema>.<table>.
+				db, schema = string(p.PrefixName), string(p.DatabaseName)
+			} else if _, ok := dbNames[db]; !ok && !p.DBNameOriginallyOmitted && sessionDatabase != "" {
+				// The name may be of the form <schema>.<table>.
+				db, schema = sessionDatabase, db
+			}
+			if schema == tree.PublicSchemaName {
+				schema = ""
+			}
 			tablesByDatabase[db] = append(tablesByDatabase[db], table{
+				schema:   schema,
 				name:     string(p.TableName),
 				validity: maybeValid,
 			})
@@ -108,15 +129,25 @@ func descriptorsMatchingTargets(
 				return nil, nil, errors.Errorf("unknown ParentID: %d", tableDesc.ParentID)
 			}
 			normalizedDBName := dbDesc.Name
+			var schemaName string
+			if schema, ok := dbDesc.FindSchemaByID(tableDesc.ParentSchemaID); ok {
+				schemaName = schema.Name
+			}
+			// Temporary tables are private to the session that created them,
+			// which does not outlive the cluster they live in.
+			isTemp := sql.IsTempSchemaName(schemaName)
 			if tables, ok := tablesByDatabase[normalizedDBName]; ok {
 				for i := range tables {
-					if tables[i].name == tableDesc.Name {
+					if tables[i].schema == schemaName && tables[i].name == tableDesc.Name {
+						if isTemp {
+							return nil, nil, errors.Errorf("cannot backup temporary table %q", tableDesc.Name)
+						}
 						tables[i].validity = valid
 						ret = append(ret, desc)
 						break
 					}
 				}
-			} else if _, ok := starByDatabase[normalizedDBName]; ok {
+			} else if _, ok := starByDatabase[normalizedDBName]; ok && !isTemp {
 				ret = append(ret, desc)
 			}
 		}
@@ -31,6 +31,10 @@ func TestDescriptorsMatchingTargets(t *testing.T) {
 		*sqlbase.WrapDescriptor(&sqlbase.DatabaseDescriptor{ID: 3, Name: "data"}),
 		*sqlbase.WrapDescriptor(&sqlbase.TableDescriptor{ID: 4, Name: "baz", ParentID: 3}),
 		*sqlbase.WrapDescriptor(&sqlbase.DatabaseDescriptor{ID: 5, Name: "empty"}),
+		*sqlbase.WrapDescriptor(&sqlbase.DatabaseDescriptor{ID: 6, Name: "scdb",
+			Schemas: []sqlbase.SchemaDescriptor{{ID: 7, Name: "sc"}}}),
+		*sqlbase.WrapDescriptor(&sqlbase.TableDescriptor{ID: 8, Name: "pub", ParentID: 6}),
+		*sqlbase.WrapDescriptor(&sqlbase.TableDescriptor{ID: 9, Name: "priv", ParentID: 6, ParentSchemaID: 7}),
 	}
 
 	tests := []struct {
@@ -84,6 +88,17 @@ func TestDescriptorsMatchingTargets(t *testing.T) {
 
 		{"", `TABLE system."foo"`, []string{"system", "foo"}, nil, ``},
 		{"system", `TABLE "foo"`, []string{"system", "foo"}, nil, ``},
+
+		{"", "DATABASE scdb", []string{"scdb", "pub", "priv"}, []string{"scdb"}, ``},
+		{"", "TABLE scdb.*", []string{"scdb", "pub", "priv"}, nil, ``},
+		{"", "TABLE scdb.priv", nil, nil, `table "priv" does not exist`},
+		{"", "TABLE scdb.sc.priv", []string{"scdb", "priv"}, nil, ``},
+		{"", "TABLE scdb.sc.pub", nil, nil, `table "pub" does not exist`},
+		{"", "TABLE scdb.public.pub", []string{"scdb", "pub"}, nil, ``},
+		{"scdb", "TABLE priv", nil, nil, `table "priv" does not exist`},
+		{"scdb", "TABLE sc.priv", []string{"scdb", "priv"}, nil, ``},
+		{"scdb", "TABLE sc.priv, pub", []string{"scdb", "pub", "priv"}, nil, ``},
+		{"scdb", "TABLE noexist.priv", nil, nil, `table "priv" does not exist`},
 		// TODO(dan): Enable these tests once #8862 is fixed.
 		// {"", `TABLE system."FOO"`, []string{"system"}},
 		// {"system", `TABLE "FOO"`, []string{"system"}},
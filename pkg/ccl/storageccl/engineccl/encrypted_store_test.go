@@ -0,0 +1,164 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package engineccl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// TestEncryptedStoreKeyRotation writes data to an encrypted store, and reads
+// it back after reopening the store with a new store key, and again once the
+// old store key is gone.
+func TestEncryptedStoreKeyRotation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	dbDir := filepath.Join(dir, "db")
+
+	key1 := filepath.Join(dir, "aes-128.key")
+	key2 := filepath.Join(dir, "aes-256.key")
+	if err := ioutil.WriteFile(key1, bytes.Repeat([]byte{1}, 16), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(key2, bytes.Repeat([]byte{2}, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	open := func(currentKey, oldKey string) (*engine.RocksDB, error) {
+		var extraOptions []byte
+		if currentKey != "" {
+			var err error
+			extraOptions, err = protoutil.Marshal(&baseccl.EncryptionOptions{
+				KeySource: baseccl.EncryptionKeySource_KeyFiles,
+				KeyFiles: &baseccl.EncryptionKeyFiles{
+					CurrentKey: currentKey,
+					OldKey:     oldKey,
+				},
+				DataKeyRotationPeriod: 3600,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return engine.NewRocksDB(
+			engine.RocksDBConfig{
+				Settings:     cluster.MakeTestingClusterSettings(),
+				Dir:          dbDir,
+				ExtraOptions: extraOptions,
+			},
+			engine.RocksDBCache{},
+		)
+	}
+
+	// The values don't compress well, so that they would show up verbatim in
+	// the files if they weren't encrypted.
+	keys := []engine.MVCCKey{
+		{Key: []byte("a")}, {Key: []byte("b")}, {Key: []byte("c")},
+	}
+	values := [][]byte{
+		[]byte("first value: 7d41a3e0c95b2f68"),
+		[]byte("second value: e1c4907ab35d26f8"),
+		[]byte("third value: 0f96d2b7a843ce15"),
+	}
+
+	checkValues := func(db *engine.RocksDB, n int) {
+		for i := 0; i < n; i++ {
+			v, err := db.Get(keys[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(v, values[i]) {
+				t.Fatalf("%s: expected %q, got %q", keys[i], values[i], v)
+			}
+		}
+	}
+
+	// writeValue writes and flushes the i-th value, so that it makes its way
+	// into both the WAL and an sstable.
+	writeValue := func(db *engine.RocksDB, i int) {
+		if err := db.Put(keys[i], values[i]); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Write data using the first store key.
+	db, err := open(key1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeValue(db, 0)
+	checkValues(db, 1)
+	db.Close()
+
+	// Rotate the store key. The files written before the rotation are still
+	// readable, and new files use a new data key.
+	db, err = open(key2, key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValues(db, 1)
+	writeValue(db, 1)
+	checkValues(db, 2)
+	db.Close()
+
+	// The data keys registry is now encrypted with the second store key, so
+	// the first one is no longer needed.
+	db, err = open(key2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValues(db, 2)
+	writeValue(db, 2)
+	checkValues(db, 3)
+	db.Close()
+
+	// Reusing a retired store key is not allowed.
+	if _, err := open(key1, key2); !testutils.IsError(err, "already exists as an inactive key") {
+		t.Fatalf("expected error reusing an inactive store key, got %v", err)
+	}
+
+	// The store can't be opened without encryption options.
+	if _, err := open("", ""); !testutils.IsError(err, "encryption was used on this store before") {
+		t.Fatalf("expected error opening the store without encryption, got %v", err)
+	}
+
+	// None of the values can be found in plaintext on disk.
+	if err := filepath.Walk(dbDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			if bytes.Contains(contents, v) {
+				t.Errorf("%s contains %q in plaintext", path, v)
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
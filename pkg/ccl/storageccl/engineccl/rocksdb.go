@@ -21,6 +21,7 @@ import (
 
 // #cgo CPPFLAGS: -I../../../../c-deps/libroach/include
 // #cgo LDFLAGS: -lroachccl
+// #cgo LDFLAGS: -lcryptopp
 // #cgo LDFLAGS: -lroach
 // #cgo LDFLAGS: -lprotobuf
 // #cgo LDFLAGS: -lrocksdb
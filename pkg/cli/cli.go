@@ -153,6 +153,29 @@ func AddCmd(c *cobra.Command) {
 	cockroachCmd.AddCommand(c)
 }
 
+// StartCmd and DebugCmd are exported so that CCL code can register
+// enterprise-only flags and subcommands.
+var (
+	StartCmd = startCmd
+	DebugCmd = debugCmd
+)
+
+// AddPersistentPreRunE adds 'fn' as a persistent pre-run function to 'cmd'.
+// If the command has an existing pre-run function, it is called before 'fn'.
+// This allows an arbitrary number of pre-run functions, ordered by the calls
+// to AddPersistentPreRunE (usually package init order).
+func AddPersistentPreRunE(cmd *cobra.Command, fn func(*cobra.Command, []string) error) {
+	wrapped := cmd.PersistentPreRunE
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if wrapped != nil {
+			if err := wrapped(cmd, args); err != nil {
+				return err
+			}
+		}
+		return fn(cmd, args)
+	}
+}
+
 // Run ...
 func Run(args []string) error {
 	cockroachCmd.SetArgs(args)
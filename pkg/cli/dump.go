@@ -38,7 +38,8 @@ var dumpCmd = &cobra.Command{
 	Short: "dump sql tables\n",
 	Long: `
 Dump SQL tables of a cockroach database. If the table name
-is omitted, dump all tables in the database.
+is omitted, dump all tables in the database. Tables of user-defined
+schemas are designated as <schema>.<table>.
 `,
 	RunE: MaybeDecorateGRPCError(runDump),
 }
@@ -98,6 +99,9 @@ func runDump(cmd *cobra.Command, args []string) error {
 	w := os.Stdout
 
 	if dumpCtx.dumpMode != dumpDataOnly {
+		if err := dumpCreateSchemas(w, mds); err != nil {
+			return err
+		}
 		for i, md := range mds {
 			if i > 0 {
 				fmt.Fprintln(w)
@@ -165,16 +169,25 @@ func getDumpMetadata(
 		clusterTS = asOf
 	}
 
+	var names []tree.TableName
 	if tableNames == nil {
-		tableNames, err = getTableNames(conn, dbName, clusterTS)
+		names, err = getTableNames(conn, dbName, clusterTS)
 		if err != nil {
 			return nil, "", err
 		}
+	} else {
+		for _, tableName := range tableNames {
+			name, err := resolveTableName(conn, dbName, tableName, clusterTS)
+			if err != nil {
+				return nil, "", err
+			}
+			names = append(names, name)
+		}
 	}
 
-	mds = make([]tableMetadata, len(tableNames))
-	for i, tableName := range tableNames {
-		md, err := getMetadataForTable(conn, dbName, tableName, clusterTS)
+	mds = make([]tableMetadata, len(names))
+	for i := range names {
+		md, err := getMetadataForTable(conn, &names[i], clusterTS)
 		if err != nil {
 			return nil, "", err
 		}
@@ -184,13 +197,34 @@ func getDumpMetadata(
 	return mds, clusterTS, nil
 }
 
-// getTableNames retrieves all tables names in the given database.
-func getTableNames(conn *sqlConn, dbName string, ts string) (tableNames []string, err error) {
+// resolveTableName resolves a table name given on the command line. A
+// name of the form <schema>.<table> designates a table of a user-defined
+// schema, unless the public schema contains a table with this exact name.
+func resolveTableName(
+	conn *sqlConn, dbName, tableName string, ts string,
+) (tree.TableName, error) {
+	name := tree.TableName{DatabaseName: tree.Name(dbName), TableName: tree.Name(tableName)}
+	idx := strings.IndexByte(tableName, '.')
+	if idx < 0 {
+		return name, nil
+	}
+	if _, err := getTableID(conn, &name, ts); err != io.EOF {
+		return name, err
+	}
+	name.SchemaName = tree.Name(tableName[:idx])
+	name.TableName = tree.Name(tableName[idx+1:])
+	return name, nil
+}
+
+// getTableNames retrieves all tables names in the given database,
+// including the tables of its user-defined schemas.
+func getTableNames(conn *sqlConn, dbName string, ts string) (tableNames []tree.TableName, err error) {
 	rows, err := conn.Query(fmt.Sprintf(`
 		SELECT TABLE_NAME
 		FROM "".information_schema.tables
 		AS OF SYSTEM TIME '%s'
-		WHERE TABLE_SCHEMA = $1
+		WHERE TABLE_CATALOG = 'def'
+			AND TABLE_SCHEMA = $1
 		`, ts), []driver.Value{dbName})
 	if err != nil {
 		return nil, err
@@ -208,90 +242,114 @@ func getTableNames(conn *sqlConn, dbName string, ts string) (tableNames []string
 		if !ok {
 			return nil, fmt.Errorf("unexpected value: %T", nameI)
 		}
-		tableNames = append(tableNames, name)
+		tableNames = append(tableNames, tree.TableName{
+			DatabaseName: tree.Name(dbName),
+			TableName:    tree.Name(name),
+		})
 	}
 
 	if err := rows.Close(); err != nil {
 		return nil, err
 	}
 
-	return tableNames, nil
-}
-
-func getMetadataForTable(
-	conn *sqlConn, dbName, tableName string, ts string,
-) (tableMetadata, error) {
-	name := &tree.TableName{DatabaseName: tree.Name(dbName), TableName: tree.Name(tableName)}
-
-	// Fetch table ID.
-	vals, err := conn.QueryRow(fmt.Sprintf(`
-		SELECT table_id
+	// The tables of the user-defined schemas are listed separately, so
+	// that they are ordered by schema. Temporary tables are private to the
+	// sessions which created them, and are not dumped.
+	rows, err = conn.Query(fmt.Sprintf(`
+		SELECT schema_name, name
 		FROM %s.crdb_internal.tables
 		AS OF SYSTEM TIME '%s'
-		WHERE DATABASE_NAME = $1
-			AND NAME = $2
-		`, tree.Name(dbName).String(), ts), []driver.Value{dbName, tableName})
+		WHERE database_name = $1
+			AND schema_name != 'public'
+			AND schema_name !~ '^pg_temp_'
+			AND state = 'PUBLIC'
+		ORDER BY schema_name, name
+		`, tree.Name(dbName).String(), ts), []driver.Value{dbName})
 	if err != nil {
-		if err == io.EOF {
-			return tableMetadata{}, errors.Errorf("relation %s does not exist", name)
-		}
-		return tableMetadata{}, err
+		return nil, err
 	}
-	tableID := vals[0].(int64)
 
-	// Fetch column types.
-	rows, err := conn.Query(fmt.Sprintf(`
-		SELECT COLUMN_NAME, DATA_TYPE
-		FROM "".information_schema.columns
-		AS OF SYSTEM TIME '%s'
-		WHERE TABLE_SCHEMA = $1
-			AND TABLE_NAME = $2
-		`, ts), []driver.Value{dbName, tableName})
-	if err != nil {
-		return tableMetadata{}, err
-	}
 	vals = make([]driver.Value, 2)
-	coltypes := make(map[string]string)
-	var colnames bytes.Buffer
 	for {
 		if err := rows.Next(vals); err == io.EOF {
 			break
 		} else if err != nil {
-			return tableMetadata{}, err
+			return nil, err
 		}
-		nameI, typI := vals[0], vals[1]
-		name, ok := nameI.(string)
+		schema, ok := vals[0].(string)
 		if !ok {
-			return tableMetadata{}, fmt.Errorf("unexpected value: %T", nameI)
+			return nil, fmt.Errorf("unexpected value: %T", vals[0])
 		}
-		typ, ok := typI.(string)
+		name, ok := vals[1].(string)
 		if !ok {
-			return tableMetadata{}, fmt.Errorf("unexpected value: %T", typI)
+			return nil, fmt.Errorf("unexpected value: %T", vals[1])
 		}
-		coltypes[name] = typ
-		if colnames.Len() > 0 {
-			colnames.WriteString(", ")
-		}
-		tree.FormatNode(&colnames, tree.FmtSimple, tree.Name(name))
+		tableNames = append(tableNames, tree.TableName{
+			DatabaseName: tree.Name(dbName),
+			SchemaName:   tree.Name(schema),
+			TableName:    tree.Name(name),
+		})
 	}
+
 	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return tableNames, nil
+}
+
+// getTableID retrieves the ID of the given table. io.EOF is returned if
+// the table does not exist.
+func getTableID(conn *sqlConn, name *tree.TableName, ts string) (int64, error) {
+	schema := name.Schema()
+	if schema == "" {
+		schema = tree.PublicSchemaName
+	}
+	vals, err := conn.QueryRow(fmt.Sprintf(`
+		SELECT table_id
+		FROM %s.crdb_internal.tables
+		AS OF SYSTEM TIME '%s'
+		WHERE DATABASE_NAME = $1
+			AND SCHEMA_NAME = $2
+			AND NAME = $3
+		`, name.DatabaseName.String(), ts), []driver.Value{name.Database(), schema, name.Table()})
+	if err != nil {
+		return 0, err
+	}
+	return vals[0].(int64), nil
+}
+
+func getMetadataForTable(conn *sqlConn, name *tree.TableName, ts string) (tableMetadata, error) {
+	dbName := name.Database()
+
+	// Fetch table ID.
+	tableID, err := getTableID(conn, name, ts)
+	if err != nil {
+		if err == io.EOF {
+			return tableMetadata{}, errors.Errorf("relation %s does not exist", name)
+		}
 		return tableMetadata{}, err
 	}
 
-	vals, err = conn.QueryRow(fmt.Sprintf(`
+	vals, err := conn.QueryRow(fmt.Sprintf(`
 		SELECT create_statement, descriptor_type = 'view'
 		FROM %s.crdb_internal.create_statements
 		AS OF SYSTEM TIME '%s'
-		WHERE descriptor_name = $1
-			AND database_name = $2
-		`, tree.Name(dbName).String(), ts), []driver.Value{tableName, dbName})
+		WHERE descriptor_id = $1
+		`, tree.Name(dbName).String(), ts), []driver.Value{tableID})
 	if err != nil {
 		return tableMetadata{}, err
 	}
 	create := vals[0].(string)
 	descType := vals[1].(bool)
 
-	rows, err = conn.Query(fmt.Sprintf(`
+	// Fetch column types.
+	colnames, colTypes, err := getColumns(conn, name, ts)
+	if err != nil {
+		return tableMetadata{}, err
+	}
+
+	rows, err := conn.Query(fmt.Sprintf(`
 		SELECT dependson_id
 		FROM %s.crdb_internal.backward_dependencies
 		AS OF SYSTEM TIME '%s'
@@ -319,14 +377,92 @@ func getMetadataForTable(
 	return tableMetadata{
 		ID:          tableID,
 		name:        name,
-		columnNames: colnames.String(),
-		columnTypes: coltypes,
+		columnNames: colnames,
+		columnTypes: colTypes,
 		createStmt:  create,
 		dependsOn:   refs,
 		isView:      descType,
 	}, nil
 }
 
+// getColumns retrieves the names of the columns of a table, excluding the
+// computed columns, and the types of its columns.
+func getColumns(
+	conn *sqlConn, name *tree.TableName, ts string,
+) (string, map[string]string, error) {
+	// information_schema presents databases as schemas of the "def"
+	// catalog, and user-defined schemas as schemas of the catalog named
+	// after their database.
+	catalog, schema := "def", name.Database()
+	if name.Schema() != "" {
+		catalog, schema = name.Database(), name.Schema()
+	}
+	rows, err := conn.Query(fmt.Sprintf(`
+		SELECT COLUMN_NAME, DATA_TYPE, IS_GENERATED = 'ALWAYS'
+		FROM "".information_schema.columns
+		AS OF SYSTEM TIME '%s'
+		WHERE TABLE_CATALOG = $1
+			AND TABLE_SCHEMA = $2
+			AND TABLE_NAME = $3
+		`, ts), []driver.Value{catalog, schema, name.Table()})
+	if err != nil {
+		return "", nil, err
+	}
+	vals := make([]driver.Value, 3)
+	colTypes := make(map[string]string)
+	var names bytes.Buffer
+	for {
+		if err := rows.Next(vals); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", nil, err
+		}
+		nameI, typI := vals[0], vals[1]
+		name, ok := nameI.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("unexpected value: %T", nameI)
+		}
+		typ, ok := typI.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("unexpected value: %T", typI)
+		}
+		colTypes[name] = typ
+		// The values of computed columns are computed again when the rows
+		// are inserted, so they aren't dumped.
+		if generated, ok := vals[2].(bool); ok && generated {
+			continue
+		}
+		if names.Len() > 0 {
+			names.WriteString(", ")
+		}
+		tree.FormatNode(&names, tree.FmtSimple, tree.Name(name))
+	}
+	if err := rows.Close(); err != nil {
+		return "", nil, err
+	}
+	return names.String(), colTypes, nil
+}
+
+// dumpCreateSchemas dumps the CREATE statements of the user-defined
+// schemas of the specified tables to w.
+func dumpCreateSchemas(w io.Writer, mds []tableMetadata) error {
+	seen := make(map[tree.Name]bool)
+	var schemas []string
+	for _, md := range mds {
+		if md.name.SchemaName != "" && !seen[md.name.SchemaName] {
+			seen[md.name.SchemaName] = true
+			schemas = append(schemas, md.name.SchemaName.String())
+		}
+	}
+	sort.Strings(schemas)
+	for _, schema := range schemas {
+		if _, err := fmt.Fprintf(w, "CREATE SCHEMA %s;\n\n", schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // dumpCreateTable dumps the CREATE statement of the specified table to w.
 func dumpCreateTable(w io.Writer, md tableMetadata) error {
 	if _, err := w.Write([]byte(md.createStmt)); err != nil {
@@ -345,7 +481,8 @@ const (
 
 // dumpTableData dumps the data of the specified table to w.
 func dumpTableData(w io.Writer, conn *sqlConn, clusterTS string, md tableMetadata) error {
-	bs := fmt.Sprintf("SELECT * FROM %s AS OF SYSTEM TIME '%s' ORDER BY PRIMARY KEY %[1]s",
+	bs := fmt.Sprintf("SELECT %s FROM %s AS OF SYSTEM TIME '%s' ORDER BY PRIMARY KEY %[2]s",
+		md.columnNames,
 		md.name,
 		clusterTS,
 	)
@@ -499,7 +636,13 @@ func dumpTableData(w io.Writer, conn *sqlConn, clusterTS string, md tableMetadat
 }
 
 func writeInserts(w io.Writer, md tableMetadata, inserts []string) {
-	fmt.Fprintf(w, "\nINSERT INTO %s (%s) VALUES", md.name.TableName, md.columnNames)
+	// The tables of user-defined schemas are qualified with their schema.
+	name := tree.TableName{
+		SchemaName:              md.name.SchemaName,
+		TableName:               md.name.TableName,
+		DBNameOriginallyOmitted: true,
+	}
+	fmt.Fprintf(w, "\nINSERT INTO %s (%s) VALUES", &name, md.columnNames)
 	for idx, values := range inserts {
 		if idx > 0 {
 			fmt.Fprint(w, ",")
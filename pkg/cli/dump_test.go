@@ -904,3 +904,113 @@ INSERT INTO t (i) VALUES
 		t.Fatalf("expected: %s\ngot: %s", expect, out)
 	}
 }
+
+// TestDumpComputedColumn tests that the values of computed columns are not
+// dumped, since they are computed again when the rows are inserted.
+func TestDumpComputedColumn(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newCLITest(cliTestParams{t: t})
+	defer c.cleanup()
+
+	const create = `
+	CREATE DATABASE d;
+	CREATE TABLE d.t (
+		i int PRIMARY KEY,
+		j int AS (i * 2) STORED,
+		s string
+	);
+	INSERT INTO d.t (i, s) VALUES (1, 'a'), (2, 'b');
+`
+
+	c.RunWithArgs([]string{"sql", "-e", create})
+
+	out, err := c.RunWithCapture("dump d t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expect = `dump d t
+CREATE TABLE t (
+	i INT NOT NULL,
+	j INT NULL AS (i * 2) STORED,
+	s STRING NULL,
+	CONSTRAINT "primary" PRIMARY KEY (i ASC),
+	FAMILY "primary" (i, j, s)
+);
+
+INSERT INTO t (i, s) VALUES
+	(1, 'a'),
+	(2, 'b');
+`
+
+	if out != expect {
+		t.Fatalf("expected: %s\ngot: %s", expect, out)
+	}
+}
+
+// TestDumpSchema tests that the tables of user-defined schemas are dumped
+// along with their schema.
+func TestDumpSchema(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newCLITest(cliTestParams{t: t})
+	defer c.cleanup()
+
+	const create = `
+	CREATE DATABASE d;
+	CREATE SCHEMA d.s;
+	CREATE TABLE d.t (i int PRIMARY KEY);
+	CREATE TABLE d.s.u (i int PRIMARY KEY);
+	INSERT INTO d.t VALUES (1);
+	INSERT INTO d.s.u VALUES (2);
+`
+
+	c.RunWithArgs([]string{"sql", "-e", create})
+
+	out, err := c.RunWithCapture("dump d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expect = `dump d
+CREATE SCHEMA s;
+
+CREATE TABLE s.u (
+	i INT NOT NULL,
+	CONSTRAINT "primary" PRIMARY KEY (i ASC),
+	FAMILY "primary" (i)
+);
+
+CREATE TABLE t (
+	i INT NOT NULL,
+	CONSTRAINT "primary" PRIMARY KEY (i ASC),
+	FAMILY "primary" (i)
+);
+
+INSERT INTO s.u (i) VALUES
+	(2);
+
+INSERT INTO t (i) VALUES
+	(1);
+`
+
+	if out != expect {
+		t.Fatalf("expected: %s\ngot: %s", expect, out)
+	}
+
+	out, err = c.RunWithCapture("dump d s.u --dump-mode=data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expectData = `dump d s.u --dump-mode=data
+
+INSERT INTO s.u (i) VALUES
+	(2);
+`
+
+	if out != expectData {
+		t.Fatalf("expected: %s\ngot: %s", expectData, out)
+	}
+}
@@ -155,6 +155,20 @@ func varFlag(f *pflag.FlagSet, value pflag.Value, flagInfo cliflags.FlagInfo) {
 	setFlagFromEnv(f, flagInfo)
 }
 
+// VarFlag registers a flag of an arbitrary pflag.Value type. It is exported
+// for use by CCL code.
+func VarFlag(f *pflag.FlagSet, value pflag.Value, flagInfo cliflags.FlagInfo) {
+	varFlag(f, value, flagInfo)
+}
+
+// GetServerCfgStores provides direct public access to the StoreSpecList inside
+// serverCfg. This is used by CCL code to populate some fields.
+//
+// WARNING: consider very carefully whether you should be using this.
+func GetServerCfgStores() base.StoreSpecList {
+	return serverCfg.Stores
+}
+
 func init() {
 	// Change the logging defaults for the main cockroach binary.
 	// The value is overridden after command-line parsing.
@@ -104,6 +104,8 @@ var defaultVals = map[string][]string{
 	"decimal":     decimalInputs,
 	"timestamp":   timestampInputs,
 	"timestamptz": timestampInputs,
+	"time":        timeInputs,
+	"timetz":      timeTZInputs,
 	"date":        dateInputs,
 	"inet":        inetInputs,
 }
@@ -171,6 +173,22 @@ var timestampInputs = []string{
 	"9004-10-19 10:23:54",
 }
 
+var timeInputs = []string{
+	"00:00:00",
+	"04:05:06",
+	"04:05:06.789",
+	"12:00:00.000001",
+	"23:59:59.999999",
+}
+
+var timeTZInputs = []string{
+	"00:00:00+00",
+	"04:05:06-08",
+	"04:05:06.789+05:30",
+	"12:00:00.000001-03:30",
+	"23:59:59.999999+14",
+}
+
 var dateInputs = []string{
 	"1999-01-08",
 	"0009-01-08",
@@ -221,6 +221,46 @@ func (c *Constraint) FromString(short string) error {
 	return nil
 }
 
+// StoreHasConstraint returns whether a store's attributes or node's locality
+// matches the key value pair in the constraint.
+func StoreHasConstraint(store roachpb.StoreDescriptor, c Constraint) bool {
+	if c.Key == "" {
+		for _, attrs := range []roachpb.Attributes{store.Attrs, store.Node.Attrs} {
+			for _, attr := range attrs.Attrs {
+				if attr == c.Value {
+					return true
+				}
+			}
+		}
+	} else {
+		for _, tier := range store.Node.Locality.Tiers {
+			if c.Key == tier.Key && c.Value == tier.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StoreSatisfiesConstraints returns whether a store satisfies all of the
+// required and prohibited constraints. Positive constraints only express a
+// preference, so they are ignored.
+func StoreSatisfiesConstraints(store roachpb.StoreDescriptor, constraints Constraints) bool {
+	for _, c := range constraints.Constraints {
+		switch c.Type {
+		case Constraint_REQUIRED:
+			if !StoreHasConstraint(store, c) {
+				return false
+			}
+		case Constraint_PROHIBITED:
+			if StoreHasConstraint(store, c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 var _ yaml.Marshaler = Constraints{}
 var _ yaml.Unmarshaler = &Constraints{}
 
@@ -249,6 +289,145 @@ func (c *Constraints) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// ConjunctionString returns the constraints in comma-separated shorthand
+// notation, which is how each conjunction of per-replica constraints is
+// written in YAML.
+func (c Constraints) ConjunctionString() string {
+	short := make([]string, len(c.Constraints))
+	for i, c := range c.Constraints {
+		short[i] = c.String()
+	}
+	return strings.Join(short, ",")
+}
+
+// parseConstraintsConjunction is the inverse of Constraints.ConjunctionString.
+func parseConstraintsConjunction(s string) ([]Constraint, error) {
+	shortConstraints := strings.Split(s, ",")
+	constraints := make([]Constraint, len(shortConstraints))
+	for i, short := range shortConstraints {
+		short = strings.TrimSpace(short)
+		if short == "" {
+			return nil, errors.Errorf("constraints %q contain an empty constraint", s)
+		}
+		if err := constraints[i].FromString(short); err != nil {
+			return nil, err
+		}
+	}
+	return constraints, nil
+}
+
+// zoneConstraintsYAML is the YAML representation of the constraints in a
+// ZoneConfig. A list of constraints, e.g. [+region=us-east1, -ssd], applies to
+// all replicas and corresponds to ZoneConfig.Constraints. A map from
+// comma-separated constraints to replica counts, e.g.
+// {"+region=us-east1,+ssd": 2, +region=us-west1: 1}, corresponds to
+// ZoneConfig.ReplicaConstraints.
+type zoneConstraintsYAML struct {
+	constraints        Constraints
+	replicaConstraints []Constraints
+}
+
+var _ yaml.Marshaler = zoneConstraintsYAML{}
+var _ yaml.Unmarshaler = &zoneConstraintsYAML{}
+
+// MarshalYAML implements yaml.Marshaler.
+func (c zoneConstraintsYAML) MarshalYAML() (interface{}, error) {
+	if len(c.replicaConstraints) == 0 {
+		return c.constraints.MarshalYAML()
+	}
+	short := make(yaml.MapSlice, len(c.replicaConstraints))
+	for i, constraints := range c.replicaConstraints {
+		short[i] = yaml.MapItem{
+			Key:   constraints.ConjunctionString(),
+			Value: constraints.NumReplicas,
+		}
+	}
+	return short, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *zoneConstraintsYAML) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var constraints Constraints
+	if err := unmarshal(&constraints); err == nil {
+		*c = zoneConstraintsYAML{constraints: constraints}
+		return nil
+	}
+	var short yaml.MapSlice
+	if err := unmarshal(&short); err != nil {
+		return errors.New("constraints must be a list of constraints or a map from " +
+			"comma-separated constraints to the number of replicas they apply to")
+	}
+	replicaConstraints := make([]Constraints, len(short))
+	for i, item := range short {
+		key, ok := item.Key.(string)
+		if !ok {
+			return errors.Errorf("constraints must be strings, not %v", item.Key)
+		}
+		numReplicas, ok := item.Value.(int)
+		if !ok {
+			return errors.Errorf("number of replicas for constraints %q must be an integer, not %v",
+				key, item.Value)
+		}
+		conjunction, err := parseConstraintsConjunction(key)
+		if err != nil {
+			return err
+		}
+		replicaConstraints[i] = Constraints{
+			NumReplicas: int32(numReplicas),
+			Constraints: conjunction,
+		}
+	}
+	*c = zoneConstraintsYAML{replicaConstraints: replicaConstraints}
+	return nil
+}
+
+// marshalableZoneConfig is the YAML representation of a ZoneConfig. It must be
+// kept in sync with the YAML-visible fields of ZoneConfig.
+type marshalableZoneConfig struct {
+	RangeMinBytes int64               `yaml:"range_min_bytes"`
+	RangeMaxBytes int64               `yaml:"range_max_bytes"`
+	GC            GCPolicy            `yaml:"gc"`
+	NumReplicas   int32               `yaml:"num_replicas"`
+	Constraints   zoneConstraintsYAML `yaml:"constraints,flow"`
+}
+
+func zoneConfigToMarshalable(z ZoneConfig) marshalableZoneConfig {
+	return marshalableZoneConfig{
+		RangeMinBytes: z.RangeMinBytes,
+		RangeMaxBytes: z.RangeMaxBytes,
+		GC:            z.GC,
+		NumReplicas:   z.NumReplicas,
+		Constraints: zoneConstraintsYAML{
+			constraints:        z.Constraints,
+			replicaConstraints: z.ReplicaConstraints,
+		},
+	}
+}
+
+var _ yaml.Marshaler = ZoneConfig{}
+var _ yaml.Unmarshaler = &ZoneConfig{}
+
+// MarshalYAML implements yaml.Marshaler.
+func (z ZoneConfig) MarshalYAML() (interface{}, error) {
+	return zoneConfigToMarshalable(z), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. Fields that are not present in
+// the YAML retain their existing values.
+func (z *ZoneConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	aux := zoneConfigToMarshalable(*z)
+	if err := unmarshal(&aux); err != nil {
+		return err
+	}
+	z.RangeMinBytes = aux.RangeMinBytes
+	z.RangeMaxBytes = aux.RangeMaxBytes
+	z.GC = aux.GC
+	z.NumReplicas = aux.NumReplicas
+	z.Constraints = aux.Constraints.constraints
+	z.ReplicaConstraints = aux.Constraints.replicaConstraints
+	return nil
+}
+
 // minRangeMaxBytes is the minimum value for range max bytes.
 const minRangeMaxBytes = 64 << 10 // 64 KB
 
@@ -313,6 +492,31 @@ func (z *ZoneConfig) Validate() error {
 	case 2:
 		return fmt.Errorf("at least 3 replicas are required for multi-replica configurations")
 	}
+	if z.Constraints.NumReplicas != 0 {
+		return fmt.Errorf("constraints that apply to all replicas cannot specify a number of replicas")
+	}
+	var constrainedReplicas int32
+	for _, constraints := range z.ReplicaConstraints {
+		if len(constraints.Constraints) == 0 {
+			return fmt.Errorf("constraints with a number of replicas cannot be empty")
+		}
+		if constraints.NumReplicas <= 0 {
+			return fmt.Errorf("constraints %q must apply to at least one replica",
+				constraints.ConjunctionString())
+		}
+		for _, c := range constraints.Constraints {
+			if c.Type == Constraint_POSITIVE {
+				return fmt.Errorf("constraints with a number of replicas must be required (+) "+
+					"or prohibited (-), but %q is neither", c)
+			}
+		}
+		constrainedReplicas += constraints.NumReplicas
+	}
+	if constrainedReplicas > z.NumReplicas {
+		return fmt.Errorf("the number of replicas specified in constraints (%d) "+
+			"cannot be greater than the number of replicas configured for the zone (%d)",
+			constrainedReplicas, z.NumReplicas)
+	}
 	if z.RangeMaxBytes < minRangeMaxBytes {
 		return fmt.Errorf("RangeMaxBytes %d less than minimum allowed %d",
 			z.RangeMaxBytes, minRangeMaxBytes)
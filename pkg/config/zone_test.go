@@ -63,6 +63,58 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"is greater than or equal to RangeMaxBytes",
 		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				ReplicaConstraints: []config.Constraints{
+					{NumReplicas: 2, Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Value: "a"}}},
+					{NumReplicas: 1, Constraints: []config.Constraint{{Type: config.Constraint_PROHIBITED, Value: "a"}}},
+				},
+			},
+			"",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				ReplicaConstraints: []config.Constraints{
+					{NumReplicas: 2, Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Value: "a"}}},
+					{NumReplicas: 2, Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Value: "b"}}},
+				},
+			},
+			"the number of replicas specified in constraints \\(4\\) cannot be greater than",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				ReplicaConstraints: []config.Constraints{
+					{NumReplicas: 0, Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Value: "a"}}},
+				},
+			},
+			"must apply to at least one replica",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				ReplicaConstraints: []config.Constraints{
+					{NumReplicas: 1, Constraints: []config.Constraint{{Type: config.Constraint_POSITIVE, Value: "a"}}},
+				},
+			},
+			"must be required \\(\\+\\) or prohibited \\(-\\)",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				ReplicaConstraints: []config.Constraints{
+					{NumReplicas: 1},
+				},
+			},
+			"constraints with a number of replicas cannot be empty",
+		},
 	}
 	for i, c := range testCases {
 		err := c.cfg.Validate()
@@ -191,6 +243,90 @@ constraints: [foo, +duck=foo, -duck=foo]
 	}
 }
 
+// TestZoneConfigReplicaConstraintsYAML makes sure that per-replica constraints
+// are correctly marshaled to YAML and back, and that they replace any
+// all-replica constraints (and vice versa) when unmarshaled.
+func TestZoneConfigReplicaConstraintsYAML(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	original := config.ZoneConfig{
+		RangeMinBytes: 1,
+		RangeMaxBytes: 1,
+		GC: config.GCPolicy{
+			TTLSeconds: 1,
+		},
+		NumReplicas: 3,
+		ReplicaConstraints: []config.Constraints{
+			{
+				NumReplicas: 2,
+				Constraints: []config.Constraint{
+					{Type: config.Constraint_REQUIRED, Key: "region", Value: "us-east"},
+					{Type: config.Constraint_PROHIBITED, Value: "hdd"},
+				},
+			},
+			{
+				NumReplicas: 1,
+				Constraints: []config.Constraint{
+					{Type: config.Constraint_REQUIRED, Key: "region", Value: "us-west"},
+				},
+			},
+		},
+	}
+
+	expected := `range_min_bytes: 1
+range_max_bytes: 1
+gc:
+  ttlseconds: 1
+num_replicas: 3
+constraints: {'+region=us-east,-hdd': 2, +region=us-west: 1}
+`
+
+	body, err := yaml.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != expected {
+		t.Fatalf("yaml.Marshal(%+v) = %s; not %s", original, body, expected)
+	}
+
+	unmarshaled := config.ZoneConfig{
+		Constraints: config.Constraints{
+			Constraints: []config.Constraint{{Value: "foo"}},
+		},
+	}
+	if err := yaml.UnmarshalStrict(body, &unmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(&unmarshaled, &original) {
+		t.Errorf("yaml.UnmarshalStrict(%q) = %+v; not %+v", body, unmarshaled, original)
+	}
+
+	if err := yaml.UnmarshalStrict([]byte("constraints: [+ssd]"), &unmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if len(unmarshaled.ReplicaConstraints) != 0 {
+		t.Errorf("expected per-replica constraints to be cleared, but got %+v",
+			unmarshaled.ReplicaConstraints)
+	}
+	if unmarshaled.NumReplicas != original.NumReplicas {
+		t.Errorf("expected num_replicas to be retained, but got %d", unmarshaled.NumReplicas)
+	}
+
+	for _, tc := range []struct {
+		yaml     string
+		expected string
+	}{
+		{`constraints: {"+a,,+b": 1}`, "contain an empty constraint"},
+		{`constraints: {+a: foo}`, "must be an integer"},
+		{`constraints: +a`, "must be a list of constraints or a map"},
+	} {
+		var zone config.ZoneConfig
+		if err := yaml.UnmarshalStrict([]byte(tc.yaml), &zone); !testutils.IsError(err, tc.expected) {
+			t.Errorf("%s: expected %q, but got %v", tc.yaml, tc.expected, err)
+		}
+	}
+}
+
 func TestZoneSpecifiers(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
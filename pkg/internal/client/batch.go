@@ -262,6 +262,7 @@ func (b *Batch) fillResults(ctx context.Context) error {
 			case *roachpb.ImportRequest:
 			case *roachpb.AdminScatterRequest:
 			case *roachpb.AddSSTableRequest:
+			case *roachpb.ClearRangeRequest:
 			}
 			// Fill up the resume span.
 			if result.Err == nil && reply != nil && reply.Header().ResumeSpan != nil {
@@ -665,6 +666,25 @@ func (b *Batch) writeBatch(s, e interface{}, data []byte) {
 	b.initResult(1, 0, notRaw, nil)
 }
 
+// clearRange is only exported on DB.
+func (b *Batch) clearRange(s, e interface{}) {
+	begin, err := marshalKey(s)
+	if err != nil {
+		b.initResult(0, 0, notRaw, err)
+		return
+	}
+	end, err := marshalKey(e)
+	if err != nil {
+		b.initResult(0, 0, notRaw, err)
+		return
+	}
+	req := &roachpb.ClearRangeRequest{
+		Span: roachpb.Span{Key: begin, EndKey: end},
+	}
+	b.appendReqs(req)
+	b.initResult(1, 0, notRaw, nil)
+}
+
 // addSSTable is only exported on DB.
 func (b *Batch) addSSTable(s, e interface{}, data []byte) {
 	begin, err := marshalKey(s)
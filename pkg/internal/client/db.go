@@ -357,6 +357,18 @@ func (db *DB) DelRange(ctx context.Context, begin, end interface{}) error {
 	return getOneErr(db.Run(ctx, b), b)
 }
 
+// ClearRange removes all values, including all of their versions, in the
+// span [begin, end). Unlike DelRange, it bypasses MVCC and may not be used
+// on keys which are still being read or written (e.g. only once the data
+// of a dropped table is past its GC TTL).
+//
+// begin and end can be either byte slices or strings.
+func (db *DB) ClearRange(ctx context.Context, begin, end interface{}) error {
+	b := &Batch{}
+	b.clearRange(begin, end)
+	return getOneErr(db.Run(ctx, b), b)
+}
+
 // AdminMerge merges the range containing key and the subsequent
 // range. After the merge operation is complete, the range containing
 // key will contain all of the key/value pairs of the subsequent range
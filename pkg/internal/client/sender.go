@@ -35,6 +35,17 @@ type SenderWithDistSQLBackdoor interface {
 	// GetTxnState returns the state that the TxnCoordSender has for a
 	// transaction. The bool is false is no state is found.
 	GetTxnState(txnID uuid.UUID) (roachpb.Transaction, bool)
+
+	// DisableRefreshes informs the TxnCoordSender that the transaction has
+	// performed reads which it hasn't seen, so its reads can no longer be
+	// refreshed in the event of a timestamp push.
+	DisableRefreshes(txn roachpb.Transaction)
+
+	// AugmentTxnState informs the TxnCoordSender about intents that were
+	// written on behalf of a transaction through another sender, and about the
+	// updated transaction proto returned by those writes. If the transaction is
+	// not tracked yet, it starts being tracked (and heartbeated).
+	AugmentTxnState(ctx context.Context, txn roachpb.Transaction, intents []roachpb.Span) error
 }
 
 // SenderFunc is an adapter to allow the use of ordinary functions
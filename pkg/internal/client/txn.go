@@ -95,6 +95,14 @@ type Txn struct {
 		// TODO(andrei): This is broken for DistSQL, which doesn't account for the
 		// requests it uses the transaction for.
 		commandCount int
+		// trackIntents is set on DistSQL leaf transactions that write. The spans
+		// of the intents written through the txn are then accumulated in
+		// intentSpans, to be passed back to the gateway's TxnCoordSender.
+		trackIntents bool
+		intentSpans  []roachpb.Span
+		// remoteWrites is set on root transactions that had writes performed on
+		// their behalf by DistSQL flows.
+		remoteWrites bool
 	}
 
 	// Set for DistSQL transactions that get errors that would otherwise be
@@ -323,6 +331,100 @@ func (txn *Txn) Proto() *roachpb.Transaction {
 	return &txn.mu.Proto
 }
 
+// DisableRefreshes prevents the transaction's reads from being refreshed
+// when its timestamp is pushed. It must be called before reading on behalf of
+// the transaction through a sender other than the transaction's own, e.g. by
+// DistSQL flows.
+func (txn *Txn) DisableRefreshes() {
+	txn.mu.Lock()
+	proto := txn.mu.Proto.Clone()
+	txn.mu.Unlock()
+	if sender, ok := txn.db.GetSender().(SenderWithDistSQLBackdoor); ok {
+		sender.DisableRefreshes(proto)
+	}
+}
+
+// EnableIntentTracking makes the transaction record the spans of the intents
+// written through it. Used by DistSQL processors writing on remote nodes,
+// whose requests bypass the TxnCoordSender.
+func (txn *Txn) EnableIntentTracking() {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	txn.mu.trackIntents = true
+}
+
+// TrackedWrites returns a copy of the transaction proto together with the
+// (merged) spans of the intents written through the transaction since
+// EnableIntentTracking was called.
+func (txn *Txn) TrackedWrites() (roachpb.Transaction, []roachpb.Span) {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	spans := append([]roachpb.Span(nil), txn.mu.intentSpans...)
+	spans, _ = roachpb.MergeSpans(spans)
+	return txn.mu.Proto.Clone(), spans
+}
+
+// PrepareForRemoteWrites must be called before the transaction's proto is
+// shipped to DistSQL flows which are going to write on its behalf. It writes
+// the transaction record (anchored at anchor, unless an anchor key was set
+// explicitly) if that hasn't happened yet, so that remote writers see a
+// Writing transaction, and it makes the TxnCoordSender track the transaction.
+//
+// spans must cover all the keys that the flows may write. They are registered
+// as intents with the TxnCoordSender before the flows run, so that the intents
+// are resolved when the transaction finishes even if the metadata reporting
+// the precise intents never makes it back to the gateway (e.g. because a flow
+// failed or the connection to its node broke).
+func (txn *Txn) PrepareForRemoteWrites(
+	ctx context.Context, anchor roachpb.Key, spans []roachpb.Span,
+) error {
+	txn.mu.Lock()
+	writing := txn.mu.Proto.Writing
+	if !writing && len(txn.mu.Proto.Key) == 0 {
+		if len(txn.mu.txnAnchorKey) != 0 {
+			anchor = txn.mu.txnAnchorKey
+		}
+		txn.mu.Proto.Key = anchor
+	}
+	key := txn.mu.Proto.Key
+	txn.mu.remoteWrites = true
+	txn.mu.Unlock()
+
+	if !writing {
+		var ba roachpb.BatchRequest
+		ba.Add(&roachpb.BeginTransactionRequest{Span: roachpb.Span{Key: key}})
+		if _, pErr := txn.Send(ctx, ba); pErr != nil {
+			return pErr.GoError()
+		}
+	}
+	txn.mu.Lock()
+	proto := txn.mu.Proto.Clone()
+	txn.mu.Unlock()
+	return txn.db.GetSender().(SenderWithDistSQLBackdoor).AugmentTxnState(ctx, proto, spans)
+}
+
+// AugmentRemoteWrites updates the transaction with the state returned by a
+// DistSQL flow that wrote on its behalf: the (possibly pushed) transaction
+// proto and the spans of the intents that were written. The intents are
+// already covered by the spans passed to PrepareForRemoteWrites; they're
+// passed along in case the flow wrote outside of those.
+//
+// If the transaction has been restarted with a new ID in the meantime (e.g.
+// because another flow ran into a TransactionAbortedError), the intents are
+// still handed to the TxnCoordSender under the old ID so that they get cleaned
+// up.
+func (txn *Txn) AugmentRemoteWrites(
+	ctx context.Context, remoteTxn roachpb.Transaction, intents []roachpb.Span,
+) error {
+	txn.mu.Lock()
+	if remoteTxn.ID == txn.mu.Proto.ID {
+		txn.mu.Proto.Update(&remoteTxn)
+		remoteTxn = txn.mu.Proto.Clone()
+	}
+	txn.mu.Unlock()
+	return txn.db.GetSender().(SenderWithDistSQLBackdoor).AugmentTxnState(ctx, remoteTxn, intents)
+}
+
 // IsSerializableRestart returns true if the transaction is serializable and
 // its timestamp has been pushed. Used to detect whether the txn will be
 // allowed to commit.
@@ -339,7 +441,7 @@ func (txn *Txn) IsSerializableRestart() bool {
 	// shouldn't be. Hopefully the proto initialization can be improved such that
 	// Timestamp is always set.
 	isTxnPushed := txn.Proto().Timestamp.WallTime != 0 &&
-		txn.Proto().Timestamp != txn.Proto().OrigTimestamp
+		txn.Proto().Timestamp != txn.Proto().ReadTimestamp()
 	return txn.Proto().Isolation == enginepb.SERIALIZABLE && isTxnPushed
 }
 
@@ -622,6 +724,55 @@ func (txn *Txn) rollback(ctx context.Context) *roachpb.Error {
 	return txn.sendEndTxnReq(ctx, false /* commit */, nil)
 }
 
+// SavepointToken identifies a point in a transaction's history created by
+// Savepoint.
+type SavepointToken struct {
+	txnID    uuid.UUID
+	epoch    uint32
+	writeSeq int32
+}
+
+// Savepoint marks the current point in the transaction, so that the writes
+// performed after it can later be undone by RollbackToSavepoint.
+func (txn *Txn) Savepoint() SavepointToken {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	txn.mu.Proto.WriteSeq++
+	return SavepointToken{
+		txnID:    txn.mu.Proto.ID,
+		epoch:    txn.mu.Proto.Epoch,
+		writeSeq: txn.mu.Proto.WriteSeq,
+	}
+}
+
+// RollbackToSavepoint undoes the writes performed by the transaction since
+// the given savepoint was created. Unlike Rollback, it leaves the
+// transaction open and the savepoint valid. It fails if the transaction has
+// been restarted since the savepoint was created, as the restart discarded
+// all of the transaction's writes regardless.
+func (txn *Txn) RollbackToSavepoint(ctx context.Context, sp SavepointToken) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	if txn.mu.finalized {
+		return errors.Errorf("cannot roll back to savepoint of finalized transaction")
+	}
+	if txn.mu.Proto.ID != sp.txnID || txn.mu.Proto.Epoch != sp.epoch {
+		return errors.Errorf("cannot roll back to savepoint across a transaction restart")
+	}
+	log.VEventf(ctx, 2, "rolling back to savepoint at write sequence %d", sp.writeSeq)
+	// The list of ignored ranges may be shared with other copies of the
+	// transaction proto, so it is never appended to in place.
+	ignored := make([]enginepb.IgnoredSeqNumRange, 0, len(txn.mu.Proto.IgnoredSeqNums)+1)
+	ignored = append(ignored, txn.mu.Proto.IgnoredSeqNums...)
+	txn.mu.Proto.IgnoredSeqNums = append(ignored, enginepb.IgnoredSeqNumRange{
+		Start: sp.writeSeq,
+		End:   txn.mu.Proto.WriteSeq,
+	})
+	// Writes performed from now on belong to a new scope.
+	txn.mu.Proto.WriteSeq++
+	return nil
+}
+
 // AddCommitTrigger adds a closure to be executed on successful commit
 // of the transaction.
 func (txn *Txn) AddCommitTrigger(trigger func()) {
@@ -962,6 +1113,14 @@ func (txn *Txn) Send(
 	txn.mu.Lock()
 	defer txn.mu.Unlock()
 
+	if txn.mu.trackIntents && haveTxnWrite {
+		// Intents can be laid down even if the batch failed, so they're recorded
+		// regardless of the error.
+		ba.IntentSpanIterate(br, func(key, endKey roachpb.Key) {
+			txn.mu.intentSpans = append(txn.mu.intentSpans, roachpb.Span{Key: key, EndKey: endKey})
+		})
+	}
+
 	// If we inserted a begin transaction request, remove it here. We also
 	// unset the flag writingTxnRecord flag in case another ever needs to
 	// be sent again (for instance, if we're aborted and need to restart).
@@ -1103,8 +1262,14 @@ func (txn *Txn) UpdateStateOnRemoteRetryableErr(ctx context.Context, pErr roachp
 	// transaction that had performed writes and hence started being tracked). If
 	// the TxnCoordSender were to have state, it'd be a bad thing that we're not
 	// updating it.
+	//
+	// The exception are transactions that had DistSQL flows write on their
+	// behalf; those are tracked by the TxnCoordSender, which keeps their
+	// intents across epochs. If the transaction was aborted, the
+	// TxnCoordSender's heartbeat loop finds out and cleans up.
 	txnID := pErr.GetTxn().ID
-	if _, ok := txn.db.GetSender().(SenderWithDistSQLBackdoor).GetTxnState(txnID); ok {
+	if _, ok := txn.db.GetSender().(SenderWithDistSQLBackdoor).GetTxnState(txnID); ok &&
+		!txn.mu.remoteWrites {
 		log.Fatalf(ctx, "unexpected state in TxnCoordSender for transaction in error: %s", pErr)
 	}
 
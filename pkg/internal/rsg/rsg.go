@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -229,6 +230,9 @@ func (r *RSG) GenerateRandomArg(typ types.T) string {
 	case types.Timestamp, types.TimestampTZ:
 		t := timeutil.Unix(0, r.Int63())
 		v = fmt.Sprintf(`'%s'`, t.Format(time.RFC3339Nano))
+	case types.Time, types.TimeTZ:
+		t := timeofday.FromInt(r.Int63())
+		v = fmt.Sprintf(`'%s'`, t)
 	case types.Bool:
 		v = boolArgs[r.Intn(2)]
 	case types.Date:
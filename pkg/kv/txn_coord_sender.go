@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -56,6 +57,63 @@ var maxIntents = settings.RegisterIntSetting(
 	100000,
 )
 
+// maxTxnRefreshSpansBytes is a threshold in bytes for the read spans
+// tracked by the coordinator over the lifetime of a transaction. Read
+// spans are refreshed when a SERIALIZABLE transaction's timestamp is
+// pushed in order to avoid a restart; transactions which read more than
+// this cannot be refreshed.
+var maxTxnRefreshSpansBytes = settings.RegisterIntSetting(
+	"kv.transaction.max_refresh_spans_bytes",
+	"maximum number of bytes used to track refresh spans in serializable transactions",
+	256*1000,
+)
+
+// txnRefreshState holds the spans read by a transaction through this
+// coordinator. If the transaction's timestamp is pushed, the spans are
+// checked for intervening writes using Refresh and RefreshRange requests,
+// which allows a SERIALIZABLE transaction to commit at the pushed
+// timestamp instead of restarting.
+//
+// Unlike txnMetadata, which only exists once a transaction has written,
+// refresh state is created with the transaction's first read. Read-only
+// transactions are never finished through the coordinator, so idle
+// refresh state is garbage collected after refreshStateTTL.
+type txnRefreshState struct {
+	// epoch is the transaction epoch in which the spans were read. Reads
+	// from earlier epochs are discarded when the transaction restarts.
+	epoch uint32
+	// spans are the key spans read in the current epoch.
+	spans []roachpb.Span
+	// spansBytes is the size of the keys in spans.
+	spansBytes int64
+	// invalid is set if the transaction performed reads which aren't
+	// reflected in spans, in which case its reads cannot be refreshed.
+	invalid bool
+	// lastUpdateNanos is the wall time at which the state was last updated.
+	lastUpdateNanos int64
+}
+
+// add records the spans of all reads in the batch.
+func (rs *txnRefreshState) add(ba roachpb.BatchRequest, maxBytes int64) {
+	if rs.invalid {
+		return
+	}
+	for _, union := range ba.Requests {
+		args := union.GetInner()
+		if !roachpb.IsReadOnly(args) || !roachpb.UpdatesTimestampCache(args) {
+			continue
+		}
+		span := args.Header()
+		rs.spans = append(rs.spans, span)
+		rs.spansBytes += int64(len(span.Key) + len(span.EndKey))
+	}
+	if rs.spansBytes > maxBytes {
+		rs.invalid = true
+		rs.spans = nil
+		rs.spansBytes = 0
+	}
+}
+
 // txnMetadata holds information about an ongoing transaction, as
 // seen from the perspective of this coordinator. It records all
 // keys (and key ranges) mutated as part of the transaction for
@@ -124,6 +182,11 @@ type TxnMetrics struct {
 	RestartsDeleteRange    *metric.Counter
 	RestartsSerializable   *metric.Counter
 	RestartsPossibleReplay *metric.Counter
+
+	// Counts of attempts to refresh a transaction's reads to a pushed
+	// timestamp in lieu of a restart.
+	RefreshSuccess *metric.Counter
+	RefreshFail    *metric.Counter
 }
 
 var (
@@ -157,6 +220,12 @@ var (
 	metaRestartsPossibleReplay = metric.Metadata{
 		Name: "txn.restarts.possiblereplay",
 		Help: "Number of restarts due to possible replays of command batches at the storage layer"}
+	metaRefreshSuccess = metric.Metadata{
+		Name: "txn.refresh.success",
+		Help: "Number of successful refreshes of a transaction's reads to a pushed timestamp"}
+	metaRefreshFail = metric.Metadata{
+		Name: "txn.refresh.fail",
+		Help: "Number of failed refreshes of a transaction's reads to a pushed timestamp"}
 )
 
 // MakeTxnMetrics returns a TxnMetrics struct that contains metrics whose
@@ -173,6 +242,8 @@ func MakeTxnMetrics(histogramWindow time.Duration) TxnMetrics {
 		RestartsDeleteRange:    metric.NewCounter(metaRestartsDeleteRange),
 		RestartsSerializable:   metric.NewCounter(metaRestartsSerializable),
 		RestartsPossibleReplay: metric.NewCounter(metaRestartsPossibleReplay),
+		RefreshSuccess:         metric.NewCounter(metaRefreshSuccess),
+		RefreshFail:            metric.NewCounter(metaRefreshFail),
 	}
 }
 
@@ -196,6 +267,8 @@ type TxnCoordSender struct {
 	txnMu             struct {
 		syncutil.Mutex
 		txns map[uuid.UUID]*txnMetadata // txn key to metadata
+		// refreshes holds the read spans of transactions, see txnRefreshState.
+		refreshes map[uuid.UUID]*txnRefreshState
 	}
 	linearizable bool // enables linearizable behaviour
 	stopper      *stop.Stopper
@@ -231,14 +304,148 @@ func NewTxnCoordSender(
 		metrics:           txnMetrics,
 	}
 	tc.txnMu.txns = map[uuid.UUID]*txnMetadata{}
+	tc.txnMu.refreshes = map[uuid.UUID]*txnRefreshState{}
 
 	ctx := tc.AnnotateCtx(context.Background())
 	tc.stopper.RunWorker(ctx, func(ctx context.Context) {
 		tc.printStatsLoop(ctx)
 	})
+	tc.stopper.RunWorker(ctx, func(ctx context.Context) {
+		tc.refreshStateGCLoop(ctx)
+	})
 	return tc
 }
 
+// refreshStateTTL returns the duration after which the refresh state of an
+// idle transaction which hasn't written is garbage collected.
+func (tc *TxnCoordSender) refreshStateTTL() time.Duration {
+	return 2 * tc.clientTimeout
+}
+
+// refreshStateGCLoop blocks and periodically removes the refresh state of
+// transactions which haven't been active within refreshStateTTL. Writing
+// transactions are exempt; their state is removed when they finish.
+func (tc *TxnCoordSender) refreshStateGCLoop(ctx context.Context) {
+	var gcTimer timeutil.Timer
+	defer gcTimer.Stop()
+	for {
+		gcTimer.Reset(tc.clientTimeout)
+		select {
+		case <-gcTimer.C:
+			gcTimer.Read = true
+			tc.txnMu.Lock()
+			horizon := tc.clock.PhysicalNow() - tc.refreshStateTTL().Nanoseconds()
+			for txnID, rs := range tc.txnMu.refreshes {
+				if _, ok := tc.txnMu.txns[txnID]; !ok && rs.lastUpdateNanos < horizon {
+					delete(tc.txnMu.refreshes, txnID)
+				}
+			}
+			tc.txnMu.Unlock()
+		case <-tc.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+// getRefreshStateLocked returns the refresh state for the transaction,
+// creating it if necessary.
+func (tc *TxnCoordSender) getRefreshStateLocked(txn *roachpb.Transaction) *txnRefreshState {
+	nowNanos := tc.clock.PhysicalNow()
+	rs, ok := tc.txnMu.refreshes[txn.ID]
+	if !ok {
+		rs = &txnRefreshState{epoch: txn.Epoch}
+		// The state of a transaction which has been idle for longer than
+		// refreshStateTTL may have been garbage collected. Since the state
+		// can't outlive the transaction's start by less than that, the state
+		// of a transaction older than clientTimeout is conservatively assumed
+		// to be missing earlier reads.
+		if nowNanos-txn.OrigTimestamp.WallTime >= tc.clientTimeout.Nanoseconds() {
+			rs.invalid = true
+		}
+		tc.txnMu.refreshes[txn.ID] = rs
+	} else if rs.epoch < txn.Epoch {
+		// The transaction has restarted and will redo its reads.
+		*rs = txnRefreshState{epoch: txn.Epoch}
+	}
+	rs.lastUpdateNanos = nowNanos
+	return rs
+}
+
+// DisableRefreshes is part of the SenderWithDistSQLBackdoor interface.
+func (tc *TxnCoordSender) DisableRefreshes(txn roachpb.Transaction) {
+	tc.txnMu.Lock()
+	defer tc.txnMu.Unlock()
+	if rs := tc.getRefreshStateLocked(&txn); rs.epoch == txn.Epoch {
+		rs.invalid = true
+		rs.spans = nil
+		rs.spansBytes = 0
+	}
+}
+
+// maybeRefreshReads attempts to move the transaction's reads up to its
+// (pushed) timestamp by sending Refresh and RefreshRange requests for all
+// spans it has read through this coordinator. On success, the returned
+// transaction's RefreshedTimestamp is forwarded to its timestamp, which
+// allows a SERIALIZABLE transaction to commit without a restart. The
+// boolean is false if the reads could not be refreshed.
+func (tc *TxnCoordSender) maybeRefreshReads(
+	ctx context.Context, txn roachpb.Transaction,
+) (roachpb.Transaction, bool) {
+	if txn.Isolation != enginepb.SERIALIZABLE || txn.WriteTooOld ||
+		!txn.ReadTimestamp().Less(txn.Timestamp) {
+		return txn, false
+	}
+
+	var spans []roachpb.Span
+	{
+		tc.txnMu.Lock()
+		rs := tc.getRefreshStateLocked(&txn)
+		invalid := rs.invalid || rs.epoch != txn.Epoch
+		if !invalid {
+			spans, _ = roachpb.MergeSpans(append([]roachpb.Span(nil), rs.spans...))
+		}
+		tc.txnMu.Unlock()
+		if invalid {
+			log.VEventf(ctx, 2, "cannot refresh reads of txn %s", txn.Short())
+			tc.metrics.RefreshFail.Inc(1)
+			return txn, false
+		}
+	}
+
+	refreshTxn := txn.Clone()
+	refreshTxn.RefreshedTimestamp.Forward(refreshTxn.Timestamp)
+	if len(spans) > 0 {
+		var ba roachpb.BatchRequest
+		ba.Txn = &refreshTxn
+		for _, span := range spans {
+			if len(span.EndKey) == 0 {
+				ba.Add(&roachpb.RefreshRequest{Span: span})
+			} else {
+				ba.Add(&roachpb.RefreshRangeRequest{Span: span})
+			}
+		}
+		log.VEventf(ctx, 2, "refreshing %d spans of txn %s to %s", len(spans), txn.Short(), txn.Timestamp)
+		if _, pErr := tc.wrapped.Send(ctx, ba); pErr != nil {
+			log.VEventf(ctx, 2, "failed to refresh reads of txn %s: %s", txn.Short(), pErr)
+			tc.metrics.RefreshFail.Inc(1)
+			return txn, false
+		}
+	}
+	tc.metrics.RefreshSuccess.Inc(1)
+	return refreshTxn, true
+}
+
+// isRefreshableRetryError returns whether the error is a retry error of a
+// SERIALIZABLE transaction caused only by its timestamp having been pushed,
+// which can be avoided by refreshing the transaction's reads.
+func isRefreshableRetryError(pErr *roachpb.Error) bool {
+	if pErr.GetTxn() == nil {
+		return false
+	}
+	tErr, ok := pErr.GetDetail().(*roachpb.TransactionRetryError)
+	return ok && tErr.Reason == roachpb.RETRY_SERIALIZABLE
+}
+
 // printStatsLoop blocks and periodically logs transaction statistics
 // (throughput, success rates, durations, ...). Note that this only captures
 // write txns, since read-only txns are stateless as far as TxnCoordSender is
@@ -434,6 +641,15 @@ func (tc *TxnCoordSender) Send(
 				log.Eventf(ctx, "intent: [%s,%s)", intent.Key, intent.EndKey)
 			}
 		}
+
+		// If the transaction's timestamp has been pushed above the timestamp
+		// at which it read, committing would result in a retry error. Try to
+		// refresh the reads to the pushed timestamp first.
+		if hasET {
+			if refreshedTxn, ok := tc.maybeRefreshReads(ctx, *ba.Txn); ok {
+				ba.Txn = &refreshedTxn
+			}
+		}
 	}
 
 	// Send the command through wrapped sender, taking appropriate measures
@@ -447,6 +663,22 @@ func (tc *TxnCoordSender) Send(
 			br, pErr = tc.resendWithTxn(ctx, ba)
 		}
 
+		// If the commit failed only because the transaction record had been
+		// pushed, try to refresh the reads to the pushed timestamp and commit
+		// again. Batches which begin the transaction aren't retried since the
+		// transaction record may have been written.
+		if _, hasBT := ba.GetArg(roachpb.BeginTransaction); !hasBT && isRefreshableRetryError(pErr) {
+			if _, hasET := ba.GetArg(roachpb.EndTransaction); hasET {
+				txn := ba.Txn.Clone()
+				txn.Timestamp.Forward(pErr.GetTxn().Timestamp)
+				if refreshedTxn, ok := tc.maybeRefreshReads(ctx, txn); ok {
+					ba.Txn = &refreshedTxn
+					ba.SetNewRequest()
+					br, pErr = tc.wrapped.Send(ctx, ba)
+				}
+			}
+		}
+
 		if pErr = tc.updateState(ctx, startNS, ba, br, pErr); pErr != nil {
 			log.Eventf(ctx, "error: %s", pErr)
 			return nil, pErr
@@ -571,6 +803,7 @@ func (tc *TxnCoordSender) validateTxnForBatch(ctx context.Context, ba *roachpb.B
 // gracefully.
 func (tc *TxnCoordSender) cleanupTxnLocked(ctx context.Context, txn roachpb.Transaction) {
 	log.Event(ctx, "coordinator stops")
+	delete(tc.txnMu.refreshes, txn.ID)
 	txnMeta, ok := tc.txnMu.txns[txn.ID]
 	// The heartbeat might've already removed the record. Or we may have already
 	// closed txnEnd but we are racing with the heartbeat cleanup.
@@ -605,6 +838,7 @@ func (tc *TxnCoordSender) unregisterTxnLocked(
 	txnMeta.keys = nil
 
 	delete(tc.txnMu.txns, txnID)
+	delete(tc.txnMu.refreshes, txnID)
 
 	return duration, restarts, status
 }
@@ -829,6 +1063,12 @@ func (tc *TxnCoordSender) updateState(
 	}
 
 	txnID := ba.Txn.ID
+	if pErr == nil || pErr.TransactionRestart == roachpb.TransactionRestart_NONE {
+		// Track the spans read by the batch, which may have to be refreshed
+		// should the transaction's timestamp be pushed.
+		tc.getRefreshStateLocked(ba.Txn).add(ba, maxTxnRefreshSpansBytes.Get(&tc.st.SV))
+	}
+
 	var newTxn roachpb.Transaction
 	if pErr == nil {
 		newTxn.Update(ba.Txn)
@@ -987,6 +1227,43 @@ func (tc *TxnCoordSender) GetTxnState(txnID uuid.UUID) (roachpb.Transaction, boo
 	return roachpb.Transaction{}, false
 }
 
+// AugmentTxnState is part of the SenderWithDistSQLBackdoor interface.
+func (tc *TxnCoordSender) AugmentTxnState(
+	ctx context.Context, txn roachpb.Transaction, intents []roachpb.Span,
+) error {
+	tc.txnMu.Lock()
+	defer tc.txnMu.Unlock()
+
+	txnMeta := tc.txnMu.txns[txn.ID]
+	if txnMeta == nil {
+		log.Event(ctx, "coordinator spawns for remote writes")
+		now := tc.clock.PhysicalNow()
+		txnMeta = &txnMetadata{
+			txn:              txn,
+			firstUpdateNanos: now,
+			lastUpdateNanos:  now,
+			timeoutDuration:  tc.clientTimeout,
+			txnEnd:           make(chan struct{}),
+		}
+		tc.txnMu.txns[txn.ID] = txnMeta
+		txnID := txn.ID
+		if err := tc.stopper.RunAsyncTask(
+			ctx, "kv.TxnCoordSender: heartbeat loop", func(ctx context.Context) {
+				tc.heartbeatLoop(ctx, txnID)
+			}); err != nil {
+			tc.unregisterTxnLocked(txnID)
+			return err
+		}
+	}
+	txnMeta.keys = append(txnMeta.keys, intents...)
+	if int64(len(txnMeta.keys)) > maxIntents.Get(&tc.st.SV) {
+		return errors.Errorf("transaction is too large to commit: %d intents", len(txnMeta.keys))
+	}
+	txnMeta.txn.Update(&txn)
+	txnMeta.setLastUpdate(tc.clock.PhysicalNow())
+	return nil
+}
+
 // TODO(tschottdorf): this method is somewhat awkward but unless we want to
 // give this error back to the client, our options are limited. We'll have to
 // run the whole thing for them, or any restart will still end up at the client
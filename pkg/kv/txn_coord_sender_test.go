@@ -550,6 +550,83 @@ func TestTxnCoordSenderAddIntentOnError(t *testing.T) {
 	}
 }
 
+// TestTxnCoordSenderAugmentTxnState verifies that the intents written on
+// behalf of a transaction through another sender, as DistSQL does, are
+// resolved when the transaction finishes, whether they're registered before
+// the writes or reported after them.
+func TestTxnCoordSenderAugmentTxnState(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sender := createTestDB(t)
+	defer s.Stop()
+	ctx := context.TODO()
+
+	// remoteTxn returns a transaction writing on behalf of txn without going
+	// through the TxnCoordSender.
+	remoteTxn := func(txn *client.Txn) *client.Txn {
+		return client.NewTxnWithProto(
+			client.NewDB(sender.wrapped, s.Clock), 0 /* gatewayNodeID */, *txn.Proto(),
+		)
+	}
+
+	t.Run("registered spans", func(t *testing.T) {
+		key := roachpb.Key("c")
+		txn := client.NewTxn(s.DB, 0 /* gatewayNodeID */)
+		spans := []roachpb.Span{{Key: roachpb.Key("b"), EndKey: roachpb.Key("d")}}
+		if err := txn.PrepareForRemoteWrites(ctx, roachpb.Key("b"), spans); err != nil {
+			t.Fatal(err)
+		}
+		if err := remoteTxn(txn).Put(ctx, key, "value"); err != nil {
+			t.Fatal(err)
+		}
+		// The remote writes are never reported; the registered spans cover them.
+		if err := txn.Rollback(ctx); err != nil {
+			t.Fatal(err)
+		}
+		verifyCleanup(key, sender, s.Eng, t)
+	})
+
+	t.Run("reported intents", func(t *testing.T) {
+		key := roachpb.Key("x")
+		txn := client.NewTxn(s.DB, 0 /* gatewayNodeID */)
+		if err := txn.PrepareForRemoteWrites(ctx, roachpb.Key("a"), nil /* spans */); err != nil {
+			t.Fatal(err)
+		}
+		leaf := remoteTxn(txn)
+		leaf.EnableIntentTracking()
+		if err := leaf.Put(ctx, key, "value"); err != nil {
+			t.Fatal(err)
+		}
+		leafProto, intents := leaf.TrackedWrites()
+		if expected := []roachpb.Span{{Key: key}}; !reflect.DeepEqual(intents, expected) {
+			t.Fatalf("expected intents %v, got %v", expected, intents)
+		}
+		if err := txn.AugmentRemoteWrites(ctx, leafProto, intents); err != nil {
+			t.Fatal(err)
+		}
+
+		sender.txnMu.Lock()
+		intentSpans := append([]roachpb.Span(nil), sender.txnMu.txns[txn.Proto().ID].keys...)
+		sender.txnMu.Unlock()
+		found := false
+		for _, span := range intentSpans {
+			found = found || span.EqualValue(roachpb.Span{Key: key})
+		}
+		if !found {
+			t.Fatalf("expected stored intents %v to contain %s", intentSpans, key)
+		}
+
+		if err := txn.CommitOrCleanup(ctx); err != nil {
+			t.Fatal(err)
+		}
+		verifyCleanup(key, sender, s.Eng, t)
+		if kv, err := s.DB.Get(ctx, key); err != nil {
+			t.Fatal(err)
+		} else if !kv.Exists() {
+			t.Fatalf("expected %s to be committed", key)
+		}
+	})
+}
+
 func assertTransactionRetryError(t *testing.T, e error) {
 	if retErr, ok := e.(*roachpb.HandledRetryableTxnError); ok {
 		if !testutils.IsError(retErr, "TransactionRetryError") {
@@ -1461,6 +1538,7 @@ func TestTxnRestartCount(t *testing.T) {
 	s, sender, cleanupFn := setupMetricsTest(t)
 	defer cleanupFn()
 
+	readKey := []byte("read-key")
 	key := []byte("key-restart")
 	value := []byte("value")
 	db := client.NewDB(sender, s.Clock)
@@ -1470,6 +1548,15 @@ func TestTxnRestartCount(t *testing.T) {
 	if _, err := txn.Get(context.TODO(), key); err != nil {
 		t.Fatal(err)
 	}
+	if _, err := txn.Get(context.TODO(), readKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// Outside of the transaction, write to a key read by the transaction. This
+	// prevents the transaction's reads from being refreshed to a higher timestamp.
+	if err := db.Put(context.TODO(), readKey, value); err != nil {
+		t.Fatal(err)
+	}
 
 	// Outside of the transaction, read the same key as was read within the transaction. This
 	// means that future attempts to write will increase the timestamp.
@@ -1493,6 +1580,54 @@ func TestTxnRestartCount(t *testing.T) {
 	checkTxnMetrics(t, sender, "restart txn", 0, 0, 0, 1, 1)
 }
 
+// TestTxnRefreshAvoidsRestart verifies that a serializable transaction whose
+// timestamp is pushed can commit at the pushed timestamp if its reads can be
+// refreshed.
+func TestTxnRefreshAvoidsRestart(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sender, cleanupFn := setupMetricsTest(t)
+	defer cleanupFn()
+
+	readKey := roachpb.Key("read-key")
+	key := []byte("key-refresh")
+	value := []byte("value")
+	db := client.NewDB(sender, s.Clock)
+
+	txn := client.NewTxn(db, 0 /* gatewayNodeID */)
+	if _, err := txn.Scan(context.TODO(), readKey, readKey.PrefixEnd(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Get(context.TODO(), key); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the key outside of the transaction so that the transaction's write
+	// is pushed to a higher timestamp.
+	if _, err := db.Get(context.TODO(), key); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(context.TODO(), key, value); err != nil {
+		t.Fatal(err)
+	}
+	if !txn.Proto().OrigTimestamp.Less(txn.Proto().Timestamp) {
+		t.Errorf("expected timestamp to increase: %s", txn.Proto())
+	}
+
+	// Nothing the transaction read has changed, so it commits without a restart.
+	if err := txn.CommitOrCleanup(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if a, e := sender.metrics.RefreshSuccess.Count(), int64(1); a != e {
+		t.Errorf("expected %d successful refreshes, got %d", e, a)
+	}
+	if a, e := sender.metrics.RefreshFail.Count(), int64(0); a != e {
+		t.Errorf("expected %d failed refreshes, got %d", e, a)
+	}
+
+	teardownHeartbeats(sender)
+	checkTxnMetrics(t, sender, "refresh txn", 1, 0, 0, 0, 0)
+}
+
 func TestTxnDurations(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s, sender, cleanupFn := setupMetricsTest(t)
@@ -281,6 +281,32 @@ func (r *AdminScatterResponse) combine(c combinable) error {
 
 var _ combinable = &AdminScatterResponse{}
 
+// combine implements the combinable interface.
+func (r *RefreshRangeResponse) combine(c combinable) error {
+	if r != nil {
+		otherR := c.(*RefreshRangeResponse)
+		if err := r.ResponseHeader.combine(otherR.Header()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ combinable = &RefreshRangeResponse{}
+
+// combine implements the combinable interface.
+func (r *ClearRangeResponse) combine(c combinable) error {
+	if r != nil {
+		otherR := c.(*ClearRangeResponse)
+		if err := r.ResponseHeader.combine(otherR.Header()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ combinable = &ClearRangeResponse{}
+
 // Header implements the Request interface.
 func (rh Span) Header() Span {
 	return rh
@@ -509,6 +535,15 @@ func (*AdminScatterRequest) Method() Method { return AdminScatter }
 // Method implements the Request interface.
 func (*AddSSTableRequest) Method() Method { return AddSSTable }
 
+// Method implements the Request interface.
+func (*RefreshRequest) Method() Method { return Refresh }
+
+// Method implements the Request interface.
+func (*RefreshRangeRequest) Method() Method { return RefreshRange }
+
+// Method implements the Request interface.
+func (*ClearRangeRequest) Method() Method { return ClearRange }
+
 // ShallowCopy implements the Request interface.
 func (gr *GetRequest) ShallowCopy() Request {
 	shallowCopy := *gr
@@ -725,6 +760,24 @@ func (r *AddSSTableRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (r *RefreshRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
+// ShallowCopy implements the Request interface.
+func (r *RefreshRangeRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
+// ShallowCopy implements the Request interface.
+func (r *ClearRangeRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
 // NewGet returns a Request initialized to get the value at key.
 func NewGet(key Key) Request {
 	return &GetRequest{
@@ -756,6 +809,17 @@ func NewPut(key Key, value Value) Request {
 	}
 }
 
+// NewLock returns a Request initialized to acquire an exclusive lock on key
+// on behalf of the transaction, without writing to it.
+func NewLock(key Key) Request {
+	return &PutRequest{
+		Span: Span{
+			Key: key,
+		},
+		Lock: true,
+	}
+}
+
 // NewPutInline returns a Request initialized to put the value at key
 // using an inline value.
 func NewPutInline(key Key, value Value) Request {
@@ -949,6 +1013,13 @@ func (*ExportRequest) flags() int                   { return isRead | isRange |
 func (*ImportRequest) flags() int                   { return isAdmin | isAlone }
 func (*AdminScatterRequest) flags() int             { return isAdmin | isAlone | isRange }
 func (*AddSSTableRequest) flags() int               { return isWrite | isAlone | isRange }
+func (*ClearRangeRequest) flags() int               { return isWrite | isAlone | isRange }
+
+// Refresh and RefreshRange read at the transaction's new timestamp and
+// update the read timestamp cache so that no writer can slip in beneath
+// the timestamp at which the reads were verified.
+func (*RefreshRequest) flags() int      { return isRead | isTxn | updatesTSCache }
+func (*RefreshRangeRequest) flags() int { return isRead | isTxn | isRange | updatesTSCache }
 
 // Keys returns credentials in an aws.Config.
 func (b *ExportStorage_S3) Keys() *aws.Config {
@@ -722,6 +722,7 @@ func (t Transaction) Clone() Transaction {
 	// Note that we're not cloning the span keys under the assumption that the
 	// keys themselves are not mutable.
 	t.Intents = append([]Span(nil), t.Intents...)
+	t.IgnoredSeqNums = append([]enginepb.IgnoredSeqNumRange(nil), t.IgnoredSeqNums...)
 	return t
 }
 
@@ -840,7 +841,21 @@ func (t *Transaction) Restart(
 	t.UpgradePriority(upgradePriority)
 	t.WriteTooOld = false
 	t.RetryOnPush = false
+	t.RefreshedTimestamp = hlc.Timestamp{}
 	t.Sequence = 0
+	// Savepoints do not survive a restart, so neither do the writes they
+	// rolled back.
+	t.WriteSeq = 0
+	t.IgnoredSeqNums = nil
+}
+
+// ReadTimestamp returns the timestamp at which the transaction's reads
+// are evaluated. This is the original timestamp unless the transaction's
+// read spans have since been refreshed to a later timestamp.
+func (t *Transaction) ReadTimestamp() hlc.Timestamp {
+	ts := t.OrigTimestamp
+	ts.Forward(t.RefreshedTimestamp)
+	return ts
 }
 
 // BumpEpoch increments the transaction's epoch, allowing for an in-place
@@ -870,11 +885,22 @@ func (t *Transaction) Update(o *Transaction) {
 	}
 	if t.Epoch < o.Epoch {
 		t.Epoch = o.Epoch
+		t.WriteSeq = o.WriteSeq
+		t.IgnoredSeqNums = o.IgnoredSeqNums
+	} else if t.Epoch == o.Epoch {
+		if t.WriteSeq < o.WriteSeq {
+			t.WriteSeq = o.WriteSeq
+		}
+		// Ignored ranges are only ever appended within an epoch.
+		if len(t.IgnoredSeqNums) < len(o.IgnoredSeqNums) {
+			t.IgnoredSeqNums = o.IgnoredSeqNums
+		}
 	}
 	t.Timestamp.Forward(o.Timestamp)
 	t.LastHeartbeat.Forward(o.LastHeartbeat)
 	t.OrigTimestamp.Forward(o.OrigTimestamp)
 	t.MaxTimestamp.Forward(o.MaxTimestamp)
+	t.RefreshedTimestamp.Forward(o.RefreshedTimestamp)
 
 	// Absorb the collected clock uncertainty information.
 	for _, v := range o.ObservedTimestamps {
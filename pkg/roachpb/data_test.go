@@ -376,14 +376,16 @@ func TestFastPathObservedTimestamp(t *testing.T) {
 
 var nonZeroTxn = Transaction{
 	TxnMeta: enginepb.TxnMeta{
-		Isolation:  enginepb.SNAPSHOT,
-		Key:        Key("foo"),
-		ID:         uuid.MakeV4(),
-		Epoch:      2,
-		Timestamp:  makeTS(20, 21),
-		Priority:   957356782,
-		Sequence:   123,
-		BatchIndex: 1,
+		Isolation:      enginepb.SNAPSHOT,
+		Key:            Key("foo"),
+		ID:             uuid.MakeV4(),
+		Epoch:          2,
+		Timestamp:      makeTS(20, 21),
+		Priority:       957356782,
+		Sequence:       123,
+		BatchIndex:     1,
+		WriteSeq:       4,
+		IgnoredSeqNums: []enginepb.IgnoredSeqNumRange{{Start: 1, End: 2}},
 	},
 	Name:               "name",
 	Status:             COMMITTED,
@@ -394,6 +396,7 @@ var nonZeroTxn = Transaction{
 	Writing:            true,
 	WriteTooOld:        true,
 	RetryOnPush:        true,
+	RefreshedTimestamp: makeTS(35, 36),
 	Intents:            []Span{{Key: []byte("a"), EndKey: []byte("b")}},
 }
 
@@ -421,6 +424,24 @@ func TestTransactionUpdate(t *testing.T) {
 	}
 }
 
+func TestTransactionReadTimestamp(t *testing.T) {
+	txn := Transaction{OrigTimestamp: makeTS(10, 0)}
+	if ts := txn.ReadTimestamp(); ts != makeTS(10, 0) {
+		t.Errorf("expected read timestamp %s; got %s", makeTS(10, 0), ts)
+	}
+	txn.RefreshedTimestamp = makeTS(20, 1)
+	if ts := txn.ReadTimestamp(); ts != makeTS(20, 1) {
+		t.Errorf("expected read timestamp %s; got %s", makeTS(20, 1), ts)
+	}
+	txn.Restart(NormalUserPriority, 0, makeTS(30, 0))
+	if txn.RefreshedTimestamp != (hlc.Timestamp{}) {
+		t.Errorf("expected refreshed timestamp to be reset on restart; got %s", txn.RefreshedTimestamp)
+	}
+	if ts := txn.ReadTimestamp(); ts != makeTS(30, 0) {
+		t.Errorf("expected read timestamp %s; got %s", makeTS(30, 0), ts)
+	}
+}
+
 func TestTransactionClone(t *testing.T) {
 	txn := nonZeroTxn.Clone()
 
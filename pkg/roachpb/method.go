@@ -133,4 +133,15 @@ const (
 	AdminScatter
 	// AddSSTable links a file into the RocksDB log-structured merge-tree.
 	AddSSTable
+	// Refresh verifies no writes to a key have occurred since the
+	// transaction's original timestamp and sets a new entry in the
+	// timestamp cache at the transaction's refreshed timestamp.
+	Refresh
+	// RefreshRange verifies no writes have occurred to a span of keys
+	// since the transaction's original timestamp and sets a new entry in
+	// the timestamp cache at the transaction's refreshed timestamp.
+	RefreshRange
+	// ClearRange removes all values (including all of their versions)
+	// for a span of keys, bypassing MVCC.
+	ClearRange
 )
@@ -470,6 +470,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		StatusServer:            s.status,
 		SessionRegistry:         s.sessionRegistry,
 		JobRegistry:             s.jobRegistry,
+		NodeLiveness:            s.nodeLiveness,
 		HistogramWindowInterval: s.cfg.HistogramWindowInterval(),
 		RangeDescriptorCache:    s.distSender.RangeDescriptorCache(),
 		LeaseHolderCache:        s.distSender.LeaseHolderCache(),
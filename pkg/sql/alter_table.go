@@ -41,6 +41,9 @@ func (p *planner) AlterTable(ctx context.Context, n *tree.AlterTable) (planNode,
 	if err != nil {
 		return nil, err
 	}
+	if err := p.searchTempSchema(ctx, tn); err != nil {
+		return nil, err
+	}
 
 	tableDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), tn)
 	if err != nil {
@@ -83,9 +86,16 @@ func (n *alterTableNode) Start(params runParams) error {
 			if err != nil {
 				return err
 			}
+			if col.IsComputed() {
+				err := n.tableDesc.ValidateComputedColumn(col, params.p.semaCtx.SearchPath)
+				if err != nil {
+					return err
+				}
+			}
 			// We're checking to see if a user is trying add a non-nullable column without a default to a
 			// non empty table by scanning the primary index span with a limit of 1 to see if any key exists.
-			if !col.Nullable && col.DefaultExpr == nil {
+			// Computed columns are checked when they are backfilled.
+			if !col.Nullable && col.DefaultExpr == nil && !col.IsComputed() {
 				kvs, err := params.p.txn.Scan(params.ctx, n.tableDesc.PrimaryIndexSpan().Key, n.tableDesc.PrimaryIndexSpan().EndKey, 1)
 				if err != nil {
 					return err
@@ -230,6 +240,34 @@ func (n *alterTableNode) Start(params runParams) error {
 			if n.tableDesc.PrimaryIndex.ContainsColumnID(col.ID) {
 				return fmt.Errorf("column %q is referenced by the primary key", col.Name)
 			}
+			for _, idx := range n.tableDesc.AllNonDropIndexes() {
+				predCols, err := n.tableDesc.IndexPredicateColumnIDs(&idx)
+				if err != nil {
+					return err
+				}
+				for _, id := range predCols {
+					if id == col.ID {
+						return fmt.Errorf("column %q is referenced by the predicate of index %q",
+							col.Name, idx.Name)
+					}
+				}
+			}
+			for _, c := range n.tableDesc.Columns {
+				deps, err := n.tableDesc.ComputedColumnDeps(&c)
+				if err != nil {
+					return err
+				}
+				for _, id := range deps {
+					if id != col.ID {
+						continue
+					}
+					if c.IsIndexExpr() {
+						return fmt.Errorf("column %q is referenced by the index expression %s",
+							col.Name, *c.ComputeExpr)
+					}
+					return fmt.Errorf("column %q is referenced by computed column %q", col.Name, c.Name)
+				}
+			}
 			for _, idx := range n.tableDesc.AllNonDropIndexes() {
 				// We automatically drop indexes on that column that only
 				// index that column (and no other columns). If CASCADE is
@@ -503,6 +541,9 @@ func applyColumnMutation(
 	case *tree.AlterTableSetDefault:
 		if t.Default == nil {
 			col.DefaultExpr = nil
+		} else if col.IsComputed() {
+			return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+				"computed column %q cannot have a default value", col.Name)
 		} else {
 			colDatumType := col.Type.ToDatumType()
 			if _, err := sqlbase.SanitizeVarFreeExpr(
@@ -72,6 +72,11 @@ var (
 	// TimestampWithTZ is an immutable T instance.
 	TimestampWithTZ = &TTimestampTZ{}
 
+	// Time is an immutable T instance.
+	Time = &TTime{}
+	// TimeWithTZ is an immutable T instance.
+	TimeWithTZ = &TTimeTZ{}
+
 	// Interval is an immutable T instance.
 	Interval = &TInterval{}
 
@@ -80,6 +80,10 @@ func DatumTypeToColumnType(t types.T) (T, error) {
 		return Timestamp, nil
 	case types.TimestampTZ:
 		return TimestampWithTZ, nil
+	case types.Time:
+		return Time, nil
+	case types.TimeTZ:
+		return TimeWithTZ, nil
 	case types.Interval:
 		return Interval, nil
 	case types.JSON:
@@ -116,6 +120,8 @@ func DatumTypeToColumnType(t types.T) (T, error) {
 		return ArrayOf(elemTyp, nil)
 	case types.TOidWrapper:
 		return DatumTypeToColumnType(typ.T)
+	case types.TEnum:
+		return &TUserDefined{Name: typ.Name, Typ: typ}, nil
 	}
 
 	return nil, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
@@ -146,6 +152,10 @@ func CastTargetToDatumType(t CastTargetType) types.T {
 		return types.Timestamp
 	case *TTimestampTZ:
 		return types.TimestampTZ
+	case *TTime:
+		return types.Time
+	case *TTimeTZ:
+		return types.TimeTZ
 	case *TInterval:
 		return types.Interval
 	case *TJSON:
@@ -162,6 +172,12 @@ func CastTargetToDatumType(t CastTargetType) types.T {
 		return types.IntVector
 	case *TOid:
 		return TOidToType(ct)
+	case *TUserDefined:
+		if ct.Typ == nil {
+			// The name has not been resolved yet.
+			return types.FamEnum
+		}
+		return ct.Typ
 	default:
 		panic(fmt.Sprintf("unexpected CastTarget %T", t))
 	}
@@ -54,6 +54,8 @@ func (*TDecimal) columnType()        {}
 func (*TDate) columnType()           {}
 func (*TTimestamp) columnType()      {}
 func (*TTimestampTZ) columnType()    {}
+func (*TTime) columnType()           {}
+func (*TTimeTZ) columnType()         {}
 func (*TInterval) columnType()       {}
 func (*TJSON) columnType()           {}
 func (*TUUID) columnType()           {}
@@ -65,6 +67,7 @@ func (*TCollatedString) columnType() {}
 func (*TArray) columnType()          {}
 func (*TVector) columnType()         {}
 func (*TOid) columnType()            {}
+func (*TUserDefined) columnType()    {}
 
 // All Ts also implement CastTargetType.
 func (*TBool) castTargetType()           {}
@@ -74,6 +77,8 @@ func (*TDecimal) castTargetType()        {}
 func (*TDate) castTargetType()           {}
 func (*TTimestamp) castTargetType()      {}
 func (*TTimestampTZ) castTargetType()    {}
+func (*TTime) castTargetType()           {}
+func (*TTimeTZ) castTargetType()         {}
 func (*TInterval) castTargetType()       {}
 func (*TJSON) castTargetType()           {}
 func (*TUUID) castTargetType()           {}
@@ -85,6 +90,7 @@ func (*TCollatedString) castTargetType() {}
 func (*TArray) castTargetType()          {}
 func (*TVector) castTargetType()         {}
 func (*TOid) castTargetType()            {}
+func (*TUserDefined) castTargetType()    {}
 
 func (node *TBool) String() string           { return ColTypeAsString(node) }
 func (node *TInt) String() string            { return ColTypeAsString(node) }
@@ -93,6 +99,8 @@ func (node *TDecimal) String() string        { return ColTypeAsString(node) }
 func (node *TDate) String() string           { return ColTypeAsString(node) }
 func (node *TTimestamp) String() string      { return ColTypeAsString(node) }
 func (node *TTimestampTZ) String() string    { return ColTypeAsString(node) }
+func (node *TTime) String() string           { return ColTypeAsString(node) }
+func (node *TTimeTZ) String() string         { return ColTypeAsString(node) }
 func (node *TInterval) String() string       { return ColTypeAsString(node) }
 func (node *TJSON) String() string           { return ColTypeAsString(node) }
 func (node *TUUID) String() string           { return ColTypeAsString(node) }
@@ -104,3 +112,4 @@ func (node *TCollatedString) String() string { return ColTypeAsString(node) }
 func (node *TArray) String() string          { return ColTypeAsString(node) }
 func (node *TVector) String() string         { return ColTypeAsString(node) }
 func (node *TOid) String() string            { return ColTypeAsString(node) }
+func (node *TUserDefined) String() string    { return ColTypeAsString(node) }
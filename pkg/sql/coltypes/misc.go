@@ -18,6 +18,7 @@ import (
 	"bytes"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 )
 
 // This file contains column type definitions that don't fit
@@ -69,3 +70,18 @@ type TOid struct {
 func (node *TOid) Format(buf *bytes.Buffer, f lex.EncodeFlags) {
 	buf.WriteString(node.Name)
 }
+
+// TUserDefined represents a user-defined type, designated by its name. ENUM
+// types are currently the only user-defined types. The datum type is not
+// known to the parser: it is filled in when the name is resolved, during
+// type checking or planning.
+type TUserDefined struct {
+	Name string
+	// Typ is the resolved type, or nil if the name has not been resolved yet.
+	Typ types.T
+}
+
+// Format implements the ColTypeFormatter interface.
+func (node *TUserDefined) Format(buf *bytes.Buffer, f lex.EncodeFlags) {
+	lex.EncodeRestrictedSQLIdent(buf, node.Name, f)
+}
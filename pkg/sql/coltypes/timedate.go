@@ -44,6 +44,22 @@ func (node *TTimestampTZ) Format(buf *bytes.Buffer, f lex.EncodeFlags) {
 	buf.WriteString("TIMESTAMP WITH TIME ZONE")
 }
 
+// TTime represents a TIME type.
+type TTime struct{}
+
+// Format implements the ColTypeFormatter interface.
+func (node *TTime) Format(buf *bytes.Buffer, f lex.EncodeFlags) {
+	buf.WriteString("TIME")
+}
+
+// TTimeTZ represents a TIME WITH TIME ZONE type.
+type TTimeTZ struct{}
+
+// Format implements the ColTypeFormatter interface.
+func (node *TTimeTZ) Format(buf *bytes.Buffer, f lex.EncodeFlags) {
+	buf.WriteString("TIME WITH TIME ZONE")
+}
+
 // TInterval represents an INTERVAL type
 type TInterval struct{}
 
@@ -183,6 +183,7 @@ func (n *copyNode) addRow(ctx context.Context, line []byte) error {
 			exprs[i] = tree.DNull
 			continue
 		}
+		needsDecode := false
 		switch t := n.resultColumns[i].Typ; t {
 		case types.Bytes,
 			types.Date,
@@ -191,7 +192,14 @@ func (n *copyNode) addRow(ctx context.Context, line []byte) error {
 			types.String,
 			types.Timestamp,
 			types.TimestampTZ,
+			types.Time,
+			types.TimeTZ,
 			types.UUID:
+			needsDecode = true
+		default:
+			needsDecode = t.FamilyEqual(types.FamEnum)
+		}
+		if needsDecode {
 			s, err = decodeCopy(s)
 			if err != nil {
 				return err
@@ -72,6 +72,7 @@ var crdbInternal = virtualSchema{
 		crdbInternalTableColumnsTable,
 		crdbInternalTableIndexesTable,
 		crdbInternalTablesTable,
+		crdbInternalUnsatisfiableZoneConstraintsTable,
 		crdbInternalZonesTable,
 	},
 }
@@ -174,6 +175,7 @@ CREATE TABLE crdb_internal.tables (
   parent_id                INT NOT NULL,
   name                     STRING NOT NULL,
   database_name            STRING NOT NULL,
+  schema_name              STRING NOT NULL,
   version                  INT NOT NULL,
   mod_time                 TIMESTAMP NOT NULL,
   mod_time_logical         DECIMAL NOT NULL,
@@ -189,11 +191,15 @@ CREATE TABLE crdb_internal.tables (
 			return err
 		}
 		dbNames := make(map[sqlbase.ID]string)
-		// Record database descriptors for name lookups.
+		scNames := make(map[sqlbase.ID]string)
+		// Record database and schema descriptors for name lookups.
 		for _, desc := range descs {
 			db, ok := desc.(*sqlbase.DatabaseDescriptor)
 			if ok {
 				dbNames[db.ID] = db.Name
+				for _, sc := range db.Schemas {
+					scNames[sc.ID] = sc.Name
+				}
 			}
 		}
 		// Note: we do not use forEachTableDesc() here because we want to
@@ -211,6 +217,14 @@ CREATE TABLE crdb_internal.tables (
 				// effectively deleted.
 				dbName = fmt.Sprintf("[%d]", table.GetParentID())
 			}
+			scName := tree.PublicSchemaName
+			if table.ParentSchemaID != 0 {
+				scName = scNames[table.ParentSchemaID]
+				if scName == "" {
+					// The parent schema was dropped.
+					scName = fmt.Sprintf("[%d]", table.ParentSchemaID)
+				}
+			}
 			leaseNodeDatum := tree.DNull
 			leaseExpDatum := tree.DNull
 			if table.Lease != nil {
@@ -224,6 +238,7 @@ CREATE TABLE crdb_internal.tables (
 				tree.NewDInt(tree.DInt(int64(table.GetParentID()))),
 				tree.NewDString(table.Name),
 				tree.NewDString(dbName),
+				tree.NewDString(scName),
 				tree.NewDInt(tree.DInt(int64(table.Version))),
 				tree.MakeDTimestamp(timeutil.Unix(0, table.ModificationTime.WallTime), time.Microsecond),
 				tree.TimestampToDecimal(table.ModificationTime),
@@ -879,6 +894,7 @@ var crdbInternalCreateStmtsTable = virtualSchemaTable{
 CREATE TABLE crdb_internal.create_statements (
   database_id      INT,
   database_name    STRING NOT NULL,
+  schema_name      STRING NOT NULL,
   descriptor_id    INT,
   descriptor_type  STRING NOT NULL,
   descriptor_name  STRING NOT NULL,
@@ -894,12 +910,18 @@ CREATE TABLE crdb_internal.create_statements (
 				var err error
 				var typeView = tree.DString("view")
 				var typeTable = tree.DString("table")
+				tn := tree.TableName{TableName: tree.Name(table.Name), DBNameOriginallyOmitted: true}
+				scName := tree.PublicSchemaName
+				if scDesc, ok := db.FindSchemaByID(table.ParentSchemaID); ok {
+					tn.SchemaName = tree.Name(scDesc.Name)
+					scName = scDesc.Name
+				}
 				if table.IsView() {
 					descType = &typeView
-					stmt, err = p.showCreateView(ctx, tree.Name(table.Name), table)
+					stmt, err = p.showCreateView(ctx, &tn, table)
 				} else {
 					descType = &typeTable
-					stmt, err = p.showCreateTable(ctx, tree.Name(table.Name), prefix, table)
+					stmt, err = p.showCreateTable(ctx, &tn, prefix, table)
 				}
 				if err != nil {
 					return err
@@ -916,6 +938,7 @@ CREATE TABLE crdb_internal.create_statements (
 				return addRow(
 					dbDescID,
 					tree.NewDString(db.Name),
+					tree.NewDString(scName),
 					descID,
 					descType,
 					tree.NewDString(table.Name),
@@ -1523,3 +1546,96 @@ CREATE TABLE crdb_internal.zones (
 		return nil
 	},
 }
+
+// crdbInternalUnsatisfiableZoneConstraintsTable reports the zone
+// constraints that cannot be satisfied by the nodes currently in the
+// cluster, i.e. constraint groups that require more replicas than there
+// are distinct nodes with matching stores. Ranges governed by such
+// configs will be under-replicated until the cluster topology changes.
+// Zones which merely ask for more replicas than there are nodes are not
+// reported unless their constraints exclude some of the nodes.
+var crdbInternalUnsatisfiableZoneConstraintsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.unsatisfiable_zone_constraints (
+  zone_id        INT NOT NULL,
+  cli_specifier  STRING NOT NULL,
+  constraints    STRING NOT NULL,
+  num_replicas   INT NOT NULL,
+  matching_nodes INT NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser("read crdb_internal.unsatisfiable_zone_constraints"); err != nil {
+			return err
+		}
+		response, err := p.session.execCfg.StatusServer.Nodes(ctx, &serverpb.NodesRequest{})
+		if err != nil {
+			return err
+		}
+		var stores []roachpb.StoreDescriptor
+		for _, n := range response.Nodes {
+			for _, s := range n.StoreStatuses {
+				stores = append(stores, s.Desc)
+			}
+		}
+		// matchingNodes returns the number of distinct nodes with at least one
+		// store satisfying all of the given constraint conjunctions.
+		matchingNodes := func(constraints ...config.Constraints) int {
+			nodes := make(map[roachpb.NodeID]struct{})
+			for _, s := range stores {
+				ok := true
+				for _, c := range constraints {
+					if !config.StoreSatisfiesConstraints(s, c) {
+						ok = false
+						break
+					}
+				}
+				if ok {
+					nodes[s.Node.NodeID] = struct{}{}
+				}
+			}
+			return len(nodes)
+		}
+		totalNodes := matchingNodes()
+
+		p = makeInternalPlanner("unsatisfiable-zones", p.txn, p.evalCtx.User, p.session.memMetrics)
+		defer finishInternalPlanner(p)
+		rows, err := p.queryRows(ctx, `SELECT id, cli_specifier, config_proto FROM crdb_internal.zones`)
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			var zone config.ZoneConfig
+			if err := protoutil.Unmarshal([]byte(*r[2].(*tree.DBytes)), &zone); err != nil {
+				return err
+			}
+			report := func(constraints string, numReplicas int32, matching int) error {
+				// A shortfall which isn't caused by the constraints is a
+				// property of the cluster size, not of the zone config.
+				if matching >= int(numReplicas) || matching >= totalNodes {
+					return nil
+				}
+				return addRow(
+					r[0], // zone_id
+					r[1], // cli_specifier
+					tree.NewDString(constraints),
+					tree.NewDInt(tree.DInt(numReplicas)),
+					tree.NewDInt(tree.DInt(matching)),
+				)
+			}
+			if err := report(
+				zone.Constraints.ConjunctionString(), zone.NumReplicas, matchingNodes(zone.Constraints),
+			); err != nil {
+				return err
+			}
+			for _, group := range zone.ReplicaConstraints {
+				if err := report(
+					group.ConjunctionString(), group.NumReplicas, matchingNodes(zone.Constraints, group),
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
@@ -138,7 +138,9 @@ func (p *planner) CreateIndex(ctx context.Context, n *tree.CreateIndex) (planNod
 		return nil, err
 	}
 
-	tableDesc, err := MustGetTableDesc(ctx, p.txn, p.getVirtualTabler(), tn, true /*allowAdding*/)
+	tableDesc, err := mustGetIndexableTableDesc(
+		ctx, p.txn, p.getVirtualTabler(), tn, true, /*allowAdding*/
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -166,9 +168,23 @@ func (n *createIndexNode) Start(params runParams) error {
 		Unique:           n.n.Unique,
 		StoreColumnNames: n.n.Storing.ToStrings(),
 	}
-	if err := indexDesc.FillColumns(n.n.Columns); err != nil {
+	columns, err := resolveIndexExprs(n.tableDesc, n.n.Columns, params.p.semaCtx.SearchPath,
+		func(col sqlbase.ColumnDescriptor) {
+			n.tableDesc.AddColumnMutation(col, sqlbase.DescriptorMutation_ADD)
+		})
+	if err != nil {
+		return err
+	}
+	if err := indexDesc.FillColumns(columns); err != nil {
 		return err
 	}
+	if n.n.Predicate != nil {
+		if indexDesc.Predicate, err = n.tableDesc.MakeIndexPredicate(
+			n.n.Predicate, params.p.semaCtx.SearchPath,
+		); err != nil {
+			return err
+		}
+	}
 
 	mutationIdx := len(n.tableDesc.Mutations)
 	if err := n.tableDesc.AddIndexMutation(indexDesc, sqlbase.DescriptorMutation_ADD); err != nil {
@@ -440,11 +456,12 @@ func (*alterUserSetPasswordNode) Next(runParams) (bool, error) { return false, n
 func (*alterUserSetPasswordNode) Close(context.Context)        {}
 func (*alterUserSetPasswordNode) Values() tree.Datums          { return tree.Datums{} }
 
-// createViewNode represents a CREATE VIEW statement.
+// createViewNode represents a CREATE [MATERIALIZED] VIEW statement.
 type createViewNode struct {
 	p             *planner
 	n             *tree.CreateView
 	dbDesc        *sqlbase.DatabaseDescriptor
+	scDesc        *sqlbase.SchemaDescriptor
 	sourceColumns sqlbase.ResultColumns
 	// planDeps tracks which tables and views the view being created
 	// depends on. This is collected during the construction of
@@ -452,8 +469,9 @@ type createViewNode struct {
 	planDeps planDependencies
 }
 
-// CreateView creates a view.
-// Privileges: CREATE on database plus SELECT on all the selected columns.
+// CreateView creates a view or a materialized view.
+// Privileges: CREATE on database (or on the schema of the view) plus SELECT
+// on all the selected columns.
 //   notes: postgres requires CREATE on database plus SELECT on all the
 //						selected columns.
 //          mysql requires CREATE VIEW plus SELECT on all the selected columns.
@@ -463,12 +481,12 @@ func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode,
 		return nil, err
 	}
 
-	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), name.Database())
+	dbDesc, scDesc, err := resolveTableSchema(ctx, p.txn, p.getVirtualTabler(), name)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(createPrivilegeTarget(dbDesc, scDesc), privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -491,6 +509,19 @@ func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode,
 					fmtErr = err
 					return
 				}
+				// Resolve user-defined schemas, so that the meaning of the
+				// view query does not depend on the session database.
+				if _, _, err := resolveTableSchema(ctx, p.txn, p.getVirtualTabler(), tn); err != nil {
+					fmtErr = err
+					return
+				}
+				// Views outlive the sessions, and thus cannot depend on
+				// their temporary tables.
+				if IsTempSchemaName(tn.Schema()) {
+					fmtErr = pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+						"views cannot depend on temporary table %s", tree.ErrString(tn))
+					return
+				}
 				// Persist the database prefix expansion.
 				tn.DBNameOriginallyOmitted = false
 			},
@@ -521,6 +552,7 @@ func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode,
 		p:             p,
 		n:             n,
 		dbDesc:        dbDesc,
+		scDesc:        scDesc,
 		sourceColumns: sourceColumns,
 		planDeps:      planDeps,
 	}, nil
@@ -528,7 +560,7 @@ func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode,
 
 func (n *createViewNode) Start(params runParams) error {
 	viewName := n.n.Name.TableName().Table()
-	tKey := tableKey{parentID: n.dbDesc.ID, name: viewName}
+	tKey := tableKey{parentID: namespaceParentID(n.dbDesc, n.scDesc), name: viewName}
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, n.p.txn, key); err == nil && exists {
 		// TODO(a-robinson): Support CREATE OR REPLACE commands.
@@ -542,8 +574,8 @@ func (n *createViewNode) Start(params runParams) error {
 		return nil
 	}
 
-	// Inherit permissions from the database descriptor.
-	privs := n.dbDesc.GetPrivileges()
+	// Inherit permissions from the database or schema descriptor.
+	privs := createPrivilegeTarget(n.dbDesc, n.scDesc).GetPrivileges()
 
 	desc, err := n.makeViewTableDesc(
 		params.ctx,
@@ -559,6 +591,10 @@ func (n *createViewNode) Start(params runParams) error {
 		return err
 	}
 
+	if n.scDesc != nil {
+		desc.ParentSchemaID = n.scDesc.ID
+	}
+
 	if err = desc.ValidateTable(); err != nil {
 		return err
 	}
@@ -596,6 +632,12 @@ func (n *createViewNode) Start(params runParams) error {
 		return err
 	}
 
+	if desc.MaterializedView() {
+		if err := n.p.populateMaterializedView(params.ctx, &desc); err != nil {
+			return err
+		}
+	}
+
 	// Log Create View event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
 	return MakeEventLogger(n.p.LeaseMgr()).InsertEventRecord(
@@ -620,32 +662,66 @@ func (*createViewNode) Values() tree.Datums          { return tree.Datums{} }
 type createTableNode struct {
 	n          *tree.CreateTable
 	dbDesc     *sqlbase.DatabaseDescriptor
+	scDesc     *sqlbase.SchemaDescriptor
 	sourcePlan planNode
 	count      int
 }
 
 // CreateTable creates a table.
-// Privileges: CREATE on database.
-//   Notes: postgres/mysql require CREATE on database.
+// Privileges: CREATE on database, or on the schema of the table. Temporary
+// tables only require any privilege on the database.
+//   Notes: postgres/mysql require CREATE on database. postgres requires
+//          TEMPORARY on database for temporary tables.
 func (p *planner) CreateTable(ctx context.Context, n *tree.CreateTable) (planNode, error) {
 	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
 	if err != nil {
 		return nil, err
 	}
 
-	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), tn.Database())
-	if err != nil {
-		return nil, err
-	}
-
-	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
-		return nil, err
+	var dbDesc *sqlbase.DatabaseDescriptor
+	var scDesc *sqlbase.SchemaDescriptor
+	if n.Temporary {
+		if n.Interleave != nil {
+			return nil, pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"temporary tables cannot be interleaved")
+		}
+		dbDesc, scDesc, err = p.resolveTempTableSchema(ctx, tn)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.anyPrivilege(dbDesc); err != nil {
+			return nil, err
+		}
+	} else {
+		if n.OnCommit != tree.OnCommitPreserveRows {
+			return nil, pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"ON COMMIT can only be used on temporary tables")
+		}
+		dbDesc, scDesc, err = resolveTableSchema(ctx, p.txn, p.getVirtualTabler(), tn)
+		if err != nil {
+			return nil, err
+		}
+		if scDesc != nil && IsTempSchemaName(scDesc.Name) {
+			return nil, pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"cannot create a permanent relation in a temporary schema")
+		}
+		if err := p.CheckPrivilege(createPrivilegeTarget(dbDesc, scDesc), privilege.CREATE); err != nil {
+			return nil, err
+		}
 	}
 
 	HoistConstraints(n)
 	for _, def := range n.Defs {
 		switch t := def.(type) {
 		case *tree.ForeignKeyConstraintTableDef:
+			if n.Temporary {
+				// Temporary tables may only reference the temporary tables of
+				// the session, which are found through the search path.
+				if _, err := p.QualifyWithDatabase(ctx, &t.Table); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			if _, err := t.Table.NormalizeWithDatabaseName(p.session.Database); err != nil {
 				return nil, err
 			}
@@ -673,7 +749,7 @@ func (p *planner) CreateTable(ctx context.Context, n *tree.CreateTable) (planNod
 		}
 	}
 
-	return &createTableNode{n: n, dbDesc: dbDesc, sourcePlan: sourcePlan}, nil
+	return &createTableNode{n: n, dbDesc: dbDesc, scDesc: scDesc, sourcePlan: sourcePlan}, nil
 }
 
 // HoistConstraints finds column constraints defined inline with the columns
@@ -712,7 +788,16 @@ func HoistConstraints(n *tree.CreateTable) {
 }
 
 func (n *createTableNode) Start(params runParams) error {
-	tKey := tableKey{parentID: n.dbDesc.ID, name: n.n.Table.TableName().Table()}
+	if n.n.Temporary && n.scDesc == nil {
+		// This is the first temporary table of the session in the database.
+		scDesc, err := params.p.createTempSchema(params.ctx, n.dbDesc)
+		if err != nil {
+			return err
+		}
+		n.scDesc = scDesc
+	}
+
+	tKey := tableKey{parentID: namespaceParentID(n.dbDesc, n.scDesc), name: n.n.Table.TableName().Table()}
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
 		if n.n.IfNotExists {
@@ -730,7 +815,7 @@ func (n *createTableNode) Start(params runParams) error {
 
 	// If a new system table is being created (which should only be doable by
 	// an internal user account), make sure it gets the correct privileges.
-	privs := n.dbDesc.GetPrivileges()
+	privs := createPrivilegeTarget(n.dbDesc, n.scDesc).GetPrivileges()
 	if n.dbDesc.ID == keys.SystemDatabaseID {
 		privs = sqlbase.NewDefaultPrivilegeDescriptor()
 	}
@@ -747,6 +832,12 @@ func (n *createTableNode) Start(params runParams) error {
 	if err != nil {
 		return err
 	}
+	if n.scDesc != nil {
+		desc.ParentSchemaID = n.scDesc.ID
+	}
+	if err := params.p.checkTempTableReferences(params.ctx, &desc, n.n.Temporary); err != nil {
+		return err
+	}
 
 	// We need to validate again after adding the FKs.
 	// Only validate the table because backreferences aren't created yet.
@@ -781,6 +872,14 @@ func (n *createTableNode) Start(params runParams) error {
 		return err
 	}
 
+	if n.n.OnCommit != tree.OnCommitPreserveRows {
+		s := params.p.session
+		if s.tempTablesOnCommit == nil {
+			s.tempTablesOnCommit = make(map[sqlbase.ID]tree.OnCommitAction)
+		}
+		s.tempTablesOnCommit[desc.ID] = n.n.OnCommit
+	}
+
 	// Log Create Table event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
 	if err := MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
@@ -850,6 +949,49 @@ func (n *createTableNode) Close(ctx context.Context) {
 func (*createTableNode) Next(runParams) (bool, error) { return false, nil }
 func (*createTableNode) Values() tree.Datums          { return tree.Datums{} }
 
+// resolveIndexExprs replaces the expression elements of an index with
+// references to the hidden computed columns which back them. The columns which
+// don't exist yet are added to the table with addColumn.
+func resolveIndexExprs(
+	desc *sqlbase.TableDescriptor,
+	elems tree.IndexElemList,
+	searchPath tree.SearchPath,
+	addColumn func(sqlbase.ColumnDescriptor),
+) (tree.IndexElemList, error) {
+	var res tree.IndexElemList
+	for i, elem := range elems {
+		if elem.Expr == nil {
+			continue
+		}
+		if res == nil {
+			res = append(tree.IndexElemList(nil), elems...)
+		}
+		// A parenthesized column name is a plain column reference.
+		if vBase, ok := elem.Expr.(tree.VarName); ok {
+			v, err := vBase.NormalizeVarName()
+			if err != nil {
+				return nil, err
+			}
+			if c, ok := v.(*tree.ColumnItem); ok && c.TableName.TableName == "" {
+				res[i] = tree.IndexElem{Column: c.ColumnName, Direction: elem.Direction}
+				continue
+			}
+		}
+		col, added, err := desc.MakeIndexExprColumn(elem.Expr, searchPath)
+		if err != nil {
+			return nil, err
+		}
+		if added {
+			addColumn(col)
+		}
+		res[i] = tree.IndexElem{Column: tree.Name(col.Name), Direction: elem.Direction}
+	}
+	if res == nil {
+		return elems, nil
+	}
+	return res, nil
+}
+
 type indexMatch bool
 
 const (
@@ -859,13 +1001,17 @@ const (
 
 // Referenced cols must be unique, thus referenced indexes must match exactly.
 // Referencing cols have no uniqueness requirement and thus may match a strict
-// prefix of an index.
+// prefix of an index. Partial indexes, which don't contain all the rows,
+// never match.
 func matchesIndex(
 	cols []sqlbase.ColumnDescriptor, idx sqlbase.IndexDescriptor, exact indexMatch,
 ) bool {
 	if len(cols) > len(idx.ColumnIDs) || (exact && len(cols) != len(idx.ColumnIDs)) {
 		return false
 	}
+	if idx.IsPartial() {
+		return false
+	}
 
 	for i := range cols {
 		if cols[i].ID != idx.ColumnIDs[i] {
@@ -1421,6 +1567,9 @@ func (n *createViewNode) makeViewTableDesc(
 ) (sqlbase.TableDescriptor, error) {
 	desc := initTableDescriptor(id, parentID, viewName, n.p.txn.OrigTimestamp(), privileges)
 	desc.ViewQuery = tree.AsStringWithFlags(n.n.AsSource, tree.FmtParsable)
+	// A materialized view is given a hidden primary key when its IDs are
+	// allocated, like a table without an explicit one.
+	desc.IsMaterializedView = n.n.Materialized
 	for i, colRes := range resultColumns {
 		colType, err := coltypes.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
@@ -1529,6 +1678,16 @@ func MakeTableDesc(
 		}
 	}
 
+	// The computed columns can only be validated once all the columns of the
+	// table are known.
+	for i := range desc.Columns {
+		if col := &desc.Columns[i]; col.IsComputed() {
+			if err := desc.ValidateComputedColumn(col, semaCtx.SearchPath); err != nil {
+				return desc, err
+			}
+		}
+	}
+
 	var primaryIndexColumnSet map[string]struct{}
 	for _, def := range n.Defs {
 		switch d := def.(type) {
@@ -1540,9 +1699,18 @@ func MakeTableDesc(
 				Name:             string(d.Name),
 				StoreColumnNames: d.Storing.ToStrings(),
 			}
-			if err := idx.FillColumns(d.Columns); err != nil {
+			columns, err := resolveIndexExprs(&desc, d.Columns, semaCtx.SearchPath, desc.AddColumn)
+			if err != nil {
+				return desc, err
+			}
+			if err := idx.FillColumns(columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				if idx.Predicate, err = desc.MakeIndexPredicate(d.Predicate, semaCtx.SearchPath); err != nil {
+					return desc, err
+				}
+			}
 			if err := desc.AddIndex(idx, false); err != nil {
 				return desc, err
 			}
@@ -1555,9 +1723,26 @@ func MakeTableDesc(
 				Unique:           true,
 				StoreColumnNames: d.Storing.ToStrings(),
 			}
-			if err := idx.FillColumns(d.Columns); err != nil {
+			columns := d.Columns
+			if !d.PrimaryKey {
+				var err error
+				columns, err = resolveIndexExprs(&desc, d.Columns, semaCtx.SearchPath, desc.AddColumn)
+				if err != nil {
+					return desc, err
+				}
+			}
+			if err := idx.FillColumns(columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				if d.PrimaryKey {
+					return desc, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+						"primary key cannot be a partial index")
+				}
+				if idx.Predicate, err = desc.MakeIndexPredicate(d.Predicate, semaCtx.SearchPath); err != nil {
+					return desc, err
+				}
+			}
 			if err := desc.AddIndex(idx, d.PrimaryKey); err != nil {
 				return desc, err
 			}
@@ -1605,6 +1790,20 @@ func MakeTableDesc(
 		return desc, err
 	}
 
+	// The rows are written by primary key before their computed columns are
+	// computed, so the primary key cannot contain computed columns.
+	for i, colID := range desc.PrimaryIndex.ColumnIDs {
+		col, err := desc.FindColumnByID(colID)
+		if err != nil {
+			return desc, err
+		}
+		if col.IsComputed() {
+			return desc, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+				"computed column %q cannot be part of the primary key",
+				desc.PrimaryIndex.ColumnNames[i])
+		}
+	}
+
 	if n.Interleave != nil {
 		if err := addInterleave(ctx, txn, vt, &desc, &desc.PrimaryIndex, n.Interleave, sessionDB); err != nil {
 			return desc, err
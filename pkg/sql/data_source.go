@@ -199,13 +199,49 @@ type sourceAliases []sourceAlias
 // source (and whether we found one).
 func (s sourceAliases) srcIdx(name tree.TableName) (srcIdx int, found bool) {
 	for i := range s {
-		if s[i].name.DatabaseName == name.DatabaseName && s[i].name.TableName == name.TableName {
+		if _, ok := matchSourceName(s[i].name, name); ok {
 			return i, true
 		}
 	}
 	return -1, false
 }
 
+// matchSourceName checks whether name designates the source with the given
+// alias, and if so returns the name qualified like the alias. The parser
+// cannot tell apart <database>.<table> from <schema>.<table>, nor
+// <prefix>.<database>.<table> from <database>.<schema>.<table>, so the
+// parts of a name that wasn't resolved yet are also matched against the
+// database and schema of the alias. Tables that aren't in a user-defined
+// schema can also be qualified with the public schema.
+func matchSourceName(alias, name tree.TableName) (tree.TableName, bool) {
+	if alias.TableName != name.TableName {
+		return tree.TableName{}, false
+	}
+	if name.SchemaName != "" {
+		// The name was already resolved.
+		return name, alias.DatabaseName == name.DatabaseName && alias.SchemaName == name.SchemaName
+	}
+	schema := alias.SchemaName
+	if schema == "" {
+		if alias.DatabaseName == name.DatabaseName {
+			return name, true
+		}
+		schema = tree.PublicSchemaName
+	}
+	if name.PrefixOriginallySpecified {
+		if alias.DatabaseName != name.PrefixName || schema != name.DatabaseName {
+			return tree.TableName{}, false
+		}
+	} else if schema != name.DatabaseName {
+		return tree.TableName{}, false
+	}
+	name.PrefixName = ""
+	name.PrefixOriginallySpecified = false
+	name.DatabaseName = alias.DatabaseName
+	name.SchemaName = alias.SchemaName
+	return name, true
+}
+
 // columnSet looks up a source by name and returns the column set (and
 // whether we found the name).
 func (s sourceAliases) columnSet(name tree.TableName) (_ util.FastIntSet, found bool) {
@@ -240,8 +276,11 @@ func newSourceInfoForSingleTable(tn tree.TableName, columns sqlbase.ResultColumn
 }
 
 // getSources combines zero or more FROM sources into cross-joins.
+// scanVisibility only applies to the first source, which is the target
+// table of UPDATE ... FROM and DELETE ... USING; the sources it is joined
+// with only expose their public columns.
 func (p *planner) getSources(
-	ctx context.Context, sources []tree.TableExpr, scanVisibility scanVisibility, lockForUpdate bool,
+	ctx context.Context, sources []tree.TableExpr, scanVisibility scanVisibility, locking sqlbase.ScanLocking,
 ) (planDataSource, error) {
 	switch len(sources) {
 	case 0:
@@ -252,14 +291,14 @@ func (p *planner) getSources(
 		}, nil
 
 	case 1:
-		return p.getDataSource(ctx, sources[0], nil /* hints */, scanVisibility, lockForUpdate)
+		return p.getDataSource(ctx, sources[0], nil /* hints */, scanVisibility, locking)
 
 	default:
-		left, err := p.getDataSource(ctx, sources[0], nil /* hints */, scanVisibility, lockForUpdate)
+		left, err := p.getDataSource(ctx, sources[0], nil /* hints */, scanVisibility, locking)
 		if err != nil {
 			return planDataSource{}, err
 		}
-		right, err := p.getSources(ctx, sources[1:], scanVisibility, lockForUpdate)
+		right, err := p.getSources(ctx, sources[1:], publicColumns, locking)
 		if err != nil {
 			return planDataSource{}, err
 		}
@@ -334,7 +373,7 @@ func (p *planner) getVirtualDataSource(
 func (p *planner) getDataSourceAsOneColumn(
 	ctx context.Context, src *tree.FuncExpr,
 ) (planDataSource, error) {
-	ds, err := p.getDataSource(ctx, src, nil /* hints */, publicColumns, false /* lockForUpdate */)
+	ds, err := p.getDataSource(ctx, src, nil /* hints */, publicColumns, sqlbase.ScanLocking{})
 	if err != nil {
 		return ds, err
 	}
@@ -369,7 +408,7 @@ func (p *planner) getDataSource(
 	src tree.TableExpr,
 	hints *tree.IndexHints,
 	scanVisibility scanVisibility,
-	lockForUpdate bool,
+	locking sqlbase.ScanLocking,
 ) (planDataSource, error) {
 	switch t := src.(type) {
 	case *tree.NormalizableTableName:
@@ -387,7 +426,7 @@ func (p *planner) getDataSource(
 		if foundVirtual {
 			return ds, nil
 		}
-		return p.getTableScanOrViewPlan(ctx, tn, hints, scanVisibility, lockForUpdate)
+		return p.getTableScanOrViewPlan(ctx, tn, hints, scanVisibility, locking)
 
 	case *tree.FuncExpr:
 		return p.getGeneratorPlan(ctx, t)
@@ -397,11 +436,11 @@ func (p *planner) getDataSource(
 
 	case *tree.JoinTableExpr:
 		// Joins: two sources.
-		left, err := p.getDataSource(ctx, t.Left, nil /* hints */, scanVisibility, lockForUpdate)
+		left, err := p.getDataSource(ctx, t.Left, nil /* hints */, scanVisibility, locking)
 		if err != nil {
 			return left, err
 		}
-		right, err := p.getDataSource(ctx, t.Right, nil /* hints */, scanVisibility, lockForUpdate)
+		right, err := p.getDataSource(ctx, t.Right, nil /* hints */, scanVisibility, locking)
 		if err != nil {
 			return right, err
 		}
@@ -418,10 +457,10 @@ func (p *planner) getDataSource(
 		}, nil
 
 	case *tree.ParenTableExpr:
-		return p.getDataSource(ctx, t.Expr, hints, scanVisibility, lockForUpdate)
+		return p.getDataSource(ctx, t.Expr, hints, scanVisibility, locking)
 
 	case *tree.TableRef:
-		return p.getTableScanByRef(ctx, t, hints, scanVisibility, lockForUpdate)
+		return p.getTableScanByRef(ctx, t, hints, scanVisibility, locking)
 
 	case *tree.AliasedTableExpr:
 		// Alias clause: source AS alias(cols...)
@@ -430,7 +469,7 @@ func (p *planner) getDataSource(
 			hints = t.Hints
 		}
 
-		src, err := p.getDataSource(ctx, t.Expr, hints, scanVisibility, lockForUpdate)
+		src, err := p.getDataSource(ctx, t.Expr, hints, scanVisibility, locking)
 		if err != nil {
 			return src, err
 		}
@@ -461,6 +500,13 @@ func (p *planner) QualifyWithDatabase(
 		if err := p.searchAndQualifyDatabase(ctx, tn); err != nil {
 			return nil, err
 		}
+	} else if !tn.DBNameOriginallyOmitted {
+		// The name is already qualified; this merely records the session
+		// database, in which names of the form <schema>.<table> are
+		// resolved.
+		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+			return nil, err
+		}
 	}
 	return tn, nil
 }
@@ -480,7 +526,7 @@ func (p *planner) getTableScanByRef(
 	tref *tree.TableRef,
 	hints *tree.IndexHints,
 	scanVisibility scanVisibility,
-	lockForUpdate bool,
+	locking sqlbase.ScanLocking,
 ) (planDataSource, error) {
 	desc, err := p.getTableDescByID(ctx, sqlbase.ID(tref.TableID))
 	if err != nil {
@@ -500,7 +546,7 @@ func (p *planner) getTableScanByRef(
 		DBNameOriginallyOmitted: true,
 	}
 
-	src, err := p.getPlanForDesc(ctx, desc, &tn, hints, scanVisibility, lockForUpdate, tref.Columns)
+	src, err := p.getPlanForDesc(ctx, desc, &tn, hints, scanVisibility, locking, tref.Columns)
 	if err != nil {
 		return src, err
 	}
@@ -574,20 +620,14 @@ func (p *planner) getTableScanOrViewPlan(
 	tn *tree.TableName,
 	hints *tree.IndexHints,
 	scanVisibility scanVisibility,
-	lockForUpdate bool,
+	locking sqlbase.ScanLocking,
 ) (planDataSource, error) {
-	if tn.PrefixOriginallySpecified {
-		// Prefixes are currently only supported for virtual tables.
-		return planDataSource{}, tree.NewInvalidNameErrorf(
-			"invalid table name: %q", tree.ErrString(tn))
-	}
-
 	desc, err := p.getTableDesc(ctx, tn)
 	if err != nil {
 		return planDataSource{}, err
 	}
 
-	return p.getPlanForDesc(ctx, desc, tn, hints, scanVisibility, lockForUpdate, nil /* wantedColumns */)
+	return p.getPlanForDesc(ctx, desc, tn, hints, scanVisibility, locking, nil /* wantedColumns */)
 }
 
 func (p *planner) getTableDesc(
@@ -598,8 +638,15 @@ func (p *planner) getTableDesc(
 		// specified time, and never lease anything. The proto transaction already
 		// has its timestamps set correctly so getTableOrViewDesc will fetch with
 		// the correct timestamp.
-		return MustGetTableOrViewDesc(
+		desc, err := MustGetTableOrViewDesc(
 			ctx, p.txn, p.getVirtualTabler(), tn, false /*allowAdding*/)
+		if err != nil {
+			return nil, err
+		}
+		if IsTempSchemaName(tn.Schema()) && tn.Schema() != p.session.tempSchemaName {
+			return nil, errTempTableOfOtherSession()
+		}
+		return desc, nil
 	}
 	return p.session.tables.getTableVersion(ctx, p.txn, p.getVirtualTabler(), tn)
 }
@@ -610,26 +657,33 @@ func (p *planner) getPlanForDesc(
 	tn *tree.TableName,
 	hints *tree.IndexHints,
 	scanVisibility scanVisibility,
-	lockForUpdate bool,
+	locking sqlbase.ScanLocking,
 	wantedColumns []tree.ColumnID,
 ) (planDataSource, error) {
-	if desc.IsView() {
+	if desc.IsView() && !desc.MaterializedView() {
 		if wantedColumns != nil {
 			return planDataSource{},
 				errors.Errorf("cannot specify an explicit column list when accessing a view by reference")
 		}
 		return p.getViewPlan(ctx, tn, desc)
-	} else if !desc.IsTable() {
+	} else if !desc.IsTable() && !desc.MaterializedView() {
 		return planDataSource{}, errors.Errorf(
 			"unexpected table descriptor of type %s for %q", desc.TypeName(), tree.ErrString(tn))
 	}
 
-	// This name designates a real table.
+	// This name designates a real table, or a materialized view whose
+	// results are stored like a table's rows.
 	scan := p.Scan()
 	if err := scan.initTable(p, desc, hints, scanVisibility, wantedColumns); err != nil {
 		return planDataSource{}, err
 	}
-	scan.lockForUpdate = lockForUpdate
+	if locking.ForUpdate {
+		// Like Postgres, require UPDATE privileges to lock rows.
+		if err := p.CheckPrivilege(desc, privilege.UPDATE); err != nil {
+			return planDataSource{}, err
+		}
+	}
+	scan.locking = locking
 
 	return planDataSource{
 		info: newSourceInfoForSingleTable(*tn, planColumns(scan)),
@@ -682,6 +736,10 @@ func (p *planner) getViewPlan(
 		p.planDeps = nil
 	}
 
+	// The view's query can't refer to the columns of the query using the view.
+	defer func(prev *subqueryScope) { p.subqueryScope = prev }(p.subqueryScope)
+	p.subqueryScope = nil
+
 	// TODO(a-robinson): Support ORDER BY and LIMIT in views. Is it as simple as
 	// just passing the entire select here or will inserting an ORDER BY in the
 	// middle of a query plan break things?
@@ -793,6 +851,7 @@ func (sources multiSourceInfo) checkDatabaseName(tn tree.TableName) (tree.TableN
 						return tree.TableName{}, newAmbiguousSourceError(tn.TableName, "")
 					}
 					tn.DatabaseName = alias.name.DatabaseName
+					tn.SchemaName = alias.name.SchemaName
 					found = true
 				}
 			}
@@ -803,20 +862,25 @@ func (sources multiSourceInfo) checkDatabaseName(tn tree.TableName) (tree.TableN
 		return tn, nil
 	}
 
-	// Database given. Check that the name is unambiguous.
+	// Database or schema given. Check that the name is unambiguous.
+	var res tree.TableName
 	found := false
 	for _, src := range sources {
-		if _, ok := src.sourceAliases.srcIdx(tn); ok {
-			if found {
-				return tree.TableName{}, newAmbiguousSourceError(tn.TableName, tn.DatabaseName)
+		for _, alias := range src.sourceAliases {
+			if qualified, ok := matchSourceName(alias.name, tn); ok {
+				if found {
+					return tree.TableName{}, newAmbiguousSourceError(tn.TableName, tn.DatabaseName)
+				}
+				res = qualified
+				found = true
+				break
 			}
-			found = true
 		}
 	}
 	if !found {
 		return tree.TableName{}, newUnknownSourceError(&tn)
 	}
-	return tn, nil
+	return res, nil
 }
 
 // checkDatabaseName checks whether the given TableName is unambiguous
@@ -832,6 +896,7 @@ func (src *dataSourceInfo) checkDatabaseName(tn tree.TableName) (tree.TableName,
 				}
 				found = true
 				tn.DatabaseName = alias.name.DatabaseName
+				tn.SchemaName = alias.name.SchemaName
 			}
 		}
 		if !found {
@@ -840,11 +905,13 @@ func (src *dataSourceInfo) checkDatabaseName(tn tree.TableName) (tree.TableName,
 		return tn, nil
 	}
 
-	// Database given.
-	if _, found := src.sourceAliases.srcIdx(tn); !found {
-		return tree.TableName{}, newUnknownSourceError(&tn)
+	// Database or schema given.
+	for _, alias := range src.sourceAliases {
+		if qualified, ok := matchSourceName(alias.name, tn); ok {
+			return qualified, nil
+		}
 	}
-	return tn, nil
+	return tree.TableName{}, newUnknownSourceError(&tn)
 }
 
 func findColHelper(
@@ -881,9 +948,12 @@ func (sources multiSourceInfo) findColumn(c *tree.ColumnItem) (srcIdx int, colId
 		}
 		tableName = tn
 
-		// Propagate the discovered database name back to the original VarName.
-		// (to clarify the output of e.g. EXPLAIN)
+		// Propagate the discovered database and schema names back to the
+		// original VarName (to clarify the output of e.g. EXPLAIN).
+		c.TableName.PrefixName = tableName.PrefixName
+		c.TableName.PrefixOriginallySpecified = tableName.PrefixOriginallySpecified
 		c.TableName.DatabaseName = tableName.DatabaseName
+		c.TableName.SchemaName = tableName.SchemaName
 	}
 
 	colIdx = invalidColIdx
@@ -944,6 +1014,10 @@ func (src *dataSourceInfo) FormatVar(buf *bytes.Buffer, f tree.FmtFlags, colIdx
 					tree.FormatNode(buf, f, tableAlias.DatabaseName)
 					buf.WriteByte('.')
 				}
+				if tableAlias.SchemaName != "" {
+					tree.FormatNode(buf, f, tableAlias.SchemaName)
+					buf.WriteByte('.')
+				}
 				tree.FormatNode(buf, f, tableAlias.TableName)
 				buf.WriteByte('.')
 			}
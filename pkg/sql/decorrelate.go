@@ -0,0 +1,244 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// decorrelateWhere plans the correlated [NOT] EXISTS subqueries found among
+// the conjuncts of a WHERE clause as semi (resp. anti) joins of the source of
+// the renderNode with the source of the subquery. It returns the remaining
+// WHERE expression, which is nil if all the conjuncts have been decorrelated.
+//
+// For example:
+//
+//   SELECT * FROM t WHERE EXISTS (SELECT * FROM u WHERE u.a = t.a) AND t.b > 1
+//
+// is planned as:
+//
+//   SELECT * FROM (t SEMI JOIN u ON u.a = t.a) WHERE t.b > 1
+//
+// Only subqueries which are simple enough to be expressed as a join are
+// decorrelated (see decorrelatableSelect); the others are run again for every
+// row of the source (see subquery.evalCorrelated).
+func (r *renderNode) decorrelateWhere(ctx context.Context, where tree.Expr) (tree.Expr, error) {
+	if isUnarySource(r.source) {
+		return where, nil
+	}
+	var remaining tree.Expr
+	for _, e := range splitAndAST(where, nil) {
+		ok, err := r.decorrelateExists(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			continue
+		}
+		if remaining == nil {
+			remaining = e
+		} else {
+			remaining = &tree.AndExpr{Left: remaining, Right: e}
+		}
+	}
+	return remaining, nil
+}
+
+// splitAndAST splits an expression, before semantic analysis, into its
+// conjuncts.
+func splitAndAST(e tree.Expr, exprs tree.Exprs) tree.Exprs {
+	switch t := e.(type) {
+	case *tree.AndExpr:
+		return splitAndAST(t.Right, splitAndAST(t.Left, exprs))
+	case *tree.ParenExpr:
+		return splitAndAST(t.Expr, exprs)
+	}
+	return append(exprs, e)
+}
+
+// decorrelateExists attempts to replace the source of the renderNode by a
+// semi or anti join implementing the given [NOT] EXISTS expression. It
+// returns false if the expression can't be decorrelated.
+func (r *renderNode) decorrelateExists(ctx context.Context, e tree.Expr) (bool, error) {
+	typ := joinTypeSemi
+	if not, ok := tree.StripParens(e).(*tree.NotExpr); ok {
+		typ = joinTypeAnti
+		e = not.Expr
+	}
+	exists, ok := tree.StripParens(e).(*tree.ExistsExpr)
+	if !ok {
+		return false, nil
+	}
+	sq, ok := exists.Subquery.(*tree.Subquery)
+	if !ok {
+		return false, nil
+	}
+	sel := decorrelatableSelect(sq.Select)
+	if sel == nil {
+		return false, nil
+	}
+
+	p := r.planner
+	left := r.source
+	numLeft := len(left.info.sourceColumns)
+
+	// Plan the source of the subquery, and analyze its WHERE clause. The names
+	// which are not found in the subquery are resolved against the source of
+	// the renderNode.
+	scope := &subqueryScope{
+		parent:     p.subqueryScope,
+		sources:    r.sourceInfo,
+		ivarHelper: tree.MakeIndexedVarHelper(nil, numLeft),
+	}
+	defer func(prev *subqueryScope) { p.subqueryScope = prev }(p.subqueryScope)
+	p.subqueryScope = scope
+
+	inner := &renderNode{planner: p}
+	if err := inner.initFrom(ctx, sel, publicColumns, sqlbase.ScanLocking{}); err != nil {
+		return false, err
+	}
+	if len(scope.vars) > 0 {
+		// The FROM clause of the subquery is itself correlated.
+		inner.source.plan.Close(ctx)
+		return false, nil
+	}
+	innerFilter := &filterNode{source: inner.source}
+	filter, err := p.analyzeExpr(
+		ctx, sel.Where.Expr, inner.sourceInfo,
+		tree.MakeIndexedVarHelper(innerFilter, len(inner.source.info.sourceColumns)),
+		types.Bool, true /* requireType */, "WHERE",
+	)
+	if err == nil {
+		err = p.txCtx.AssertNoAggregationOrWindowing(filter, "WHERE", p.session.SearchPath)
+	}
+	if err != nil || len(scope.vars) == 0 {
+		// Uncorrelated subqueries are run only once; they don't need to be
+		// decorrelated.
+		inner.source.plan.Close(ctx)
+		return false, err
+	}
+
+	pred, _, err := makeCrossPredicate(typ, left.info, inner.source.info)
+	if err != nil {
+		inner.source.plan.Close(ctx)
+		return false, err
+	}
+	// Make the filter refer to the columns of the join.
+	v := decorrelateVisitor{pred: pred, numLeft: numLeft, scope: scope}
+	onCond, _ := tree.WalkExpr(&v, filter)
+	pred.onCond = onCond.(tree.TypedExpr)
+
+	columns := append(sqlbase.ResultColumns(nil), left.info.sourceColumns...)
+	r.source = planDataSource{
+		info: left.info,
+		plan: p.newJoinNode(typ, left, inner.source, pred, columns),
+	}
+	return true, nil
+}
+
+// decorrelatableSelect returns the SELECT clause of an EXISTS subquery if the
+// subquery can be decorrelated: it must have a FROM and a WHERE clause, and its
+// results must be the rows of its source filtered by the WHERE clause. The
+// rendered expressions are ignored by EXISTS; only constants and stars are
+// accepted, so that they can't be invalid.
+func decorrelatableSelect(stmt tree.SelectStatement) *tree.SelectClause {
+	for {
+		switch t := stmt.(type) {
+		case *tree.ParenSelect:
+			sel := t.Select
+			if len(sel.OrderBy) > 0 || sel.Limit != nil || sel.Locking.Strength != tree.ForNone {
+				return nil
+			}
+			stmt = sel.Select
+		case *tree.SelectClause:
+			if t.Distinct || len(t.GroupBy) > 0 || t.Having != nil || len(t.Window) > 0 ||
+				t.TableSelect || t.Where == nil || t.From == nil || len(t.From.Tables) == 0 ||
+				t.From.AsOf.Expr != nil || containsSubquery(t.Where.Expr) {
+				return nil
+			}
+			for _, target := range t.Exprs {
+				switch target.Expr.(type) {
+				case tree.UnqualifiedStar, *tree.AllColumnsSelector, tree.Constant, tree.Datum:
+				default:
+					return nil
+				}
+			}
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+// containsSubquery returns true if the expression contains a subquery.
+func containsSubquery(e tree.Expr) bool {
+	var v subqueryFinder
+	tree.WalkExprConst(&v, e)
+	return v.found
+}
+
+// subqueryFinder is a tree.Visitor which detects subqueries.
+type subqueryFinder struct {
+	found bool
+}
+
+var _ tree.Visitor = &subqueryFinder{}
+
+func (v *subqueryFinder) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch expr.(type) {
+	case *tree.Subquery, *subquery:
+		v.found = true
+	}
+	return !v.found, expr
+}
+
+func (v *subqueryFinder) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// decorrelateVisitor converts the WHERE clause of a decorrelated subquery,
+// where the IndexedVars refer to the columns of the subquery's source and
+// outerVars to the columns of the enclosing query, into the ON condition of
+// the join.
+type decorrelateVisitor struct {
+	pred    *joinPredicate
+	numLeft int
+	scope   *subqueryScope
+}
+
+var _ tree.Visitor = &decorrelateVisitor{}
+
+func (v *decorrelateVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch t := expr.(type) {
+	case *tree.IndexedVar:
+		return false, v.pred.iVarHelper.IndexedVar(v.numLeft + t.Idx)
+	case *outerVar:
+		for i := range v.scope.vars {
+			if v.scope.vars[i] != t {
+				continue
+			}
+			if key := v.scope.keys[i]; key.parent != nil {
+				// A column of a query enclosing the renderNode itself.
+				return false, key.parent
+			}
+			return false, v.pred.iVarHelper.IndexedVar(v.scope.keys[i].idx)
+		}
+	}
+	return true, expr
+}
+
+func (v *decorrelateVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
@@ -59,7 +59,11 @@ func (p *planner) Delete(
 		return nil, pgerror.NewDangerousStatementErrorf("DELETE without WHERE clause")
 	}
 
-	tn, err := p.getAliasedTableName(n.Table)
+	if len(n.Using) > 0 && n.Limit != nil {
+		return nil, pgerror.Unimplemented("delete using limit", "DELETE ... USING does not support LIMIT")
+	}
+
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -69,8 +73,9 @@ func (p *planner) Delete(
 		return nil, err
 	}
 
+	_, retExprs := n.Returning.(*tree.ReturningExprs)
 	var requestedCols []sqlbase.ColumnDescriptor
-	if _, retExprs := n.Returning.(*tree.ReturningExprs); retExprs {
+	if retExprs {
 		// TODO(dan): This could be made tighter, just the rows needed for RETURNING
 		// exprs.
 		requestedCols = en.tableDesc.Columns
@@ -92,16 +97,32 @@ func (p *planner) Delete(
 	// this node's initSelect() method both does type checking and also
 	// performs index selection. We cannot perform index selection
 	// properly until the placeholder values are known.
+	//
+	// With a USING clause, the query joins the table with the other data
+	// sources.
+	targetName := editTargetName(n.Table, tn)
+	fetchExprs := sqlbase.ColumnsSelectors(rd.FetchCols)
+	if len(n.Using) > 0 {
+		fetchExprs = editColumnsSelectors(rd.FetchCols, targetName)
+	}
 	rows, err := p.SelectClause(ctx, &tree.SelectClause{
-		Exprs: sqlbase.ColumnsSelectors(rd.FetchCols),
-		From:  &tree.From{Tables: []tree.TableExpr{n.Table}},
+		Exprs: fetchExprs,
+		From:  &tree.From{Tables: append(tree.TableExprs{n.Table}, n.Using...)},
 		Where: n.Where,
-	}, nil /* orderBy */, n.Limit, false, /* lockForUpdate */
+	}, nil /* orderBy */, n.Limit, sqlbase.ScanLocking{},
 		nil /* desiredTypes */, publicAndNonPublicColumns)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(n.Using) > 0 {
+		en.join, err = newEditJoinHelper(
+			p, rows.(*renderNode), targetName, en.tableDesc, rd.FetchColIDtoRowIndex, retExprs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	dn := deleteNodePool.Get().(*deleteNode)
 	*dn = deleteNode{
 		n:            n,
@@ -142,6 +163,9 @@ func (d *deleteNode) Start(params runParams) error {
 func (d *deleteNode) Close(ctx context.Context) {
 	d.run.rows.Close(ctx)
 	d.tw.close(ctx)
+	if d.join != nil {
+		d.join.close(ctx, d.p.session)
+	}
 	*d = deleteNode{}
 	deleteNodePool.Put(d)
 }
@@ -160,7 +184,7 @@ func (d *deleteNode) Next(params runParams) (bool, error) {
 
 	traceKV := d.p.session.Tracing.KVTracingEnabled()
 
-	next, err := d.run.rows.Next(params)
+	next, err := d.run.nextRow(params, &d.editNodeBase, len(d.tw.rd.FetchCols))
 	if !next {
 		if err == nil {
 			if err := params.p.cancelChecker.Check(); err != nil {
@@ -173,12 +197,19 @@ func (d *deleteNode) Next(params runParams) (bool, error) {
 	}
 
 	rowVals := d.run.rows.Values()
+	var sourceVals tree.Datums
+	if d.join != nil {
+		sourceVals = d.join.sourceValues(rowVals)
+	}
 
 	_, err = d.tw.row(params.ctx, rowVals, traceKV)
 	if err != nil {
 		return false, err
 	}
 
+	if d.join != nil {
+		rowVals = d.join.returningRow(rowVals, len(d.tableDesc.Columns), sourceVals)
+	}
 	resultRow, err := d.rh.cookResultRow(rowVals)
 	if err != nil {
 		return false, err
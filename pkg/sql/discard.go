@@ -42,6 +42,11 @@ func (p *planner) Discard(ctx context.Context, s *tree.Discard) (planNode, error
 
 		// DEALLOCATE ALL
 		p.session.PreparedStatements.DeleteAll(ctx)
+
+		// DISCARD TEMP
+		return p.DiscardTemp(ctx)
+	case tree.DiscardModeTemp:
+		return p.DiscardTemp(ctx)
 	default:
 		return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
 			"unknown mode for DISCARD: %d", s.Mode)
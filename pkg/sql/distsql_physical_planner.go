@@ -109,6 +109,28 @@ var planMergeJoins = settings.RegisterBoolSetting(
 	true,
 )
 
+// planLookupJoins is off by default: without table statistics, a lookup join
+// is chosen whenever the right side is indexed on the equality columns, and
+// it is much slower than a hash join when the left side is large.
+var planLookupJoins = settings.RegisterBoolSetting(
+	"sql.distsql.lookup_joins.enabled",
+	"if set, we plan lookup joins when the right side of a join is a table "+
+		"indexed on the equality columns",
+	false,
+)
+
+// distributeMutations is off by default: the TableWriters (and the
+// TableReaders of locking scans) write on behalf of the transaction without
+// going through its TxnCoordSender, and the way this interacts with
+// transaction retries and with splits and lease transfers of the ranges being
+// written is not covered by tests yet.
+var distributeMutations = settings.RegisterBoolSetting(
+	"sql.distsql.distribute_mutations.enabled",
+	"if set, INSERT, UPDATE and DELETE statements can be run by DistSQL, "+
+		"writing on the nodes that produce the rows",
+	false,
+)
+
 // NewDistSQLPlanner initializes a DistSQLPlanner
 func NewDistSQLPlanner(
 	ctx context.Context,
@@ -157,15 +179,54 @@ func (v *distSQLExprCheckVisitor) VisitPre(expr tree.Expr) (recurse bool, newExp
 		return false, expr
 	}
 	switch t := expr.(type) {
-	case *subquery, *tree.Subquery:
+	case *subquery:
+		if t.correlated() {
+			v.err = newQueryNotSupportedError("correlated subqueries not supported yet")
+			return false, expr
+		}
+		// Subqueries are evaluated before the plan is distributed (see
+		// planAndRunSubqueries) and their results are serialized in place of
+		// the subquery. Sets compared against with IN, ANY, SOME or ALL are
+		// serialized as tuples (see distsqlplan.EvaluatedSetExpr); the
+		// results of ARRAY subqueries have no literal form.
+		switch t.execMode {
+		case execModeExists, execModeAllRowsNormalized:
+		case execModeOneRow:
+			if _, ok := t.typ.(types.TTuple); ok {
+				v.err = newQueryNotSupportedError("subqueries returning tuples not supported yet")
+				return false, expr
+			}
+		default:
+			v.err = newQueryNotSupportedError("ARRAY subqueries not supported yet")
+			return false, expr
+		}
+		return false, expr
+
+	case *tree.Subquery:
 		v.err = newQueryNotSupportedError("subqueries not supported yet")
 		return false, expr
 
+	case *outerVar:
+		v.err = newQueryNotSupportedError("correlated subqueries not supported yet")
+		return false, expr
+
 	case *tree.FuncExpr:
 		if t.IsDistSQLBlacklist() {
 			v.err = newQueryNotSupportedErrorf("function %s cannot be executed with distsql", t)
 			return false, expr
 		}
+
+	case *tree.IndexedVar:
+		// The types of the columns are sent along with the processor specs.
+		return false, expr
+	}
+	// Values of user-defined types cannot be serialized: the remote nodes
+	// would not be able to resolve their type.
+	if typedExpr, ok := expr.(tree.TypedExpr); ok {
+		if _, ok := types.UnwrapType(typedExpr.ResolvedType()).(types.TEnum); ok {
+			v.err = newQueryNotSupportedError("user-defined types not supported yet")
+			return false, expr
+		}
 	}
 	return true, expr
 }
@@ -315,8 +376,11 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 			// ranges at a time.
 			rec = shouldNotDistribute
 		}
-		if n.lockForUpdate {
-			return 0, newQueryNotSupportedError("explicit locking with FOR UPDATE is not yet supported")
+		if n.locking.ForUpdate && !distributeMutations.Get(&dsp.st.SV) {
+			// Locks are intents laid down by the table readers on behalf of the
+			// transaction, like the writes of distributed mutations.
+			return 0, newQueryNotSupportedError(
+				"scans with FOR UPDATE require sql.distsql.distribute_mutations.enabled")
 		}
 		// We recommend running scans distributed if we have a filtering
 		// expression or if we have a full table scan.
@@ -333,6 +397,10 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return rec, nil
 
 	case *indexJoinNode:
+		if n.table.locking.ForUpdate {
+			// The rows would have to be locked by the joinReader.
+			return 0, newQueryNotSupportedError("index joins with FOR UPDATE cannot be distributed")
+		}
 		// n.table doesn't have meaningful spans, but we need to check support (e.g.
 		// for any filtering expression).
 		if _, err := dsp.checkSupportForNode(n.table); err != nil {
@@ -382,8 +450,7 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return shouldDistribute, nil
 
 	case *insertNode, *updateNode, *deleteNode:
-		// This is a potential hot path.
-		return 0, mutationsNotSupportedError
+		return dsp.checkSupportForMutation(n)
 
 	case *setNode, *setClusterSettingNode:
 		// SET statements are never distributed.
@@ -404,6 +471,16 @@ type planningCtx struct {
 	// physicalPlan we generate with this context.
 	// Nodes that fail a health check have empty addresses.
 	nodeAddresses map[roachpb.NodeID]string
+	// writeAnchor is set if the plan writes on behalf of the transaction; it
+	// is the key at which the transaction record is anchored if it hasn't
+	// been written yet.
+	writeAnchor roachpb.Key
+	// writeSpans are the spans that the plan may write to, if writeAnchor is
+	// set.
+	writeSpans roachpb.Spans
+	// collectStats is set if the flows of the plan must collect execution
+	// statistics (see EXPLAIN ANALYZE).
+	collectStats bool
 }
 
 // sanityCheckAddresses returns an error if the same address is used by two
@@ -658,8 +735,10 @@ func initTableReaderSpec(
 	n *scanNode,
 ) (distsqlrun.TableReaderSpec, distsqlrun.PostProcessSpec, error) {
 	s := distsqlrun.TableReaderSpec{
-		Table:   *n.desc,
-		Reverse: n.reverse,
+		Table:         *n.desc,
+		Reverse:       n.reverse,
+		LockForUpdate: n.locking.ForUpdate,
+		LockNoWait:    n.locking.NoWait,
 	}
 	if n.index != &n.desc.PrimaryIndex {
 		for i := range n.desc.Indexes {
@@ -757,6 +836,13 @@ func (dsp *DistSQLPlanner) createTableReaders(
 	if err != nil {
 		return physicalPlan{}, err
 	}
+	if spec.LockForUpdate {
+		// The table readers lock the rows they output in the primary index.
+		if planCtx.writeAnchor == nil {
+			planCtx.writeAnchor = sqlbase.MakeIndexKeyPrefix(n.desc, n.desc.PrimaryIndex.ID)
+		}
+		planCtx.writeSpans = append(planCtx.writeSpans, n.desc.IndexSpan(n.desc.PrimaryIndex.ID))
+	}
 
 	spanPartitions, err := dsp.partitionSpans(planCtx, n.spans)
 	if err != nil {
@@ -1334,6 +1420,29 @@ func (dsp *DistSQLPlanner) addAggregators(
 		groupCols[i] = uint32(p.planToStreamColMap[i])
 	}
 
+	// If the input is ordered on some of the grouping columns, the aggregators
+	// can output each group as soon as they see a row with different values
+	// for these columns. With multiple streams, only the ordering which is
+	// maintained when merging them can be used.
+	inputOrdering := p.MergeOrdering
+	if len(p.ResultRouters) == 1 {
+		inputOrdering = dsp.convertOrdering(planPhysicalProps(n.plan), p.planToStreamColMap)
+	}
+	var orderedGroupCols []uint32
+	for _, o := range inputOrdering.Columns {
+		isGroupCol := false
+		for _, c := range groupCols {
+			if c == o.ColIdx {
+				isGroupCol = true
+				break
+			}
+		}
+		if !isGroupCol {
+			break
+		}
+		orderedGroupCols = append(orderedGroupCols, o.ColIdx)
+	}
+
 	// We either have a local stage on each stream followed by a final stage, or
 	// just a final stage. We only use a local stage if:
 	//  - the previous stage is distributed on multiple nodes, and
@@ -1432,6 +1541,11 @@ func (dsp *DistSQLPlanner) addAggregators(
 			Aggregations: aggregations,
 			GroupCols:    groupCols,
 		}
+		// With multiple streams, the final stage is distributed by hash and the
+		// input ordering is lost; see below.
+		if len(p.ResultRouters) == 1 {
+			finalAggsSpec.OrderedGroupCols = orderedGroupCols
+		}
 	} else {
 		// Some aggregations might need multiple aggregation as part of
 		// their local and final stages (along with a final render
@@ -1622,8 +1736,9 @@ func (dsp *DistSQLPlanner) addAggregators(
 		}
 
 		localAggsSpec := distsqlrun.AggregatorSpec{
-			Aggregations: localAggs,
-			GroupCols:    groupCols,
+			Aggregations:     localAggs,
+			GroupCols:        groupCols,
+			OrderedGroupCols: orderedGroupCols,
 		}
 
 		p.AddNoGroupingStage(
@@ -1845,6 +1960,168 @@ ColLoop:
 	return plan, nil
 }
 
+// findLookupIndex returns the index of the table scanned by n whose first
+// columns are exactly the given columns of n (in any order), along with the
+// position of each of these index columns in cols. The primary index is
+// preferred; partial indexes, which don't contain all the rows, are not
+// considered. Returns ok=false if there is no such index.
+func findLookupIndex(n *scanNode, cols []int) (indexIdx uint32, colPos []int, ok bool) {
+	match := func(index *sqlbase.IndexDescriptor) []int {
+		if len(index.ColumnIDs) < len(cols) || index.IsPartial() {
+			return nil
+		}
+		pos := make([]int, len(cols))
+	IndexColLoop:
+		for i, colID := range index.ColumnIDs[:len(cols)] {
+			for j, c := range cols {
+				if n.cols[c].ID == colID {
+					pos[i] = j
+					continue IndexColLoop
+				}
+			}
+			return nil
+		}
+		return pos
+	}
+	if pos := match(&n.desc.PrimaryIndex); pos != nil {
+		return 0, pos, true
+	}
+	for i := range n.desc.Indexes {
+		if pos := match(&n.desc.Indexes[i]); pos != nil {
+			// IndexIdx is 1 based (0 means primary index).
+			return uint32(i + 1), pos, true
+		}
+	}
+	return 0, nil, false
+}
+
+// tryCreatePlanForLookupJoin plans a join as a lookup join if the right side
+// is a table indexed on the equality columns: for each row on the left side,
+// the join readers look up the matching rows in the index. This avoids
+// reading the entire right side. Returns ok=false if a lookup join can't be
+// used.
+func (dsp *DistSQLPlanner) tryCreatePlanForLookupJoin(
+	planCtx *planningCtx, n *joinNode, leftPlan physicalPlan,
+) (_ physicalPlan, ok bool) {
+	var joinType distsqlrun.JoinType
+	switch n.joinType {
+	case joinTypeInner:
+		joinType = distsqlrun.JoinType_INNER
+	case joinTypeLeftOuter:
+		joinType = distsqlrun.JoinType_LEFT_OUTER
+	default:
+		return physicalPlan{}, false
+	}
+	scan, isScan := n.right.plan.(*scanNode)
+	if !isScan || len(n.pred.rightEqualityIndices) == 0 || scan.hardLimit != 0 {
+		return physicalPlan{}, false
+	}
+	// The join reader produces all the table columns; the scan's columns must
+	// map 1-1 to them.
+	if len(scan.cols) != len(scan.desc.Columns) {
+		return physicalPlan{}, false
+	}
+	for i := range scan.cols {
+		if scan.cols[i].ID != scan.desc.Columns[i].ID {
+			return physicalPlan{}, false
+		}
+	}
+	indexIdx, eqPos, ok := findLookupIndex(scan, n.pred.rightEqualityIndices)
+	if !ok {
+		return physicalPlan{}, false
+	}
+
+	numLeftCols := len(leftPlan.ResultTypes)
+	lookupCols := make([]uint32, len(eqPos))
+	for i, j := range eqPos {
+		lookupCols[i] = uint32(leftPlan.planToStreamColMap[n.pred.leftEqualityIndices[j]])
+	}
+
+	// The ON expression combines the join's ON condition with the scan's
+	// filter. The filter from before index selection is used, since the spans
+	// of the scan are not used.
+	var onExpr distsqlrun.Expression
+	if n.pred.onCond != nil {
+		joinColMap := make([]int, len(n.columns))
+		for i := 0; i < n.pred.numLeftCols; i++ {
+			joinColMap[i] = leftPlan.planToStreamColMap[i]
+		}
+		for i := 0; i < n.pred.numRightCols; i++ {
+			joinColMap[n.pred.numLeftCols+i] = numLeftCols + i
+		}
+		onExpr = distsqlplan.MakeExpression(n.pred.onCond, planCtx.evalCtx, joinColMap)
+	}
+	scanFilter := scan.origFilter
+	if scanFilter == nil {
+		scanFilter = scan.filter
+	}
+	if scanFilter != nil {
+		scanColMap := make([]int, len(scan.cols))
+		for i := range scanColMap {
+			scanColMap[i] = numLeftCols + i
+		}
+		filter := distsqlplan.MakeExpression(scanFilter, planCtx.evalCtx, scanColMap)
+		if onExpr.Expr == "" {
+			onExpr = filter
+		} else {
+			onExpr.Expr = fmt.Sprintf("(%s) AND (%s)", onExpr.Expr, filter.Expr)
+		}
+	}
+
+	post := distsqlrun.PostProcessSpec{
+		Projection: true,
+	}
+	joinToStreamColMap := makePlanToStreamColMap(len(n.columns))
+	for i := 0; i < n.pred.numLeftCols; i++ {
+		if !n.columns[i].Omitted {
+			joinToStreamColMap[i] = len(post.OutputColumns)
+			post.OutputColumns = append(post.OutputColumns, uint32(leftPlan.planToStreamColMap[i]))
+		}
+	}
+	for i := 0; i < n.pred.numRightCols; i++ {
+		if !n.columns[n.pred.numLeftCols+i].Omitted {
+			joinToStreamColMap[n.pred.numLeftCols+i] = len(post.OutputColumns)
+			post.OutputColumns = append(post.OutputColumns, uint32(numLeftCols+i))
+		}
+	}
+
+	joinReaderSpec := distsqlrun.JoinReaderSpec{
+		Table:         *scan.desc,
+		IndexIdx:      indexIdx,
+		LookupColumns: lookupCols,
+		OnExpr:        onExpr,
+		Type:          joinType,
+	}
+
+	// The join readers preserve the ordering of the left side.
+	plan := leftPlan
+	types := getTypesForPlanResult(n, joinToStreamColMap)
+	if distributeIndexJoin.Get(&dsp.st.SV) && len(plan.ResultRouters) > 1 {
+		// Instantiate one join reader for every stream.
+		plan.AddNoGroupingStage(
+			distsqlrun.ProcessorCoreUnion{JoinReader: &joinReaderSpec},
+			post,
+			types,
+			dsp.convertOrdering(n.props, joinToStreamColMap),
+		)
+	} else {
+		// Use a single join reader (if there is a single stream, on that node; if
+		// not, on the gateway node).
+		node := dsp.nodeDesc.NodeID
+		if len(plan.ResultRouters) == 1 {
+			node = plan.Processors[plan.ResultRouters[0]].Node
+		}
+		plan.AddSingleGroupStage(
+			node,
+			distsqlrun.ProcessorCoreUnion{JoinReader: &joinReaderSpec},
+			post,
+			types,
+		)
+	}
+	plan.planToStreamColMap = joinToStreamColMap
+	return plan, true
+}
+
 // getTypesForPlanResult returns the types of the elements in the result streams
 // of a plan that corresponds to a given planNode. If planToSreamColMap is nil,
 // a 1-1 mapping is assumed.
@@ -1910,6 +2187,11 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 	if err != nil {
 		return physicalPlan{}, err
 	}
+	if planLookupJoins.Get(&dsp.st.SV) {
+		if lookupPlan, ok := dsp.tryCreatePlanForLookupJoin(planCtx, n, leftPlan); ok {
+			return lookupPlan, nil
+		}
+	}
 	rightPlan, err := dsp.createPlanForNode(planCtx, n.right.plan)
 	if err != nil {
 		return physicalPlan{}, err
@@ -1942,6 +2224,10 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 		joinType = distsqlrun.JoinType_RIGHT_OUTER
 	case joinTypeLeftOuter:
 		joinType = distsqlrun.JoinType_LEFT_OUTER
+	case joinTypeSemi:
+		joinType = distsqlrun.JoinType_LEFT_SEMI
+	case joinTypeAnti:
+		joinType = distsqlrun.JoinType_LEFT_ANTI
 	default:
 		panic(fmt.Sprintf("invalid join type %d", n.joinType))
 	}
@@ -1980,7 +2266,7 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 			rightEqCols[i] = uint32(rightPlan.planToStreamColMap[rightPlanCol])
 		}
 		if planMergeJoins.Get(&dsp.st.SV) && len(n.mergeJoinOrdering) > 0 &&
-			joinType == distsqlrun.JoinType_INNER {
+			(joinType == distsqlrun.JoinType_INNER || n.joinType.outputsLeftOnly()) {
 			// TODO(radu): we currently only use merge joins when we have an ordering on
 			// all equality columns. We should relax this by either:
 			//  - implementing a hybrid hash/merge processor which implements merge
@@ -2031,6 +2317,7 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 	// The join columns are in two groups:
 	//  - the columns on the left side (numLeftCols)
 	//  - the columns on the right side (numRightCols)
+	// Semi and anti joins only have the first group.
 	joinCol := 0
 
 	for i := 0; i < n.pred.numLeftCols; i++ {
@@ -2039,7 +2326,7 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 		}
 		joinCol++
 	}
-	for i := 0; i < n.pred.numRightCols; i++ {
+	for i := 0; i < n.pred.numRightCols && !n.joinType.outputsLeftOnly(); i++ {
 		if !n.columns[joinCol].Omitted {
 			joinToStreamColMap[joinCol] = addOutCol(
 				uint32(rightPlan.planToStreamColMap[i] + len(leftTypes)),
@@ -2053,7 +2340,7 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 		// the join columns as described above) to values that make sense in the
 		// joiner (0 to N-1 for the left input columns, N to N+M-1 for the right
 		// input columns).
-		joinColMap := make([]int, len(n.columns))
+		joinColMap := make([]int, n.pred.numLeftCols+n.pred.numRightCols)
 		idx := 0
 		for i := 0; i < n.pred.numLeftCols; i++ {
 			joinColMap[idx] = leftPlan.planToStreamColMap[i]
@@ -2237,6 +2524,9 @@ func (dsp *DistSQLPlanner) createPlanForNode(
 	case *valuesNode:
 		return dsp.createPlanForValues(planCtx, n)
 
+	case *insertNode, *updateNode, *deleteNode:
+		return dsp.createPlanForMutation(planCtx, n)
+
 	default:
 		panic(fmt.Sprintf("unsupported node type %T", n))
 	}
@@ -2354,6 +2644,189 @@ func (dsp *DistSQLPlanner) createPlanForDistinct(
 	return plan, nil
 }
 
+// checkSupportForMutation checks whether an INSERT, UPDATE or DELETE can be
+// run by TableWriter processors. This is only the case for statements that
+// need nothing but the rows produced by their source: no RETURNING clause, no
+// ON CONFLICT clause, no foreign key or check constraints to verify, and no
+// schema change in progress on the table.
+func (dsp *DistSQLPlanner) checkSupportForMutation(node planNode) (distRecommendation, error) {
+	if !distributeMutations.Get(&dsp.st.SV) {
+		return 0, mutationsNotSupportedError
+	}
+	var en *editNodeBase
+	var source planNode
+	switch n := node.(type) {
+	case *insertNode:
+		en, source = &n.editNodeBase, n.run.rows
+		if n.n.OnConflict != nil {
+			return 0, newQueryNotSupportedError("ON CONFLICT not supported")
+		}
+		for i := len(planColumns(source)); i < len(n.insertCols) && n.defaultExprs != nil; i++ {
+			if err := dsp.checkExpr(n.defaultExprs[i]); err != nil {
+				return 0, err
+			}
+		}
+		// The rows written by the statement must not be visible to its source.
+		readsTable := false
+		if err := walkPlan(context.TODO(), source, planObserver{
+			enterNode: func(_ context.Context, _ string, p planNode) bool {
+				if scan, ok := p.(*scanNode); ok && scan.desc.ID == n.tableDesc.ID {
+					readsTable = true
+				}
+				return !readsTable
+			},
+		}); err != nil {
+			return 0, err
+		}
+		if readsTable {
+			return 0, newQueryNotSupportedError("INSERT reading from the target table not supported")
+		}
+
+	case *updateNode:
+		en, source = &n.editNodeBase, n.run.rows
+		for _, slot := range n.sourceSlots {
+			if _, ok := slot.(scalarSlot); !ok {
+				return 0, newQueryNotSupportedError("UPDATE assigning tuples not supported")
+			}
+		}
+		// Rows must not move while they are being scanned and updated.
+		indexCols := make(map[sqlbase.ColumnID]struct{})
+		for _, index := range n.tableDesc.AllNonDropIndexes() {
+			for _, id := range index.ColumnIDs {
+				indexCols[id] = struct{}{}
+			}
+		}
+		for _, col := range n.updateCols {
+			if _, ok := indexCols[col.ID]; ok {
+				return 0, newQueryNotSupportedErrorf("UPDATE of indexed column %q not supported", col.Name)
+			}
+		}
+
+	case *deleteNode:
+		en, source = &n.editNodeBase, n.run.rows
+		// Deletes which don't need to scan the rows are better served by the
+		// local fast path.
+		maybeScan := source
+		if sel, ok := maybeScan.(*renderNode); ok {
+			maybeScan = sel.source.plan
+		}
+		if scan, ok := maybeScan.(*scanNode); ok && canDeleteWithoutScan(context.TODO(), n.n, scan, &n.tw) {
+			return 0, newQueryNotSupportedError("DELETE can use the fast path")
+		}
+	}
+
+	if en.join != nil {
+		// A target row joining with several rows must only be written once,
+		// which the TableWriters can't ensure.
+		return 0, newQueryNotSupportedError("UPDATE ... FROM and DELETE ... USING not supported")
+	}
+	if en.rh.exprs != nil {
+		return 0, newQueryNotSupportedError("RETURNING not supported")
+	}
+	desc := en.tableDesc
+	if desc.ID <= keys.MaxReservedDescID {
+		return 0, newQueryNotSupportedError("mutations of system tables not supported")
+	}
+	if len(desc.Checks) > 0 || sqlbase.TablesNeededForFKs(*desc, sqlbase.CheckUpdates) != nil {
+		return 0, newQueryNotSupportedError("mutations of tables with constraints not supported")
+	}
+	if len(desc.Mutations) > 0 {
+		return 0, newQueryNotSupportedError("mutations of tables undergoing schema changes not supported")
+	}
+	return dsp.checkSupportForNode(source)
+}
+
+// createPlanForMutation creates a plan for an INSERT, UPDATE or DELETE that
+// passed checkSupportForMutation. The rows produced by the source of the
+// statement are written by TableWriter processors colocated with the
+// processors producing them; the row counts are summed up on the gateway.
+func (dsp *DistSQLPlanner) createPlanForMutation(
+	planCtx *planningCtx, node planNode,
+) (physicalPlan, error) {
+	var desc *sqlbase.TableDescriptor
+	var source planNode
+	spec := distsqlrun.TableWriterSpec{}
+	// exprs describe the input columns of the TableWriters in terms of the
+	// columns of the source.
+	var exprs []tree.TypedExpr
+	var cols []sqlbase.ColumnDescriptor
+	switch n := node.(type) {
+	case *insertNode:
+		desc, source = n.tableDesc, n.run.rows
+		spec.Type = distsqlrun.TableWriterSpec_INSERT
+		numSourceCols := len(planColumns(source))
+		for i, col := range n.insertCols {
+			spec.ColumnIDs = append(spec.ColumnIDs, uint32(col.ID))
+			switch {
+			case i < numSourceCols:
+				exprs = append(exprs, tree.NewOrdinalReference(i))
+			case n.defaultExprs == nil:
+				exprs = append(exprs, tree.DNull)
+			default:
+				exprs = append(exprs, n.defaultExprs[i])
+			}
+		}
+		cols = n.insertCols
+
+	case *updateNode:
+		desc, source = n.tableDesc, n.run.rows
+		spec.Type = distsqlrun.TableWriterSpec_UPDATE
+		for i := range n.tw.ru.FetchCols {
+			exprs = append(exprs, tree.NewOrdinalReference(i))
+		}
+		for i, slot := range n.sourceSlots {
+			spec.ColumnIDs = append(spec.ColumnIDs, uint32(n.updateCols[i].ID))
+			exprs = append(exprs, tree.NewOrdinalReference(slot.(scalarSlot).sourceIndex))
+		}
+		cols = append(append(cols, n.tw.ru.FetchCols...), n.updateCols...)
+
+	case *deleteNode:
+		desc, source = n.tableDesc, n.run.rows
+		spec.Type = distsqlrun.TableWriterSpec_DELETE
+		for i := range n.tw.rd.FetchCols {
+			exprs = append(exprs, tree.NewOrdinalReference(i))
+		}
+		cols = n.tw.rd.FetchCols
+	}
+	spec.Table = *desc
+
+	plan, err := dsp.createPlanForNode(planCtx, source)
+	if err != nil {
+		return physicalPlan{}, err
+	}
+	colTypes := make([]sqlbase.ColumnType, len(cols))
+	for i := range cols {
+		colTypes[i] = cols[i].Type
+	}
+	plan.AddRendering(exprs, planCtx.evalCtx, plan.planToStreamColMap, colTypes)
+
+	countTypes := []sqlbase.ColumnType{{SemanticType: sqlbase.ColumnType_INT}}
+	plan.AddNoGroupingStage(
+		distsqlrun.ProcessorCoreUnion{TableWriter: &spec},
+		distsqlrun.PostProcessSpec{},
+		countTypes,
+		distsqlrun.Ordering{},
+	)
+	plan.AddSingleGroupStage(
+		dsp.nodeDesc.NodeID,
+		distsqlrun.ProcessorCoreUnion{Aggregator: &distsqlrun.AggregatorSpec{
+			Aggregations: []distsqlrun.AggregatorSpec_Aggregation{
+				{Func: distsqlrun.AggregatorSpec_SUM_INT, ColIdx: []uint32{0}},
+			},
+		}},
+		distsqlrun.PostProcessSpec{},
+		countTypes,
+	)
+	plan.planToStreamColMap = []int{0}
+
+	planCtx.writeAnchor = sqlbase.MakeIndexKeyPrefix(desc, desc.PrimaryIndex.ID)
+	// The spans of interleaved indexes are those of their root ancestors.
+	for _, index := range desc.AllNonDropIndexes() {
+		planCtx.writeSpans = append(planCtx.writeSpans, desc.IndexSpan(index.ID))
+	}
+	return plan, nil
+}
+
 func (dsp *DistSQLPlanner) newPlanningCtx(
 	ctx context.Context, evalCtx *tree.EvalContext, txn *client.Txn,
 ) planningCtx {
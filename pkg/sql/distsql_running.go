@@ -117,6 +117,27 @@ func (dsp *DistSQLPlanner) Run(
 		return err
 	}
 
+	// DistSQL flows read through their own senders, bypassing the
+	// TxnCoordSender, so the reads performed by this plan can't be refreshed.
+	txn.DisableRefreshes()
+
+	if planCtx.writeAnchor != nil {
+		// The plan writes (or locks rows) on behalf of the transaction. The
+		// transaction record needs to be written first (so that the flows see a
+		// Writing transaction), and the TxnCoordSender needs to start tracking
+		// the transaction along with the spans the flows may write to: the
+		// intents reported by the flows might get lost, for example if a flow
+		// fails.
+		if err := txn.PrepareForRemoteWrites(
+			ctx, planCtx.writeAnchor, planCtx.writeSpans,
+		); err != nil {
+			return err
+		}
+		// Mutations produce the number of rows they affected. This doesn't
+		// apply to locking SELECTs, whose results are rows.
+		recv.rowCountResult = true
+	}
+
 	flows := plan.GenerateFlowSpecs(dsp.nodeDesc.NodeID /* gateway */)
 
 	if logPlanDiagram {
@@ -157,10 +178,11 @@ func (dsp *DistSQLPlanner) Run(
 			continue
 		}
 		req := &distsqlrun.SetupFlowRequest{
-			Version:     distsqlrun.Version,
-			Txn:         *txn.Proto(),
-			Flow:        flowSpec,
-			EvalContext: evalCtxProto,
+			Version:      distsqlrun.Version,
+			Txn:          *txn.Proto(),
+			Flow:         flowSpec,
+			EvalContext:  evalCtxProto,
+			CollectStats: planCtx.collectStats,
 		}
 		runReq := runnerRequest{
 			ctx:         ctx,
@@ -196,10 +218,11 @@ func (dsp *DistSQLPlanner) Run(
 
 	// Set up the flow on this node.
 	localReq := distsqlrun.SetupFlowRequest{
-		Version:     distsqlrun.Version,
-		Txn:         *txn.Proto(),
-		Flow:        flows[thisNodeID],
-		EvalContext: evalCtxProto,
+		Version:      distsqlrun.Version,
+		Txn:          *txn.Proto(),
+		Flow:         flows[thisNodeID],
+		EvalContext:  evalCtxProto,
+		CollectStats: planCtx.collectStats,
 	}
 	ctx, flow, err := dsp.distSQLSrv.SetupSyncFlow(ctx, &localReq, recv)
 	if err != nil {
@@ -264,6 +287,11 @@ type distSQLReceiver struct {
 	// A handler for clock signals arriving from remote nodes. This should update
 	// this node's clock.
 	updateClock func(observedTs hlc.Timestamp)
+
+	// rowCountResult is set if the plan produces a single row containing the
+	// number of rows affected by the statement (as is the case for mutations),
+	// as opposed to each row counting as an affected row.
+	rowCountResult bool
 }
 
 // rowResultWriter is a subset of StatementResult to be used with the
@@ -339,6 +367,15 @@ func (r *distSQLReceiver) Push(
 				r.err = err
 			}
 		}
+		if meta.TxnWrites != nil && r.txn != nil {
+			// Writes performed by the flow bypassed the TxnCoordSender, which
+			// needs to know about them in order to resolve the intents.
+			if err := r.txn.AugmentRemoteWrites(
+				r.ctx, meta.TxnWrites.Txn, meta.TxnWrites.IntentSpans,
+			); err != nil && r.err == nil {
+				r.err = err
+			}
+		}
 		if len(meta.TraceData) > 0 {
 			span := opentracing.SpanFromContext(r.ctx)
 			if span == nil {
@@ -363,7 +400,16 @@ func (r *distSQLReceiver) Push(
 
 	if r.resultWriter.StatementType() != tree.Rows {
 		// We only need the row count.
-		r.resultWriter.IncrementRowsAffected(1)
+		if !r.rowCountResult {
+			r.resultWriter.IncrementRowsAffected(1)
+			return r.status
+		}
+		if err := row[0].EnsureDecoded(&r.outputTypes[0], &r.alloc); err != nil {
+			r.err = err
+			r.status = distsqlrun.ConsumerClosed
+			return r.status
+		}
+		r.resultWriter.IncrementRowsAffected(int(tree.MustBeDInt(row[0].Datum)))
 		return r.status
 	}
 	if r.row == nil {
@@ -439,6 +485,10 @@ func (dsp *DistSQLPlanner) PlanAndRun(
 	recv *distSQLReceiver,
 	evalCtx tree.EvalContext,
 ) error {
+	if err := dsp.planAndRunSubqueries(ctx, txn, tree, recv, evalCtx); err != nil {
+		return err
+	}
+
 	planCtx := dsp.newPlanningCtx(ctx, &evalCtx, txn)
 
 	log.VEvent(ctx, 1, "creating DistSQL plan")
@@ -450,3 +500,80 @@ func (dsp *DistSQLPlanner) PlanAndRun(
 	dsp.FinalizePlan(&planCtx, &plan)
 	return dsp.Run(&planCtx, txn, &plan, recv, evalCtx)
 }
+
+// planAndRunSubqueries evaluates the subqueries of a plan which is about to be
+// run by DistSQL. The results replace the subqueries in the expressions that
+// are sent to the processors (see subquery.Format). Subqueries are themselves
+// run through DistSQL when possible, and locally otherwise.
+func (dsp *DistSQLPlanner) planAndRunSubqueries(
+	ctx context.Context,
+	txn *client.Txn,
+	plan planNode,
+	recv *distSQLReceiver,
+	evalCtx tree.EvalContext,
+) error {
+	subqueryNode := func(ctx context.Context, sq *subquery) error {
+		if !sq.expanded {
+			panic("subquery was not expanded properly")
+		}
+		if sq.started {
+			return nil
+		}
+		if _, err := dsp.CheckSupport(sq.plan); err != nil {
+			// The subquery can't be distributed; run it locally.
+			if err := sq.planner.startPlan(ctx, sq.plan); err != nil {
+				return err
+			}
+			sq.started = true
+			res, err := sq.doEval(ctx)
+			if err != nil {
+				return err
+			}
+			sq.result = res
+			return nil
+		}
+
+		sq.started = true
+		defer func() { sq.plan.Close(ctx); sq.plan = nil }()
+
+		rows := sqlbase.NewRowContainer(
+			evalCtx.Mon.MakeBoundAccount(), sqlbase.ColTypeInfoFromResCols(planColumns(sq.plan)), 0,
+		)
+		defer rows.Close(ctx)
+		subRecv, err := makeDistSQLReceiver(
+			ctx, NewRowResultWriter(tree.Rows, rows),
+			recv.rangeCache, recv.leaseCache, recv.txn, recv.updateClock,
+		)
+		if err != nil {
+			return err
+		}
+		if err := dsp.PlanAndRun(ctx, txn, sq.plan, &subRecv, evalCtx); err != nil {
+			return err
+		}
+		if subRecv.err != nil {
+			return subRecv.err
+		}
+
+		i := 0
+		res, err := sq.evalRows(sq.plan, func() (tree.Datums, bool, error) {
+			if i >= rows.Len() {
+				return nil, false, nil
+			}
+			i++
+			return rows.At(i - 1), true, nil
+		})
+		if err != nil {
+			return err
+		}
+		sq.result = res
+		return nil
+	}
+	return walkPlan(ctx, plan, planObserver{
+		subqueryNode: subqueryNode,
+		enterNode: func(_ context.Context, _ string, n planNode) bool {
+			// EXPLAIN doesn't start/substitute sub-queries.
+			_, ok := n.(*explainPlanNode)
+			return !ok
+		},
+	})
+}
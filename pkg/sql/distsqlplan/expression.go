@@ -53,6 +53,68 @@ func exprFmtFlagsNoMap(evalCtx *tree.EvalContext) tree.FmtFlags {
 		})
 }
 
+// EvaluatedSetExpr is implemented by expressions standing for a set of values
+// which is computed before the plan is distributed, like an uncorrelated
+// subquery on the right-hand side of IN. Such expressions are serialized as
+// a tuple of their values.
+type EvaluatedSetExpr interface {
+	tree.TypedExpr
+
+	// EvaluatedSet returns the values of the set, or false if the expression
+	// hasn't been evaluated.
+	EvaluatedSet() (tree.Datums, bool)
+}
+
+// evaluatedSetVisitor rewrites the comparisons with evaluated sets into
+// expressions which can be serialized. There is no syntax for an empty set
+// (e.g. "x IN ()"), so comparisons with an empty set are replaced with their
+// result, and the right-hand side of ANY, SOME and ALL is written as a ROW,
+// which unlike a parenthesized list also denotes a tuple when it has a single
+// element.
+type evaluatedSetVisitor struct{}
+
+var _ tree.Visitor = evaluatedSetVisitor{}
+
+func (evaluatedSetVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	return true, expr
+}
+
+func (evaluatedSetVisitor) VisitPost(expr tree.Expr) tree.Expr {
+	c, ok := expr.(*tree.ComparisonExpr)
+	if !ok {
+		return expr
+	}
+	set, ok := c.Right.(EvaluatedSetExpr)
+	if !ok {
+		return expr
+	}
+	values, ok := set.EvaluatedSet()
+	if !ok {
+		return expr
+	}
+	switch c.Operator {
+	case tree.In, tree.Any, tree.Some:
+		if len(values) == 0 {
+			return tree.DBoolFalse
+		}
+	case tree.NotIn, tree.All:
+		if len(values) == 0 {
+			return tree.DBoolTrue
+		}
+	}
+	switch c.Operator {
+	case tree.Any, tree.Some, tree.All:
+		row := &tree.Tuple{Exprs: make(tree.Exprs, len(values)), Row: true}
+		for i, v := range values {
+			row.Exprs[i] = v
+		}
+		cCopy := *c
+		cCopy.Right = row
+		return &cCopy
+	}
+	return expr
+}
+
 // MakeExpression creates a distsqlrun.Expression.
 //
 // The distsqlrun.Expression uses the placeholder syntax (@1, @2, @3..) to refer
@@ -85,7 +147,8 @@ func MakeExpression(
 		)
 	}
 	var buf bytes.Buffer
-	tree.FormatNode(&buf, f, expr)
+	newExpr, _ := tree.WalkExpr(evaluatedSetVisitor{}, expr)
+	tree.FormatNode(&buf, f, newExpr)
 	if log.V(1) {
 		log.Infof(context.TODO(), "Expr %s:\n%s", buf.String(), tree.ExprDebugString(expr))
 	}
@@ -15,14 +15,18 @@
 package distsqlrun
 
 import (
+	"bytes"
 	"strings"
 	"sync"
 	"unsafe"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -89,6 +93,18 @@ func GetAggregateInfo(
 //
 // aggregator's output schema is comprised of what is specified by the
 // accompanying SELECT expressions.
+//
+// If the buckets do not fit in the memory budget and temp storage is enabled,
+// the aggregator stops creating new buckets in memory. Rows belonging to
+// groups that are not already in memory are stored on disk, sorted by the
+// grouping columns, and aggregated one group at a time once the in-memory
+// buckets have been output. Each group is thus either entirely in memory or
+// entirely on disk.
+//
+// If the input is ordered on some of the grouping columns, the aggregator
+// streams: whenever the values of these columns change, the groups
+// accumulated so far are complete, so they are output and released before
+// the next row is accumulated.
 type aggregator struct {
 	processorBase
 
@@ -101,10 +117,25 @@ type aggregator struct {
 
 	bucketsAcc mon.BoundAccount
 
-	groupCols    columns
-	aggregations []AggregatorSpec_Aggregation
+	groupCols columns
+	// orderedGroupCols is the subset of groupCols on which the input is
+	// ordered.
+	orderedGroupCols columns
+	aggregations     []AggregatorSpec_Aggregation
 
 	buckets map[string]struct{} // The set of bucket keys.
+
+	// useTempStorage is set if the aggregator can spill to temp storage once
+	// the memory budget is exhausted.
+	useTempStorage bool
+	// spilled is set while accumulating rows after the memory budget has been
+	// exhausted. No new buckets are created in memory and the seen sets of
+	// DISTINCT aggregations grow on disk.
+	spilled bool
+	// spilledRows holds the input rows of the groups that did not fit in
+	// memory, sorted by the grouping columns. It is nil if the aggregator
+	// never ran out of memory.
+	spilledRows *diskRowContainer
 }
 
 var _ Processor = &aggregator{}
@@ -117,14 +148,15 @@ func newAggregator(
 	output RowReceiver,
 ) (*aggregator, error) {
 	ag := &aggregator{
-		flowCtx:      flowCtx,
-		input:        input,
-		groupCols:    spec.GroupCols,
-		aggregations: spec.Aggregations,
-		buckets:      make(map[string]struct{}),
-		funcs:        make([]*aggregateFuncHolder, len(spec.Aggregations)),
-		outputTypes:  make([]sqlbase.ColumnType, len(spec.Aggregations)),
-		bucketsAcc:   flowCtx.EvalCtx.Mon.MakeBoundAccount(),
+		flowCtx:          flowCtx,
+		input:            input,
+		groupCols:        spec.GroupCols,
+		orderedGroupCols: spec.OrderedGroupCols,
+		aggregations:     spec.Aggregations,
+		buckets:          make(map[string]struct{}),
+		funcs:            make([]*aggregateFuncHolder, len(spec.Aggregations)),
+		outputTypes:      make([]sqlbase.ColumnType, len(spec.Aggregations)),
+		bucketsAcc:       flowCtx.EvalCtx.Mon.MakeBoundAccount(),
 	}
 
 	// Loop over the select expressions and extract any aggregate functions --
@@ -177,14 +209,6 @@ func (ag *aggregator) Run(ctx context.Context, wg *sync.WaitGroup) {
 	if wg != nil {
 		defer wg.Done()
 	}
-	defer ag.bucketsAcc.Close(ctx)
-	defer func() {
-		for _, f := range ag.funcs {
-			for _, aggFunc := range f.buckets {
-				aggFunc.Close(ctx)
-			}
-		}
-	}()
 
 	ctx = log.WithLogTag(ctx, "Agg", nil)
 	ctx, span := processorSpan(ctx, "aggregator")
@@ -195,6 +219,32 @@ func (ag *aggregator) Run(ctx context.Context, wg *sync.WaitGroup) {
 		defer log.Infof(ctx, "exiting aggregator")
 	}
 
+	// Enable fall back to disk if the cluster setting is set or a memory limit
+	// has been set through testing.
+	st := ag.flowCtx.Settings
+	ag.useTempStorage = settingUseTempStorageAggregations.Get(&st.SV) ||
+		ag.flowCtx.testingKnobs.MemoryLimitBytes > 0
+	if ag.useTempStorage {
+		// Limit the memory use by creating a child monitor with a hard limit.
+		// The aggregator will overflow to disk if this limit is not enough.
+		limit := ag.flowCtx.testingKnobs.MemoryLimitBytes
+		if limit <= 0 {
+			limit = settingWorkMemBytes.Get(&st.SV)
+		}
+		limitedMon := mon.MakeMonitorInheritWithLimit("aggregator-limited", limit, ag.flowCtx.EvalCtx.Mon)
+		limitedMon.Start(ctx, ag.flowCtx.EvalCtx.Mon, mon.BoundAccount{})
+		defer limitedMon.Stop(ctx)
+
+		ag.bucketsAcc = limitedMon.MakeBoundAccount()
+	}
+	defer ag.bucketsAcc.Close(ctx)
+	defer ag.closeBuckets(ctx)
+	defer func() {
+		if ag.spilledRows != nil {
+			ag.spilledRows.Close(ctx)
+		}
+	}()
+
 	if err := ag.accumulateRows(ctx); err != nil {
 		// We swallow the error here, it has already been forwarded to the output.
 		return
@@ -204,32 +254,21 @@ func (ag *aggregator) Run(ctx context.Context, wg *sync.WaitGroup) {
 
 	// Queries like `SELECT MAX(n) FROM t` expect a row of NULLs if nothing was
 	// aggregated.
-	if len(ag.buckets) < 1 && len(ag.groupCols) == 0 {
+	if len(ag.buckets) < 1 && len(ag.groupCols) == 0 && ag.spilledRows == nil {
 		ag.buckets[""] = struct{}{}
 	}
 
-	// Render the results.
-	var consumerDone bool
+	// Render the results: first the in-memory buckets, then the groups that
+	// were spilled to disk.
 	row := make(sqlbase.EncDatumRow, len(ag.funcs))
-	for bucket := range ag.buckets {
-		for i, f := range ag.funcs {
-			result, err := f.get(bucket)
-			if err != nil {
-				DrainAndClose(ctx, ag.out.output, err, ag.input)
-				return
-			}
-			if result == nil {
-				// Special case useful when this is a local stage of a distributed
-				// aggregation.
-				result = tree.DNull
-			}
-			row[i] = sqlbase.DatumToEncDatum(ag.outputTypes[i], result)
-		}
-
-		consumerDone = !emitHelper(ctx, &ag.out, row, ProducerMetadata{})
-		if consumerDone {
-			break
-		}
+	consumerDone, err := ag.renderBuckets(ctx, row)
+	if err == nil && !consumerDone && ag.spilledRows != nil {
+		log.VEvent(ctx, 1, "rendering spilled groups")
+		consumerDone, err = ag.renderSpilledRows(ctx, row)
+	}
+	if err != nil {
+		DrainAndClose(ctx, ag.out.output, err, ag.input)
+		return
 	}
 	// If the consumer has been found to be done, emitHelper() already closed the
 	// output.
@@ -256,6 +295,10 @@ func (ag *aggregator) accumulateRows(ctx context.Context) (err error) {
 	}()
 
 	var scratch []byte
+	// lastOrderedKey is the encoding of the ordered grouping columns of the
+	// previous row.
+	var lastOrderedKey, orderedScratch []byte
+	var outRow sqlbase.EncDatumRow
 	for {
 		row, meta := ag.input.Next()
 		if !meta.Empty() {
@@ -278,6 +321,25 @@ func (ag *aggregator) accumulateRows(ctx context.Context) (err error) {
 			return nil
 		}
 
+		if len(ag.orderedGroupCols) > 0 {
+			orderedKey, err := ag.encodeCols(orderedScratch, row, ag.orderedGroupCols)
+			if err != nil {
+				return err
+			}
+			if lastOrderedKey != nil && !bytes.Equal(orderedKey, lastOrderedKey) {
+				if outRow == nil {
+					outRow = make(sqlbase.EncDatumRow, len(ag.funcs))
+				}
+				if consumerDone, err := ag.emitGroups(ctx, outRow); err != nil {
+					return err
+				} else if consumerDone {
+					cleanupRequired = false
+					return errors.Errorf("consumer stopped before it received all rows")
+				}
+			}
+			lastOrderedKey, orderedScratch = orderedKey, lastOrderedKey[:0]
+		}
+
 		// The encoding computed here determines which bucket the non-grouping
 		// datums are accumulated to.
 		encoded, err := ag.encode(scratch, row)
@@ -285,52 +347,213 @@ func (ag *aggregator) accumulateRows(ctx context.Context) (err error) {
 			return err
 		}
 
-		if err := ag.bucketsAcc.Grow(ctx, int64(len(encoded))); err != nil {
+		if err := ag.accumulateRow(ctx, row, encoded); err != nil {
 			return err
 		}
+		scratch = encoded[:0]
+	}
+}
 
+// accumulateRow feeds the non-grouping datums of the row to the func holders
+// of the bucket with the given encoding, creating the bucket if needed. If
+// the bucket does not exist and the aggregator has run out of memory, the row
+// is stored in spilledRows instead.
+func (ag *aggregator) accumulateRow(
+	ctx context.Context, row sqlbase.EncDatumRow, encoded []byte,
+) error {
+	if _, ok := ag.buckets[string(encoded)]; !ok {
+		if ag.spilled {
+			return ag.spilledRows.AddRow(ctx, row)
+		}
+		// Account for the bucket key, stored in ag.buckets and in each func
+		// holder, and for the aggregate functions.
+		// TODO(radu): we should account for the size of impl (this needs to be
+		// done in each aggregate constructor).
+		usage := int64(len(encoded)) + int64(len(ag.funcs))*(int64(len(encoded))+sizeOfAggregateFunc)
+		if err := ag.bucketsAcc.Grow(ctx, usage); err != nil {
+			if err := ag.spill(ctx, err); err != nil {
+				return err
+			}
+			return ag.spilledRows.AddRow(ctx, row)
+		}
 		ag.buckets[string(encoded)] = struct{}{}
-		// Feed the func holders for this bucket the non-grouping datums.
-		for i, a := range ag.aggregations {
-			if a.FilterColIdx != nil {
-				col := *a.FilterColIdx
-				if err := row[col].EnsureDecoded(&ag.inputTypes[col], &ag.datumAlloc); err != nil {
-					return err
-				}
-				if row[*a.FilterColIdx].Datum != tree.DBoolTrue {
-					// This row doesn't contribute to this aggregation.
-					continue
-				}
+	}
+
+	// Feed the func holders for this bucket the non-grouping datums.
+	for i, a := range ag.aggregations {
+		if a.FilterColIdx != nil {
+			col := *a.FilterColIdx
+			if err := row[col].EnsureDecoded(&ag.inputTypes[col], &ag.datumAlloc); err != nil {
+				return err
 			}
-			// Extract the corresponding arguments from the row to feed into the
-			// aggregate function.
-			// Most functions require at most one argument thus we separate
-			// the first argument and allocation of (if applicable) a variadic
-			// collection of arguments thereafter.
-			var firstArg tree.Datum
-			var otherArgs tree.Datums
-			if len(a.ColIdx) > 1 {
-				otherArgs = make(tree.Datums, len(a.ColIdx)-1)
+			if row[*a.FilterColIdx].Datum != tree.DBoolTrue {
+				// This row doesn't contribute to this aggregation.
+				continue
 			}
-			isFirstArg := true
-			for j, c := range a.ColIdx {
-				if err := row[c].EnsureDecoded(&ag.inputTypes[c], &ag.datumAlloc); err != nil {
-					return err
-				}
-				if isFirstArg {
-					firstArg = row[c].Datum
-					isFirstArg = false
-					continue
-				}
-				otherArgs[j-1] = row[c].Datum
+		}
+		// Extract the corresponding arguments from the row to feed into the
+		// aggregate function.
+		// Most functions require at most one argument thus we separate
+		// the first argument and allocation of (if applicable) a variadic
+		// collection of arguments thereafter.
+		var firstArg tree.Datum
+		var otherArgs tree.Datums
+		if len(a.ColIdx) > 1 {
+			otherArgs = make(tree.Datums, len(a.ColIdx)-1)
+		}
+		isFirstArg := true
+		for j, c := range a.ColIdx {
+			if err := row[c].EnsureDecoded(&ag.inputTypes[c], &ag.datumAlloc); err != nil {
+				return err
+			}
+			if isFirstArg {
+				firstArg = row[c].Datum
+				isFirstArg = false
+				continue
 			}
+			otherArgs[j-1] = row[c].Datum
+		}
 
-			if err := ag.funcs[i].add(ctx, encoded, firstArg, otherArgs); err != nil {
-				return err
+		if err := ag.funcs[i].add(ctx, encoded, firstArg, otherArgs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// spill switches the aggregator to external mode after the memory budget was
+// exhausted. err is the error returned by the memory account; it is returned
+// unchanged if it is not a memory error.
+func (ag *aggregator) spill(ctx context.Context, err error) error {
+	if pgErr, ok := pgerror.GetPGCause(err); !(ok && pgErr.Code == pgerror.CodeOutOfMemoryError) {
+		return err
+	}
+	if !ag.useTempStorage {
+		return errors.Wrap(err, "external storage for large queries disabled")
+	}
+	if ag.spilledRows != nil {
+		// The spilled groups are aggregated in memory one at a time; there is
+		// nowhere left to spill to.
+		return errors.Wrap(err, "aggregation group does not fit in memory")
+	}
+
+	log.VEventf(ctx, 2, "falling back to disk")
+	ordering := make(sqlbase.ColumnOrdering, len(ag.groupCols))
+	for i, col := range ag.groupCols {
+		ordering[i] = sqlbase.ColumnOrderInfo{ColIdx: int(col), Direction: encoding.Ascending}
+	}
+	rows := makeDiskRowContainer(
+		ctx, ag.flowCtx.diskMonitor, ag.inputTypes, ordering, ag.flowCtx.TempStorage,
+	)
+	ag.spilledRows = &rows
+	ag.spilled = true
+	return nil
+}
+
+// renderBuckets outputs a row for each in-memory bucket. The inputs, if any,
+// are drained or closed if the consumer doesn't need more rows.
+func (ag *aggregator) renderBuckets(
+	ctx context.Context, row sqlbase.EncDatumRow, inputs ...RowSource,
+) (consumerDone bool, _ error) {
+	for bucket := range ag.buckets {
+		for i, f := range ag.funcs {
+			result, err := f.get(bucket)
+			if err != nil {
+				return false, err
+			}
+			if result == nil {
+				// Special case useful when this is a local stage of a distributed
+				// aggregation.
+				result = tree.DNull
+			}
+			row[i] = sqlbase.DatumToEncDatum(ag.outputTypes[i], result)
+		}
+
+		if !emitHelper(ctx, &ag.out, row, ProducerMetadata{}, inputs...) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// renderSpilledRows aggregates and outputs the groups stored in spilledRows.
+// The rows are sorted by the grouping columns, so each group is accumulated
+// in memory and output before moving on to the next one.
+func (ag *aggregator) renderSpilledRows(
+	ctx context.Context, row sqlbase.EncDatumRow, inputs ...RowSource,
+) (consumerDone bool, _ error) {
+	// The in-memory buckets have all been output; release them.
+	ag.closeBuckets(ctx)
+	ag.bucketsAcc.Clear(ctx)
+	ag.spilled = false
+
+	i := ag.spilledRows.NewIterator(ctx)
+	defer i.Close()
+	var scratch []byte
+	for i.Rewind(); ; i.Next() {
+		if ok, err := i.Valid(); err != nil {
+			return false, err
+		} else if !ok {
+			break
+		}
+		inputRow, err := i.Row()
+		if err != nil {
+			return false, err
+		}
+		encoded, err := ag.encode(scratch, inputRow)
+		if err != nil {
+			return false, err
+		}
+		if _, ok := ag.buckets[string(encoded)]; !ok && len(ag.buckets) > 0 {
+			// This row starts a new group; output the previous one.
+			if consumerDone, err := ag.renderBuckets(ctx, row, inputs...); consumerDone || err != nil {
+				return consumerDone, err
 			}
+			ag.closeBuckets(ctx)
+			ag.bucketsAcc.Clear(ctx)
+		}
+		if err := ag.accumulateRow(ctx, inputRow, encoded); err != nil {
+			return false, err
 		}
 		scratch = encoded[:0]
 	}
+	return ag.renderBuckets(ctx, row, inputs...)
+}
+
+// emitGroups outputs and releases all the groups accumulated so far, including
+// those spilled to disk. It is used when streaming, once the groups are known
+// to be complete; the input is drained or closed if the consumer doesn't need
+// more rows.
+func (ag *aggregator) emitGroups(
+	ctx context.Context, row sqlbase.EncDatumRow,
+) (consumerDone bool, err error) {
+	consumerDone, err = ag.renderBuckets(ctx, row, ag.input)
+	if err == nil && !consumerDone && ag.spilledRows != nil {
+		consumerDone, err = ag.renderSpilledRows(ctx, row, ag.input)
+	}
+	if ag.spilledRows != nil {
+		ag.spilledRows.Close(ctx)
+		ag.spilledRows = nil
+	}
+	ag.closeBuckets(ctx)
+	ag.bucketsAcc.Clear(ctx)
+	return consumerDone, err
+}
+
+// closeBuckets closes the aggregate functions and seen sets of all in-memory
+// buckets and resets them. The caller is responsible for clearing bucketsAcc.
+func (ag *aggregator) closeBuckets(ctx context.Context) {
+	for _, f := range ag.funcs {
+		for _, aggFunc := range f.buckets {
+			aggFunc.Close(ctx)
+		}
+		f.buckets = make(map[string]tree.AggregateFunc)
+		if f.seen != nil {
+			f.seen = make(map[string]struct{})
+		}
+		f.closeSeenOnDisk(ctx)
+	}
+	ag.buckets = make(map[string]struct{})
 }
 
 type aggregateFuncHolder struct {
@@ -339,6 +562,12 @@ type aggregateFuncHolder struct {
 	buckets       map[string]tree.AggregateFunc
 	seen          map[string]struct{}
 	bucketsMemAcc *mon.BoundAccount
+
+	// seenOnDisk holds the encodings of DISTINCT arguments that did not fit
+	// in the seen map. It is nil until the aggregator spills.
+	seenOnDisk engine.SortedDiskMap
+	// diskAcc keeps track of seenOnDisk's disk usage.
+	diskAcc mon.BoundAccount
 }
 
 const sizeOfAggregateFunc = int64(unsafe.Sizeof(tree.AggregateFunc(nil)))
@@ -369,35 +598,81 @@ func (a *aggregateFuncHolder) add(
 				return err
 			}
 		}
-		if _, ok := a.seen[string(encoded)]; ok {
+		isNew, err := a.markSeen(ctx, encoded)
+		if err != nil {
+			return err
+		}
+		if !isNew {
 			// skip
 			return nil
 		}
-		if err := a.bucketsMemAcc.Grow(ctx, int64(len(encoded))); err != nil {
-			return err
-		}
-		a.seen[string(encoded)] = struct{}{}
 	}
 
 	impl, ok := a.buckets[string(bucket)]
 	if !ok {
-		// TODO(radu): we should account for the size of impl (this needs to be done
-		// in each aggregate constructor).
-		impl = a.create(&a.group.flowCtx.EvalCtx)
-		usage := int64(len(bucket))
-		usage += sizeOfAggregateFunc
+		// The memory for the bucket was accounted for by the aggregator when the
+		// bucket was created.
 		// TODO(radu): this model of each func having a map of buckets (one per
 		// group) for each func plus a global map is very wasteful. We should have a
 		// single map that stores all the AggregateFuncs.
-		if err := a.bucketsMemAcc.Grow(ctx, usage); err != nil {
-			return err
-		}
+		impl = a.create(&a.group.flowCtx.EvalCtx)
 		a.buckets[string(bucket)] = impl
 	}
 
 	return impl.Add(ctx, firstArg, otherArgs...)
 }
 
+// markSeen adds the encoded arguments to the seen set and returns whether they
+// were not already present. Once the aggregator has spilled, new encodings are
+// stored on disk.
+func (a *aggregateFuncHolder) markSeen(ctx context.Context, encoded []byte) (bool, error) {
+	if _, ok := a.seen[string(encoded)]; ok {
+		return false, nil
+	}
+	if a.seenOnDisk != nil {
+		v, err := a.seenOnDisk.Get(encoded)
+		if err != nil {
+			return false, err
+		}
+		if v != nil {
+			return false, nil
+		}
+		return true, a.putSeenOnDisk(ctx, encoded)
+	}
+
+	if !a.group.spilled {
+		err := a.bucketsMemAcc.Grow(ctx, int64(len(encoded)))
+		if err == nil {
+			a.seen[string(encoded)] = struct{}{}
+			return true, nil
+		}
+		if err := a.group.spill(ctx, err); err != nil {
+			return false, err
+		}
+	}
+
+	a.seenOnDisk = engine.NewRocksDBMap(a.group.flowCtx.TempStorage)
+	a.diskAcc = a.group.flowCtx.diskMonitor.MakeBoundAccount()
+	return true, a.putSeenOnDisk(ctx, encoded)
+}
+
+func (a *aggregateFuncHolder) putSeenOnDisk(ctx context.Context, encoded []byte) error {
+	if err := a.diskAcc.Grow(ctx, int64(len(encoded)+len(seenOnDiskValue))); err != nil {
+		return errors.Wrapf(err, "this query requires additional disk space")
+	}
+	return a.seenOnDisk.Put(encoded, seenOnDiskValue)
+}
+
+// closeSeenOnDisk releases the on-disk seen set, if any.
+func (a *aggregateFuncHolder) closeSeenOnDisk(ctx context.Context) {
+	if a.seenOnDisk == nil {
+		return
+	}
+	a.seenOnDisk.Close(ctx)
+	a.seenOnDisk = nil
+	a.diskAcc.Close(ctx)
+}
+
 func (a *aggregateFuncHolder) get(bucket string) (tree.Datum, error) {
 	found, ok := a.buckets[bucket]
 	if !ok {
@@ -412,7 +687,14 @@ func (a *aggregateFuncHolder) get(bucket string) (tree.Datum, error) {
 func (ag *aggregator) encode(
 	appendTo []byte, row sqlbase.EncDatumRow,
 ) (encoding []byte, err error) {
-	for _, colIdx := range ag.groupCols {
+	return ag.encodeCols(appendTo, row, ag.groupCols)
+}
+
+// encodeCols appends the encoding of the given columns of the row.
+func (ag *aggregator) encodeCols(
+	appendTo []byte, row sqlbase.EncDatumRow, cols columns,
+) (encoding []byte, err error) {
+	for _, colIdx := range cols {
 		appendTo, err = row[colIdx].Encode(&ag.inputTypes[colIdx], &ag.datumAlloc, sqlbase.DatumEncoding_ASCENDING_KEY, appendTo)
 		if err != nil {
 			return appendTo, err
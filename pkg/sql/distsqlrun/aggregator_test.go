@@ -15,25 +15,31 @@
 package distsqlrun
 
 import (
+	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"testing"
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 )
 
 // TODO(irfansharif): Add tests to verify the following aggregation functions:
-//      AVG
-//      BOOL_AND
-//      BOOL_OR
-//      CONCAT_AGG
-//      STDDEV
-//      VARIANCE
+//
+//	AVG
+//	BOOL_AND
+//	BOOL_OR
+//	CONCAT_AGG
+//	STDDEV
+//	VARIANCE
 func TestAggregator(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -308,6 +314,75 @@ func TestAggregator(t *testing.T) {
 				{v[5], v[2], v[5], v[2]},
 			},
 		},
+		{
+			// SELECT @2, COUNT(@1), GROUP BY @2, with the input ordered on @2.
+			spec: AggregatorSpec{
+				GroupCols:        []uint32{1},
+				OrderedGroupCols: []uint32{1},
+				Aggregations: []AggregatorSpec_Aggregation{
+					{
+						Func:   AggregatorSpec_IDENT,
+						ColIdx: []uint32{1},
+					},
+					{
+						Func:   AggregatorSpec_COUNT,
+						ColIdx: []uint32{0},
+					},
+				},
+			},
+			inputTypes: twoIntCols,
+			input: sqlbase.EncDatumRows{
+				{v[3], null},
+				{v[1], v[2]},
+				{v[6], v[2]},
+				{null, v[2]},
+				{v[8], v[4]},
+			},
+			outputTypes: twoIntCols,
+			expected: sqlbase.EncDatumRows{
+				{null, v[1]},
+				{v[2], v[2]},
+				{v[4], v[1]},
+			},
+		},
+		{
+			// SELECT @1, @2, SUM_INT(@3), GROUP BY @1, @2, with the input ordered
+			// on @1.
+			spec: AggregatorSpec{
+				GroupCols:        []uint32{0, 1},
+				OrderedGroupCols: []uint32{0},
+				Aggregations: []AggregatorSpec_Aggregation{
+					{
+						Func:   AggregatorSpec_IDENT,
+						ColIdx: []uint32{0},
+					},
+					{
+						Func:   AggregatorSpec_IDENT,
+						ColIdx: []uint32{1},
+					},
+					{
+						Func:   AggregatorSpec_SUM_INT,
+						ColIdx: []uint32{2},
+					},
+				},
+			},
+			inputTypes: threeIntCols,
+			input: sqlbase.EncDatumRows{
+				{v[1], v[2], v[1]},
+				{v[1], v[3], v[2]},
+				{v[1], v[2], v[3]},
+				{v[2], v[3], v[4]},
+				{v[2], v[3], v[5]},
+				{v[3], v[2], v[6]},
+			},
+			outputTypes: threeIntCols,
+			expected: sqlbase.EncDatumRows{
+				{v[1], v[2], v[4]},
+				{v[1], v[3], v[2]},
+				{v[2], v[3], v[9]},
+				{v[3], v[2], v[6]},
+			},
+		},
 		{
 			// SELECT MAX(@1) FILTER @2, COUNT(@3) FILTER @4, COUNT_ROWS FILTER @4
 			spec: AggregatorSpec{
@@ -343,48 +418,129 @@ func TestAggregator(t *testing.T) {
 		},
 	}
 
+	ctx := context.Background()
+	tempEngine, err := engine.NewTempEngine(base.DefaultTestTempStorageConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tempEngine.Close()
+
+	diskMonitor := mon.MakeMonitor(
+		"test-disk",
+		mon.DiskResource,
+		nil, /* curCount */
+		nil, /* maxHist */
+		-1,  /* increment: use default block size */
+		math.MaxInt64,
+	)
+	diskMonitor.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+	defer diskMonitor.Stop(ctx)
+
 	for _, c := range testCases {
-		t.Run("", func(t *testing.T) {
-			ags := c.spec
+		// Test with several memory limits:
+		// 0: Use the default limit.
+		// 150: Enough for any single group, but not for all the groups of the
+		//      GROUP BY cases, which then spill to disk.
+		for _, memLimit := range []int64{0, 150} {
+			t.Run(fmt.Sprintf("MemLimit=%d", memLimit), func(t *testing.T) {
+				ags := c.spec
 
-			in := NewRowBuffer(c.inputTypes, c.input, RowBufferArgs{})
-			out := NewRowBuffer(c.outputTypes, nil /* rows */, RowBufferArgs{})
-			evalCtx := tree.MakeTestingEvalContext()
-			defer evalCtx.Stop(context.Background())
-			flowCtx := FlowCtx{
-				Settings: cluster.MakeTestingClusterSettings(),
-				EvalCtx:  evalCtx,
-			}
+				in := NewRowBuffer(c.inputTypes, c.input, RowBufferArgs{})
+				out := NewRowBuffer(c.outputTypes, nil /* rows */, RowBufferArgs{})
+				evalCtx := tree.MakeTestingEvalContext()
+				defer evalCtx.Stop(ctx)
+				flowCtx := FlowCtx{
+					Settings:    cluster.MakeTestingClusterSettings(),
+					EvalCtx:     evalCtx,
+					TempStorage: tempEngine,
+					diskMonitor: &diskMonitor,
+				}
+				flowCtx.testingKnobs.MemoryLimitBytes = memLimit
 
-			ag, err := newAggregator(&flowCtx, &ags, in, &PostProcessSpec{}, out)
-			if err != nil {
-				t.Fatal(err)
-			}
+				ag, err := newAggregator(&flowCtx, &ags, in, &PostProcessSpec{}, out)
+				if err != nil {
+					t.Fatal(err)
+				}
 
-			ag.Run(context.Background(), nil)
+				ag.Run(ctx, nil)
 
-			var expected []string
-			for _, row := range c.expected {
-				expected = append(expected, row.String(c.outputTypes))
-			}
-			sort.Strings(expected)
-			expStr := strings.Join(expected, "")
+				var expected []string
+				for _, row := range c.expected {
+					expected = append(expected, row.String(c.outputTypes))
+				}
+				sort.Strings(expected)
+				expStr := strings.Join(expected, "")
+
+				var rets []string
+				for {
+					row := out.NextNoMeta(t)
+					if row == nil {
+						break
+					}
+					rets = append(rets, row.String(c.outputTypes))
+				}
+				sort.Strings(rets)
+				retStr := strings.Join(rets, "")
 
-			var rets []string
-			for {
-				row := out.NextNoMeta(t)
-				if row == nil {
-					break
+				if expStr != retStr {
+					t.Errorf("invalid results; expected:\n   %s\ngot:\n   %s",
+						expStr, retStr)
 				}
-				rets = append(rets, row.String(c.outputTypes))
-			}
-			sort.Strings(rets)
-			retStr := strings.Join(rets, "")
+			})
+		}
+	}
+}
 
-			if expStr != retStr {
-				t.Errorf("invalid results; expected:\n   %s\ngot:\n   %s",
-					expStr, retStr)
-			}
-		})
+// TestAggregatorStreaming verifies that an aggregator whose input is ordered
+// on the grouping columns outputs the groups without consuming all its input.
+func TestAggregatorStreaming(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var input sqlbase.EncDatumRows
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 3; j++ {
+			input = append(input, sqlbase.EncDatumRow{
+				sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(i))),
+			})
+		}
+	}
+	spec := AggregatorSpec{
+		GroupCols:        []uint32{0},
+		OrderedGroupCols: []uint32{0},
+		Aggregations: []AggregatorSpec_Aggregation{
+			{Func: AggregatorSpec_IDENT, ColIdx: []uint32{0}},
+			{Func: AggregatorSpec_COUNT_ROWS},
+		},
+	}
+
+	ctx := context.Background()
+	in := NewRowBuffer(oneIntCol, input, RowBufferArgs{})
+	out := NewRowBuffer(twoIntCols, nil /* rows */, RowBufferArgs{})
+	evalCtx := tree.MakeTestingEvalContext()
+	defer evalCtx.Stop(ctx)
+	flowCtx := FlowCtx{
+		Settings: cluster.MakeTestingClusterSettings(),
+		EvalCtx:  evalCtx,
+	}
+
+	ag, err := newAggregator(&flowCtx, &spec, in, &PostProcessSpec{Limit: 2}, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ag.Run(ctx, nil)
+
+	var rets []string
+	for {
+		row := out.NextNoMeta(t)
+		if row == nil {
+			break
+		}
+		rets = append(rets, row.String(twoIntCols))
+	}
+	if expected := "[0 3][1 3]"; strings.Join(rets, "") != expected {
+		t.Errorf("expected %s, got %s", expected, strings.Join(rets, ""))
+	}
+	if in.ConsumerStatus != DrainRequested {
+		t.Errorf("expected the input to be asked to drain, got %d", in.ConsumerStatus)
 	}
 }
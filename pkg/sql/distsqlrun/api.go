@@ -26,5 +26,6 @@ func MakeEvalContext(evalCtx tree.EvalContext) EvalContext {
 		Location:           evalCtx.GetLocation().String(),
 		Database:           evalCtx.Database,
 		User:               evalCtx.User,
+		Vectorize:          evalCtx.Vectorize,
 	}
 }
@@ -37,8 +37,16 @@ const (
 	leftOuter
 	rightOuter
 	fullOuter
+	leftSemi
+	leftAnti
 )
 
+// outputsLeftOnly returns true for the join types which output the left rows
+// themselves, without any columns from the right side.
+func (t joinType) outputsLeftOnly() bool {
+	return t == leftSemi || t == leftAnti
+}
+
 const rowChannelBufSize = 16
 
 type columns []uint32
@@ -290,11 +298,15 @@ type ProducerMetadata struct {
 	Err error
 	// TraceData is sent if snowball tracing is enabled.
 	TraceData []tracing.RecordedSpan
+	// TxnWrites is sent by processors that wrote on behalf of the flow's
+	// transaction; the gateway uses it to track the resulting intents.
+	TxnWrites *RemoteProducerMetadata_TxnWrites
 }
 
 // Empty returns true if none of the fields in metadata are populated.
 func (meta ProducerMetadata) Empty() bool {
-	return meta.Ranges == nil && meta.Err == nil && meta.TraceData == nil
+	return meta.Ranges == nil && meta.Err == nil && meta.TraceData == nil &&
+		meta.TxnWrites == nil
 }
 
 // RowChannel is a thin layer over a RowChannelMsg channel, which can be used to
@@ -0,0 +1,149 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// colTableScan is the vectorized counterpart of the tableReader: it reads the
+// rows of a table or index and outputs them in batches. The columns which are
+// not needed by the post-processing stage are not decoded and are output as
+// NULLs.
+type colTableScan struct {
+	flowCtx *FlowCtx
+	// ctx is set by the materializer before the operator is initialized.
+	ctx context.Context
+
+	spans     roachpb.Spans
+	limitHint int64
+
+	types     []sqlbase.ColumnType
+	execTypes []exec.Type
+	needed    []bool
+
+	fetcher sqlbase.RowFetcher
+	alloc   sqlbase.DatumAlloc
+	batch   exec.Batch
+	done    bool
+}
+
+var _ exec.Operator = &colTableScan{}
+
+func newColTableScan(
+	flowCtx *FlowCtx, spec *TableReaderSpec, post *PostProcessSpec,
+) (*colTableScan, error) {
+	if flowCtx.nodeID == 0 {
+		return nil, errors.Errorf("attempting to create a colTableScan with uninitialized NodeID")
+	}
+	if spec.LockForUpdate {
+		return nil, errors.Errorf("row locking is not supported by the vectorized engine")
+	}
+	s := &colTableScan{
+		flowCtx:   flowCtx,
+		limitHint: tableReaderLimitHint(spec, post),
+		types:     make([]sqlbase.ColumnType, len(spec.Table.Columns)),
+		execTypes: make([]exec.Type, len(spec.Table.Columns)),
+	}
+	for i := range s.types {
+		s.types[i] = spec.Table.Columns[i].Type
+	}
+
+	// The columns needed by the post-processing stage are determined with a
+	// throwaway helper.
+	var h ProcOutputHelper
+	if err := h.Init(post, s.types, &flowCtx.EvalCtx, nil /* output */); err != nil {
+		return nil, err
+	}
+	s.needed = h.neededColumns()
+	for i, t := range s.types {
+		if !s.needed[i] {
+			continue
+		}
+		if s.execTypes[i] = execType(t); s.execTypes[i] == exec.Unknown {
+			return nil, errors.Errorf("unsupported column type %s", t.SQLString())
+		}
+	}
+
+	desc := spec.Table
+	if _, _, err := initRowFetcher(
+		&s.fetcher, &desc, int(spec.IndexIdx), spec.Reverse, sqlbase.ScanLocking{}, s.needed, &s.alloc,
+	); err != nil {
+		return nil, err
+	}
+
+	s.spans = make(roachpb.Spans, len(spec.Spans))
+	for i, sp := range spec.Spans {
+		s.spans[i] = sp.Span
+	}
+	return s, nil
+}
+
+// Init is part of the exec.Operator interface.
+func (s *colTableScan) Init() {
+	s.batch = exec.NewMemBatch(s.execTypes)
+	if err := s.fetcher.StartScan(
+		s.ctx, s.flowCtx.txn, s.spans, true /* limit batches */, s.limitHint, false, /* traceKV */
+	); err != nil {
+		exec.RaiseError(err)
+	}
+}
+
+// Next is part of the exec.Operator interface.
+func (s *colTableScan) Next() exec.Batch {
+	s.batch.SetSelection(false)
+	for i := range s.types {
+		s.batch.ColVec(i).UnsetNulls()
+	}
+	var n uint16
+	for !s.done && n < exec.BatchSize {
+		row, err := s.fetcher.NextRow(s.ctx)
+		if err != nil {
+			exec.RaiseError(err)
+		}
+		if row == nil {
+			s.done = true
+			break
+		}
+		for i := range s.types {
+			if !s.needed[i] {
+				continue
+			}
+			if err := row[i].EnsureDecoded(&s.types[i], &s.alloc); err != nil {
+				exec.RaiseError(err)
+			}
+			setVecDatum(s.batch.ColVec(i), n, row[i].Datum)
+		}
+		n++
+		// With a small limit, return the rows right away instead of reading
+		// more than needed.
+		if s.limitHint != 0 && int64(n) >= s.limitHint {
+			break
+		}
+	}
+	s.batch.SetLength(n)
+	return s.batch
+}
+
+// sendMisplannedRangesMetadata sends information about the non-local ranges
+// that were read by the scan.
+func (s *colTableScan) sendMisplannedRangesMetadata(ctx context.Context, output RowReceiver) {
+	sendMisplannedRangesMetadata(ctx, &s.fetcher, s.flowCtx.nodeID, output)
+}
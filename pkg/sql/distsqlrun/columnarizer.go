@@ -0,0 +1,162 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// execType returns the representation of the values of the given column type
+// in the vectorized engine, or exec.Unknown if they can't be represented.
+func execType(t sqlbase.ColumnType) exec.Type {
+	switch t.SemanticType {
+	case sqlbase.ColumnType_BOOL:
+		return exec.Bool
+	case sqlbase.ColumnType_INT:
+		return exec.Int64
+	case sqlbase.ColumnType_FLOAT:
+		return exec.Float64
+	case sqlbase.ColumnType_STRING, sqlbase.ColumnType_BYTES:
+		return exec.Bytes
+	}
+	return exec.Unknown
+}
+
+// execTypes converts column types to vectorized types; it returns an error if
+// any of the types can't be represented.
+func execTypes(types []sqlbase.ColumnType) ([]exec.Type, error) {
+	res := make([]exec.Type, len(types))
+	for i, t := range types {
+		res[i] = execType(t)
+		if res[i] == exec.Unknown {
+			return nil, errors.Errorf("unsupported column type %s", t.SQLString())
+		}
+	}
+	return res, nil
+}
+
+// setVecDatum sets the i-th value of a vector to the given datum, which must
+// be NULL or of a type represented by the vector.
+func setVecDatum(vec exec.ColVec, i uint16, d tree.Datum) {
+	if d == tree.DNull {
+		vec.SetNull(i)
+		return
+	}
+	switch t := d.(type) {
+	case *tree.DBool:
+		vec.Bool()[i] = bool(*t)
+	case *tree.DInt:
+		vec.Int64()[i] = int64(*t)
+	case *tree.DFloat:
+		vec.Float64()[i] = float64(*t)
+	case *tree.DString:
+		vec.Bytes()[i] = append(vec.Bytes()[i][:0], *t...)
+	case *tree.DBytes:
+		vec.Bytes()[i] = append(vec.Bytes()[i][:0], *t...)
+	default:
+		panic(errors.Errorf("unsupported datum %s", d))
+	}
+}
+
+// vecDatum returns the i-th value of a vector as a datum of the given type.
+func vecDatum(
+	vec exec.ColVec, i uint16, t *sqlbase.ColumnType, alloc *sqlbase.DatumAlloc,
+) tree.Datum {
+	if vec.HasNulls() && vec.NullAt(i) {
+		return tree.DNull
+	}
+	switch t.SemanticType {
+	case sqlbase.ColumnType_BOOL:
+		return tree.MakeDBool(tree.DBool(vec.Bool()[i]))
+	case sqlbase.ColumnType_INT:
+		return alloc.NewDInt(tree.DInt(vec.Int64()[i]))
+	case sqlbase.ColumnType_FLOAT:
+		return alloc.NewDFloat(tree.DFloat(vec.Float64()[i]))
+	case sqlbase.ColumnType_STRING:
+		return alloc.NewDString(tree.DString(vec.Bytes()[i]))
+	case sqlbase.ColumnType_BYTES:
+		return alloc.NewDBytes(tree.DBytes(vec.Bytes()[i]))
+	}
+	panic(errors.Errorf("unsupported column type %s", t.SQLString()))
+}
+
+// columnarizer is the vectorized operator which turns the rows of a RowSource
+// into batches. It is used for the inputs of the processors run by the
+// vectorized engine.
+//
+// The metadata received from the input is forwarded to metadataSink as it
+// arrives; errors are raised with exec.RaiseError.
+type columnarizer struct {
+	input        RowSource
+	metadataSink RowReceiver
+	types        []sqlbase.ColumnType
+	execTypes    []exec.Type
+
+	batch exec.Batch
+	alloc sqlbase.DatumAlloc
+}
+
+var _ exec.Operator = &columnarizer{}
+
+func newColumnarizer(input RowSource, metadataSink RowReceiver) (*columnarizer, error) {
+	c := &columnarizer{input: input, metadataSink: metadataSink, types: input.Types()}
+	var err error
+	if c.execTypes, err = execTypes(c.types); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Init is part of the exec.Operator interface.
+func (c *columnarizer) Init() {
+	c.batch = exec.NewMemBatch(c.execTypes)
+}
+
+// Next is part of the exec.Operator interface.
+func (c *columnarizer) Next() exec.Batch {
+	c.batch.SetSelection(false)
+	for i := range c.types {
+		c.batch.ColVec(i).UnsetNulls()
+	}
+	var n uint16
+	for n < exec.BatchSize {
+		row, meta := c.input.Next()
+		if !meta.Empty() {
+			if meta.Err != nil {
+				exec.RaiseError(meta.Err)
+			}
+			// The status is ignored; the materializer notices it when it emits
+			// its next row.
+			_ = c.metadataSink.Push(nil /* row */, meta)
+			continue
+		}
+		if row == nil {
+			break
+		}
+		for i := range c.types {
+			if err := row[i].EnsureDecoded(&c.types[i], &c.alloc); err != nil {
+				exec.RaiseError(err)
+			}
+			setVecDatum(c.batch.ColVec(i), n, row[i].Datum)
+		}
+		n++
+	}
+	c.batch.SetLength(n)
+	return c.batch
+}
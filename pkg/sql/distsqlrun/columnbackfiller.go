@@ -94,7 +94,12 @@ func (cb *columnBackfiller) init() error {
 	}
 
 	cb.updateCols = append(cb.added, cb.dropped...)
-	if len(cb.dropped) > 0 || len(defaultExprs) > 0 {
+	// The values of the computed columns are computed by the row updater.
+	hasComputed := false
+	for i := range cb.added {
+		hasComputed = hasComputed || cb.added[i].IsComputed()
+	}
+	if len(cb.dropped) > 0 || len(defaultExprs) > 0 || hasComputed {
 		// Populate default values.
 		cb.updateExprs = make([]tree.TypedExpr, len(cb.updateCols))
 		for j := range cb.added {
@@ -120,7 +125,7 @@ func (cb *columnBackfiller) init() error {
 		colIdxMap[c.ID] = i
 	}
 	return cb.fetcher.Init(
-		&desc, colIdxMap, &desc.PrimaryIndex, false /* reverse */, false, /* lockForUpdate */
+		&desc, colIdxMap, &desc.PrimaryIndex, false /* reverse */, sqlbase.ScanLocking{},
 		false /* isSecondaryIndex */, desc.Columns, valNeededForCol, false /* returnRangeInfo */, &cb.alloc,
 	)
 }
@@ -17,10 +17,13 @@ package distsqlrun
 import (
 	"sync"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
@@ -36,6 +39,16 @@ type distinct struct {
 	distinctCols map[uint32]struct{}
 	memAcc       mon.BoundAccount
 	datumAlloc   sqlbase.DatumAlloc
+
+	// useTempStorage is set if the seen set can be spilled to temp storage
+	// once the memory budget is exhausted.
+	useTempStorage bool
+	// seenOnDisk holds the encodings that did not fit in the in-memory seen
+	// set. It is nil until the memory budget is exhausted. Encodings are
+	// looked up in both sets.
+	seenOnDisk engine.SortedDiskMap
+	// diskAcc keeps track of seenOnDisk's disk usage.
+	diskAcc mon.BoundAccount
 }
 
 var _ Processor = &distinct{}
@@ -70,12 +83,32 @@ func (d *distinct) Run(ctx context.Context, wg *sync.WaitGroup) {
 	if wg != nil {
 		defer wg.Done()
 	}
-	defer d.memAcc.Close(ctx)
 
 	ctx = log.WithLogTag(ctx, "Evaluator", nil)
 	ctx, span := processorSpan(ctx, "distinct")
 	defer tracing.FinishSpan(span)
 
+	// Enable fall back to disk if the cluster setting is set or a memory limit
+	// has been set through testing.
+	st := d.flowCtx.Settings
+	d.useTempStorage = settingUseTempStorageDistinct.Get(&st.SV) ||
+		d.flowCtx.testingKnobs.MemoryLimitBytes > 0
+	if d.useTempStorage {
+		// Limit the memory use by creating a child monitor with a hard limit.
+		// The seen set will overflow to disk if this limit is not enough.
+		limit := d.flowCtx.testingKnobs.MemoryLimitBytes
+		if limit <= 0 {
+			limit = settingWorkMemBytes.Get(&st.SV)
+		}
+		limitedMon := mon.MakeMonitorInheritWithLimit("distinct-limited", limit, d.flowCtx.EvalCtx.Mon)
+		limitedMon.Start(ctx, d.flowCtx.EvalCtx.Mon, mon.BoundAccount{})
+		defer limitedMon.Stop(ctx)
+
+		d.memAcc = limitedMon.MakeBoundAccount()
+	}
+	defer d.memAcc.Close(ctx)
+	defer d.closeSeenOnDisk(ctx)
+
 	if log.V(2) {
 		log.Infof(ctx, "starting distinct process")
 		defer log.Infof(ctx, "exiting distinct")
@@ -130,15 +163,14 @@ func (d *distinct) mainLoop(ctx context.Context) (earlyExit bool, _ error) {
 			d.lastGroupKey = row
 			d.seen = make(map[string]struct{})
 			d.memAcc.Clear(ctx)
+			d.closeSeenOnDisk(ctx)
 		}
 
-		if _, ok := d.seen[string(encoding)]; !ok {
-			if len(encoding) > 0 {
-				if err := d.memAcc.Grow(ctx, int64(len(encoding))); err != nil {
-					return false, err
-				}
-				d.seen[string(encoding)] = struct{}{}
-			}
+		isNew, err := d.markSeen(ctx, encoding)
+		if err != nil {
+			return false, err
+		}
+		if isNew {
 			if !emitHelper(ctx, &d.out, row, ProducerMetadata{}, d.input) {
 				// No cleanup required; emitHelper() took care of it.
 				return true, nil
@@ -148,6 +180,66 @@ func (d *distinct) mainLoop(ctx context.Context) (earlyExit bool, _ error) {
 	}
 }
 
+// seenOnDiskValue is the value stored in seenOnDisk for every encoding. It is
+// non-empty so that a stored entry can be told apart from a missing one.
+var seenOnDiskValue = []byte{1}
+
+// markSeen adds the encoding to the seen set and returns whether it was not
+// already present. If the in-memory seen set exceeds the memory budget and
+// temp storage is enabled, new encodings are stored on disk instead.
+func (d *distinct) markSeen(ctx context.Context, encoding []byte) (bool, error) {
+	if _, ok := d.seen[string(encoding)]; ok {
+		return false, nil
+	}
+	if len(encoding) == 0 {
+		return true, nil
+	}
+	if d.seenOnDisk != nil {
+		v, err := d.seenOnDisk.Get(encoding)
+		if err != nil {
+			return false, err
+		}
+		if v != nil {
+			return false, nil
+		}
+		return true, d.putSeenOnDisk(ctx, encoding)
+	}
+
+	err := d.memAcc.Grow(ctx, int64(len(encoding)))
+	if err == nil {
+		d.seen[string(encoding)] = struct{}{}
+		return true, nil
+	}
+	if pgErr, ok := pgerror.GetPGCause(err); !(ok && pgErr.Code == pgerror.CodeOutOfMemoryError) {
+		return false, err
+	}
+	if !d.useTempStorage {
+		return false, errors.Wrap(err, "external storage for large queries disabled")
+	}
+
+	log.VEventf(ctx, 2, "falling back to disk")
+	d.seenOnDisk = engine.NewRocksDBMap(d.flowCtx.TempStorage)
+	d.diskAcc = d.flowCtx.diskMonitor.MakeBoundAccount()
+	return true, d.putSeenOnDisk(ctx, encoding)
+}
+
+func (d *distinct) putSeenOnDisk(ctx context.Context, encoding []byte) error {
+	if err := d.diskAcc.Grow(ctx, int64(len(encoding)+len(seenOnDiskValue))); err != nil {
+		return errors.Wrapf(err, "this query requires additional disk space")
+	}
+	return d.seenOnDisk.Put(encoding, seenOnDiskValue)
+}
+
+// closeSeenOnDisk releases the on-disk seen set, if any.
+func (d *distinct) closeSeenOnDisk(ctx context.Context) {
+	if d.seenOnDisk == nil {
+		return
+	}
+	d.seenOnDisk.Close(ctx)
+	d.seenOnDisk = nil
+	d.diskAcc.Close(ctx)
+}
+
 func (d *distinct) matchLastGroupKey(row sqlbase.EncDatumRow) (bool, error) {
 	if d.lastGroupKey == nil {
 		return false, nil
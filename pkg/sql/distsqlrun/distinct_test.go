@@ -15,12 +15,17 @@
 package distsqlrun
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 
 	"golang.org/x/net/context"
 )
@@ -131,41 +136,67 @@ func TestDistinct(t *testing.T) {
 		},
 	}
 
+	ctx := context.Background()
+	tempEngine, err := engine.NewTempEngine(base.DefaultTestTempStorageConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tempEngine.Close()
+
+	diskMonitor := mon.MakeMonitor(
+		"test-disk",
+		mon.DiskResource,
+		nil, /* curCount */
+		nil, /* maxHist */
+		-1,  /* increment: use default block size */
+		math.MaxInt64,
+	)
+	diskMonitor.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+	defer diskMonitor.Stop(ctx)
+
 	for _, c := range testCases {
-		t.Run("", func(t *testing.T) {
-			ds := c.spec
+		// Test with several memory limits:
+		// 0: Use the default limit.
+		// 1: Immediately spill the seen set to disk.
+		for _, memLimit := range []int64{0, 1} {
+			t.Run(fmt.Sprintf("MemLimit=%d", memLimit), func(t *testing.T) {
+				ds := c.spec
 
-			in := NewRowBuffer(twoIntCols, c.input, RowBufferArgs{})
-			out := &RowBuffer{}
+				in := NewRowBuffer(twoIntCols, c.input, RowBufferArgs{})
+				out := &RowBuffer{}
 
-			evalCtx := tree.MakeTestingEvalContext()
-			defer evalCtx.Stop(context.Background())
-			flowCtx := FlowCtx{
-				Settings: cluster.MakeTestingClusterSettings(),
-				EvalCtx:  evalCtx,
-			}
+				evalCtx := tree.MakeTestingEvalContext()
+				defer evalCtx.Stop(ctx)
+				flowCtx := FlowCtx{
+					Settings:    cluster.MakeTestingClusterSettings(),
+					EvalCtx:     evalCtx,
+					TempStorage: tempEngine,
+					diskMonitor: &diskMonitor,
+				}
+				flowCtx.testingKnobs.MemoryLimitBytes = memLimit
 
-			d, err := newDistinct(&flowCtx, &ds, in, &PostProcessSpec{}, out)
-			if err != nil {
-				t.Fatal(err)
-			}
+				d, err := newDistinct(&flowCtx, &ds, in, &PostProcessSpec{}, out)
+				if err != nil {
+					t.Fatal(err)
+				}
 
-			d.Run(context.Background(), nil)
-			if !out.ProducerClosed {
-				t.Fatalf("output RowReceiver not closed")
-			}
-			var res sqlbase.EncDatumRows
-			for {
-				row := out.NextNoMeta(t)
-				if row == nil {
-					break
+				d.Run(ctx, nil)
+				if !out.ProducerClosed {
+					t.Fatalf("output RowReceiver not closed")
+				}
+				var res sqlbase.EncDatumRows
+				for {
+					row := out.NextNoMeta(t)
+					if row == nil {
+						break
+					}
+					res = append(res, row)
 				}
-				res = append(res, row)
-			}
 
-			if result := res.String(twoIntCols); result != c.expected.String(twoIntCols) {
-				t.Errorf("invalid results: %s, expected %s'", result, c.expected.String(twoIntCols))
-			}
-		})
+				if result := res.String(twoIntCols); result != c.expected.String(twoIntCols) {
+					t.Errorf("invalid results: %s, expected %s'", result, c.expected.String(twoIntCols))
+				}
+			})
+		}
 	}
 }
@@ -81,6 +81,10 @@ type FlowCtx struct {
 	// diskMonitor is used to monitor temporary storage disk usage.
 	diskMonitor *mon.BytesMonitor
 
+	// collectStats is set if the processors and streams of the flow collect
+	// execution statistics (see SetupFlowRequest.CollectStats).
+	collectStats bool
+
 	// JobRegistry is used during backfill to load jobs which keep state.
 	JobRegistry *jobs.Registry
 }
@@ -138,6 +142,10 @@ type Flow struct {
 
 	// spec is the request that produced this flow. Only used for debugging.
 	spec *FlowSpec
+
+	// statsMonitors are the monitors set up for the processors whose execution
+	// statistics are collected; they are stopped in Cleanup().
+	statsMonitors []*mon.BytesMonitor
 }
 
 func newFlow(flowCtx FlowCtx, flowReg *flowRegistry, syncFlowConsumer RowReceiver) *Flow {
@@ -203,7 +211,7 @@ func (f *Flow) setupOutboundStream(spec StreamEndpointSpec) (RowReceiver, error)
 	case StreamEndpointSpec_REMOTE:
 		outbox := newOutbox(&f.FlowCtx, spec.TargetAddr, f.id, sid)
 		f.startables = append(f.startables, outbox)
-		return outbox, nil
+		return f.maybeCollectStreamStats(sid, outbox), nil
 
 	case StreamEndpointSpec_LOCAL:
 		rowChan, found := f.localStreams[sid]
@@ -215,12 +223,21 @@ func (f *Flow) setupOutboundStream(spec StreamEndpointSpec) (RowReceiver, error)
 			return nil, errors.Errorf("stream %d has multiple connections", sid)
 		}
 		f.localStreams[sid] = nil
-		return rowChan, nil
+		return f.maybeCollectStreamStats(sid, rowChan), nil
 	default:
 		return nil, errors.Errorf("invalid stream type %d", spec.Type)
 	}
 }
 
+// maybeCollectStreamStats wraps the producer side of a stream so that its
+// execution statistics are collected, if the flow collects statistics.
+func (f *Flow) maybeCollectStreamStats(sid StreamID, stream RowReceiver) RowReceiver {
+	if !f.collectStats {
+		return stream
+	}
+	return &streamStatsCollector{RowReceiver: stream, flow: f, streamID: sid}
+}
+
 // setupRouter initializes a router and the outbound streams.
 //
 // Pass-through routers are not supported; they should be handled separately.
@@ -246,7 +263,9 @@ func checkNumInOut(inputs []RowSource, outputs []RowReceiver, numIn, numOut int)
 	return nil
 }
 
-func (f *Flow) makeProcessor(ps *ProcessorSpec, inputs []RowSource) (Processor, error) {
+func (f *Flow) makeProcessor(
+	ctx context.Context, ps *ProcessorSpec, inputs []RowSource,
+) (Processor, error) {
 	if len(ps.Output) != 1 {
 		return nil, errors.Errorf("only single-output processors supported")
 	}
@@ -274,7 +293,15 @@ func (f *Flow) makeProcessor(ps *ProcessorSpec, inputs []RowSource) (Processor,
 		outputs[i] = r
 		f.startables = append(f.startables, r)
 	}
-	proc, err := newProcessor(&f.FlowCtx, &ps.Core, &ps.Post, inputs, outputs)
+	flowCtx, procOutputs := &f.FlowCtx, outputs
+	var statsProc *statsProcessor
+	if f.collectStats {
+		procOutputs = make([]RowReceiver, 1)
+		statsProc, flowCtx, inputs, procOutputs[0] = f.newStatsProcessor(
+			ctx, ps.ProcessorID, inputs, outputs[0],
+		)
+	}
+	proc, err := newProcessor(flowCtx, &ps.Core, &ps.Post, inputs, procOutputs)
 	if err != nil {
 		return nil, err
 	}
@@ -286,8 +313,16 @@ func (f *Flow) makeProcessor(ps *ProcessorSpec, inputs []RowSource) (Processor,
 			o.init(&f.FlowCtx, types)
 		case *outbox:
 			o.init(types)
+		case *streamStatsCollector:
+			if ob, ok := o.RowReceiver.(*outbox); ok {
+				ob.init(types)
+			}
 		}
 	}
+	if statsProc != nil {
+		statsProc.Processor = proc
+		return statsProc, nil
+	}
 	return proc, nil
 }
 
@@ -349,7 +384,7 @@ func (f *Flow) setup(ctx context.Context, spec *FlowSpec) error {
 
 	for i := range spec.Processors {
 		var err error
-		f.processors[i], err = f.makeProcessor(&spec.Processors[i], inputSyncs[i])
+		f.processors[i], err = f.makeProcessor(ctx, &spec.Processors[i], inputSyncs[i])
 		if err != nil {
 			return err
 		}
@@ -427,6 +462,9 @@ func (f *Flow) Cleanup(ctx context.Context) {
 	if f.status == FlowFinished {
 		panic("flow cleanup called twice")
 	}
+	for _, m := range f.statsMonitors {
+		m.Stop(ctx)
+	}
 	// This closes the account and monitor opened in ServerImpl.setupFlow.
 	f.EvalCtx.ActiveMemAcc.Close(ctx)
 	f.EvalCtx.Stop(ctx)
@@ -121,6 +121,15 @@ func (jr *JoinReaderSpec) summary() (string, []string) {
 	details := []string{
 		fmt.Sprintf("%s@%s", index, jr.Table.Name),
 	}
+	if len(jr.LookupColumns) > 0 {
+		details = append(details, fmt.Sprintf("Lookup join on: %s", colListStr(jr.LookupColumns)))
+		if jr.Type != JoinType_INNER {
+			details = append(details, fmt.Sprintf("Type: %s", jr.Type))
+		}
+		if jr.OnExpr.Expr != "" {
+			details = append(details, fmt.Sprintf("ON %s", jr.OnExpr.Expr))
+		}
+	}
 	return "JoinReader", details
 }
 
@@ -266,10 +275,11 @@ type diagramProcessor struct {
 }
 
 type diagramEdge struct {
-	SourceProc   int `json:"sourceProc"`
-	SourceOutput int `json:"sourceOutput"`
-	DestProc     int `json:"destProc"`
-	DestInput    int `json:"destInput"`
+	SourceProc   int      `json:"sourceProc"`
+	SourceOutput int      `json:"sourceOutput"`
+	DestProc     int      `json:"destProc"`
+	DestInput    int      `json:"destInput"`
+	Stats        []string `json:"stats,omitempty"`
 }
 
 type diagramData struct {
@@ -278,7 +288,12 @@ type diagramData struct {
 	Edges      []diagramEdge      `json:"edges"`
 }
 
-func generateDiagramData(flows []FlowSpec, nodeNames []string) (diagramData, error) {
+// generateDiagramData generates the diagram data for the given flows. If stats
+// is not nil, the execution statistics of the processors and streams are added
+// to the diagram.
+func generateDiagramData(
+	flows []FlowSpec, nodeNames []string, stats *FlowStats,
+) (diagramData, error) {
 	d := diagramData{NodeNames: nodeNames}
 
 	// inPorts maps streams to their "destination" attachment point. Only DestProc
@@ -292,6 +307,11 @@ func generateDiagramData(flows []FlowSpec, nodeNames []string) (diagramData, err
 			proc := diagramProcessor{NodeIdx: n}
 			proc.Core.Title, proc.Core.Details = p.Core.GetValue().(diagramCellType).summary()
 			proc.Core.Details = append(proc.Core.Details, p.Post.summary()...)
+			if stats != nil {
+				if s, ok := stats.Processors[p.ProcessorID]; ok {
+					proc.Core.Details = append(proc.Core.Details, formatStats(s.Fields())...)
+				}
+			}
 
 			// We need explicit synchronizers if we have multiple inputs, or if the
 			// one input has multiple input streams.
@@ -376,6 +396,11 @@ func generateDiagramData(flows []FlowSpec, nodeNames []string) (diagramData, err
 						}
 						edge.DestProc = to.DestProc
 						edge.DestInput = to.DestInput
+						if stats != nil {
+							if s, ok := stats.Streams[o.StreamID]; ok {
+								edge.Stats = formatStats(s.Fields())
+							}
+						}
 					}
 					d.Edges = append(d.Edges, edge)
 				}
@@ -386,10 +411,24 @@ func generateDiagramData(flows []FlowSpec, nodeNames []string) (diagramData, err
 	return d, nil
 }
 
+func formatStats(fields []StatField) []string {
+	res := make([]string, len(fields))
+	for i, f := range fields {
+		res[i] = fmt.Sprintf("%s: %s", f.Name, f.Value)
+	}
+	return res
+}
+
 // GeneratePlanDiagram generates the json data for a flow diagram.  There should
 // be one FlowSpec per node. The function assumes that StreamIDs are unique
 // across all flows.
 func GeneratePlanDiagram(flows map[roachpb.NodeID]FlowSpec, w io.Writer) error {
+	return generatePlanDiagram(flows, nil /* stats */, w)
+}
+
+func generatePlanDiagram(
+	flows map[roachpb.NodeID]FlowSpec, stats *FlowStats, w io.Writer,
+) error {
 	// We sort the flows by node because we want the diagram data to be
 	// deterministic.
 	nodeIDs := make([]int, 0, len(flows))
@@ -407,7 +446,7 @@ func GeneratePlanDiagram(flows map[roachpb.NodeID]FlowSpec, w io.Writer) error {
 		nodeNames[i] = n.String()
 	}
 
-	d, err := generateDiagramData(flowSlice, nodeNames)
+	d, err := generateDiagramData(flowSlice, nodeNames, stats)
 	if err != nil {
 		return err
 	}
@@ -418,8 +457,23 @@ func GeneratePlanDiagram(flows map[roachpb.NodeID]FlowSpec, w io.Writer) error {
 // URL which encodes the diagram. There should be one FlowSpec per node. The
 // function assumes that StreamIDs are unique across all flows.
 func GeneratePlanDiagramWithURL(flows map[roachpb.NodeID]FlowSpec) (string, url.URL, error) {
+	return generatePlanDiagramWithURL(flows, nil /* stats */)
+}
+
+// GeneratePlanDiagramWithStats is like GeneratePlanDiagramWithURL, but the
+// diagram is annotated with the execution statistics collected while running
+// the flows.
+func GeneratePlanDiagramWithStats(
+	flows map[roachpb.NodeID]FlowSpec, stats FlowStats,
+) (string, url.URL, error) {
+	return generatePlanDiagramWithURL(flows, &stats)
+}
+
+func generatePlanDiagramWithURL(
+	flows map[roachpb.NodeID]FlowSpec, stats *FlowStats,
+) (string, url.URL, error) {
 	var json, compressed bytes.Buffer
-	if err := GeneratePlanDiagram(flows, &json); err != nil {
+	if err := generatePlanDiagram(flows, stats, &json); err != nil {
 		return "", url.URL{}, err
 	}
 	jsonStr := json.String()
@@ -75,6 +75,9 @@ func (p hashJoinPhase) String() string {
 //  3. Probe phase: in this phase we process all the rows from the other stream
 //     and look for matching rows from the stored stream using the map.
 //
+// Semi and anti joins always store the right stream: the left rows are output
+// (at most once) as they are probed.
+//
 // There is no guarantee on the output ordering.
 type hashJoiner struct {
 	joinerBase
@@ -431,7 +434,7 @@ func (h *hashJoiner) bufferPhase(
 	ctx context.Context,
 ) (row sqlbase.EncDatumRow, earlyExit bool, _ error) {
 	srcs := [2]RowSource{h.leftSource, h.rightSource}
-	for {
+	for !h.joinType.outputsLeftOnly() {
 		if err := h.cancelChecker.Check(); err != nil {
 			return nil, false, err
 		}
@@ -482,8 +485,9 @@ func (h *hashJoiner) bufferPhase(
 		}
 	}
 
-	// We did not find a short stream. Stop reading for both streams, just
-	// choose the right stream and consume it.
+	// We did not find a short stream (or the type of join requires storing the
+	// right stream). Stop reading for both streams, just choose the right
+	// stream and consume it.
 	h.storedSide = rightSide
 
 	for {
@@ -541,6 +545,17 @@ func (h *hashJoiner) probeRow(
 		// If the ON condition failed, renderedRow is nil.
 		if renderedRow != nil {
 			probeMatched = true
+			if h.joinType.outputsLeftOnly() {
+				// The stored side is the right side. A semi join outputs the left
+				// row on its first match; an anti join doesn't output it at all.
+				if h.joinType == leftSemi {
+					consumerStatus, err := h.out.EmitRow(ctx, row)
+					if err != nil || consumerStatus != NeedMoreRows {
+						return true, nil
+					}
+				}
+				break
+			}
 			if shouldEmitUnmatchedRow(h.storedSide, h.joinType) {
 				// Mark the row on the stored side. The unmarked rows can then
 				// be iterated over for {right, left} outer joins (depending on
@@ -507,6 +507,65 @@ func TestHashJoiner(t *testing.T) {
 				{null, null, null, null, null},
 			},
 		},
+
+		// Tests for semi and anti joins: each left row is output at most once,
+		// depending on whether it has a match.
+		{
+			spec: HashJoinerSpec{
+				LeftEqColumns:  []uint32{0},
+				RightEqColumns: []uint32{0},
+				Type:           JoinType_LEFT_SEMI,
+				OnExpr:         Expression{Expr: "@4 > 1"},
+			},
+			outCols:   []uint32{0, 1},
+			leftTypes: twoIntCols,
+			leftInput: sqlbase.EncDatumRows{
+				{v[0], v[0]},
+				{v[1], v[1]},
+				{v[2], v[2]},
+				{null, v[3]},
+			},
+			rightTypes: twoIntCols,
+			rightInput: sqlbase.EncDatumRows{
+				{v[0], v[1]},
+				{v[1], v[2]},
+				{v[1], v[3]},
+				{v[3], v[4]},
+				{null, v[5]},
+			},
+			expected: sqlbase.EncDatumRows{
+				{v[1], v[1]},
+			},
+		},
+		{
+			spec: HashJoinerSpec{
+				LeftEqColumns:  []uint32{0},
+				RightEqColumns: []uint32{0},
+				Type:           JoinType_LEFT_ANTI,
+				OnExpr:         Expression{Expr: "@4 > 1"},
+			},
+			outCols:   []uint32{0, 1},
+			leftTypes: twoIntCols,
+			leftInput: sqlbase.EncDatumRows{
+				{v[0], v[0]},
+				{v[1], v[1]},
+				{v[2], v[2]},
+				{null, v[3]},
+			},
+			rightTypes: twoIntCols,
+			rightInput: sqlbase.EncDatumRows{
+				{v[0], v[1]},
+				{v[1], v[2]},
+				{v[1], v[3]},
+				{v[3], v[4]},
+				{null, v[5]},
+			},
+			expected: sqlbase.EncDatumRows{
+				{v[0], v[0]},
+				{v[2], v[2]},
+				{null, v[3]},
+			},
+		},
 	}
 
 	ctx := context.Background()
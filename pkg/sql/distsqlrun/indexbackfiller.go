@@ -103,11 +103,32 @@ func (ib *indexBackfiller) init() error {
 			for i, col := range cols {
 				valNeededForCol[i] = valNeededForCol[i] || idx.ContainsColumnID(col.ID)
 			}
+			// The predicate of a partial index determines which rows have
+			// entries in the index.
+			predCols, err := desc.IndexPredicateColumnIDs(idx)
+			if err != nil {
+				return err
+			}
+			for _, colID := range predCols {
+				valNeededForCol[ib.colIdxMap[colID]] = true
+			}
+			// The computed columns of expression indexes are computed from
+			// the columns they depend on.
+			for _, colID := range idx.ColumnIDs {
+				col := &cols[ib.colIdxMap[colID]]
+				deps, err := desc.ComputedColumnDeps(col)
+				if err != nil {
+					return err
+				}
+				for _, dep := range deps {
+					valNeededForCol[ib.colIdxMap[dep]] = true
+				}
+			}
 		}
 	}
 
 	return ib.fetcher.Init(
-		&desc, ib.colIdxMap, &desc.PrimaryIndex, false /* reverse */, false, /* lockForUpdate */
+		&desc, ib.colIdxMap, &desc.PrimaryIndex, false /* reverse */, sqlbase.ScanLocking{},
 		false /* isSecondaryIndex */, cols, valNeededForCol, false /* returnRangeInfo */, &ib.alloc,
 	)
 }
@@ -136,6 +157,29 @@ func (ib *indexBackfiller) runChunk(
 		added[i] = *m.GetIndex()
 	}
 	secondaryIndexEntries := make([]sqlbase.IndexEntry, len(mutations))
+	predicates, err := sqlbase.MakePartialIndexPredicates(&ib.spec.Table, added)
+	if err != nil {
+		return nil, err
+	}
+	// The values of the computed columns of expression indexes are computed
+	// from the rows instead of being read, so that the index backfill doesn't
+	// depend on the backfill of the columns.
+	var computedCols []sqlbase.ColumnDescriptor
+	for i := range added {
+		for _, colID := range added[i].ColumnIDs {
+			col, err := ib.spec.Table.FindColumnByID(colID)
+			if err != nil {
+				return nil, err
+			}
+			if col.IsComputed() {
+				computedCols = append(computedCols, *col)
+			}
+		}
+	}
+	computed, err := sqlbase.MakeComputedColumns(&ib.spec.Table, computedCols)
+	if err != nil {
+		return nil, err
+	}
 
 	buildIndexEntries := func(ctx context.Context, txn *client.Txn) ([]sqlbase.IndexEntry, error) {
 		entries := make([]sqlbase.IndexEntry, 0, chunkSize*int64(len(added)))
@@ -169,12 +213,26 @@ func (ib *indexBackfiller) runChunk(
 			if err := sqlbase.EncDatumRowToDatums(ib.types, ib.rowVals, encRow, &ib.da); err != nil {
 				return nil, err
 			}
+			if err := computed.Eval(ib.colIdxMap, ib.rowVals); err != nil {
+				return nil, err
+			}
 			if err := sqlbase.EncodeSecondaryIndexes(
 				&ib.spec.Table, added, ib.colIdxMap,
 				ib.rowVals, secondaryIndexEntries); err != nil {
 				return nil, err
 			}
-			entries = append(entries, secondaryIndexEntries...)
+			if err := predicates.FilterIndexEntries(
+				ib.colIdxMap, ib.rowVals, secondaryIndexEntries,
+			); err != nil {
+				return nil, err
+			}
+			for _, e := range secondaryIndexEntries {
+				// Rows which don't satisfy the predicate of a partial index don't
+				// have entries in it.
+				if e.Key != nil {
+					entries = append(entries, e)
+				}
+			}
 		}
 		return entries, nil
 	}
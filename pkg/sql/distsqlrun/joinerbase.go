@@ -94,6 +94,11 @@ func (jb *joinerBase) init(
 	if err := jb.onCond.init(onExpr, types, &flowCtx.EvalCtx); err != nil {
 		return err
 	}
+	if jb.joinType.outputsLeftOnly() {
+		// The ON condition applies to rows from both sides, but the output rows
+		// only contain the left columns.
+		types = leftTypes
+	}
 	return jb.out.Init(post, types, &flowCtx.EvalCtx, output)
 }
 
@@ -109,10 +114,13 @@ func otherSide(s joinSide) joinSide {
 }
 
 // renderUnmatchedRow creates a result row given an unmatched row on either
-// side. Only used for outer joins.
+// side. Only used for outer and anti joins.
 func (jb *joinerBase) renderUnmatchedRow(
 	row sqlbase.EncDatumRow, side joinSide,
 ) sqlbase.EncDatumRow {
+	if jb.joinType == leftAnti {
+		return row
+	}
 	lrow, rrow := jb.emptyLeft, jb.emptyRight
 	if side == leftSide {
 		lrow = row
@@ -133,16 +141,17 @@ func (jb *joinerBase) renderUnmatchedRow(
 
 // shouldEmitUnmatchedRow determines if we should emit am ummatched row (with
 // NULLs for the columns of the other stream). This happens in FULL OUTER joins
-// and LEFT or RIGHT OUTER joins (depending on which stream).
+// and LEFT or RIGHT OUTER joins (depending on which stream). Unmatched left rows
+// are also emitted, as they are, by LEFT ANTI joins.
 func shouldEmitUnmatchedRow(side joinSide, joinType joinType) bool {
 	switch joinType {
-	case innerJoin:
+	case innerJoin, leftSemi:
 		return false
 	case rightOuter:
 		if side == leftSide {
 			return false
 		}
-	case leftOuter:
+	case leftOuter, leftAnti:
 		if side == rightSide {
 			return false
 		}
@@ -20,9 +20,12 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
@@ -44,9 +47,39 @@ type joinReader struct {
 
 	input      RowSource
 	inputTypes []sqlbase.ColumnType
+
+	// The fields below are only used for lookup joins.
+
+	// lookupCols are the input columns whose values are matched against the
+	// first len(lookupCols) columns of the index.
+	lookupCols columns
+	// lookupTypes are the types of the index columns matched against
+	// lookupCols, and lookupTableCols are their ordinals in the table.
+	lookupTypes     []sqlbase.ColumnType
+	lookupTableCols []int
+
+	joinType joinType
+	onCond   exprHelper
+
+	// primaryFetcher is set when the lookup index is a secondary index that
+	// doesn't contain all the needed columns; the matching rows are then
+	// retrieved from the primary index (index join).
+	primaryFetcher *sqlbase.RowFetcher
+	// primaryKeyTypes and primaryKeyCols describe the primary key columns.
+	primaryKeyTypes []sqlbase.ColumnType
+	primaryKeyCols  []int
+
+	emptyRight  sqlbase.EncDatumRow
+	combinedRow sqlbase.EncDatumRow
+	rowAlloc    sqlbase.EncDatumRowAlloc
+
+	// memAcc accounts for the input rows of the current batch and their
+	// matches, which are all held in memory until the batch is emitted.
+	memAcc mon.BoundAccount
 }
 
 var _ Processor = &joinReader{}
+var _ kvBatchCounter = &joinReader{}
 
 func newJoinReader(
 	flowCtx *FlowCtx,
@@ -55,9 +88,8 @@ func newJoinReader(
 	post *PostProcessSpec,
 	output RowReceiver,
 ) (*joinReader, error) {
-	if spec.IndexIdx != 0 {
-		// TODO(radu): for now we only support joining with the primary index
-		return nil, errors.Errorf("join with index not implemented")
+	if len(spec.LookupColumns) == 0 && spec.IndexIdx != 0 {
+		return nil, errors.Errorf("index join with secondary index not supported")
 	}
 
 	jr := &joinReader{
@@ -65,29 +97,137 @@ func newJoinReader(
 		desc:       spec.Table,
 		input:      input,
 		inputTypes: input.Types(),
+		lookupCols: columns(spec.LookupColumns),
 	}
 
-	types := make([]sqlbase.ColumnType, len(spec.Table.Columns))
-	for i := range types {
-		types[i] = spec.Table.Columns[i].Type
+	tableTypes := make([]sqlbase.ColumnType, len(spec.Table.Columns))
+	for i := range tableTypes {
+		tableTypes[i] = spec.Table.Columns[i].Type
 	}
 
-	if err := jr.out.Init(post, types, &flowCtx.EvalCtx, output); err != nil {
-		return nil, err
+	if !jr.isLookupJoin() {
+		if err := jr.out.Init(post, tableTypes, &flowCtx.EvalCtx, output); err != nil {
+			return nil, err
+		}
+
+		var err error
+		jr.index, _, err = initRowFetcher(
+			&jr.fetcher, &jr.desc, int(spec.IndexIdx), false, /* reverse */
+			sqlbase.ScanLocking{}, jr.out.neededColumns(), &jr.alloc,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// TODO(radu): verify the input types match the index key types
+
+		return jr, nil
 	}
 
-	var err error
-	jr.index, _, err = initRowFetcher(
-		&jr.fetcher, &jr.desc, int(spec.IndexIdx), false, /* reverse */
-		jr.out.neededColumns(), &jr.alloc,
-	)
-	if err != nil {
+	if err := jr.initLookupJoin(spec, tableTypes, post, output); err != nil {
 		return nil, err
 	}
+	return jr, nil
+}
 
-	// TODO(radu): verify the input types match the index key types
+func (jr *joinReader) isLookupJoin() bool {
+	return len(jr.lookupCols) > 0
+}
 
-	return jr, nil
+// initLookupJoin sets up the join reader for a lookup join.
+func (jr *joinReader) initLookupJoin(
+	spec *JoinReaderSpec, tableTypes []sqlbase.ColumnType, post *PostProcessSpec, output RowReceiver,
+) error {
+	jr.joinType = joinType(spec.Type)
+	if jr.joinType != innerJoin && jr.joinType != leftOuter {
+		return errors.Errorf("lookup join of type %s not supported", spec.Type)
+	}
+
+	types := make([]sqlbase.ColumnType, 0, len(jr.inputTypes)+len(tableTypes))
+	types = append(types, jr.inputTypes...)
+	types = append(types, tableTypes...)
+	if err := jr.onCond.init(spec.OnExpr, types, &jr.flowCtx.EvalCtx); err != nil {
+		return err
+	}
+	if err := jr.out.Init(post, types, &jr.flowCtx.EvalCtx, output); err != nil {
+		return err
+	}
+
+	jr.emptyRight = make(sqlbase.EncDatumRow, len(tableTypes))
+	for i := range jr.emptyRight {
+		jr.emptyRight[i] = sqlbase.DatumToEncDatum(tableTypes[i], tree.DNull)
+	}
+	jr.combinedRow = make(sqlbase.EncDatumRow, 0, len(types))
+	jr.memAcc = jr.flowCtx.EvalCtx.Mon.MakeBoundAccount()
+
+	if spec.IndexIdx > uint32(len(jr.desc.Indexes)) {
+		return errors.Errorf("invalid indexIdx %d", spec.IndexIdx)
+	}
+	jr.index = &jr.desc.PrimaryIndex
+	if spec.IndexIdx > 0 {
+		jr.index = &jr.desc.Indexes[spec.IndexIdx-1]
+	}
+	if len(jr.lookupCols) > len(jr.index.ColumnIDs) {
+		return errors.Errorf("%d lookup columns, but index %s has %d columns",
+			len(jr.lookupCols), jr.index.Name, len(jr.index.ColumnIDs))
+	}
+
+	colIdxMap := make(map[sqlbase.ColumnID]int, len(jr.desc.Columns))
+	for i, c := range jr.desc.Columns {
+		colIdxMap[c.ID] = i
+	}
+
+	// Determine the table columns we need: those used by the post-processing
+	// stage or by the ON expression, and the lookup columns.
+	needed := jr.out.neededColumns()[len(jr.inputTypes):]
+	for i := range needed {
+		if !needed[i] && jr.onCond.expr != nil {
+			needed[i] = jr.onCond.vars.IndexedVarUsed(len(jr.inputTypes) + i)
+		}
+	}
+	jr.lookupTypes = make([]sqlbase.ColumnType, len(jr.lookupCols))
+	jr.lookupTableCols = make([]int, len(jr.lookupCols))
+	for i := range jr.lookupCols {
+		colIdx := colIdxMap[jr.index.ColumnIDs[i]]
+		jr.lookupTypes[i] = tableTypes[colIdx]
+		jr.lookupTableCols[i] = colIdx
+		needed[colIdx] = true
+	}
+
+	indexNeeded := needed
+	if jr.index != &jr.desc.PrimaryIndex {
+		for i := range needed {
+			if needed[i] && !jr.index.ContainsColumnID(jr.desc.Columns[i].ID) {
+				jr.primaryFetcher = &sqlbase.RowFetcher{}
+				break
+			}
+		}
+	}
+	if jr.primaryFetcher != nil {
+		// The lookup index only needs to provide the primary key of the
+		// matching rows.
+		indexNeeded = make([]bool, len(needed))
+		jr.primaryKeyTypes = make([]sqlbase.ColumnType, len(jr.desc.PrimaryIndex.ColumnIDs))
+		jr.primaryKeyCols = make([]int, len(jr.desc.PrimaryIndex.ColumnIDs))
+		for i, colID := range jr.desc.PrimaryIndex.ColumnIDs {
+			colIdx := colIdxMap[colID]
+			jr.primaryKeyTypes[i] = tableTypes[colIdx]
+			jr.primaryKeyCols[i] = colIdx
+			indexNeeded[colIdx] = true
+		}
+		if _, _, err := initRowFetcher(
+			jr.primaryFetcher, &jr.desc, 0 /* indexIdx */, false, /* reverse */
+			sqlbase.ScanLocking{}, needed, &jr.alloc,
+		); err != nil {
+			return err
+		}
+	}
+
+	_, _, err := initRowFetcher(
+		&jr.fetcher, &jr.desc, int(spec.IndexIdx), false, /* reverse */
+		sqlbase.ScanLocking{}, indexNeeded, &jr.alloc,
+	)
+	return err
 }
 
 func (jr *joinReader) generateKey(
@@ -106,6 +246,216 @@ func (jr *joinReader) generateKey(
 	return sqlbase.MakeKeyFromEncDatums(types, row, &jr.desc, index, primaryKeyPrefix, alloc)
 }
 
+// makeLookupKey returns the key prefix of the index entries whose lookup
+// columns match the given values. It returns nil if any of the values is NULL,
+// as NULLs never match.
+func (jr *joinReader) makeLookupKey(
+	values sqlbase.EncDatumRow, keyPrefix []byte,
+) (roachpb.Key, error) {
+	for i := range values {
+		if values[i].IsNull() {
+			return nil, nil
+		}
+	}
+	return sqlbase.MakeKeyFromEncDatums(jr.lookupTypes, values, &jr.desc, jr.index, keyPrefix, &jr.alloc)
+}
+
+// lookupBatch retrieves the table rows that match each of the given input
+// rows on the lookup columns. The i-th element of the result contains the
+// matches for the i-th input row.
+func (jr *joinReader) lookupBatch(
+	ctx context.Context, txn *client.Txn, inputRows sqlbase.EncDatumRows,
+) ([]sqlbase.EncDatumRows, error) {
+	keyPrefix := sqlbase.MakeIndexKeyPrefix(&jr.desc, jr.index.ID)
+	matches := make([]sqlbase.EncDatumRows, len(inputRows))
+
+	// rowsByKey maps each lookup key to the input rows with that key.
+	rowsByKey := make(map[string][]int, len(inputRows))
+	spans := make(roachpb.Spans, 0, len(inputRows))
+	values := make(sqlbase.EncDatumRow, len(jr.lookupCols))
+	for i, row := range inputRows {
+		for j, c := range jr.lookupCols {
+			values[j] = row[c]
+		}
+		key, err := jr.makeLookupKey(values, keyPrefix)
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			continue
+		}
+		if _, ok := rowsByKey[string(key)]; !ok {
+			spans = append(spans, roachpb.Span{Key: key, EndKey: key.PrefixEnd()})
+		}
+		rowsByKey[string(key)] = append(rowsByKey[string(key)], i)
+	}
+	if len(spans) == 0 {
+		return matches, nil
+	}
+
+	// TODO(radu,andrei,knz): set the traceKV flag when requested by the session.
+	if err := jr.fetcher.StartScan(
+		ctx, txn, spans, false /* no batch limits */, 0, false, /* traceKV */
+	); err != nil {
+		return nil, err
+	}
+	fetcher := &jr.fetcher
+
+	if jr.primaryFetcher != nil {
+		// Retrieve the primary keys of the matching rows and look them up in the
+		// primary index.
+		primaryKeyPrefix := sqlbase.MakeIndexKeyPrefix(&jr.desc, jr.desc.PrimaryIndex.ID)
+		pkValues := make(sqlbase.EncDatumRow, len(jr.primaryKeyCols))
+		seen := make(map[string]struct{})
+		spans = spans[:0]
+		for {
+			row, err := jr.fetcher.NextRow(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if row == nil {
+				break
+			}
+			for j, c := range jr.primaryKeyCols {
+				pkValues[j] = row[c]
+			}
+			key, err := sqlbase.MakeKeyFromEncDatums(
+				jr.primaryKeyTypes, pkValues, &jr.desc, &jr.desc.PrimaryIndex, primaryKeyPrefix, &jr.alloc,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := seen[string(key)]; ok {
+				continue
+			}
+			seen[string(key)] = struct{}{}
+			spans = append(spans, roachpb.Span{Key: key, EndKey: key.PrefixEnd()})
+		}
+		if len(spans) == 0 {
+			return matches, nil
+		}
+		if err := jr.primaryFetcher.StartScan(
+			ctx, txn, spans, false /* no batch limits */, 0, false, /* traceKV */
+		); err != nil {
+			return nil, err
+		}
+		fetcher = jr.primaryFetcher
+	}
+
+	for {
+		row, err := fetcher.NextRow(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			break
+		}
+		for j, c := range jr.lookupTableCols {
+			values[j] = row[c]
+		}
+		key, err := jr.makeLookupKey(values, keyPrefix)
+		if err != nil {
+			return nil, err
+		}
+		rows := rowsByKey[string(key)]
+		if len(rows) == 0 {
+			continue
+		}
+		// The fetcher's row is only valid until the next call.
+		if err := jr.memAcc.Grow(ctx, int64(row.Size())); err != nil {
+			return nil, err
+		}
+		row = jr.rowAlloc.CopyRow(row)
+		for _, i := range rows {
+			matches[i] = append(matches[i], row)
+		}
+	}
+	return matches, nil
+}
+
+// renderLookupRow constructs a row from an input row and a table row. The ON
+// condition is evaluated; if it fails, returns nil.
+func (jr *joinReader) renderLookupRow(
+	inputRow, tableRow sqlbase.EncDatumRow,
+) (sqlbase.EncDatumRow, error) {
+	jr.combinedRow = append(jr.combinedRow[:0], inputRow...)
+	jr.combinedRow = append(jr.combinedRow, tableRow...)
+	if jr.onCond.expr != nil {
+		res, err := jr.onCond.evalFilter(jr.combinedRow)
+		if !res || err != nil {
+			return nil, err
+		}
+	}
+	return jr.combinedRow, nil
+}
+
+// lookupLoop is the mainLoop for lookup joins. Input rows are read in batches;
+// the matching table rows for an entire batch are retrieved at once, after
+// which the joined rows are emitted in the order of the input rows.
+func (jr *joinReader) lookupLoop(ctx context.Context, txn *client.Txn) error {
+	defer jr.memAcc.Close(ctx)
+	inputRows := make(sqlbase.EncDatumRows, 0, joinReaderBatchSize)
+	for {
+		inputRows = inputRows[:0]
+		jr.memAcc.Clear(ctx)
+		for len(inputRows) < joinReaderBatchSize {
+			row, meta := jr.input.Next()
+			if !meta.Empty() {
+				if meta.Err != nil {
+					return meta.Err
+				}
+				if !emitHelper(ctx, &jr.out, nil /* row */, meta, jr.input) {
+					return nil
+				}
+				continue
+			}
+			if row == nil {
+				break
+			}
+			if err := jr.memAcc.Grow(ctx, int64(row.Size())); err != nil {
+				return err
+			}
+			inputRows = append(inputRows, jr.rowAlloc.CopyRow(row))
+		}
+
+		matches, err := jr.lookupBatch(ctx, txn, inputRows)
+		if err != nil {
+			return err
+		}
+
+		for i, inputRow := range inputRows {
+			matched := false
+			for _, tableRow := range matches[i] {
+				row, err := jr.renderLookupRow(inputRow, tableRow)
+				if err != nil {
+					return err
+				}
+				if row == nil {
+					continue
+				}
+				matched = true
+				if !emitHelper(ctx, &jr.out, row, ProducerMetadata{}, jr.input) {
+					return nil
+				}
+			}
+			if !matched && jr.joinType == leftOuter {
+				jr.combinedRow = append(jr.combinedRow[:0], inputRow...)
+				jr.combinedRow = append(jr.combinedRow, jr.emptyRight...)
+				if !emitHelper(ctx, &jr.out, jr.combinedRow, ProducerMetadata{}, jr.input) {
+					return nil
+				}
+			}
+		}
+
+		if len(inputRows) != joinReaderBatchSize {
+			// This was the last batch.
+			sendTraceData(ctx, jr.out.output)
+			jr.out.Close()
+			return nil
+		}
+	}
+}
+
 // mainLoop runs the mainLoop and returns any error.
 //
 // If no error is returned, the input has been drained and the output has been
@@ -128,6 +478,10 @@ func (jr *joinReader) mainLoop(ctx context.Context) error {
 		defer log.Infof(ctx, "exiting")
 	}
 
+	if jr.isLookupJoin() {
+		return jr.lookupLoop(ctx, txn)
+	}
+
 	for {
 		// TODO(radu): figure out how to send smaller batches if the source has
 		// a soft limit (perhaps send the batch out if we don't get a result
@@ -214,3 +568,12 @@ func (jr *joinReader) Run(ctx context.Context, wg *sync.WaitGroup) {
 		DrainAndClose(ctx, jr.out.output, err /* cause */, jr.input)
 	}
 }
+
+// kvBatches is part of the kvBatchCounter interface.
+func (jr *joinReader) kvBatches() int64 {
+	n := jr.fetcher.KVBatches()
+	if jr.primaryFetcher != nil {
+		n += jr.primaryFetcher.KVBatches()
+	}
+	return n
+}
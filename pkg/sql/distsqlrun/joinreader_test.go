@@ -16,6 +16,7 @@ package distsqlrun
 
 import (
 	"errors"
+	"math"
 	"testing"
 
 	"golang.org/x/net/context"
@@ -25,9 +26,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 )
 
 func TestJoinReader(t *testing.T) {
@@ -59,7 +62,10 @@ func TestJoinReader(t *testing.T) {
 
 	td := sqlbase.GetTableDescriptor(kvDB, "test", "t")
 
+	intIntStrType := []sqlbase.ColumnType{intType, intType, strType}
+
 	testCases := []struct {
+		spec        JoinReaderSpec
 		post        PostProcessSpec
 		input       [][]tree.Datum
 		outputTypes []sqlbase.ColumnType
@@ -98,6 +104,64 @@ func TestJoinReader(t *testing.T) {
 			outputTypes: []sqlbase.ColumnType{strType},
 			expected:    "[['one'] ['five'] ['two-one'] ['one-three'] ['five-zero']]",
 		},
+		{
+			// Lookup join on the secondary index bs, which contains all the needed
+			// columns.
+			spec: JoinReaderSpec{
+				IndexIdx:      1,
+				LookupColumns: []uint32{1},
+				OnExpr:        Expression{Expr: "@1 = @3"},
+			},
+			post: PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1, 5},
+			},
+			input: [][]tree.Datum{
+				{aFn(2), bFn(2)},
+				{aFn(15), bFn(15)},
+			},
+			outputTypes: intIntStrType,
+			expected:    "[[0 2 'two'] [1 5 'one-five']]",
+		},
+		{
+			// Lookup join on the secondary index bs, which doesn't contain the sum
+			// column; the rows are retrieved from the primary index.
+			spec: JoinReaderSpec{
+				IndexIdx:      1,
+				LookupColumns: []uint32{1},
+				OnExpr:        Expression{Expr: "@1 = @3"},
+			},
+			post: PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1, 4},
+			},
+			input: [][]tree.Datum{
+				{aFn(2), bFn(2)},
+				{aFn(15), bFn(15)},
+			},
+			outputTypes: threeIntCols,
+			expected:    "[[0 2 2] [1 5 6]]",
+		},
+		{
+			// Left outer lookup join on a prefix of the primary index.
+			spec: JoinReaderSpec{
+				LookupColumns: []uint32{0},
+				OnExpr:        Expression{Expr: "@2 = @4"},
+				Type:          JoinType_LEFT_OUTER,
+			},
+			post: PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1, 5},
+			},
+			input: [][]tree.Datum{
+				{aFn(2), bFn(2)},
+				{tree.NewDInt(20), bFn(5)},
+				{tree.DNull, bFn(7)},
+				{aFn(34), bFn(34)},
+			},
+			outputTypes: intIntStrType,
+			expected:    "[[0 2 'two'] [20 5 NULL] [NULL 7 NULL] [3 4 'three-four']]",
+		},
 	}
 	for _, c := range testCases {
 		t.Run("", func(t *testing.T) {
@@ -121,7 +185,9 @@ func TestJoinReader(t *testing.T) {
 			in := NewRowBuffer(twoIntCols, encRows, RowBufferArgs{})
 
 			out := &RowBuffer{}
-			jr, err := newJoinReader(&flowCtx, &JoinReaderSpec{Table: *td}, in, &c.post, out)
+			spec := c.spec
+			spec.Table = *td
+			jr, err := newJoinReader(&flowCtx, &spec, in, &c.post, out)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -151,6 +217,71 @@ func TestJoinReader(t *testing.T) {
 	}
 }
 
+// TestJoinReaderLookupMemoryLimit verifies that the rows buffered by a lookup
+// join are accounted for.
+func TestJoinReaderLookupMemoryLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlutils.CreateTable(t, sqlDB, "t",
+		"a INT, b INT, PRIMARY KEY (a, b)",
+		99,
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, sqlutils.RowModuloFn(10)))
+	td := sqlbase.GetTableDescriptor(kvDB, "test", "t")
+
+	ctx := context.Background()
+	evalCtx := tree.MakeTestingEvalContext()
+	defer evalCtx.Mon.Stop(ctx)
+	// Make a monitor with no capacity.
+	monitor := mon.MakeMonitor(
+		"test-lookup-join",
+		mon.MemoryResource,
+		nil,           /* curCount */
+		nil,           /* maxHist */
+		1,             /* increment */
+		math.MaxInt64, /* noteworthy */
+	)
+	monitor.Start(ctx, nil, mon.MakeStandaloneBudget(0 /* capacity */))
+	defer monitor.Stop(ctx)
+	evalCtx.Mon = &monitor
+
+	flowCtx := FlowCtx{
+		EvalCtx:  evalCtx,
+		Settings: cluster.MakeTestingClusterSettings(),
+		// Pass a DB without a TxnCoordSender.
+		txn: client.NewTxn(client.NewDB(s.DistSender(), s.Clock()), s.NodeID()),
+	}
+
+	encRow := sqlbase.EncDatumRow{sqlbase.DatumToEncDatum(intType, tree.NewDInt(1))}
+	in := NewRowBuffer(oneIntCol, sqlbase.EncDatumRows{encRow}, RowBufferArgs{})
+	out := &RowBuffer{}
+	jr, err := newJoinReader(
+		&flowCtx, &JoinReaderSpec{Table: *td, LookupColumns: []uint32{0}}, in, &PostProcessSpec{}, out,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jr.Run(ctx, nil)
+
+	for {
+		row, meta := out.Next()
+		if row != nil {
+			t.Fatalf("row was pushed unexpectedly: %s", row.String(twoIntCols))
+		}
+		if meta.Empty() {
+			t.Fatal("expected a memory budget error")
+		}
+		if meta.Err != nil {
+			if !testutils.IsError(meta.Err, "memory budget exceeded") {
+				t.Fatalf("unexpected error: %v", meta.Err)
+			}
+			break
+		}
+	}
+}
+
 // TestJoinReaderDrain tests various scenarios in which a joinReader's consumer
 // is closed.
 func TestJoinReaderDrain(t *testing.T) {
@@ -0,0 +1,124 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// materializer is the processor which runs a tree of vectorized operators and
+// turns the batches they produce back into rows. The part of the
+// post-processing which couldn't be vectorized is done by its
+// ProcOutputHelper.
+type materializer struct {
+	processorBase
+
+	flowCtx *FlowCtx
+	name    string
+
+	input exec.Operator
+	// types are the types of the columns of the batches produced by input.
+	types []sqlbase.ColumnType
+	// inputs are the row sources consumed through columnarizers; they are
+	// drained when the materializer is done.
+	inputs []RowSource
+	// scan is set if the operator tree reads from a table.
+	scan *colTableScan
+
+	row   sqlbase.EncDatumRow
+	alloc sqlbase.DatumAlloc
+}
+
+var _ Processor = &materializer{}
+var _ kvBatchCounter = &materializer{}
+
+// Run is part of the processor interface.
+func (m *materializer) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	ctx, span := processorSpan(ctx, m.name)
+	defer tracing.FinishSpan(span)
+
+	log.VEventf(ctx, 1, "starting vectorized %s", m.name)
+	if log.V(1) {
+		defer log.Infof(ctx, "exiting vectorized %s", m.name)
+	}
+
+	if m.scan != nil {
+		if m.flowCtx.txn == nil {
+			log.Fatalf(ctx, "vectorized %s outside of txn", m.name)
+		}
+		m.scan.ctx = ctx
+	}
+
+	m.row = make(sqlbase.EncDatumRow, len(m.types))
+	status := NeedMoreRows
+	err := exec.CatchError(func() {
+		m.input.Init()
+		for status == NeedMoreRows {
+			batch := m.input.Next()
+			n := batch.Length()
+			if n == 0 {
+				return
+			}
+			sel := batch.Selection()
+			for k := uint16(0); k < n && status == NeedMoreRows; k++ {
+				i := k
+				if sel != nil {
+					i = sel[k]
+				}
+				for c := range m.row {
+					m.row[c] = sqlbase.DatumToEncDatum(
+						m.types[c], vecDatum(batch.ColVec(c), i, &m.types[c], &m.alloc),
+					)
+				}
+				var err error
+				if status, err = m.out.EmitRow(ctx, m.row); err != nil {
+					exec.RaiseError(err)
+				}
+			}
+		}
+	})
+
+	if err == nil && status == ConsumerClosed {
+		log.VEventf(ctx, 1, "no more rows required")
+		for _, input := range m.inputs {
+			input.ConsumerClosed()
+		}
+		m.out.Close()
+		return
+	}
+	if err == nil && m.scan != nil {
+		m.scan.sendMisplannedRangesMetadata(ctx, m.out.output)
+	}
+	DrainAndClose(ctx, m.out.output, err, m.inputs...)
+}
+
+// kvBatches is part of the kvBatchCounter interface.
+func (m *materializer) kvBatches() int64 {
+	if m.scan == nil {
+		return 0
+	}
+	return m.scan.fetcher.KVBatches()
+}
@@ -130,6 +130,17 @@ func (m *mergeJoiner) outputBatch(
 			}
 			if renderedRow != nil {
 				matched = true
+				if m.joinType.outputsLeftOnly() {
+					// A semi join outputs the left row on its first match; an anti
+					// join doesn't output it at all.
+					if m.joinType == leftSemi {
+						consumerStatus, err := m.out.EmitRow(ctx, lrow)
+						if err != nil || consumerStatus != NeedMoreRows {
+							return false, err
+						}
+					}
+					break
+				}
 				if matchedRight != nil {
 					matchedRight[rIdx] = true
 				}
@@ -348,6 +348,78 @@ func TestMergeJoiner(t *testing.T) {
 				{null, v[5], v[1]},
 			},
 		},
+		{
+			spec: MergeJoinerSpec{
+				LeftOrdering: convertToSpecOrdering(
+					sqlbase.ColumnOrdering{
+						{ColIdx: 0, Direction: encoding.Ascending},
+					}),
+				RightOrdering: convertToSpecOrdering(
+					sqlbase.ColumnOrdering{
+						{ColIdx: 0, Direction: encoding.Ascending},
+					}),
+				Type:   JoinType_LEFT_SEMI,
+				OnExpr: Expression{Expr: "@4 > 1"},
+				// Implicit @1 = @3 constraint.
+			},
+			outCols:   []uint32{0, 1},
+			leftTypes: twoIntCols,
+			leftInput: sqlbase.EncDatumRows{
+				{v[0], v[0]},
+				{v[1], v[1]},
+				{v[2], v[2]},
+				{v[3], v[3]},
+			},
+			rightTypes: twoIntCols,
+			rightInput: sqlbase.EncDatumRows{
+				{v[0], v[1]},
+				{v[1], v[2]},
+				{v[1], v[3]},
+				{v[3], v[0]},
+				{v[3], v[4]},
+				{v[4], v[5]},
+			},
+			expected: sqlbase.EncDatumRows{
+				{v[1], v[1]},
+				{v[3], v[3]},
+			},
+		},
+		{
+			spec: MergeJoinerSpec{
+				LeftOrdering: convertToSpecOrdering(
+					sqlbase.ColumnOrdering{
+						{ColIdx: 0, Direction: encoding.Ascending},
+					}),
+				RightOrdering: convertToSpecOrdering(
+					sqlbase.ColumnOrdering{
+						{ColIdx: 0, Direction: encoding.Ascending},
+					}),
+				Type:   JoinType_LEFT_ANTI,
+				OnExpr: Expression{Expr: "@4 > 1"},
+				// Implicit @1 = @3 constraint.
+			},
+			outCols:   []uint32{0, 1},
+			leftTypes: twoIntCols,
+			leftInput: sqlbase.EncDatumRows{
+				{v[0], v[0]},
+				{v[1], v[1]},
+				{v[2], v[2]},
+				{v[3], v[3]},
+			},
+			rightTypes: twoIntCols,
+			rightInput: sqlbase.EncDatumRows{
+				{v[0], v[1]},
+				{v[1], v[2]},
+				{v[1], v[3]},
+				{v[3], v[0]},
+				{v[3], v[4]},
+				{v[4], v[5]},
+			},
+			expected: sqlbase.EncDatumRows{
+				{v[0], v[0]},
+				{v[2], v[2]},
+			},
+		},
 	}
 
 	for _, c := range testCases {
@@ -72,6 +72,11 @@ type ProcOutputHelper struct {
 	maxRowIdx uint64
 
 	rowIdx uint64
+
+	// onFilterPassed, if set, is called for each row that passes the filter,
+	// before the offset and the limit are applied. It is used by table readers
+	// to lock the rows they output (see TableReaderSpec.lock_for_update).
+	onFilterPassed func(context.Context) error
 }
 
 // Init sets up a ProcOutputHelper. The types describe the internal schema of
@@ -266,6 +271,11 @@ func (h *ProcOutputHelper) EmitRow(
 			return NeedMoreRows, nil
 		}
 	}
+	if h.onFilterPassed != nil {
+		if err := h.onFilterPassed(ctx); err != nil {
+			return ConsumerClosed, err
+		}
+	}
 	h.rowIdx++
 	if h.rowIdx <= h.offset {
 		// Suppress row.
@@ -384,6 +394,14 @@ func newProcessor(
 	inputs []RowSource,
 	outputs []RowReceiver,
 ) (Processor, error) {
+	if flowCtx.EvalCtx.Vectorize && len(outputs) == 1 {
+		p, err := newVectorizedProcessor(flowCtx, core, post, inputs, outputs[0])
+		if err == nil {
+			return p, nil
+		}
+		log.VEventf(flowCtx.AnnotateCtx(context.TODO()), 1,
+			"falling back to row-based execution: %s", err)
+	}
 	if core.Noop != nil {
 		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
 			return nil, err
@@ -408,6 +426,12 @@ func newProcessor(
 		}
 		return newJoinReader(flowCtx, core.JoinReader, inputs[0], post, outputs[0])
 	}
+	if core.TableWriter != nil {
+		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
+			return nil, err
+		}
+		return newTableWriter(flowCtx, core.TableWriter, inputs[0], post, outputs[0])
+	}
 	if core.Sorter != nil {
 		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
 			return nil, err
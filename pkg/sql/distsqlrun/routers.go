@@ -195,7 +195,11 @@ func (rb *routerBase) init(flowCtx *FlowCtx, types []sqlbase.ColumnType) {
 		// to take the mutex.
 		rb.outputs[i].mu.rowContainer.init(nil /* ordering */, types, &flowCtx.EvalCtx)
 		// Initialize any outboxes.
-		if o, ok := rb.outputs[i].stream.(*outbox); ok {
+		stream := rb.outputs[i].stream
+		if s, ok := stream.(*streamStatsCollector); ok {
+			stream = s.RowReceiver
+		}
+		if o, ok := stream.(*outbox); ok {
 			o.init(types)
 		}
 	}
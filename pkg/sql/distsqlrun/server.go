@@ -30,7 +30,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -71,7 +70,7 @@ type DistSQLVersion uint32
 //
 // ATTENTION: When updating these fields, add to version_history.txt explaining
 // what changed.
-const Version DistSQLVersion = 7
+const Version DistSQLVersion = 10
 
 // MinAcceptedVersion is the oldest version that the server is
 // compatible with; see above.
@@ -89,6 +88,18 @@ var settingUseTempStorageJoins = settings.RegisterBoolSetting(
 	true,
 )
 
+var settingUseTempStorageAggregations = settings.RegisterBoolSetting(
+	"sql.distsql.temp_storage.aggregations",
+	"set to true to enable use of disk for distributed sql aggregations",
+	true,
+)
+
+var settingUseTempStorageDistinct = settings.RegisterBoolSetting(
+	"sql.distsql.temp_storage.distinct",
+	"set to true to enable use of disk for distributed sql distinct",
+	true,
+)
+
 var settingWorkMemBytes = settings.RegisterByteSizeSetting(
 	"sql.distsql.temp_storage.workmem",
 	"maximum amount of memory in bytes a processor can use before falling back to temp storage",
@@ -251,7 +262,7 @@ func (ds *ServerImpl) setupFlow(
 	// by the TxnCoordSender.
 	txn.AcceptUnhandledRetryableErrors()
 
-	location, err := sqlbase.TimeZoneStringToLocation(req.EvalContext.Location)
+	location, err := timeutil.TimeZoneStringToLocation(req.EvalContext.Location)
 	if err != nil {
 		tracing.FinishSpan(sp)
 		return ctx, nil, err
@@ -261,6 +272,7 @@ func (ds *ServerImpl) setupFlow(
 		Database:     req.EvalContext.Database,
 		User:         req.EvalContext.User,
 		SearchPath:   tree.MakeSearchPath(req.EvalContext.SearchPath),
+		Vectorize:    req.EvalContext.Vectorize,
 		ClusterID:    ds.ServerConfig.ClusterID,
 		NodeID:       nodeID,
 		ReCache:      ds.regexpCache,
@@ -291,6 +303,7 @@ func (ds *ServerImpl) setupFlow(
 		TempStorage:    ds.TempStorage,
 		diskMonitor:    ds.DiskMonitor,
 		JobRegistry:    ds.ServerConfig.JobRegistry,
+		collectStats:   req.CollectStats,
 	}
 
 	ctx = flowCtx.AnnotateCtx(ctx)
@@ -0,0 +1,502 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// The execution statistics of processors and streams are sent to the gateway
+// as the tags of trace spans. A span carries either the processorIDTagKey or
+// the streamIDTagKey tag, along with one tag per statistic (prefixed with
+// statTagPrefix).
+const (
+	processorIDTagKey = "cockroach.processorid"
+	streamIDTagKey    = "cockroach.streamid"
+	statTagPrefix     = "cockroach.stat."
+
+	inputRowsStat   = "inputrows."
+	outputRowsStat  = "outputrows"
+	outputBytesStat = "outputbytes"
+	wallTimeStat    = "walltime"
+	maxMemoryStat   = "maxmemory"
+	maxDiskStat     = "maxdisk"
+	kvBatchesStat   = "kvbatches"
+	rowsStat        = "rows"
+	bytesStat       = "bytes"
+)
+
+// ProcessorStats are the execution statistics of a processor.
+type ProcessorStats struct {
+	// InputRows contains the number of rows read from each input.
+	InputRows   []int64
+	OutputRows  int64
+	OutputBytes int64
+	// WallTime is the time elapsed between the start of the processor and the
+	// moment it finished producing its output.
+	WallTime  time.Duration
+	MaxMemory int64
+	MaxDisk   int64
+	// KVBatches is the number of KV batches sent by the processor; it is only
+	// set for processors which read from the KV layer.
+	KVBatches int64
+}
+
+// StreamStats are the execution statistics of a stream, as seen by its
+// producer.
+type StreamStats struct {
+	Rows  int64
+	Bytes int64
+}
+
+// StatField is a statistic formatted for display.
+type StatField struct {
+	Name  string
+	Value string
+}
+
+// Fields returns the statistics formatted for display.
+func (s *ProcessorStats) Fields() []StatField {
+	var res []StatField
+	for i, rows := range s.InputRows {
+		name := "rows in"
+		if len(s.InputRows) > 1 {
+			name = fmt.Sprintf("rows in (input %d)", i)
+		}
+		res = append(res, StatField{Name: name, Value: strconv.FormatInt(rows, 10)})
+	}
+	res = append(res,
+		StatField{Name: "rows out", Value: strconv.FormatInt(s.OutputRows, 10)},
+		StatField{Name: "bytes out", Value: humanizeutil.IBytes(s.OutputBytes)},
+		StatField{Name: "wall time", Value: s.WallTime.String()},
+		StatField{Name: "max memory", Value: humanizeutil.IBytes(s.MaxMemory)},
+	)
+	if s.MaxDisk > 0 {
+		res = append(res, StatField{Name: "max disk", Value: humanizeutil.IBytes(s.MaxDisk)})
+	}
+	if s.KVBatches > 0 {
+		res = append(res, StatField{Name: "kv batches", Value: strconv.FormatInt(s.KVBatches, 10)})
+	}
+	return res
+}
+
+// Fields returns the statistics formatted for display.
+func (s *StreamStats) Fields() []StatField {
+	return []StatField{
+		{Name: "rows", Value: strconv.FormatInt(s.Rows, 10)},
+		{Name: "bytes", Value: humanizeutil.IBytes(s.Bytes)},
+	}
+}
+
+func (s *ProcessorStats) tags() map[string]interface{} {
+	tags := map[string]interface{}{
+		statTagPrefix + outputRowsStat:  s.OutputRows,
+		statTagPrefix + outputBytesStat: s.OutputBytes,
+		statTagPrefix + wallTimeStat:    int64(s.WallTime),
+		statTagPrefix + maxMemoryStat:   s.MaxMemory,
+		statTagPrefix + maxDiskStat:     s.MaxDisk,
+		statTagPrefix + kvBatchesStat:   s.KVBatches,
+	}
+	for i, rows := range s.InputRows {
+		tags[statTagPrefix+inputRowsStat+strconv.Itoa(i)] = rows
+	}
+	return tags
+}
+
+func (s *StreamStats) tags() map[string]interface{} {
+	return map[string]interface{}{
+		statTagPrefix + rowsStat:  s.Rows,
+		statTagPrefix + bytesStat: s.Bytes,
+	}
+}
+
+// FlowStats are the execution statistics of the processors and streams of a
+// plan, indexed by processor ID and stream ID.
+type FlowStats struct {
+	Processors map[int32]*ProcessorStats
+	Streams    map[StreamID]*StreamStats
+}
+
+// ExtractFlowStats extracts the execution statistics from the recording of the
+// trace of a plan whose flows were set up with CollectStats.
+func ExtractFlowStats(rec []tracing.RecordedSpan) (FlowStats, error) {
+	res := FlowStats{
+		Processors: make(map[int32]*ProcessorStats),
+		Streams:    make(map[StreamID]*StreamStats),
+	}
+	for _, sp := range rec {
+		if id, ok := sp.Tags[processorIDTagKey]; ok {
+			procID, err := strconv.Atoi(id)
+			if err != nil {
+				return FlowStats{}, errors.Wrapf(err, "invalid processor ID %q", id)
+			}
+			s := &ProcessorStats{}
+			for k, v := range sp.Tags {
+				if !strings.HasPrefix(k, statTagPrefix) {
+					continue
+				}
+				val, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return FlowStats{}, errors.Wrapf(err, "invalid value for %s", k)
+				}
+				switch stat := strings.TrimPrefix(k, statTagPrefix); stat {
+				case outputRowsStat:
+					s.OutputRows = val
+				case outputBytesStat:
+					s.OutputBytes = val
+				case wallTimeStat:
+					s.WallTime = time.Duration(val)
+				case maxMemoryStat:
+					s.MaxMemory = val
+				case maxDiskStat:
+					s.MaxDisk = val
+				case kvBatchesStat:
+					s.KVBatches = val
+				default:
+					if !strings.HasPrefix(stat, inputRowsStat) {
+						continue
+					}
+					i, err := strconv.Atoi(strings.TrimPrefix(stat, inputRowsStat))
+					if err != nil {
+						return FlowStats{}, errors.Wrapf(err, "invalid stat %s", k)
+					}
+					for len(s.InputRows) <= i {
+						s.InputRows = append(s.InputRows, 0)
+					}
+					s.InputRows[i] = val
+				}
+			}
+			res.Processors[int32(procID)] = s
+		} else if id, ok := sp.Tags[streamIDTagKey]; ok {
+			streamID, err := strconv.Atoi(id)
+			if err != nil {
+				return FlowStats{}, errors.Wrapf(err, "invalid stream ID %q", id)
+			}
+			s := &StreamStats{}
+			for k, v := range sp.Tags {
+				if !strings.HasPrefix(k, statTagPrefix) {
+					continue
+				}
+				val, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return FlowStats{}, errors.Wrapf(err, "invalid value for %s", k)
+				}
+				switch strings.TrimPrefix(k, statTagPrefix) {
+				case rowsStat:
+					s.Rows = val
+				case bytesStat:
+					s.Bytes = val
+				}
+			}
+			res.Streams[StreamID(streamID)] = s
+		}
+	}
+	return res, nil
+}
+
+// FlowStatsRow is a row of the text output of EXPLAIN ANALYZE, which has the
+// layout of the output of EXPLAIN: the processors and streams of the plan form
+// a tree rooted at the processor producing the results, in which every node is
+// described by a row (with Type set) followed by a row per attribute (with
+// Field and Description set).
+type FlowStatsRow struct {
+	// Level is the depth of the node in the tree.
+	Level       int
+	Type        string
+	Field       string
+	Description string
+}
+
+// FormatFlowStats returns the tree of the processors and streams of the given
+// flows, annotated with their execution statistics. The inputs of a processor
+// are its input streams, whose children are the processors producing them.
+// A processor feeding several streams is only described in full the first
+// time it appears in the tree.
+func FormatFlowStats(flows map[roachpb.NodeID]FlowSpec, stats FlowStats) []FlowStatsRow {
+	type procInfo struct {
+		nodeID roachpb.NodeID
+		spec   *ProcessorSpec
+	}
+	procs := make(map[int32]procInfo)
+	// producers maps streams to the processor producing them.
+	producers := make(map[StreamID]int32)
+	root := int32(-1)
+	for nodeID, flow := range flows {
+		for i := range flow.Processors {
+			p := &flow.Processors[i]
+			procs[p.ProcessorID] = procInfo{nodeID: nodeID, spec: p}
+			for _, o := range p.Output {
+				for _, stream := range o.Streams {
+					if stream.Type == StreamEndpointSpec_SYNC_RESPONSE {
+						root = p.ProcessorID
+					} else {
+						producers[stream.StreamID] = p.ProcessorID
+					}
+				}
+			}
+		}
+	}
+	if root < 0 {
+		return nil
+	}
+
+	var res []FlowStatsRow
+	addNode := func(level int, typ string) {
+		res = append(res, FlowStatsRow{Level: level, Type: typ})
+	}
+	addAttr := func(level int, field, description string) {
+		res = append(res, FlowStatsRow{Level: level, Field: field, Description: description})
+	}
+
+	seen := make(map[int32]bool)
+	var formatProc func(id int32, level int)
+	formatProc = func(id int32, level int) {
+		p := procs[id]
+		title, details := p.spec.Core.GetValue().(diagramCellType).summary()
+		addNode(level, title)
+		addAttr(level, "id", strconv.Itoa(int(id)))
+		if seen[id] {
+			addAttr(level, "details", "shown above")
+			return
+		}
+		seen[id] = true
+		addAttr(level, "node", p.nodeID.String())
+		for _, d := range append(details, p.spec.Post.summary()...) {
+			addAttr(level, "details", d)
+		}
+		if s, ok := stats.Processors[id]; ok {
+			for _, f := range s.Fields() {
+				addAttr(level, f.Name, f.Value)
+			}
+		}
+		for _, in := range p.spec.Input {
+			for _, stream := range in.Streams {
+				addNode(level+1, "stream")
+				addAttr(level+1, "id", strconv.Itoa(int(stream.StreamID)))
+				if s, ok := stats.Streams[stream.StreamID]; ok {
+					for _, f := range s.Fields() {
+						addAttr(level+1, f.Name, f.Value)
+					}
+				}
+				if producer, ok := producers[stream.StreamID]; ok {
+					formatProc(producer, level+2)
+				}
+			}
+		}
+	}
+	formatProc(root, 0)
+	return res
+}
+
+// sendStats sends the given statistics to dst as the tags of a trace span, if
+// the trace of ctx is being recorded.
+func sendStats(
+	ctx context.Context, dst RowReceiver, opName string, idKey string, id int, tags map[string]interface{},
+) {
+	parentSp := opentracing.SpanFromContext(ctx)
+	if parentSp == nil || !tracing.IsRecording(parentSp) {
+		return
+	}
+	sp := tracing.StartChildSpan(opName, parentSp, true /* separateRecording */)
+	sp.SetTag(idKey, id)
+	for k, v := range tags {
+		sp.SetTag(k, v)
+	}
+	sp.Finish()
+	if rec := tracing.GetRecording(sp); rec != nil {
+		dst.Push(nil /* row */, ProducerMetadata{TraceData: rec})
+	}
+}
+
+// kvBatchCounter is implemented by the processors which read from the KV
+// layer.
+type kvBatchCounter interface {
+	// kvBatches returns the number of KV batches sent so far.
+	kvBatches() int64
+}
+
+// inputStatsCollector wraps an input of a processor and counts the rows read
+// from it.
+type inputStatsCollector struct {
+	RowSource
+	rows int64
+}
+
+var _ RowSource = &inputStatsCollector{}
+
+// Next is part of the RowSource interface.
+func (c *inputStatsCollector) Next() (sqlbase.EncDatumRow, ProducerMetadata) {
+	row, meta := c.RowSource.Next()
+	if row != nil {
+		c.rows++
+	}
+	return row, meta
+}
+
+// statsProcessor wraps a processor whose execution statistics are collected.
+// The statistics are collected by the wrappers of its inputs and output and
+// by the monitors through which it allocates memory and disk; they are sent
+// right before the processor signals that its output is done.
+type statsProcessor struct {
+	Processor
+
+	ctx     context.Context
+	start   time.Time
+	inputs  []*inputStatsCollector
+	memMon  *mon.BytesMonitor
+	diskMon *mon.BytesMonitor
+
+	processorID int32
+	stats       ProcessorStats
+}
+
+var _ Processor = &statsProcessor{}
+
+// newStatsProcessor sets up the collection of the execution statistics of a
+// processor. It returns the FlowCtx, inputs and output with which the
+// processor must be created; the processor must then be set as the wrapped
+// Processor.
+//
+// The processor gets its own memory and disk monitors (children of the flow's
+// monitors) so that its usage can be measured separately; they are stopped
+// when the flow is cleaned up.
+func (f *Flow) newStatsProcessor(
+	ctx context.Context, processorID int32, inputs []RowSource, output RowReceiver,
+) (*statsProcessor, *FlowCtx, []RowSource, RowReceiver) {
+	p := &statsProcessor{processorID: processorID}
+
+	flowCtx := new(FlowCtx)
+	*flowCtx = f.FlowCtx
+	memMon := mon.MakeMonitorInheritWithLimit(
+		fmt.Sprintf("processor-%d", processorID), math.MaxInt64, f.EvalCtx.Mon,
+	)
+	memMon.Start(ctx, f.EvalCtx.Mon, mon.BoundAccount{})
+	p.memMon = &memMon
+	flowCtx.EvalCtx.Mon = p.memMon
+	f.statsMonitors = append(f.statsMonitors, p.memMon)
+	if f.diskMonitor != nil {
+		diskMon := mon.MakeMonitorInheritWithLimit(
+			fmt.Sprintf("processor-%d-disk", processorID), math.MaxInt64, f.diskMonitor,
+		)
+		diskMon.Start(ctx, f.diskMonitor, mon.BoundAccount{})
+		p.diskMon = &diskMon
+		flowCtx.diskMonitor = p.diskMon
+		f.statsMonitors = append(f.statsMonitors, p.diskMon)
+	}
+
+	wrappedInputs := make([]RowSource, len(inputs))
+	p.inputs = make([]*inputStatsCollector, len(inputs))
+	for i, in := range inputs {
+		p.inputs[i] = &inputStatsCollector{RowSource: in}
+		wrappedInputs[i] = p.inputs[i]
+	}
+	return p, flowCtx, wrappedInputs, &processorStatsOutput{RowReceiver: output, p: p}
+}
+
+// Run is part of the Processor interface.
+func (p *statsProcessor) Run(ctx context.Context, wg *sync.WaitGroup) {
+	p.ctx = ctx
+	p.start = timeutil.Now()
+	p.Processor.Run(ctx, wg)
+}
+
+// processorStatsOutput is the RowReceiver which wraps the output of a
+// statsProcessor.
+type processorStatsOutput struct {
+	RowReceiver
+	p *statsProcessor
+}
+
+var _ RowReceiver = &processorStatsOutput{}
+
+// Push is part of the RowReceiver interface.
+func (o *processorStatsOutput) Push(row sqlbase.EncDatumRow, meta ProducerMetadata) ConsumerStatus {
+	if row != nil {
+		o.p.stats.OutputRows++
+		o.p.stats.OutputBytes += int64(row.Size())
+	}
+	return o.RowReceiver.Push(row, meta)
+}
+
+// ProducerDone is part of the RowReceiver interface.
+func (o *processorStatsOutput) ProducerDone() {
+	p := o.p
+	p.stats.WallTime = timeutil.Since(p.start)
+	p.stats.InputRows = make([]int64, len(p.inputs))
+	for i, in := range p.inputs {
+		p.stats.InputRows[i] = in.rows
+	}
+	p.stats.MaxMemory = p.memMon.MaximumBytes()
+	if p.diskMon != nil {
+		p.stats.MaxDisk = p.diskMon.MaximumBytes()
+	}
+	if c, ok := p.Processor.(kvBatchCounter); ok {
+		p.stats.KVBatches = c.kvBatches()
+	}
+	sendStats(
+		p.ctx, o.RowReceiver, "processor stats", processorIDTagKey, int(p.processorID), p.stats.tags(),
+	)
+	o.RowReceiver.ProducerDone()
+}
+
+// streamStatsCollector wraps the producer side of a stream and counts the rows
+// pushed into it. The statistics are sent through the stream right before it
+// is closed.
+type streamStatsCollector struct {
+	RowReceiver
+	flow     *Flow
+	streamID StreamID
+	stats    StreamStats
+}
+
+var _ RowReceiver = &streamStatsCollector{}
+
+// Push is part of the RowReceiver interface.
+func (c *streamStatsCollector) Push(row sqlbase.EncDatumRow, meta ProducerMetadata) ConsumerStatus {
+	if row != nil {
+		c.stats.Rows++
+		c.stats.Bytes += int64(row.Size())
+	}
+	return c.RowReceiver.Push(row, meta)
+}
+
+// ProducerDone is part of the RowReceiver interface.
+func (c *streamStatsCollector) ProducerDone() {
+	// The flow's context is only set if the flow was started (as opposed to
+	// run synchronously).
+	if c.flow.ctx != nil {
+		sendStats(
+			c.flow.ctx, c.RowReceiver, "stream stats", streamIDTagKey, int(c.streamID), c.stats.tags(),
+		)
+	}
+	c.RowReceiver.ProducerDone()
+}
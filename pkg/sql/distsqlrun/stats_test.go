@@ -0,0 +1,145 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// recordedSpan returns a RecordedSpan with the given tags, stringified the
+// way the tracer stringifies the tags of a span.
+func recordedSpan(tags map[string]interface{}) tracing.RecordedSpan {
+	sp := tracing.RecordedSpan{Tags: make(map[string]string)}
+	for k, v := range tags {
+		sp.Tags[k] = fmt.Sprint(v)
+	}
+	return sp
+}
+
+func TestExtractFlowStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	procStats := ProcessorStats{
+		InputRows:   []int64{10, 20},
+		OutputRows:  5,
+		OutputBytes: 100,
+		WallTime:    3 * time.Millisecond,
+		MaxMemory:   1024,
+		MaxDisk:     0,
+		KVBatches:   2,
+	}
+	streamStats := StreamStats{Rows: 5, Bytes: 100}
+
+	procTags := procStats.tags()
+	procTags[processorIDTagKey] = 3
+	streamTags := streamStats.tags()
+	streamTags[streamIDTagKey] = 7
+
+	rec := []tracing.RecordedSpan{
+		recordedSpan(procTags),
+		recordedSpan(streamTags),
+		// Spans without an ID tag are ignored.
+		recordedSpan(map[string]interface{}{"foo": "bar"}),
+	}
+	stats, err := ExtractFlowStats(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Processors) != 1 || len(stats.Streams) != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if s := stats.Processors[3]; s == nil || !reflect.DeepEqual(*s, procStats) {
+		t.Errorf("expected processor stats %+v, got %+v", procStats, s)
+	}
+	if s := stats.Streams[7]; s == nil || *s != streamStats {
+		t.Errorf("expected stream stats %+v, got %+v", streamStats, s)
+	}
+}
+
+func TestFormatFlowStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := &sqlbase.TableDescriptor{Name: "Table"}
+	flows := map[roachpb.NodeID]FlowSpec{
+		1: {Processors: []ProcessorSpec{{
+			ProcessorID: 1,
+			Input: []InputSyncSpec{{
+				Type:    InputSyncSpec_UNORDERED,
+				Streams: []StreamEndpointSpec{{StreamID: 0}},
+			}},
+			Core: ProcessorCoreUnion{Noop: &NoopCoreSpec{}},
+			Output: []OutputRouterSpec{{
+				Type:    OutputRouterSpec_PASS_THROUGH,
+				Streams: []StreamEndpointSpec{{Type: StreamEndpointSpec_SYNC_RESPONSE}},
+			}},
+		}}},
+		2: {Processors: []ProcessorSpec{{
+			ProcessorID: 0,
+			Core:        ProcessorCoreUnion{TableReader: &TableReaderSpec{Table: *desc}},
+			Output: []OutputRouterSpec{{
+				Type:    OutputRouterSpec_PASS_THROUGH,
+				Streams: []StreamEndpointSpec{{StreamID: 0}},
+			}},
+		}}},
+	}
+	stats := FlowStats{
+		Processors: map[int32]*ProcessorStats{
+			0: {OutputRows: 2, OutputBytes: 10, WallTime: time.Second, KVBatches: 1},
+			1: {InputRows: []int64{2}, OutputRows: 2, OutputBytes: 10, WallTime: time.Second},
+		},
+		Streams: map[StreamID]*StreamStats{
+			0: {Rows: 2, Bytes: 10},
+		},
+	}
+
+	var res []string
+	for _, r := range FormatFlowStats(flows, stats) {
+		res = append(res, fmt.Sprintf("%d %s %s=%s", r.Level, r.Type, r.Field, r.Description))
+	}
+	expected := []string{
+		"0 No-op =",
+		"0  id=1",
+		"0  node=1",
+		"0  rows in=2",
+		"0  rows out=2",
+		"0  bytes out=10 B",
+		"0  wall time=1s",
+		"0  max memory=0 B",
+		"1 stream =",
+		"1  id=0",
+		"1  rows=2",
+		"1  bytes=10 B",
+		"2 TableReader =",
+		"2  id=0",
+		"2  node=2",
+		"2  details=primary@Table",
+		"2  rows out=2",
+		"2  bytes out=10 B",
+		"2  wall time=1s",
+		"2  max memory=0 B",
+		"2  kv batches=1",
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, res)
+	}
+}
@@ -91,6 +91,10 @@ func (se *StreamEncoder) AddMetadata(meta ProducerMetadata) {
 				CollectedSpans: meta.TraceData,
 			},
 		}
+	} else if meta.TxnWrites != nil {
+		enc.Value = &RemoteProducerMetadata_TxnWrites_{
+			TxnWrites: meta.TxnWrites,
+		}
 	} else {
 		enc.Value = &RemoteProducerMetadata_Error{
 			Error: NewError(meta.Err),
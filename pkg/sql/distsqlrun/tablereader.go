@@ -39,12 +39,14 @@ type tableReader struct {
 	tableID   sqlbase.ID
 	spans     roachpb.Spans
 	limitHint int64
+	locking   sqlbase.ScanLocking
 
 	fetcher sqlbase.RowFetcher
 	alloc   sqlbase.DatumAlloc
 }
 
 var _ Processor = &tableReader{}
+var _ kvBatchCounter = &tableReader{}
 
 // newTableReader creates a tableReader.
 func newTableReader(
@@ -56,15 +58,52 @@ func newTableReader(
 	tr := &tableReader{
 		flowCtx: flowCtx,
 		tableID: spec.Table.ID,
+		locking: sqlbase.ScanLocking{ForUpdate: spec.LockForUpdate, NoWait: spec.LockNoWait},
 	}
 
+	tr.limitHint = tableReaderLimitHint(spec, post)
+
+	types := make([]sqlbase.ColumnType, len(spec.Table.Columns))
+	for i := range types {
+		types[i] = spec.Table.Columns[i].Type
+	}
+	if err := tr.out.Init(post, types, &flowCtx.EvalCtx, output); err != nil {
+		return nil, err
+	}
+
+	desc := spec.Table
+	if _, _, err := initRowFetcher(
+		&tr.fetcher, &desc, int(spec.IndexIdx), spec.Reverse, tr.locking, tr.out.neededColumns(),
+		&tr.alloc,
+	); err != nil {
+		return nil, err
+	}
+	if tr.locking.ForUpdate {
+		// The rows are locked once they've passed the filter. The locks are
+		// intents, which need to be reported to the gateway.
+		tr.out.onFilterPassed = tr.fetcher.LockRow
+		flowCtx.txn.EnableIntentTracking()
+	}
+
+	tr.spans = make(roachpb.Spans, len(spec.Spans))
+	for i, s := range spec.Spans {
+		tr.spans[i] = s.Span
+	}
+
+	return tr, nil
+}
+
+// tableReaderLimitHint returns the number of rows that a table reader with
+// the given specs is expected to need, or 0 if there is no limit.
+func tableReaderLimitHint(spec *TableReaderSpec, post *PostProcessSpec) int64 {
+	var limitHint int64
 	// We ignore any limits that are higher than this value to avoid any
 	// overflows.
 	const overflowProtection = 1000000000
 	if post.Limit != 0 && post.Limit <= overflowProtection {
 		// In this case the ProcOutputHelper will tell us to stop once we emit
 		// enough rows.
-		tr.limitHint = int64(post.Limit)
+		limitHint = int64(post.Limit)
 	} else if spec.LimitHint != 0 && spec.LimitHint <= overflowProtection {
 		// If it turns out that limiHint rows are sufficient for our consumer, we
 		// want to avoid asking for another batch. Currently, the only way for us to
@@ -83,35 +122,15 @@ func newTableReader(
 		// reasoning goes out the door.
 		//
 		// TODO(radu, andrei): work on a real mechanism for limits.
-		tr.limitHint = spec.LimitHint + rowChannelBufSize + 1
+		limitHint = spec.LimitHint + rowChannelBufSize + 1
 	}
 
 	if post.Filter.Expr != "" {
 		// We have a filter so we will likely need to read more rows.
-		tr.limitHint *= 2
+		limitHint *= 2
 	}
 
-	types := make([]sqlbase.ColumnType, len(spec.Table.Columns))
-	for i := range types {
-		types[i] = spec.Table.Columns[i].Type
-	}
-	if err := tr.out.Init(post, types, &flowCtx.EvalCtx, output); err != nil {
-		return nil, err
-	}
-
-	desc := spec.Table
-	if _, _, err := initRowFetcher(
-		&tr.fetcher, &desc, int(spec.IndexIdx), spec.Reverse, tr.out.neededColumns(), &tr.alloc,
-	); err != nil {
-		return nil, err
-	}
-
-	tr.spans = make(roachpb.Spans, len(spec.Spans))
-	for i, s := range spec.Spans {
-		tr.spans[i] = s.Span
-	}
-
-	return tr, nil
+	return limitHint
 }
 
 func initRowFetcher(
@@ -119,6 +138,7 @@ func initRowFetcher(
 	desc *sqlbase.TableDescriptor,
 	indexIdx int,
 	reverseScan bool,
+	locking sqlbase.ScanLocking,
 	valNeededForCol []bool,
 	alloc *sqlbase.DatumAlloc,
 ) (index *sqlbase.IndexDescriptor, isSecondaryIndex bool, err error) {
@@ -140,7 +160,7 @@ func initRowFetcher(
 		colIdxMap[c.ID] = i
 	}
 	if err := fetcher.Init(
-		desc, colIdxMap, index, reverseScan, false /* lockForUpdate */, isSecondaryIndex,
+		desc, colIdxMap, index, reverseScan, locking, isSecondaryIndex,
 		desc.Columns, valNeededForCol, true /* returnRangeInfo */, alloc,
 	); err != nil {
 		return nil, false, err
@@ -149,13 +169,15 @@ func initRowFetcher(
 }
 
 // sendMisplannedRangesMetadata sends information about the non-local ranges
-// that were read by this tableReader. This should be called after the fetcher
-// was used to read everything this tableReader was supposed to read.
-func (tr *tableReader) sendMisplannedRangesMetadata(ctx context.Context) {
-	rangeInfos := tr.fetcher.GetRangeInfo()
+// that were read by a table reader's fetcher. This should be called after the
+// fetcher was used to read everything the table reader was supposed to read.
+func sendMisplannedRangesMetadata(
+	ctx context.Context, fetcher *sqlbase.RowFetcher, nodeID roachpb.NodeID, output RowReceiver,
+) {
+	rangeInfos := fetcher.GetRangeInfo()
 	var misplannedRanges []roachpb.RangeInfo
 	for _, ri := range rangeInfos {
-		if ri.Lease.Replica.NodeID != tr.flowCtx.nodeID {
+		if ri.Lease.Replica.NodeID != nodeID {
 			misplannedRanges = append(misplannedRanges, ri)
 		}
 	}
@@ -168,7 +190,7 @@ func (tr *tableReader) sendMisplannedRangesMetadata(ctx context.Context) {
 		}
 		log.VEventf(ctx, 2, "tableReader pushing metadata about misplanned ranges: %s",
 			msg)
-		tr.out.output.Push(nil /* row */, ProducerMetadata{Ranges: misplannedRanges})
+		output.Push(nil /* row */, ProducerMetadata{Ranges: misplannedRanges})
 	}
 }
 
@@ -219,7 +241,20 @@ func (tr *tableReader) Run(ctx context.Context, wg *sync.WaitGroup) {
 			break
 		}
 	}
-	tr.sendMisplannedRangesMetadata(ctx)
+	if tr.locking.ForUpdate {
+		// The locks have to be reported even if an error occurred: some of them
+		// might have been acquired anyway.
+		writeTxn, intents := txn.TrackedWrites()
+		tr.out.output.Push(nil /* row */, ProducerMetadata{
+			TxnWrites: &RemoteProducerMetadata_TxnWrites{Txn: writeTxn, IntentSpans: intents},
+		})
+	}
+	sendMisplannedRangesMetadata(ctx, &tr.fetcher, tr.flowCtx.nodeID, tr.out.output)
 	sendTraceData(ctx, tr.out.output)
 	tr.out.Close()
 }
+
+// kvBatches is part of the kvBatchCounter interface.
+func (tr *tableReader) kvBatches() int64 {
+	return tr.fetcher.KVBatches()
+}
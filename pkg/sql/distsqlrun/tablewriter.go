@@ -0,0 +1,270 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// tableWriterBatchSize is the number of rows written by a tableWriter in a
+// single KV batch.
+const tableWriterBatchSize = 1000
+
+// tableWriter is a processor that inserts, updates or deletes the rows it
+// receives from its input. It outputs a single row containing the number of
+// rows that were written.
+//
+// The writes are performed through the flow's transaction; the resulting
+// intents and the updated transaction are sent back to the gateway as
+// metadata.
+type tableWriter struct {
+	processorBase
+
+	flowCtx *FlowCtx
+	input   RowSource
+	desc    sqlbase.TableDescriptor
+	typ     TableWriterSpec_Type
+
+	ri sqlbase.RowInserter
+	ru sqlbase.RowUpdater
+	rd sqlbase.RowDeleter
+
+	// cols are the columns whose values are written: the insert columns for
+	// INSERT and the update columns for UPDATE.
+	cols []sqlbase.ColumnDescriptor
+
+	datums     tree.Datums
+	datumAlloc sqlbase.DatumAlloc
+}
+
+var _ Processor = &tableWriter{}
+
+func newTableWriter(
+	flowCtx *FlowCtx,
+	spec *TableWriterSpec,
+	input RowSource,
+	post *PostProcessSpec,
+	output RowReceiver,
+) (*tableWriter, error) {
+	tw := &tableWriter{
+		flowCtx: flowCtx,
+		input:   input,
+		desc:    spec.Table,
+		typ:     spec.Type,
+	}
+
+	cols := make([]sqlbase.ColumnDescriptor, len(spec.ColumnIDs))
+	for i, id := range spec.ColumnIDs {
+		col, err := tw.desc.FindColumnByID(sqlbase.ColumnID(id))
+		if err != nil {
+			return nil, err
+		}
+		cols[i] = *col
+	}
+
+	// The row writers are set up exactly like the local planNodes do it (see
+	// the table writers in the sql package), without foreign key checks. The
+	// gateway relies on this to know which columns to send for UPDATE and
+	// DELETE.
+	var numInputCols int
+	var err error
+	switch spec.Type {
+	case TableWriterSpec_INSERT:
+		tw.ri, err = sqlbase.MakeRowInserter(
+			flowCtx.txn, &tw.desc, nil /* fkTables */, cols, sqlbase.SkipFKs, &tw.datumAlloc,
+		)
+		tw.cols = tw.ri.InsertCols
+		numInputCols = len(tw.ri.InsertCols)
+	case TableWriterSpec_UPDATE:
+		tw.ru, err = sqlbase.MakeRowUpdater(
+			flowCtx.txn, &tw.desc, nil /* fkTables */, cols, nil, /* requestedCols */
+			sqlbase.RowUpdaterDefault, &tw.datumAlloc,
+		)
+		tw.cols = tw.ru.UpdateCols
+		numInputCols = len(tw.ru.FetchCols) + len(tw.ru.UpdateCols)
+	case TableWriterSpec_DELETE:
+		if len(cols) == 0 {
+			cols = nil
+		}
+		tw.rd, err = sqlbase.MakeRowDeleter(
+			flowCtx.txn, &tw.desc, nil /* fkTables */, cols, sqlbase.SkipFKs, &tw.datumAlloc,
+		)
+		numInputCols = len(tw.rd.FetchCols)
+	default:
+		return nil, errors.Errorf("unknown table writer type %s", spec.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n := len(input.Types()); n != numInputCols {
+		return nil, errors.Errorf(
+			"%s on table %s expects %d input columns, got %d", spec.Type, tw.desc.Name, numInputCols, n,
+		)
+	}
+	tw.datums = make(tree.Datums, numInputCols)
+
+	// The intents written by this processor need to be reported to the
+	// gateway.
+	flowCtx.txn.EnableIntentTracking()
+
+	types := []sqlbase.ColumnType{{SemanticType: sqlbase.ColumnType_INT}}
+	if err := tw.out.Init(post, types, &flowCtx.EvalCtx, output); err != nil {
+		return nil, err
+	}
+	return tw, nil
+}
+
+// Run is part of the processor interface.
+func (tw *tableWriter) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	ctx = log.WithLogTag(ctx, "TableWriter", nil)
+	ctx, span := processorSpan(ctx, "table writer")
+	defer tracing.FinishSpan(span)
+
+	if log.V(2) {
+		log.Infof(ctx, "starting table writer (%s)", tw.typ)
+		defer log.Infof(ctx, "exiting table writer")
+	}
+
+	count, earlyExit, err := tw.mainLoop(ctx)
+	if earlyExit {
+		return
+	}
+
+	// The writes have to be reported even if an error occurred: some intents
+	// might have been laid down anyway.
+	txn, intents := tw.flowCtx.txn.TrackedWrites()
+	tw.out.output.Push(nil /* row */, ProducerMetadata{
+		TxnWrites: &RemoteProducerMetadata_TxnWrites{Txn: txn, IntentSpans: intents},
+	})
+
+	if err == nil {
+		row := sqlbase.EncDatumRow{
+			sqlbase.DatumToEncDatum(sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT},
+				tree.NewDInt(tree.DInt(count))),
+		}
+		if _, err = tw.out.EmitRow(ctx, row); err == nil {
+			sendTraceData(ctx, tw.out.output)
+			tw.input.ConsumerClosed()
+			tw.out.Close()
+			return
+		}
+	}
+	DrainAndClose(ctx, tw.out.output, err, tw.input)
+}
+
+// mainLoop writes all the rows coming from the input and returns their
+// number. If earlyExit is set, the input and the output have been closed
+// already.
+func (tw *tableWriter) mainLoop(ctx context.Context) (count int64, earlyExit bool, _ error) {
+	types := tw.input.Types()
+	b := tw.flowCtx.txn.NewBatch()
+	batchRows := 0
+	flush := func() error {
+		if batchRows == 0 {
+			return nil
+		}
+		if err := tw.flowCtx.txn.Run(ctx, b); err != nil {
+			return sqlbase.ConvertBatchError(ctx, &tw.desc, b)
+		}
+		b = tw.flowCtx.txn.NewBatch()
+		batchRows = 0
+		return nil
+	}
+
+	for {
+		row, meta := tw.input.Next()
+		if !meta.Empty() {
+			if meta.Err != nil {
+				return count, false, meta.Err
+			}
+			if !emitHelper(ctx, &tw.out, nil /* row */, meta, tw.input) {
+				return count, true, nil
+			}
+			continue
+		}
+		if row == nil {
+			return count, false, flush()
+		}
+
+		for i := range row {
+			if err := row[i].EnsureDecoded(&types[i], &tw.datumAlloc); err != nil {
+				return count, false, err
+			}
+			tw.datums[i] = row[i].Datum
+		}
+		if err := tw.writeRow(ctx, b, tw.datums); err != nil {
+			return count, false, err
+		}
+		count++
+		batchRows++
+		if batchRows >= tableWriterBatchSize {
+			if err := flush(); err != nil {
+				return count, false, err
+			}
+		}
+	}
+}
+
+// writeRow validates a row and adds the KV operations that write it to the
+// batch.
+func (tw *tableWriter) writeRow(ctx context.Context, b *client.Batch, row tree.Datums) error {
+	switch tw.typ {
+	case TableWriterSpec_INSERT:
+		for _, col := range tw.desc.Columns {
+			if col.Nullable {
+				continue
+			}
+			if i, ok := tw.ri.InsertColIDtoRowIndex[col.ID]; !ok || row[i] == tree.DNull {
+				return sqlbase.NewNonNullViolationError(col.Name)
+			}
+		}
+		for i := range row {
+			if err := sqlbase.CheckValueWidth(tw.cols[i], row[i]); err != nil {
+				return err
+			}
+		}
+		return tw.ri.InsertRow(ctx, b, row, false /* ignoreConflicts */, false /* traceKV */)
+
+	case TableWriterSpec_UPDATE:
+		oldValues := row[:len(tw.ru.FetchCols)]
+		updateValues := row[len(tw.ru.FetchCols):]
+		for i, col := range tw.cols {
+			if !col.Nullable && updateValues[i] == tree.DNull {
+				return sqlbase.NewNonNullViolationError(col.Name)
+			}
+			if err := sqlbase.CheckValueWidth(col, updateValues[i]); err != nil {
+				return err
+			}
+		}
+		_, err := tw.ru.UpdateRow(ctx, b, oldValues, updateValues, false /* traceKV */)
+		return err
+
+	default:
+		return tw.rd.DeleteRow(ctx, b, row, false /* traceKV */)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestTableWriter runs table writers the way the gateway does it: on behalf of
+// a transaction whose TxnCoordSender learns about the writes from the
+// metadata they produce.
+func TestTableWriter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlutils.CreateTable(t, sqlDB, "t",
+		"k INT PRIMARY KEY, v INT NOT NULL",
+		10,
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, sqlutils.RowModuloFn(3)))
+	td := sqlbase.GetTableDescriptor(kvDB, "test", "t")
+
+	intRow := func(vals ...int) sqlbase.EncDatumRow {
+		row := make(sqlbase.EncDatumRow, len(vals))
+		for i, v := range vals {
+			row[i] = sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(v)))
+		}
+		return row
+	}
+
+	testCases := []struct {
+		name  string
+		spec  TableWriterSpec
+		types []sqlbase.ColumnType
+		input sqlbase.EncDatumRows
+		// expErr is set if the writes fail, in which case the transaction is
+		// rolled back.
+		expErr   string
+		expCount int
+		expected string
+	}{
+		{
+			name:     "insert",
+			spec:     TableWriterSpec{Type: TableWriterSpec_INSERT, ColumnIDs: []uint32{1, 2}},
+			types:    twoIntCols,
+			input:    sqlbase.EncDatumRows{intRow(11, 5), intRow(12, 6)},
+			expCount: 2,
+			expected: "12 6",
+		},
+		{
+			name:   "insert duplicate",
+			spec:   TableWriterSpec{Type: TableWriterSpec_INSERT, ColumnIDs: []uint32{1, 2}},
+			types:  twoIntCols,
+			input:  sqlbase.EncDatumRows{intRow(13, 7), intRow(1, 7)},
+			expErr: "duplicate key value",
+			// The first row isn't committed.
+			expected: "12 6",
+		},
+		{
+			name:   "insert null",
+			spec:   TableWriterSpec{Type: TableWriterSpec_INSERT, ColumnIDs: []uint32{1}},
+			types:  oneIntCol,
+			input:  sqlbase.EncDatumRows{intRow(14)},
+			expErr: `null value in column "v" violates not-null constraint`,
+			// The row is not written.
+			expected: "12 6",
+		},
+		{
+			// The updater fetches both columns, followed by the new value of v.
+			name:     "update",
+			spec:     TableWriterSpec{Type: TableWriterSpec_UPDATE, ColumnIDs: []uint32{2}},
+			types:    threeIntCols,
+			input:    sqlbase.EncDatumRows{intRow(12, 6, 60)},
+			expCount: 1,
+			expected: "12 60",
+		},
+		{
+			// The deleter fetches the primary key.
+			name:     "delete",
+			spec:     TableWriterSpec{Type: TableWriterSpec_DELETE},
+			types:    oneIntCol,
+			input:    sqlbase.EncDatumRows{intRow(11), intRow(12)},
+			expCount: 2,
+			expected: "10 1",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			evalCtx := tree.MakeTestingEvalContext()
+			defer evalCtx.Stop(ctx)
+
+			// The gateway's transaction, and the leaf transaction used by the
+			// flow, which doesn't go through a TxnCoordSender.
+			txn := client.NewTxn(kvDB, s.NodeID())
+			if err := txn.PrepareForRemoteWrites(
+				ctx, roachpb.Key(td.PrimaryIndexSpan().Key), nil, /* spans */
+			); err != nil {
+				t.Fatal(err)
+			}
+			flowCtx := FlowCtx{
+				EvalCtx:  evalCtx,
+				Settings: cluster.MakeTestingClusterSettings(),
+				txn: client.NewTxnWithProto(
+					client.NewDB(s.DistSender(), s.Clock()), s.NodeID(), *txn.Proto(),
+				),
+			}
+
+			spec := c.spec
+			spec.Table = *td
+			in := NewRowBuffer(c.types, c.input, RowBufferArgs{})
+			out := &RowBuffer{}
+			tw, err := newTableWriter(&flowCtx, &spec, in, &PostProcessSpec{}, out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tw.Run(ctx, nil)
+			if !out.ProducerClosed {
+				t.Fatalf("output RowReceiver not closed")
+			}
+
+			var count int
+			var writes *RemoteProducerMetadata_TxnWrites
+			var writeErr error
+			for {
+				row, meta := out.Next()
+				if meta.TxnWrites != nil {
+					writes = meta.TxnWrites
+				}
+				if meta.Err != nil {
+					writeErr = meta.Err
+				}
+				if row == nil && meta.Empty() {
+					break
+				}
+				if row != nil {
+					count = int(tree.MustBeDInt(row[0].Datum))
+				}
+			}
+
+			// The writes are reported even if they failed.
+			if writes == nil {
+				t.Fatal("the writes were not reported")
+			}
+			if c.expErr == "" && len(writes.IntentSpans) == 0 {
+				t.Fatal("no intents were reported")
+			}
+			if err := txn.AugmentRemoteWrites(ctx, writes.Txn, writes.IntentSpans); err != nil {
+				t.Fatal(err)
+			}
+
+			if c.expErr != "" {
+				if !testutils.IsError(writeErr, c.expErr) {
+					t.Fatalf("expected error %q, got %v", c.expErr, writeErr)
+				}
+				if err := txn.Rollback(ctx); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				if writeErr != nil {
+					t.Fatal(writeErr)
+				}
+				if count != c.expCount {
+					t.Fatalf("expected count %d, got %d", c.expCount, count)
+				}
+				if err := txn.CommitOrCleanup(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The intents have been resolved: reading the table doesn't block.
+			var k, v int
+			r := sqlutils.MakeSQLRunner(t, sqlDB)
+			r.QueryRow(`SELECT k, v FROM test.t ORDER BY k DESC LIMIT 1`).Scan(&k, &v)
+			if result := fmt.Sprintf("%d %d", k, v); result != c.expected {
+				t.Fatalf("expected last row %s, got %s", c.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// newVectorizedProcessor creates a processor which runs the given core, and as
+// much as possible of the post-processing stage, with the vectorized engine.
+// An error is returned if the core or the types of its inputs and outputs are
+// not supported, in which case the caller should use the row-based processor.
+func newVectorizedProcessor(
+	flowCtx *FlowCtx,
+	core *ProcessorCoreUnion,
+	post *PostProcessSpec,
+	inputs []RowSource,
+	output RowReceiver,
+) (Processor, error) {
+	m := &materializer{flowCtx: flowCtx, inputs: inputs}
+
+	columnarize := func(input RowSource) (exec.Operator, error) {
+		return newColumnarizer(input, output)
+	}
+
+	var op exec.Operator
+	var err error
+	switch {
+	case core.TableReader != nil && len(inputs) == 0:
+		m.name = "table reader"
+		if m.scan, err = newColTableScan(flowCtx, core.TableReader, post); err != nil {
+			return nil, err
+		}
+		op, m.types = m.scan, m.scan.types
+
+	case core.Noop != nil && len(inputs) == 1:
+		m.name = "noop"
+		if op, err = columnarize(inputs[0]); err != nil {
+			return nil, err
+		}
+		m.types = inputs[0].Types()
+
+	case core.Sorter != nil && len(inputs) == 1:
+		m.name = "sorter"
+		input, err := columnarize(inputs[0])
+		if err != nil {
+			return nil, err
+		}
+		m.types = inputs[0].Types()
+		ordering := make([]exec.SortColumn, len(core.Sorter.OutputOrdering.Columns))
+		for i, c := range core.Sorter.OutputOrdering.Columns {
+			ordering[i] = exec.SortColumn{
+				ColIdx: int(c.ColIdx),
+				Desc:   c.Direction == Ordering_Column_DESC,
+			}
+		}
+		if op, err = exec.NewSorter(input, mustExecTypes(m.types), ordering); err != nil {
+			return nil, err
+		}
+
+	case core.Aggregator != nil && len(inputs) == 1:
+		m.name = "aggregator"
+		if op, m.types, err = newVectorizedAggregator(core.Aggregator, inputs[0], columnarize); err != nil {
+			return nil, err
+		}
+
+	case core.HashJoiner != nil && len(inputs) == 2:
+		m.name = "hash joiner"
+		if op, m.types, err = newVectorizedHashJoiner(
+			core.HashJoiner, inputs[0], inputs[1], columnarize,
+		); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, errors.Errorf("unsupported processor core %s", core)
+	}
+
+	var remaining *PostProcessSpec
+	m.input, m.types, remaining = planPostProcess(op, post, m.types, &flowCtx.EvalCtx)
+	if err := m.out.Init(remaining, m.types, &flowCtx.EvalCtx, output); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// mustExecTypes is like execTypes, for types known to be supported.
+func mustExecTypes(types []sqlbase.ColumnType) []exec.Type {
+	res, err := execTypes(types)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// aggFuncs maps the aggregate functions supported by the vectorized engine to
+// their implementation.
+var aggFuncs = map[AggregatorSpec_Func]exec.AggFunc{
+	AggregatorSpec_IDENT:      exec.AnyNotNull,
+	AggregatorSpec_BOOL_AND:   exec.BoolAnd,
+	AggregatorSpec_BOOL_OR:    exec.BoolOr,
+	AggregatorSpec_COUNT:      exec.Count,
+	AggregatorSpec_COUNT_ROWS: exec.CountRows,
+	AggregatorSpec_MAX:        exec.Max,
+	AggregatorSpec_MIN:        exec.Min,
+	// SUM is only supported for floats; on integers it returns a decimal.
+	AggregatorSpec_SUM:     exec.Sum,
+	AggregatorSpec_SUM_INT: exec.Sum,
+}
+
+func newVectorizedAggregator(
+	spec *AggregatorSpec,
+	input RowSource,
+	columnarize func(RowSource) (exec.Operator, error),
+) (exec.Operator, []sqlbase.ColumnType, error) {
+	inputTypes := input.Types()
+	aggs := make([]exec.Aggregation, len(spec.Aggregations))
+	outputTypes := make([]sqlbase.ColumnType, len(spec.Aggregations))
+	for i, a := range spec.Aggregations {
+		fn, ok := aggFuncs[a.Func]
+		if !ok || a.Distinct || a.FilterColIdx != nil || len(a.ColIdx) > 1 {
+			return nil, nil, errors.Errorf("unsupported aggregation %s", a.Func)
+		}
+		argTypes := make([]sqlbase.ColumnType, len(a.ColIdx))
+		for j, c := range a.ColIdx {
+			argTypes[j] = inputTypes[c]
+		}
+		_, retType, err := GetAggregateInfo(a.Func, argTypes...)
+		if err != nil {
+			return nil, nil, err
+		}
+		if a.Func == AggregatorSpec_SUM && retType.SemanticType != sqlbase.ColumnType_FLOAT {
+			return nil, nil, errors.Errorf("unsupported aggregation %s on %s", a.Func, retType.SQLString())
+		}
+		aggs[i].Func = fn
+		if len(a.ColIdx) == 1 {
+			aggs[i].ColIdx = int(a.ColIdx[0])
+		}
+		outputTypes[i] = retType
+	}
+	groupCols := make([]int, len(spec.GroupCols))
+	for i, c := range spec.GroupCols {
+		groupCols[i] = int(c)
+	}
+
+	in, err := columnarize(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	op, err := exec.NewHashAggregator(in, mustExecTypes(inputTypes), groupCols, aggs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return op, outputTypes, nil
+}
+
+func newVectorizedHashJoiner(
+	spec *HashJoinerSpec,
+	left, right RowSource,
+	columnarize func(RowSource) (exec.Operator, error),
+) (exec.Operator, []sqlbase.ColumnType, error) {
+	if spec.Type != JoinType_INNER || spec.OnExpr.Expr != "" || spec.MergedColumns {
+		return nil, nil, errors.Errorf("unsupported hash join")
+	}
+	leftTypes, rightTypes := left.Types(), right.Types()
+	leftEqCols := make([]int, len(spec.LeftEqColumns))
+	rightEqCols := make([]int, len(spec.RightEqColumns))
+	for i := range spec.LeftEqColumns {
+		leftEqCols[i], rightEqCols[i] = int(spec.LeftEqColumns[i]), int(spec.RightEqColumns[i])
+		if leftTypes[leftEqCols[i]].SemanticType != rightTypes[rightEqCols[i]].SemanticType {
+			return nil, nil, errors.Errorf("unsupported equality between different types")
+		}
+	}
+
+	leftOp, err := columnarize(left)
+	if err != nil {
+		return nil, nil, err
+	}
+	rightOp, err := columnarize(right)
+	if err != nil {
+		return nil, nil, err
+	}
+	op, err := exec.NewHashJoiner(
+		leftOp, rightOp, mustExecTypes(leftTypes), mustExecTypes(rightTypes), leftEqCols, rightEqCols,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return op, append(append([]sqlbase.ColumnType(nil), leftTypes...), rightTypes...), nil
+}
+
+// planPostProcess adds to op the vectorized operators implementing the filter,
+// projection and rendering of the given post-processing spec, as long as all
+// their expressions are supported. It returns the resulting operator, the
+// types of its output and the part of the post-processing which is left to the
+// ProcOutputHelper.
+func planPostProcess(
+	op exec.Operator, post *PostProcessSpec, types []sqlbase.ColumnType, evalCtx *tree.EvalContext,
+) (exec.Operator, []sqlbase.ColumnType, *PostProcessSpec) {
+	if post.Filter.Expr != "" {
+		var eh exprHelper
+		if err := eh.init(post.Filter, types, evalCtx); err != nil {
+			return op, types, post
+		}
+		filtered, err := planFilter(op, eh.expr, types)
+		if err != nil {
+			return op, types, post
+		}
+		op = filtered
+	}
+	remaining := &PostProcessSpec{Offset: post.Offset, Limit: post.Limit}
+
+	if post.Projection {
+		outTypes := make([]sqlbase.ColumnType, len(post.OutputColumns))
+		for i, c := range post.OutputColumns {
+			outTypes[i] = types[c]
+		}
+		return exec.NewSimpleProjectOp(op, post.OutputColumns), outTypes, remaining
+	}
+
+	if len(post.RenderExprs) > 0 {
+		rendered := op
+		renderTypes := types
+		projection := make([]uint32, len(post.RenderExprs))
+		outTypes := make([]sqlbase.ColumnType, len(post.RenderExprs))
+		for i, expr := range post.RenderExprs {
+			var eh exprHelper
+			err := eh.init(expr, types, evalCtx)
+			if err == nil {
+				var colIdx int
+				rendered, renderTypes, colIdx, err = planRender(rendered, eh.expr, renderTypes)
+				projection[i] = uint32(colIdx)
+				if err == nil {
+					outTypes[i] = renderTypes[colIdx]
+				}
+			}
+			if err != nil {
+				remaining.RenderExprs = post.RenderExprs
+				return op, types, remaining
+			}
+		}
+		return exec.NewSimpleProjectOp(rendered, projection), outTypes, remaining
+	}
+	return op, types, remaining
+}
+
+// planFilter adds to op the selection operators implementing a filter
+// expression.
+func planFilter(
+	op exec.Operator, expr tree.TypedExpr, types []sqlbase.ColumnType,
+) (exec.Operator, error) {
+	switch t := expr.(type) {
+	case *tree.AndExpr:
+		op, err := planFilter(op, t.TypedLeft(), types)
+		if err != nil {
+			return nil, err
+		}
+		return planFilter(op, t.TypedRight(), types)
+
+	case *tree.ParenExpr:
+		return planFilter(op, t.TypedInnerExpr(), types)
+
+	case *tree.ComparisonExpr:
+		left, right := t.TypedLeft(), t.TypedRight()
+		if t.Operator == tree.Is || t.Operator == tree.IsNot {
+			if ivar, ok := left.(*tree.IndexedVar); ok && right == tree.DNull {
+				return exec.NewSelIsNullOp(op, ivar.Idx, t.Operator == tree.IsNot), nil
+			}
+			break
+		}
+		cmpOp, ok := cmpOps[t.Operator]
+		if !ok {
+			break
+		}
+		if _, ok := left.(*tree.IndexedVar); !ok {
+			// Put the column on the left.
+			left, right = right, left
+			cmpOp = flippedCmpOps[cmpOp]
+		}
+		ivar, ok := left.(*tree.IndexedVar)
+		if !ok {
+			break
+		}
+		typ := types[ivar.Idx]
+		switch r := right.(type) {
+		case *tree.IndexedVar:
+			if types[r.Idx].SemanticType != typ.SemanticType {
+				break
+			}
+			return exec.NewSelColOp(op, execType(typ), ivar.Idx, r.Idx, cmpOp)
+		case tree.Datum:
+			if c, ok := datumConstant(r, typ); ok {
+				return exec.NewSelConstOp(op, execType(typ), ivar.Idx, cmpOp, c)
+			}
+		}
+	}
+	return nil, errors.Errorf("unsupported filter expression %s", expr)
+}
+
+var cmpOps = map[tree.ComparisonOperator]exec.CmpOp{
+	tree.EQ: exec.EQ,
+	tree.NE: exec.NE,
+	tree.LT: exec.LT,
+	tree.LE: exec.LE,
+	tree.GT: exec.GT,
+	tree.GE: exec.GE,
+}
+
+// flippedCmpOps maps each comparison to the one which holds when its operands
+// are swapped.
+var flippedCmpOps = map[exec.CmpOp]exec.CmpOp{
+	exec.EQ: exec.EQ,
+	exec.NE: exec.NE,
+	exec.LT: exec.GT,
+	exec.LE: exec.GE,
+	exec.GT: exec.LT,
+	exec.GE: exec.LE,
+}
+
+var binOps = map[tree.BinaryOperator]exec.BinOp{
+	tree.Plus:  exec.Plus,
+	tree.Minus: exec.Minus,
+	tree.Mult:  exec.Mult,
+	tree.Div:   exec.Div,
+}
+
+// planRender adds to op the projection operators computing a render
+// expression. It returns the resulting operator, the types of the columns of
+// its batches and the index of the column holding the result.
+func planRender(
+	op exec.Operator, expr tree.TypedExpr, types []sqlbase.ColumnType,
+) (exec.Operator, []sqlbase.ColumnType, int, error) {
+	switch t := expr.(type) {
+	case *tree.IndexedVar:
+		return op, types, t.Idx, nil
+
+	case *tree.ParenExpr:
+		return planRender(op, t.TypedInnerExpr(), types)
+
+	case *tree.BinaryExpr:
+		binOp, ok := binOps[t.Operator]
+		if !ok {
+			break
+		}
+		typ, err := sqlbase.DatumTypeToColumnType(t.ResolvedType())
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		left, right := t.TypedLeft(), t.TypedRight()
+		if _, ok := left.(tree.Datum); ok && (binOp == exec.Plus || binOp == exec.Mult) {
+			// Put the constant on the right.
+			left, right = right, left
+		}
+		if _, ok := left.(tree.Datum); ok {
+			break
+		}
+		var leftIdx int
+		op, types, leftIdx, err = planRender(op, left, types)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if types[leftIdx].SemanticType != typ.SemanticType {
+			break
+		}
+		outputIdx := len(types)
+		if d, ok := right.(tree.Datum); ok {
+			c, ok := datumConstant(d, typ)
+			if !ok {
+				break
+			}
+			op, err = exec.NewProjConstOp(op, execType(typ), leftIdx, binOp, c, outputIdx)
+		} else {
+			var rightIdx int
+			op, types, rightIdx, err = planRender(op, right, types)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			if types[rightIdx].SemanticType != typ.SemanticType {
+				break
+			}
+			outputIdx = len(types)
+			op, err = exec.NewProjColOp(op, execType(typ), leftIdx, rightIdx, binOp, outputIdx)
+		}
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		return op, append(types[:len(types):len(types)], typ), outputIdx, nil
+	}
+	return nil, nil, 0, errors.Errorf("unsupported render expression %s", expr)
+}
+
+// datumConstant converts a non-NULL datum to the representation of the values
+// of the given column type in the vectorized engine.
+func datumConstant(d tree.Datum, typ sqlbase.ColumnType) (interface{}, bool) {
+	switch t := d.(type) {
+	case *tree.DBool:
+		return bool(*t), typ.SemanticType == sqlbase.ColumnType_BOOL
+	case *tree.DInt:
+		return int64(*t), typ.SemanticType == sqlbase.ColumnType_INT
+	case *tree.DFloat:
+		return float64(*t), typ.SemanticType == sqlbase.ColumnType_FLOAT
+	case *tree.DString:
+		return []byte(*t), typ.SemanticType == sqlbase.ColumnType_STRING
+	case *tree.DBytes:
+		return []byte(*t), typ.SemanticType == sqlbase.ColumnType_BYTES
+	}
+	return nil, false
+}
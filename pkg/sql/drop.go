@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 type dropDatabaseNode struct {
@@ -68,12 +69,12 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 		return nil, err
 	}
 
-	tbNames, err := getTableNames(ctx, p.txn, p.getVirtualTabler(), dbDesc, false)
+	tbNames, err := getAllTableNames(ctx, p.txn, p.getVirtualTabler(), dbDesc)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(tbNames) > 0 {
+	if len(tbNames) > 0 || len(dbDesc.Schemas) > 0 {
 		switch n.DropBehavior {
 		case tree.DropRestrict:
 			return nil, pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
@@ -263,7 +264,9 @@ func (p *planner) DropIndex(ctx context.Context, n *tree.DropIndex) (planNode, e
 			return nil, fmt.Errorf("index %q not found", index.Index)
 		}
 
-		tableDesc, err := MustGetTableDesc(ctx, p.txn, p.getVirtualTabler(), tn, true /*allowAdding*/)
+		tableDesc, err := mustGetIndexableTableDesc(
+			ctx, p.txn, p.getVirtualTabler(), tn, true, /*allowAdding*/
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -284,7 +287,7 @@ func (n *dropIndexNode) Start(params runParams) error {
 		// the list: when two or more index names refer to the same table,
 		// the mutation list and new version number created by the first
 		// drop need to be visible to the second drop.
-		tableDesc, err := getTableDesc(ctx, params.p.txn, params.p.getVirtualTabler(), index.tn)
+		tableDesc, err := getTableOrViewDesc(ctx, params.p.txn, params.p.getVirtualTabler(), index.tn)
 		if err != nil || tableDesc == nil {
 			// newPlan() and Start() ultimately run within the same
 			// transaction. If we got a descriptor during newPlan(), we
@@ -405,6 +408,28 @@ func (p *planner) dropIndexByName(
 		return fmt.Errorf("index %q in the middle of being added, try again later", idxName)
 	}
 
+	// Drop the hidden computed columns which backed the expressions of the
+	// index, unless another index uses them.
+	for _, colID := range idx.ColumnIDs {
+		used := false
+		for _, other := range tableDesc.AllNonDropIndexes() {
+			if other.ContainsColumnID(colID) {
+				used = true
+				break
+			}
+		}
+		if used {
+			continue
+		}
+		for i := range tableDesc.Columns {
+			if col := tableDesc.Columns[i]; col.ID == colID && col.IsIndexExpr() {
+				tableDesc.AddColumnMutation(col, sqlbase.DescriptorMutation_DROP)
+				tableDesc.Columns = append(tableDesc.Columns[:i], tableDesc.Columns[i+1:]...)
+				break
+			}
+		}
+	}
+
 	if err := tableDesc.Validate(ctx, p.txn); err != nil {
 		return err
 	}
@@ -479,6 +504,14 @@ func (p *planner) DropView(ctx context.Context, n *tree.DropView) (planNode, err
 		if !droppedDesc.IsView() {
 			return nil, sqlbase.NewWrongObjectTypeError(tn, "view")
 		}
+		if n.Materialized != droppedDesc.MaterializedView() {
+			if n.Materialized {
+				return nil, sqlbase.NewWrongObjectTypeError(tn, "materialized view")
+			}
+			return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+				"%q is a materialized view", tree.ErrString(tn),
+			).SetHintf("use DROP MATERIALIZED VIEW instead")
+		}
 
 		td = append(td, droppedDesc)
 	}
@@ -569,6 +602,9 @@ func (p *planner) DropTable(ctx context.Context, n *tree.DropTable) (planNode, e
 		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
 			return nil, err
 		}
+		if err := p.searchTempSchema(ctx, tn); err != nil {
+			return nil, err
+		}
 
 		droppedDesc, err := p.dropTableOrViewPrepare(ctx, tn)
 		if err != nil {
@@ -879,6 +915,8 @@ func (p *planner) initiateDropTable(ctx context.Context, tableDesc *sqlbase.Tabl
 		return err
 	}
 	tableDesc.State = sqlbase.TableDescriptor_DROP
+	// The table's data is cleared once its GC TTL has elapsed since now.
+	tableDesc.DropTime = timeutil.Now().UnixNano()
 	if err := p.writeTableDesc(ctx, tableDesc); err != nil {
 		return err
 	}
@@ -1100,6 +1138,20 @@ func (n *dropUserNode) Start(params runParams) error {
 					tree.Name(db.Name).Format(&usedBy, tree.FmtSimple)
 				}
 			}
+			for i := range db.Schemas {
+				for _, u := range db.Schemas[i].GetPrivileges().Users {
+					if _, ok := userNames[u.User]; ok {
+						if usedBy.Len() > 0 {
+							usedBy.WriteString(", ")
+						}
+						sn := tree.SchemaName{
+							DatabaseName: tree.Name(db.Name),
+							SchemaName:   tree.Name(db.Schemas[i].Name),
+						}
+						sn.Format(&usedBy, tree.FmtSimple)
+					}
+				}
+			}
 			return nil
 		}); err != nil {
 		return err
@@ -1112,6 +1164,9 @@ func (n *dropUserNode) Start(params runParams) error {
 						DatabaseName: tree.Name(db.Name),
 						TableName:    tree.Name(table.Name),
 					}
+					if scDesc, ok := db.FindSchemaByID(table.ParentSchemaID); ok {
+						tn.SchemaName = tree.Name(scDesc.Name)
+					}
 					if usedBy.Len() > 0 {
 						usedBy.WriteString(", ")
 					}
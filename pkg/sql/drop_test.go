@@ -19,6 +19,7 @@ import (
 	gosql "database/sql"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -258,8 +259,10 @@ INSERT INTO t.kv VALUES ('c', 'e'), ('a', 'c'), ('b', 'd');
 	}
 	tbDesc := desc.GetTable()
 
-	// Add a zone config for both the table and database.
+	// Add a zone config for both the table and database. The zero GC TTL
+	// allows the table's data to be deleted right after the drop.
 	cfg := config.DefaultZoneConfig()
+	cfg.GC.TTLSeconds = 0
 	buf, err := protoutil.Marshal(&cfg)
 	if err != nil {
 		t.Fatal(err)
@@ -356,6 +359,19 @@ SHOW TABLES;
 	}
 }
 
+// addImmediateGCZoneConfig sets a zone config with a zero GC TTL for the
+// object with the given ID, so that its data is deleted right after a drop.
+func addImmediateGCZoneConfig(sqlDB *gosql.DB, id sqlbase.ID) error {
+	cfg := config.DefaultZoneConfig()
+	cfg.GC.TTLSeconds = 0
+	buf, err := protoutil.Marshal(&cfg)
+	if err != nil {
+		return err
+	}
+	_, err = sqlDB.Exec(`UPSERT INTO system.zones VALUES ($1, $2)`, id, buf)
+	return err
+}
+
 func checkKeyCount(t *testing.T, kvDB *client.DB, span roachpb.Span, numKeys int) {
 	if kvs, err := kvDB.Scan(context.TODO(), span.Key, span.EndKey, 0); err != nil {
 		t.Fatal(err)
@@ -586,10 +602,14 @@ func TestDropTable(t *testing.T) {
 func TestDropTableDeleteData(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	params, _ := createTestServerParams()
+	var notHitGCTTL int32
 	params.Knobs = base.TestingKnobs{
 		SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
 			// Turn on quick garbage collection.
 			AsyncExecQuickly: true,
+			GCTTLDeadlineNotHitNotification: func(sqlbase.ID) {
+				atomic.StoreInt32(&notHitGCTTL, 1)
+			},
 		},
 	}
 	s, sqlDB, kvDB := serverutils.StartServer(t, params)
@@ -631,6 +651,23 @@ func TestDropTableDeleteData(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// The data isn't deleted before the table's GC TTL has elapsed.
+	testutils.SucceedsSoon(t, func() error {
+		if atomic.LoadInt32(&notHitGCTTL) == 0 {
+			return errors.New("schema changer hasn't checked the GC TTL")
+		}
+		return nil
+	})
+	if err := descExists(sqlDB, true, tableDesc.ID); err != nil {
+		t.Fatal(err)
+	}
+	checkKeyCount(t, kvDB, tableSpan, 3*numRows)
+
+	// Lower the GC TTL so that the data is deleted.
+	if err := addImmediateGCZoneConfig(sqlDB, tableDesc.ID); err != nil {
+		t.Fatal(err)
+	}
+
 	testutils.SucceedsSoon(t, func() error {
 		if err := descExists(sqlDB, false, tableDesc.ID); err != nil {
 			return err
@@ -664,6 +701,9 @@ func TestDropTableInterleavedDeleteData(t *testing.T) {
 	tableSpan := tableDesc.TableSpan()
 
 	checkKeyCount(t, kvDB, tableSpan, 3*numRows)
+	if err := addImmediateGCZoneConfig(sqlDB, tableDescInterleaved.ID); err != nil {
+		t.Fatal(err)
+	}
 	if _, err := sqlDB.Exec(`DROP TABLE t.intlv`); err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,177 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// editJoinHelper holds the state needed by the row-modifying statements
+// which join their target table with other data sources, that is UPDATE
+// ... FROM and DELETE ... USING.
+//
+// The rows to modify are produced by a renderNode over a cross-join of the
+// target table with the other data sources. A target row that joins with
+// more than one row is only modified once, using the first row it joins
+// with, which matches the Postgres semantics.
+type editJoinHelper struct {
+	// targetName is the name under which the target table is visible to the
+	// statement's expressions.
+	targetName tree.TableName
+
+	// sourceInfo describes the columns of the data sources joined with the
+	// target table. It is only populated when the statement has a RETURNING
+	// clause, which can refer to these columns.
+	sourceInfo *dataSourceInfo
+	// sourceIdxs are the indexes of the columns described by sourceInfo in
+	// the rows produced by the source plan.
+	sourceIdxs []int
+
+	tableDesc       *sqlbase.TableDescriptor
+	colIDtoRowIndex map[sqlbase.ColumnID]int
+
+	// seen holds the primary keys of the target rows modified so far. Its
+	// memory usage is tracked by seenMemAcc.
+	seen       map[string]struct{}
+	seenMemAcc WrappableMemoryAccount
+}
+
+// editTargetName returns the name under which the target table of a
+// row-modifying statement is visible to the statement's expressions.
+func editTargetName(target tree.TableExpr, tn *tree.TableName) tree.TableName {
+	if ate, ok := target.(*tree.AliasedTableExpr); ok && ate.As.Alias != "" {
+		return tree.TableName{TableName: ate.As.Alias}
+	}
+	return *tn
+}
+
+// editColumnsSelectors is like sqlbase.ColumnsSelectors, but qualifies the
+// columns with the given table name so that they remain unambiguous when
+// the target table is joined with other data sources.
+func editColumnsSelectors(cols []sqlbase.ColumnDescriptor, tn tree.TableName) tree.SelectExprs {
+	exprs := sqlbase.ColumnsSelectors(cols)
+	for i := range exprs {
+		exprs[i].Expr.(*tree.ColumnItem).TableName = tn
+	}
+	return exprs
+}
+
+// newEditJoinHelper creates an editJoinHelper for a statement whose rows
+// are produced by render. If withSources is set, the columns of the data
+// sources joined with the target table are appended to the renders so that
+// they can be referred to by a RETURNING clause.
+func newEditJoinHelper(
+	p *planner,
+	render *renderNode,
+	targetName tree.TableName,
+	tableDesc *sqlbase.TableDescriptor,
+	colIDtoRowIndex map[sqlbase.ColumnID]int,
+	withSources bool,
+) (*editJoinHelper, error) {
+	j := &editJoinHelper{
+		targetName:      targetName,
+		tableDesc:       tableDesc,
+		colIDtoRowIndex: colIDtoRowIndex,
+		seen:            make(map[string]struct{}),
+		seenMemAcc:      p.session.TxnState.OpenAccount(),
+	}
+	if !withSources {
+		return j, nil
+	}
+
+	// The target table is the left side of the cross-join, so its columns
+	// come first.
+	info := render.source.info
+	targetIdx, ok := info.sourceAliases.srcIdx(targetName)
+	if !ok {
+		return nil, errors.Errorf("target table %q not found in data source", tree.ErrString(&targetName))
+	}
+	numTargetCols := len(info.sourceAliases[targetIdx].columnSet.Ordered())
+
+	j.sourceInfo = &dataSourceInfo{sourceColumns: info.sourceColumns[numTargetCols:]}
+	for i, alias := range info.sourceAliases {
+		if i == targetIdx {
+			continue
+		}
+		j.sourceInfo.sourceAliases = append(j.sourceInfo.sourceAliases, sourceAlias{
+			name:      alias.name,
+			columnSet: alias.columnSet.Shift(-numTargetCols),
+		})
+	}
+	j.sourceIdxs = make([]int, len(j.sourceInfo.sourceColumns))
+	for i, col := range j.sourceInfo.sourceColumns {
+		ivar := render.ivarHelper.IndexedVar(numTargetCols + i)
+		j.sourceIdxs[i] = render.addOrReuseRender(col, ivar, true /* reuse */)
+	}
+	return j, nil
+}
+
+// seenRow returns true if the target row with the given values, which must
+// include the primary key columns, was already modified by the statement.
+// Otherwise, the row is recorded as modified.
+func (j *editJoinHelper) seenRow(params runParams, values tree.Datums) (bool, error) {
+	key, _, err := sqlbase.EncodeIndexKey(
+		j.tableDesc, &j.tableDesc.PrimaryIndex, j.colIDtoRowIndex, values, nil)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := j.seen[string(key)]; ok {
+		return true, nil
+	}
+	if err := j.seenMemAcc.Wtxn(params.p.session).Grow(params.ctx, int64(len(key))); err != nil {
+		return false, err
+	}
+	j.seen[string(key)] = struct{}{}
+	return false, nil
+}
+
+// close releases the memory used to track the modified rows.
+func (j *editJoinHelper) close(ctx context.Context, s *Session) {
+	j.seen = nil
+	j.seenMemAcc.Wtxn(s).Close(ctx)
+}
+
+// sourceValues returns the values of the joined data sources' columns in
+// the given row of the source plan, or nil if they are not needed. The
+// values are copied, as the source row may be modified while the target row
+// is written.
+func (j *editJoinHelper) sourceValues(sourceRow tree.Datums) tree.Datums {
+	if j.sourceInfo == nil {
+		return nil
+	}
+	vals := make(tree.Datums, len(j.sourceIdxs))
+	for i, idx := range j.sourceIdxs {
+		vals[i] = sourceRow[idx]
+	}
+	return vals
+}
+
+// returningRow returns the row to evaluate the RETURNING clause against:
+// the first numTableCols values of the target row, which correspond to the
+// table's columns, followed by the values returned by sourceValues.
+func (j *editJoinHelper) returningRow(
+	tableVals tree.Datums, numTableCols int, sourceVals tree.Datums,
+) tree.Datums {
+	if j.sourceInfo == nil {
+		return tableVals
+	}
+	row := make(tree.Datums, numTableCols, numTableCols+len(sourceVals))
+	copy(row, tableVals)
+	return append(row, sourceVals...)
+}
@@ -0,0 +1,465 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// User-defined types are stored inside the descriptor of their database,
+// like user-defined schemas, and each of them is assigned an ID from the
+// descriptor ID space. ENUM types are currently the only user-defined types.
+//
+// The columns of an ENUM type carry a copy of the values of the type in
+// their ColumnType, so that their data can be encoded and decoded without
+// looking up the descriptor of the type. Adding a value to a type therefore
+// updates the descriptors of all the tables that use it.
+//
+// A new value cannot be used as soon as the transaction adding it commits,
+// because the nodes still holding a lease on the previous version of a table
+// would not be able to decode it. The value is first marked as being added,
+// in the type and in the columns: it can be decoded but not produced, which
+// also prevents its use in the transaction that adds it, like in postgres.
+// Once the transaction has committed, publishEnumValues waits for each table
+// to have a single version in use before clearing the mark.
+
+// ResolveTypeName implements the tree.TypeResolver interface. User-defined
+// types are looked up in the session database.
+func (p *planner) ResolveTypeName(name string) (types.T, error) {
+	if p.session.Database == "" {
+		return nil, sqlbase.NewUndefinedTypeError(name)
+	}
+	dbDesc, err := MustGetDatabaseDesc(
+		p.session.Ctx(), p.txn, p.getVirtualTabler(), p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+	typDesc, ok := dbDesc.FindType(name)
+	if !ok {
+		return nil, sqlbase.NewUndefinedTypeError(name)
+	}
+	return typDesc.DatumType(), nil
+}
+
+// resolveTypeDatabase returns the descriptor of the session database, in
+// which user-defined types are created.
+func (p *planner) resolveTypeDatabase(ctx context.Context) (*sqlbase.DatabaseDescriptor, error) {
+	dbName := p.session.Database
+	if dbName == "" {
+		return nil, errNoDatabase
+	}
+	if p.session.virtualSchemas.isVirtualDatabase(dbName) {
+		return nil, fmt.Errorf("cannot modify the types of virtual database %q", dbName)
+	}
+	return MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), dbName)
+}
+
+// checkEnumLabel verifies that a label can be used as a value of an ENUM
+// type.
+func checkEnumLabel(label string) error {
+	if label == "" {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"invalid enum label %q", label)
+	}
+	return nil
+}
+
+func newDuplicateEnumLabelError(label string) error {
+	return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+		"enum label %q already exists", label)
+}
+
+// forEachEnumColumn calls fn on the descriptors of all the tables that
+// have a column, or a column being added or dropped, of the given ENUM
+// type.
+func forEachEnumColumn(
+	ctx context.Context,
+	txn *client.Txn,
+	typeID sqlbase.ID,
+	fn func(tbDesc *sqlbase.TableDescriptor, col *sqlbase.ColumnDescriptor) error,
+) error {
+	descs, err := getAllDescriptors(ctx, txn)
+	if err != nil {
+		return err
+	}
+	for _, desc := range descs {
+		tbDesc, ok := desc.(*sqlbase.TableDescriptor)
+		if !ok || tbDesc.Dropped() {
+			continue
+		}
+		if err := forEachTableEnumColumn(tbDesc, typeID, func(col *sqlbase.ColumnDescriptor) error {
+			return fn(tbDesc, col)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachTableEnumColumn calls fn on the columns, and the columns being added
+// or dropped, of the given ENUM type in a table.
+func forEachTableEnumColumn(
+	tbDesc *sqlbase.TableDescriptor, typeID sqlbase.ID, fn func(col *sqlbase.ColumnDescriptor) error,
+) error {
+	isEnumColumn := func(col *sqlbase.ColumnDescriptor) bool {
+		return col.Type.SemanticType == sqlbase.ColumnType_ENUM && col.Type.EnumTypeID == typeID
+	}
+	for i := range tbDesc.Columns {
+		if col := &tbDesc.Columns[i]; isEnumColumn(col) {
+			if err := fn(col); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range tbDesc.Mutations {
+		if col := tbDesc.Mutations[i].GetColumn(); col != nil && isEnumColumn(col) {
+			if err := fn(col); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// enumTypeRef identifies a user-defined ENUM type.
+type enumTypeRef struct {
+	dbID   sqlbase.ID
+	typeID sqlbase.ID
+}
+
+// publishEnumValues makes the values being added to an ENUM type usable. It
+// runs after the transaction that added them has committed. The mark of the
+// values is first cleared from the columns of the type, one table at a time,
+// once all the nodes use the version of the table that knows about them. The
+// type is updated last, which ensures that the columns created in the
+// meantime, which copy the mark from the type, are updated as well.
+func publishEnumValues(ctx context.Context, e *Executor, ref enumTypeRef) error {
+	for {
+		var tableIDs []sqlbase.ID
+		if err := e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			tableIDs = tableIDs[:0]
+			return forEachEnumColumn(ctx, txn, ref.typeID,
+				func(tbDesc *sqlbase.TableDescriptor, col *sqlbase.ColumnDescriptor) error {
+					if len(col.Type.EnumAddingLabels) > 0 &&
+						(len(tableIDs) == 0 || tableIDs[len(tableIDs)-1] != tbDesc.ID) {
+						tableIDs = append(tableIDs, tbDesc.ID)
+					}
+					return nil
+				})
+		}); err != nil {
+			return err
+		}
+
+		for _, id := range tableIDs {
+			// Publish only writes the new version once the previous one,
+			// written by the transaction that added the values, is the only
+			// one in use.
+			if _, err := e.cfg.LeaseManager.Publish(ctx, id, func(tbDesc *sqlbase.TableDescriptor) error {
+				modified := false
+				if err := forEachTableEnumColumn(tbDesc, ref.typeID, func(col *sqlbase.ColumnDescriptor) error {
+					if len(col.Type.EnumAddingLabels) > 0 {
+						col.Type.EnumAddingLabels = nil
+						modified = true
+					}
+					return nil
+				}); err != nil {
+					return err
+				}
+				if !modified {
+					return errDidntUpdateDescriptor
+				}
+				return nil
+			}, nil /* logEvent */); err != nil {
+				return err
+			}
+		}
+
+		done := true
+		if err := e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			done = true
+			if err := txn.SetSystemConfigTrigger(); err != nil {
+				return err
+			}
+			dbDesc, err := getDatabaseDescByID(ctx, txn, ref.dbID)
+			if err != nil || dbDesc == nil {
+				return err
+			}
+			var typDesc *sqlbase.TypeDescriptor
+			for i := range dbDesc.Types {
+				if dbDesc.Types[i].ID == ref.typeID {
+					typDesc = &dbDesc.Types[i]
+				}
+			}
+			if typDesc == nil || len(typDesc.EnumAddingLabels) == 0 {
+				return nil
+			}
+			// Columns created since the tables were scanned need to be
+			// published first.
+			if err := forEachEnumColumn(ctx, txn, ref.typeID,
+				func(_ *sqlbase.TableDescriptor, col *sqlbase.ColumnDescriptor) error {
+					if len(col.Type.EnumAddingLabels) > 0 {
+						done = false
+					}
+					return nil
+				}); err != nil || !done {
+				return err
+			}
+			typDesc.EnumAddingLabels = nil
+			return txn.Put(ctx, sqlbase.MakeDescMetadataKey(dbDesc.ID), sqlbase.WrapDescriptor(dbDesc))
+		}); err != nil {
+			return err
+		}
+		if done {
+			log.VEventf(ctx, 2, "published the new values of type %d", ref.typeID)
+			return nil
+		}
+	}
+}
+
+// CreateType creates a user-defined ENUM type.
+// Privileges: CREATE on database.
+//   Notes: postgres requires CREATE on the schema of the type.
+func (p *planner) CreateType(ctx context.Context, n *tree.CreateType) (planNode, error) {
+	if n.Name == "" {
+		return nil, pgerror.NewError(pgerror.CodeInvalidNameError, "empty type name")
+	}
+	dbDesc, err := p.resolveTypeDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	labels := make(map[string]struct{}, len(n.EnumLabels))
+	for _, label := range n.EnumLabels {
+		if err := checkEnumLabel(label); err != nil {
+			return nil, err
+		}
+		if _, ok := labels[label]; ok {
+			return nil, newDuplicateEnumLabelError(label)
+		}
+		labels[label] = struct{}{}
+	}
+	return &createTypeNode{n: n, dbDesc: dbDesc}, nil
+}
+
+type createTypeNode struct {
+	n      *tree.CreateType
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+func (n *createTypeNode) Start(params runParams) error {
+	name := string(n.n.Name)
+	if _, ok := n.dbDesc.FindType(name); ok {
+		return sqlbase.NewTypeAlreadyExistsError(name)
+	}
+
+	id, err := GenerateUniqueDescID(params.ctx, params.p.session.execCfg.DB)
+	if err != nil {
+		return err
+	}
+
+	n.dbDesc.Types = append(n.dbDesc.Types, sqlbase.TypeDescriptor{
+		Name:             name,
+		ID:               id,
+		EnumLabels:       n.n.EnumLabels,
+		EnumPhysicalReps: sqlbase.GenerateEnumPhysicalReps(len(n.n.EnumLabels)),
+	})
+	return params.p.writeDatabaseDesc(params.ctx, n.dbDesc)
+}
+
+func (*createTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*createTypeNode) Close(context.Context)        {}
+func (*createTypeNode) Values() tree.Datums          { return tree.Datums{} }
+
+// AlterTypeAddValue adds a value to a user-defined ENUM type.
+// Privileges: CREATE on database.
+//   Notes: postgres requires the type owner.
+func (p *planner) AlterTypeAddValue(
+	ctx context.Context, n *tree.AlterTypeAddValue,
+) (planNode, error) {
+	dbDesc, err := p.resolveTypeDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := dbDesc.FindType(string(n.Name)); !ok {
+		return nil, sqlbase.NewUndefinedTypeError(string(n.Name))
+	}
+	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	if err := checkEnumLabel(n.NewVal); err != nil {
+		return nil, err
+	}
+	return &alterTypeNode{n: n, dbDesc: dbDesc}, nil
+}
+
+type alterTypeNode struct {
+	n      *tree.AlterTypeAddValue
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+func (n *alterTypeNode) Start(params runParams) error {
+	typDesc, _ := n.dbDesc.FindType(string(n.n.Name))
+	enumTyp := typDesc.DatumType()
+	if enumTyp.LabelIndex(n.n.NewVal) >= 0 {
+		if n.n.IfNotExists {
+			// Resume the publication of the values left being added by a
+			// node that failed before publishing them.
+			if len(typDesc.EnumAddingLabels) > 0 {
+				params.p.session.TxnState.schemaChangers.queueEnumType(
+					enumTypeRef{dbID: n.dbDesc.ID, typeID: typDesc.ID})
+			}
+			return nil
+		}
+		return newDuplicateEnumLabelError(n.n.NewVal)
+	}
+
+	// Determine the position of the new value.
+	pos := len(typDesc.EnumLabels)
+	if placement := n.n.Placement; placement != nil {
+		idx := enumTyp.LabelIndex(placement.ExistingVal)
+		if idx < 0 {
+			return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"%q is not an existing enum label", placement.ExistingVal)
+		}
+		pos = idx
+		if !placement.Before {
+			pos++
+		}
+	}
+	var prev, next []byte
+	if pos > 0 {
+		prev = typDesc.EnumPhysicalReps[pos-1]
+	}
+	if pos < len(typDesc.EnumPhysicalReps) {
+		next = typDesc.EnumPhysicalReps[pos]
+	}
+	rep := sqlbase.EnumPhysicalRepBetween(prev, next)
+
+	// The existing slices may be shared with the descriptors of tables or
+	// with types resolved earlier in the transaction: build new ones.
+	labels := make([]string, 0, len(typDesc.EnumLabels)+1)
+	labels = append(labels, typDesc.EnumLabels[:pos]...)
+	labels = append(labels, n.n.NewVal)
+	labels = append(labels, typDesc.EnumLabels[pos:]...)
+	reps := make([][]byte, 0, len(typDesc.EnumPhysicalReps)+1)
+	reps = append(reps, typDesc.EnumPhysicalReps[:pos]...)
+	reps = append(reps, rep)
+	reps = append(reps, typDesc.EnumPhysicalReps[pos:]...)
+	adding := make([]string, 0, len(typDesc.EnumAddingLabels)+1)
+	adding = append(adding, typDesc.EnumAddingLabels...)
+	adding = append(adding, n.n.NewVal)
+	typDesc.EnumLabels = labels
+	typDesc.EnumPhysicalReps = reps
+	typDesc.EnumAddingLabels = adding
+	if err := params.p.writeDatabaseDesc(params.ctx, n.dbDesc); err != nil {
+		return err
+	}
+
+	// Update the copies of the values held by the columns of the type.
+	var modified []*sqlbase.TableDescriptor
+	if err := forEachEnumColumn(params.ctx, params.p.txn, typDesc.ID,
+		func(tbDesc *sqlbase.TableDescriptor, col *sqlbase.ColumnDescriptor) error {
+			col.Type.EnumLabels = labels
+			col.Type.EnumPhysicalReps = reps
+			col.Type.EnumAddingLabels = adding
+			if len(modified) == 0 || modified[len(modified)-1] != tbDesc {
+				modified = append(modified, tbDesc)
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	for _, tbDesc := range modified {
+		if err := params.p.saveNonmutationAndNotify(params.ctx, tbDesc); err != nil {
+			return err
+		}
+	}
+	params.p.session.TxnState.schemaChangers.queueEnumType(
+		enumTypeRef{dbID: n.dbDesc.ID, typeID: typDesc.ID})
+	return nil
+}
+
+func (*alterTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterTypeNode) Close(context.Context)        {}
+func (*alterTypeNode) Values() tree.Datums          { return tree.Datums{} }
+
+// DropType drops user-defined types.
+// Privileges: DROP on database.
+//   Notes: postgres requires the type owner.
+func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, error) {
+	dbDesc, err := p.resolveTypeDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []sqlbase.ID
+	for _, name := range n.Names {
+		typDesc, ok := dbDesc.FindType(string(name))
+		if !ok {
+			if n.IfExists {
+				// Noop.
+				continue
+			}
+			return nil, sqlbase.NewUndefinedTypeError(string(name))
+		}
+		if err := p.CheckPrivilege(dbDesc, privilege.DROP); err != nil {
+			return nil, err
+		}
+		if err := forEachEnumColumn(ctx, p.txn, typDesc.ID,
+			func(tbDesc *sqlbase.TableDescriptor, col *sqlbase.ColumnDescriptor) error {
+				err := pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
+					"cannot drop type %q because column %q of table %q depends on it",
+					typDesc.Name, col.Name, tbDesc.Name)
+				if n.DropBehavior == tree.DropCascade {
+					return err.SetHintf("DROP TYPE ... CASCADE is not supported; drop the column first")
+				}
+				return err
+			}); err != nil {
+			return nil, err
+		}
+		ids = append(ids, typDesc.ID)
+	}
+	if len(ids) == 0 {
+		return &zeroNode{}, nil
+	}
+	return &dropTypeNode{dbDesc: dbDesc, ids: ids}, nil
+}
+
+type dropTypeNode struct {
+	dbDesc *sqlbase.DatabaseDescriptor
+	ids    []sqlbase.ID
+}
+
+func (n *dropTypeNode) Start(params runParams) error {
+	for _, id := range n.ids {
+		n.dbDesc.RemoveType(id)
+	}
+	return params.p.writeDatabaseDesc(params.ctx, n.dbDesc)
+}
+
+func (*dropTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropTypeNode) Close(context.Context)        {}
+func (*dropTypeNode) Values() tree.Datums          { return tree.Datums{} }
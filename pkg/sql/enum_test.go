@@ -0,0 +1,116 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestAlterTypeAddValueMultiNode verifies that a value added to an ENUM type
+// cannot be used until all the nodes know about it, and that it can then be
+// used on all of them.
+func TestAlterTypeAddValueMultiNode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numNodes = 3
+	tc := serverutils.StartTestCluster(t, numNodes, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(context.TODO())
+	kvDB := tc.Server(0).KVClient().(*client.DB)
+
+	// Types are resolved in the session database: use a single connection
+	// per node.
+	var runners []*sqlutils.SQLRunner
+	for i := 0; i < numNodes; i++ {
+		db := tc.ServerConn(i)
+		db.SetMaxOpenConns(1)
+		runners = append(runners, sqlutils.MakeSQLRunner(t, db))
+	}
+	runners[0].Exec(`CREATE DATABASE d`)
+	for _, r := range runners {
+		r.Exec(`SET DATABASE = d`)
+	}
+	runners[0].Exec(`CREATE TYPE mood AS ENUM ('sad', 'happy')`)
+	runners[0].Exec(`CREATE TABLE t (k INT PRIMARY KEY, m mood)`)
+	runners[0].Exec(`INSERT INTO t VALUES (1, 'sad')`)
+
+	// The third node holds a lease on the table, which keeps the version of
+	// the table that doesn't know about the new value in use.
+	tx, err := tc.ServerConn(2).Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`SELECT * FROM t`); err != nil {
+		t.Fatal(err)
+	}
+
+	altered := make(chan error, 1)
+	go func() {
+		_, err := tc.ServerConn(1).Exec(`ALTER TYPE mood ADD VALUE 'ok' BEFORE 'happy'`)
+		altered <- err
+	}()
+
+	// Once the first node knows about the value, it refuses to use it.
+	testutils.SucceedsSoon(t, func() error {
+		_, err := tc.ServerConn(0).Exec(`INSERT INTO t VALUES (2, 'ok')`)
+		if !testutils.IsError(err, `unsafe use of new value "ok" of enum type mood`) {
+			return errors.Errorf("unexpected error: %v", err)
+		}
+		return nil
+	})
+	select {
+	case err := <-altered:
+		t.Fatalf("ALTER TYPE returned while the previous version is in use: %v", err)
+	default:
+	}
+	tbDesc := sqlbase.GetTableDescriptor(kvDB, "d", "t")
+	if adding := tbDesc.Columns[1].Type.EnumAddingLabels; !reflect.DeepEqual(adding, []string{"ok"}) {
+		t.Fatalf("expected the value to be added, found %v", adding)
+	}
+
+	// The statement returns once the value has been published.
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-altered; err != nil {
+		t.Fatal(err)
+	}
+	tbDesc = sqlbase.GetTableDescriptor(kvDB, "d", "t")
+	if adding := tbDesc.Columns[1].Type.EnumAddingLabels; len(adding) != 0 {
+		t.Fatalf("expected the value to be published, found %v being added", adding)
+	}
+	if labels := tbDesc.Columns[1].Type.EnumLabels; !reflect.DeepEqual(labels, []string{"sad", "ok", "happy"}) {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+
+	runners[2].Exec(`INSERT INTO t VALUES (2, 'ok')`)
+	runners[1].Exec(`INSERT INTO t VALUES (3, 'happy')`)
+	for _, r := range runners {
+		r.CheckQueryResults(`SELECT k, m FROM t WHERE m < 'happy' ORDER BY m DESC`,
+			[][]string{{"2", "ok"}, {"1", "sad"}})
+		r.CheckQueryResults(`SELECT 'ok'::mood < 'happy'::mood`, [][]string{{"true"}})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+// BatchSize is the maximum number of rows in a batch.
+const BatchSize = 1024
+
+// Batch is a set of column vectors holding the values of up to BatchSize
+// rows.
+//
+// When the selection vector is in use, the rows of the batch are the rows
+// whose indexes are in the first Length() entries of the selection vector;
+// the other rows of the vectors must be ignored. Otherwise, the rows of the
+// batch are the first Length() rows of the vectors.
+type Batch interface {
+	// Length returns the number of rows in the batch.
+	Length() uint16
+	// SetLength sets the number of rows in the batch.
+	SetLength(n uint16)
+	// Width returns the number of columns in the batch.
+	Width() int
+	// ColVec returns the i-th column vector.
+	ColVec(i int) ColVec
+	// ColVecs returns all the column vectors.
+	ColVecs() []ColVec
+	// Selection returns the selection vector, or nil if it isn't in use. The
+	// returned slice has BatchSize entries; it can be modified by the operators
+	// which filter the rows of the batch.
+	Selection() []uint16
+	// SetSelection sets whether the selection vector is in use.
+	SetSelection(bool)
+	// AppendCol appends a column vector of the given type to the batch.
+	AppendCol(t Type)
+}
+
+// memBatch is the Batch implementation which stores the columns in memory.
+type memBatch struct {
+	n      uint16
+	b      []ColVec
+	sel    []uint16
+	useSel bool
+}
+
+var _ Batch = &memBatch{}
+
+// NewMemBatch allocates a batch with columns of the given types.
+func NewMemBatch(types []Type) Batch {
+	b := &memBatch{
+		b:   make([]ColVec, len(types)),
+		sel: make([]uint16, BatchSize),
+	}
+	for i, t := range types {
+		b.b[i] = newMemColumn(t, BatchSize)
+	}
+	return b
+}
+
+// Length is part of the Batch interface.
+func (m *memBatch) Length() uint16 { return m.n }
+
+// SetLength is part of the Batch interface.
+func (m *memBatch) SetLength(n uint16) { m.n = n }
+
+// Width is part of the Batch interface.
+func (m *memBatch) Width() int { return len(m.b) }
+
+// ColVec is part of the Batch interface.
+func (m *memBatch) ColVec(i int) ColVec { return m.b[i] }
+
+// ColVecs is part of the Batch interface.
+func (m *memBatch) ColVecs() []ColVec { return m.b }
+
+// Selection is part of the Batch interface.
+func (m *memBatch) Selection() []uint16 {
+	if !m.useSel {
+		return nil
+	}
+	return m.sel
+}
+
+// SetSelection is part of the Batch interface.
+func (m *memBatch) SetSelection(b bool) { m.useSel = b }
+
+// AppendCol is part of the Batch interface.
+func (m *memBatch) AppendCol(t Type) {
+	m.b = append(m.b, newMemColumn(t, BatchSize))
+}
+
+// projectingBatch is a Batch which exposes a subset of the columns of another
+// batch, possibly in a different order.
+type projectingBatch struct {
+	Batch
+	projection []uint32
+}
+
+var _ Batch = &projectingBatch{}
+
+// Width is part of the Batch interface.
+func (b *projectingBatch) Width() int { return len(b.projection) }
+
+// ColVec is part of the Batch interface.
+func (b *projectingBatch) ColVec(i int) ColVec {
+	return b.Batch.ColVec(int(b.projection[i]))
+}
+
+// ColVecs is part of the Batch interface.
+func (b *projectingBatch) ColVecs() []ColVec {
+	vecs := make([]ColVec, len(b.projection))
+	for i, c := range b.projection {
+		vecs[i] = b.Batch.ColVec(int(c))
+	}
+	return vecs
+}
+
+// AppendCol is part of the Batch interface.
+func (b *projectingBatch) AppendCol(t Type) {
+	b.Batch.AppendCol(t)
+	b.projection = append(b.projection, uint32(b.Batch.Width()-1))
+}
@@ -0,0 +1,281 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// colBuffer accumulates the values of a column across batches, for the
+// operators which need to see all their input (or one side of it) before
+// producing any rows.
+type colBuffer struct {
+	typ Type
+
+	bools  []bool
+	bytes  [][]byte
+	ints   []int64
+	floats []float64
+	nulls  []bool
+}
+
+func (b *colBuffer) len() int { return len(b.nulls) }
+
+// appendRows appends the rows of vec given by sel (or its first n rows if sel
+// is nil) to the buffer. The values are copied: the buffer doesn't keep
+// references to the memory of the batch.
+func (b *colBuffer) appendRows(vec ColVec, sel []uint16, n uint16) {
+	switch b.typ {
+	case Bool:
+		col := vec.Bool()
+		if sel == nil {
+			b.bools = append(b.bools, col[:n]...)
+		} else {
+			for _, i := range sel[:n] {
+				b.bools = append(b.bools, col[i])
+			}
+		}
+	case Bytes:
+		col := vec.Bytes()
+		for i := uint16(0); i < n; i++ {
+			j := i
+			if sel != nil {
+				j = sel[i]
+			}
+			b.bytes = append(b.bytes, append([]byte(nil), col[j]...))
+		}
+	case Int64:
+		col := vec.Int64()
+		if sel == nil {
+			b.ints = append(b.ints, col[:n]...)
+		} else {
+			for _, i := range sel[:n] {
+				b.ints = append(b.ints, col[i])
+			}
+		}
+	case Float64:
+		col := vec.Float64()
+		if sel == nil {
+			b.floats = append(b.floats, col[:n]...)
+		} else {
+			for _, i := range sel[:n] {
+				b.floats = append(b.floats, col[i])
+			}
+		}
+	}
+	hasNulls := vec.HasNulls()
+	for i := uint16(0); i < n; i++ {
+		j := i
+		if sel != nil {
+			j = sel[i]
+		}
+		b.nulls = append(b.nulls, hasNulls && vec.NullAt(j))
+	}
+}
+
+// grow extends the buffer to n rows; the new rows are NULL.
+func (b *colBuffer) grow(n int) {
+	for b.len() < n {
+		switch b.typ {
+		case Bool:
+			b.bools = append(b.bools, false)
+		case Bytes:
+			b.bytes = append(b.bytes, nil)
+		case Int64:
+			b.ints = append(b.ints, 0)
+		case Float64:
+			b.floats = append(b.floats, 0)
+		}
+		b.nulls = append(b.nulls, true)
+	}
+}
+
+// set sets the value of the j-th row of the buffer to the i-th value of vec,
+// which must not be NULL.
+func (b *colBuffer) set(j int, vec ColVec, i uint16) {
+	switch b.typ {
+	case Bool:
+		b.bools[j] = vec.Bool()[i]
+	case Bytes:
+		b.bytes[j] = append(b.bytes[j][:0], vec.Bytes()[i]...)
+	case Int64:
+		b.ints[j] = vec.Int64()[i]
+	case Float64:
+		b.floats[j] = vec.Float64()[i]
+	}
+	b.nulls[j] = false
+}
+
+// copyTo copies the given rows of the buffer to the first len(rows) rows of
+// vec. The nulls of vec must have been unset.
+func (b *colBuffer) copyTo(vec ColVec, rows []int) {
+	switch b.typ {
+	case Bool:
+		col := vec.Bool()
+		for i, j := range rows {
+			col[i] = b.bools[j]
+		}
+	case Bytes:
+		col := vec.Bytes()
+		for i, j := range rows {
+			col[i] = b.bytes[j]
+		}
+	case Int64:
+		col := vec.Int64()
+		for i, j := range rows {
+			col[i] = b.ints[j]
+		}
+	case Float64:
+		col := vec.Float64()
+		for i, j := range rows {
+			col[i] = b.floats[j]
+		}
+	}
+	if b.typ == Unknown {
+		return
+	}
+	for i, j := range rows {
+		if b.nulls[j] {
+			vec.SetNull(uint16(i))
+		}
+	}
+}
+
+// compareFn returns a function which compares two rows of the buffer. NULLs
+// sort before all the other values.
+func (b *colBuffer) compareFn() func(i, j int) int {
+	var cmp func(i, j int) int
+	switch b.typ {
+	case Bool:
+		cmp = func(i, j int) int { return compareBool(b.bools[i], b.bools[j]) }
+	case Bytes:
+		cmp = func(i, j int) int { return bytes.Compare(b.bytes[i], b.bytes[j]) }
+	case Int64:
+		cmp = func(i, j int) int { return compareInt64(b.ints[i], b.ints[j]) }
+	case Float64:
+		cmp = func(i, j int) int { return compareFloat64(b.floats[i], b.floats[j]) }
+	default:
+		cmp = func(i, j int) int { return 0 }
+	}
+	return func(i, j int) int {
+		if ni, nj := b.nulls[i], b.nulls[j]; ni || nj {
+			switch {
+			case ni && nj:
+				return 0
+			case ni:
+				return -1
+			default:
+				return 1
+			}
+		}
+		return cmp(i, j)
+	}
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareFloat64 compares two floats the way SQL does: NaN is equal to itself
+// and smaller than all the other values.
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case a == b:
+		return 0
+	}
+	aNaN, bNaN := math.IsNaN(a), math.IsNaN(b)
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// appendKeys appends to keys[i] an encoding of the value of the i-th row of
+// the batch (the row given by sel[i] if sel is non-nil) in vec. Two non-NULL
+// values have the same encoding if and only if they are equal; all the NULLs
+// have the same encoding. If hasNull is non-nil, hasNull[i] is set for the
+// NULL values.
+func appendKeys(keys [][]byte, hasNull []bool, vec ColVec, sel []uint16, n uint16) {
+	var scratch [8]byte
+	vecHasNulls := vec.HasNulls()
+	for i := uint16(0); i < n; i++ {
+		j := i
+		if sel != nil {
+			j = sel[i]
+		}
+		if vecHasNulls && vec.NullAt(j) {
+			keys[i] = append(keys[i], 0)
+			if hasNull != nil {
+				hasNull[i] = true
+			}
+			continue
+		}
+		keys[i] = append(keys[i], 1)
+		switch vec.Type() {
+		case Bool:
+			if vec.Bool()[j] {
+				keys[i] = append(keys[i], 1)
+			} else {
+				keys[i] = append(keys[i], 0)
+			}
+		case Bytes:
+			v := vec.Bytes()[j]
+			l := binary.PutUvarint(scratch[:], uint64(len(v)))
+			keys[i] = append(append(keys[i], scratch[:l]...), v...)
+		case Int64:
+			binary.BigEndian.PutUint64(scratch[:], uint64(vec.Int64()[j]))
+			keys[i] = append(keys[i], scratch[:]...)
+		case Float64:
+			f := vec.Float64()[j]
+			switch {
+			case f == 0:
+				// -0 is equal to 0.
+				f = 0
+			case math.IsNaN(f):
+				f = math.NaN()
+			}
+			binary.BigEndian.PutUint64(scratch[:], math.Float64bits(f))
+			keys[i] = append(keys[i], scratch[:]...)
+		}
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// AggFunc is an aggregate function.
+type AggFunc int
+
+// Aggregate functions.
+const (
+	// AnyNotNull returns one of the non-NULL values of the group. It is used to
+	// output the values of the grouping columns.
+	AnyNotNull AggFunc = iota
+	BoolAnd
+	BoolOr
+	Count
+	CountRows
+	Max
+	Min
+	// Sum is the sum of a FLOAT column, or the sum of an INT column as an INT.
+	Sum
+)
+
+// Aggregation describes an aggregate function and its argument.
+type Aggregation struct {
+	Func AggFunc
+	// ColIdx is the argument column; it is ignored by CountRows.
+	ColIdx int
+}
+
+// aggState holds the state of an aggregate function for all the groups.
+type aggState interface {
+	// grow extends the state to n groups.
+	grow(n int)
+	// update feeds the rows of the batch given by sel (or its first n rows if
+	// sel is nil); the i-th of these rows belongs to groups[i].
+	update(batch Batch, sel []uint16, n uint16, groups []int)
+	// output writes the results for the given groups to vec.
+	output(vec ColVec, groups []int)
+}
+
+// hashAggregator is an operator which groups the rows of its input using a
+// hash table and computes aggregate functions for each group. It consumes all
+// its input before producing any rows.
+type hashAggregator struct {
+	input     Operator
+	groupCols []int
+
+	aggs    []aggState
+	batch   Batch
+	built   bool
+	groupID map[string]int
+	nGroups int
+
+	// Scratch space, reused across batches.
+	keys   [][]byte
+	groups []int
+
+	// outputGroup is the next group to output.
+	outputGroup int
+	outRows     []int
+}
+
+var _ Operator = &hashAggregator{}
+
+// NewHashAggregator returns an operator which computes the given aggregations
+// over the groups of rows of its input which have the same values in the
+// grouping columns. NULL values are grouped together. If there are no
+// grouping columns, a single row is produced, even if the input is empty.
+//
+// The output contains one column per aggregation. Count and CountRows produce
+// Int64 columns; the other functions produce columns of the type of their
+// argument.
+func NewHashAggregator(
+	input Operator, types []Type, groupCols []int, aggregations []Aggregation,
+) (Operator, error) {
+	h := &hashAggregator{
+		input:     input,
+		groupCols: groupCols,
+		aggs:      make([]aggState, len(aggregations)),
+		groupID:   make(map[string]int),
+	}
+	outTypes := make([]Type, len(aggregations))
+	for i, a := range aggregations {
+		var t Type
+		if a.Func != CountRows {
+			t = types[a.ColIdx]
+		}
+		switch a.Func {
+		case AnyNotNull, Max, Min:
+			if t == Unknown {
+				return nil, fmt.Errorf("unsupported aggregation of %s column", t)
+			}
+			h.aggs[i] = &valueAgg{colIdx: a.ColIdx, fn: a.Func, vals: colBuffer{typ: t}}
+		case BoolAnd, BoolOr:
+			if t != Bool {
+				return nil, fmt.Errorf("unsupported boolean aggregation of %s column", t)
+			}
+			h.aggs[i] = &boolAgg{colIdx: a.ColIdx, and: a.Func == BoolAnd}
+		case Count, CountRows:
+			h.aggs[i] = &countAgg{colIdx: a.ColIdx, rows: a.Func == CountRows}
+			t = Int64
+		case Sum:
+			if t != Int64 && t != Float64 {
+				return nil, fmt.Errorf("unsupported sum of %s column", t)
+			}
+			h.aggs[i] = &sumAgg{colIdx: a.ColIdx, typ: t}
+		default:
+			return nil, fmt.Errorf("unsupported aggregate function %d", a.Func)
+		}
+		outTypes[i] = t
+	}
+	h.batch = NewMemBatch(outTypes)
+	return h, nil
+}
+
+// Init is part of the Operator interface.
+func (h *hashAggregator) Init() {
+	h.input.Init()
+	h.keys = make([][]byte, BatchSize)
+	h.groups = make([]int, BatchSize)
+	h.outRows = make([]int, 0, BatchSize)
+}
+
+// build consumes the input and computes the aggregations.
+func (h *hashAggregator) build() {
+	if len(h.groupCols) == 0 {
+		h.addGroup()
+	}
+	for {
+		batch := h.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			return
+		}
+		sel := batch.Selection()
+		groups := h.groups[:n]
+		if len(h.groupCols) == 0 {
+			for i := range groups {
+				groups[i] = 0
+			}
+		} else {
+			keys := h.keys[:n]
+			for i := range keys {
+				keys[i] = keys[i][:0]
+			}
+			for _, c := range h.groupCols {
+				appendKeys(keys, nil /* hasNull */, batch.ColVec(c), sel, n)
+			}
+			for i, k := range keys {
+				g, ok := h.groupID[string(k)]
+				if !ok {
+					g = h.addGroup()
+					h.groupID[string(k)] = g
+				}
+				groups[i] = g
+			}
+		}
+		for _, a := range h.aggs {
+			a.update(batch, sel, n, groups)
+		}
+	}
+}
+
+func (h *hashAggregator) addGroup() int {
+	h.nGroups++
+	for _, a := range h.aggs {
+		a.grow(h.nGroups)
+	}
+	return h.nGroups - 1
+}
+
+// Next is part of the Operator interface.
+func (h *hashAggregator) Next() Batch {
+	if !h.built {
+		h.build()
+		h.built = true
+	}
+	h.outRows = h.outRows[:0]
+	for g := h.outputGroup; g < h.nGroups && len(h.outRows) < BatchSize; g++ {
+		h.outRows = append(h.outRows, g)
+	}
+	h.outputGroup += len(h.outRows)
+	for i, a := range h.aggs {
+		vec := h.batch.ColVec(i)
+		vec.UnsetNulls()
+		a.output(vec, h.outRows)
+	}
+	h.batch.SetLength(uint16(len(h.outRows)))
+	return h.batch
+}
+
+// rowIdx returns the index in the vectors of the i-th row of a batch.
+func rowIdx(sel []uint16, i uint16) uint16 {
+	if sel != nil {
+		return sel[i]
+	}
+	return i
+}
+
+// countAgg implements Count and CountRows.
+type countAgg struct {
+	colIdx int
+	rows   bool
+	counts []int64
+}
+
+func (a *countAgg) grow(n int) {
+	for len(a.counts) < n {
+		a.counts = append(a.counts, 0)
+	}
+}
+
+func (a *countAgg) update(batch Batch, sel []uint16, n uint16, groups []int) {
+	var nulls Nulls
+	if !a.rows {
+		if vec := batch.ColVec(a.colIdx); vec.HasNulls() {
+			nulls = vec
+		}
+	}
+	for i, g := range groups[:n] {
+		if nulls == nil || !nulls.NullAt(rowIdx(sel, uint16(i))) {
+			a.counts[g]++
+		}
+	}
+}
+
+func (a *countAgg) output(vec ColVec, groups []int) {
+	col := vec.Int64()
+	for i, g := range groups {
+		col[i] = a.counts[g]
+	}
+}
+
+// sumAgg implements Sum.
+type sumAgg struct {
+	colIdx int
+	typ    Type
+	ints   []int64
+	floats []float64
+	seen   []bool
+}
+
+func (a *sumAgg) grow(n int) {
+	for len(a.seen) < n {
+		a.ints = append(a.ints, 0)
+		a.floats = append(a.floats, 0)
+		a.seen = append(a.seen, false)
+	}
+}
+
+func (a *sumAgg) update(batch Batch, sel []uint16, n uint16, groups []int) {
+	vec := batch.ColVec(a.colIdx)
+	hasNulls := vec.HasNulls()
+	switch a.typ {
+	case Int64:
+		col := vec.Int64()
+		for i, g := range groups[:n] {
+			j := rowIdx(sel, uint16(i))
+			if hasNulls && vec.NullAt(j) {
+				continue
+			}
+			v := col[j]
+			r := a.ints[g] + v
+			if (r < a.ints[g]) != (v < 0) {
+				RaiseError(errIntOutOfRange)
+			}
+			a.ints[g] = r
+			a.seen[g] = true
+		}
+	case Float64:
+		col := vec.Float64()
+		for i, g := range groups[:n] {
+			j := rowIdx(sel, uint16(i))
+			if hasNulls && vec.NullAt(j) {
+				continue
+			}
+			a.floats[g] += col[j]
+			a.seen[g] = true
+		}
+	}
+}
+
+func (a *sumAgg) output(vec ColVec, groups []int) {
+	for i, g := range groups {
+		if !a.seen[g] {
+			vec.SetNull(uint16(i))
+			continue
+		}
+		if a.typ == Int64 {
+			vec.Int64()[i] = a.ints[g]
+		} else {
+			vec.Float64()[i] = a.floats[g]
+		}
+	}
+}
+
+// boolAgg implements BoolAnd and BoolOr.
+type boolAgg struct {
+	colIdx int
+	and    bool
+	vals   []bool
+	seen   []bool
+}
+
+func (a *boolAgg) grow(n int) {
+	for len(a.seen) < n {
+		a.vals = append(a.vals, a.and)
+		a.seen = append(a.seen, false)
+	}
+}
+
+func (a *boolAgg) update(batch Batch, sel []uint16, n uint16, groups []int) {
+	vec := batch.ColVec(a.colIdx)
+	hasNulls := vec.HasNulls()
+	col := vec.Bool()
+	for i, g := range groups[:n] {
+		j := rowIdx(sel, uint16(i))
+		if hasNulls && vec.NullAt(j) {
+			continue
+		}
+		if a.and {
+			a.vals[g] = a.vals[g] && col[j]
+		} else {
+			a.vals[g] = a.vals[g] || col[j]
+		}
+		a.seen[g] = true
+	}
+}
+
+func (a *boolAgg) output(vec ColVec, groups []int) {
+	col := vec.Bool()
+	for i, g := range groups {
+		if !a.seen[g] {
+			vec.SetNull(uint16(i))
+			continue
+		}
+		col[i] = a.vals[g]
+	}
+}
+
+// valueAgg implements the aggregate functions which return one of the values
+// of the group: AnyNotNull, Max and Min.
+type valueAgg struct {
+	colIdx int
+	fn     AggFunc
+	// vals holds the current value of each group; it is NULL until a non-NULL
+	// value is seen.
+	vals colBuffer
+	cmp  func(vec ColVec, i uint16, g int) int
+}
+
+func (a *valueAgg) grow(n int) {
+	a.vals.grow(n)
+}
+
+// compareFn returns a function which compares the i-th value of vec with the
+// current value of a group.
+func (a *valueAgg) compareFn() func(vec ColVec, i uint16, g int) int {
+	switch a.vals.typ {
+	case Bool:
+		return func(vec ColVec, i uint16, g int) int { return compareBool(vec.Bool()[i], a.vals.bools[g]) }
+	case Bytes:
+		return func(vec ColVec, i uint16, g int) int { return bytes.Compare(vec.Bytes()[i], a.vals.bytes[g]) }
+	case Int64:
+		return func(vec ColVec, i uint16, g int) int { return compareInt64(vec.Int64()[i], a.vals.ints[g]) }
+	case Float64:
+		return func(vec ColVec, i uint16, g int) int {
+			return compareFloat64(vec.Float64()[i], a.vals.floats[g])
+		}
+	}
+	panic(fmt.Sprintf("unhandled type %s", a.vals.typ))
+}
+
+func (a *valueAgg) update(batch Batch, sel []uint16, n uint16, groups []int) {
+	if a.cmp == nil {
+		a.cmp = a.compareFn()
+	}
+	vec := batch.ColVec(a.colIdx)
+	hasNulls := vec.HasNulls()
+	for i, g := range groups[:n] {
+		j := rowIdx(sel, uint16(i))
+		if hasNulls && vec.NullAt(j) {
+			continue
+		}
+		if !a.vals.nulls[g] {
+			switch a.fn {
+			case AnyNotNull:
+				continue
+			case Max:
+				if a.cmp(vec, j, g) <= 0 {
+					continue
+				}
+			case Min:
+				if a.cmp(vec, j, g) >= 0 {
+					continue
+				}
+			}
+		}
+		a.vals.set(g, vec, j)
+	}
+}
+
+func (a *valueAgg) output(vec ColVec, groups []int) {
+	a.vals.copyTo(vec, groups)
+}
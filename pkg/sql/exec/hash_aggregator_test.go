@@ -0,0 +1,135 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestHashAggregator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	input := tuples{
+		{"a", int64(1), 1.5, true},
+		{"b", int64(2), nil, false},
+		{"a", nil, 2.5, false},
+		{nil, int64(4), -1.0, nil},
+		{"b", int64(-3), 0.5, false},
+		{nil, int64(6), nil, true},
+		{"a", int64(7), -2.0, true},
+	}
+	types := []Type{Bytes, Int64, Float64, Bool}
+
+	testCases := []struct {
+		desc      string
+		input     tuples
+		groupCols []int
+		aggs      []Aggregation
+		expected  tuples
+	}{
+		{
+			desc:      "grouped",
+			input:     input,
+			groupCols: []int{0},
+			aggs: []Aggregation{
+				{Func: AnyNotNull, ColIdx: 0},
+				{Func: CountRows},
+				{Func: Count, ColIdx: 1},
+				{Func: Sum, ColIdx: 1},
+				{Func: Sum, ColIdx: 2},
+				{Func: Min, ColIdx: 1},
+				{Func: Max, ColIdx: 2},
+				{Func: BoolAnd, ColIdx: 3},
+				{Func: BoolOr, ColIdx: 3},
+			},
+			expected: tuples{
+				{"a", int64(3), int64(2), int64(8), 2.0, int64(1), 2.5, false, true},
+				{"b", int64(2), int64(2), int64(-1), 0.5, int64(-3), 0.5, false, false},
+				{nil, int64(2), int64(2), int64(10), -1.0, int64(4), -1.0, true, true},
+			},
+		},
+		{
+			desc:      "scalar",
+			input:     input,
+			groupCols: nil,
+			aggs: []Aggregation{
+				{Func: CountRows},
+				{Func: Max, ColIdx: 0},
+				{Func: Sum, ColIdx: 1},
+			},
+			expected: tuples{{int64(7), "b", int64(17)}},
+		},
+		{
+			desc:      "scalar on empty input",
+			input:     nil,
+			groupCols: nil,
+			aggs: []Aggregation{
+				{Func: CountRows},
+				{Func: Count, ColIdx: 1},
+				{Func: Min, ColIdx: 0},
+				{Func: Sum, ColIdx: 2},
+			},
+			expected: tuples{{int64(0), int64(0), nil, nil}},
+		},
+		{
+			desc:      "grouped on empty input",
+			input:     nil,
+			groupCols: []int{0},
+			aggs:      []Aggregation{{Func: CountRows}},
+			expected:  nil,
+		},
+		{
+			desc:      "multiple grouping columns",
+			input:     input,
+			groupCols: []int{3, 0},
+			aggs:      []Aggregation{{Func: AnyNotNull, ColIdx: 3}, {Func: CountRows}},
+			expected: tuples{
+				{true, int64(2)},
+				{false, int64(1)},
+				{false, int64(2)},
+				{nil, int64(1)},
+				{true, int64(1)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			runTests(t, []tuples{tc.input}, [][]Type{types}, tc.expected, false, /* ordered */
+				func(inputs []Operator) (Operator, error) {
+					return NewHashAggregator(inputs[0], types, tc.groupCols, tc.aggs)
+				})
+		})
+	}
+
+	t.Run("overflow", func(t *testing.T) {
+		src := newOpTestInput(BatchSize, []Type{Int64}, tuples{{int64(math.MaxInt64)}, {int64(1)}})
+		op, err := NewHashAggregator(src, []Type{Int64}, nil, []Aggregation{{Func: Sum}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := collect(op); !testutils.IsError(err, "integer out of range") {
+			t.Fatalf("expected overflow error, got %v", err)
+		}
+	})
+
+	if _, err := NewHashAggregator(nil, types, nil, []Aggregation{{Func: Sum, ColIdx: 0}}); err == nil {
+		t.Error("expected error for the sum of a bytes column")
+	}
+}
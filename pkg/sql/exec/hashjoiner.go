@@ -0,0 +1,194 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import "fmt"
+
+// hashJoiner is an operator which computes the inner equality join of two
+// inputs. The right input (the build side) is stored in a hash table; the
+// batches of the left input (the probe side) are then joined one at a time.
+type hashJoiner struct {
+	left, right           Operator
+	leftTypes, rightTypes []Type
+	leftEqCols            []int
+	rightEqCols           []int
+
+	// buildCols holds the rows of the right input.
+	buildCols []colBuffer
+	// buckets maps the encoding of the equality columns to the indexes of the
+	// rows of the right input which have these values. The rows with NULLs in
+	// the equality columns, which can't match any row, are not stored.
+	buckets      map[string][]int
+	numBuildRows int
+	built        bool
+
+	// probe is the current batch of the left input; probeIdx is the index of
+	// the next row of the batch to be joined, and matchIdx the index of its
+	// next match in its bucket.
+	probe    Batch
+	probeSel []uint16
+	probeLen uint16
+	probeIdx uint16
+	matchIdx int
+	keys     [][]byte
+	hasNull  []bool
+
+	// The pairs of rows which form the next output batch.
+	outProbe []uint16
+	outBuild []int
+
+	batch Batch
+}
+
+var _ Operator = &hashJoiner{}
+
+// NewHashJoiner returns an operator which outputs the pairs of rows of its
+// two inputs which have equal values in the given equality columns. The
+// output rows contain the columns of the left input followed by the columns
+// of the right input. The right input is consumed before any row is produced.
+func NewHashJoiner(
+	left, right Operator, leftTypes, rightTypes []Type, leftEqCols, rightEqCols []int,
+) (Operator, error) {
+	if len(leftEqCols) != len(rightEqCols) {
+		return nil, fmt.Errorf("mismatched number of equality columns")
+	}
+	for i := range leftEqCols {
+		l, r := leftTypes[leftEqCols[i]], rightTypes[rightEqCols[i]]
+		if l != r || l == Unknown {
+			return nil, fmt.Errorf("unsupported equality between %s and %s columns", l, r)
+		}
+	}
+	h := &hashJoiner{
+		left:        left,
+		right:       right,
+		leftTypes:   leftTypes,
+		rightTypes:  rightTypes,
+		leftEqCols:  leftEqCols,
+		rightEqCols: rightEqCols,
+		buildCols:   make([]colBuffer, len(rightTypes)),
+		buckets:     make(map[string][]int),
+	}
+	for i, t := range rightTypes {
+		h.buildCols[i].typ = t
+	}
+	return h, nil
+}
+
+// Init is part of the Operator interface.
+func (h *hashJoiner) Init() {
+	h.left.Init()
+	h.right.Init()
+	h.keys = make([][]byte, BatchSize)
+	h.hasNull = make([]bool, BatchSize)
+	h.outProbe = make([]uint16, 0, BatchSize)
+	h.outBuild = make([]int, 0, BatchSize)
+	h.batch = NewMemBatch(append(append([]Type(nil), h.leftTypes...), h.rightTypes...))
+}
+
+// computeKeys encodes the values of the given equality columns of the batch
+// into h.keys, and marks the rows which have NULLs in h.hasNull.
+func (h *hashJoiner) computeKeys(batch Batch, eqCols []int) {
+	n := batch.Length()
+	sel := batch.Selection()
+	for i := uint16(0); i < n; i++ {
+		h.keys[i] = h.keys[i][:0]
+		h.hasNull[i] = false
+	}
+	for _, c := range eqCols {
+		appendKeys(h.keys[:n], h.hasNull[:n], batch.ColVec(c), sel, n)
+	}
+}
+
+// build consumes the right input and fills the hash table.
+func (h *hashJoiner) build() {
+	for {
+		batch := h.right.Next()
+		n := batch.Length()
+		if n == 0 {
+			return
+		}
+		h.computeKeys(batch, h.rightEqCols)
+		for i, k := range h.keys[:n] {
+			if !h.hasNull[i] {
+				h.buckets[string(k)] = append(h.buckets[string(k)], h.numBuildRows+i)
+			}
+		}
+		h.numBuildRows += int(n)
+		sel := batch.Selection()
+		for i := range h.buildCols {
+			h.buildCols[i].appendRows(batch.ColVec(i), sel, n)
+		}
+	}
+}
+
+// Next is part of the Operator interface.
+func (h *hashJoiner) Next() Batch {
+	if !h.built {
+		h.build()
+		h.built = true
+	}
+	h.outProbe = h.outProbe[:0]
+	h.outBuild = h.outBuild[:0]
+	for len(h.outProbe) == 0 {
+		if h.probeIdx >= h.probeLen {
+			h.probe = h.left.Next()
+			h.probeLen = h.probe.Length()
+			h.probeSel = h.probe.Selection()
+			h.probeIdx = 0
+			h.matchIdx = 0
+			if h.probeLen == 0 {
+				h.batch.SetLength(0)
+				return h.batch
+			}
+			h.computeKeys(h.probe, h.leftEqCols)
+		}
+		for ; h.probeIdx < h.probeLen; h.probeIdx++ {
+			if h.hasNull[h.probeIdx] {
+				continue
+			}
+			matches := h.buckets[string(h.keys[h.probeIdx])]
+			for ; h.matchIdx < len(matches); h.matchIdx++ {
+				if len(h.outProbe) == BatchSize {
+					h.emit()
+					return h.batch
+				}
+				h.outProbe = append(h.outProbe, rowIdx(h.probeSel, h.probeIdx))
+				h.outBuild = append(h.outBuild, matches[h.matchIdx])
+			}
+			h.matchIdx = 0
+		}
+	}
+	h.emit()
+	return h.batch
+}
+
+// emit copies the pairs of rows in outProbe and outBuild to the output batch.
+// The rows of the probe batch must be copied before the next batch is
+// requested from the left input.
+func (h *hashJoiner) emit() {
+	n := uint16(len(h.outProbe))
+	nLeft := len(h.leftTypes)
+	for i := range h.leftTypes {
+		vec := h.batch.ColVec(i)
+		vec.UnsetNulls()
+		copyVec(vec, h.probe.ColVec(i), h.outProbe, n)
+	}
+	for i := range h.rightTypes {
+		vec := h.batch.ColVec(nLeft + i)
+		vec.UnsetNulls()
+		h.buildCols[i].copyTo(vec, h.outBuild)
+	}
+	h.batch.SetLength(n)
+}
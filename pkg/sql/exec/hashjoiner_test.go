@@ -0,0 +1,143 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestHashJoiner(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		desc        string
+		left, right tuples
+		leftTypes   []Type
+		rightTypes  []Type
+		leftEqCols  []int
+		rightEqCols []int
+		expected    tuples
+	}{
+		{
+			desc: "single equality column",
+			left: tuples{
+				{int64(1), "a"},
+				{int64(2), "b"},
+				{nil, "c"},
+				{int64(1), "d"},
+			},
+			right: tuples{
+				{int64(1), 10.0},
+				{nil, 20.0},
+				{int64(1), 30.0},
+				{int64(3), 40.0},
+			},
+			leftTypes:   []Type{Int64, Bytes},
+			rightTypes:  []Type{Int64, Float64},
+			leftEqCols:  []int{0},
+			rightEqCols: []int{0},
+			expected: tuples{
+				{int64(1), "a", int64(1), 10.0},
+				{int64(1), "a", int64(1), 30.0},
+				{int64(1), "d", int64(1), 10.0},
+				{int64(1), "d", int64(1), 30.0},
+			},
+		},
+		{
+			desc: "multiple equality columns",
+			left: tuples{
+				{"x", int64(1)},
+				{"x", int64(2)},
+				{"y", int64(1)},
+			},
+			right: tuples{
+				{int64(1), "x", true},
+				{int64(1), "y", false},
+				{int64(2), "y", nil},
+			},
+			leftTypes:   []Type{Bytes, Int64},
+			rightTypes:  []Type{Int64, Bytes, Bool},
+			leftEqCols:  []int{0, 1},
+			rightEqCols: []int{1, 0},
+			expected: tuples{
+				{"x", int64(1), int64(1), "x", true},
+				{"y", int64(1), int64(1), "y", false},
+			},
+		},
+		{
+			desc:        "empty build side",
+			left:        tuples{{int64(1)}},
+			right:       nil,
+			leftTypes:   []Type{Int64},
+			rightTypes:  []Type{Int64},
+			leftEqCols:  []int{0},
+			rightEqCols: []int{0},
+			expected:    nil,
+		},
+	}
+
+	// A join whose output doesn't fit in one batch.
+	var left, right, expected tuples
+	for i := 0; i < 3; i++ {
+		left = append(left, tuple{int64(i % 2)})
+	}
+	for i := 0; i < 1000; i++ {
+		right = append(right, tuple{int64(0), int64(i)})
+	}
+	for i := 0; i < 3; i += 2 {
+		for j := 0; j < 1000; j++ {
+			expected = append(expected, tuple{int64(0), int64(0), int64(j)})
+		}
+	}
+	testCases = append(testCases, struct {
+		desc        string
+		left, right tuples
+		leftTypes   []Type
+		rightTypes  []Type
+		leftEqCols  []int
+		rightEqCols []int
+		expected    tuples
+	}{
+		desc:        "large output",
+		left:        left,
+		right:       right,
+		leftTypes:   []Type{Int64},
+		rightTypes:  []Type{Int64, Int64},
+		leftEqCols:  []int{0},
+		rightEqCols: []int{0},
+		expected:    expected,
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			runTests(
+				t, []tuples{tc.left, tc.right}, [][]Type{tc.leftTypes, tc.rightTypes},
+				tc.expected, false, /* ordered */
+				func(inputs []Operator) (Operator, error) {
+					return NewHashJoiner(
+						inputs[0], inputs[1], tc.leftTypes, tc.rightTypes, tc.leftEqCols, tc.rightEqCols,
+					)
+				})
+		})
+	}
+
+	if _, err := NewHashJoiner(
+		nil, nil, []Type{Int64}, []Type{Float64}, []int{0}, []int{0},
+	); err == nil {
+		t.Error("expected error for an equality between int and float columns")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+// Operator is a vectorized operator: it produces the rows of its result one
+// batch at a time.
+type Operator interface {
+	// Init initializes the operator and its inputs. It is called once, before
+	// the first call to Next.
+	Init()
+
+	// Next returns the next batch of rows. A batch of length 0 indicates that
+	// there are no more rows. The returned batch is owned by the operator (or
+	// one of its inputs); it is only valid until the next call to Next, and can
+	// only be modified by filtering its rows or appending columns to it.
+	//
+	// Errors are raised with RaiseError.
+	Next() Batch
+}
+
+// execError wraps the errors raised by the operators.
+type execError struct {
+	err error
+}
+
+// RaiseError aborts the execution of the operators with the given error. The
+// operators don't return errors: an operator which encounters one panics, and
+// the panic is recovered by CatchError at the root of the operator tree.
+func RaiseError(err error) {
+	panic(execError{err: err})
+}
+
+// CatchError runs fn and returns the error raised with RaiseError during its
+// execution, if any. Other panics are propagated.
+func CatchError(fn func()) (retErr error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(execError)
+			if !ok {
+				panic(r)
+			}
+			retErr = e.err
+		}
+	}()
+	fn()
+	return nil
+}
+
+// simpleProjectOp is an operator which projects a subset of the columns of
+// its input, possibly in a different order.
+type simpleProjectOp struct {
+	input Operator
+	batch *projectingBatch
+}
+
+var _ Operator = &simpleProjectOp{}
+
+// NewSimpleProjectOp returns an operator which outputs the given columns of
+// its input.
+func NewSimpleProjectOp(input Operator, projection []uint32) Operator {
+	return &simpleProjectOp{
+		input: input,
+		batch: &projectingBatch{projection: append([]uint32(nil), projection...)},
+	}
+}
+
+// Init is part of the Operator interface.
+func (p *simpleProjectOp) Init() {
+	p.input.Init()
+}
+
+// Next is part of the Operator interface.
+func (p *simpleProjectOp) Next() Batch {
+	p.batch.Batch = p.input.Next()
+	return p.batch
+}
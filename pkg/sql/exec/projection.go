@@ -0,0 +1,236 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+var errIntOutOfRange = pgerror.NewError(
+	pgerror.CodeNumericValueOutOfRangeError, "integer out of range",
+)
+
+// BinOp is an arithmetic operator.
+type BinOp int
+
+// Arithmetic operators.
+const (
+	Plus BinOp = iota
+	Minus
+	Mult
+	Div
+)
+
+// projOp is an operator which appends to the batches of its input a column
+// holding the result of an arithmetic operation between a column and a
+// constant or between two columns. The result is NULL if any of the operands
+// is NULL.
+type projOp struct {
+	input Operator
+	typ   Type
+	op    BinOp
+
+	colIdx int
+	// Either col2Idx is set (to a non-negative value) or the constant
+	// corresponding to typ.
+	col2Idx    int
+	constInt   int64
+	constFloat float64
+
+	outputIdx int
+}
+
+var _ Operator = &projOp{}
+
+func checkBinOp(t Type, op BinOp) error {
+	switch t {
+	case Int64:
+		if op == Div {
+			// Integer division results in a DECIMAL.
+			return fmt.Errorf("unsupported division of %s values", t)
+		}
+		return nil
+	case Float64:
+		return nil
+	}
+	return fmt.Errorf("unsupported arithmetic on %s values", t)
+}
+
+// NewProjConstOp returns an operator which appends to the batches of its
+// input, as column outputIdx, the result of the operation between the given
+// column, of type t, and the constant. The constant must be an int64 or a
+// float64 depending on the type. The result is of type t.
+//
+// outputIdx must be the number of columns of the batches of the input.
+func NewProjConstOp(
+	input Operator, t Type, colIdx int, op BinOp, constant interface{}, outputIdx int,
+) (Operator, error) {
+	if err := checkBinOp(t, op); err != nil {
+		return nil, err
+	}
+	p := &projOp{input: input, typ: t, op: op, colIdx: colIdx, col2Idx: -1, outputIdx: outputIdx}
+	var ok bool
+	switch t {
+	case Int64:
+		p.constInt, ok = constant.(int64)
+	case Float64:
+		p.constFloat, ok = constant.(float64)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unsupported operation between %s column and %T", t, constant)
+	}
+	return p, nil
+}
+
+// NewProjColOp returns an operator which appends to the batches of its input,
+// as column outputIdx, the result of the operation between the two given
+// columns, of type t. The result is of type t.
+//
+// outputIdx must be the number of columns of the batches of the input.
+func NewProjColOp(
+	input Operator, t Type, col1Idx, col2Idx int, op BinOp, outputIdx int,
+) (Operator, error) {
+	if err := checkBinOp(t, op); err != nil {
+		return nil, err
+	}
+	return &projOp{
+		input: input, typ: t, op: op, colIdx: col1Idx, col2Idx: col2Idx, outputIdx: outputIdx,
+	}, nil
+}
+
+// Init is part of the Operator interface.
+func (p *projOp) Init() {
+	p.input.Init()
+}
+
+// Next is part of the Operator interface.
+func (p *projOp) Next() Batch {
+	batch := p.input.Next()
+	if batch.Width() == p.outputIdx {
+		batch.AppendCol(p.typ)
+	}
+	n := batch.Length()
+	if n == 0 {
+		return batch
+	}
+	sel := batch.Selection()
+	out := batch.ColVec(p.outputIdx)
+	out.UnsetNulls()
+
+	vec := batch.ColVec(p.colIdx)
+	var vec2 ColVec
+	if p.col2Idx >= 0 {
+		vec2 = batch.ColVec(p.col2Idx)
+	}
+	// The result is NULL if any of the operands is NULL; the operation is only
+	// evaluated for the other rows.
+	for _, v := range []ColVec{vec, vec2} {
+		if v == nil || !v.HasNulls() {
+			continue
+		}
+		for k := uint16(0); k < n; k++ {
+			i := k
+			if sel != nil {
+				i = sel[k]
+			}
+			if v.NullAt(i) {
+				out.SetNull(i)
+			}
+		}
+	}
+	hasNulls := out.HasNulls()
+
+	switch p.typ {
+	case Int64:
+		col, res := vec.Int64(), out.Int64()
+		var col2 []int64
+		if vec2 != nil {
+			col2 = vec2.Int64()
+		}
+		for k := uint16(0); k < n; k++ {
+			i := k
+			if sel != nil {
+				i = sel[k]
+			}
+			if hasNulls && out.NullAt(i) {
+				continue
+			}
+			b := p.constInt
+			if col2 != nil {
+				b = col2[i]
+			}
+			res[i] = p.evalInt64(col[i], b)
+		}
+	case Float64:
+		col, res := vec.Float64(), out.Float64()
+		var col2 []float64
+		if vec2 != nil {
+			col2 = vec2.Float64()
+		}
+		for k := uint16(0); k < n; k++ {
+			i := k
+			if sel != nil {
+				i = sel[k]
+			}
+			if hasNulls && out.NullAt(i) {
+				continue
+			}
+			b := p.constFloat
+			if col2 != nil {
+				b = col2[i]
+			}
+			res[i] = p.evalFloat64(col[i], b)
+		}
+	}
+	return batch
+}
+
+func (p *projOp) evalInt64(a, b int64) int64 {
+	var r int64
+	switch p.op {
+	case Plus:
+		r = a + b
+		if (r < a) != (b < 0) {
+			RaiseError(errIntOutOfRange)
+		}
+	case Minus:
+		r = a - b
+		if (r < a) != (b > 0) {
+			RaiseError(errIntOutOfRange)
+		}
+	case Mult:
+		r = a * b
+		if a != 0 && (r/a != b || (a == -1 && b == -1<<63)) {
+			RaiseError(errIntOutOfRange)
+		}
+	}
+	return r
+}
+
+func (p *projOp) evalFloat64(a, b float64) float64 {
+	switch p.op {
+	case Plus:
+		return a + b
+	case Minus:
+		return a - b
+	case Mult:
+		return a * b
+	case Div:
+		return a / b
+	}
+	panic(fmt.Sprintf("invalid arithmetic operator %d", p.op))
+}
@@ -0,0 +1,130 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestProjOps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	input := tuples{
+		{int64(1), int64(3), 0.5},
+		{int64(2), nil, 2.0},
+		{nil, int64(1), nil},
+		{int64(-4), int64(6), -1.0},
+	}
+	types := []Type{Int64, Int64, Float64}
+
+	testCases := []struct {
+		desc     string
+		expected tuples
+		mkOp     func(input Operator) (Operator, error)
+	}{
+		{
+			desc: "int + const",
+			expected: tuples{
+				{int64(1), int64(3), 0.5, int64(11)},
+				{int64(2), nil, 2.0, int64(12)},
+				{nil, int64(1), nil, nil},
+				{int64(-4), int64(6), -1.0, int64(6)},
+			},
+			mkOp: func(input Operator) (Operator, error) {
+				return NewProjConstOp(input, Int64, 0, Plus, int64(10), 3)
+			},
+		},
+		{
+			desc: "int * col",
+			expected: tuples{
+				{int64(1), int64(3), 0.5, int64(3)},
+				{int64(2), nil, 2.0, nil},
+				{nil, int64(1), nil, nil},
+				{int64(-4), int64(6), -1.0, int64(-24)},
+			},
+			mkOp: func(input Operator) (Operator, error) {
+				return NewProjColOp(input, Int64, 0, 1, Mult, 3)
+			},
+		},
+		{
+			desc: "float / const, filtered",
+			expected: tuples{
+				{int64(2), nil, 2.0, 0.5},
+				{int64(-4), int64(6), -1.0, -0.25},
+			},
+			mkOp: func(input Operator) (Operator, error) {
+				sel, err := NewSelConstOp(input, Float64, 2, GT, -2.0)
+				if err != nil {
+					return nil, err
+				}
+				sel, err = NewSelConstOp(sel, Float64, 2, NE, 0.5)
+				if err != nil {
+					return nil, err
+				}
+				return NewProjConstOp(sel, Float64, 2, Div, 4.0, 3)
+			},
+		},
+		{
+			desc: "simple projection",
+			expected: tuples{
+				{0.5, int64(1), int64(1)},
+				{2.0, int64(2), int64(2)},
+				{nil, nil, nil},
+				{-1.0, int64(-4), int64(-4)},
+			},
+			mkOp: func(input Operator) (Operator, error) {
+				return NewSimpleProjectOp(input, []uint32{2, 0, 0}), nil
+			},
+		},
+		{
+			desc: "projection on top of simple projection",
+			expected: tuples{
+				{int64(3), int64(1), int64(2)},
+				{nil, int64(2), nil},
+				{int64(1), nil, nil},
+				{int64(6), int64(-4), int64(10)},
+			},
+			mkOp: func(input Operator) (Operator, error) {
+				return NewProjColOp(NewSimpleProjectOp(input, []uint32{1, 0}), Int64, 0, 1, Minus, 2)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			runTests(t, []tuples{input}, [][]Type{types}, tc.expected, true, /* ordered */
+				func(inputs []Operator) (Operator, error) { return tc.mkOp(inputs[0]) })
+		})
+	}
+
+	t.Run("overflow", func(t *testing.T) {
+		src := newOpTestInput(BatchSize, []Type{Int64}, tuples{{int64(math.MaxInt64)}})
+		op, err := NewProjConstOp(src, Int64, 0, Plus, int64(1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := collect(op); !testutils.IsError(err, "integer out of range") {
+			t.Fatalf("expected overflow error, got %v", err)
+		}
+	})
+
+	if _, err := NewProjColOp(nil, Int64, 0, 1, Div, 2); err == nil {
+		t.Error("expected error for integer division")
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CmpOp is a comparison operator.
+type CmpOp int
+
+// Comparison operators.
+const (
+	EQ CmpOp = iota
+	NE
+	LT
+	LE
+	GT
+	GE
+)
+
+// holds returns true if the comparison holds for two values whose comparison
+// function returned cmp.
+func (op CmpOp) holds(cmp int) bool {
+	switch op {
+	case EQ:
+		return cmp == 0
+	case NE:
+		return cmp != 0
+	case LT:
+		return cmp < 0
+	case LE:
+		return cmp <= 0
+	case GT:
+		return cmp > 0
+	case GE:
+		return cmp >= 0
+	}
+	panic(fmt.Sprintf("invalid comparison operator %d", op))
+}
+
+// selOp is an operator which filters the rows of its input using a
+// comparison between a column and a constant or between two columns. The rows
+// for which the comparison is NULL are filtered out.
+type selOp struct {
+	input Operator
+	typ   Type
+	op    CmpOp
+
+	colIdx int
+	// Either col2Idx is set (to a non-negative value) or one of the constants
+	// corresponding to typ.
+	col2Idx    int
+	constBool  bool
+	constBytes []byte
+	constInt   int64
+	constFloat float64
+}
+
+var _ Operator = &selOp{}
+
+// NewSelConstOp returns an operator which filters out the rows of its input
+// for which the comparison between the given column, of type t, and the
+// constant doesn't hold. The constant must be a bool, []byte, int64 or float64
+// depending on the type.
+func NewSelConstOp(
+	input Operator, t Type, colIdx int, op CmpOp, constant interface{},
+) (Operator, error) {
+	s := &selOp{input: input, typ: t, op: op, colIdx: colIdx, col2Idx: -1}
+	var ok bool
+	switch t {
+	case Bool:
+		s.constBool, ok = constant.(bool)
+	case Bytes:
+		s.constBytes, ok = constant.([]byte)
+	case Int64:
+		s.constInt, ok = constant.(int64)
+	case Float64:
+		s.constFloat, ok = constant.(float64)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unsupported comparison of %s column with %T", t, constant)
+	}
+	return s, nil
+}
+
+// NewSelColOp returns an operator which filters out the rows of its input for
+// which the comparison between the two given columns, of type t, doesn't
+// hold.
+func NewSelColOp(input Operator, t Type, col1Idx, col2Idx int, op CmpOp) (Operator, error) {
+	if t == Unknown {
+		return nil, fmt.Errorf("unsupported comparison of %s columns", t)
+	}
+	return &selOp{input: input, typ: t, op: op, colIdx: col1Idx, col2Idx: col2Idx}, nil
+}
+
+// Init is part of the Operator interface.
+func (s *selOp) Init() {
+	s.input.Init()
+}
+
+// compareFn returns a function which compares the i-th value of the column
+// with the constant or with the i-th value of the second column.
+func (s *selOp) compareFn(batch Batch) func(i uint16) int {
+	vec := batch.ColVec(s.colIdx)
+	var vec2 ColVec
+	if s.col2Idx >= 0 {
+		vec2 = batch.ColVec(s.col2Idx)
+	}
+	switch s.typ {
+	case Bool:
+		col := vec.Bool()
+		if vec2 != nil {
+			col2 := vec2.Bool()
+			return func(i uint16) int { return compareBool(col[i], col2[i]) }
+		}
+		return func(i uint16) int { return compareBool(col[i], s.constBool) }
+	case Bytes:
+		col := vec.Bytes()
+		if vec2 != nil {
+			col2 := vec2.Bytes()
+			return func(i uint16) int { return bytes.Compare(col[i], col2[i]) }
+		}
+		return func(i uint16) int { return bytes.Compare(col[i], s.constBytes) }
+	case Int64:
+		col := vec.Int64()
+		if vec2 != nil {
+			col2 := vec2.Int64()
+			return func(i uint16) int { return compareInt64(col[i], col2[i]) }
+		}
+		return func(i uint16) int { return compareInt64(col[i], s.constInt) }
+	case Float64:
+		col := vec.Float64()
+		if vec2 != nil {
+			col2 := vec2.Float64()
+			return func(i uint16) int { return compareFloat64(col[i], col2[i]) }
+		}
+		return func(i uint16) int { return compareFloat64(col[i], s.constFloat) }
+	}
+	panic(fmt.Sprintf("unhandled type %s", s.typ))
+}
+
+// Next is part of the Operator interface.
+func (s *selOp) Next() Batch {
+	for {
+		batch := s.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			return batch
+		}
+		cmp := s.compareFn(batch)
+		var nulls1, nulls2 Nulls
+		if vec := batch.ColVec(s.colIdx); vec.HasNulls() {
+			nulls1 = vec
+		}
+		if s.col2Idx >= 0 {
+			if vec := batch.ColVec(s.col2Idx); vec.HasNulls() {
+				nulls2 = vec
+			}
+		}
+		pass := func(i uint16) bool {
+			if (nulls1 != nil && nulls1.NullAt(i)) || (nulls2 != nil && nulls2.NullAt(i)) {
+				return false
+			}
+			return s.op.holds(cmp(i))
+		}
+
+		var idx uint16
+		if sel := batch.Selection(); sel != nil {
+			for _, i := range sel[:n] {
+				if pass(i) {
+					sel[idx] = i
+					idx++
+				}
+			}
+		} else {
+			batch.SetSelection(true)
+			sel := batch.Selection()
+			for i := uint16(0); i < n; i++ {
+				if pass(i) {
+					sel[idx] = i
+					idx++
+				}
+			}
+		}
+		if idx > 0 {
+			batch.SetLength(idx)
+			return batch
+		}
+	}
+}
+
+// selNullOp is an operator which filters the rows of its input according to
+// whether a column is NULL.
+type selNullOp struct {
+	input   Operator
+	colIdx  int
+	notNull bool
+}
+
+var _ Operator = &selNullOp{}
+
+// NewSelIsNullOp returns an operator which only passes the rows of its input
+// for which the given column is NULL (or, if notNull is set, isn't NULL).
+func NewSelIsNullOp(input Operator, colIdx int, notNull bool) Operator {
+	return &selNullOp{input: input, colIdx: colIdx, notNull: notNull}
+}
+
+// Init is part of the Operator interface.
+func (s *selNullOp) Init() {
+	s.input.Init()
+}
+
+// Next is part of the Operator interface.
+func (s *selNullOp) Next() Batch {
+	for {
+		batch := s.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			return batch
+		}
+		vec := batch.ColVec(s.colIdx)
+		if !vec.HasNulls() {
+			if s.notNull {
+				return batch
+			}
+			continue
+		}
+		var idx uint16
+		if sel := batch.Selection(); sel != nil {
+			for _, i := range sel[:n] {
+				if vec.NullAt(i) != s.notNull {
+					sel[idx] = i
+					idx++
+				}
+			}
+		} else {
+			batch.SetSelection(true)
+			sel := batch.Selection()
+			for i := uint16(0); i < n; i++ {
+				if vec.NullAt(i) != s.notNull {
+					sel[idx] = i
+					idx++
+				}
+			}
+		}
+		if idx > 0 {
+			batch.SetLength(idx)
+			return batch
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSelOps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	intInput := tuples{
+		{int64(1), int64(3)},
+		{int64(2), int64(2)},
+		{nil, int64(1)},
+		{int64(4), nil},
+		{int64(5), int64(0)},
+	}
+	intTypes := []Type{Int64, Int64}
+	floatInput := tuples{
+		{1.5, "a"},
+		{math.NaN(), "b"},
+		{-2.0, "c"},
+		{nil, nil},
+		{0.0, "a"},
+	}
+	floatTypes := []Type{Float64, Bytes}
+
+	testCases := []struct {
+		desc     string
+		input    tuples
+		types    []Type
+		expected tuples
+		mkOp     func(input Operator) (Operator, error)
+	}{
+		{
+			desc:     "int > const",
+			input:    intInput,
+			types:    intTypes,
+			expected: tuples{{int64(4), nil}, {int64(5), int64(0)}},
+			mkOp: func(input Operator) (Operator, error) {
+				return NewSelConstOp(input, Int64, 0, GT, int64(2))
+			},
+		},
+		{
+			desc:     "int <= col",
+			input:    intInput,
+			types:    intTypes,
+			expected: tuples{{int64(1), int64(3)}, {int64(2), int64(2)}},
+			mkOp: func(input Operator) (Operator, error) {
+				return NewSelColOp(input, Int64, 0, 1, LE)
+			},
+		},
+		{
+			desc:  "chained filters",
+			input: intInput,
+			types: intTypes,
+			expected: tuples{
+				{int64(2), int64(2)},
+			},
+			mkOp: func(input Operator) (Operator, error) {
+				op, err := NewSelConstOp(input, Int64, 0, NE, int64(1))
+				if err != nil {
+					return nil, err
+				}
+				return NewSelConstOp(op, Int64, 1, GE, int64(1))
+			},
+		},
+		{
+			// NaN is smaller than all the other values, and equal to itself.
+			desc:     "float < const",
+			input:    floatInput,
+			types:    floatTypes,
+			expected: tuples{{"b"}, {"c"}, {"a"}},
+			mkOp: func(input Operator) (Operator, error) {
+				op, err := NewSelConstOp(input, Float64, 0, LT, 0.5)
+				return NewSimpleProjectOp(op, []uint32{1}), err
+			},
+		},
+		{
+			desc:     "float = NaN",
+			input:    floatInput,
+			types:    floatTypes,
+			expected: tuples{{"b"}},
+			mkOp: func(input Operator) (Operator, error) {
+				op, err := NewSelConstOp(input, Float64, 0, EQ, math.NaN())
+				return NewSimpleProjectOp(op, []uint32{1}), err
+			},
+		},
+		{
+			desc:     "bytes = const",
+			input:    floatInput,
+			types:    floatTypes,
+			expected: tuples{{1.5, "a"}, {0.0, "a"}},
+			mkOp: func(input Operator) (Operator, error) {
+				return NewSelConstOp(input, Bytes, 1, EQ, []byte("a"))
+			},
+		},
+		{
+			desc:     "is null",
+			input:    intInput,
+			types:    intTypes,
+			expected: tuples{{nil, int64(1)}},
+			mkOp: func(input Operator) (Operator, error) {
+				return NewSelIsNullOp(input, 0, false /* notNull */), nil
+			},
+		},
+		{
+			desc:     "chained is not null",
+			input:    intInput,
+			types:    intTypes,
+			expected: tuples{{int64(1), int64(3)}, {int64(2), int64(2)}, {int64(5), int64(0)}},
+			mkOp: func(input Operator) (Operator, error) {
+				op := NewSelIsNullOp(input, 0, true /* notNull */)
+				return NewSelIsNullOp(op, 1, true /* notNull */), nil
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			runTests(t, []tuples{tc.input}, [][]Type{tc.types}, tc.expected, true, /* ordered */
+				func(inputs []Operator) (Operator, error) { return tc.mkOp(inputs[0]) })
+		})
+	}
+
+	if _, err := NewSelConstOp(nil, Int64, 0, EQ, 1.5); err == nil {
+		t.Error("expected error comparing an int column with a float")
+	}
+}
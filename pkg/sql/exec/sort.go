@@ -0,0 +1,129 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortColumn describes one of the columns of an ordering.
+type SortColumn struct {
+	ColIdx int
+	Desc   bool
+}
+
+// sorter is an operator which sorts all the rows of its input in memory.
+type sorter struct {
+	input    Operator
+	types    []Type
+	ordering []SortColumn
+
+	cols   []colBuffer
+	sorted bool
+	// order is the permutation of the rows of cols in the output order; emitted
+	// is the number of rows already output.
+	order   []int
+	emitted int
+
+	batch Batch
+}
+
+var _ Operator = &sorter{}
+
+// NewSorter returns an operator which outputs the rows of its input sorted
+// according to the given ordering. NULLs sort before the other values.
+func NewSorter(input Operator, types []Type, ordering []SortColumn) (Operator, error) {
+	for _, c := range ordering {
+		if types[c.ColIdx] == Unknown {
+			return nil, fmt.Errorf("unsupported sort on %s column", types[c.ColIdx])
+		}
+	}
+	s := &sorter{
+		input:    input,
+		types:    types,
+		ordering: ordering,
+		cols:     make([]colBuffer, len(types)),
+	}
+	for i, t := range types {
+		s.cols[i].typ = t
+	}
+	return s, nil
+}
+
+// Init is part of the Operator interface.
+func (s *sorter) Init() {
+	s.input.Init()
+	s.batch = NewMemBatch(s.types)
+}
+
+// sort consumes the input and sorts it.
+func (s *sorter) sort() {
+	numRows := 0
+	for {
+		batch := s.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			break
+		}
+		sel := batch.Selection()
+		for i := range s.cols {
+			s.cols[i].appendRows(batch.ColVec(i), sel, n)
+		}
+		numRows += int(n)
+	}
+
+	s.order = make([]int, numRows)
+	for i := range s.order {
+		s.order[i] = i
+	}
+	cmps := make([]func(i, j int) int, len(s.ordering))
+	for i, c := range s.ordering {
+		cmps[i] = s.cols[c.ColIdx].compareFn()
+	}
+	sort.Slice(s.order, func(a, b int) bool {
+		i, j := s.order[a], s.order[b]
+		for k, c := range s.ordering {
+			if cmp := cmps[k](i, j); cmp != 0 {
+				if c.Desc {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+		}
+		return false
+	})
+}
+
+// Next is part of the Operator interface.
+func (s *sorter) Next() Batch {
+	if !s.sorted {
+		s.sort()
+		s.sorted = true
+	}
+	n := len(s.order) - s.emitted
+	if n > BatchSize {
+		n = BatchSize
+	}
+	rows := s.order[s.emitted : s.emitted+n]
+	for i := range s.cols {
+		vec := s.batch.ColVec(i)
+		vec.UnsetNulls()
+		s.cols[i].copyTo(vec, rows)
+	}
+	s.emitted += n
+	s.batch.SetLength(uint16(n))
+	return s.batch
+}
@@ -0,0 +1,98 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSorter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	input := tuples{
+		{int64(3), "c", 1.0},
+		{nil, "a", 2.0},
+		{int64(1), "b", nil},
+		{int64(3), "a", 3.0},
+		{int64(1), nil, 4.0},
+		{int64(-2), "d", 5.0},
+	}
+	types := []Type{Int64, Bytes, Float64}
+
+	testCases := []struct {
+		desc     string
+		ordering []SortColumn
+		expected tuples
+	}{
+		{
+			desc:     "ascending",
+			ordering: []SortColumn{{ColIdx: 0}, {ColIdx: 1}},
+			expected: tuples{
+				{nil, "a", 2.0},
+				{int64(-2), "d", 5.0},
+				{int64(1), nil, 4.0},
+				{int64(1), "b", nil},
+				{int64(3), "a", 3.0},
+				{int64(3), "c", 1.0},
+			},
+		},
+		{
+			desc:     "mixed",
+			ordering: []SortColumn{{ColIdx: 0, Desc: true}, {ColIdx: 1}},
+			expected: tuples{
+				{int64(3), "a", 3.0},
+				{int64(3), "c", 1.0},
+				{int64(1), nil, 4.0},
+				{int64(1), "b", nil},
+				{int64(-2), "d", 5.0},
+				{nil, "a", 2.0},
+			},
+		},
+		{
+			desc:     "descending float",
+			ordering: []SortColumn{{ColIdx: 2, Desc: true}},
+			expected: tuples{
+				{int64(-2), "d", 5.0},
+				{int64(1), nil, 4.0},
+				{int64(3), "a", 3.0},
+				{nil, "a", 2.0},
+				{int64(3), "c", 1.0},
+				{int64(1), "b", nil},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			runTests(t, []tuples{input}, [][]Type{types}, tc.expected, true, /* ordered */
+				func(inputs []Operator) (Operator, error) {
+					return NewSorter(inputs[0], types, tc.ordering)
+				})
+		})
+	}
+
+	// More rows than fit in a batch.
+	var large, sorted tuples
+	for i := 0; i < 2*BatchSize+10; i++ {
+		large = append(large, tuple{int64((i * 7919) % (2*BatchSize + 10))})
+		sorted = append(sorted, tuple{int64(i)})
+	}
+	runTests(t, []tuples{large}, [][]Type{{Int64}}, sorted, true, /* ordered */
+		func(inputs []Operator) (Operator, error) {
+			return NewSorter(inputs[0], []Type{Int64}, []SortColumn{{ColIdx: 0}})
+		})
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package exec contains the vectorized execution engine: operators which
+// process batches of rows stored by column instead of one row at a time.
+//
+// The values of a column for the rows of a batch are stored in a vector of
+// the corresponding Go type, along with a bitmap of the NULL values. Filters
+// don't move any data; they set the selection vector of the batch to the
+// indexes of the rows which pass.
+//
+// This package doesn't know about SQL types or datums; the conversion to and
+// from rows happens at the boundaries of the vectorized flows (see the
+// columnarizer and the materializer in distsqlrun).
+package exec
+
+import "fmt"
+
+// Type is the physical representation of the values of a column vector.
+type Type int
+
+const (
+	// Unknown is the type of the columns whose values can't be represented by
+	// a vector. Such columns can't be read by any operator; all their values are
+	// NULL.
+	Unknown Type = iota
+	// Bool is the representation of BOOL values.
+	Bool
+	// Bytes is the representation of STRING and BYTES values.
+	Bytes
+	// Int64 is the representation of INT values.
+	Int64
+	// Float64 is the representation of FLOAT values.
+	Float64
+)
+
+func (t Type) String() string {
+	switch t {
+	case Unknown:
+		return "unknown"
+	case Bool:
+		return "bool"
+	case Bytes:
+		return "bytes"
+	case Int64:
+		return "int64"
+	case Float64:
+		return "float64"
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
@@ -0,0 +1,163 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// tuple is a row of test values: nil, bool, string (for Bytes columns), int64
+// or float64.
+type tuple []interface{}
+
+type tuples []tuple
+
+// batchSizes are the batch sizes with which the test inputs are produced.
+var batchSizes = []uint16{1, 3, BatchSize}
+
+// opTestInput is an Operator which outputs a set of tuples in batches of a
+// given size.
+type opTestInput struct {
+	typs      []Type
+	tuples    tuples
+	batchSize uint16
+	batch     Batch
+}
+
+var _ Operator = &opTestInput{}
+
+func newOpTestInput(batchSize uint16, typs []Type, tups tuples) *opTestInput {
+	return &opTestInput{typs: typs, tuples: tups, batchSize: batchSize}
+}
+
+func (s *opTestInput) Init() {
+	s.batch = NewMemBatch(s.typs)
+}
+
+func (s *opTestInput) Next() Batch {
+	s.batch.SetSelection(false)
+	n := len(s.tuples)
+	if n > int(s.batchSize) {
+		n = int(s.batchSize)
+	}
+	// Operators may have appended columns to the batch; only the columns of the
+	// input are filled.
+	for i := range s.typs {
+		vec := s.batch.ColVec(i)
+		vec.UnsetNulls()
+		for j, t := range s.tuples[:n] {
+			switch v := t[i].(type) {
+			case nil:
+				vec.SetNull(uint16(j))
+			case bool:
+				vec.Bool()[j] = v
+			case string:
+				vec.Bytes()[j] = []byte(v)
+			case int64:
+				vec.Int64()[j] = v
+			case float64:
+				vec.Float64()[j] = v
+			default:
+				panic(fmt.Sprintf("unhandled test value %T", v))
+			}
+		}
+	}
+	s.tuples = s.tuples[n:]
+	s.batch.SetLength(uint16(n))
+	return s.batch
+}
+
+// collect initializes and runs an operator, and returns its output.
+func collect(op Operator) (tuples, error) {
+	var res tuples
+	err := CatchError(func() {
+		op.Init()
+		for {
+			batch := op.Next()
+			n := batch.Length()
+			if n == 0 {
+				return
+			}
+			sel := batch.Selection()
+			for k := uint16(0); k < n; k++ {
+				i := rowIdx(sel, k)
+				t := make(tuple, batch.Width())
+				for c := range t {
+					vec := batch.ColVec(c)
+					if vec.HasNulls() && vec.NullAt(i) {
+						continue
+					}
+					switch vec.Type() {
+					case Bool:
+						t[c] = vec.Bool()[i]
+					case Bytes:
+						t[c] = string(vec.Bytes()[i])
+					case Int64:
+						t[c] = vec.Int64()[i]
+					case Float64:
+						t[c] = vec.Float64()[i]
+					}
+				}
+				res = append(res, t)
+			}
+		}
+	})
+	return res, err
+}
+
+// runTests runs an operator built by mkOp over the given inputs, produced in
+// batches of various sizes, and checks its output. If ordered is false, the
+// order of the output rows is ignored.
+func runTests(
+	t *testing.T,
+	inputs []tuples,
+	inputTypes [][]Type,
+	expected tuples,
+	ordered bool,
+	mkOp func(inputs []Operator) (Operator, error),
+) {
+	t.Helper()
+	for _, batchSize := range batchSizes {
+		ops := make([]Operator, len(inputs))
+		for i := range inputs {
+			ops[i] = newOpTestInput(batchSize, inputTypes[i], inputs[i])
+		}
+		op, err := mkOp(ops)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := collect(op)
+		if err != nil {
+			t.Fatalf("batch size %d: %s", batchSize, err)
+		}
+		if !ordered {
+			sortTuples(res)
+			expected = append(tuples(nil), expected...)
+			sortTuples(expected)
+		}
+		if len(res) != len(expected) || (len(res) > 0 && !reflect.DeepEqual(res, expected)) {
+			t.Errorf("batch size %d: expected\n%v\ngot\n%v", batchSize, expected, res)
+		}
+	}
+}
+
+func sortTuples(tups tuples) {
+	sort.Slice(tups, func(i, j int) bool {
+		return fmt.Sprint(tups[i]) < fmt.Sprint(tups[j])
+	})
+}
@@ -0,0 +1,178 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import "fmt"
+
+// Nulls is the bitmap of the NULL values of a column vector.
+type Nulls interface {
+	// HasNulls returns true if any of the values is NULL.
+	HasNulls() bool
+	// NullAt returns true if the i-th value is NULL.
+	NullAt(i uint16) bool
+	// SetNull marks the i-th value as NULL.
+	SetNull(i uint16)
+	// UnsetNulls marks all the values as non-NULL.
+	UnsetNulls()
+}
+
+// ColVec is a column vector: the values of a column for the rows of a batch.
+// Only the accessor corresponding to the type of the vector can be used. The
+// values of the NULL rows are undefined.
+type ColVec interface {
+	Nulls
+
+	// Type returns the type of the values of the vector.
+	Type() Type
+
+	// Bool returns the values of a Bool vector.
+	Bool() []bool
+	// Bytes returns the values of a Bytes vector.
+	Bytes() [][]byte
+	// Int64 returns the values of an Int64 vector.
+	Int64() []int64
+	// Float64 returns the values of a Float64 vector.
+	Float64() []float64
+}
+
+// memColumn is the ColVec implementation which stores the values in a slice.
+type memColumn struct {
+	typ Type
+	col interface{}
+
+	nulls    []uint64
+	hasNulls bool
+}
+
+var _ ColVec = &memColumn{}
+
+// newMemColumn allocates a vector of n values of the given type.
+func newMemColumn(t Type, n int) *memColumn {
+	m := &memColumn{typ: t, nulls: make([]uint64, (n+63)/64)}
+	switch t {
+	case Unknown:
+		for i := range m.nulls {
+			m.nulls[i] = ^uint64(0)
+		}
+		m.hasNulls = true
+	case Bool:
+		m.col = make([]bool, n)
+	case Bytes:
+		m.col = make([][]byte, n)
+	case Int64:
+		m.col = make([]int64, n)
+	case Float64:
+		m.col = make([]float64, n)
+	default:
+		panic(fmt.Sprintf("unhandled type %s", t))
+	}
+	return m
+}
+
+// Type is part of the ColVec interface.
+func (m *memColumn) Type() Type { return m.typ }
+
+// Bool is part of the ColVec interface.
+func (m *memColumn) Bool() []bool { return m.col.([]bool) }
+
+// Bytes is part of the ColVec interface.
+func (m *memColumn) Bytes() [][]byte { return m.col.([][]byte) }
+
+// Int64 is part of the ColVec interface.
+func (m *memColumn) Int64() []int64 { return m.col.([]int64) }
+
+// Float64 is part of the ColVec interface.
+func (m *memColumn) Float64() []float64 { return m.col.([]float64) }
+
+// HasNulls is part of the Nulls interface.
+func (m *memColumn) HasNulls() bool { return m.hasNulls }
+
+// NullAt is part of the Nulls interface.
+func (m *memColumn) NullAt(i uint16) bool {
+	return m.nulls[i/64]&(1<<(i%64)) != 0
+}
+
+// SetNull is part of the Nulls interface.
+func (m *memColumn) SetNull(i uint16) {
+	m.nulls[i/64] |= 1 << (i % 64)
+	m.hasNulls = true
+}
+
+// UnsetNulls is part of the Nulls interface. The values of Unknown vectors
+// remain NULL.
+func (m *memColumn) UnsetNulls() {
+	if !m.hasNulls || m.typ == Unknown {
+		return
+	}
+	for i := range m.nulls {
+		m.nulls[i] = 0
+	}
+	m.hasNulls = false
+}
+
+// copyVec copies the rows of src given by sel (or its first n rows if sel is
+// nil) to the first n rows of dst, which must be of the same type. The nulls
+// of dst must have been unset.
+func copyVec(dst, src ColVec, sel []uint16, n uint16) {
+	switch src.Type() {
+	case Bool:
+		d, s := dst.Bool(), src.Bool()
+		if sel == nil {
+			copy(d, s[:n])
+		} else {
+			for i, j := range sel[:n] {
+				d[i] = s[j]
+			}
+		}
+	case Bytes:
+		d, s := dst.Bytes(), src.Bytes()
+		if sel == nil {
+			copy(d, s[:n])
+		} else {
+			for i, j := range sel[:n] {
+				d[i] = s[j]
+			}
+		}
+	case Int64:
+		d, s := dst.Int64(), src.Int64()
+		if sel == nil {
+			copy(d, s[:n])
+		} else {
+			for i, j := range sel[:n] {
+				d[i] = s[j]
+			}
+		}
+	case Float64:
+		d, s := dst.Float64(), src.Float64()
+		if sel == nil {
+			copy(d, s[:n])
+		} else {
+			for i, j := range sel[:n] {
+				d[i] = s[j]
+			}
+		}
+	}
+	if src.HasNulls() && src.Type() != Unknown {
+		for i := uint16(0); i < n; i++ {
+			j := i
+			if sel != nil {
+				j = sel[i]
+			}
+			if src.NullAt(j) {
+				dst.SetNull(i)
+			}
+		}
+	}
+}
@@ -46,6 +46,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -278,6 +279,7 @@ type ExecutorConfig struct {
 	StatusServer    serverpb.StatusServer
 	SessionRegistry *SessionRegistry
 	JobRegistry     *jobs.Registry
+	NodeLiveness    *storage.NodeLiveness
 
 	TestingKnobs              *ExecutorTestingKnobs
 	SchemaChangerTestingKnobs *SchemaChangerTestingKnobs
@@ -424,6 +426,7 @@ func (e *Executor) Start(
 			}
 		}
 	})
+	e.startTempSchemaCleaner(ctx)
 
 	ctx = log.WithLogTag(ctx, "startup", nil)
 	startupSession := NewSession(ctx, SessionArgs{}, e, nil, startupMemMetrics)
@@ -835,7 +838,7 @@ func (e *Executor) execParsed(
 			e, session, stmtsToExec, !inTxn /* txnPrefix */, autoCommit,
 			protoTS, pinfo, avoidCachedDescriptors,
 		)
-		if autoCommit && txnState.State() != NoTxn {
+		if autoCommit && txnState.State() != NoTxn && !txnState.heldOpen {
 			log.Fatalf(session.Ctx(), "after an implicit txn, state should always be NoTxn, but found: %s",
 				txnState.State())
 		}
@@ -850,8 +853,10 @@ func (e *Executor) execParsed(
 		}
 
 		// Sanity check about not leaving KV txns open on errors (other than
-		// retriable errors).
-		if err != nil && txnState.mu.txn != nil && !txnState.mu.txn.IsFinalized() {
+		// retriable errors and errors which can be undone by rolling back to a
+		// savepoint).
+		if err != nil && txnState.mu.txn != nil && !txnState.mu.txn.IsFinalized() &&
+			!(txnState.State() == Aborted && len(txnState.savepoints) > 0) {
 			if _, retryable := err.(*roachpb.HandledRetryableTxnError); !retryable {
 				log.Fatalf(session.Ctx(), "got a non-retryable error but the KV "+
 					"transaction is not finalized. TxnState: %s, err: %s\n"+
@@ -1039,6 +1044,12 @@ func runWithAutoRetry(
 			}
 		}
 
+		// If the execution of a portal was suspended, the implicit transaction
+		// is held open until the client sends Sync.
+		if autoCommit && err == nil && txnState.heldOpen {
+			autoCommit = false
+		}
+
 		// Check if we need to auto-commit. If so, we end the transaction now; the
 		// transaction was only supposed to exist for the statement that we just
 		// ran.
@@ -1072,7 +1083,7 @@ func runWithAutoRetry(
 						skipCommit = err != nil
 					}
 					if !skipCommit {
-						err = txn.Commit(session.Ctx())
+						err = session.commitWithOnCommitActions(session.Ctx(), e, txn)
 					}
 					log.Eventf(session.Ctx(), "AutoCommit. err: %v\ntxn: %+v", err, txn.Proto())
 					if err != nil {
@@ -1385,8 +1396,11 @@ func runShowTransactionState(session *Session, res StatementResult) error {
 // execStmtInAbortedTxn executes a statement in a txn that's in state
 // Aborted or RestartWait. All statements cause errors except:
 // - COMMIT / ROLLBACK: aborts the current transaction.
-// - ROLLBACK TO SAVEPOINT / SAVEPOINT: reopens the current transaction,
-//   allowing it to be retried.
+// - ROLLBACK TO SAVEPOINT / SAVEPOINT cockroach_restart: reopens the current
+//   transaction, allowing it to be retried.
+// - ROLLBACK TO SAVEPOINT for another savepoint: resumes the current
+//   transaction, if it was aborted by a non-retryable error after the
+//   savepoint was created.
 func (e *Executor) execStmtInAbortedTxn(
 	session *Session, stmt Statement, res StatementResult,
 ) error {
@@ -1394,6 +1408,22 @@ func (e *Executor) execStmtInAbortedTxn(
 	if txnState.State() != Aborted && txnState.State() != RestartWait {
 		panic("execStmtInAbortedTxn called outside of an aborted txn")
 	}
+	if s, ok := stmt.AST.(*tree.RollbackToSavepoint); ok && !tree.IsRestartSavepoint(s.Savepoint) {
+		if txnState.State() == RestartWait {
+			// The txn is being restarted, which destroyed its savepoints.
+			_, err := txnState.findSavepoint(s.Savepoint)
+			return txnState.updateStateAndCleanupOnErr(err, e)
+		}
+		if txnState.mu.txn == nil {
+			// The KV txn has already been rolled back.
+			return sqlbase.NewTransactionAbortedError("" /* customMsg */)
+		}
+		if err := execRollbackToSavepoint(session, s, res); err != nil {
+			return err
+		}
+		txnState.SetState(Open)
+		return nil
+	}
 	// TODO(andrei/cuongdo): Figure out what statements to count here.
 	switch s := stmt.AST.(type) {
 	case *tree.CommitTransaction, *tree.RollbackTransaction:
@@ -1407,8 +1437,10 @@ func (e *Executor) execStmtInAbortedTxn(
 			return transition.err
 		}
 		// Reset the state to allow new transactions to start.
-		// The KV txn has already been rolled back when we entered the Aborted state.
+		// The KV txn has already been rolled back when we entered the Aborted
+		// state, unless it was kept open for rolling back to a savepoint.
 		// Note: postgres replies to COMMIT of failed txn with "ROLLBACK" too.
+		txnState.rollbackHeldTxn()
 		txnState.resetStateAndTxn(NoTxn)
 		res.BeginResult((*tree.RollbackTransaction)(nil))
 		return res.CloseResult()
@@ -1425,11 +1457,13 @@ func (e *Executor) execStmtInAbortedTxn(
 		default:
 			panic("unreachable")
 		}
-		if err := tree.ValidateRestartCheckpoint(spName); err != nil {
+		if !tree.IsRestartSavepoint(spName) {
+			// Savepoints cannot be created in an aborted txn.
 			if txnState.State() == RestartWait {
-				err = txnState.updateStateAndCleanupOnErr(err, e)
+				return txnState.updateStateAndCleanupOnErr(sqlbase.NewTransactionAbortedError(
+					"Expected \"ROLLBACK TO SAVEPOINT COCKROACH_RESTART\"" /* customMsg */), e)
 			}
-			return err
+			return sqlbase.NewTransactionAbortedError("" /* customMsg */)
 		}
 		if !txnState.retryIntent {
 			err := fmt.Errorf("SAVEPOINT %s has not been used", tree.RestartSavepointName)
@@ -1452,9 +1486,11 @@ func (e *Executor) execStmtInAbortedTxn(
 			// ROLLBACK TO SAVEPOINT after every error and possibly follow it with a
 			// ROLLBACK and also because we accept ROLLBACK TO SAVEPOINT in the Open
 			// state, so this is consistent.
-			// The old txn has already been rolled back; we start a new txn with the
+			// The old txn has already been rolled back (or is rolled back now, if
+			// it was kept open for savepoints); we start a new txn with the
 			// same sql timestamp and isolation as the current one.
 			curTs, curIso, curPri := txnState.sqlTimestamp, txnState.isolation, txnState.priority
+			txnState.rollbackHeldTxn()
 			txnState.finishSQLTxn(session)
 			txnState.resetForNewSQLTxn(
 				e, session,
@@ -1668,17 +1704,17 @@ func (e *Executor) execStmtInOpenTxn(
 	case *tree.CommitTransaction:
 		// CommitTransaction is executed fully here; there's no planNode for it
 		// and a planner is not involved at all.
-		transition = commitSQLTransaction(txnState, commit, res)
+		transition = commitSQLTransaction(session, e, commit, res)
 		explicitStateTransition = true
 		return nil
 
 	case *tree.ReleaseSavepoint:
-		if err := tree.ValidateRestartCheckpoint(s.Savepoint); err != nil {
-			return err
+		if !tree.IsRestartSavepoint(s.Savepoint) {
+			return execReleaseSavepoint(session, s, res)
 		}
 		// ReleaseSavepoint is executed fully here; there's no planNode for it
 		// and a planner is not involved at all.
-		transition = commitSQLTransaction(txnState, release, res)
+		transition = commitSQLTransaction(session, e, release, res)
 		explicitStateTransition = true
 		return nil
 
@@ -1693,8 +1729,8 @@ func (e *Executor) execStmtInOpenTxn(
 		return nil
 
 	case *tree.Savepoint:
-		if err := tree.ValidateRestartCheckpoint(s.Name); err != nil {
-			return err
+		if !tree.IsRestartSavepoint(s.Name) {
+			return execSavepoint(session, s, res)
 		}
 		// We want to disallow SAVEPOINTs to be issued after a transaction has
 		// started running. The client txn's statement count indicates how many
@@ -1710,8 +1746,8 @@ func (e *Executor) execStmtInOpenTxn(
 		return res.CloseResult()
 
 	case *tree.RollbackToSavepoint:
-		if err := tree.ValidateRestartCheckpoint(s.Savepoint); err != nil {
-			return err
+		if !tree.IsRestartSavepoint(s.Savepoint) {
+			return execRollbackToSavepoint(session, s, res)
 		}
 		if !txnState.retryIntent {
 			err := fmt.Errorf("SAVEPOINT %s has not been used", tree.RestartSavepointName)
@@ -1725,6 +1761,7 @@ func (e *Executor) execStmtInOpenTxn(
 
 		// Move the state to AutoRetry; we're morally beginning a new transaction.
 		txnState.SetState(AutoRetry)
+		txnState.savepoints = nil
 		// If commands have already been sent through the transaction,
 		// restart the client txn's proto to increment the epoch.
 		if txnState.mu.txn.CommandCount() > 0 {
@@ -1760,10 +1797,15 @@ func (e *Executor) execStmtInOpenTxn(
 
 	var p *planner
 	runInParallel := parallelize && !txnState.implicitTxn
+	suspendable := session.portalExec.suspendable(stmt)
 	if runInParallel {
 		// Create a new planner from the Session to execute the statement, since
 		// we're executing in parallel.
 		p = session.newPlanner(e, txnState.mu.txn)
+	} else if suspendable {
+		// The planner of a portal whose execution may be suspended can outlive
+		// the statement, so it can't be the cached one.
+		p = session.newPlanner(e, txnState.mu.txn)
 	} else {
 		// We're not executing in parallel. We can use the cached planner on the
 		// session.
@@ -1793,7 +1835,12 @@ func (e *Executor) execStmtInOpenTxn(
 		// immediately blocking.
 		err = e.execStmtInParallel(stmt, p, res)
 	} else {
-		p.autoCommit = txnState.implicitTxn && !e.cfg.TestingKnobs.DisableAutoCommit
+		// The transaction can't be committed by the statement if it may be
+		// needed again after the statement.
+		// Neither can it if the ON COMMIT actions of temporary tables must run
+		// in the transaction before it commits.
+		p.autoCommit = txnState.implicitTxn && !txnState.heldOpen && !suspendable &&
+			len(session.tempTablesOnCommit) == 0 && !e.cfg.TestingKnobs.DisableAutoCommit
 		err = e.execStmt(stmt, p, automaticRetryCount, res)
 		// Zeroing the cached planner allows the GC to clean up any memory hanging
 		// off the planner, which we're finished using at this point.
@@ -1893,18 +1940,19 @@ const (
 )
 
 // commitSQLTransaction executes a COMMIT or RELEASE SAVEPOINT statement. The
-// transaction is committed and the statement result is written to res.
+// transaction is committed, along with the ON COMMIT actions of the temporary
+// tables of the session, and the statement result is written to res.
 func commitSQLTransaction(
-	txnState *txnState, commitType commitType, res StatementResult,
+	session *Session, e *Executor, commitType commitType, res StatementResult,
 ) stateTransition {
-
+	txnState := &session.TxnState
 	if !txnState.TxnIsOpen() {
 		panic(fmt.Sprintf("commitSqlTransaction called on non-open txn: %+v", txnState.mu.txn))
 	}
 	if commitType == commit {
 		txnState.commitSeen = true
 	}
-	if err := txnState.mu.txn.Commit(txnState.Ctx); err != nil {
+	if err := session.commitWithOnCommitActions(txnState.Ctx, e, txnState.mu.txn); err != nil {
 		// Errors on COMMIT need special handling: if the errors is not handled by
 		// auto-retry, COMMIT needs to finalize the transaction (it can't leave it
 		// in Aborted or RestartWait). Higher layers will handle this with the help
@@ -2134,16 +2182,28 @@ func (e *Executor) execStmt(
 		return err
 	}
 
-	defer plan.Close(ctx)
+	// The plan of a suspended portal is closed when the portal is.
+	suspended := false
+	defer func() {
+		if !suspended {
+			plan.Close(ctx)
+		}
+	}()
 
 	err = initStatementResult(res, stmt, plan)
 	if err != nil {
 		return err
 	}
 
-	useDistSQL, err := e.shouldUseDistSQL(planner, plan)
-	if err != nil {
-		return err
+	// The execution of a portal can only be suspended if it runs locally,
+	// since the rows are pulled from the plan as the client requests them.
+	suspendable := session.portalExec.suspendable(stmt)
+	useDistSQL := false
+	if !suspendable {
+		useDistSQL, err = e.shouldUseDistSQL(planner, plan)
+		if err != nil {
+			return err
+		}
 	}
 
 	if e.cfg.TestingKnobs.BeforeExecute != nil {
@@ -2154,6 +2214,8 @@ func (e *Executor) execStmt(
 	session.setQueryExecutionMode(stmt.queryID, useDistSQL, false /* isParallel */)
 	if useDistSQL {
 		err = e.execDistSQL(planner, plan, res)
+	} else if suspendable {
+		suspended, err = e.execSuspendable(planner, stmt, plan, res)
 	} else {
 		err = e.execClassic(planner, plan, res)
 	}
@@ -2164,7 +2226,9 @@ func (e *Executor) execStmt(
 	if e.cfg.TestingKnobs.AfterExecute != nil {
 		e.cfg.TestingKnobs.AfterExecute(ctx, stmt.String(), res, err)
 	}
-	if err != nil {
+	if err != nil || suspended {
+		// The result of a suspended portal is closed when its execution
+		// completes.
 		return err
 	}
 	return res.CloseResult()
@@ -2350,6 +2414,8 @@ func checkResultType(typ types.T) error {
 	case types.Date:
 	case types.Timestamp:
 	case types.TimestampTZ:
+	case types.Time:
+	case types.TimeTZ:
 	case types.Interval:
 	case types.JSON:
 	case types.UUID:
@@ -2370,6 +2436,7 @@ func checkResultType(typ types.T) error {
 				return pgerror.Unimplemented("nested arrays", "arrays cannot have arrays as element type")
 			}
 		case istype(types.FamCollatedString):
+		case istype(types.FamEnum):
 		case istype(types.FamTuple):
 		case istype(types.FamPlaceholder):
 			return errors.Errorf("could not determine data type of %s", typ)
@@ -253,6 +253,7 @@ func doExpandPlan(
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterTypeNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *scrubNode:
@@ -260,13 +261,18 @@ func doExpandPlan(
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSchemaNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSchemaNode:
+	case *dropTypeNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshViewNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
@@ -580,6 +586,7 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterTypeNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *scrubNode:
@@ -587,13 +594,18 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSchemaNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSchemaNode:
+	case *dropTypeNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshViewNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
@@ -25,6 +25,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 type explainMode int
@@ -122,7 +124,13 @@ func (p *planner) Explain(ctx context.Context, n *tree.Explain) (planNode, error
 			mode = newMode
 		}
 	}
-	if mode == explainNone {
+	if n.Analyze {
+		switch mode {
+		case explainNone, explainDistSQL:
+		default:
+			return nil, fmt.Errorf("EXPLAIN ANALYZE only supports the DISTSQL option")
+		}
+	} else if mode == explainNone {
 		mode = explainPlan
 	}
 
@@ -132,6 +140,15 @@ func (p *planner) Explain(ctx context.Context, n *tree.Explain) (planNode, error
 	if err != nil {
 		return nil, err
 	}
+	if n.Analyze {
+		return &explainDistSQLNode{
+			plan:           plan,
+			distSQLPlanner: p.session.distSQLPlanner,
+			txn:            p.txn,
+			analyze:        true,
+			textStats:      mode == explainNone,
+		}, nil
+	}
 	switch mode {
 	case explainDistSQL:
 		return &explainDistSQLNode{
@@ -153,6 +170,11 @@ func (p *planner) Explain(ctx context.Context, n *tree.Explain) (planNode, error
 
 // explainDistSQLNode is a planNode that wraps a plan and returns
 // information related to running that plan under DistSQL.
+//
+// For EXPLAIN ANALYZE, the plan is also run, with every processor and stream
+// collecting execution statistics. The statistics are sent back to the
+// gateway as trace spans, and are added either to the plan diagram or, when
+// textStats is set, returned as text rows.
 type explainDistSQLNode struct {
 	optColumnsSlot
 
@@ -162,11 +184,17 @@ type explainDistSQLNode struct {
 	// txn is the current transaction (used for the fake span resolver).
 	txn *client.Txn
 
-	// The single row returned by the node.
-	values tree.Datums
+	// analyze is set for EXPLAIN ANALYZE.
+	analyze bool
+	// textStats is set for EXPLAIN ANALYZE without the DISTSQL option; the
+	// results are then described by explainAnalyzeColumns.
+	textStats bool
+
+	// The rows returned by the node.
+	rows []tree.Datums
 
-	// done is set if Next() was called.
-	done bool
+	// rowIdx is the index of the current row (1-based).
+	rowIdx int
 }
 
 func (n *explainDistSQLNode) Close(ctx context.Context) {
@@ -179,6 +207,23 @@ var explainDistSQLColumns = sqlbase.ResultColumns{
 	{Name: "JSON", Typ: types.String, Hidden: true},
 }
 
+// explainAnalyzeColumns are the columns of EXPLAIN ANALYZE, which describes
+// the tree of processors of the plan the same way EXPLAIN describes the tree
+// of planNodes.
+var explainAnalyzeColumns = sqlbase.ResultColumns{
+	{Name: "Level", Typ: types.Int},
+	{Name: "Type", Typ: types.String},
+	{Name: "Field", Typ: types.String},
+	{Name: "Description", Typ: types.String},
+}
+
+func (n *explainDistSQLNode) columns(mut bool) sqlbase.ResultColumns {
+	if n.textStats {
+		return n.getColumns(mut, explainAnalyzeColumns)
+	}
+	return n.getColumns(mut, explainDistSQLColumns)
+}
+
 func (n *explainDistSQLNode) Start(params runParams) error {
 	// Trigger limit propagation.
 	setUnlimited(n.plan)
@@ -188,6 +233,10 @@ func (n *explainDistSQLNode) Start(params runParams) error {
 		return err
 	}
 
+	if n.analyze {
+		return n.startAnalyze(params, auto)
+	}
+
 	planCtx := n.distSQLPlanner.newPlanningCtx(params.ctx, &params.p.evalCtx, n.txn)
 	plan, err := n.distSQLPlanner.createPlanForNode(&planCtx, n.plan)
 	if err != nil {
@@ -200,22 +249,117 @@ func (n *explainDistSQLNode) Start(params runParams) error {
 		return err
 	}
 
-	n.values = tree.Datums{
+	n.rows = []tree.Datums{{
 		tree.MakeDBool(tree.DBool(auto)),
 		tree.NewDString(planURL.String()),
 		tree.NewDString(planJSON),
+	}}
+	return nil
+}
+
+// startAnalyze runs the plan with statistics collection enabled, under a
+// recording trace span which receives the statistics of all the flows.
+func (n *explainDistSQLNode) startAnalyze(params runParams, auto bool) error {
+	ctx, sp, err := tracing.StartSnowballTrace(
+		params.ctx, params.p.ExecCfg().AmbientCtx.Tracer, "explain analyze",
+	)
+	if err != nil {
+		return err
+	}
+	defer sp.Finish()
+
+	dsp := n.distSQLPlanner
+	execCfg := params.p.ExecCfg()
+	var rowCount rowCountWriter
+	recv, err := makeDistSQLReceiver(
+		ctx, &rowCount,
+		execCfg.RangeDescriptorCache, execCfg.LeaseHolderCache,
+		n.txn,
+		func(ts hlc.Timestamp) {
+			_ = execCfg.Clock.Update(ts)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if err := dsp.planAndRunSubqueries(ctx, n.txn, n.plan, &recv, params.p.evalCtx); err != nil {
+		return err
+	}
+
+	planCtx := dsp.newPlanningCtx(ctx, &params.p.evalCtx, n.txn)
+	planCtx.collectStats = true
+	plan, err := dsp.createPlanForNode(&planCtx, n.plan)
+	if err != nil {
+		return err
+	}
+	dsp.FinalizePlan(&planCtx, &plan)
+	if err := dsp.Run(&planCtx, n.txn, &plan, &recv, params.p.evalCtx); err != nil {
+		return err
+	}
+	if recv.err != nil {
+		return recv.err
+	}
+
+	stats, err := distsqlrun.ExtractFlowStats(tracing.GetRecording(sp))
+	if err != nil {
+		return err
+	}
+	flows := plan.GenerateFlowSpecs(params.p.evalCtx.NodeID)
+	if n.textStats {
+		for _, r := range distsqlrun.FormatFlowStats(flows, stats) {
+			n.rows = append(n.rows, tree.Datums{
+				tree.NewDInt(tree.DInt(r.Level)),
+				tree.NewDString(r.Type),
+				tree.NewDString(r.Field),
+				tree.NewDString(r.Description),
+			})
+		}
+		return nil
+	}
+	planJSON, planURL, err := distsqlrun.GeneratePlanDiagramWithStats(flows, stats)
+	if err != nil {
+		return err
 	}
+	n.rows = []tree.Datums{{
+		tree.MakeDBool(tree.DBool(auto)),
+		tree.NewDString(planURL.String()),
+		tree.NewDString(planJSON),
+	}}
 	return nil
 }
 
 func (n *explainDistSQLNode) Next(runParams) (bool, error) {
-	if n.done {
+	if n.rowIdx >= len(n.rows) {
 		return false, nil
 	}
-	n.done = true
+	n.rowIdx++
 	return true, nil
 }
 
 func (n *explainDistSQLNode) Values() tree.Datums {
-	return n.values
+	return n.rows[n.rowIdx-1]
+}
+
+// rowCountWriter is the rowResultWriter used by EXPLAIN ANALYZE; it discards
+// the results of the plan.
+type rowCountWriter struct {
+	rowsAffected int
+}
+
+var _ rowResultWriter = &rowCountWriter{}
+
+// AddRow implements the rowResultWriter interface.
+func (w *rowCountWriter) AddRow(context.Context, tree.Datums) error {
+	w.rowsAffected++
+	return nil
+}
+
+// IncrementRowsAffected implements the rowResultWriter interface.
+func (w *rowCountWriter) IncrementRowsAffected(n int) {
+	w.rowsAffected += n
+}
+
+// StatementType implements the rowResultWriter interface.
+func (w *rowCountWriter) StatementType() tree.StatementType {
+	return tree.Rows
 }
@@ -71,9 +71,13 @@ func (v *varConvertVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree
 	}
 
 	if varExpr, ok := expr.(tree.VariableExpr); ok {
-		// Ignore sub-queries and placeholders
 		switch expr.(type) {
-		case *subquery, *tree.Placeholder:
+		case *subquery:
+			// Only the references of correlated sub-queries to the columns of
+			// the enclosing expression (if any) need to be considered.
+			return true, expr
+		case *tree.Placeholder, *outerVar:
+			// Ignore placeholders and outer columns.
 			return false, expr
 		}
 
@@ -310,6 +310,7 @@ func (p *planner) propagateFilters(
 		}
 
 	case *alterTableNode:
+	case *alterTypeNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *scrubNode:
@@ -317,13 +318,18 @@ func (p *planner) propagateFilters(
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSchemaNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSchemaNode:
+	case *dropTypeNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshViewNode:
 	case *hookFnNode:
 	case *valueGenerator:
 	case *valuesNode:
@@ -681,7 +687,8 @@ func (p *planner) addJoinFilter(
 ) (planNode, tree.TypedExpr, error) {
 
 	// There are four steps to the transformation below:
-	//  1. For inner joins, incorporate the extra filter into the ON condition.
+	//  1. For inner and semi joins, incorporate the extra filter into the ON
+	//     condition.
 	//  2. Extract any join equality constraints from the ON condition.
 	//  3. "Expand" the remaining ON condition with new constraints inferred based
 	//     on the equality columns (see expandOnCond).
@@ -691,8 +698,10 @@ func (p *planner) addJoinFilter(
 
 	onAndExprs := splitAndExpr(&p.evalCtx, n.pred.onCond, nil)
 
-	// Step 1: for inner joins, incorporate the filter into the ON condition.
-	if n.joinType == joinTypeInner {
+	// Step 1: for inner and semi joins, incorporate the filter into the ON
+	// condition. For semi joins, the filter only refers to left columns, which
+	// are also the first columns of the ON condition.
+	if n.joinType == joinTypeInner || n.joinType == joinTypeSemi {
 		onAndExprs = splitAndExpr(&p.evalCtx, extraFilter, onAndExprs)
 		extraFilter = nil
 	}
@@ -716,7 +725,7 @@ func (p *planner) addJoinFilter(
 	// Step 4: propagate the filter and ON conditions as allowed by the join type.
 	var propagateLeft, propagateRight, filterRemainder tree.TypedExpr
 	switch n.joinType {
-	case joinTypeInner:
+	case joinTypeInner, joinTypeSemi:
 		// We transform:
 		//   SELECT * FROM
 		//          l JOIN r ON (onLeft AND onRight AND onCombined)
@@ -769,6 +778,22 @@ func (p *planner) addJoinFilter(
 		// onCond = onRight AND onCombined.
 		propagateLeft, onCond = splitJoinFilterLeft(n, numLeft, onCond)
 
+	case joinTypeAnti:
+		// We transform:
+		//   SELECT * FROM
+		//          l ANTI JOIN r ON (onLeft AND onRight AND onCombined)
+		//   WHERE filterLeft
+		// to:
+		//   SELECT * FROM
+		//          (SELECT * FROM l WHERE filterLeft)
+		//          ANTI JOIN
+		//          (SELECT * from r WHERE onRight)
+		//          ON (onLeft AND onCombined)
+		//
+		// The filter can only refer to the left columns.
+		propagateLeft = extraFilter
+		propagateRight, onCond = splitJoinFilterRight(n, numLeft, onCond)
+
 	case joinTypeFullOuter:
 		// Not much we can do for full outer joins.
 		filterRemainder = extraFilter
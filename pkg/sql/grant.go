@@ -28,6 +28,10 @@ func (p *planner) changePrivileges(
 	grantees tree.NameList,
 	changePrivilege func(*sqlbase.PrivilegeDescriptor, string),
 ) (planNode, error) {
+	if targets.Schemas != nil {
+		return p.changeSchemaPrivileges(ctx, targets.Schemas, grantees, changePrivilege)
+	}
+
 	descriptors, err := getDescriptorsFromTargetList(ctx, p.txn, p.getVirtualTabler(), p.session.Database, targets)
 	if err != nil {
 		return nil, err
@@ -72,11 +76,11 @@ func (p *planner) changePrivileges(
 
 // Grant adds privileges to users.
 // Current status:
-// - Target: single database, table, or view.
+// - Target: single database, schema, table, or view.
 // TODO(marc): open questions:
 // - should we have root always allowed and not present in the permissions list?
 // - should we make users case-insensitive?
-// Privileges: GRANT on database/table/view.
+// Privileges: GRANT on database/schema/table/view.
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
 func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
@@ -87,11 +91,11 @@ func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
 
 // Revoke removes privileges from users.
 // Current status:
-// - Target: single database, table, or view.
+// - Target: single database, schema, table, or view.
 // TODO(marc): open questions:
 // - should we have root always allowed and not present in the permissions list?
 // - should we make users case-insensitive?
-// Privileges: GRANT on database/table/view.
+// Privileges: GRANT on database/schema/table/view.
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
 func (p *planner) Revoke(ctx context.Context, n *tree.Revoke) (planNode, error) {
@@ -122,7 +122,10 @@ func (p *planner) makeIndexJoin(
 	// Create a new scanNode that will be used with the primary index.
 	table := p.Scan()
 	table.desc = origScan.desc
-	table.lockForUpdate = origScan.lockForUpdate
+	// Rows are locked by the scan of the primary index, once they've passed
+	// the remainder of the filter.
+	table.locking = origScan.locking
+	indexScan.locking.ForUpdate = false
 	// Note: initDescDefaults can only error out if its 2nd argument is not nil.
 	_ = table.initDescDefaults(origScan.scanVisibility, nil)
 	table.initOrdering(0)
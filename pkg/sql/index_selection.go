@@ -100,8 +100,37 @@ func (p *planner) selectIndex(
 		return s, nil
 	}
 
+	// A partial index can only be used if all the rows which pass the filter
+	// are in the index.
+	var filterConjuncts tree.TypedExprs
+	if s.filter != nil {
+		filterConjuncts = splitAndExpr(&p.evalCtx, s.filter, nil)
+	}
+	usable := func(index *sqlbase.IndexDescriptor) (bool, error) {
+		if !index.IsPartial() {
+			return true, nil
+		}
+		return partialIndexImplied(&p.evalCtx, s, index, filterConjuncts)
+	}
+
+	// Expression indexes can only be constrained by the filter if it refers to
+	// their hidden computed columns.
+	if s.filter != nil {
+		var err error
+		s.filter, err = replaceComputedExprs(&p.evalCtx, s, s.filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	candidates := make([]*indexInfo, 0, len(s.desc.Indexes)+1)
 	if s.specifiedIndex != nil {
+		if ok, err := usable(s.specifiedIndex); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("index \"%s\" is a partial index whose predicate is not implied by the filter",
+				s.specifiedIndex.Name)
+		}
 		// An explicit secondary index was requested. Only add it to the candidate
 		// indexes list.
 		candidates = append(candidates, &indexInfo{
@@ -114,6 +143,11 @@ func (p *planner) selectIndex(
 			index: &s.desc.PrimaryIndex,
 		})
 		for i := range s.desc.Indexes {
+			if ok, err := usable(&s.desc.Indexes[i]); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
 			candidates = append(candidates, &indexInfo{
 				desc:  s.desc,
 				index: &s.desc.Indexes[i],
@@ -267,6 +301,182 @@ func (p *planner) selectIndex(
 	return plan, nil
 }
 
+// partialIndexImplied returns true if the given filter conjuncts imply the
+// predicate of the partial index, i.e. if all the rows which pass the filter
+// are in the index. The check is conservative: each conjunct of the predicate
+// must either appear in the filter, or be implied by a comparison of the same
+// column with a constant in the filter (e.g. `a > 10` implies `a > 0` and
+// `a IS NOT NULL`).
+func partialIndexImplied(
+	evalCtx *tree.EvalContext, s *scanNode, index *sqlbase.IndexDescriptor, filter tree.TypedExprs,
+) (bool, error) {
+	pred, err := s.desc.IndexPredicateExpr(index, s.cols, s)
+	if err != nil {
+		return false, err
+	}
+	pred, err = evalCtx.NormalizeExpr(pred)
+	if err != nil {
+		return false, err
+	}
+PredLoop:
+	for _, p := range splitAndExpr(evalCtx, pred, nil) {
+		if p == tree.DBoolTrue {
+			continue
+		}
+		for _, f := range filter {
+			if f.String() == p.String() || comparisonImplies(evalCtx, f, p) {
+				continue PredLoop
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// replaceComputedExprs replaces the subexpressions of the given filter which
+// are the expression of a computed column of the scanned table, such as the
+// hidden column backing an expression index, with references to the column.
+// Computed columns are stored, so the result is equivalent to the filter.
+func replaceComputedExprs(
+	evalCtx *tree.EvalContext, s *scanNode, filter tree.TypedExpr,
+) (tree.TypedExpr, error) {
+	v := computedExprReplacer{vars: &s.filterVars}
+	for i := range s.cols {
+		col := &s.cols[i]
+		if !col.IsComputed() {
+			continue
+		}
+		expr, err := s.desc.ComputedColumnExpr(col, s.cols, s)
+		if err != nil {
+			return nil, err
+		}
+		expr, err = evalCtx.NormalizeExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+		if v.exprs == nil {
+			v.exprs = make(map[string]int)
+		}
+		v.exprs[expr.String()] = i
+	}
+	if v.exprs == nil {
+		return filter, nil
+	}
+	newFilter, _ := tree.WalkExpr(&v, filter)
+	return newFilter.(tree.TypedExpr), nil
+}
+
+// computedExprReplacer is the visitor used by replaceComputedExprs. exprs maps
+// the normalized expressions of the computed columns to their ordinals in
+// the columns of the scan.
+type computedExprReplacer struct {
+	exprs map[string]int
+	vars  *tree.IndexedVarHelper
+}
+
+var _ tree.Visitor = &computedExprReplacer{}
+
+func (v *computedExprReplacer) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch expr.(type) {
+	case *tree.IndexedVar, tree.Datum:
+		return false, expr
+	}
+	if colIdx, ok := v.exprs[expr.String()]; ok {
+		return false, v.vars.IndexedVar(colIdx)
+	}
+	return true, expr
+}
+
+func (*computedExprReplacer) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// varConstComparison returns the column and the constant of a comparison of
+// the form `@1 op constant`, where op is one of =, !=, <, <=, >, >= and the
+// constant is not NULL; such a comparison is never true for a NULL column.
+func varConstComparison(e tree.TypedExpr) (*tree.ComparisonExpr, *tree.IndexedVar, tree.Datum) {
+	c, ok := e.(*tree.ComparisonExpr)
+	if !ok {
+		return nil, nil, nil
+	}
+	switch c.Operator {
+	case tree.EQ, tree.NE, tree.LT, tree.LE, tree.GT, tree.GE:
+	default:
+		return nil, nil, nil
+	}
+	v, ok := c.Left.(*tree.IndexedVar)
+	if !ok {
+		return nil, nil, nil
+	}
+	d, ok := c.Right.(tree.Datum)
+	if !ok || d == tree.DNull {
+		return nil, nil, nil
+	}
+	return c, v, d
+}
+
+// comparisonImplies returns true if f and p are comparisons of the same column
+// and every value which satisfies f satisfies p.
+func comparisonImplies(evalCtx *tree.EvalContext, f, p tree.TypedExpr) bool {
+	fc, fVar, fVal := varConstComparison(f)
+	if fc == nil {
+		return false
+	}
+	if pc, ok := p.(*tree.ComparisonExpr); ok && pc.Operator == tree.IsNot && pc.Right == tree.DNull {
+		// `@1 IS NOT NULL` is implied by any comparison which rejects NULLs.
+		v, ok := pc.Left.(*tree.IndexedVar)
+		return ok && v.Idx == fVar.Idx
+	}
+	pc, pVar, pVal := varConstComparison(p)
+	if pc == nil || pVar.Idx != fVar.Idx ||
+		!fVal.ResolvedType().Equivalent(pVal.ResolvedType()) {
+		return false
+	}
+	cmp := fVal.Compare(evalCtx, pVal)
+	switch fc.Operator {
+	case tree.EQ:
+		switch pc.Operator {
+		case tree.EQ:
+			return cmp == 0
+		case tree.NE:
+			return cmp != 0
+		case tree.LT:
+			return cmp < 0
+		case tree.LE:
+			return cmp <= 0
+		case tree.GT:
+			return cmp > 0
+		case tree.GE:
+			return cmp >= 0
+		}
+	case tree.NE:
+		return pc.Operator == tree.NE && cmp == 0
+	case tree.LT:
+		switch pc.Operator {
+		case tree.LT, tree.LE, tree.NE:
+			return cmp <= 0
+		}
+	case tree.LE:
+		switch pc.Operator {
+		case tree.LT, tree.NE:
+			return cmp < 0
+		case tree.LE:
+			return cmp <= 0
+		}
+	case tree.GT:
+		switch pc.Operator {
+		case tree.GT, tree.GE, tree.NE:
+			return cmp >= 0
+		}
+	case tree.GE:
+		switch pc.Operator {
+		case tree.GT, tree.NE:
+			return cmp > 0
+		case tree.GE:
+			return cmp >= 0
+		}
+	}
+	return false
+}
+
 type indexConstraint struct {
 	start *tree.ComparisonExpr
 	end   *tree.ComparisonExpr
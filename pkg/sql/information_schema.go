@@ -96,6 +96,19 @@ func dIntFnOrNull(fn func() (int32, bool)) tree.Datum {
 	return tree.DNull
 }
 
+// tableSchemaDatums returns the values of the catalog and schema columns
+// describing a table. information_schema presents databases as schemas of
+// the "def" catalog. The tables of a user-defined schema are presented as
+// part of that schema, in the catalog named after their database.
+func tableSchemaDatums(
+	db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor,
+) (catalog, schema tree.Datum) {
+	if scDesc, ok := db.FindSchemaByID(table.ParentSchemaID); ok {
+		return tree.NewDString(db.Name), tree.NewDString(scDesc.Name)
+	}
+	return defString, tree.NewDString(db.Name)
+}
+
 var informationSchemaColumnsTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE information_schema.columns (
@@ -111,18 +124,23 @@ CREATE TABLE information_schema.columns (
 	CHARACTER_OCTET_LENGTH INT,
 	NUMERIC_PRECISION INT,
 	NUMERIC_SCALE INT,
-	DATETIME_PRECISION INT
+	DATETIME_PRECISION INT,
+	IS_GENERATED STRING NOT NULL DEFAULT '',
+	GENERATION_EXPRESSION STRING
 );
 `,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
-		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+		return forEachTableDescInSchemas(ctx, p, prefix, func(
+			db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor, _ tableLookupFn,
+		) error {
+			catalog, schema := tableSchemaDatums(db, table)
 			// Table descriptors already holds columns in-order.
 			visible := 0
 			return forEachColumnInTable(table, func(column *sqlbase.ColumnDescriptor) error {
 				visible++
 				return addRow(
-					defString,                                // table_catalog
-					tree.NewDString(db.Name),                 // table_schema
+					catalog,                                  // table_catalog
+					schema,                                   // table_schema
 					tree.NewDString(table.Name),              // table_name
 					tree.NewDString(column.Name),             // column_name
 					tree.NewDInt(tree.DInt(visible)),         // ordinal_position, 1-indexed
@@ -134,12 +152,21 @@ CREATE TABLE information_schema.columns (
 					numericPrecision(column.Type),            // numeric_precision
 					numericScale(column.Type),                // numeric_scale
 					datetimePrecision(column.Type),           // datetime_precision
+					isGenerated(column),                      // is_generated
+					dStringPtrOrNull(column.ComputeExpr),     // generation_expression
 				)
 			})
 		})
 	},
 }
 
+func isGenerated(column *sqlbase.ColumnDescriptor) tree.Datum {
+	if column.IsComputed() {
+		return tree.NewDString("ALWAYS")
+	}
+	return tree.NewDString("NEVER")
+}
+
 func characterMaximumLength(colType sqlbase.ColumnType) tree.Datum {
 	return dIntFnOrNull(colType.MaxCharacterLength)
 }
@@ -175,7 +202,7 @@ CREATE TABLE information_schema.key_column_usage (
 	POSITION_IN_UNIQUE_CONSTRAINT INT
 );`,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
-		return forEachTableDescWithTableLookup(ctx, p, prefix, func(
+		return forEachTableDescInSchemas(ctx, p, prefix, func(
 			db *sqlbase.DatabaseDescriptor,
 			table *sqlbase.TableDescriptor,
 			tableLookup tableLookupFn,
@@ -184,6 +211,7 @@ CREATE TABLE information_schema.key_column_usage (
 			if err != nil {
 				return err
 			}
+			catalog, schema := tableSchemaDatums(db, table)
 
 			for name, c := range info {
 				// Only Primary Key, Foreign Key, and Unique constraints are included.
@@ -202,11 +230,11 @@ CREATE TABLE information_schema.key_column_usage (
 						uniquePos = ordinalPos
 					}
 					if err := addRow(
-						defString,                   // constraint_catalog
-						tree.NewDString(db.Name),    // constraint_schema
+						catalog,                     // constraint_catalog
+						schema,                      // constraint_schema
 						dStringOrNull(name),         // constraint_name
-						defString,                   // table_catalog
-						tree.NewDString(db.Name),    // table_schema
+						catalog,                     // table_catalog
+						schema,                      // table_schema
 						tree.NewDString(table.Name), // table_name
 						tree.NewDString(column),     // column_name
 						ordinalPos,                  // ordinal_position, 1-indexed
@@ -231,12 +259,22 @@ CREATE TABLE information_schema.schemata (
 );`,
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
 		return forEachDatabaseDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor) error {
-			return addRow(
+			if err := addRow(
 				defString,                // catalog_name
 				tree.NewDString(db.Name), // schema_name
 				tree.DNull,               // default_character_set_name
 				tree.DNull,               // sql_path
-			)
+			); err != nil {
+				return err
+			}
+			return forEachSchemaDesc(p, db, func(sc *sqlbase.SchemaDescriptor) error {
+				return addRow(
+					tree.NewDString(db.Name), // catalog_name
+					tree.NewDString(sc.Name), // schema_name
+					tree.DNull,               // default_character_set_name
+					tree.DNull,               // sql_path
+				)
+			})
 		})
 	},
 }
@@ -252,13 +290,15 @@ CREATE TABLE information_schema.schema_privileges (
 );
 `,
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
-		return forEachDatabaseDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor) error {
-			for _, u := range db.Privileges.Show() {
+		addPrivileges := func(
+			privs *sqlbase.PrivilegeDescriptor, catalog, schema tree.Datum,
+		) error {
+			for _, u := range privs.Show() {
 				for _, privilege := range u.Privileges {
 					if err := addRow(
 						tree.NewDString(u.User),    // grantee
-						defString,                  // table_catalog,
-						tree.NewDString(db.Name),   // table_schema
+						catalog,                    // table_catalog,
+						schema,                     // table_schema
 						tree.NewDString(privilege), // privilege_type
 						tree.DNull,                 // is_grantable
 					); err != nil {
@@ -267,6 +307,14 @@ CREATE TABLE information_schema.schema_privileges (
 				}
 			}
 			return nil
+		}
+		return forEachDatabaseDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor) error {
+			if err := addPrivileges(db.Privileges, defString, tree.NewDString(db.Name)); err != nil {
+				return err
+			}
+			return forEachSchemaDesc(p, db, func(sc *sqlbase.SchemaDescriptor) error {
+				return addPrivileges(sc.Privileges, tree.NewDString(db.Name), tree.NewDString(sc.Name))
+			})
 		})
 	},
 }
@@ -330,22 +378,25 @@ CREATE TABLE information_schema.statistics (
 	IMPLICIT BOOL NOT NULL DEFAULT FALSE
 );`,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
-		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+		return forEachTableDescInSchemas(ctx, p, prefix, func(
+			db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor, _ tableLookupFn,
+		) error {
+			catalog, schema := tableSchemaDatums(db, table)
 			appendRow := func(index *sqlbase.IndexDescriptor, colName string, sequence int,
 				direction tree.Datum, isStored, isImplicit bool,
 			) error {
 				return addRow(
-					defString,                                 // table_catalog
-					tree.NewDString(db.GetName()),             // table_schema
-					tree.NewDString(table.GetName()),          // table_name
+					catalog,                          // table_catalog
+					schema,                           // table_schema
+					tree.NewDString(table.GetName()), // table_name
 					tree.MakeDBool(tree.DBool(!index.Unique)), // non_unique
-					tree.NewDString(db.GetName()),             // index_schema
-					tree.NewDString(index.Name),               // index_name
-					tree.NewDInt(tree.DInt(sequence)),         // seq_in_index
-					tree.NewDString(colName),                  // column_name
-					tree.DNull,                                // collation
-					tree.DNull,                                // cardinality
-					direction,                                 // direction
+					schema,                                 // index_schema
+					tree.NewDString(index.Name),            // index_name
+					tree.NewDInt(tree.DInt(sequence)),      // seq_in_index
+					tree.NewDString(colName),               // column_name
+					tree.DNull,                             // collation
+					tree.DNull,                             // cardinality
+					direction,                              // direction
 					tree.MakeDBool(tree.DBool(isStored)),   // storing
 					tree.MakeDBool(tree.DBool(isImplicit)), // implicit
 				)
@@ -418,7 +469,7 @@ CREATE TABLE information_schema.table_constraints (
 	INITIALLY_DEFERRED STRING NOT NULL DEFAULT ''
 );`,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
-		return forEachTableDescWithTableLookup(ctx, p, prefix, func(
+		return forEachTableDescInSchemas(ctx, p, prefix, func(
 			db *sqlbase.DatabaseDescriptor,
 			table *sqlbase.TableDescriptor,
 			tableLookup tableLookupFn,
@@ -427,14 +478,15 @@ CREATE TABLE information_schema.table_constraints (
 			if err != nil {
 				return err
 			}
+			catalog, schema := tableSchemaDatums(db, table)
 
 			for name, c := range info {
 				if err := addRow(
-					defString,                       // constraint_catalog
-					tree.NewDString(db.Name),        // constraint_schema
+					catalog,                         // constraint_catalog
+					schema,                          // constraint_schema
 					dStringOrNull(name),             // constraint_name
-					defString,                       // table_catalog
-					tree.NewDString(db.Name),        // table_schema
+					catalog,                         // table_catalog
+					schema,                          // table_schema
 					tree.NewDString(table.Name),     // table_name
 					tree.NewDString(string(c.Kind)), // constraint_type
 					yesOrNoDatum(false),             // is_deferrable
@@ -486,14 +538,17 @@ CREATE TABLE information_schema.table_privileges (
 );
 `,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
-		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+		return forEachTableDescInSchemas(ctx, p, prefix, func(
+			db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor, _ tableLookupFn,
+		) error {
+			catalog, schema := tableSchemaDatums(db, table)
 			for _, u := range table.Privileges.Show() {
 				for _, privilege := range u.Privileges {
 					if err := addRow(
 						tree.DNull,                  // grantor
 						tree.NewDString(u.User),     // grantee
-						defString,                   // table_catalog,
-						tree.NewDString(db.Name),    // table_schema
+						catalog,                     // table_catalog,
+						schema,                      // table_schema
 						tree.NewDString(table.Name), // table_name
 						tree.NewDString(privilege),  // privilege_type
 						tree.DNull,                  // is_grantable
@@ -524,18 +579,21 @@ CREATE TABLE information_schema.tables (
 	VERSION INT
 );`,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
-		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+		return forEachTableDescInSchemas(ctx, p, prefix, func(
+			db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor, _ tableLookupFn,
+		) error {
 			tableType := tableTypeBaseTable
 			if isVirtualDescriptor(table) {
 				tableType = tableTypeSystemView
 			} else if table.IsView() {
 				tableType = tableTypeView
 			}
+			catalog, schema := tableSchemaDatums(db, table)
 			return addRow(
-				defString,                   // table_catalog
-				tree.NewDString(db.Name),    // table_schema
-				tree.NewDString(table.Name), // table_name
-				tableType,                   // table_type
+				catalog,                                // table_catalog
+				schema,                                 // table_schema
+				tree.NewDString(table.Name),            // table_name
+				tableType,                              // table_type
 				tree.NewDInt(tree.DInt(table.Version)), // version
 			)
 		})
@@ -557,10 +615,13 @@ CREATE TABLE information_schema.views (
     IS_TRIGGER_INSERTABLE_INTO BOOL NOT NULL DEFAULT FALSE
 );`,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
-		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+		return forEachTableDescInSchemas(ctx, p, prefix, func(
+			db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor, _ tableLookupFn,
+		) error {
 			if !table.IsView() {
 				return nil
 			}
+			catalog, schema := tableSchemaDatums(db, table)
 			// Note that the view query printed will not include any column aliases
 			// specified outside the initial view query into the definition returned,
 			// unlike Postgres. For example, for the view created via
@@ -570,8 +631,8 @@ CREATE TABLE information_schema.views (
 			// TODO(a-robinson): Insert column aliases into view query once we
 			// have a semantic query representation to work with (#10083).
 			return addRow(
-				defString,                        // table_catalog
-				tree.NewDString(db.Name),         // table_schema
+				catalog,                          // table_catalog
+				schema,                           // table_schema
 				tree.NewDString(table.Name),      // table_name
 				tree.NewDString(table.ViewQuery), // view_definition
 				tree.DNull,                       // check_option
@@ -647,14 +708,15 @@ func forEachTableDesc(
 }
 
 // forEachTableDescAll does the same as forEachTableDesc but also
-// includes newly added non-public descriptors.
+// includes newly added non-public descriptors and the tables of
+// user-defined schemas.
 func forEachTableDescAll(
 	ctx context.Context,
 	p *planner,
 	prefix string,
 	fn func(*sqlbase.DatabaseDescriptor, *sqlbase.TableDescriptor) error,
 ) error {
-	return forEachTableDescWithTableLookupInternal(ctx, p, prefix, true /* allowAdding */, func(
+	return forEachTableDescWithTableLookupInternal(ctx, p, prefix, true /* allowAdding */, true /* includeUserSchemas */, func(
 		db *sqlbase.DatabaseDescriptor,
 		table *sqlbase.TableDescriptor,
 		_ tableLookupFn,
@@ -703,7 +765,44 @@ func forEachTableDescWithTableLookup(
 	prefix string,
 	fn func(*sqlbase.DatabaseDescriptor, *sqlbase.TableDescriptor, tableLookupFn) error,
 ) error {
-	return forEachTableDescWithTableLookupInternal(ctx, p, prefix, false /* allowAdding */, fn)
+	return forEachTableDescWithTableLookupInternal(
+		ctx, p, prefix, false /* allowAdding */, false /* includeUserSchemas */, fn)
+}
+
+// forEachTableDescInSchemas acts like forEachTableDescWithTableLookup,
+// except it also visits the tables of user-defined schemas, after the
+// tables of the public schema of their database. It is used by
+// information_schema, which presents user-defined schemas alongside the
+// databases.
+func forEachTableDescInSchemas(
+	ctx context.Context,
+	p *planner,
+	prefix string,
+	fn func(*sqlbase.DatabaseDescriptor, *sqlbase.TableDescriptor, tableLookupFn) error,
+) error {
+	return forEachTableDescWithTableLookupInternal(
+		ctx, p, prefix, false /* allowAdding */, true /* includeUserSchemas */, fn)
+}
+
+// forEachSchemaDesc iterates through the user-defined schemas of the given
+// database that are visible to the session user, in lexicographical order
+// with respect to their name.
+func forEachSchemaDesc(
+	p *planner, db *sqlbase.DatabaseDescriptor, fn func(*sqlbase.SchemaDescriptor) error,
+) error {
+	schemas := make([]*sqlbase.SchemaDescriptor, 0, len(db.Schemas))
+	for i := range db.Schemas {
+		if userCanSeeDescriptor(&db.Schemas[i], p.session.User) {
+			schemas = append(schemas, &db.Schemas[i])
+		}
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+	for _, sc := range schemas {
+		if err := fn(sc); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // forEachTableDescWithTableLookupInternal is the logic that supports
@@ -711,15 +810,23 @@ func forEachTableDescWithTableLookup(
 //
 // The allowAdding argument if true includes newly added tables that
 // are not yet public.
+//
+// The includeUserSchemas argument if true includes the tables of
+// user-defined schemas, after the tables of the public schema of their
+// database. Otherwise only the tables of the public schema are visited.
 func forEachTableDescWithTableLookupInternal(
 	ctx context.Context,
 	p *planner,
 	prefix string,
 	allowAdding bool,
+	includeUserSchemas bool,
 	fn func(*sqlbase.DatabaseDescriptor, *sqlbase.TableDescriptor, tableLookupFn) error,
 ) error {
 	type dbDescTables struct {
-		desc       *sqlbase.DatabaseDescriptor
+		desc *sqlbase.DatabaseDescriptor
+		// tables is keyed by the name of the schema of the table, followed
+		// by a NUL byte and the name of the table. The name of the public
+		// schema is empty so that its tables sort first.
 		tables     map[string]*sqlbase.TableDescriptor
 		tablesByID map[sqlbase.ID]*sqlbase.TableDescriptor
 	}
@@ -758,8 +865,16 @@ func forEachTableDescWithTableLookupInternal(
 				return errors.Errorf("no database with ID %d found", table.GetParentID())
 			}
 			dbTables := databases[dbName]
-			dbTables.tables[table.Name] = table
 			dbTables.tablesByID[table.ID] = table
+			var scName string
+			if table.ParentSchemaID != 0 {
+				scDesc, ok := dbTables.desc.FindSchemaByID(table.ParentSchemaID)
+				if !ok || !includeUserSchemas {
+					continue
+				}
+				scName = scDesc.Name
+			}
+			dbTables.tables[scName+"\x00"+table.Name] = table
 		}
 	}
 
@@ -767,7 +882,7 @@ func forEachTableDescWithTableLookupInternal(
 	for dbName, schema := range p.session.virtualSchemas.entries {
 		dbTables := make(map[string]*sqlbase.TableDescriptor, len(schema.tables))
 		for tableName, entry := range schema.tables {
-			dbTables[tableName] = entry.desc
+			dbTables["\x00"+tableName] = entry.desc
 		}
 		databases[dbName] = dbDescTables{
 			desc:   schema.desc,
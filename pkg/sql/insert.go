@@ -65,6 +65,10 @@ type insertNode struct {
 
 		rowIdxToRetIdx []int
 		rowTemplate    tree.Datums
+		// computed computes the values of the computed columns in rowTemplate,
+		// which colIDToRetIdx maps column IDs to.
+		computed      *sqlbase.ComputedColumns
+		colIDToRetIdx map[sqlbase.ColumnID]int
 
 		doneUpserting bool
 		rowsUpserted  *sqlbase.RowContainer
@@ -78,7 +82,7 @@ type insertNode struct {
 func (p *planner) Insert(
 	ctx context.Context, n *tree.Insert, desiredTypes []types.T,
 ) (planNode, error) {
-	tn, err := p.getAliasedTableName(n.Table)
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +106,7 @@ func (p *planner) Insert(
 	var cols []sqlbase.ColumnDescriptor
 	// Determine which columns we're inserting into.
 	if n.DefaultValues() {
-		cols = en.tableDesc.Columns
+		cols = writableColumns(en.tableDesc.Columns)
 	} else {
 		var err error
 		if cols, err = p.processColumns(en.tableDesc, n.Columns); err != nil {
@@ -187,6 +191,17 @@ func (p *planner) Insert(
 		if err != nil {
 			return nil, err
 		}
+		// The conflicts are detected using the inserted values, before the
+		// computed columns are computed.
+		for _, colID := range conflictIndex.ColumnIDs {
+			if col, err := en.tableDesc.FindColumnByID(colID); err != nil {
+				return nil, err
+			} else if col.IsComputed() {
+				return nil, pgerror.Unimplemented("upsert computed", fmt.Sprintf(
+					"ON CONFLICT on index %q containing computed column %q not supported",
+					conflictIndex.Name, col.Name))
+			}
+		}
 
 		if n.OnConflict.DoNothing {
 			// TODO(dan): Postgres allows ON CONFLICT DO NOTHING without specifying a
@@ -220,6 +235,10 @@ func (p *planner) Insert(
 				if err != nil {
 					return nil, err
 				}
+				if col.IsComputed() {
+					return nil, pgerror.NewErrorf(pgerror.CodeGeneratedAlwaysError,
+						"cannot write directly to computed column %q", col.Name)
+				}
 				updateCols[i] = col
 			}
 
@@ -297,6 +316,12 @@ func (n *insertNode) Start(params runParams) error {
 		for i, col := range n.insertCols {
 			n.run.rowIdxToRetIdx[i] = colIDToRetIndex[col.ID]
 		}
+
+		computed, err := sqlbase.MakeComputedColumns(n.tableDesc, n.tableDesc.Columns)
+		if err != nil {
+			return err
+		}
+		n.run.computed, n.run.colIDToRetIdx = computed, colIDToRetIndex
 	}
 
 	if err := n.run.startEditNode(params, &n.editNodeBase); err != nil {
@@ -406,6 +431,9 @@ func (n *insertNode) internalNext(params runParams) (bool, error) {
 				n.run.rowTemplate[n.run.rowIdxToRetIdx[i]] = val
 			}
 		}
+		if err := n.run.computed.Eval(n.run.colIDToRetIdx, n.run.rowTemplate); err != nil {
+			return false, err
+		}
 
 		resultRow, err := n.rh.cookResultRow(n.run.rowTemplate)
 		if err != nil {
@@ -451,9 +479,10 @@ func GenerateInsertRow(
 		}
 	}
 
-	// Check to see if NULL is being inserted into any non-nullable column.
+	// Check to see if NULL is being inserted into any non-nullable column. The
+	// computed columns are checked once they are computed.
 	for _, col := range tableDesc.Columns {
-		if !col.Nullable {
+		if !col.Nullable && !col.IsComputed() {
 			if i, ok := insertColIDtoRowIndex[col.ID]; !ok || rowVals[i] == tree.DNull {
 				return nil, sqlbase.NewNonNullViolationError(col.Name)
 			}
@@ -477,7 +506,7 @@ func (p *planner) processColumns(
 		// (as opposed to INSERT INTO <table> (...) VALUES (...)) from writing
 		// hidden columns. At present, the only hidden column is the implicit rowid
 		// primary key column.
-		return tableDesc.VisibleColumns(), nil
+		return writableColumns(tableDesc.VisibleColumns()), nil
 	}
 
 	cols := make([]sqlbase.ColumnDescriptor, len(node))
@@ -496,6 +525,10 @@ func (p *planner) processColumns(
 		if err != nil {
 			return nil, err
 		}
+		if col.IsComputed() {
+			return nil, pgerror.NewErrorf(pgerror.CodeGeneratedAlwaysError,
+				"cannot write directly to computed column %q", col.Name)
+		}
 
 		if _, ok := colIDSet[col.ID]; ok {
 			return nil, fmt.Errorf("multiple assignments to the same column %q", n)
@@ -507,6 +540,18 @@ func (p *planner) processColumns(
 	return cols, nil
 }
 
+// writableColumns returns the columns among cols which aren't computed, and
+// can thus be written directly.
+func writableColumns(cols []sqlbase.ColumnDescriptor) []sqlbase.ColumnDescriptor {
+	res := cols[:0:0]
+	for _, col := range cols {
+		if !col.IsComputed() {
+			res = append(res, col)
+		}
+	}
+	return res
+}
+
 // extractInsertSource removes the parentheses around the data source of an INSERT statement.
 // If the data source is a VALUES clause not further qualified with LIMIT/OFFSET and ORDER BY,
 // the 2nd return value is a pre-casted pointer to the VALUES clause.
@@ -112,16 +112,12 @@ func getTableID(ctx context.Context, p *planner, tn *tree.TableName) (sqlbase.ID
 		return virtual.GetID(), nil
 	}
 
-	txnRunner := func(ctx context.Context, retryable func(ctx context.Context, txn *client.Txn) error) error {
-		return retryable(ctx, p.txn)
-	}
-
-	dbID, err := p.session.tables.databaseCache.getDatabaseID(ctx, txnRunner, p.getVirtualTabler(), tn.Database())
+	parentID, err := p.session.tables.getNamespaceID(ctx, p.txn, p.getVirtualTabler(), tn)
 	if err != nil {
 		return 0, err
 	}
 
-	nameKey := tableKey{dbID, tn.Table()}
+	nameKey := tableKey{parentID, tn.Table()}
 	key := nameKey.Key()
 	gr, err := p.txn.Get(ctx, key)
 	if err != nil {
@@ -34,8 +34,20 @@ const (
 	joinTypeLeftOuter
 	joinTypeRightOuter
 	joinTypeFullOuter
+	// Semi and anti joins produce the rows of the left side which have (semi)
+	// or don't have (anti) a matching row on the right side; their result
+	// columns are those of the left side. They have no SQL syntax and are only
+	// used for decorrelated subqueries (see decorrelateExists).
+	joinTypeSemi
+	joinTypeAnti
 )
 
+// outputsLeftOnly returns true for the join types whose results only contain
+// the columns of the left side.
+func (typ joinType) outputsLeftOnly() bool {
+	return typ == joinTypeSemi || typ == joinTypeAnti
+}
+
 // bucket here is the set of rows for a given group key (comprised of
 // columns specified by the join constraints), 'seen' is used to determine if
 // there was a matching row in the opposite stream.
@@ -120,8 +132,8 @@ func (b *buckets) Fetch(encoding []byte) (*bucket, bool) {
 	return bk, ok
 }
 
-// joinNode is a planNode whose rows are the result of an inner or
-// left/right outer join.
+// joinNode is a planNode whose rows are the result of an inner,
+// left/right/full outer, semi or anti join.
 type joinNode struct {
 	planner  *planner
 	joinType joinType
@@ -171,6 +183,41 @@ type joinNode struct {
 	finishedOutput bool
 }
 
+// newJoinNode creates a joinNode for the given sources and predicate.
+func (p *planner) newJoinNode(
+	typ joinType,
+	left planDataSource,
+	right planDataSource,
+	pred *joinPredicate,
+	columns sqlbase.ResultColumns,
+) *joinNode {
+	n := &joinNode{
+		planner:  p,
+		left:     left,
+		right:    right,
+		joinType: typ,
+		pred:     pred,
+		columns:  columns,
+	}
+
+	n.buffer = &RowBuffer{
+		RowContainer: sqlbase.NewRowContainer(
+			p.session.TxnState.makeBoundAccount(), sqlbase.ColTypeInfoFromResCols(planColumns(n)), 0,
+		),
+	}
+
+	n.bucketsMemAcc = p.session.TxnState.OpenAccount()
+	n.buckets = buckets{
+		buckets: make(map[string]*bucket),
+		rowContainer: sqlbase.NewRowContainer(
+			p.session.TxnState.makeBoundAccount(),
+			sqlbase.ColTypeInfoFromResCols(planColumns(n.right.plan)),
+			0,
+		),
+	}
+	return n
+}
+
 // commonColumns returns the names of columns common on the
 // right and left sides, for use by NATURAL JOIN.
 func commonColumns(left, right *dataSourceInfo) tree.NameList {
@@ -260,30 +307,7 @@ func (p *planner) makeJoin(
 		return planDataSource{}, err
 	}
 
-	n := &joinNode{
-		planner:  p,
-		left:     left,
-		right:    right,
-		joinType: typ,
-		pred:     pred,
-		columns:  info.sourceColumns,
-	}
-
-	n.buffer = &RowBuffer{
-		RowContainer: sqlbase.NewRowContainer(
-			p.session.TxnState.makeBoundAccount(), sqlbase.ColTypeInfoFromResCols(planColumns(n)), 0,
-		),
-	}
-
-	n.bucketsMemAcc = p.session.TxnState.OpenAccount()
-	n.buckets = buckets{
-		buckets: make(map[string]*bucket),
-		rowContainer: sqlbase.NewRowContainer(
-			p.session.TxnState.makeBoundAccount(),
-			sqlbase.ColTypeInfoFromResCols(planColumns(n.right.plan)),
-			0,
-		),
-	}
+	n := p.newJoinNode(typ, left, right, pred, info.sourceColumns)
 
 	joinDataSource := planDataSource{info: info, plan: n}
 
@@ -475,8 +499,13 @@ func (n *joinNode) Start(params runParams) error {
 		return err
 	}
 
-	// Pre-allocate the space for output rows.
+	// Pre-allocate the space for output rows. For semi and anti joins, the
+	// output rows are the left rows; the space is used to evaluate the ON
+	// condition.
 	n.output = make(tree.Datums, len(n.columns))
+	if n.joinType.outputsLeftOnly() {
+		n.output = make(tree.Datums, n.pred.numLeftCols+n.pred.numRightCols)
+	}
 
 	// If needed, pre-allocate left and right rows of NULL tuples for when the
 	// join predicate fails to match.
@@ -540,7 +569,8 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 		return false, nil
 	}
 
-	wantUnmatchedLeft := n.joinType == joinTypeLeftOuter || n.joinType == joinTypeFullOuter
+	wantUnmatchedLeft := n.joinType == joinTypeLeftOuter || n.joinType == joinTypeFullOuter ||
+		n.joinType == joinTypeAnti
 	wantUnmatchedRight := n.joinType == joinTypeRightOuter || n.joinType == joinTypeFullOuter
 
 	if len(n.buckets.Buckets()) == 0 {
@@ -622,8 +652,7 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 			}
 			// We append an empty right row to the left row, adding the result
 			// to our buffer for the subsequent call to Next().
-			n.pred.prepareRow(n.output, lrow, n.emptyRight)
-			if _, err := n.buffer.AddRow(params.ctx, n.output); err != nil {
+			if err := n.addUnmatchedLeftRow(params.ctx, lrow); err != nil {
 				return false, err
 			}
 			return n.buffer.Next(), nil
@@ -639,8 +668,7 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 			// Given that we did not find a matching right row we append an
 			// empty right row to the left row, adding the result to our buffer
 			// for the subsequent call to Next().
-			n.pred.prepareRow(n.output, lrow, n.emptyRight)
-			if _, err := n.buffer.AddRow(params.ctx, n.output); err != nil {
+			if err := n.addUnmatchedLeftRow(params.ctx, lrow); err != nil {
 				return false, err
 			}
 			return n.buffer.Next(), nil
@@ -660,6 +688,11 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 			}
 			foundMatch = true
 
+			if n.joinType.outputsLeftOnly() {
+				// The left row is output at most once: we are done with it.
+				break
+			}
+
 			n.pred.prepareRow(n.output, lrow, rrow)
 			if wantUnmatchedRight {
 				// Mark the row as seen if we need to retrieve the rows
@@ -670,12 +703,16 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 				return false, err
 			}
 		}
+		if foundMatch && n.joinType == joinTypeSemi {
+			if _, err := n.buffer.AddRow(params.ctx, lrow); err != nil {
+				return false, err
+			}
+		}
 		if !foundMatch && wantUnmatchedLeft {
 			// If none of the rows matched the on condition and we are computing a
 			// left or full outer join, we need to add a row with an empty
 			// right side.
-			n.pred.prepareRow(n.output, lrow, n.emptyRight)
-			if _, err := n.buffer.AddRow(params.ctx, n.output); err != nil {
+			if err := n.addUnmatchedLeftRow(params.ctx, lrow); err != nil {
 				return false, err
 			}
 		}
@@ -708,6 +745,18 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 	return n.buffer.Next(), nil
 }
 
+// addUnmatchedLeftRow adds a left row which has no match to the buffer. The
+// row is padded with NULLs for outer joins.
+func (n *joinNode) addUnmatchedLeftRow(ctx context.Context, lrow tree.Datums) error {
+	row := lrow
+	if !n.joinType.outputsLeftOnly() {
+		n.pred.prepareRow(n.output, lrow, n.emptyRight)
+		row = n.output
+	}
+	_, err := n.buffer.AddRow(ctx, row)
+	return err
+}
+
 // Values implements the planNode interface.
 func (n *joinNode) Values() tree.Datums {
 	return n.buffer.Values()
@@ -746,6 +795,14 @@ func (n *joinNode) joinOrdering() physicalProps {
 	leftOrd := planPhysicalProps(n.left.plan)
 	rightOrd := planPhysicalProps(n.right.plan)
 
+	if n.joinType.outputsLeftOnly() {
+		// The results are a subset of the left rows, in the same order; but we
+		// can't rely on that order in DistSQL.
+		info = leftOrd.copy()
+		info.ordering = nil
+		return info
+	}
+
 	// Propagate the equivalency groups for the left columns.
 	for i := 0; i < n.pred.numLeftCols; i++ {
 		if group := leftOrd.eqGroups.Find(i); group != i {
@@ -329,8 +329,13 @@ func (p *joinPredicate) getNeededColumns(neededJoined []bool) ([]bool, []bool) {
 	// are effectively needed.
 	p.onCond = p.iVarHelper.Rebind(p.onCond, true, false)
 
-	// The columns that are part of the expression are always needed.
+	// The columns that are part of the expression are always needed. Semi
+	// and anti joins only produce the left columns; none of the right columns
+	// are needed beyond those used by the predicate.
 	neededJoined = append([]bool(nil), neededJoined...)
+	for len(neededJoined) < p.numLeftCols+p.numRightCols {
+		neededJoined = append(neededJoined, false)
+	}
 	for i := range neededJoined {
 		if p.iVarHelper.IndexedVarUsed(i) {
 			neededJoined[i] = true
@@ -1089,7 +1089,7 @@ func (c *tableNameCache) get(
 	if !nameMatchesTable(&table.TableDescriptor, dbID, tableName) {
 		panic(fmt.Sprintf("Out of sync entry in the name cache. "+
 			"Cache entry: %d.%q -> %d. Lease: %d.%q.",
-			dbID, tableName, table.ID, table.ParentID, table.Name))
+			dbID, tableName, table.ID, table.NamespaceParentID(), table.Name))
 	}
 
 	if !table.leased {
@@ -1111,7 +1111,7 @@ func (c *tableNameCache) insert(table *tableVersionState) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	key := makeTableNameCacheKey(table.ParentID, table.Name)
+	key := makeTableNameCacheKey(table.NamespaceParentID(), table.Name)
 	existing, ok := c.tables[key]
 	if !ok {
 		c.tables[key] = table
@@ -1131,7 +1131,7 @@ func (c *tableNameCache) remove(table *tableVersionState) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	key := makeTableNameCacheKey(table.ParentID, table.Name)
+	key := makeTableNameCacheKey(table.NamespaceParentID(), table.Name)
 	existing, ok := c.tables[key]
 	if !ok {
 		// Table for lease not found in table name cache. This can happen if we had
@@ -1223,7 +1223,7 @@ func NewLeaseManager(
 }
 
 func nameMatchesTable(table *sqlbase.TableDescriptor, dbID sqlbase.ID, tableName string) bool {
-	return table.ParentID == dbID && table.Name == tableName
+	return table.NamespaceParentID() == dbID && table.Name == tableName
 }
 
 // AcquireByName returns a table version for the specified table valid for
@@ -164,6 +164,7 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterTypeNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *scrubNode:
@@ -171,13 +172,18 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSchemaNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSchemaNode:
+	case *dropTypeNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshViewNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
@@ -184,6 +184,7 @@ func setNeededColumns(plan planNode, needed []bool) {
 		setNeededColumns(n.rows, allColumns(n.rows))
 
 	case *alterTableNode:
+	case *alterTypeNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
@@ -191,13 +192,18 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSchemaNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSchemaNode:
+	case *dropTypeNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshViewNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
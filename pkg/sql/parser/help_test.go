@@ -70,6 +70,12 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER DATABASE foo RENAME ??`, `ALTER DATABASE`},
 		{`ALTER DATABASE foo RENAME TO bar ??`, `ALTER DATABASE`},
 
+		{`ALTER SCHEMA ??`, `ALTER SCHEMA`},
+		{`ALTER SCHEMA foo RENAME TO bar ??`, `ALTER SCHEMA`},
+
+		{`ALTER TYPE ??`, `ALTER TYPE`},
+		{`ALTER TYPE foo ADD VALUE ??`, `ALTER TYPE`},
+
 		{`ALTER VIEW IF ??`, `ALTER VIEW`},
 		{`ALTER VIEW blah ??`, `ALTER VIEW`},
 		{`ALTER VIEW blah RENAME ??`, `ALTER VIEW`},
@@ -94,6 +100,12 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE DATABASE IF NOT ??`, `CREATE DATABASE`},
 		{`CREATE DATABASE blih ??`, `CREATE DATABASE`},
 
+		{`CREATE SCHEMA ??`, `CREATE SCHEMA`},
+		{`CREATE SCHEMA IF NOT ??`, `CREATE SCHEMA`},
+
+		{`CREATE TYPE ??`, `CREATE TYPE`},
+		{`CREATE TYPE foo AS ENUM ??`, `CREATE TYPE`},
+
 		{`CREATE USER blih ??`, `CREATE USER`},
 		{`CREATE USER blih WITH ??`, `CREATE USER`},
 
@@ -101,6 +113,7 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE VIEW blah AS (SELECT c FROM x) ??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS SELECT c FROM x ??`, `SELECT`},
 		{`CREATE VIEW blah AS (??`, `<SELECTCLAUSE>`},
+		{`CREATE MATERIALIZED VIEW blah (??`, `CREATE VIEW`},
 
 		{`CREATE TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ??`, `CREATE TABLE`},
@@ -109,6 +122,8 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE TABLE blah AS ??`, `CREATE TABLE`},
 		{`CREATE TABLE blah AS (SELECT 1) ??`, `CREATE TABLE`},
 		{`CREATE TABLE blah AS SELECT 1 ??`, `SELECT`},
+		{`CREATE TEMP TABLE ??`, `CREATE TABLE`},
+		{`CREATE TEMPORARY TABLE blah (x INT) ON COMMIT ??`, `CREATE TABLE`},
 
 		{`DELETE FROM ??`, `DELETE`},
 		{`DELETE FROM blah ??`, `DELETE`},
@@ -116,6 +131,7 @@ func TestContextualHelp(t *testing.T) {
 		{`DELETE FROM blah WHERE x > 3 ??`, `DELETE`},
 
 		{`DISCARD ALL ??`, `DISCARD`},
+		{`DISCARD TEMP ??`, `DISCARD`},
 		{`DISCARD ??`, `DISCARD`},
 
 		{`DROP ??`, `DROP`},
@@ -123,6 +139,12 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP DATABASE IF ??`, `DROP DATABASE`},
 		{`DROP DATABASE IF EXISTS blah ??`, `DROP DATABASE`},
 
+		{`DROP SCHEMA ??`, `DROP SCHEMA`},
+		{`DROP SCHEMA IF EXISTS blah ??`, `DROP SCHEMA`},
+
+		{`DROP TYPE ??`, `DROP TYPE`},
+		{`DROP TYPE IF EXISTS blah ??`, `DROP TYPE`},
+
 		{`DROP INDEX blah, ??`, `DROP INDEX`},
 		{`DROP INDEX blah@blih ??`, `DROP INDEX`},
 
@@ -133,6 +155,7 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP VIEW blah ??`, `DROP VIEW`},
 		{`DROP VIEW IF ??`, `DROP VIEW`},
 		{`DROP VIEW IF EXISTS blih, bloh ??`, `DROP VIEW`},
+		{`DROP MATERIALIZED VIEW blah ??`, `DROP VIEW`},
 
 		{`DROP USER IF ??`, `DROP USER`},
 		{`DROP USER IF EXISTS bloh ??`, `DROP USER`},
@@ -309,6 +332,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SAVEPOINT blah ??`, `SAVEPOINT`},
 
+		{`REFRESH ??`, `REFRESH`},
+		{`REFRESH MATERIALIZED VIEW blah ??`, `REFRESH`},
+
 		{`RELEASE blah ??`, `RELEASE`},
 		{`RELEASE SAVEPOINT blah ??`, `RELEASE`},
 
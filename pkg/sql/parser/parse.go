@@ -162,6 +162,10 @@ func ParseStringAs(t types.T, s string, evalCtx *tree.EvalContext) (tree.Datum,
 		d, err = tree.ParseDTimestamp(s, time.Microsecond)
 	case types.TimestampTZ:
 		d, err = tree.ParseDTimestampTZ(s, evalCtx.GetLocation(), time.Microsecond)
+	case types.Time:
+		d, err = tree.ParseDTime(s)
+	case types.TimeTZ:
+		d, err = tree.ParseDTimeTZ(s, evalCtx.GetLocation())
 	case types.UUID:
 		d, err = tree.ParseDUuidFromString(s)
 	case types.INet:
@@ -176,6 +180,8 @@ func ParseStringAs(t types.T, s string, evalCtx *tree.EvalContext) (tree.Datum,
 			if err != nil {
 				return nil, err
 			}
+		} else if e, ok := t.(types.TEnum); ok {
+			d, err = tree.MakeDEnumFromLogicalRepresentation(e, s)
 		} else {
 			return nil, pgerror.NewErrorf(pgerror.CodeInternalError, "unknown type %s", t)
 		}
@@ -149,6 +149,15 @@ func (u *sqlSymUnion) tablePatterns() tree.TablePatterns {
 func (u *sqlSymUnion) tableNameReferences() tree.TableNameReferences {
     return u.val.(tree.TableNameReferences)
 }
+func (u *sqlSymUnion) schemaName() tree.SchemaName {
+    return u.val.(tree.SchemaName)
+}
+func (u *sqlSymUnion) schemaNames() tree.SchemaNames {
+    return u.val.(tree.SchemaNames)
+}
+func (u *sqlSymUnion) alterTypeAddValuePlacement() *tree.AlterTypeAddValuePlacement {
+    return u.val.(*tree.AlterTypeAddValuePlacement)
+}
 func (u *sqlSymUnion) indexHints() *tree.IndexHints {
     return u.val.(*tree.IndexHints)
 }
@@ -269,6 +278,15 @@ func (u *sqlSymUnion) updateExprs() tree.UpdateExprs {
 func (u *sqlSymUnion) limit() *tree.Limit {
     return u.val.(*tree.Limit)
 }
+func (u *sqlSymUnion) lockingClause() tree.LockingClause {
+    return u.val.(tree.LockingClause)
+}
+func (u *sqlSymUnion) lockingStrength() tree.LockingStrength {
+    return u.val.(tree.LockingStrength)
+}
+func (u *sqlSymUnion) lockingWaitPolicy() tree.LockingWaitPolicy {
+    return u.val.(tree.LockingWaitPolicy)
+}
 func (u *sqlSymUnion) targetList() tree.TargetList {
     return u.val.(tree.TargetList)
 }
@@ -329,6 +347,9 @@ func (u *sqlSymUnion) validationBehavior() tree.ValidationBehavior {
 func (u *sqlSymUnion) interleave() *tree.InterleaveDef {
     return u.val.(*tree.InterleaveDef)
 }
+func (u *sqlSymUnion) onCommitAction() tree.OnCommitAction {
+    return u.val.(tree.OnCommitAction)
+}
 func (u *sqlSymUnion) partitionBy() *tree.PartitionBy {
     return u.val.(*tree.PartitionBy)
 }
@@ -408,18 +429,18 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str>   ACTION ADD
+%token <str>   ACTION ADD AFTER
 %token <str>   ALL ALL_EXISTENCE ALTER ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str>   ASYMMETRIC AT
 
-%token <str>   BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
+%token <str>   BACKUP BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str>   BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
 %token <str>   CANCEL CASCADE CASE CAST CHAR
 %token <str>   CHARACTER CHARACTERISTICS CHECK
 %token <str>   CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
 %token <str>   COMMITTED CONCAT CONFIGURATION CONFIGURATIONS CONFIGURE
-%token <str>   CONFLICT CONSTRAINT CONSTRAINTS CONTAINS COPY COVERING CREATE
+%token <str>   CONCURRENTLY CONFLICT CONSTRAINT CONSTRAINTS CONTAINS COPY COVERING CREATE
 %token <str>   CROSS CSV CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
 %token <str>   CURRENT_ROLE CURRENT_TIME CURRENT_TIMESTAMP
 %token <str>   CURRENT_USER CYCLE
@@ -428,7 +449,7 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %token <str>   DEALLOCATE DEFERRABLE DELETE DESC
 %token <str>   DISCARD DISTINCT DO DOUBLE DROP
 
-%token <str>   ELSE ENCODING END ESCAPE EXCEPT
+%token <str>   ELSE ENCODING END ENUM ESCAPE EXCEPT
 %token <str>   EXISTS EXECUTE EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL
 %token <str>   EXPLAIN EXTRACT EXTRACT_DURATION
 
@@ -450,36 +471,36 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 
 %token <str>   LATERAL LC_CTYPE LC_COLLATE
 %token <str>   LEADING LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
-%token <str>   LOCALTIME LOCALTIMESTAMP LOW LSHIFT
+%token <str>   LOCALTIME LOCALTIMESTAMP LOCKED LOW LSHIFT
 
-%token <str>   MATCH MAXVALUE MINUTE MONTH
+%token <str>   MATCH MATERIALIZED MAXVALUE MINUTE MONTH
 
 %token <str>   NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
-%token <str>   NOT NOTHING NULL NULLIF
+%token <str>   NOT NOTHING NOWAIT NULL NULLIF
 %token <str>   NULLS NUMERIC
 
 %token <str>   OF OFF OFFSET OID ON ONLY OPTIONS OR
 %token <str>   ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY
 
 %token <str>   PARENT PARTIAL PARTITION PASSWORD PAUSE PHYSICAL PLACING
-%token <str>   PLANS POSITION PRECEDING PRECISION PREPARE PRIMARY PRIORITY
+%token <str>   PLANS POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIORITY
 
 %token <str>   QUERIES QUERY
 
-%token <str>   RANGE READ REAL RECURSIVE REF REFERENCES
+%token <str>   RANGE READ REAL RECURSIVE REF REFERENCES REFRESH
 %token <str>   REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str>   REMOVE_PATH RENAME REPEATABLE
 %token <str>   RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
 %token <str>   ROLLBACK ROLLUP ROW ROWS RSHIFT
 
-%token <str>   SAVEPOINT SCATTER SCRUB SEARCH SECOND SELECT SEQUENCES
+%token <str>   SAVEPOINT SCATTER SCHEMA SCRUB SEARCH SECOND SELECT SEQUENCES
 %token <str>   SERIAL SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
-%token <str>   SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SOME_EXISTENCE SPLIT SQL
-%token <str>   START STATUS STDIN STRICT STRING STORE STORING SUBSTRING
+%token <str>   SHARE SHOW SIMILAR SIMPLE SKIP SMALLINT SMALLSERIAL SNAPSHOT SOME SOME_EXISTENCE SPLIT SQL
+%token <str>   START STATUS STDIN STRICT STRING STORE STORED STORING SUBSTRING
 %token <str>   SYMMETRIC SYSTEM
 
 %token <str>   TABLE TABLES TEMP TEMPLATE TEMPORARY TESTING_RANGES TESTING_RELOCATE TEXT THAN THEN
-%token <str>   TIME TIMESTAMP TIMESTAMPTZ TO TRAILING TRACE TRANSACTION TREAT TRIM TRUE
+%token <str>   TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO TRAILING TRACE TRANSACTION TREAT TRIM TRUE
 %token <str>   TRUNCATE TYPE
 
 %token <str>   UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN
@@ -539,6 +560,13 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> alter_rename_database_stmt
 %type <tree.Statement> alter_zone_database_stmt
 
+// ALTER SCHEMA
+%type <tree.Statement> alter_schema_stmt
+%type <tree.Statement> alter_rename_schema_stmt
+
+// ALTER TYPE
+%type <tree.Statement> alter_type_stmt
+
 // ALTER USER
 %type <tree.Statement> alter_user_password_stmt
 
@@ -573,6 +601,8 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> create_ddl_stmt
 %type <tree.Statement> create_database_stmt
 %type <tree.Statement> create_index_stmt
+%type <tree.Statement> create_schema_stmt
+%type <tree.Statement> create_type_stmt
 %type <tree.Statement> create_table_stmt
 %type <tree.Statement> create_table_as_stmt
 %type <tree.Statement> create_user_stmt
@@ -584,6 +614,8 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> drop_ddl_stmt
 %type <tree.Statement> drop_database_stmt
 %type <tree.Statement> drop_index_stmt
+%type <tree.Statement> drop_schema_stmt
+%type <tree.Statement> drop_type_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_user_stmt
 %type <tree.Statement> drop_view_stmt
@@ -598,6 +630,7 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> insert_stmt
 %type <tree.Statement> import_stmt
 %type <tree.Statement> pause_stmt
+%type <tree.Statement> refresh_stmt
 %type <tree.Statement> release_stmt
 %type <tree.Statement> reset_stmt reset_session_stmt reset_csetting_stmt
 %type <tree.Statement> resume_stmt
@@ -646,6 +679,8 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> use_stmt
 
 %type <[]string> opt_incremental
+%type <[]string> enum_val_list opt_enum_val_list
+%type <*tree.AlterTypeAddValuePlacement> opt_add_val_placement
 %type <tree.KVOption> kv_option
 %type <[]tree.KVOption> kv_option_list opt_with_options
 %type <str> import_data_format
@@ -696,6 +731,7 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 
 %type <tree.TableDefs> opt_table_elem_list table_elem_list
 %type <*tree.InterleaveDef> opt_interleave
+%type <tree.OnCommitAction> opt_on_commit
 %type <*tree.PartitionBy> opt_partition_by partition_by
 %type <str> partition opt_partition
 %type <tree.ListPartition> list_partition
@@ -709,9 +745,11 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <[]*tree.Order> sortby_list
 %type <tree.IndexElemList> index_params
 %type <tree.NameList> name_list opt_name_list
+%type <tree.SchemaName> schema_name
+%type <tree.SchemaNames> schema_name_list
 %type <[]int32> opt_array_bounds
-%type <*tree.From> from_clause update_from_clause
-%type <tree.TableExprs> from_list
+%type <*tree.From> from_clause
+%type <tree.TableExprs> from_list update_from_clause delete_using_clause
 %type <tree.UnresolvedNames> qualified_name_list
 %type <tree.TablePatterns> table_pattern_list
 %type <tree.UnresolvedName> any_name
@@ -743,12 +781,14 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.DurationField> opt_interval interval_second
 %type <tree.Expr> overlay_placing
 
-%type <bool> opt_unique opt_column
+%type <bool> opt_unique opt_column opt_concurrently opt_temp
 
 %type <empty> opt_set_data
 
 %type <*tree.Limit> limit_clause offset_clause opt_limit_clause
-%type <bool> for_locking_clause opt_for_locking_clause
+%type <tree.LockingClause> for_locking_clause opt_for_locking_clause
+%type <tree.LockingStrength> for_locking_strength
+%type <tree.LockingWaitPolicy> opt_nowait_or_skip
 %type <tree.Expr>  select_limit_value
 %type <tree.Expr> opt_select_fetch_first_value
 %type <empty> row_or_rows
@@ -975,6 +1015,7 @@ stmt:
   {
     $$.val = $1.slct()
   }
+| refresh_stmt     // EXTEND WITH HELP: REFRESH
 | release_stmt     // EXTEND WITH HELP: RELEASE
 | reset_stmt       // help texts in sub-rule
 | set_stmt         // help texts in sub-rule
@@ -990,7 +1031,8 @@ stmt:
 
 // %Help: ALTER
 // %Category: Group
-// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER DATABASE, ALTER USER
+// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER DATABASE, ALTER SCHEMA, ALTER TYPE,
+// ALTER USER
 alter_stmt:
   alter_ddl_stmt      // help texts in sub-rule
 | alter_user_stmt     // EXTEND WITH HELP: ALTER USER
@@ -1001,6 +1043,8 @@ alter_ddl_stmt:
 | alter_index_stmt    // EXTEND WITH HELP: ALTER INDEX
 | alter_view_stmt     // EXTEND WITH HELP: ALTER VIEW
 | alter_database_stmt // EXTEND WITH HELP: ALTER DATABASE
+| alter_schema_stmt   // EXTEND WITH HELP: ALTER SCHEMA
+| alter_type_stmt     // EXTEND WITH HELP: ALTER TYPE
 | alter_range_stmt
 
 // %Help: ALTER TABLE - change the definition of a table
@@ -1023,6 +1067,7 @@ alter_ddl_stmt:
 //
 // Column qualifiers:
 //   [CONSTRAINT <constraintname>] {NULL | NOT NULL | UNIQUE | PRIMARY KEY | CHECK (<expr>) | DEFAULT <expr>}
+//   AS ( <expr> ) STORED
 //   FAMILY <familyname>, CREATE [IF NOT EXISTS] FAMILY [<familyname>]
 //   REFERENCES <tablename> [( <colnames...> )]
 //   COLLATE <collationname>
@@ -1071,6 +1116,54 @@ alter_database_stmt:
 // prefix is spread over multiple non-terminals.
 | ALTER DATABASE error // SHOW HELP: ALTER DATABASE
 
+// %Help: ALTER SCHEMA - change the definition of a schema
+// %Category: DDL
+// %Text:
+// ALTER SCHEMA [<databasename> .] <name> RENAME TO <newname>
+// %SeeAlso: CREATE SCHEMA, DROP SCHEMA
+alter_schema_stmt:
+  alter_rename_schema_stmt
+| ALTER SCHEMA error // SHOW HELP: ALTER SCHEMA
+
+// %Help: ALTER TYPE - change the definition of a user-defined type
+// %Category: DDL
+// %Text:
+// ALTER TYPE <name> ADD VALUE [IF NOT EXISTS] <label> [{BEFORE | AFTER} <label>]
+// %SeeAlso: CREATE TYPE, DROP TYPE
+alter_type_stmt:
+  ALTER TYPE name ADD VALUE SCONST opt_add_val_placement
+  {
+    $$.val = &tree.AlterTypeAddValue{
+      Name: tree.Name($3),
+      NewVal: $6,
+      Placement: $7.alterTypeAddValuePlacement(),
+    }
+  }
+| ALTER TYPE name ADD VALUE IF NOT EXISTS SCONST opt_add_val_placement
+  {
+    $$.val = &tree.AlterTypeAddValue{
+      Name: tree.Name($3),
+      NewVal: $9,
+      IfNotExists: true,
+      Placement: $10.alterTypeAddValuePlacement(),
+    }
+  }
+| ALTER TYPE error // SHOW HELP: ALTER TYPE
+
+opt_add_val_placement:
+  BEFORE SCONST
+  {
+    $$.val = &tree.AlterTypeAddValuePlacement{Before: true, ExistingVal: $2}
+  }
+| AFTER SCONST
+  {
+    $$.val = &tree.AlterTypeAddValuePlacement{Before: false, ExistingVal: $2}
+  }
+| /* EMPTY */
+  {
+    $$.val = (*tree.AlterTypeAddValuePlacement)(nil)
+  }
+
 alter_range_stmt:
   alter_zone_range_stmt
 
@@ -1548,8 +1641,8 @@ cancel_query_stmt:
 // %Help: CREATE
 // %Category: Group
 // %Text:
-// CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
-// CREATE USER, CREATE VIEW
+// CREATE DATABASE, CREATE SCHEMA, CREATE TABLE, CREATE INDEX,
+// CREATE TABLE AS, CREATE TYPE, CREATE USER, CREATE VIEW
 create_stmt:
   create_user_stmt     // EXTEND WITH HELP: CREATE USER
 | create_ddl_stmt      // help texts in sub-rule
@@ -1558,34 +1651,49 @@ create_stmt:
 create_ddl_stmt:
   create_database_stmt // EXTEND WITH HELP: CREATE DATABASE
 | create_index_stmt    // EXTEND WITH HELP: CREATE INDEX
+| create_schema_stmt   // EXTEND WITH HELP: CREATE SCHEMA
 | create_table_stmt    // EXTEND WITH HELP: CREATE TABLE
 | create_table_as_stmt // EXTEND WITH HELP: CREATE TABLE
+| create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
-| CREATE TABLE error   // SHOW HELP: CREATE TABLE
+| CREATE opt_temp TABLE error // SHOW HELP: CREATE TABLE
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 
 
 // %Help: DELETE - delete rows from a table
 // %Category: DML
-// %Text: DELETE FROM <tablename> [WHERE <expr>]
+// %Text: DELETE FROM <tablename> [[AS] <name>]
+//               [USING <source> [, ...]]
+//               [WHERE <expr>]
 //               [LIMIT <expr>]
 //               [RETURNING <exprs...>]
 // %SeeAlso: WEBDOCS/delete.html
 delete_stmt:
-  opt_with_clause DELETE FROM relation_expr_opt_alias where_clause opt_limit_clause returning_clause
+  opt_with_clause DELETE FROM relation_expr_opt_alias delete_using_clause where_clause opt_limit_clause returning_clause
   {
     $$.val = &tree.Delete{
       Table: $4.tblExpr(),
-      Where: tree.NewWhere(tree.AstWhere, $5.expr()),
-      Limit: $6.limit(),
-      Returning: $7.retClause(),
+      Using: $5.tblExprs(),
+      Where: tree.NewWhere(tree.AstWhere, $6.expr()),
+      Limit: $7.limit(),
+      Returning: $8.retClause(),
     }
   }
 | opt_with_clause DELETE error // SHOW HELP: DELETE
 
+delete_using_clause:
+  USING from_list
+  {
+    $$.val = $2.tblExprs()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.TableExprs(nil)
+  }
+
 // %Help: DISCARD - reset the session to its initial state
 // %Category: Cfg
-// %Text: DISCARD ALL
+// %Text: DISCARD { ALL | TEMP }
 discard_stmt:
   DISCARD ALL
   {
@@ -1593,13 +1701,19 @@ discard_stmt:
   }
 | DISCARD PLANS { return unimplemented(sqllex, "discard plans") }
 | DISCARD SEQUENCES { return unimplemented(sqllex, "discard sequences") }
-| DISCARD TEMP { return unimplemented(sqllex, "discard temp") }
-| DISCARD TEMPORARY { return unimplemented(sqllex, "discard temporary") }
+| DISCARD TEMP
+  {
+    $$.val = &tree.Discard{Mode: tree.DiscardModeTemp}
+  }
+| DISCARD TEMPORARY
+  {
+    $$.val = &tree.Discard{Mode: tree.DiscardModeTemp}
+  }
 | DISCARD error // SHOW HELP: DISCARD
 
 // %Help: DROP
 // %Category: Group
-// %Text: DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP USER
+// %Text: DROP DATABASE, DROP SCHEMA, DROP INDEX, DROP TABLE, DROP TYPE, DROP VIEW, DROP USER
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
@@ -1608,12 +1722,14 @@ drop_stmt:
 drop_ddl_stmt:
   drop_database_stmt // EXTEND WITH HELP: DROP DATABASE
 | drop_index_stmt    // EXTEND WITH HELP: DROP INDEX
+| drop_schema_stmt   // EXTEND WITH HELP: DROP SCHEMA
 | drop_table_stmt    // EXTEND WITH HELP: DROP TABLE
+| drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
-// %Text: DROP VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
+// %Text: DROP [MATERIALIZED] VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
 // %SeeAlso: WEBDOCS/drop-index.html
 drop_view_stmt:
   DROP VIEW table_name_list opt_drop_behavior
@@ -1624,7 +1740,26 @@ drop_view_stmt:
   {
     $$.val = &tree.DropView{Names: $5.tableNameReferences(), IfExists: true, DropBehavior: $6.dropBehavior()}
   }
+| DROP MATERIALIZED VIEW table_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropView{
+      Names: $4.tableNameReferences(),
+      IfExists: false,
+      DropBehavior: $5.dropBehavior(),
+      Materialized: true,
+    }
+  }
+| DROP MATERIALIZED VIEW IF EXISTS table_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropView{
+      Names: $6.tableNameReferences(),
+      IfExists: true,
+      DropBehavior: $7.dropBehavior(),
+      Materialized: true,
+    }
+  }
 | DROP VIEW error // SHOW HELP: DROP VIEW
+| DROP MATERIALIZED VIEW error // SHOW HELP: DROP VIEW
 
 // %Help: DROP TABLE - remove a table
 // %Category: DDL
@@ -1687,6 +1822,36 @@ drop_database_stmt:
   }
 | DROP DATABASE error // SHOW HELP: DROP DATABASE
 
+// %Help: DROP SCHEMA - remove a schema
+// %Category: DDL
+// %Text: DROP SCHEMA [IF EXISTS] [<databasename> .] <name> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE SCHEMA
+drop_schema_stmt:
+  DROP SCHEMA schema_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropSchema{Names: $3.schemaNames(), IfExists: false, DropBehavior: $4.dropBehavior()}
+  }
+| DROP SCHEMA IF EXISTS schema_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropSchema{Names: $5.schemaNames(), IfExists: true, DropBehavior: $6.dropBehavior()}
+  }
+| DROP SCHEMA error // SHOW HELP: DROP SCHEMA
+
+// %Help: DROP TYPE - remove a user-defined type
+// %Category: DDL
+// %Text: DROP TYPE [IF EXISTS] <name> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE TYPE, ALTER TYPE
+drop_type_stmt:
+  DROP TYPE name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{Names: $3.nameList(), IfExists: false, DropBehavior: $4.dropBehavior()}
+  }
+| DROP TYPE IF EXISTS name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{Names: $5.nameList(), IfExists: true, DropBehavior: $6.dropBehavior()}
+  }
+| DROP TYPE error // SHOW HELP: DROP TYPE
+
 // %Help: DROP USER - remove a user
 // %Category: Priv
 // %Text: DROP USER [IF EXISTS] <user> [, ...]
@@ -1737,6 +1902,7 @@ attrs:
 // %Text:
 // EXPLAIN <statement>
 // EXPLAIN [( [PLAN ,] <planoptions...> )] <statement>
+// EXPLAIN ANALYZE [(DISTSQL)] <statement>
 //
 // Explainable statements:
 //     SELECT, CREATE, DROP, ALTER, INSERT, UPSERT, UPDATE, DELETE,
@@ -1745,6 +1911,9 @@ attrs:
 // Plan options:
 //     TYPES, EXPRS, METADATA, QUALIFY, INDENT, VERBOSE, DIST_SQL
 //
+// EXPLAIN ANALYZE executes the statement and reports the statistics
+// collected while running it through DistSQL.
+//
 // %SeeAlso: WEBDOCS/explain.html
 explain_stmt:
   EXPLAIN explainable_stmt
@@ -1756,6 +1925,14 @@ explain_stmt:
   {
     $$.val = &tree.Explain{Options: $3.strs(), Statement: $5.stmt()}
   }
+| EXPLAIN ANALYZE explainable_stmt
+  {
+    $$.val = &tree.Explain{Analyze: true, Statement: $3.stmt()}
+  }
+| EXPLAIN ANALYZE '(' explain_option_list ')' explainable_stmt
+  {
+    $$.val = &tree.Explain{Options: $4.strs(), Analyze: true, Statement: $6.stmt()}
+  }
 // This second error rule is necessary, because otherwise
 // explainable_stmt also provides "selectclause := '(' error ..."  and
 // cause a help text for the select clause, which will be confusing in
@@ -1890,6 +2067,7 @@ deallocate_stmt:
 //
 // Targets:
 //   DATABASE <databasename> [, ...]
+//   SCHEMA [<databasename> .] <schemaname> [, ...]
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //
 // %SeeAlso: REVOKE, WEBDOCS/grant.html
@@ -1910,6 +2088,7 @@ grant_stmt:
 //
 // Targets:
 //   DATABASE <databasename> [, <databasename>]...
+//   SCHEMA [<databasename> .] <schemaname> [, ...]
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //
 // %SeeAlso: GRANT, WEBDOCS/revoke.html
@@ -1933,6 +2112,10 @@ targets:
   {
     $$.val = tree.TargetList{Databases: $2.nameList()}
   }
+| SCHEMA schema_name_list
+  {
+    $$.val = tree.TargetList{Schemas: $2.schemaNames()}
+  }
 
 // ALL is always by itself.
 privileges:
@@ -2687,8 +2870,8 @@ pause_stmt:
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
 // %Text:
-// CREATE TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
-// CREATE TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
+// CREATE [TEMP] TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>] [<on_commit>]
+// CREATE [TEMP] TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] [<on_commit>] AS <source>
 //
 // Table elements:
 //    <name> <type> [<qualifiers...>]
@@ -2705,6 +2888,7 @@ pause_stmt:
 //
 // Column qualifiers:
 //   [CONSTRAINT <constraintname>] {NULL | NOT NULL | UNIQUE | PRIMARY KEY | CHECK (<expr>) | DEFAULT <expr>}
+//   AS ( <expr> ) STORED
 //   FAMILY <familyname>, CREATE [IF NOT EXISTS] FAMILY [<familyname>]
 //   REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}]
 //   COLLATE <collationname>
@@ -2712,35 +2896,72 @@ pause_stmt:
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
 //
-// %SeeAlso: SHOW TABLES, CREATE VIEW, SHOW CREATE TABLE,
+// On commit clause (temporary tables only):
+//    ON COMMIT {PRESERVE ROWS | DELETE ROWS | DROP}
+//
+// %SeeAlso: SHOW TABLES, CREATE VIEW, SHOW CREATE TABLE, DISCARD,
 // WEBDOCS/create-table.html
 // WEBDOCS/create-table-as.html
 create_table_stmt:
-  CREATE TABLE any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
+  CREATE opt_temp TABLE any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by opt_on_commit
   {
     $$.val = &tree.CreateTable{
-      Table: $3.normalizableTableName(),
+      Table: $4.normalizableTableName(),
       IfNotExists: false,
-      Interleave: $7.interleave(),
-      Defs: $5.tblDefs(),
+      Temporary: $2.bool(),
+      Interleave: $8.interleave(),
+      Defs: $6.tblDefs(),
       AsSource: nil,
       AsColumnNames: nil,
-      PartitionBy: $8.partitionBy(),
+      PartitionBy: $9.partitionBy(),
+      OnCommit: $10.onCommitAction(),
     }
   }
-| CREATE TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave
+| CREATE opt_temp TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave opt_on_commit
   {
-    $$.val = &tree.CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: $10.interleave(), Defs: $8.tblDefs(), AsSource: nil, AsColumnNames: nil}
+    $$.val = &tree.CreateTable{Table: $7.normalizableTableName(), IfNotExists: true, Temporary: $2.bool(), Interleave: $11.interleave(), Defs: $9.tblDefs(), AsSource: nil, AsColumnNames: nil, OnCommit: $12.onCommitAction()}
   }
 
 create_table_as_stmt:
-  CREATE TABLE any_name opt_column_list AS select_stmt
+  CREATE opt_temp TABLE any_name opt_column_list opt_on_commit AS select_stmt
   {
-    $$.val = &tree.CreateTable{Table: $3.normalizableTableName(), IfNotExists: false, Interleave: nil, Defs: nil, AsSource: $6.slct(), AsColumnNames: $4.nameList()}
+    $$.val = &tree.CreateTable{Table: $4.normalizableTableName(), IfNotExists: false, Temporary: $2.bool(), Interleave: nil, Defs: nil, AsSource: $8.slct(), AsColumnNames: $5.nameList(), OnCommit: $6.onCommitAction()}
   }
-| CREATE TABLE IF NOT EXISTS any_name opt_column_list AS select_stmt
+| CREATE opt_temp TABLE IF NOT EXISTS any_name opt_column_list opt_on_commit AS select_stmt
   {
-    $$.val = &tree.CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: nil, Defs: nil, AsSource: $9.slct(), AsColumnNames: $7.nameList()}
+    $$.val = &tree.CreateTable{Table: $7.normalizableTableName(), IfNotExists: true, Temporary: $2.bool(), Interleave: nil, Defs: nil, AsSource: $11.slct(), AsColumnNames: $8.nameList(), OnCommit: $9.onCommitAction()}
+  }
+
+opt_temp:
+  TEMP
+  {
+    $$.val = true
+  }
+| TEMPORARY
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+opt_on_commit:
+  ON COMMIT PRESERVE ROWS
+  {
+    $$.val = tree.OnCommitPreserveRows
+  }
+| ON COMMIT DELETE ROWS
+  {
+    $$.val = tree.OnCommitDeleteRows
+  }
+| ON COMMIT DROP
+  {
+    $$.val = tree.OnCommitDrop
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.OnCommitPreserveRows
   }
 
 opt_table_elem_list:
@@ -2963,6 +3184,10 @@ col_qualification_elem:
   {
     $$.val = &tree.ColumnDefault{Expr: $2.expr()}
   }
+| AS '(' a_expr ')' STORED
+  {
+    $$.val = &tree.ColumnComputedDef{Expr: $3.expr()}
+  }
 | REFERENCES qualified_name opt_name_parens key_match reference_actions
  {
     $$.val = &tree.ColumnFKConstraint{
@@ -2973,16 +3198,17 @@ col_qualification_elem:
  }
 
 index_def:
-  INDEX opt_name '(' index_params ')' opt_storing opt_interleave
+  INDEX opt_name '(' index_params ')' opt_storing opt_interleave where_clause
   {
     $$.val = &tree.IndexTableDef{
       Name:    tree.Name($2),
       Columns: $4.idxElems(),
       Storing: $6.nameList(),
       Interleave: $7.interleave(),
+      Predicate: $8.expr(),
     }
   }
-| UNIQUE INDEX opt_name '(' index_params ')' opt_storing opt_interleave
+| UNIQUE INDEX opt_name '(' index_params ')' opt_storing opt_interleave where_clause
   {
     $$.val = &tree.UniqueConstraintTableDef{
       IndexTableDef: tree.IndexTableDef {
@@ -2990,6 +3216,7 @@ index_def:
         Columns: $5.idxElems(),
         Storing: $7.nameList(),
         Interleave: $8.interleave(),
+        Predicate: $9.expr(),
       },
     }
   }
@@ -3206,8 +3433,11 @@ opt_password:
 
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
-// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
-// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, WEBDOCS/create-view.html
+// %Text: CREATE [MATERIALIZED] VIEW <viewname> [( <colnames...> )] AS <source>
+//
+// The results of a materialized view are stored when it is created, and
+// recomputed by REFRESH MATERIALIZED VIEW.
+// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, REFRESH, WEBDOCS/create-view.html
 create_view_stmt:
   CREATE VIEW any_name opt_column_list AS select_stmt
   {
@@ -3217,7 +3447,17 @@ create_view_stmt:
       AsSource: $6.slct(),
     }
   }
+| CREATE MATERIALIZED VIEW any_name opt_column_list AS select_stmt
+  {
+    $$.val = &tree.CreateView{
+      Name: $4.normalizableTableName(),
+      ColumnNames: $5.nameList(),
+      AsSource: $7.slct(),
+      Materialized: true,
+    }
+  }
 | CREATE VIEW error // SHOW HELP: CREATE VIEW
+| CREATE MATERIALIZED VIEW error // SHOW HELP: CREATE VIEW
 
 // TODO(a-robinson): CREATE OR REPLACE VIEW support (#2971).
 
@@ -3226,7 +3466,10 @@ create_view_stmt:
 // %Text:
 // CREATE [UNIQUE] INDEX [IF NOT EXISTS] [<idxname>]
 //        ON <tablename> ( <colname> [ASC | DESC] [, ...] )
-//        [STORING ( <colnames...> )] [<interleave>]
+//        [STORING ( <colnames...> )] [<interleave>] [WHERE <predicate>]
+//
+// A partial index, created with a WHERE clause, only contains the rows which
+// satisfy the predicate.
 //
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
@@ -3234,7 +3477,7 @@ create_view_stmt:
 // %SeeAlso: CREATE TABLE, SHOW INDEXES, SHOW CREATE INDEX,
 // WEBDOCS/create-index.html
 create_index_stmt:
-  CREATE opt_unique INDEX opt_name ON qualified_name '(' index_params ')' opt_storing opt_interleave
+  CREATE opt_unique INDEX opt_name ON qualified_name '(' index_params ')' opt_storing opt_interleave where_clause
   {
     $$.val = &tree.CreateIndex{
       Name:    tree.Name($4),
@@ -3243,9 +3486,10 @@ create_index_stmt:
       Columns: $8.idxElems(),
       Storing: $10.nameList(),
       Interleave: $11.interleave(),
+      Predicate: $12.expr(),
     }
   }
-| CREATE opt_unique INDEX IF NOT EXISTS name ON qualified_name '(' index_params ')' opt_storing opt_interleave
+| CREATE opt_unique INDEX IF NOT EXISTS name ON qualified_name '(' index_params ')' opt_storing opt_interleave where_clause
   {
     $$.val = &tree.CreateIndex{
       Name:        tree.Name($7),
@@ -3255,6 +3499,7 @@ create_index_stmt:
       Columns:     $11.idxElems(),
       Storing:     $13.nameList(),
       Interleave: $14.interleave(),
+      Predicate:   $15.expr(),
     }
   }
 | CREATE opt_unique INDEX error // SHOW HELP: CREATE INDEX
@@ -3287,8 +3532,14 @@ index_elem:
   {
     $$.val = tree.IndexElem{Column: tree.Name($1), Direction: $3.dir()}
   }
-| func_expr_windowless opt_collate opt_asc_desc { return unimplemented(sqllex, "index_elem func expr") }
-| '(' a_expr ')' opt_collate opt_asc_desc { return unimplemented(sqllex, "index_elem a_expr") }
+| func_expr_windowless opt_collate opt_asc_desc
+  {
+    $$.val = tree.IndexElem{Expr: $1.expr(), Direction: $3.dir()}
+  }
+| '(' a_expr ')' opt_collate opt_asc_desc
+  {
+    $$.val = tree.IndexElem{Expr: $2.expr(), Direction: $5.dir()}
+  }
 
 opt_collate:
   COLLATE unrestricted_name { return unimplementedWithIssue(sqllex, 16619) }
@@ -3314,6 +3565,12 @@ alter_rename_database_stmt:
     $$.val = &tree.RenameDatabase{Name: tree.Name($3), NewName: tree.Name($6)}
   }
 
+alter_rename_schema_stmt:
+  ALTER SCHEMA schema_name RENAME TO name
+  {
+    $$.val = &tree.RenameSchema{Schema: $3.schemaName(), NewName: tree.Name($6)}
+  }
+
 // https://www.postgresql.org/docs/10/static/sql-alteruser.html
 alter_user_password_stmt:
   ALTER USER string_or_placeholder WITH PASSWORD string_or_placeholder
@@ -3381,6 +3638,33 @@ opt_set_data:
   SET DATA {}
 | /* EMPTY */ {}
 
+// %Help: REFRESH - recompute the results of a materialized view
+// %Category: DDL
+// %Text: REFRESH MATERIALIZED VIEW [CONCURRENTLY] <viewname>
+//
+// The view keeps serving its previous results until the new results have
+// been computed. CONCURRENTLY requires a unique index on the view.
+// %SeeAlso: CREATE VIEW
+refresh_stmt:
+  REFRESH MATERIALIZED VIEW opt_concurrently relation_expr
+  {
+    $$.val = &tree.RefreshMaterializedView{
+      Name: $5.normalizableTableName(),
+      Concurrently: $4.bool(),
+    }
+  }
+| REFRESH error // SHOW HELP: REFRESH
+
+opt_concurrently:
+  CONCURRENTLY
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
 // %Help: RELEASE - complete a retryable block
 // %Category: Txn
 // %Text: RELEASE [SAVEPOINT] cockroach_restart
@@ -3605,6 +3889,52 @@ create_database_stmt:
    }
 | CREATE DATABASE error // SHOW HELP: CREATE DATABASE
 
+// %Help: CREATE SCHEMA - create a new schema
+// %Category: DDL
+// %Text: CREATE SCHEMA [IF NOT EXISTS] [<databasename> .] <name>
+// %SeeAlso: DROP SCHEMA, ALTER SCHEMA
+create_schema_stmt:
+  CREATE SCHEMA schema_name
+  {
+    $$.val = &tree.CreateSchema{Schema: $3.schemaName()}
+  }
+| CREATE SCHEMA IF NOT EXISTS schema_name
+  {
+    $$.val = &tree.CreateSchema{Schema: $6.schemaName(), IfNotExists: true}
+  }
+| CREATE SCHEMA error // SHOW HELP: CREATE SCHEMA
+
+// %Help: CREATE TYPE - create a new user-defined type
+// %Category: DDL
+// %Text: CREATE TYPE <name> AS ENUM ([<label> [, ...]])
+// %SeeAlso: ALTER TYPE, DROP TYPE
+create_type_stmt:
+  CREATE TYPE name AS ENUM '(' opt_enum_val_list ')'
+  {
+    $$.val = &tree.CreateType{Name: tree.Name($3), EnumLabels: $7.strs()}
+  }
+| CREATE TYPE error // SHOW HELP: CREATE TYPE
+
+opt_enum_val_list:
+  enum_val_list
+  {
+    $$.val = $1.strs()
+  }
+| /* EMPTY */
+  {
+    $$.val = []string(nil)
+  }
+
+enum_val_list:
+  SCONST
+  {
+    $$.val = []string{$1}
+  }
+| enum_val_list ',' SCONST
+  {
+    $$.val = append($1.strs(), $3)
+  }
+
 opt_template_clause:
   TEMPLATE opt_equal non_reserved_word_or_sconst
   {
@@ -3757,20 +4087,28 @@ returning_clause:
 
 // %Help: UPDATE - update rows of a table
 // %Category: DML
-// %Text: UPDATE <tablename> [[AS] <name>] SET ... [WHERE <expr>] [RETURNING <exprs...>]
+// %Text: UPDATE <tablename> [[AS] <name>] SET ...
+//               [FROM <source> [, ...]]
+//               [WHERE <expr>]
+//               [RETURNING <exprs...>]
 // %SeeAlso: INSERT, UPSERT, DELETE, WEBDOCS/update.html
 update_stmt:
   opt_with_clause UPDATE relation_expr_opt_alias
     SET set_clause_list update_from_clause where_clause returning_clause
   {
-    $$.val = &tree.Update{Table: $3.tblExpr(), Exprs: $5.updateExprs(), Where: tree.NewWhere(tree.AstWhere, $7.expr()), Returning: $8.retClause()}
+    $$.val = &tree.Update{Table: $3.tblExpr(), Exprs: $5.updateExprs(), From: $6.tblExprs(), Where: tree.NewWhere(tree.AstWhere, $7.expr()), Returning: $8.retClause()}
   }
 | opt_with_clause UPDATE error // SHOW HELP: UPDATE
 
-// Mark this as unimplemented until the normal from_clause is supported here.
 update_from_clause:
-  FROM from_list { return unimplementedWithIssue(sqllex, 7841) }
-| /* EMPTY */ {}
+  FROM from_list
+  {
+    $$.val = $2.tblExprs()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.TableExprs(nil)
+  }
 
 set_clause_list:
   set_clause
@@ -3871,11 +4209,11 @@ select_no_parens:
   }
 | select_clause opt_sort_clause for_locking_clause opt_select_limit
   {
-    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), LockForUpdate: $3.bool(), Limit: $4.limit()}
+    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Locking: $3.lockingClause(), Limit: $4.limit()}
   }
 | select_clause opt_sort_clause select_limit opt_for_locking_clause
   {
-    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $3.limit(), LockForUpdate: $4.bool()}
+    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $3.limit(), Locking: $4.lockingClause()}
   }
 | with_clause select_clause
   {
@@ -3887,11 +4225,11 @@ select_no_parens:
   }
 | with_clause select_clause opt_sort_clause for_locking_clause opt_select_limit
   {
-    $$.val = &tree.Select{Select: $2.selectStmt(), OrderBy: $3.orderBy(), LockForUpdate: $4.bool(), Limit: $5.limit()}
+    $$.val = &tree.Select{Select: $2.selectStmt(), OrderBy: $3.orderBy(), Locking: $4.lockingClause(), Limit: $5.limit()}
   }
 | with_clause select_clause opt_sort_clause select_limit opt_for_locking_clause
   {
-    $$.val = &tree.Select{Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit(), LockForUpdate: $5.bool()}
+    $$.val = &tree.Select{Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit(), Locking: $5.lockingClause()}
   }
 
 select_clause:
@@ -3951,7 +4289,7 @@ simple_select:
 //        [ ORDER BY <expr> [ ASC | DESC ] [, ...] ]
 //        [ LIMIT { <expr> | ALL } ]
 //        [ OFFSET <expr> [ ROW | ROWS ] ]
-//        [ FOR UPDATE ]
+//        [ FOR { UPDATE | NO KEY UPDATE | SHARE | KEY SHARE } [ NOWAIT | SKIP LOCKED ] ]
 // %SeeAlso: WEBDOCS/select.html
 simple_select_clause:
   SELECT opt_all_clause target_list
@@ -4260,13 +4598,49 @@ opt_for_locking_clause:
   for_locking_clause
 | /* EMPTY */
   {
-    $$.val = false
+    $$.val = tree.LockingClause{}
   }
 
 for_locking_clause:
+  for_locking_strength opt_nowait_or_skip
+  {
+    $$.val = tree.LockingClause{Strength: $1.lockingStrength(), WaitPolicy: $2.lockingWaitPolicy()}
+  }
+| for_locking_strength OF error
+  {
+    return unimplemented(sqllex, "locking clause with OF")
+  }
+
+for_locking_strength:
   FOR UPDATE
   {
-    $$.val = true
+    $$.val = tree.ForUpdate
+  }
+| FOR NO KEY UPDATE
+  {
+    $$.val = tree.ForNoKeyUpdate
+  }
+| FOR SHARE
+  {
+    $$.val = tree.ForShare
+  }
+| FOR KEY SHARE
+  {
+    $$.val = tree.ForKeyShare
+  }
+
+opt_nowait_or_skip:
+  /* EMPTY */
+  {
+    $$.val = tree.LockWaitBlock
+  }
+| SKIP LOCKED
+  {
+    $$.val = tree.LockWaitSkip
+  }
+| NOWAIT
+  {
+    $$.val = tree.LockWaitError
   }
 
 // Given "VALUES (a, b)" in a table expression context, we have to
@@ -4722,19 +5096,19 @@ cast_target:
     $$.val = $1.castTargetType()
   }
 
+// Multiple bounds declare a multidimensional array type, which, as in
+// Postgres, is the same type as the one-dimensional array type.
 opt_array_bounds:
-  // TODO(justin): reintroduce multiple array bounds
-  // opt_array_bounds '[' ']' { $$.val = append($1.int32s(), -1) }
-  '[' ']' { $$.val = []int32{-1} }
-| '[' ICONST ']'
+  opt_array_bounds '[' ']' { $$.val = append($1.int32s(), -1) }
+| opt_array_bounds '[' ICONST ']'
   {
     /* SKIP DOC */
-    bound, err := $2.numVal().AsInt32()
+    bound, err := $3.numVal().AsInt32()
     if err != nil {
       sqllex.Error(err.Error())
       return 1
     }
-    $$.val = []int32{bound}
+    $$.val = append($1.int32s(), bound)
   }
 | /* EMPTY */ { $$.val = []int32(nil) }
 
@@ -4806,13 +5180,12 @@ simple_typename:
     // See https://www.postgresql.org/docs/9.1/static/datatype-character.html
     // Postgres supports a special character type named "char" (with the quotes)
     // that is a single-character column type. It's used by system tables.
-    // Eventually this clause will be used to parse user-defined types as well,
-    // since their names can be quoted.
+    // Any other identifier names a user-defined type, which is resolved
+    // during semantic analysis.
     if $1 == "char" {
       $$.val = coltypes.Char
     } else {
-      sqllex.Error("syntax error")
-      return 1
+      $$.val = &coltypes.TUserDefined{Name: $1}
     }
   }
 
@@ -5099,6 +5472,22 @@ const_datetime:
   {
     $$.val = coltypes.TimestampWithTZ
   }
+| TIME
+  {
+    $$.val = coltypes.Time
+  }
+| TIME WITHOUT TIME ZONE
+  {
+    $$.val = coltypes.Time
+  }
+| TIMETZ
+  {
+    $$.val = coltypes.TimeWithTZ
+  }
+| TIME WITH_LA TIME ZONE
+  {
+    $$.val = coltypes.TimeWithTZ
+  }
 
 const_interval:
   INTERVAL {
@@ -5205,7 +5594,10 @@ a_expr:
   {
     $$.val = &tree.CollateExpr{Expr: $1.expr(), Locale: $3}
   }
-| a_expr AT TIME ZONE a_expr %prec AT { return unimplemented(sqllex, "at tz") }
+| a_expr AT TIME ZONE a_expr %prec AT
+  {
+    $$.val = &tree.FuncExpr{Func: tree.WrapFunction("timezone"), Exprs: tree.Exprs{$5.expr(), $1.expr()}}
+  }
   // These operators must be called out explicitly in order to make use of
   // bison's automatic operator-precedence handling. All other operator names
   // are handled by the generic productions using "OP", below; and all those
@@ -5754,8 +6146,14 @@ func_expr:
 // expressions are not allowed, where needed to disambiguate the grammar
 // (e.g. in CREATE INDEX).
 func_expr_windowless:
-  func_application { return unimplemented(sqllex, "func_application") }
-| func_expr_common_subexpr { return unimplemented(sqllex, "func_expr_common_subexpr") }
+  func_application
+  {
+    $$.val = $1.expr()
+  }
+| func_expr_common_subexpr
+  {
+    $$.val = $1.expr()
+  }
 
 // Special expressions that are considered to be functions.
 func_expr_common_subexpr:
@@ -6480,6 +6878,26 @@ name_list:
     $$.val = append($1.nameList(), tree.Name($3))
   }
 
+schema_name:
+  name
+  {
+    $$.val = tree.SchemaName{SchemaName: tree.Name($1)}
+  }
+| name '.' name
+  {
+    $$.val = tree.SchemaName{DatabaseName: tree.Name($1), SchemaName: tree.Name($3)}
+  }
+
+schema_name_list:
+  schema_name
+  {
+    $$.val = tree.SchemaNames{$1.schemaName()}
+  }
+| schema_name_list ',' schema_name
+  {
+    $$.val = append($1.schemaNames(), $3.schemaName())
+  }
+
 opt_name_list:
   '(' name_list ')'
   {
@@ -6640,9 +7058,11 @@ unrestricted_name:
 unreserved_keyword:
   ACTION
 | ADD
+| AFTER
 | ALTER
 | AT
 | BACKUP
+| BEFORE
 | BEGIN
 | BLOB
 | BY
@@ -6673,6 +7093,7 @@ unreserved_keyword:
 | DOUBLE
 | DROP
 | ENCODING
+| ENUM
 | EXECUTE
 | EXPERIMENTAL
 | EXPERIMENTAL_FINGERPRINTS
@@ -6702,8 +7123,10 @@ unreserved_keyword:
 | LEVEL
 | LIST
 | LOCAL
+| LOCKED
 | LOW
 | MATCH
+| MATERIALIZED
 | MINUTE
 | MONTH
 | NAMES
@@ -6712,6 +7135,7 @@ unreserved_keyword:
 | NO
 | NORMAL
 | NO_INDEX_JOIN
+| NOWAIT
 | NULLS
 | OF
 | OFF
@@ -6728,6 +7152,7 @@ unreserved_keyword:
 | PLANS
 | PRECEDING
 | PREPARE
+| PRESERVE
 | PRIORITY
 | QUERIES
 | QUERY
@@ -6735,6 +7160,7 @@ unreserved_keyword:
 | READ
 | RECURSIVE
 | REF
+| REFRESH
 | REGCLASS
 | REGPROC
 | REGPROCEDURE
@@ -6756,6 +7182,7 @@ unreserved_keyword:
 | STATUS
 | SAVEPOINT
 | SCATTER
+| SCHEMA
 | SCRUB
 | SEARCH
 | SECOND
@@ -6764,13 +7191,16 @@ unreserved_keyword:
 | SESSION
 | SESSIONS
 | SET
+| SHARE
 | SHOW
 | SIMPLE
+| SKIP
 | SNAPSHOT
 | SQL
 | START
 | STDIN
 | STORE
+| STORED
 | STORING
 | STRICT
 | SPLIT
@@ -6866,6 +7296,7 @@ col_name_keyword:
 | STRING
 | SUBSTRING
 | TIME
+| TIMETZ
 | TIMESTAMP
 | TIMESTAMPTZ
 | TREAT
@@ -6887,6 +7318,7 @@ col_name_keyword:
 // TODO(dan): see if we can move MAXVALUE to a less restricted list
 type_func_name_keyword:
   COLLATION
+| CONCURRENTLY
 | CROSS
 | FAMILY
 | FULL
@@ -30,6 +30,7 @@ import (
 	"golang.org/x/text/collate"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -221,9 +222,10 @@ CREATE TABLE pg_catalog.pg_attribute (
 }
 
 var (
-	relKindTable = tree.NewDString("r")
-	relKindIndex = tree.NewDString("i")
-	relKindView  = tree.NewDString("v")
+	relKindTable   = tree.NewDString("r")
+	relKindIndex   = tree.NewDString("i")
+	relKindView    = tree.NewDString("v")
+	relKindMatView = tree.NewDString("m")
 
 	relPersistencePermanent = tree.NewDString("p")
 )
@@ -266,7 +268,9 @@ CREATE TABLE pg_catalog.pg_class (
 		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
 			// Table.
 			relKind := relKindTable
-			if table.IsView() {
+			if table.MaterializedView() {
+				relKind = relKindMatView
+			} else if table.IsView() {
 				// The only difference between tables and views is the relkind column.
 				relKind = relKindView
 			}
@@ -755,9 +759,25 @@ CREATE TABLE pg_catalog.pg_enum (
   enumlabel STRING
 );
 `,
-	populate: func(_ context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
-		// Enum types are not currently supported.
-		return nil
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		h := makeOidHasher()
+		return forEachDatabaseDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor) error {
+			for i := range db.Types {
+				typ := &db.Types[i]
+				enumTypOid := typOid(typ.DatumType())
+				for j, label := range typ.EnumLabels {
+					if err := addRow(
+						h.EnumLabelOid(typ, label), // oid
+						enumTypOid,                 // enumtypid
+						tree.NewDFloat(tree.DFloat(float64(j+1))), // enumsortorder
+						tree.NewDString(label),                    // enumlabel
+					); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
 	},
 }
 
@@ -852,6 +872,10 @@ CREATE TABLE pg_catalog.pg_index (
 				if err != nil {
 					return err
 				}
+				indpred := tree.DNull
+				if index.IsPartial() {
+					indpred = tree.NewDString(index.Predicate)
+				}
 				return addRow(
 					h.IndexOid(db, table, index), // indexrelid
 					tableOid,                     // indrelid
@@ -871,7 +895,7 @@ CREATE TABLE pg_catalog.pg_index (
 					zeroVal,                                  // indclass
 					zeroVal,                                  // indoption
 					tree.DNull,                               // indexprs
-					tree.DNull,                               // indpred
+					indpred,                                  // indpred
 				)
 			})
 		})
@@ -944,6 +968,13 @@ func indexDefFromDescriptor(
 		if index.ColumnDirections[i] == sqlbase.IndexDescriptor_DESC {
 			elem.Direction = tree.Descending
 		}
+		if col, err := table.FindColumnByID(index.ColumnIDs[i]); err == nil && col.IsIndexExpr() {
+			expr, err := parser.ParseExpr(*col.ComputeExpr)
+			if err != nil {
+				return "", err
+			}
+			elem.Expr = expr
+		}
 		indexDef.Columns[i] = elem
 	}
 	for i, name := range index.StoreColumnNames {
@@ -978,6 +1009,13 @@ func indexDefFromDescriptor(
 		}
 		indexDef.Interleave = intlDef
 	}
+	if index.IsPartial() {
+		pred, err := parser.ParseExpr(index.Predicate)
+		if err != nil {
+			return "", err
+		}
+		indexDef.Predicate = pred
+	}
 	return indexDef.String(), nil
 }
 
@@ -1009,12 +1047,34 @@ CREATE TABLE pg_catalog.pg_namespace (
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
 		h := makeOidHasher()
 		return forEachDatabaseDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor) error {
-			return addRow(
+			if err := addRow(
 				h.NamespaceOid(db.Name),  // oid
 				tree.NewDString(db.Name), // nspname
 				tree.DNull,               // nspowner
 				tree.DNull,               // nspacl
-			)
+			); err != nil {
+				return err
+			}
+			// The user-defined schemas of the session database are listed
+			// alongside the databases, which CockroachDB presents as schemas.
+			if db.Name != p.session.Database {
+				return nil
+			}
+			for i := range db.Schemas {
+				sc := &db.Schemas[i]
+				if !userCanSeeDescriptor(sc, p.session.User) {
+					continue
+				}
+				if err := addRow(
+					h.SchemaOid(db, sc),      // oid
+					tree.NewDString(sc.Name), // nspname
+					tree.DNull,               // nspowner
+					tree.DNull,               // nspacl
+				); err != nil {
+					return err
+				}
+			}
+			return nil
 		})
 	},
 }
@@ -1373,7 +1433,6 @@ var (
 	// Avoid unused warning for constants.
 	_ = typTypeComposite
 	_ = typTypeDomain
-	_ = typTypeEnum
 	_ = typTypePseudo
 	_ = typTypeRange
 
@@ -1397,7 +1456,6 @@ var (
 	// Avoid unused warning for constants.
 	_ = typCategoryArray
 	_ = typCategoryComposite
-	_ = typCategoryEnum
 	_ = typCategoryGeometric
 	_ = typCategoryNetworkAddr
 	_ = typCategoryPseudo
@@ -1445,7 +1503,7 @@ CREATE TABLE pg_catalog.pg_type (
 	typacl STRING
 );
 `,
-	populate: func(_ context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
 		h := makeOidHasher()
 		for o, typ := range types.OidToType {
 			cat := typCategory(typ)
@@ -1509,7 +1567,50 @@ CREATE TABLE pg_catalog.pg_type (
 				return err
 			}
 		}
-		return nil
+
+		// User-defined enum types are stored on their database's descriptor
+		// and live in that database's namespace.
+		return forEachDatabaseDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor) error {
+			for i := range db.Types {
+				typ := db.Types[i].DatumType()
+				if err := addRow(
+					typOid(typ),             // oid
+					tree.NewDName(typ.Name), // typname
+					h.NamespaceOid(db.Name), // typnamespace
+					tree.DNull,              // typowner
+					typLen(typ),             // typlen
+					typByVal(typ),           // typbyval
+					typTypeEnum,             // typtype
+					typCategory(typ),        // typcategory
+					tree.MakeDBool(false),   // typispreferred
+					tree.MakeDBool(true),    // typisdefined
+					typDelim,                // typdelim
+					oidZero,                 // typrelid
+					oidZero,                 // typelem
+					oidZero,                 // typarray
+					h.RegProc("enum_in"),    // typinput
+					h.RegProc("enum_out"),   // typoutput
+					h.RegProc("enum_recv"),  // typreceive
+					h.RegProc("enum_send"),  // typsend
+					oidZero,                 // typmodin
+					oidZero,                 // typmodout
+					oidZero,                 // typanalyze
+					tree.DNull,              // typalign
+					tree.DNull,              // typstorage
+					tree.MakeDBool(false),   // typnotnull
+					oidZero,                 // typbasetype
+					negOneVal,               // typtypmod
+					zeroVal,                 // typndims
+					oidZero,                 // typcollation
+					tree.DNull,              // typdefaultbin
+					tree.DNull,              // typdefault
+					tree.DNull,              // typacl
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	},
 }
 
@@ -1560,11 +1661,14 @@ var datumToTypeCategory = map[reflect.Type]*tree.DString{
 	reflect.TypeOf(types.String):      typCategoryString,
 	reflect.TypeOf(types.Timestamp):   typCategoryDateTime,
 	reflect.TypeOf(types.TimestampTZ): typCategoryDateTime,
+	reflect.TypeOf(types.Time):        typCategoryDateTime,
+	reflect.TypeOf(types.TimeTZ):      typCategoryDateTime,
 	reflect.TypeOf(types.FamTuple):    typCategoryPseudo,
 	reflect.TypeOf(types.FamTable):    typCategoryPseudo,
 	reflect.TypeOf(types.Oid):         typCategoryNumeric,
 	reflect.TypeOf(types.UUID):        typCategoryUserDefined,
 	reflect.TypeOf(types.INet):        typCategoryNetworkAddr,
+	reflect.TypeOf(types.TEnum{}):     typCategoryEnum,
 }
 
 func typCategory(typ types.T) tree.Datum {
@@ -1586,7 +1690,8 @@ CREATE TABLE pg_catalog.pg_views (
 `,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
 		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, desc *sqlbase.TableDescriptor) error {
-			if !desc.IsView() {
+			// Like in Postgres, materialized views are not listed here.
+			if !desc.IsView() || desc.MaterializedView() {
 				return nil
 			}
 			// Note that the view query printed will not include any column aliases
@@ -1670,6 +1775,8 @@ const (
 	functionTypeTag
 	userTypeTag
 	collationTypeTag
+	schemaTypeTag
+	enumLabelTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -1718,6 +1825,16 @@ func (h oidHasher) NamespaceOid(namespace string) *tree.DOid {
 	return h.getOid()
 }
 
+func (h oidHasher) SchemaOid(
+	db *sqlbase.DatabaseDescriptor, sc *sqlbase.SchemaDescriptor,
+) *tree.DOid {
+	h.writeTypeTag(schemaTypeTag)
+	h.writeDB(db)
+	h.writeUInt32(uint32(sc.ID))
+	h.writeStr(sc.Name)
+	return h.getOid()
+}
+
 func (h oidHasher) DBOid(db *sqlbase.DatabaseDescriptor) *tree.DOid {
 	h.writeTypeTag(databaseTypeTag)
 	h.writeDB(db)
@@ -1822,6 +1939,13 @@ func (h oidHasher) CollationOid(collation string) *tree.DOid {
 	return h.getOid()
 }
 
+func (h oidHasher) EnumLabelOid(typ *sqlbase.TypeDescriptor, label string) *tree.DOid {
+	h.writeTypeTag(enumLabelTypeTag)
+	h.writeUInt32(uint32(typ.ID))
+	h.writeStr(label)
+	return h.getOid()
+}
+
 // pgNamespace represents a PostgreSQL-style namespace, which is the structure
 // underlying SQL schemas: "each namespace can have a separate collection of
 // relations, types, etc. without name conflicts."
@@ -117,6 +117,28 @@ func TestBinaryTimestampTZ(t *testing.T) {
 	})
 }
 
+func TestBinaryTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	testBinaryDatumType(t, "time", func(val string) tree.Datum {
+		d, err := tree.ParseDTime(val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	})
+}
+
+func TestBinaryTimeTZ(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	testBinaryDatumType(t, "timetz", func(val string) tree.Datum {
+		d, err := tree.ParseDTimeTZ(val, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	})
+}
+
 func TestBinaryDate(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	testBinaryDatumType(t, "date", func(val string) tree.Datum {
@@ -152,6 +174,40 @@ func TestBinaryIntArray(t *testing.T) {
 	}
 }
 
+func TestBinaryMultidimensionalIntArray(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	evalCtx := tree.NewTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+
+	d := tree.NewDArray(types.Int)
+	for _, elem := range []tree.Datum{
+		tree.NewDInt(1), tree.DNull, tree.NewDInt(3),
+		tree.NewDInt(4), tree.NewDInt(5), tree.NewDInt(6),
+	} {
+		if err := d.Append(elem); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.SetShape([]int{2, 3}, []int{0, 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+	buf.writeBinaryDatum(context.Background(), d, time.UTC)
+	if buf.err != nil {
+		t.Fatal(buf.err)
+	}
+	b := buf.wrapped.Bytes()
+
+	got, err := decodeOidDatum(oid.T__int8, formatBinary, b[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Compare(evalCtx, d) != 0 {
+		t.Fatalf("expected %s, got %s", d, got)
+	}
+}
+
 var generateBinaryCmd = flag.String("generate-binary", "", "generate-binary command invocation")
 
 func TestRandomBinaryDecimal(t *testing.T) {
@@ -0,0 +1,214 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// rawConn speaks the extended protocol to the server, which lib/pq can't do
+// with row limits.
+type rawConn struct {
+	t    *testing.T
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func dialRaw(t *testing.T, addr string) *rawConn {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &rawConn{t: t, conn: conn, rd: bufio.NewReader(conn)}
+
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, int32(196608))
+	for _, s := range []string{"user", "root", "database", "test", ""} {
+		buf.WriteString(s)
+		buf.WriteByte(0)
+	}
+	var startup bytes.Buffer
+	_ = binary.Write(&startup, binary.BigEndian, int32(buf.Len()+4))
+	startup.Write(buf.Bytes())
+	if _, err := conn.Write(startup.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if typ, _ := c.recv(); typ == 'Z' {
+			return c
+		}
+	}
+}
+
+func (c *rawConn) send(typ byte, fields ...interface{}) {
+	var body bytes.Buffer
+	for _, f := range fields {
+		switch f := f.(type) {
+		case string:
+			body.WriteString(f)
+			body.WriteByte(0)
+		default:
+			_ = binary.Write(&body, binary.BigEndian, f)
+		}
+	}
+	var msg bytes.Buffer
+	msg.WriteByte(typ)
+	_ = binary.Write(&msg, binary.BigEndian, int32(body.Len()+4))
+	msg.Write(body.Bytes())
+	if _, err := c.conn.Write(msg.Bytes()); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *rawConn) recv() (byte, []byte) {
+	typ, err := c.rd.ReadByte()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	var n int32
+	if err := binary.Read(c.rd, binary.BigEndian, &n); err != nil {
+		c.t.Fatal(err)
+	}
+	body := make([]byte, n-4)
+	if _, err := io.ReadFull(c.rd, body); err != nil {
+		c.t.Fatal(err)
+	}
+	return typ, body
+}
+
+// expect reads messages from the server and checks their types. The
+// transaction must have ended when the server is ready for a query.
+func (c *rawConn) expect(types string) error {
+	for i := range types {
+		typ, body := c.recv()
+		if typ != types[i] {
+			return errors.Errorf("expected message %q, got %q: %q", types[i], typ, body)
+		}
+		if typ == 'Z' && body[0] != 'I' {
+			return errors.Errorf("expected idle transaction status, got %q", body[0])
+		}
+	}
+	return nil
+}
+
+// prepare sends Parse and Bind messages creating the given portal.
+func (c *rawConn) prepare(portal, query string) {
+	c.send('P', portal, query, int16(0))
+	c.send('B', portal, portal, int16(0), int16(0), int16(0))
+}
+
+// TestPGWireSuspendedPortal checks the execution of portals with a row limit:
+// the client gets at most the requested number of rows followed by
+// PortalSuspended, and the next Execute message resumes the execution in the
+// same transaction, which isn't committed before Sync.
+func TestPGWireSuspendedPortal(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(context.TODO())
+
+	r := sqlutils.MakeSQLRunner(t, db)
+	r.Exec(`CREATE DATABASE test`)
+	r.Exec(`CREATE TABLE test.t (k INT PRIMARY KEY)`)
+	r.Exec(`INSERT INTO test.t VALUES (1), (2), (3), (4), (5)`)
+
+	c := dialRaw(t, s.ServingAddr())
+	defer c.conn.Close()
+
+	// The rows are sent in chunks.
+	c.prepare("p1", "SELECT k FROM t ORDER BY k")
+	c.send('E', "p1", int32(2))
+	c.send('E', "p1", int32(2))
+	c.send('E', "p1", int32(2))
+	c.send('S')
+	if err := c.expect("12DDsDDsDCZ"); err != nil {
+		t.Fatal(err)
+	}
+
+	// We don't know that no rows remain when the limit is reached.
+	c.prepare("p2", "SELECT k FROM t ORDER BY k")
+	c.send('E', "p2", int32(5))
+	c.send('E', "p2", int32(5))
+	c.send('S')
+	if err := c.expect("12DDDDDsCZ"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A portal that isn't resumed is closed by Sync.
+	c.prepare("p3", "SELECT k FROM t ORDER BY k")
+	c.send('E', "p3", int32(1))
+	c.send('S')
+	if err := c.expect("12DsZ"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The implicit transaction of a suspended portal is held open until Sync:
+	// an error in a later statement rolls back the insert.
+	c.prepare("p4", "INSERT INTO t VALUES (6), (7) RETURNING k")
+	c.send('E', "p4", int32(1))
+	c.send('E', "p4", int32(0))
+	c.prepare("p5", "INSERT INTO t VALUES (1)")
+	c.send('E', "p5", int32(0))
+	c.send('S')
+	if err := c.expect("12DsDC12EZ"); err != nil {
+		t.Fatal(err)
+	}
+	r.CheckQueryResults(`SELECT count(*) FROM test.t`, [][]string{{"5"}})
+
+	// Otherwise, Sync commits the transaction.
+	c.prepare("p6", "INSERT INTO t VALUES (6), (7) RETURNING k")
+	c.send('E', "p6", int32(1))
+	c.send('E', "p6", int32(0))
+	c.prepare("p7", "SELECT count(*) FROM t")
+	c.send('E', "p7", int32(0))
+	c.send('S')
+	if err := c.expect("12DsDC12DCZ"); err != nil {
+		t.Fatal(err)
+	}
+	r.CheckQueryResults(`SELECT count(*) FROM test.t`, [][]string{{"7"}})
+
+	// A mutation is run to completion even if its portal is suspended, so
+	// Sync commits all of its rows.
+	c.prepare("p8", "INSERT INTO t VALUES (8), (9), (10) RETURNING k")
+	c.send('E', "p8", int32(1))
+	c.send('S')
+	if err := c.expect("12DsZ"); err != nil {
+		t.Fatal(err)
+	}
+	r.CheckQueryResults(`SELECT count(*) FROM test.t`, [][]string{{"10"}})
+
+	// The rows it returns are buffered until the client asks for them.
+	c.prepare("p9", "DELETE FROM t WHERE k > 7 RETURNING k")
+	c.send('E', "p9", int32(2))
+	c.send('E', "p9", int32(2))
+	c.send('S')
+	if err := c.expect("12DDsDCZ"); err != nil {
+		t.Fatal(err)
+	}
+	r.CheckQueryResults(`SELECT count(*) FROM test.t`, [][]string{{"7"}})
+}
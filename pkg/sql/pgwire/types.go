@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/lib/pq"
@@ -150,6 +151,9 @@ func (b *writeBuffer) writeTextDatum(ctx context.Context, d tree.Datum, sessionL
 	case *tree.DCollatedString:
 		b.writeLengthPrefixedString(v.Contents)
 
+	case *tree.DEnum:
+		b.writeLengthPrefixedString(v.LogicalRep)
+
 	case *tree.DDate:
 		t := timeutil.Unix(int64(*v)*secondsInDay, 0)
 		// Start at offset 4 because `putInt32` clobbers the first 4 bytes.
@@ -169,6 +173,12 @@ func (b *writeBuffer) writeTextDatum(ctx context.Context, d tree.Datum, sessionL
 		b.putInt32(int32(len(s)))
 		b.write(s)
 
+	case *tree.DTime:
+		b.writeLengthPrefixedString(timeofday.TimeOfDay(*v).String())
+
+	case *tree.DTimeTZ:
+		b.writeLengthPrefixedString(tree.AsStringWithFlags(v, tree.FmtBareStrings))
+
 	case *tree.DInterval:
 		b.writeLengthPrefixedString(v.ValueAsString())
 
@@ -191,24 +201,19 @@ func (b *writeBuffer) writeTextDatum(ctx context.Context, d tree.Datum, sessionL
 		b.writeLengthPrefixedVariablePutbuf()
 
 	case *tree.DArray:
-		// Arrays are serialized as a string of comma-separated values, surrounded
-		// by braces.
-		begin, sep, end := "{", ",", "}"
-
 		if d.ResolvedType().Oid() == oid.T_int2vector {
 			// int2vectors are serialized as a string of space-separated values.
-			begin, sep, end = "", " ", ""
-		}
-
-		b.variablePutbuf.WriteString(begin)
-		for i, d := range v.Array {
-			if i > 0 {
-				b.variablePutbuf.WriteString(sep)
+			for i, d := range v.Array {
+				if i > 0 {
+					b.variablePutbuf.WriteString(" ")
+				}
+				tree.FormatNode(&b.variablePutbuf, tree.FmtArrays, d)
 			}
-			// TODO(justin): add a test for nested arrays.
-			tree.FormatNode(&b.variablePutbuf, tree.FmtArrays, d)
+		} else {
+			// Arrays are serialized as a string of comma-separated values,
+			// surrounded by braces, with one level of braces per dimension.
+			v.FormatText(&b.variablePutbuf)
 		}
-		b.variablePutbuf.WriteString(end)
 		b.writeLengthPrefixedVariablePutbuf()
 
 	case *tree.DOid:
@@ -361,6 +366,9 @@ func (b *writeBuffer) writeBinaryDatum(
 	case *tree.DCollatedString:
 		b.writeLengthPrefixedString(v.Contents)
 
+	case *tree.DEnum:
+		b.writeLengthPrefixedString(v.LogicalRep)
+
 	case *tree.DTimestamp:
 		b.putInt32(8)
 		b.putInt64(timeToPgBinary(v.Time, nil))
@@ -369,26 +377,40 @@ func (b *writeBuffer) writeBinaryDatum(
 		b.putInt32(8)
 		b.putInt64(timeToPgBinary(v.Time, sessionLoc))
 
+	case *tree.DTime:
+		b.putInt32(8)
+		b.putInt64(int64(*v))
+
+	case *tree.DTimeTZ:
+		// Postgres sends the zone offset in seconds west of UTC.
+		b.putInt32(12)
+		b.putInt64(int64(v.TimeOfDay))
+		b.putInt32(-v.OffsetSecs)
+
 	case *tree.DDate:
 		b.putInt32(4)
 		b.putInt32(dateToPgBinary(v))
 
 	case *tree.DArray:
 		if v.ParamTyp.FamilyEqual(types.AnyArray) {
-			b.setError(errors.New("unsupported binary serialization of multidimensional arrays"))
+			b.setError(errors.New("unsupported binary serialization of arrays of arrays"))
 			return
 		}
 		subWriter := &writeBuffer{wrapped: b.variablePutbuf}
-		// Put the number of dimensions. We currently support 1d arrays only.
-		subWriter.putInt32(1)
+		// Put the number of dimensions, followed by the length and lower bound
+		// of each of them.
+		numDims := v.NumDims()
+		subWriter.putInt32(int32(numDims))
 		hasNulls := 0
 		if v.HasNulls {
 			hasNulls = 1
 		}
 		subWriter.putInt32(int32(hasNulls))
 		subWriter.putInt32(int32(v.ParamTyp.Oid()))
-		subWriter.putInt32(int32(v.Len()))
-		subWriter.putInt32(int32(v.Len()))
+		for i := 0; i < numDims; i++ {
+			subWriter.putInt32(int32(v.DimLen(i)))
+			subWriter.putInt32(int32(v.LowerBound(i)))
+		}
 		for _, elem := range v.Array {
 			subWriter.writeBinaryDatum(ctx, elem, sessionLoc)
 		}
@@ -523,6 +545,12 @@ func pgBinaryToIPAddr(b []byte) (ipaddr.IPAddr, error) {
 // decodeOidDatum decodes bytes with specified Oid and format code into
 // a datum.
 func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (tree.Datum, error) {
+	if id >= types.UserDefinedTypeOIDOffset {
+		// Values of user-defined enum types are sent as their label in both
+		// the text and binary formats. The label is cast to the enum type when
+		// the placeholder is evaluated.
+		return tree.NewDString(string(b)), nil
+	}
 	switch code {
 	case formatText:
 		switch id {
@@ -582,6 +610,18 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (tree.Datum, error) {
 				return nil, errors.Errorf("could not parse string %q as timestamptz", b)
 			}
 			return d, nil
+		case oid.T_time:
+			d, err := tree.ParseDTime(string(b))
+			if err != nil {
+				return nil, errors.Errorf("could not parse string %q as time", b)
+			}
+			return d, nil
+		case oid.T_timetz:
+			d, err := tree.ParseDTimeTZ(string(b), time.UTC)
+			if err != nil {
+				return nil, errors.Errorf("could not parse string %q as timetz", b)
+			}
+			return d, nil
 		case oid.T_date:
 			ts, err := tree.ParseDTimestamp(string(b), time.Microsecond)
 			if err != nil {
@@ -787,6 +827,19 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (tree.Datum, error) {
 			}
 			i := int64(binary.BigEndian.Uint64(b))
 			return tree.MakeDTimestampTZ(pgBinaryToTime(i), time.Microsecond), nil
+		case oid.T_time:
+			if len(b) < 8 {
+				return nil, errors.Errorf("time requires 8 bytes for binary format")
+			}
+			i := int64(binary.BigEndian.Uint64(b))
+			return tree.MakeDTime(timeofday.FromInt(i)), nil
+		case oid.T_timetz:
+			if len(b) < 12 {
+				return nil, errors.Errorf("timetz requires 12 bytes for binary format")
+			}
+			i := int64(binary.BigEndian.Uint64(b))
+			zone := int32(binary.BigEndian.Uint32(b[8:]))
+			return tree.NewDTimeTZ(timeofday.FromInt(i), -zone), nil
 		case oid.T_date:
 			if len(b) < 4 {
 				return nil, errors.Errorf("date requires 4 bytes for binary format")
@@ -846,29 +899,50 @@ func decodeBinaryArray(b []byte, code formatCode) (tree.Datum, error) {
 		// Nullflag
 		_       int32
 		ElemOid int32
-		// The next two fields should be arrays of size Ndims. However, since
-		// we only support 1-dimensional arrays for now, for convenience we can
-		// leave them in this struct as such for `binary.Read` to parse for us.
-		DimSize int32
-		// Dim lower bound
-		_ int32
 	}{}
 	r := bytes.NewBuffer(b)
 	if err := binary.Read(r, binary.BigEndian, &hdr); err != nil {
 		return nil, err
 	}
-	// Only 1-dimensional arrays are supported for now.
-	if hdr.Ndims != 1 {
+	if hdr.Ndims < 0 || hdr.Ndims > tree.MaxArrayDims {
 		return nil, errors.Errorf("unsupported number of array dimensions: %d", hdr.Ndims)
 	}
+	// The header is followed by the size and lower bound of each dimension.
+	dimInfo := make([]int32, 2*hdr.Ndims)
+	if err := binary.Read(r, binary.BigEndian, dimInfo); err != nil {
+		return nil, err
+	}
+	dims := make([]int, hdr.Ndims)
+	lowerBounds := make([]int, hdr.Ndims)
+	numElems := 0
+	if hdr.Ndims > 0 {
+		numElems = 1
+	}
+	for i := range dims {
+		dims[i], lowerBounds[i] = int(dimInfo[2*i]), int(dimInfo[2*i+1])
+		if dims[i] < 0 {
+			return nil, errors.Errorf("invalid array dimension size: %d", dims[i])
+		}
+		// Every element takes up at least 4 bytes for its length.
+		if numElems *= dims[i]; numElems > r.Len()/4 {
+			return nil, errors.Errorf("array dimensions exceed the size of the buffer")
+		}
+	}
 
 	elemOid := oid.Oid(hdr.ElemOid)
 	arr := tree.NewDArray(types.OidToType[elemOid])
 	var vlen int32
-	for i := int32(0); i < hdr.DimSize; i++ {
+	for i := 0; i < numElems; i++ {
 		if err := binary.Read(r, binary.BigEndian, &vlen); err != nil {
 			return nil, err
 		}
+		if vlen < 0 {
+			// NULL elements are encoded as a length of -1.
+			if err := arr.Append(tree.DNull); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		buf := r.Next(int(vlen))
 		elem, err := decodeOidDatum(elemOid, code, buf)
 		if err != nil {
@@ -878,5 +952,8 @@ func decodeBinaryArray(b []byte, code formatCode) (tree.Datum, error) {
 			return nil, err
 		}
 	}
+	if err := arr.SetShape(dims, lowerBounds); err != nil {
+		return nil, err
+	}
 	return arr, nil
 }
@@ -109,6 +109,44 @@ func TestIntArrayRoundTrip(t *testing.T) {
 	}
 }
 
+func TestWriteTextMultidimensionalArray(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		elems       []int
+		dims        []int
+		lowerBounds []int
+		expected    string
+	}{
+		{nil, nil, nil, `{}`},
+		{[]int{1, 2, 3}, []int{3}, nil, `{1,2,3}`},
+		{[]int{1, 2, 3, 4}, []int{2, 2}, nil, `{{1,2},{3,4}}`},
+		{[]int{1, 2, 3, 4}, []int{1, 2, 2}, nil, `{{{1,2},{3,4}}}`},
+		{[]int{1, 2}, []int{2}, []int{0}, `[0:1]={1,2}`},
+		{[]int{1, 2, 3, 4, 5, 6}, []int{2, 3}, []int{-1, 1}, `[-1:0][1:3]={{1,2,3},{4,5,6}}`},
+	}
+	for _, td := range testData {
+		d := tree.NewDArray(types.Int)
+		for _, e := range td.elems {
+			if err := d.Append(tree.NewDInt(tree.DInt(e))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := d.SetShape(td.dims, td.lowerBounds); err != nil {
+			t.Fatal(err)
+		}
+
+		buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+		buf.writeTextDatum(context.Background(), d, time.UTC)
+		if buf.err != nil {
+			t.Fatal(buf.err)
+		}
+		if got := string(buf.wrapped.Bytes()[4:]); got != td.expected {
+			t.Errorf("expected %s, got %s", td.expected, got)
+		}
+	}
+}
+
 func benchmarkWriteType(b *testing.B, d tree.Datum, format formatCode) {
 	ctx := context.Background()
 
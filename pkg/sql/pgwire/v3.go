@@ -77,6 +77,7 @@ const (
 	serverMsgParameterDescription serverMessageType = 't'
 	serverMsgParameterStatus      serverMessageType = 'S'
 	serverMsgParseComplete        serverMessageType = '1'
+	serverMsgPortalSuspended      serverMessageType = 's'
 	serverMsgReady                serverMessageType = 'Z'
 	serverMsgRowDescription       serverMessageType = 'T'
 )
@@ -207,7 +208,6 @@ type streamingState struct {
 	// should be sent as binary or text format. If it is nil then we send as text.
 	formatCodes     []formatCode
 	sendDescription bool
-	limit           int
 	emptyQuery      bool
 	err             error
 
@@ -240,10 +240,9 @@ type streamingState struct {
 	copyIn bool
 }
 
-func (s *streamingState) reset(formatCodes []formatCode, sendDescription bool, limit int) {
+func (s *streamingState) reset(formatCodes []formatCode, sendDescription bool) {
 	s.formatCodes = formatCodes
 	s.sendDescription = sendDescription
-	s.limit = limit
 	s.emptyQuery = false
 	s.hasSentResults = false
 	s.txnStartIdx = 0
@@ -498,10 +497,13 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 		case clientMsgSync:
 			c.doingExtendedQueryMessage = false
 			c.ignoreTillSync = false
+			err = c.handleSync()
 
 		case clientMsgSimpleQuery:
 			c.doingExtendedQueryMessage = false
-			err = c.handleSimpleQuery(&c.readBuf)
+			if err = c.handleSync(); err == nil {
+				err = c.handleSimpleQuery(&c.readBuf)
+			}
 
 		case clientMsgTerminate:
 			return nil
@@ -598,6 +600,15 @@ func (c *v3Conn) sendAuthPasswordRequest() (string, error) {
 	return c.readBuf.getString()
 }
 
+// handleSync ends the implicit transaction which was held open because the
+// execution of a portal was suspended in it, if any.
+func (c *v3Conn) handleSync() error {
+	if err := c.executor.Sync(c.session); err != nil {
+		return c.sendError(err)
+	}
+	return nil
+}
+
 func (c *v3Conn) handleSimpleQuery(buf *readBuffer) error {
 	defer c.session.FinishPlan()
 	query, err := buf.getString()
@@ -606,9 +617,7 @@ func (c *v3Conn) handleSimpleQuery(buf *readBuffer) error {
 	}
 
 	tracing.AnnotateTrace()
-	c.streamingState.reset(
-		nil /* formatCodes */, true /* sendDescription */, 0, /* limit */
-	)
+	c.streamingState.reset(nil /* formatCodes */, true /* sendDescription */)
 	c.session.ResultsWriter = c
 	if err := c.executor.ExecuteStatements(c.session, query, nil); err != nil {
 		if err := c.setError(err); err != nil {
@@ -954,15 +963,24 @@ func (c *v3Conn) handleExecute(buf *readBuffer) error {
 	}
 
 	tracing.AnnotateTrace()
-	c.streamingState.reset(portalMeta.outFormats, false /* sendDescription */, int(limit))
+	c.streamingState.reset(portalMeta.outFormats, false /* sendDescription */)
 	c.session.ResultsWriter = c
-	err = c.executor.ExecutePreparedStatement(c.session, stmt, pinfo)
+	suspended, err := c.executor.ExecutePortal(c.session, portal, pinfo, int(limit))
 	if err != nil {
 		if err := c.setError(err); err != nil {
 			return err
 		}
 	}
-	return c.done()
+	if err := c.done(); err != nil {
+		return err
+	}
+	if suspended && c.streamingState.err == nil {
+		// The row limit was reached; the client resumes the execution of the
+		// portal with another Execute message.
+		c.writeBuf.initMsg(serverMsgPortalSuspended)
+		return c.writeBuf.finishMsg(c.wr)
+	}
+	return nil
 }
 
 func (c *v3Conn) sendCommandComplete(tag []byte, w io.Writer) error {
@@ -1233,19 +1251,11 @@ func (c *v3Conn) CloseResult() error {
 
 	ctx := c.session.Ctx()
 	formatCodes := state.formatCodes
-	limit := state.limit
 
 	if err := c.flush(false /* forceSend */); err != nil {
 		return err
 	}
 
-	if limit != 0 && state.statementType == tree.Rows && state.rowsAffected > state.limit {
-		return c.setError(pgerror.NewErrorf(
-			pgerror.CodeInternalError,
-			"execute row count limits not supported: %d of %d", limit, state.rowsAffected,
-		))
-	}
-
 	if state.pgTag == "INSERT" {
 		// From the postgres docs (49.5. Message Formats):
 		// `INSERT oid rows`... oid is the object ID of the inserted row if
@@ -143,17 +143,22 @@ type planNodeFastPath interface {
 }
 
 var _ planNode = &alterTableNode{}
+var _ planNode = &alterTypeNode{}
 var _ planNode = &copyNode{}
 var _ planNode = &createDatabaseNode{}
 var _ planNode = &createIndexNode{}
+var _ planNode = &createSchemaNode{}
 var _ planNode = &createTableNode{}
+var _ planNode = &createTypeNode{}
 var _ planNode = &createViewNode{}
 var _ planNode = &delayedNode{}
 var _ planNode = &deleteNode{}
 var _ planNode = &distinctNode{}
 var _ planNode = &dropDatabaseNode{}
 var _ planNode = &dropIndexNode{}
+var _ planNode = &dropSchemaNode{}
 var _ planNode = &dropTableNode{}
+var _ planNode = &dropTypeNode{}
 var _ planNode = &dropViewNode{}
 var _ planNode = &zeroNode{}
 var _ planNode = &unaryNode{}
@@ -168,6 +173,7 @@ var _ planNode = &insertNode{}
 var _ planNode = &joinNode{}
 var _ planNode = &limitNode{}
 var _ planNode = &ordinalityNode{}
+var _ planNode = &refreshViewNode{}
 var _ planNode = &testingRelocateNode{}
 var _ planNode = &renderNode{}
 var _ planNode = &scanNode{}
@@ -331,6 +337,8 @@ func (p *planner) newPlan(
 	switch n := stmt.(type) {
 	case *tree.AlterTable:
 		return p.AlterTable(ctx, n)
+	case *tree.AlterTypeAddValue:
+		return p.AlterTypeAddValue(ctx, n)
 	case *tree.AlterUserSetPassword:
 		return p.AlterUserSetPassword(ctx, n)
 	case *tree.BeginTransaction:
@@ -349,8 +357,12 @@ func (p *planner) newPlan(
 		return p.CreateDatabase(n)
 	case *tree.CreateIndex:
 		return p.CreateIndex(ctx, n)
+	case *tree.CreateSchema:
+		return p.CreateSchema(ctx, n)
 	case *tree.CreateTable:
 		return p.CreateTable(ctx, n)
+	case *tree.CreateType:
+		return p.CreateType(ctx, n)
 	case *tree.CreateUser:
 		return p.CreateUser(ctx, n)
 	case *tree.CreateView:
@@ -365,8 +377,12 @@ func (p *planner) newPlan(
 		return p.DropDatabase(ctx, n)
 	case *tree.DropIndex:
 		return p.DropIndex(ctx, n)
+	case *tree.DropSchema:
+		return p.DropSchema(ctx, n)
 	case *tree.DropTable:
 		return p.DropTable(ctx, n)
+	case *tree.DropType:
+		return p.DropType(ctx, n)
 	case *tree.DropView:
 		return p.DropView(ctx, n)
 	case *tree.DropUser:
@@ -385,12 +401,16 @@ func (p *planner) newPlan(
 		return p.PauseJob(ctx, n)
 	case *tree.TestingRelocate:
 		return p.TestingRelocate(ctx, n)
+	case *tree.RefreshMaterializedView:
+		return p.RefreshMaterializedView(ctx, n)
 	case *tree.RenameColumn:
 		return p.RenameColumn(ctx, n)
 	case *tree.RenameDatabase:
 		return p.RenameDatabase(ctx, n)
 	case *tree.RenameIndex:
 		return p.RenameIndex(ctx, n)
+	case *tree.RenameSchema:
+		return p.RenameSchema(ctx, n)
 	case *tree.RenameTable:
 		return p.RenameTable(ctx, n)
 	case *tree.ResumeJob:
@@ -402,7 +422,7 @@ func (p *planner) newPlan(
 	case *tree.Select:
 		return p.Select(ctx, n, desiredTypes)
 	case *tree.SelectClause:
-		return p.SelectClause(ctx, n, nil /* orderBy */, nil /* limit */, false, /* lockForUpdate */
+		return p.SelectClause(ctx, n, nil /* orderBy */, nil /* limit */, sqlbase.ScanLocking{},
 			desiredTypes, publicColumns)
 	case *tree.SetClusterSetting:
 		return p.SetClusterSetting(ctx, n)
@@ -504,7 +524,7 @@ func (p *planner) prepare(ctx context.Context, stmt tree.Statement) (planNode, e
 	case *tree.Select:
 		return p.Select(ctx, n, nil)
 	case *tree.SelectClause:
-		return p.SelectClause(ctx, n, nil /* orderBy */, nil /* limit */, false, /* lockForUpdate */
+		return p.SelectClause(ctx, n, nil /* orderBy */, nil /* limit */, sqlbase.ScanLocking{},
 			nil /* desiredTypes */, publicColumns)
 	case *tree.SetClusterSetting:
 		return p.SetClusterSetting(ctx, n)
@@ -82,7 +82,7 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 	case *scrubNode:
 		return n.getColumns(mut, scrubColumns)
 	case *explainDistSQLNode:
-		return n.getColumns(mut, explainDistSQLColumns)
+		return n.columns(mut)
 	case *testingRelocateNode:
 		return n.getColumns(mut, relocateNodeColumns)
 	case *scatterNode:
@@ -175,7 +175,9 @@ func insertNodeWithValuesSpans(
 		}
 		addWriteKey(primaryKey)
 		for _, secondaryKey := range secondaryKeys {
-			addWriteKey(secondaryKey.Key)
+			if secondaryKey.Key != nil {
+				addWriteKey(secondaryKey.Key)
+			}
 		}
 
 		// Determine the table spans that foreign key constraints will require
@@ -101,6 +101,10 @@ type planner struct {
 	// hasSubqueries collects whether any subqueries expansion has
 	// occurred during logical plan construction.
 	hasSubqueries bool
+	// subqueryScope, if non-nil, describes the data sources of the queries
+	// enclosing the subquery currently being planned. It is used to resolve
+	// references to outer columns in correlated subqueries.
+	subqueryScope *subqueryScope
 	// isPreparing is true if this planner is currently preparing.
 	isPreparing bool
 	// plannedExecute is true if this planner has planned an EXECUTE statement.
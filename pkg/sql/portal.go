@@ -0,0 +1,298 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// portalExec describes the execution of the statement of a portal by
+// ExecutePortal.
+type portalExec struct {
+	portal *PreparedPortal
+	// limit is the maximum number of rows to send to the client; 0 means no
+	// limit.
+	limit int
+}
+
+// suspendable returns true if the execution of the given statement may be
+// suspended because of the row limit. Such a statement is always run locally,
+// even if it would have been distributed otherwise and even if it returns
+// fewer rows than the limit: DistSQL pushes the rows to the client as they are
+// produced, so a flow can't be paused until the client asks for more rows.
+func (pe *portalExec) suspendable(stmt Statement) bool {
+	return pe.portal != nil && pe.limit > 0 && stmt.AST.StatementType() == tree.Rows
+}
+
+// suspendedPortal is the state of a portal whose execution was suspended
+// after the row limit of an Execute message was reached. The plan is run
+// locally, so that its rows can be pulled as the client requests them.
+type suspendedPortal struct {
+	stmt    Statement
+	planner *planner
+	plan    planNode
+	// rows produces the rows sent to the client. It is the plan itself, unless
+	// the plan writes data: like Postgres, we then run the statement to
+	// completion before any row is sent, since the writes are only flushed once
+	// the source of the mutation is exhausted, and buffer the rows it returns.
+	rows planNode
+	// txn is the KV transaction in which the portal was executed. The
+	// execution can only be resumed in the same transaction.
+	txn *client.Txn
+	// rowAcc tracks the memory usage of each row.
+	rowAcc mon.BoundAccount
+}
+
+func (sp *suspendedPortal) close(ctx context.Context) {
+	if sp.rows != sp.plan {
+		sp.rows.Close(ctx)
+	}
+	sp.plan.Close(ctx)
+	sp.rowAcc.Close(ctx)
+}
+
+func (p *PreparedPortal) closeSuspended(ctx context.Context) {
+	if p.suspended != nil {
+		p.suspended.close(ctx)
+		p.suspended = nil
+	}
+}
+
+// closeSuspended closes the suspended portals.
+func (pp PreparedPortals) closeSuspended(ctx context.Context) {
+	for _, portal := range pp.portals {
+		portal.closeSuspended(ctx)
+	}
+}
+
+// ExecutePortal executes the statement of the given portal, sending at most
+// limit rows to the client; a limit of 0 means no limit. If the limit is
+// reached, the execution of the portal is suspended and true is returned; the
+// next call resumes it. If the portal was executed in an implicit
+// transaction, the transaction is held open until Sync is called.
+func (e *Executor) ExecutePortal(
+	session *Session, portal *PreparedPortal, pinfo *tree.PlaceholderInfo, limit int,
+) (suspended bool, _ error) {
+	txnState := &session.TxnState
+	if sp := portal.suspended; sp != nil {
+		if txnState.TxnIsOpen() && txnState.mu.txn == sp.txn {
+			return e.resumePortal(session, portal, limit)
+		}
+		portal.closeSuspended(session.Ctx())
+	}
+
+	session.portalExec = portalExec{portal: portal, limit: limit}
+	defer func() { session.portalExec = portalExec{} }()
+	err := e.ExecutePreparedStatement(session, portal.Stmt, pinfo)
+	return err == nil && portal.suspended != nil, err
+}
+
+// execSuspendable runs a plan locally, like execClassic, until the row limit
+// of the portal being executed is reached. In that case, the execution of the
+// portal is suspended and true is returned; the plan then belongs to the
+// portal. A plan that writes data is always run to completion first, so that
+// the transaction never commits a partial mutation.
+func (e *Executor) execSuspendable(
+	planner *planner, stmt Statement, plan planNode, res StatementResult,
+) (suspended bool, _ error) {
+	session := planner.session
+	ctx := session.Ctx()
+	pe := &session.portalExec
+
+	sp := &suspendedPortal{
+		stmt:    stmt,
+		planner: planner,
+		plan:    plan,
+		rows:    plan,
+		txn:     planner.txn,
+		rowAcc:  planner.evalCtx.Mon.MakeBoundAccount(),
+	}
+	planner.evalCtx.ActiveMemAcc = &sp.rowAcc
+
+	if err := planner.startPlan(ctx, plan); err != nil {
+		sp.rowAcc.Close(ctx)
+		return false, err
+	}
+	params := runParams{ctx: ctx, p: planner}
+	if planWritesData(ctx, plan) {
+		rows, err := bufferRows(params, plan)
+		if err != nil {
+			sp.rowAcc.Close(ctx)
+			return false, err
+		}
+		sp.rows = rows
+	}
+	limitReached, err := sendLimitedRows(params, sp.rows, pe.limit, res)
+	if err != nil || !limitReached {
+		if sp.rows != plan {
+			sp.rows.Close(ctx)
+		}
+		sp.rowAcc.Close(ctx)
+		return false, err
+	}
+
+	// The statement may be executed again by an automatic retry.
+	pe.portal.closeSuspended(ctx)
+	pe.portal.suspended = sp
+	txnState := &session.TxnState
+	// Rows have been sent to the client, so the transaction can't be
+	// retried automatically any more.
+	if txnState.State() == AutoRetry {
+		txnState.SetState(Open)
+	}
+	if txnState.implicitTxn {
+		txnState.heldOpen = true
+	}
+	return true, nil
+}
+
+// resumePortal resumes the execution of a suspended portal, sending at most
+// limit rows to the client.
+func (e *Executor) resumePortal(
+	session *Session, portal *PreparedPortal, limit int,
+) (suspended bool, _ error) {
+	defer session.maybeRecover("resuming", portal.Stmt.Str)
+
+	ctx := session.Ctx()
+	txnState := &session.TxnState
+	sp := portal.suspended
+
+	res := txnState.txnResults.NewStatementResult()
+	err := initStatementResult(res, sp.stmt, sp.plan)
+	if err == nil {
+		params := runParams{ctx: ctx, p: sp.planner}
+		suspended, err = sendLimitedRows(params, sp.rows, limit, res)
+	}
+	if err == nil && !suspended {
+		portal.closeSuspended(ctx)
+		err = res.CloseResult()
+	}
+	if err == nil {
+		err = txnState.txnResults.Flush(ctx)
+	}
+	if err != nil {
+		portal.closeSuspended(ctx)
+		err = txnState.updateStateAndCleanupOnErr(err, e)
+		return false, convertToErrWithPGCode(err)
+	}
+	return suspended, nil
+}
+
+// planWritesData returns true if the given plan, or one of its subqueries,
+// writes data.
+func planWritesData(ctx context.Context, plan planNode) bool {
+	writes := false
+	_ = walkPlan(ctx, plan, planObserver{
+		enterNode: func(_ context.Context, _ string, p planNode) bool {
+			switch p.(type) {
+			case *insertNode, *updateNode, *deleteNode:
+				writes = true
+			}
+			return !writes
+		},
+	})
+	return writes
+}
+
+// bufferRows runs a started plan to completion and returns a valuesNode
+// holding the rows it produced.
+func bufferRows(params runParams, plan planNode) (*valuesNode, error) {
+	rows := params.p.newContainerValuesNode(planColumns(plan), 0)
+	for {
+		next, err := plan.Next(params)
+		if err == nil && !next {
+			return rows, nil
+		}
+		if err == nil {
+			_, err = rows.rows.AddRow(params.ctx, plan.Values())
+			// The row now belongs to the container.
+			if params.p.evalCtx.ActiveMemAcc != nil {
+				params.p.evalCtx.ActiveMemAcc.Clear(params.ctx)
+			}
+		}
+		if err != nil {
+			rows.Close(params.ctx)
+			return nil, err
+		}
+	}
+}
+
+// sendLimitedRows sends at most limit rows of a started plan to res; a limit
+// of 0 means no limit. It returns true if the limit was reached. Like
+// Postgres, we don't check whether more rows remain, so the next execution of
+// the portal may return no rows.
+func sendLimitedRows(
+	params runParams, plan planNode, limit int, res StatementResult,
+) (limitReached bool, _ error) {
+	for n := 0; limit == 0 || n < limit; n++ {
+		next, err := plan.Next(params)
+		if err != nil || !next {
+			return false, err
+		}
+		// If we're tracking memory, clear the previous row's memory account.
+		if params.p.evalCtx.ActiveMemAcc != nil {
+			params.p.evalCtx.ActiveMemAcc.Clear(params.ctx)
+		}
+		values := plan.Values()
+		for _, val := range values {
+			if err := checkResultType(val.ResolvedType()); err != nil {
+				return false, err
+			}
+		}
+		if err := res.AddRow(params.ctx, values); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Sync is called when the client ends a sequence of extended protocol
+// messages, or before it sends a simple query. If an implicit transaction was
+// held open because the execution of a portal was suspended in it, the
+// transaction ends: it is committed if it's still open, and it was already
+// rolled back otherwise.
+func (e *Executor) Sync(session *Session) error {
+	txnState := &session.TxnState
+	if txnState.State() == NoTxn || !txnState.heldOpen {
+		return nil
+	}
+	ctx := session.Ctx()
+
+	// The suspended portals must be closed before the transaction is.
+	session.PreparedPortals.closeSuspended(ctx)
+	var err error
+	if txnState.TxnIsOpen() {
+		if err = session.commitWithOnCommitActions(ctx, e, txnState.mu.txn); err != nil {
+			err = txnState.updateStateAndCleanupOnErr(err, e)
+		}
+	}
+	txnState.resetStateAndTxn(NoTxn)
+	txnState.finishSQLTxn(session)
+
+	// Release the leases used by the transaction and run its schema changes,
+	// as is done for any other transaction that ends.
+	session.tables.releaseTables(ctx)
+	if scErr := txnState.schemaChangers.execSchemaChanges(ctx, e, session); scErr != nil && err == nil {
+		err = scErr
+	}
+	if err != nil {
+		return convertToErrWithPGCode(err)
+	}
+	return nil
+}
@@ -199,7 +199,7 @@ func (ps PreparedStatements) Delete(ctx context.Context, name string) bool {
 			for portalName := range stmt.portalNames {
 				if portal, ok := ps.session.PreparedPortals.Get(name); ok {
 					delete(ps.session.PreparedPortals.portals, portalName)
-					portal.memAcc.Wsession(ps.session).Close(ctx)
+					portal.close(ctx, ps.session)
 				}
 			}
 		}
@@ -216,7 +216,7 @@ func (ps PreparedStatements) closeAll(ctx context.Context, s *Session) {
 		stmt.close(ctx, s)
 	}
 	for _, portal := range s.PreparedPortals.portals {
-		portal.memAcc.Wsession(s).Close(ctx)
+		portal.close(ctx, s)
 	}
 }
 
@@ -246,6 +246,14 @@ type PreparedPortal struct {
 	ProtocolMeta interface{} // a field for protocol implementations to hang metadata off of.
 
 	memAcc WrappableMemoryAccount
+	// suspended is set while the execution of the portal is suspended because
+	// the row limit of an Execute message was reached.
+	suspended *suspendedPortal
+}
+
+func (p *PreparedPortal) close(ctx context.Context, s *Session) {
+	p.memAcc.Wsession(s).Close(ctx)
+	p.closeSuspended(ctx)
 }
 
 // PreparedPortals is a mapping of PreparedPortal names to their corresponding
@@ -291,7 +299,7 @@ func (pp PreparedPortals) New(
 	stmt.portalNames[name] = struct{}{}
 
 	if prevPortal, ok := pp.Get(name); ok {
-		prevPortal.memAcc.Wsession(pp.session).Close(ctx)
+		prevPortal.close(ctx, pp.session)
 	}
 
 	pp.portals[name] = portal
@@ -303,7 +311,7 @@ func (pp PreparedPortals) New(
 func (pp PreparedPortals) Delete(ctx context.Context, name string) bool {
 	if portal, ok := pp.Get(name); ok {
 		delete(portal.Stmt.portalNames, name)
-		portal.memAcc.Wsession(pp.session).Close(ctx)
+		portal.close(ctx, pp.session)
 		delete(pp.portals, name)
 		return true
 	}
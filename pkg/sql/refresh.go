@@ -0,0 +1,374 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+type refreshViewNode struct {
+	n    *tree.RefreshMaterializedView
+	desc *sqlbase.TableDescriptor
+}
+
+// RefreshMaterializedView recomputes the results of a materialized view.
+// Privileges: DROP on view.
+//   Notes: postgres allows only the view owner to REFRESH a materialized view.
+//
+// The refresh is run by the schema changer once the transaction commits. The
+// new results are written to new indexes in bounded transactions while the
+// previous results remain readable, and the new indexes then replace those
+// of the view in a single descriptor update. The previous results are
+// deleted once no node reads them any more. Since a refresh never blocks the
+// readers of the view, CONCURRENTLY only adds the requirement of Postgres
+// that the view has a unique index.
+func (p *planner) RefreshMaterializedView(
+	ctx context.Context, n *tree.RefreshMaterializedView,
+) (planNode, error) {
+	tn, err := n.Name.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := MustGetTableOrViewDesc(ctx, p.txn, p.getVirtualTabler(), tn, false /*allowAdding*/)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MaterializedView() {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "materialized view")
+	}
+
+	if err := p.CheckPrivilege(desc, privilege.DROP); err != nil {
+		return nil, err
+	}
+
+	if n.Concurrently && !hasUniqueColumnIndex(desc) {
+		return nil, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"cannot refresh materialized view %q concurrently", tn.Table()).SetHintf(
+			"Create a unique index with no WHERE clause on one or more columns of the materialized view.")
+	}
+	if desc.Refresh != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeObjectInUseError,
+			"materialized view %q is already being refreshed", tn.Table())
+	}
+	if len(desc.Mutations) > 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeObjectInUseError,
+			"materialized view %q has a schema change in progress", tn.Table())
+	}
+
+	return &refreshViewNode{n: n, desc: desc}, nil
+}
+
+// hasUniqueColumnIndex returns true if the materialized view has a unique
+// index which is neither partial nor on expressions.
+func hasUniqueColumnIndex(desc *sqlbase.TableDescriptor) bool {
+	for _, idx := range desc.Indexes {
+		if !idx.Unique || idx.IsPartial() {
+			continue
+		}
+		onColumns := true
+		for _, id := range idx.ColumnIDs {
+			if col, err := desc.FindColumnByID(id); err != nil || col.IsIndexExpr() {
+				onColumns = false
+			}
+		}
+		if onColumns {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *refreshViewNode) Start(params runParams) error {
+	job := params.p.ExecCfg().JobRegistry.NewJob(jobs.Record{
+		Description:   tree.AsStringWithFlags(n.n, tree.FmtSimpleQualified),
+		Username:      params.p.User(),
+		DescriptorIDs: sqlbase.IDs{n.desc.ID},
+		Details:       jobs.SchemaChangeDetails{},
+	})
+	if err := job.WithTxn(params.p.txn).Created(params.ctx, jobs.WithoutCancel); err != nil {
+		return err
+	}
+	n.desc.Refresh = n.desc.MakeMaterializedViewRefresh(*job.ID())
+	if err := n.desc.SetUpVersion(); err != nil {
+		return err
+	}
+	if err := params.p.writeTableDesc(params.ctx, n.desc); err != nil {
+		return err
+	}
+	params.p.notifySchemaChange(n.desc, sqlbase.InvalidMutationID)
+	return nil
+}
+
+func (*refreshViewNode) Next(runParams) (bool, error) { return false, nil }
+func (*refreshViewNode) Close(context.Context)        {}
+func (*refreshViewNode) Values() tree.Datums          { return tree.Datums{} }
+
+// errRefreshAbandoned is returned while the new results of a materialized
+// view are written if the view was dropped in the meantime.
+var errRefreshAbandoned = errors.New("materialized view refresh abandoned")
+
+// refreshFailedError is returned by the schema changer when the refresh of a
+// materialized view failed because of its new results: the query of the
+// view failed, or the results violate a unique index of the view. The
+// refresh is then abandoned, and the view keeps its previous results.
+type refreshFailedError struct {
+	cause error
+}
+
+func (e *refreshFailedError) Error() string { return e.cause.Error() }
+
+// Cause implements the causer interface, so that the client gets the error of
+// the refresh.
+func (e *refreshFailedError) Cause() error { return e.cause }
+
+// refreshIndexes returns the indexes of a refresh.
+func refreshIndexes(r *sqlbase.TableDescriptor_MaterializedViewRefresh) []sqlbase.IndexDescriptor {
+	return append([]sqlbase.IndexDescriptor{r.NewPrimaryIndex}, r.NewIndexes...)
+}
+
+// refreshMaterializedView runs the refresh of the materialized view in
+// progress. The new results are written to the new indexes of the refresh,
+// which then replace the indexes of the view; the data of the previous
+// indexes is deleted once no node uses them any more.
+func (sc *SchemaChanger) refreshMaterializedView(
+	ctx context.Context,
+	lease *sqlbase.TableDescriptor_SchemaChangeLease,
+	table *sqlbase.TableDescriptor,
+) error {
+	jobID := table.Refresh.JobID
+	job, err := sc.jobRegistry.LoadJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if err := job.Started(ctx); err != nil {
+		if log.V(2) {
+			log.Infof(ctx, "Failed to mark job %d as started: %v", jobID, err)
+		}
+	}
+
+	if !table.Refresh.Swapped {
+		if err := sc.writeMaterializedViewResults(ctx, lease, table); err != nil {
+			if _, ok := pgerror.GetPGCause(err); !ok {
+				return err
+			}
+			// The new results would be the same if the refresh was retried.
+			if err := sc.abandonRefresh(ctx, lease, table, job, err); err != nil {
+				return err
+			}
+			return &refreshFailedError{cause: err}
+		}
+		desc, err := sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+			if desc.Dropped() || desc.Refresh == nil || desc.Refresh.JobID != jobID {
+				return errRefreshAbandoned
+			}
+			desc.SwapMaterializedViewIndexes()
+			return nil
+		}, nil)
+		if err != nil {
+			return err
+		}
+		table = desc.GetTable()
+	}
+
+	// Wait for the nodes to stop reading the previous results before they are
+	// deleted.
+	if err := sc.waitToUpdateLeases(ctx, sc.tableID); err != nil {
+		return err
+	}
+	if err := sc.clearIndexes(ctx, lease, table, refreshIndexes(table.Refresh)); err != nil {
+		return err
+	}
+	_, err = sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+		if desc.Refresh == nil || desc.Refresh.JobID != jobID {
+			return errDidntUpdateDescriptor
+		}
+		desc.Refresh = nil
+		return nil
+	}, func(txn *client.Txn) error {
+		if err := job.WithTxn(txn).Succeeded(ctx); err != nil {
+			log.Warningf(ctx, "schema change ignoring error while marking job %d as successful: %+v",
+				jobID, err)
+		}
+		return nil
+	})
+	return err
+}
+
+// writeMaterializedViewResults computes the new results of the materialized
+// view being refreshed and writes them to the new indexes of the refresh. The
+// query of the view is evaluated at a fixed timestamp, and its rows are
+// written in chunks, each in its own transaction. The new indexes are not
+// used until the refresh completes, so the data written by a previous
+// attempt is simply deleted first.
+func (sc *SchemaChanger) writeMaterializedViewResults(
+	ctx context.Context,
+	lease *sqlbase.TableDescriptor_SchemaChangeLease,
+	table *sqlbase.TableDescriptor,
+) error {
+	r := table.Refresh
+	newDesc := *table
+	newDesc.PrimaryIndex = r.NewPrimaryIndex
+	newDesc.Indexes = r.NewIndexes
+	chunkSize := int(sc.getChunkSize(materializedViewChunkSize))
+
+	readAsOf := sc.clock.Now()
+	return sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ctx, readAsOf)
+		if err := sc.clearIndexes(ctx, lease, table, refreshIndexes(r)); err != nil {
+			return err
+		}
+
+		p := makeInternalPlanner("refresh-materialized-view", txn, security.RootUser, sc.leaseMgr.memMetrics)
+		defer finishInternalPlanner(p)
+		p.session.tables.leaseMgr = sc.leaseMgr
+		// The descriptors used by the query are read at the timestamp of the
+		// results.
+		p.avoidCachedDescriptors = true
+		p.evalCtx.NodeID = sc.nodeID
+		dbDesc, err := sqlbase.GetDatabaseDescFromID(ctx, txn, table.ParentID)
+		if err != nil {
+			return err
+		}
+		p.session.Database = dbDesc.Name
+
+		var alloc sqlbase.DatumAlloc
+		rows := make([]tree.Datums, 0, chunkSize)
+		flush := func() error {
+			if err := sc.ExtendLease(ctx, lease); err != nil {
+				return err
+			}
+			if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+				if sc.testingKnobs.RunBeforeRefreshChunk != nil {
+					if err := sc.testingKnobs.RunBeforeRefreshChunk(); err != nil {
+						return err
+					}
+				}
+				desc, err := sqlbase.GetTableDescFromID(ctx, txn, sc.tableID)
+				if err != nil {
+					return err
+				}
+				if desc.Dropped() || desc.Refresh == nil || desc.Refresh.JobID != r.JobID {
+					return errRefreshAbandoned
+				}
+				ri, err := sqlbase.MakeRowInserter(
+					txn, &newDesc, nil /* fkTables */, newDesc.Columns, sqlbase.SkipFKs, &alloc,
+				)
+				if err != nil {
+					return err
+				}
+				b := txn.NewBatch()
+				for _, row := range rows {
+					if err := ri.InsertRow(ctx, b, row, false /* ignoreConflicts */, false /* traceKV */); err != nil {
+						return err
+					}
+				}
+				if err := txn.Run(ctx, b); err != nil {
+					return sqlbase.ConvertBatchError(ctx, &newDesc, b)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			rows = rows[:0]
+			return nil
+		}
+		if err := p.forEachMaterializedViewRow(ctx, table, func(row tree.Datums) error {
+			rows = append(rows, append(tree.Datums(nil), row...))
+			if len(rows) == chunkSize {
+				return flush()
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return flush()
+	})
+}
+
+// abandonRefresh abandons the refresh of a materialized view which failed
+// because of its new results. The data written to the new indexes is
+// deleted, and the view keeps its previous results.
+func (sc *SchemaChanger) abandonRefresh(
+	ctx context.Context,
+	lease *sqlbase.TableDescriptor_SchemaChangeLease,
+	table *sqlbase.TableDescriptor,
+	job *jobs.Job,
+	cause error,
+) error {
+	if err := sc.clearIndexes(ctx, lease, table, refreshIndexes(table.Refresh)); err != nil {
+		return err
+	}
+	jobID := table.Refresh.JobID
+	if _, err := sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+		if desc.Refresh == nil || desc.Refresh.JobID != jobID {
+			return errDidntUpdateDescriptor
+		}
+		desc.Refresh = nil
+		return nil
+	}, nil); err != nil {
+		return err
+	}
+	job.Failed(ctx, cause)
+	return nil
+}
+
+// clearIndexes deletes the data of the given indexes of a table, which are
+// not used by any node. The keys are deleted in chunks, each in its own
+// transaction.
+func (sc *SchemaChanger) clearIndexes(
+	ctx context.Context,
+	lease *sqlbase.TableDescriptor_SchemaChangeLease,
+	table *sqlbase.TableDescriptor,
+	indexes []sqlbase.IndexDescriptor,
+) error {
+	chunkSize := sc.getChunkSize(indexTruncateChunkSize)
+	for _, idx := range indexes {
+		for span := table.IndexSpan(idx.ID); span.Key != nil; {
+			if err := sc.ExtendLease(ctx, lease); err != nil {
+				return err
+			}
+			var resume roachpb.Span
+			if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+				b := txn.NewBatch()
+				b.DelRange(span.Key, span.EndKey, false /* returnKeys */)
+				b.Header.MaxSpanRequestKeys = chunkSize
+				if err := txn.Run(ctx, b); err != nil {
+					return err
+				}
+				resume = b.Results[0].ResumeSpan
+				return nil
+			}); err != nil {
+				return err
+			}
+			span = resume
+		}
+	}
+	return nil
+}
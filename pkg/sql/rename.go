@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -66,7 +67,7 @@ func (p *planner) RenameDatabase(ctx context.Context, n *tree.RenameDatabase) (p
 	// are currently just stored as strings, they explicitly specify the database
 	// name. Rather than trying to rewrite them with the changed DB name, we
 	// simply disallow such renames for now.
-	tbNames, err := getTableNames(ctx, p.txn, p.getVirtualTabler(), dbDesc, false)
+	tbNames, err := getAllTableNames(ctx, p.txn, p.getVirtualTabler(), dbDesc)
 	if err != nil {
 		return nil, err
 	}
@@ -121,10 +122,16 @@ func (p *planner) RenameTable(ctx context.Context, n *tree.RenameTable) (planNod
 		return nil, err
 	}
 
-	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), oldTn.Database())
-	if err != nil {
+	if err := p.searchTempSchema(ctx, oldTn); err != nil {
+		return nil, err
+	}
+	if _, _, err := resolveTableSchema(ctx, p.txn, p.getVirtualTabler(), oldTn); err != nil {
 		return nil, err
 	}
+	if IsTempSchemaName(oldTn.Schema()) && newTn.DBNameOriginallyOmitted && newTn.SchemaName == "" {
+		// A temporary table keeps its schema when renamed.
+		newTn.SchemaName = oldTn.SchemaName
+	}
 
 	// Check if source table or view exists.
 	// Note that Postgres's behavior here is a little lenient - it'll let you
@@ -179,27 +186,38 @@ func (p *planner) RenameTable(ctx context.Context, n *tree.RenameTable) (planNod
 			ctx, tableDesc.TypeName(), oldTn.String(), tableDesc.ParentID, tableDesc.DependedOnBy[0].ID)
 	}
 
-	// Check if target database exists.
-	targetDbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), newTn.Database())
+	// Check if target database and schema exist.
+	targetDbDesc, targetScDesc, err := resolveTableSchema(ctx, p.txn, p.getVirtualTabler(), newTn)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(targetDbDesc, privilege.CREATE); err != nil {
+	if IsTempSchemaName(oldTn.Schema()) != IsTempSchemaName(newTn.Schema()) {
+		return nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"cannot move objects into or out of temporary schemas")
+	}
+
+	if err := p.CheckPrivilege(createPrivilegeTarget(targetDbDesc, targetScDesc), privilege.CREATE); err != nil {
 		return nil, err
 	}
 
 	// oldTn and newTn are already normalized, so we can compare directly here.
-	if oldTn.Database() == newTn.Database() && oldTn.Table() == newTn.Table() {
+	if oldTn.Database() == newTn.Database() && oldTn.Schema() == newTn.Schema() &&
+		oldTn.Table() == newTn.Table() {
 		// Noop.
 		return &zeroNode{}, nil
 	}
 
+	oldParentID := tableDesc.NamespaceParentID()
 	tableDesc.SetName(newTn.Table())
 	tableDesc.ParentID = targetDbDesc.ID
+	tableDesc.ParentSchemaID = 0
+	if targetScDesc != nil {
+		tableDesc.ParentSchemaID = targetScDesc.ID
+	}
 
 	descKey := sqlbase.MakeDescMetadataKey(tableDesc.GetID())
-	newTbKey := tableKey{targetDbDesc.ID, newTn.Table()}.Key()
+	newTbKey := tableKey{tableDesc.NamespaceParentID(), newTn.Table()}.Key()
 
 	if err := tableDesc.Validate(ctx, p.txn); err != nil {
 		return nil, err
@@ -212,7 +230,7 @@ func (p *planner) RenameTable(ctx context.Context, n *tree.RenameTable) (planNod
 		return nil, err
 	}
 	renameDetails := sqlbase.TableDescriptor_RenameInfo{
-		OldParentID: dbDesc.ID,
+		OldParentID: oldParentID,
 		OldName:     oldTn.Table()}
 	tableDesc.Renames = append(tableDesc.Renames, renameDetails)
 	if err := p.writeTableDesc(ctx, tableDesc); err != nil {
@@ -21,6 +21,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -143,7 +144,7 @@ func (p *planner) Select(
 	wrapped := n.Select
 	limit := n.Limit
 	orderBy := n.OrderBy
-	lockForUpdate := n.LockForUpdate
+	locking := n.Locking
 
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		wrapped = s.Select.Select
@@ -159,16 +160,20 @@ func (p *planner) Select(
 			}
 			limit = s.Select.Limit
 		}
-		if s.Select.LockForUpdate {
-			lockForUpdate = s.Select.LockForUpdate
+		if s.Select.Locking.Strength > locking.Strength {
+			locking = s.Select.Locking
 		}
 	}
 
 	switch s := wrapped.(type) {
 	case *tree.SelectClause:
+		scanLocking, err := p.scanLocking(locking)
+		if err != nil {
+			return nil, err
+		}
 		// Select can potentially optimize index selection if it's being ordered,
 		// so we allow it to do its own sorting.
-		return p.SelectClause(ctx, s, orderBy, limit, lockForUpdate, desiredTypes, publicColumns)
+		return p.SelectClause(ctx, s, orderBy, limit, scanLocking, desiredTypes, publicColumns)
 
 	// TODO(dan): Union can also do optimizations when it has an ORDER BY, but
 	// currently expects the ordering to be done externally, so we let it fall
@@ -176,6 +181,10 @@ func (p *planner) Select(
 	// investigating a general mechanism for passing some context down during
 	// plan node construction.
 	default:
+		if locking.Strength != tree.ForNone {
+			return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"%s is not allowed with UNION/INTERSECT/EXCEPT or VALUES", locking.Strength)
+		}
 		plan, err := p.newPlan(ctx, s, desiredTypes)
 		if err != nil {
 			return nil, err
@@ -200,6 +209,27 @@ func (p *planner) Select(
 	}
 }
 
+// scanLocking determines the row-level locking performed by the scans of a
+// SELECT with the given locking clause.
+//
+// Only exclusive locks are supported; FOR NO KEY UPDATE is treated like FOR
+// UPDATE. FOR SHARE, FOR KEY SHARE and SKIP LOCKED return an error rather
+// than silently providing weaker guarantees than requested.
+func (p *planner) scanLocking(locking tree.LockingClause) (sqlbase.ScanLocking, error) {
+	if locking.Strength != tree.ForNone && !locking.Strength.IsExclusive() {
+		return sqlbase.ScanLocking{}, pgerror.Unimplemented(
+			"shared locks", fmt.Sprintf("%s is not supported", locking.Strength))
+	}
+	if locking.WaitPolicy == tree.LockWaitSkip {
+		return sqlbase.ScanLocking{}, pgerror.Unimplemented(
+			"skip locked", fmt.Sprintf("%s SKIP LOCKED is not supported", locking.Strength))
+	}
+	return sqlbase.ScanLocking{
+		ForUpdate: locking.Strength.IsExclusive(),
+		NoWait:    locking.WaitPolicy == tree.LockWaitError,
+	}, nil
+}
+
 // SelectClause selects rows from a single table. Select is the workhorse of the
 // SQL statements. In the slowest and most general case, select must perform
 // full table scans across multiple tables and sort and join the resulting rows
@@ -219,13 +249,13 @@ func (p *planner) SelectClause(
 	parsed *tree.SelectClause,
 	orderBy tree.OrderBy,
 	limit *tree.Limit,
-	lockForUpdate bool,
+	locking sqlbase.ScanLocking,
 	desiredTypes []types.T,
 	scanVisibility scanVisibility,
 ) (planNode, error) {
 	r := &renderNode{planner: p}
 
-	if err := r.initFrom(ctx, parsed, scanVisibility, lockForUpdate); err != nil {
+	if err := r.initFrom(ctx, parsed, scanVisibility, locking); err != nil {
 		return nil, err
 	}
 
@@ -302,13 +332,13 @@ func (p *planner) SelectClause(
 
 // initFrom initializes the table node, given the parsed select expression
 func (r *renderNode) initFrom(
-	ctx context.Context, parsed *tree.SelectClause, scanVisibility scanVisibility, lockForUpdate bool,
+	ctx context.Context, parsed *tree.SelectClause, scanVisibility scanVisibility, locking sqlbase.ScanLocking,
 ) error {
 	// AS OF expressions should be handled by the executor.
 	if parsed.From.AsOf.Expr != nil && !r.planner.avoidCachedDescriptors {
 		return fmt.Errorf("unexpected AS OF SYSTEM TIME")
 	}
-	src, err := r.planner.getSources(ctx, parsed.From.Tables, scanVisibility, lockForUpdate)
+	src, err := r.planner.getSources(ctx, parsed.From.Tables, scanVisibility, locking)
 	if err != nil {
 		return err
 	}
@@ -491,6 +521,16 @@ func isUnarySource(src planDataSource) bool {
 }
 
 func (r *renderNode) initWhere(ctx context.Context, whereExpr tree.Expr) (*filterNode, error) {
+	if whereExpr != nil {
+		// Plan the correlated EXISTS subqueries as joins if possible. This
+		// replaces r.source but does not change its columns.
+		var err error
+		whereExpr, err = r.decorrelateWhere(ctx, whereExpr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	f := &filterNode{source: r.source}
 	f.ivarHelper = tree.MakeIndexedVarHelper(f, len(r.sourceInfo[0].sourceColumns))
 
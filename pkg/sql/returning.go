@@ -49,13 +49,16 @@ type returningHelper struct {
 }
 
 // newReturningHelper creates a new returningHelper for use by an
-// insert/update node.
+// insert/update node. If joined is not nil, the RETURNING expressions can
+// also refer to the columns it describes, whose values follow the table
+// columns in the rows passed to cookResultRow.
 func (p *planner) newReturningHelper(
 	ctx context.Context,
 	r tree.ReturningClause,
 	desiredTypes []types.T,
 	tn *tree.TableName,
 	tablecols []sqlbase.ColumnDescriptor,
+	joined *dataSourceInfo,
 ) (*returningHelper, error) {
 	rh := &returningHelper{
 		p: p,
@@ -82,8 +85,17 @@ func (p *planner) newReturningHelper(
 	rh.source = newSourceInfoForSingleTable(
 		*tn, sqlbase.ResultColumnsFromColDescs(tablecols),
 	)
+	if joined != nil {
+		rh.source.sourceColumns = append(rh.source.sourceColumns, joined.sourceColumns...)
+		for _, alias := range joined.sourceAliases {
+			rh.source.sourceAliases = append(rh.source.sourceAliases, sourceAlias{
+				name:      alias.name,
+				columnSet: alias.columnSet.Shift(len(tablecols)),
+			})
+		}
+	}
 	rh.exprs = make([]tree.TypedExpr, 0, len(rExprs))
-	ivarHelper := tree.MakeIndexedVarHelper(rh, len(tablecols))
+	ivarHelper := tree.MakeIndexedVarHelper(rh, len(rh.source.sourceColumns))
 	for _, target := range rExprs {
 		cols, typedExprs, _, err := p.computeRenderAllowingStars(
 			ctx, target, types.Any, multiSourceInfo{rh.source}, ivarHelper,
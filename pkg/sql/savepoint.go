@@ -0,0 +1,138 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// savepoint is a savepoint created by a SAVEPOINT statement, other than the
+// restart savepoint.
+type savepoint struct {
+	name  string
+	token client.SavepointToken
+	// numSchemaChanges is the number of schema changes performed by the txn
+	// when the savepoint was created. Schema changes cannot be rolled back to
+	// a savepoint, as they are also recorded outside of the KV txn.
+	numSchemaChanges int
+}
+
+var errHeldTxnAbandoned = errors.New("transaction aborted without rolling back to a savepoint")
+
+// numSchemaChanges returns the number of schema changes performed by the
+// current txn.
+func numSchemaChanges(session *Session) int {
+	return len(session.TxnState.schemaChangers.schemaChangers) +
+		len(session.tables.uncommittedTables) +
+		len(session.tables.uncommittedDatabases)
+}
+
+// canRollBackToSavepoint returns true if the txn can be resumed by rolling
+// back to a savepoint after encountering err.
+func (ts *txnState) canRollBackToSavepoint(err error) bool {
+	if len(ts.savepoints) == 0 || ts.commitSeen {
+		return false
+	}
+	switch err.(type) {
+	case *roachpb.HandledRetryableTxnError, *roachpb.AmbiguousResultError:
+		// Retryable errors restart the KV txn, and the outcome of an
+		// ambiguous error is unknown; neither is undone by a savepoint.
+		return false
+	}
+	return !ts.mu.txn.IsFinalized() && ts.mu.txn.Proto().Status == roachpb.PENDING
+}
+
+// rollbackHeldTxn rolls back the KV txn which an Aborted txn kept open to
+// allow rolling back to a savepoint, if any.
+func (ts *txnState) rollbackHeldTxn() {
+	ts.savepoints = nil
+	if ts.State() != Aborted || ts.mu.txn == nil {
+		return
+	}
+	ts.mu.txn.CleanupOnError(ts.Ctx, errHeldTxnAbandoned)
+	ts.resetStateAndTxn(Aborted)
+}
+
+// findSavepoint returns the index of the most recently created savepoint
+// with the given name.
+func (ts *txnState) findSavepoint(name string) (int, error) {
+	for i := len(ts.savepoints) - 1; i >= 0; i-- {
+		if ts.savepoints[i].name == name {
+			return i, nil
+		}
+	}
+	return 0, pgerror.NewErrorf(pgerror.CodeInvalidSavepointSpecificationError,
+		"savepoint %q does not exist", name)
+}
+
+// execSavepoint executes a SAVEPOINT statement for a savepoint other than
+// the restart savepoint.
+func execSavepoint(session *Session, s *tree.Savepoint, res StatementResult) error {
+	txnState := &session.TxnState
+	txnState.savepoints = append(txnState.savepoints, savepoint{
+		name:             s.Name,
+		token:            txnState.mu.txn.Savepoint(),
+		numSchemaChanges: numSchemaChanges(session),
+	})
+	res.BeginResult((*tree.Savepoint)(nil))
+	return res.CloseResult()
+}
+
+// execReleaseSavepoint executes a RELEASE SAVEPOINT statement for a
+// savepoint other than the restart savepoint. The savepoint and all the
+// savepoints created after it are destroyed; the writes performed since are
+// kept.
+func execReleaseSavepoint(
+	session *Session, s *tree.ReleaseSavepoint, res StatementResult,
+) error {
+	txnState := &session.TxnState
+	i, err := txnState.findSavepoint(s.Savepoint)
+	if err != nil {
+		return err
+	}
+	txnState.savepoints = txnState.savepoints[:i]
+	res.BeginResult((*tree.ReleaseSavepoint)(nil))
+	return res.CloseResult()
+}
+
+// execRollbackToSavepoint executes a ROLLBACK TO SAVEPOINT statement for a
+// savepoint other than the restart savepoint. The writes performed since
+// the savepoint was created are undone and the savepoints created after it
+// are destroyed; the savepoint itself remains.
+func execRollbackToSavepoint(
+	session *Session, s *tree.RollbackToSavepoint, res StatementResult,
+) error {
+	txnState := &session.TxnState
+	i, err := txnState.findSavepoint(s.Savepoint)
+	if err != nil {
+		return err
+	}
+	sp := txnState.savepoints[i]
+	if numSchemaChanges(session) != sp.numSchemaChanges {
+		return pgerror.Unimplemented("rollback to savepoint schema change",
+			"cannot roll back to a savepoint created before a schema change")
+	}
+	if err := txnState.mu.txn.RollbackToSavepoint(txnState.Ctx, sp.token); err != nil {
+		return err
+	}
+	txnState.savepoints = txnState.savepoints[:i+1]
+	res.BeginResult((*tree.RollbackToSavepoint)(nil))
+	return res.CloseResult()
+}
@@ -73,7 +73,7 @@ type scanNode struct {
 	spans            []roachpb.Span
 	isSecondaryIndex bool
 	reverse          bool
-	lockForUpdate    bool
+	locking          sqlbase.ScanLocking
 	props            physicalProps
 
 	rowIndex int64 // the index of the current row
@@ -133,7 +133,7 @@ func (n *scanNode) disableBatchLimit() {
 }
 
 func (n *scanNode) Start(runParams) error {
-	return n.fetcher.Init(n.desc, n.colIdxMap, n.index, n.reverse, n.lockForUpdate, n.isSecondaryIndex,
+	return n.fetcher.Init(n.desc, n.colIdxMap, n.index, n.reverse, n.locking, n.isSecondaryIndex,
 		n.cols, n.valNeededForCol, false /* returnRangeInfo */, &n.p.alloc)
 }
 
@@ -190,6 +190,11 @@ func (n *scanNode) Next(params runParams) (bool, error) {
 			return false, err
 		}
 		if passesFilter {
+			if n.locking.ForUpdate {
+				if err := n.fetcher.LockRow(params.ctx); err != nil {
+					return false, err
+				}
+			}
 			n.rowIndex++
 			return true, nil
 		}
@@ -0,0 +1,679 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// User-defined schemas sit between a database and its tables. They are
+// stored inside the descriptor of their database, and each of them is
+// assigned an ID from the descriptor ID space. The namespace entries of
+// the tables of a schema are keyed by the ID of the schema instead of
+// the ID of the database; the tables of the implicit public schema keep
+// using the ID of the database.
+//
+// The parser cannot tell apart <database>.<table> from <schema>.<table>,
+// nor <prefix>.<database>.<table> from <database>.<schema>.<table>.
+// Table names are therefore reinterpreted upon resolution by
+// resolveTableSchema() below.
+
+var errEmptySchemaName = errors.New("empty schema name")
+
+// normalizeSchemaPrefix rewrites a three-part name <database>.<schema>.<table>,
+// which the parser produces as <prefix>.<database>.<table>, so that the
+// schema is stored in the SchemaName field. Names that designate virtual
+// tables are left unchanged, as virtual tables use the prefix to scope
+// their contents to a database.
+func normalizeSchemaPrefix(vt VirtualTabler, tn *tree.TableName) {
+	if !tn.PrefixOriginallySpecified || vt.getVirtualDatabaseDesc(tn.Database()) != nil {
+		return
+	}
+	tn.SchemaName = tn.DatabaseName
+	tn.DatabaseName = tn.PrefixName
+	tn.PrefixName = ""
+	tn.PrefixOriginallySpecified = false
+}
+
+// qualifyWithSessionSchema rewrites a two-part name whose first part was
+// taken to be a database name to refer instead to a schema of the
+// session database. It returns false if the name cannot be
+// reinterpreted this way.
+func qualifyWithSessionSchema(tn *tree.TableName) bool {
+	if tn.SchemaName != "" || tn.DBNameOriginallyOmitted ||
+		tn.SessionDatabase() == "" || tn.Database() == tn.SessionDatabase() {
+		return false
+	}
+	tn.SchemaName = tn.DatabaseName
+	tn.DatabaseName = tree.Name(tn.SessionDatabase())
+	tn.DBNameOriginallyOmitted = true
+	return true
+}
+
+// findSchema looks up the user-defined schema with the given name in
+// the database. The empty name and the name of the public schema
+// designate the public schema, for which a nil descriptor is returned.
+func findSchema(
+	dbDesc *sqlbase.DatabaseDescriptor, name string,
+) (*sqlbase.SchemaDescriptor, error) {
+	if name == "" || name == tree.PublicSchemaName {
+		return nil, nil
+	}
+	scDesc, ok := dbDesc.FindSchema(name)
+	if !ok {
+		return nil, sqlbase.NewUndefinedSchemaError(name)
+	}
+	return scDesc, nil
+}
+
+// namespaceParentID returns the ID under which the namespace entries of
+// the tables of the given schema are keyed. A nil schema designates the
+// public schema of the database.
+func namespaceParentID(
+	dbDesc *sqlbase.DatabaseDescriptor, scDesc *sqlbase.SchemaDescriptor,
+) sqlbase.ID {
+	if scDesc != nil {
+		return scDesc.ID
+	}
+	return dbDesc.ID
+}
+
+// createPrivilegeTarget returns the descriptor on which the CREATE
+// privilege is required to create a table in the given schema, and
+// whose privileges the new table inherits.
+func createPrivilegeTarget(
+	dbDesc *sqlbase.DatabaseDescriptor, scDesc *sqlbase.SchemaDescriptor,
+) sqlbase.DescriptorProto {
+	if scDesc != nil {
+		return scDesc
+	}
+	return dbDesc
+}
+
+// resolveTableSchema resolves the database and the schema designated by
+// a table name, and normalizes the name so that its SchemaName field is
+// set if and only if the table belongs to a user-defined schema. A nil
+// schema descriptor is returned for the public schema.
+func resolveTableSchema(
+	ctx context.Context, txn *client.Txn, vt VirtualTabler, tn *tree.TableName,
+) (*sqlbase.DatabaseDescriptor, *sqlbase.SchemaDescriptor, error) {
+	normalizeSchemaPrefix(vt, tn)
+
+	dbDesc, err := getDatabaseDesc(ctx, txn, vt, tn.Database())
+	if err != nil {
+		return nil, nil, err
+	}
+	if dbDesc == nil {
+		// The name may be of the form <schema>.<table>.
+		t := *tn
+		if !qualifyWithSessionSchema(&t) {
+			return nil, nil, sqlbase.NewUndefinedDatabaseError(tn.Database())
+		}
+		sessionDB, err := getDatabaseDesc(ctx, txn, vt, t.Database())
+		if err != nil {
+			return nil, nil, err
+		}
+		if sessionDB == nil {
+			return nil, nil, sqlbase.NewUndefinedDatabaseError(tn.Database())
+		}
+		if _, err := findSchema(sessionDB, t.Schema()); err != nil {
+			return nil, nil, sqlbase.NewUndefinedDatabaseError(tn.Database())
+		}
+		*tn = t
+		dbDesc = sessionDB
+	}
+
+	scDesc, err := findSchema(dbDesc, tn.Schema())
+	if err != nil {
+		return nil, nil, err
+	}
+	if scDesc == nil {
+		tn.SchemaName = ""
+	}
+	return dbDesc, scDesc, nil
+}
+
+// getNamespaceID is the counterpart of resolveTableSchema for the table
+// collection: it resolves the database through the databases modified
+// by the transaction and the database cache, and returns the ID under
+// which the namespace entry of the table is keyed.
+func (tc *TableCollection) getNamespaceID(
+	ctx context.Context, txn *client.Txn, vt VirtualTabler, tn *tree.TableName,
+) (sqlbase.ID, error) {
+	normalizeSchemaPrefix(vt, tn)
+
+	getDatabaseID := func(tn *tree.TableName) (sqlbase.ID, error) {
+		dbID, err := tc.getUncommittedDatabaseID(tn)
+		if err != nil || dbID != 0 {
+			return dbID, err
+		}
+		// Resolve the database from the database cache when the transaction
+		// hasn't modified the database.
+		return tc.databaseCache.getDatabaseID(ctx, tc.leaseMgr.LeaseStore.db.Txn, vt, tn.Database())
+	}
+
+	dbID, err := getDatabaseID(tn)
+	if sqlbase.IsUndefinedDatabaseError(err) {
+		// The name may be of the form <schema>.<table>.
+		t := *tn
+		if qualifyWithSessionSchema(&t) {
+			if sessionDBID, err := getDatabaseID(&t); err == nil {
+				if id, err := tc.getSchemaID(ctx, txn, sessionDBID, t.Schema()); err == nil {
+					*tn = t
+					if id == sessionDBID {
+						tn.SchemaName = ""
+					}
+					return id, nil
+				}
+			}
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	id, err := tc.getSchemaID(ctx, txn, dbID, tn.Schema())
+	if err != nil {
+		return 0, err
+	}
+	if id == dbID {
+		tn.SchemaName = ""
+	}
+	return id, nil
+}
+
+// getSchemaID returns the ID of the schema of the given database, or the
+// ID of the database itself for the public schema. The database
+// descriptor is read from the database cache, and from the transaction
+// if the database was modified by the transaction or if the cache does
+// not know about the schema yet.
+func (tc *TableCollection) getSchemaID(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, name string,
+) (sqlbase.ID, error) {
+	if name == "" || name == tree.PublicSchemaName {
+		return dbID, nil
+	}
+	if !tc.isUncommittedDatabase(dbID) {
+		dbDesc, err := tc.databaseCache.getCachedDatabaseDescByID(dbID)
+		if err == nil && dbDesc != nil {
+			if scDesc, ok := dbDesc.FindSchema(name); ok {
+				return scDesc.ID, nil
+			}
+		}
+	}
+	dbDesc, err := MustGetDatabaseDescByID(ctx, txn, dbID)
+	if err != nil {
+		return 0, err
+	}
+	scDesc, err := findSchema(dbDesc, name)
+	if err != nil {
+		return 0, err
+	}
+	return scDesc.ID, nil
+}
+
+// sessionSchemaSearchPath returns the names of the schemas of the session
+// database in which unqualified table names are looked up, in order. The
+// public schema is designated by the empty string. The schemas are taken
+// from the search_path; entries that designate virtual databases are
+// skipped. The public schema is searched after the schemas listed in the
+// search_path, unless the search_path mentions it explicitly.
+//
+// Once the session has created temporary tables, its temporary schema is
+// searched first, unless the search_path mentions it explicitly as
+// pg_temp.
+func (p *planner) sessionSchemaSearchPath() []string {
+	var schemas []string
+	hasPublic := false
+	// The temporary schema is only searched by the sessions which created
+	// it, so as to not slow down the lookups of the others.
+	tempSchema := ""
+	if len(p.session.tempSchemaDBs) > 0 {
+		tempSchema = p.session.tempSchemaName
+		hasTemp := false
+		iter := p.session.SearchPath.IterWithoutImplicitPGCatalog()
+		for name, ok := iter(); ok; name, ok = iter() {
+			hasTemp = hasTemp || name == pgTempSchemaName
+		}
+		if !hasTemp {
+			schemas = append(schemas, tempSchema)
+		}
+	}
+	iter := p.session.SearchPath.IterWithoutImplicitPGCatalog()
+	for name, ok := iter(); ok; name, ok = iter() {
+		if name == pgTempSchemaName {
+			if tempSchema != "" {
+				schemas = append(schemas, tempSchema)
+			}
+			continue
+		}
+		if name == tree.PublicSchemaName {
+			if !hasPublic {
+				hasPublic = true
+				schemas = append(schemas, "")
+			}
+			continue
+		}
+		if p.session.virtualSchemas.isVirtualDatabase(name) {
+			continue
+		}
+		schemas = append(schemas, name)
+	}
+	if !hasPublic {
+		schemas = append(schemas, "")
+	}
+	return schemas
+}
+
+// searchSessionSchemas looks up an unqualified table name in the schemas
+// of the session database listed by sessionSchemaSearchPath(). The
+// provided TableName is qualified in-place with the database and the
+// schema the table was found in. It returns false if the table was not
+// found, in which case the TableName is left unchanged.
+func (p *planner) searchSessionSchemas(ctx context.Context, tn *tree.TableName) (bool, error) {
+	if p.session.Database == "" {
+		return false, nil
+	}
+	descFunc := p.session.tables.getTableVersion
+	if p.avoidCachedDescriptors {
+		descFunc = getTableOrViewDesc
+	}
+	for _, schema := range p.sessionSchemaSearchPath() {
+		t := *tn
+		t.DatabaseName = tree.Name(p.session.Database)
+		t.SchemaName = tree.Name(schema)
+		desc, err := descFunc(ctx, p.txn, p.getVirtualTabler(), &t)
+		if err != nil && !sqlbase.IsUndefinedRelationError(err) &&
+			!sqlbase.IsUndefinedDatabaseError(err) && !sqlbase.IsUndefinedSchemaError(err) {
+			return false, err
+		}
+		if desc != nil {
+			*tn = t
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getSchemaTableNames retrieves the list of qualified names of the
+// tables present in the given schema of the database.
+func getSchemaTableNames(
+	ctx context.Context,
+	txn *client.Txn,
+	dbDesc *sqlbase.DatabaseDescriptor,
+	scDesc *sqlbase.SchemaDescriptor,
+	dbNameOriginallyOmitted bool,
+) (tree.TableNames, error) {
+	tableNames, err := scanTableNames(ctx, txn, scDesc.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tableNames {
+		tableNames[i].DatabaseName = tree.Name(dbDesc.Name)
+		tableNames[i].SchemaName = tree.Name(scDesc.Name)
+		tableNames[i].DBNameOriginallyOmitted = dbNameOriginallyOmitted
+	}
+	return tableNames, nil
+}
+
+// getAllTableNames retrieves the list of qualified names of the tables
+// present in the given database, including the tables of its
+// user-defined schemas.
+func getAllTableNames(
+	ctx context.Context, txn *client.Txn, vt VirtualTabler, dbDesc *sqlbase.DatabaseDescriptor,
+) (tree.TableNames, error) {
+	tableNames, err := getTableNames(ctx, txn, vt, dbDesc, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range dbDesc.Schemas {
+		scTableNames, err := getSchemaTableNames(ctx, txn, dbDesc, &dbDesc.Schemas[i], false)
+		if err != nil {
+			return nil, err
+		}
+		tableNames = append(tableNames, scTableNames...)
+	}
+	return tableNames, nil
+}
+
+// resolveSchema looks up the user-defined schema designated by the given
+// name, which defaults to the session database.
+func (p *planner) resolveSchema(
+	ctx context.Context, sn tree.SchemaName,
+) (*sqlbase.DatabaseDescriptor, *sqlbase.SchemaDescriptor, error) {
+	if sn.SchemaName == "" {
+		return nil, nil, errEmptySchemaName
+	}
+	dbName := sn.Database(p.session.Database)
+	if dbName == "" {
+		return nil, nil, errNoDatabase
+	}
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), dbName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sn.Schema() == tree.PublicSchemaName {
+		return nil, nil, pgerror.NewErrorf(pgerror.CodeInvalidSchemaNameError,
+			"cannot modify the public schema")
+	}
+	scDesc, ok := dbDesc.FindSchema(sn.Schema())
+	if !ok {
+		return dbDesc, nil, sqlbase.NewUndefinedSchemaError(sn.Schema())
+	}
+	return dbDesc, scDesc, nil
+}
+
+// writeDatabaseDesc writes a database descriptor modified by a schema
+// statement within the current planner transaction.
+func (p *planner) writeDatabaseDesc(ctx context.Context, dbDesc *sqlbase.DatabaseDescriptor) error {
+	if err := dbDesc.Validate(); err != nil {
+		return err
+	}
+	descKey := sqlbase.MakeDescMetadataKey(dbDesc.ID)
+	descDesc := sqlbase.WrapDescriptor(dbDesc)
+	if p.session.Tracing.KVTracingEnabled() {
+		log.VEventf(ctx, 2, "Put %s -> %s", descKey, descDesc)
+	}
+	if err := p.txn.Put(ctx, descKey, descDesc); err != nil {
+		return err
+	}
+	// Further lookups of the schemas of the database within the
+	// transaction must not go through the database cache.
+	p.session.tables.addUncommittedDatabase(dbDesc.Name, dbDesc.ID, false /* dropped */)
+	return nil
+}
+
+// CreateSchema creates a user-defined schema.
+// Privileges: CREATE on database.
+//   Notes: postgres requires CREATE on database.
+func (p *planner) CreateSchema(ctx context.Context, n *tree.CreateSchema) (planNode, error) {
+	if n.Schema.SchemaName == "" {
+		return nil, errEmptySchemaName
+	}
+	dbName := n.Schema.Database(p.session.Database)
+	if dbName == "" {
+		return nil, errNoDatabase
+	}
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), dbName)
+	if err != nil {
+		return nil, err
+	}
+	if p.session.virtualSchemas.isVirtualDatabase(dbName) {
+		return nil, fmt.Errorf("cannot create a schema in virtual database %q", dbName)
+	}
+	if err := checkSchemaName(n.Schema.Schema()); err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	return &createSchemaNode{n: n, dbDesc: dbDesc}, nil
+}
+
+type createSchemaNode struct {
+	n      *tree.CreateSchema
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+func (n *createSchemaNode) Start(params runParams) error {
+	name := n.n.Schema.Schema()
+	if _, ok := n.dbDesc.FindSchema(name); ok || name == tree.PublicSchemaName {
+		if n.n.IfNotExists {
+			return nil
+		}
+		return sqlbase.NewSchemaAlreadyExistsError(name)
+	}
+
+	id, err := GenerateUniqueDescID(params.ctx, params.p.session.execCfg.DB)
+	if err != nil {
+		return err
+	}
+
+	// The schema inherits the privileges of its database.
+	privs := *n.dbDesc.GetPrivileges()
+	n.dbDesc.Schemas = append(n.dbDesc.Schemas, sqlbase.SchemaDescriptor{
+		Name:       name,
+		ID:         id,
+		Privileges: &privs,
+	})
+	return params.p.writeDatabaseDesc(params.ctx, n.dbDesc)
+}
+
+func (*createSchemaNode) Next(runParams) (bool, error) { return false, nil }
+func (*createSchemaNode) Close(context.Context)        {}
+func (*createSchemaNode) Values() tree.Datums          { return tree.Datums{} }
+
+type dropSchemaNode struct {
+	n *tree.DropSchema
+	// schemas are the schemas to drop, grouped by database.
+	schemas []schemaToDrop
+	td      []*sqlbase.TableDescriptor
+}
+
+type schemaToDrop struct {
+	dbDesc *sqlbase.DatabaseDescriptor
+	ids    []sqlbase.ID
+}
+
+// DropSchema drops user-defined schemas.
+// Privileges: DROP on schema and DROP on all tables in the schema.
+//   Notes: postgres allows only the schema owner to DROP a schema.
+func (p *planner) DropSchema(ctx context.Context, n *tree.DropSchema) (planNode, error) {
+	var schemas []schemaToDrop
+	var td []*sqlbase.TableDescriptor
+	for _, sn := range n.Names {
+		dbDesc, scDesc, err := p.resolveSchema(ctx, sn)
+		if err != nil {
+			if n.IfExists && scDesc == nil && dbDesc != nil && sqlbase.IsUndefinedSchemaError(err) {
+				// Noop.
+				continue
+			}
+			return nil, err
+		}
+
+		if err := p.CheckPrivilege(scDesc, privilege.DROP); err != nil {
+			return nil, err
+		}
+
+		// Several schemas of the same database may be dropped at once; they
+		// must all be removed from a single copy of the database descriptor.
+		i := 0
+		for ; i < len(schemas); i++ {
+			if schemas[i].dbDesc.ID == dbDesc.ID {
+				break
+			}
+		}
+		if i == len(schemas) {
+			schemas = append(schemas, schemaToDrop{dbDesc: dbDesc})
+		}
+		schemas[i].ids = append(schemas[i].ids, scDesc.ID)
+
+		tbNames, err := getSchemaTableNames(ctx, p.txn, dbDesc, scDesc, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(tbNames) > 0 && n.DropBehavior != tree.DropCascade {
+			return nil, pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
+				"schema %q is not empty and CASCADE was not specified",
+				tree.ErrString(&sn)).SetHintf("use DROP SCHEMA ... CASCADE to drop its tables")
+		}
+
+		for i := range tbNames {
+			tbDesc, err := p.dropTableOrViewPrepare(ctx, &tbNames[i])
+			if err != nil {
+				return nil, err
+			}
+			if tbDesc == nil {
+				// Schema claims to have this table, but it does not exist.
+				return nil, errors.Errorf("table %q was described by schema %q, but does not exist",
+					tbNames[i].String(), tree.ErrString(&sn))
+			}
+			// Recursively check permissions on all dependent views, since some may
+			// be in different schemas.
+			for _, ref := range tbDesc.DependedOnBy {
+				if err := p.canRemoveDependentView(ctx, tbDesc, ref, tree.DropCascade); err != nil {
+					return nil, err
+				}
+			}
+			td = append(td, tbDesc)
+		}
+	}
+
+	td, err := p.filterCascadedTables(ctx, td)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dropSchemaNode{n: n, schemas: schemas, td: td}, nil
+}
+
+func (n *dropSchemaNode) Start(params runParams) error {
+	ctx := params.ctx
+	p := params.p
+	for _, tbDesc := range n.td {
+		if tbDesc.IsView() {
+			if _, err := p.dropViewImpl(ctx, tbDesc, tree.DropCascade); err != nil {
+				return err
+			}
+		} else {
+			if _, err := p.dropTableImpl(ctx, tbDesc); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, s := range n.schemas {
+		for _, id := range s.ids {
+			s.dbDesc.RemoveSchema(id)
+		}
+		if err := p.writeDatabaseDesc(ctx, s.dbDesc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropSchemaNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropSchemaNode) Close(context.Context)        {}
+func (*dropSchemaNode) Values() tree.Datums          { return tree.Datums{} }
+
+// RenameSchema renames a user-defined schema.
+// Privileges: DROP on schema, CREATE on database.
+//   Notes: postgres requires the schema owner and CREATE on the database.
+func (p *planner) RenameSchema(ctx context.Context, n *tree.RenameSchema) (planNode, error) {
+	if n.NewName == "" {
+		return nil, errEmptySchemaName
+	}
+	if err := checkSchemaName(string(n.NewName)); err != nil {
+		return nil, err
+	}
+
+	dbDesc, scDesc, err := p.resolveSchema(ctx, n.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if IsTempSchemaName(scDesc.Name) {
+		return nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"cannot rename temporary schemas")
+	}
+
+	if err := p.CheckPrivilege(scDesc, privilege.DROP); err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	if scDesc.Name == string(n.NewName) {
+		// Noop.
+		return &zeroNode{}, nil
+	}
+	if _, ok := dbDesc.FindSchema(string(n.NewName)); ok || n.NewName == tree.PublicSchemaName {
+		return nil, sqlbase.NewSchemaAlreadyExistsError(string(n.NewName))
+	}
+
+	// Views store their query as a string which names the tables they
+	// depend on. Rather than trying to rewrite them with the changed
+	// schema name, we simply disallow such renames for now.
+	tbNames, err := getSchemaTableNames(ctx, p.txn, dbDesc, scDesc, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tbNames {
+		tbDesc, err := getTableOrViewDesc(ctx, p.txn, p.getVirtualTabler(), &tbNames[i])
+		if err != nil {
+			return nil, err
+		}
+		if tbDesc != nil && len(tbDesc.DependedOnBy) > 0 {
+			return nil, sqlbase.NewDependentObjectError(fmt.Sprintf(
+				"cannot rename schema because a view depends on table %q", tbDesc.Name))
+		}
+	}
+
+	scDesc.Name = string(n.NewName)
+	if err := p.writeDatabaseDesc(ctx, dbDesc); err != nil {
+		return nil, err
+	}
+	return &zeroNode{}, nil
+}
+
+// changeSchemaPrivileges implements GRANT and REVOKE on schemas. The
+// privileges of a schema are stored in the descriptor of its database.
+func (p *planner) changeSchemaPrivileges(
+	ctx context.Context,
+	schemas tree.SchemaNames,
+	grantees tree.NameList,
+	changePrivilege func(*sqlbase.PrivilegeDescriptor, string),
+) (planNode, error) {
+	if len(schemas) == 0 {
+		return nil, errEmptySchemaName
+	}
+	dbDescs := make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
+	for _, sn := range schemas {
+		dbDesc, _, err := p.resolveSchema(ctx, sn)
+		if err != nil {
+			return nil, err
+		}
+		// Several schemas of the same database may be listed; they must
+		// all be modified in a single copy of the database descriptor.
+		if d, ok := dbDescs[dbDesc.ID]; ok {
+			dbDesc = d
+		} else {
+			dbDescs[dbDesc.ID] = dbDesc
+		}
+		scDesc, _ := dbDesc.FindSchema(sn.Schema())
+		if err := p.CheckPrivilege(scDesc, privilege.GRANT); err != nil {
+			return nil, err
+		}
+		for _, grantee := range grantees {
+			changePrivilege(scDesc.Privileges, string(grantee))
+		}
+	}
+	for _, dbDesc := range dbDescs {
+		if err := p.writeDatabaseDesc(ctx, dbDesc); err != nil {
+			return nil, err
+		}
+	}
+	return &zeroNode{}, nil
+}
@@ -100,9 +100,13 @@ var errExistingSchemaChangeLease = errors.New(
 	"an outstanding schema change lease exists")
 var errSchemaChangeNotFirstInLine = errors.New(
 	"schema change not first in line")
+var errNotHitGCTTLDeadline = errors.New(
+	"not hit gc ttl deadline")
 
 func shouldLogSchemaChangeError(err error) bool {
-	return err != errExistingSchemaChangeLease && err != errSchemaChangeNotFirstInLine
+	return err != errExistingSchemaChangeLease &&
+		err != errSchemaChangeNotFirstInLine &&
+		err != errNotHitGCTTLDeadline
 }
 
 // AcquireLease acquires a schema change lease on the table if
@@ -260,6 +264,23 @@ func DropTableDesc(
 	})
 }
 
+// truncateTable deletes all of the data of a dropped table. Unless the table
+// is interleaved, the data is cleared using a single ClearRange request over
+// the table's span, which avoids writing an MVCC tombstone per key. This is
+// only safe because no node can read or write the table any more, and its
+// GC TTL has elapsed.
+func (sc *SchemaChanger) truncateTable(ctx context.Context, table *sqlbase.TableDescriptor) error {
+	// The key span of an interleaved table contains the rows of other tables
+	// (or is empty, if the table's rows live in its parent's span), so its rows
+	// have to be deleted individually.
+	if table.IsInterleaved() || table.DropTime == 0 {
+		return truncateTableInChunks(ctx, table, &sc.db, false /* traceKV */)
+	}
+	tableSpan := table.TableSpan()
+	log.VEventf(ctx, 2, "ClearRange %s - %s", tableSpan.Key, tableSpan.EndKey)
+	return sc.db.ClearRange(ctx, tableSpan.Key, tableSpan.EndKey)
+}
+
 // maybe Add/Drop/Rename a table depending on the state of a table descriptor.
 // This method returns true if the table is deleted.
 func (sc *SchemaChanger) maybeAddDropRename(
@@ -287,8 +308,31 @@ func (sc *SchemaChanger) maybeAddDropRename(
 			return false, nil
 		}
 
+		// The table's data is only cleared once its GC TTL has elapsed, so
+		// that it can still be read at historical timestamps until then.
+		// Tables dropped before DropTime was introduced are cleared right
+		// away.
+		if table.DropTime != 0 && table.IsPhysicalTable() {
+			if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+				_, zoneCfg, _, err := GetZoneConfigInTxn(ctx, txn, uint32(table.ID), nil, "")
+				if err != nil {
+					return err
+				}
+				deadline := table.DropTime + int64(zoneCfg.GC.TTLSeconds)*time.Second.Nanoseconds()
+				if timeutil.Now().UnixNano() < deadline {
+					if fn := sc.testingKnobs.GCTTLDeadlineNotHitNotification; fn != nil {
+						fn(table.ID)
+					}
+					return errNotHitGCTTLDeadline
+				}
+				return nil
+			}); err != nil {
+				return false, err
+			}
+		}
+
 		// Do all the hard work of deleting the table data and the table ID.
-		if err := truncateTableInChunks(ctx, table, &sc.db, false /* traceKV */); err != nil {
+		if err := sc.truncateTable(ctx, table); err != nil {
 			return false, err
 		}
 
@@ -401,6 +445,12 @@ func (sc *SchemaChanger) exec(ctx context.Context, inSession bool, evalCtx tree.
 		return nil
 	}
 
+	if tableDesc.Refresh != nil && !tableDesc.Dropped() {
+		if err := sc.refreshMaterializedView(ctx, &lease, tableDesc); err != nil {
+			return err
+		}
+	}
+
 	// Wait for the schema change to propagate to all nodes after this function
 	// returns, so that the new schema is live everywhere. This is not needed for
 	// correctness but is done to make the UI experience/tests predictable.
@@ -869,6 +919,11 @@ type SchemaChangerTestingKnobs struct {
 	// backfill function passed into the transaction executing the chunk.
 	RunBeforeBackfillChunk func(sp roachpb.Span) error
 
+	// RunBeforeRefreshChunk is called before each chunk of the new results of
+	// a materialized view is written by a refresh. It is called at the start
+	// of the function passed into the transaction writing the chunk.
+	RunBeforeRefreshChunk func() error
+
 	// RunAfterBackfillChunk is called after executing each chunk of a
 	// backfill during a schema change operation. It is called just before
 	// returning from the backfill function passed into the transaction
@@ -890,6 +945,10 @@ type SchemaChangerTestingKnobs struct {
 	// AsyncExecQuickly executes queued schema changes as soon as possible.
 	AsyncExecQuickly bool
 
+	// GCTTLDeadlineNotHitNotification is called with the ID of a dropped
+	// table whose data is left in place because its GC TTL hasn't elapsed.
+	GCTTLDeadlineNotHitNotification func(sqlbase.ID)
+
 	// WriteCheckpointInterval is the interval after which a checkpoint is
 	// written.
 	WriteCheckpointInterval time.Duration
@@ -1031,9 +1090,10 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 						// check for the presence of mutations?
 						// A schema change execution might fail soon after
 						// unsetting UpVersion, and we still want to process
-						// outstanding mutations. Similar with a table marked for deletion.
+						// outstanding mutations. Similar with a table marked for deletion,
+						// or a materialized view being refreshed.
 						if table.UpVersion || table.Dropped() || table.Adding() ||
-							table.Renamed() || len(table.Mutations) > 0 {
+							table.Renamed() || len(table.Mutations) > 0 || table.Refresh != nil {
 							if log.V(2) {
 								log.Infof(ctx, "%s: queue up pending schema change; table: %d, version: %d",
 									kv.Key, table.ID, table.Version)
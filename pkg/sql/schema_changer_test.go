@@ -2506,8 +2506,10 @@ CREATE TABLE t.test (k INT PRIMARY KEY, v INT, pi DECIMAL REFERENCES t.pi (d) DE
 
 	tableDesc := sqlbase.GetTableDescriptor(kvDB, "t", "test")
 
-	// Add a zone config.
+	// Add a zone config. The zero GC TTL allows the truncated data to be
+	// deleted right away.
 	cfg := config.DefaultZoneConfig()
+	cfg.GC.TTLSeconds = 0
 	buf, err := protoutil.Marshal(&cfg)
 	if err != nil {
 		t.Fatal(err)
@@ -2689,3 +2691,64 @@ CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
 		t.Fatalf("columns %q, %q in descriptor", k, x)
 	}
 }
+
+// TestRefreshMaterializedViewChunks tests that a refresh writes the new
+// results of a materialized view in several transactions, and that the
+// previous results can be read until the refresh is done.
+func TestRefreshMaterializedViewChunks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	const chunkSize = 10
+	var sqlDB *gosql.DB
+	var chunks int64
+	var readErr error
+	params.Knobs = base.TestingKnobs{
+		SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
+			RunBeforeRefreshChunk: func() error {
+				if atomic.AddInt64(&chunks, 1) != 2 {
+					return nil
+				}
+				var count int
+				if err := sqlDB.QueryRow(`SELECT count(*) FROM t.mv`).Scan(&count); err != nil {
+					readErr = err
+				} else if count != 50 {
+					readErr = errors.Errorf("expected the previous 50 rows, got %d", count)
+				}
+				return nil
+			},
+			AsyncExecNotification: asyncSchemaChangerDisabled,
+			BackfillChunkSize:     chunkSize,
+		},
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	sqlDB = db
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY);
+INSERT INTO t.test SELECT generate_series(1, 50);
+CREATE MATERIALIZED VIEW t.mv AS SELECT k FROM t.test;
+INSERT INTO t.test SELECT generate_series(51, 100);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sqlDB.Exec(`REFRESH MATERIALIZED VIEW t.mv`); err != nil {
+		t.Fatal(err)
+	}
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if c := atomic.LoadInt64(&chunks); c != 100/chunkSize {
+		t.Fatalf("expected %d chunks, got %d", 100/chunkSize, c)
+	}
+
+	var count int
+	if err := sqlDB.QueryRow(`SELECT count(*) FROM t.mv`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 100 {
+		t.Fatalf("expected 100 rows, got %d", count)
+	}
+}
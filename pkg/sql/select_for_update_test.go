@@ -0,0 +1,114 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestSelectForUpdate verifies that the rows locked by SELECT ... FOR UPDATE
+// make concurrent writers wait until the locking transaction finishes, and
+// that the rows filtered out by the scan are not locked.
+func TestSelectForUpdate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, distSQLMode := range []DistSQLExecMode{DistSQLOff, DistSQLOn} {
+		t.Run(fmt.Sprintf("distsql=%s", distSQLMode), func(t *testing.T) {
+			testSelectForUpdate(t, distSQLMode)
+		})
+	}
+}
+
+func testSelectForUpdate(t *testing.T, distSQLMode DistSQLExecMode) {
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	st := s.ClusterSettings()
+	st.Manual.Store(true)
+	DistSQLClusterExecMode.Override(&st.SV, int64(distSQLMode))
+	// The table readers of a distributed locking scan lock the rows like
+	// distributed mutations write them.
+	distributeMutations.Override(&st.SV, true)
+
+	sqlDB := sqlutils.MakeSQLRunner(t, db)
+	sqlDB.Exec(`
+CREATE DATABASE d;
+CREATE TABLE d.t (k INT PRIMARY KEY, v INT);
+INSERT INTO d.t VALUES (1, 10), (2, 20), (3, 30);
+`)
+
+	txn, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The locking transaction can't be aborted by the writers below.
+	if _, err := txn.Exec(`SET TRANSACTION PRIORITY HIGH`); err != nil {
+		t.Fatal(err)
+	}
+	var k int
+	if err := txn.QueryRow(`SELECT k FROM d.t WHERE v = 10 FOR UPDATE`).Scan(&k); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rows that didn't pass the filter are not locked.
+	sqlDB.Exec(`UPDATE d.t SET v = 21 WHERE k = 2`)
+
+	// A NOWAIT lock on the locked row fails right away.
+	_, err = db.Exec(`SELECT * FROM d.t WHERE k = 1 FOR UPDATE NOWAIT`)
+	if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != pgerror.CodeLockNotAvailableError {
+		t.Fatalf("expected a lock not available error, got %v", err)
+	}
+
+	// A write to the locked row waits for the locking transaction.
+	updateDone := make(chan error, 1)
+	go func() {
+		_, err := db.Exec(`UPDATE d.t SET v = v * 10 WHERE k = 1`)
+		updateDone <- err
+	}()
+	select {
+	case err := <-updateDone:
+		t.Fatalf("update of a locked row didn't wait for the lock (err: %v)", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The locking transaction writes to the row and commits without having to
+	// retry.
+	if _, err := txn.Exec(`UPDATE d.t SET v = v + 1 WHERE k = 1`); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-updateDone; err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB.CheckQueryResults(`SELECT k, v FROM d.t`, [][]string{
+		{"1", "110"},
+		{"2", "21"},
+		{"3", "30"},
+	})
+}
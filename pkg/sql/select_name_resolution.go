@@ -39,6 +39,10 @@ type nameResolutionVisitor struct {
 	iVarHelper tree.IndexedVarHelper
 	searchPath tree.SearchPath
 
+	// scope, if non-nil, is used to resolve the names which are not found in
+	// sources when the expression is part of a subquery (see subqueryScope).
+	scope *subqueryScope
+
 	// foundDependentVars is set to true during the analysis if an
 	// expression was found which can change values between rows of the
 	// same data source, for example IndexedVars and calls to the
@@ -118,6 +122,18 @@ func (v *nameResolutionVisitor) VisitPre(expr tree.Expr) (recurse bool, newNode
 	case *tree.ColumnItem:
 		srcIdx, colIdx, err := v.sources.findColumn(t)
 		if err != nil {
+			if isUnknownNameError(err) {
+				// The name may refer to a column of an enclosing query.
+				outer, outerErr := v.scope.resolveColumn(t)
+				if outerErr != nil {
+					v.err = outerErr
+					return false, expr
+				}
+				if outer != nil {
+					v.foundDependentVars = true
+					return false, outer
+				}
+			}
 			v.err = err
 			return false, expr
 		}
@@ -221,6 +237,7 @@ func (p *planner) resolveNames(
 		sources:            sources,
 		iVarHelper:         ivarHelper,
 		searchPath:         p.session.SearchPath,
+		scope:              p.subqueryScope,
 		foundDependentVars: false,
 	}
 	colOffset := 0
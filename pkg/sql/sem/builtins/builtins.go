@@ -1283,6 +1283,63 @@ CockroachDB supports the following flags:
 		},
 	},
 
+	// timezone is the function form of the AT TIME ZONE operator.
+	// https://www.postgresql.org/docs/10/static/functions-datetime.html#functions-datetime-zoneconvert
+	"timezone": {
+		tree.Builtin{
+			Types:      tree.ArgTypes{{"timezone", types.String}, {"timestamp", types.Timestamp}},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Category:   categoryDateAndTime,
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				loc, err := timeZoneArgToLocation(args[0])
+				if err != nil {
+					return nil, err
+				}
+				ts := args[1].(*tree.DTimestamp).Time
+				t := time.Date(ts.Year(), ts.Month(), ts.Day(),
+					ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), loc)
+				return tree.MakeDTimestampTZ(t, time.Microsecond), nil
+			},
+			Info: "Treats `timestamp` as a wall clock time in `timezone` and " +
+				"returns the corresponding timestamptz.",
+		},
+		tree.Builtin{
+			Types:             tree.ArgTypes{{"timezone", types.String}, {"timestamptz", types.TimestampTZ}},
+			ReturnType:        tree.FixedReturnType(types.Timestamp),
+			Category:          categoryDateAndTime,
+			PreferredOverload: true,
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				loc, err := timeZoneArgToLocation(args[0])
+				if err != nil {
+					return nil, err
+				}
+				ts := args[1].(*tree.DTimestampTZ).Time.In(loc)
+				t := time.Date(ts.Year(), ts.Month(), ts.Day(),
+					ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), time.UTC)
+				return tree.MakeDTimestamp(t, time.Microsecond), nil
+			},
+			Info: "Returns the wall clock time in `timezone` of `timestamptz`.",
+		},
+		tree.Builtin{
+			Types:      tree.ArgTypes{{"timezone", types.String}, {"timetz", types.TimeTZ}},
+			ReturnType: tree.FixedReturnType(types.TimeTZ),
+			Category:   categoryDateAndTime,
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				loc, err := timeZoneArgToLocation(args[0])
+				if err != nil {
+					return nil, err
+				}
+				// The offset of a named time zone depends on the date, which a
+				// TIMETZ lacks. Like Postgres, use the offset in effect at the
+				// current transaction timestamp.
+				_, offset := ctx.GetTxnTimestamp(time.Microsecond).Time.In(loc).Zone()
+				t := args[1].(*tree.DTimeTZ).ToTime().In(time.FixedZone("", offset))
+				return tree.NewDTimeTZFromTime(t), nil
+			},
+			Info: "Converts `timetz` to the time zone `timezone`.",
+		},
+	},
+
 	// Math functions
 	"abs": {
 		floatBuiltin1(func(x float64) (tree.Datum, error) {
@@ -1674,9 +1731,44 @@ CockroachDB supports the following flags:
 				dimen := int64(tree.MustBeDInt(args[1]))
 				return arrayLength(arr, dimen), nil
 			},
-			Info: "Calculates the length of `input` on the provided `array_dimension`. However, " +
-				"because CockroachDB doesn't yet support multi-dimensional arrays, the only supported" +
-				" `array_dimension` is **1**.",
+			Info: "Calculates the length of `input` on the provided `array_dimension`.",
+		},
+	},
+
+	"array_ndims": {
+		tree.Builtin{
+			Types:      tree.ArgTypes{{"input", types.AnyArray}},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Category:   categoryArray,
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				arr := tree.MustBeDArray(args[0])
+				if arr.NumDims() == 0 {
+					return tree.DNull, nil
+				}
+				return tree.NewDInt(tree.DInt(arr.NumDims())), nil
+			},
+			Info: "Returns the number of dimensions of `input`.",
+		},
+	},
+
+	"array_dims": {
+		tree.Builtin{
+			Types:      tree.ArgTypes{{"input", types.AnyArray}},
+			ReturnType: tree.FixedReturnType(types.String),
+			Category:   categoryArray,
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				arr := tree.MustBeDArray(args[0])
+				if arr.NumDims() == 0 {
+					return tree.DNull, nil
+				}
+				var buf bytes.Buffer
+				for i := 0; i < arr.NumDims(); i++ {
+					lb := arr.LowerBound(i)
+					fmt.Fprintf(&buf, "[%d:%d]", lb, lb+arr.DimLen(i)-1)
+				}
+				return tree.NewDString(buf.String()), nil
+			},
+			Info: "Returns the bounds of each dimension of `input` as text, e.g. `[1:2][1:3]`.",
 		},
 	},
 
@@ -1690,9 +1782,7 @@ CockroachDB supports the following flags:
 				dimen := int64(tree.MustBeDInt(args[1]))
 				return arrayLower(arr, dimen), nil
 			},
-			Info: "Calculates the minimum value of `input` on the provided `array_dimension`. " +
-				"However, because CockroachDB doesn't yet support multi-dimensional arrays, the only " +
-				"supported `array_dimension` is **1**.",
+			Info: "Calculates the minimum value of `input` on the provided `array_dimension`.",
 		},
 	},
 
@@ -1704,11 +1794,9 @@ CockroachDB supports the following flags:
 			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
 				arr := tree.MustBeDArray(args[0])
 				dimen := int64(tree.MustBeDInt(args[1]))
-				return arrayLength(arr, dimen), nil
+				return arrayUpper(arr, dimen), nil
 			},
-			Info: "Calculates the maximum value of `input` on the provided `array_dimension`. " +
-				"However, because CockroachDB doesn't yet support multi-dimensional arrays, the only " +
-				"supported `array_dimension` is **1**.",
+			Info: "Calculates the maximum value of `input` on the provided `array_dimension`.",
 		},
 	},
 
@@ -1761,14 +1849,22 @@ CockroachDB supports the following flags:
 				if args[0] == tree.DNull {
 					return tree.DNull, nil
 				}
+				arr := tree.MustBeDArray(args[0])
+				if arr.NumDims() > 1 {
+					return nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+						"removing elements from multidimensional arrays is not supported")
+				}
 				result := tree.NewDArray(typ)
-				for _, e := range tree.MustBeDArray(args[0]).Array {
+				for _, e := range arr.Array {
 					if e.Compare(ctx, args[1]) != 0 {
 						if err := result.Append(e); err != nil {
 							return nil, err
 						}
 					}
 				}
+				if err := result.SetShape([]int{result.Len()}, arr.LowerBounds); err != nil {
+					return nil, err
+				}
 				return result, nil
 			},
 			Info: "Remove from `array` all elements equal to `elem`.",
@@ -1785,8 +1881,9 @@ CockroachDB supports the following flags:
 				if args[0] == tree.DNull {
 					return tree.DNull, nil
 				}
+				arr := tree.MustBeDArray(args[0])
 				result := tree.NewDArray(typ)
-				for _, e := range tree.MustBeDArray(args[0]).Array {
+				for _, e := range arr.Array {
 					if e.Compare(ctx, args[1]) == 0 {
 						if err := result.Append(args[2]); err != nil {
 							return nil, err
@@ -1797,6 +1894,9 @@ CockroachDB supports the following flags:
 						}
 					}
 				}
+				if err := result.SetShape(arr.Shape()); err != nil {
+					return nil, err
+				}
 				return result, nil
 			},
 			Info: "Replace all occurrences of `toreplace` in `array` with `replacewith`.",
@@ -1813,9 +1913,13 @@ CockroachDB supports the following flags:
 				if args[0] == tree.DNull {
 					return tree.DNull, nil
 				}
-				for i, e := range tree.MustBeDArray(args[0]).Array {
+				arr := tree.MustBeDArray(args[0])
+				if arr.NumDims() > 1 {
+					return nil, errSearchMultidimensionalArray
+				}
+				for i, e := range arr.Array {
 					if e.Compare(ctx, args[1]) == 0 {
-						return tree.NewDInt(tree.DInt(i + 1)), nil
+						return tree.NewDInt(tree.DInt(arr.LowerBound(0) + i)), nil
 					}
 				}
 				return tree.DNull, nil
@@ -1834,10 +1938,14 @@ CockroachDB supports the following flags:
 				if args[0] == tree.DNull {
 					return tree.DNull, nil
 				}
+				arr := tree.MustBeDArray(args[0])
+				if arr.NumDims() > 1 {
+					return nil, errSearchMultidimensionalArray
+				}
 				result := tree.NewDArray(types.Int)
-				for i, e := range tree.MustBeDArray(args[0]).Array {
+				for i, e := range arr.Array {
 					if e.Compare(ctx, args[1]) == 0 {
-						if err := result.Append(tree.NewDInt(tree.DInt(i + 1))); err != nil {
+						if err := result.Append(tree.NewDInt(tree.DInt(arr.LowerBound(0) + i))); err != nil {
 							return nil, err
 						}
 					}
@@ -2798,34 +2906,43 @@ func GenerateUniqueInt(nodeID roachpb.NodeID) tree.DInt {
 	return tree.DInt(id)
 }
 
-func arrayLength(arr *tree.DArray, dim int64) tree.Datum {
-	if arr.Len() == 0 || dim < 1 {
-		return tree.DNull
-	}
-	if dim == 1 {
-		return tree.NewDInt(tree.DInt(arr.Len()))
+var errSearchMultidimensionalArray = pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+	"searching for elements in multidimensional arrays is not supported")
+
+// arrayLength, arrayLower and arrayUpper take dimensions numbered from 1, as
+// in Postgres.
+// arrayDimension returns the index of the dimension of arr that corresponds
+// to dimension dim, where the outermost dimension is 1, or false if arr has
+// no such dimension.
+func arrayDimension(arr *tree.DArray, dim int64) (int, bool) {
+	if dim < 1 || dim > int64(arr.NumDims()) {
+		return 0, false
 	}
-	a, ok := tree.AsDArray(arr.Array[0])
+	return int(dim - 1), true
+}
+
+func arrayLength(arr *tree.DArray, dim int64) tree.Datum {
+	i, ok := arrayDimension(arr, dim)
 	if !ok {
 		return tree.DNull
 	}
-	return arrayLength(a, dim-1)
+	return tree.NewDInt(tree.DInt(arr.DimLen(i)))
 }
 
-var intOne = tree.NewDInt(tree.DInt(1))
-
 func arrayLower(arr *tree.DArray, dim int64) tree.Datum {
-	if arr.Len() == 0 || dim < 1 {
+	i, ok := arrayDimension(arr, dim)
+	if !ok {
 		return tree.DNull
 	}
-	if dim == 1 {
-		return intOne
-	}
-	a, ok := tree.AsDArray(arr.Array[0])
+	return tree.NewDInt(tree.DInt(arr.LowerBound(i)))
+}
+
+func arrayUpper(arr *tree.DArray, dim int64) tree.Datum {
+	i, ok := arrayDimension(arr, dim)
 	if !ok {
 		return tree.DNull
 	}
-	return arrayLower(a, dim-1)
+	return tree.NewDInt(tree.DInt(arr.LowerBound(i) + arr.DimLen(i) - 1))
 }
 
 func extractStringFromTimestamp(
@@ -2878,6 +2995,18 @@ func extractStringFromTimestamp(
 	}
 }
 
+// timeZoneArgToLocation returns the location named by a time zone argument to
+// the timezone builtin.
+func timeZoneArgToLocation(arg tree.Datum) (*time.Location, error) {
+	name := string(tree.MustBeDString(arg))
+	loc, err := timeutil.TimeZoneStringToLocation(name)
+	if err != nil {
+		return nil, pgerror.NewErrorf(
+			pgerror.CodeInvalidParameterValueError, "cannot find time zone %q: %v", name, err)
+	}
+	return loc, nil
+}
+
 func truncateTimestamp(
 	_ *tree.EvalContext, fromTime time.Time, timeSpan string,
 ) (tree.Datum, error) {
@@ -43,6 +43,8 @@ var typeBuiltinsHaveUnderscore = map[oid.Oid]struct{}{
 	types.UUID.Oid():        {},
 	types.Timestamp.Oid():   {},
 	types.TimestampTZ.Oid(): {},
+	types.Time.Oid():        {},
+	types.TimeTZ.Oid():      {},
 	types.FamTuple.Oid():    {},
 }
 
@@ -213,6 +213,20 @@ func mustParseDTimestampTZ(t *testing.T, s string) tree.Datum {
 	}
 	return d
 }
+func mustParseDTime(t *testing.T, s string) tree.Datum {
+	d, err := tree.ParseDTime(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+func mustParseDTimeTZ(t *testing.T, s string) tree.Datum {
+	d, err := tree.ParseDTimeTZ(s, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
 func mustParseDInterval(t *testing.T, s string) tree.Datum {
 	d, err := tree.ParseDInterval(s)
 	if err != nil {
@@ -235,6 +249,8 @@ var parseFuncs = map[types.T]func(*testing.T, string) tree.Datum{
 	types.Date:        mustParseDDate,
 	types.Timestamp:   mustParseDTimestamp,
 	types.TimestampTZ: mustParseDTimestampTZ,
+	types.Time:        mustParseDTime,
+	types.TimeTZ:      mustParseDTimeTZ,
 	types.Interval:    mustParseDInterval,
 	types.JSON:        mustParseDJSON,
 }
@@ -281,6 +297,10 @@ func TestStringConstantResolveAvailableTypes(t *testing.T) {
 			c:            tree.NewStrVal("PT12H2M"),
 			parseOptions: typeSet(types.String, types.Bytes, types.Interval),
 		},
+		{
+			c:            tree.NewStrVal("12:00:00.1"),
+			parseOptions: typeSet(types.String, types.Bytes, types.Time, types.TimeTZ, types.Interval),
+		},
 		{
 			c:            tree.NewBytesStrVal("abc 世界"),
 			parseOptions: typeSet(types.String, types.Bytes),
@@ -198,17 +198,19 @@ func TestDatumOrdering(t *testing.T) {
 		{`row(row(false), row(true))`, `((false), (false))`, `((true), NULL)`,
 			`((false), (false))`, `((true), (true))`},
 
-		// Arrays
+		// Arrays. Arrays with other dimensions or lower bounds sort between an
+		// array and the same array with an extra NULL element, so arrays have
+		// no successor.
 
-		{`'{}'::INT[]`, valIsMin, `ARRAY[NULL]`, `ARRAY[]`, noMax},
+		{`'{}'::INT[]`, valIsMin, noNext, `ARRAY[]`, noMax},
 
-		{`array[NULL]`, noPrev, `ARRAY[NULL,NULL]`, `ARRAY[]`, noMax},
-		{`array[true]`, noPrev, `ARRAY[true,NULL]`, `ARRAY[]`, noMax},
+		{`array[NULL]`, noPrev, noNext, `ARRAY[]`, noMax},
+		{`array[true]`, noPrev, noNext, `ARRAY[]`, noMax},
 
 		// Mixed tuple/array datums.
-		{`row(ARRAY[true], row(true))`, `(ARRAY[true], (false))`, `(ARRAY[true,NULL], NULL)`,
+		{`row(ARRAY[true], row(true))`, `(ARRAY[true], (false))`, noNext,
 			`(ARRAY[], (false))`, noMax},
-		{`row(row(false), ARRAY[true])`, noPrev, `((false), ARRAY[true,NULL])`,
+		{`row(row(false), ARRAY[true])`, noPrev, noNext,
 			`((false), ARRAY[])`, noMax},
 	}
 	ctx := tree.NewTestingEvalContext()
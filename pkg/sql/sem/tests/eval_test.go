@@ -513,7 +513,9 @@ func TestEval(t *testing.T) {
 		{`ARRAY[NULL]`, `ARRAY[NULL]`},
 		{`ARRAY[1, 2, 3]`, `ARRAY[1,2,3]`},
 		{`ARRAY['a', 'b', 'c']`, `ARRAY['a','b','c']`},
-		{`ARRAY[ARRAY[1, 2], ARRAY[2, 3]]`, `ARRAY[ARRAY[1,2],ARRAY[2,3]]`},
+		{`ARRAY[ARRAY[1, 2], ARRAY[2, 3]]`, `'{{1,2},{2,3}}'::INT[]`},
+		{`ARRAY[ARRAY[ARRAY['a']], ARRAY[ARRAY['b']]]`, `'{{{"a"}},{{"b"}}}'::STRING[]`},
+		{`ARRAY[NULL::INT[], ARRAY[]:::INT[]]`, `ARRAY[]`},
 		{`ARRAY[1, NULL]`, `ARRAY[1,NULL]`},
 		// Array sizes.
 		{`array_length(ARRAY[1, 2, 3], 1)`, `3`},
@@ -0,0 +1,58 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import (
+	"bytes"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+)
+
+// AlterTypeAddValue represents an ALTER TYPE ... ADD VALUE statement.
+type AlterTypeAddValue struct {
+	Name        Name
+	NewVal      string
+	IfNotExists bool
+	// Placement is nil if the value is added at the end of the type.
+	Placement *AlterTypeAddValuePlacement
+}
+
+// AlterTypeAddValuePlacement represents the placement clause of an ALTER
+// TYPE ... ADD VALUE statement.
+type AlterTypeAddValuePlacement struct {
+	// Before is true if the new value is placed before ExistingVal, false if
+	// it is placed after it.
+	Before      bool
+	ExistingVal string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeAddValue) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER TYPE ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" ADD VALUE ")
+	if node.IfNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	lex.EncodeSQLStringWithFlags(buf, node.NewVal, f.encodeFlags)
+	if node.Placement != nil {
+		if node.Placement.Before {
+			buf.WriteString(" BEFORE ")
+		} else {
+			buf.WriteString(" AFTER ")
+		}
+		lex.EncodeSQLStringWithFlags(buf, node.Placement.ExistingVal, f.encodeFlags)
+	}
+}
@@ -388,10 +388,13 @@ var (
 		types.Date,
 		types.Timestamp,
 		types.TimestampTZ,
+		types.Time,
+		types.TimeTZ,
 		types.Interval,
 		types.UUID,
 		types.INet,
 		types.JSON,
+		types.FamEnum,
 	}
 	// StrValAvailBytesString is the set of types convertible to either
 	// byte array or string.
@@ -446,6 +449,13 @@ func (expr *StrVal) DesirableTypes() []types.T {
 
 // ResolveAsType implements the Constant interface.
 func (expr *StrVal) ResolveAsType(ctx *SemaContext, typ types.T) (Datum, error) {
+	if enumTyp, ok := typ.(types.TEnum); ok {
+		if enumTyp.Values == nil {
+			// The string cannot be parsed as a value of the ENUM family.
+			return nil, makeParseError(expr.s, typ, nil)
+		}
+		return MakeDEnumFromLogicalRepresentation(enumTyp, expr.s)
+	}
 	switch typ {
 	case types.String:
 		expr.resString = DString(expr.s)
@@ -477,6 +487,10 @@ func (expr *StrVal) ResolveAsType(ctx *SemaContext, typ types.T) (Datum, error)
 		return ParseDTimestamp(expr.s, time.Microsecond)
 	case types.TimestampTZ:
 		return ParseDTimestampTZ(expr.s, ctx.getLocation(), time.Microsecond)
+	case types.Time:
+		return ParseDTime(expr.s)
+	case types.TimeTZ:
+		return ParseDTimeTZ(expr.s, ctx.getLocation())
 	case types.Interval:
 		return ParseDInterval(expr.s)
 	case types.UUID:
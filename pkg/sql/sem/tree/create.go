@@ -68,15 +68,58 @@ func (node *CreateDatabase) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// CreateSchema represents a CREATE SCHEMA statement.
+type CreateSchema struct {
+	IfNotExists bool
+	Schema      SchemaName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateSchema) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE SCHEMA ")
+	if node.IfNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	FormatNode(buf, f, &node.Schema)
+}
+
+// CreateType represents a CREATE TYPE ... AS ENUM statement.
+type CreateType struct {
+	Name       Name
+	EnumLabels []string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE TYPE ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" AS ENUM (")
+	for i, label := range node.EnumLabels {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		lex.EncodeSQLStringWithFlags(buf, label, f.encodeFlags)
+	}
+	buf.WriteByte(')')
+}
+
 // IndexElem represents a column with a direction in a CREATE INDEX statement.
 type IndexElem struct {
-	Column    Name
+	Column Name
+	// Expr, if set, is the expression indexed instead of a column.
+	Expr      Expr
 	Direction Direction
 }
 
 // Format implements the NodeFormatter interface.
 func (node IndexElem) Format(buf *bytes.Buffer, f FmtFlags) {
-	FormatNode(buf, f, node.Column)
+	if node.Expr != nil {
+		buf.WriteByte('(')
+		FormatNode(buf, f, node.Expr)
+		buf.WriteByte(')')
+	} else {
+		FormatNode(buf, f, node.Column)
+	}
 	if node.Direction != DefaultDirection {
 		buf.WriteByte(' ')
 		buf.WriteString(node.Direction.String())
@@ -108,6 +151,9 @@ type CreateIndex struct {
 	// for improved reading performance.
 	Storing    NameList
 	Interleave *InterleaveDef
+	// Predicate, if set, makes the index a partial index which only contains
+	// the rows which satisfy it.
+	Predicate Expr
 }
 
 // Format implements the NodeFormatter interface.
@@ -137,6 +183,10 @@ func (node *CreateIndex) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Interleave != nil {
 		FormatNode(buf, f, node.Interleave)
 	}
+	if node.Predicate != nil {
+		buf.WriteString(" WHERE ")
+		FormatNode(buf, f, node.Predicate)
+	}
 }
 
 // TableDef represents a column, index or constraint definition within a CREATE
@@ -196,6 +246,10 @@ type ColumnTableDef struct {
 		ConstraintName Name
 	}
 	CheckExprs []ColumnTableDefCheckExpr
+	Computed   struct {
+		Computed bool
+		Expr     Expr
+	}
 	References struct {
 		Table          NormalizableTableName
 		Col            Name
@@ -265,6 +319,13 @@ func NewColumnTableDef(
 			}
 			d.DefaultExpr.Expr = t.Expr
 			d.DefaultExpr.ConstraintName = c.Name
+		case *ColumnComputedDef:
+			if d.IsComputed() {
+				return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"multiple computed expressions specified for column %q", name)
+			}
+			d.Computed.Computed = true
+			d.Computed.Expr = t.Expr
 		case NotNullConstraint:
 			if d.Nullable.Nullability == Null {
 				return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
@@ -324,6 +385,11 @@ func (node *ColumnTableDef) HasDefaultExpr() bool {
 	return node.DefaultExpr.Expr != nil
 }
 
+// IsComputed returns if the ColumnTableDef is a computed column.
+func (node *ColumnTableDef) IsComputed() bool {
+	return node.Computed.Computed
+}
+
 // HasFKConstraint returns if the ColumnTableDef has a foreign key constraint.
 func (node *ColumnTableDef) HasFKConstraint() bool {
 	return node.References.Table.TableNameReference != nil
@@ -362,6 +428,11 @@ func (node *ColumnTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
 		buf.WriteString(" DEFAULT ")
 		FormatNode(buf, f, node.DefaultExpr.Expr)
 	}
+	if node.IsComputed() {
+		buf.WriteString(" AS (")
+		FormatNode(buf, f, node.Computed.Expr)
+		buf.WriteString(") STORED")
+	}
 	for _, checkExpr := range node.CheckExprs {
 		if checkExpr.ConstraintName != "" {
 			buf.WriteString(" CONSTRAINT ")
@@ -413,6 +484,7 @@ type ColumnQualification interface {
 
 func (ColumnCollation) columnQualification()         {}
 func (*ColumnDefault) columnQualification()          {}
+func (*ColumnComputedDef) columnQualification()      {}
 func (NotNullConstraint) columnQualification()       {}
 func (NullConstraint) columnQualification()          {}
 func (PrimaryKeyConstraint) columnQualification()    {}
@@ -429,6 +501,11 @@ type ColumnDefault struct {
 	Expr Expr
 }
 
+// ColumnComputedDef represents the description of a computed column.
+type ColumnComputedDef struct {
+	Expr Expr
+}
+
 // NotNullConstraint represents NOT NULL on a column.
 type NotNullConstraint struct{}
 
@@ -467,6 +544,9 @@ type IndexTableDef struct {
 	Columns    IndexElemList
 	Storing    NameList
 	Interleave *InterleaveDef
+	// Predicate, if set, makes the index a partial index which only contains
+	// the rows which satisfy it.
+	Predicate Expr
 }
 
 // SetName implements the TableDef interface.
@@ -492,6 +572,10 @@ func (node *IndexTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Interleave != nil {
 		FormatNode(buf, f, node.Interleave)
 	}
+	if node.Predicate != nil {
+		buf.WriteString(" WHERE ")
+		FormatNode(buf, f, node.Predicate)
+	}
 }
 
 // ConstraintTableDef represents a constraint definition within a CREATE TABLE
@@ -514,6 +598,12 @@ type UniqueConstraintTableDef struct {
 
 // Format implements the NodeFormatter interface.
 func (node *UniqueConstraintTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Predicate != nil {
+		// Partial unique indexes can't be declared as constraints.
+		buf.WriteString("UNIQUE ")
+		node.IndexTableDef.Format(buf, f)
+		return
+	}
 	if node.Name != "" {
 		buf.WriteString("CONSTRAINT ")
 		FormatNode(buf, f, node.Name)
@@ -780,12 +870,35 @@ func (node RangePartition) Format(buf *bytes.Buffer, f FmtFlags) {
 // CreateTable represents a CREATE TABLE statement.
 type CreateTable struct {
 	IfNotExists   bool
+	Temporary     bool
 	Table         NormalizableTableName
 	Interleave    *InterleaveDef
 	PartitionBy   *PartitionBy
 	Defs          TableDefs
 	AsSource      *Select
 	AsColumnNames NameList // Only to be used in conjunction with AsSource
+	OnCommit      OnCommitAction
+}
+
+// OnCommitAction represents the action taken on a temporary table at the
+// end of each transaction.
+type OnCommitAction int
+
+// OnCommitAction values.
+const (
+	OnCommitPreserveRows OnCommitAction = iota
+	OnCommitDeleteRows
+	OnCommitDrop
+)
+
+var onCommitActionName = [...]string{
+	OnCommitPreserveRows: "PRESERVE ROWS",
+	OnCommitDeleteRows:   "DELETE ROWS",
+	OnCommitDrop:         "DROP",
+}
+
+func (a OnCommitAction) String() string {
+	return onCommitActionName[a]
 }
 
 // As returns true if this table represents a CREATE TABLE ... AS statement,
@@ -796,7 +909,11 @@ func (node *CreateTable) As() bool {
 
 // Format implements the NodeFormatter interface.
 func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE TABLE ")
+	buf.WriteString("CREATE ")
+	if node.Temporary {
+		buf.WriteString("TEMPORARY ")
+	}
+	buf.WriteString("TABLE ")
 	if node.IfNotExists {
 		buf.WriteString("IF NOT EXISTS ")
 	}
@@ -807,6 +924,7 @@ func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
 			FormatNode(buf, f, node.AsColumnNames)
 			buf.WriteByte(')')
 		}
+		node.formatOnCommit(buf)
 		buf.WriteString(" AS ")
 		FormatNode(buf, f, node.AsSource)
 	} else {
@@ -819,6 +937,14 @@ func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
 		if node.PartitionBy != nil {
 			FormatNode(buf, f, node.PartitionBy)
 		}
+		node.formatOnCommit(buf)
+	}
+}
+
+func (node *CreateTable) formatOnCommit(buf *bytes.Buffer) {
+	if node.OnCommit != OnCommitPreserveRows {
+		buf.WriteString(" ON COMMIT ")
+		buf.WriteString(node.OnCommit.String())
 	}
 }
 
@@ -873,16 +999,21 @@ func (node *AlterUserSetPassword) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
-// CreateView represents a CREATE VIEW statement.
+// CreateView represents a CREATE [MATERIALIZED] VIEW statement.
 type CreateView struct {
-	Name        NormalizableTableName
-	ColumnNames NameList
-	AsSource    *Select
+	Name         NormalizableTableName
+	ColumnNames  NameList
+	AsSource     *Select
+	Materialized bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *CreateView) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE VIEW ")
+	buf.WriteString("CREATE ")
+	if node.Materialized {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
 	FormatNode(buf, f, &node.Name)
 
 	if len(node.ColumnNames) > 0 {
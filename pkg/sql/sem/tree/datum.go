@@ -44,6 +44,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/stringencoding"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
@@ -1301,6 +1302,132 @@ func (d *DIPAddr) Size() uintptr {
 	return unsafe.Sizeof(*d)
 }
 
+// DEnum is the Datum for values of user-defined ENUM types.
+type DEnum struct {
+	// EnumTyp is the type of the value.
+	EnumTyp types.TEnum
+	// PhysicalRep is the byte string representing the value in the encodings.
+	PhysicalRep []byte
+	// LogicalRep is the label of the value.
+	LogicalRep string
+}
+
+// NewDEnum returns the value of the given ENUM type at the given position.
+func NewDEnum(typ types.TEnum, idx int) *DEnum {
+	return &DEnum{
+		EnumTyp:     typ,
+		PhysicalRep: typ.Values.PhysicalReps[idx],
+		LogicalRep:  typ.Values.Labels[idx],
+	}
+}
+
+// MakeDEnumFromLogicalRepresentation returns the value of the given ENUM type
+// with the given label.
+func MakeDEnumFromLogicalRepresentation(typ types.TEnum, label string) (*DEnum, error) {
+	idx := typ.LabelIndex(label)
+	if idx < 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError,
+			"invalid input value for enum %s: %q", typ.Name, label)
+	}
+	if typ.IsAdding(label) {
+		return nil, pgerror.NewErrorf(pgerror.CodeUnsafeNewEnumValueUsageError,
+			"unsafe use of new value %q of enum type %s", label, typ.Name).SetHintf(
+			"New enum values must be committed before they can be used.")
+	}
+	return NewDEnum(typ, idx), nil
+}
+
+// MakeDEnumFromPhysicalRepresentation returns the value of the given ENUM
+// type with the given physical representation.
+func MakeDEnumFromPhysicalRepresentation(typ types.TEnum, rep []byte) (*DEnum, error) {
+	idx := typ.PhysicalRepIndex(rep)
+	if idx < 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
+			"invalid physical representation %x for enum %s", rep, typ.Name)
+	}
+	return NewDEnum(typ, idx), nil
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (d *DEnum) ResolvedType() types.T {
+	return d.EnumTyp
+}
+
+// Compare implements the Datum interface.
+func (d *DEnum) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DEnum)
+	if !ok || v.EnumTyp.ID != d.EnumTyp.ID {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return bytes.Compare(d.PhysicalRep, v.PhysicalRep)
+}
+
+func (d *DEnum) index() int {
+	return d.EnumTyp.PhysicalRepIndex(d.PhysicalRep)
+}
+
+// Prev implements the Datum interface.
+func (d *DEnum) Prev(_ *EvalContext) (Datum, bool) {
+	idx := d.index()
+	if idx <= 0 {
+		return nil, false
+	}
+	return NewDEnum(d.EnumTyp, idx-1), true
+}
+
+// Next implements the Datum interface.
+func (d *DEnum) Next(_ *EvalContext) (Datum, bool) {
+	idx := d.index()
+	if idx < 0 || idx == len(d.EnumTyp.Values.Labels)-1 {
+		return nil, false
+	}
+	return NewDEnum(d.EnumTyp, idx+1), true
+}
+
+// IsMax implements the Datum interface.
+func (d *DEnum) IsMax(_ *EvalContext) bool {
+	return d.index() == len(d.EnumTyp.Values.Labels)-1
+}
+
+// IsMin implements the Datum interface.
+func (d *DEnum) IsMin(_ *EvalContext) bool {
+	return d.index() == 0
+}
+
+// Min implements the Datum interface.
+func (d *DEnum) Min(_ *EvalContext) (Datum, bool) {
+	if len(d.EnumTyp.Values.Labels) == 0 {
+		return nil, false
+	}
+	return NewDEnum(d.EnumTyp, 0), true
+}
+
+// Max implements the Datum interface.
+func (d *DEnum) Max(_ *EvalContext) (Datum, bool) {
+	n := len(d.EnumTyp.Values.Labels)
+	if n == 0 {
+		return nil, false
+	}
+	return NewDEnum(d.EnumTyp, n-1), true
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DEnum) AmbiguousFormat() bool { return false }
+
+// Format implements the NodeFormatter interface.
+func (d *DEnum) Format(buf *bytes.Buffer, f FmtFlags) {
+	lex.EncodeSQLStringWithFlags(buf, d.LogicalRep, f.encodeFlags)
+}
+
+// Size implements the Datum interface.
+func (d *DEnum) Size() uintptr {
+	return unsafe.Sizeof(*d) + uintptr(len(d.PhysicalRep)) + uintptr(len(d.LogicalRep))
+}
+
 // DDate is the date Datum represented as the number of days after
 // the Unix epoch.
 type DDate int64
@@ -1740,6 +1867,269 @@ func (d *DTimestampTZ) Size() uintptr {
 	return unsafe.Sizeof(*d)
 }
 
+// DTime is the time Datum.
+type DTime timeofday.TimeOfDay
+
+// MakeDTime creates a DTime from a TimeOfDay.
+func MakeDTime(t timeofday.TimeOfDay) *DTime {
+	d := DTime(t)
+	return &d
+}
+
+// makeDTimestampFromDateAndTime combines a date and a time of day into a
+// DTimestamp.
+func makeDTimestampFromDateAndTime(d *DDate, t *DTime) *DTimestamp {
+	date := timeutil.Unix(int64(*d)*SecondsInDay, 0)
+	return MakeDTimestamp(date.Add(time.Duration(*t)*time.Microsecond), time.Microsecond)
+}
+
+// parseTimeInLocation parses a time of day, optionally followed by a zone
+// offset. A missing offset is resolved in the provided location.
+func parseTimeInLocation(s string, loc *time.Location, typ types.T) (time.Time, error) {
+	// Anchor the time of day on the Unix epoch so that the timestamp formats
+	// can be reused.
+	t, err := parseTimestampInLocation("1970-01-01 "+s, loc, typ)
+	if err != nil {
+		return time.Time{}, makeParseError(s, typ, nil)
+	}
+	return t, nil
+}
+
+// ParseDTime parses and returns the *DTime Datum value represented by the
+// provided string, or an error if parsing is unsuccessful. Any zone offset
+// in the string is ignored.
+func ParseDTime(s string) (*DTime, error) {
+	t, err := parseTimeInLocation(s, time.UTC, types.Time)
+	if err != nil {
+		return nil, err
+	}
+	return MakeDTime(timeofday.FromTime(t)), nil
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DTime) ResolvedType() types.T {
+	return types.Time
+}
+
+// Compare implements the Datum interface.
+func (d *DTime) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DTime)
+	if !ok {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	if *d < *v {
+		return -1
+	}
+	if *v < *d {
+		return 1
+	}
+	return 0
+}
+
+// Prev implements the Datum interface.
+func (d *DTime) Prev(_ *EvalContext) (Datum, bool) {
+	prev := *d - 1
+	return &prev, true
+}
+
+// Next implements the Datum interface.
+func (d *DTime) Next(_ *EvalContext) (Datum, bool) {
+	next := *d + 1
+	return &next, true
+}
+
+// IsMax implements the Datum interface.
+func (d *DTime) IsMax(_ *EvalContext) bool {
+	return *d == DTime(timeofday.Max)
+}
+
+// IsMin implements the Datum interface.
+func (d *DTime) IsMin(_ *EvalContext) bool {
+	return *d == DTime(timeofday.Min)
+}
+
+// Max implements the Datum interface.
+func (d *DTime) Max(_ *EvalContext) (Datum, bool) {
+	return MakeDTime(timeofday.Max), true
+}
+
+// Min implements the Datum interface.
+func (d *DTime) Min(_ *EvalContext) (Datum, bool) {
+	return MakeDTime(timeofday.Min), true
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DTime) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DTime) Format(buf *bytes.Buffer, f FmtFlags) {
+	if !f.encodeFlags.BareStrings {
+		buf.WriteByte('\'')
+	}
+	timeofday.TimeOfDay(*d).Format(buf)
+	if !f.encodeFlags.BareStrings {
+		buf.WriteByte('\'')
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DTime) Size() uintptr {
+	return unsafe.Sizeof(*d)
+}
+
+// DTimeTZ is the time with time zone Datum. Values are ordered by the UTC
+// time they represent and then, as in Postgres, by offset from east to west.
+type DTimeTZ struct {
+	timeofday.TimeOfDay
+	// OffsetSecs is the offset of the time zone, in seconds east of UTC.
+	OffsetSecs int32
+}
+
+// NewDTimeTZ creates a DTimeTZ from a TimeOfDay and a zone offset in seconds
+// east of UTC.
+func NewDTimeTZ(t timeofday.TimeOfDay, offsetSecs int32) *DTimeTZ {
+	return &DTimeTZ{TimeOfDay: t, OffsetSecs: offsetSecs}
+}
+
+// NewDTimeTZFromTime creates a DTimeTZ from the wall clock time and the zone
+// offset of a time.Time.
+func NewDTimeTZFromTime(t time.Time) *DTimeTZ {
+	_, offset := t.Zone()
+	return NewDTimeTZ(timeofday.FromTime(t), int32(offset))
+}
+
+// ParseDTimeTZ parses and returns the *DTimeTZ Datum value represented by the
+// provided string, or an error if parsing is unsuccessful. If the string
+// does not specify a zone offset, the offset of the provided location is
+// used.
+func ParseDTimeTZ(s string, loc *time.Location) (*DTimeTZ, error) {
+	t, err := parseTimeInLocation(s, loc, types.TimeTZ)
+	if err != nil {
+		return nil, err
+	}
+	return NewDTimeTZFromTime(t), nil
+}
+
+// ToTime converts a DTimeTZ to a time.Time on the Unix epoch, in a fixed zone
+// with the DTimeTZ's offset.
+func (d *DTimeTZ) ToTime() time.Time {
+	utc := d.TimeOfDay.ToTime().Add(-time.Duration(d.OffsetSecs) * time.Second)
+	return utc.In(time.FixedZone("", int(d.OffsetSecs)))
+}
+
+// utcMicros returns the time of day in UTC, in microseconds since midnight.
+// The result is not normalized to a single day.
+func (d *DTimeTZ) utcMicros() int64 {
+	return int64(d.TimeOfDay) - int64(d.OffsetSecs)*int64(time.Second/time.Microsecond)
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DTimeTZ) ResolvedType() types.T {
+	return types.TimeTZ
+}
+
+// Compare implements the Datum interface.
+func (d *DTimeTZ) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DTimeTZ)
+	if !ok {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	if l, r := d.utcMicros(), v.utcMicros(); l != r {
+		if l < r {
+			return -1
+		}
+		return 1
+	}
+	// Equal UTC times sort from the easternmost offset to the westernmost.
+	if d.OffsetSecs > v.OffsetSecs {
+		return -1
+	}
+	if d.OffsetSecs < v.OffsetSecs {
+		return 1
+	}
+	return 0
+}
+
+// Prev implements the Datum interface.
+func (d *DTimeTZ) Prev(_ *EvalContext) (Datum, bool) {
+	// Values with other offsets can sort between a value and the value one
+	// microsecond earlier with the same offset, so there is no well-defined
+	// predecessor.
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DTimeTZ) Next(_ *EvalContext) (Datum, bool) {
+	// See Prev.
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DTimeTZ) IsMax(_ *EvalContext) bool {
+	return false
+}
+
+// IsMin implements the Datum interface.
+func (d *DTimeTZ) IsMin(_ *EvalContext) bool {
+	return false
+}
+
+// Max implements the Datum interface.
+func (d *DTimeTZ) Max(_ *EvalContext) (Datum, bool) {
+	// The largest value depends on the range of offsets we accept, which is
+	// not bounded.
+	return nil, false
+}
+
+// Min implements the Datum interface.
+func (d *DTimeTZ) Min(_ *EvalContext) (Datum, bool) {
+	// See Max.
+	return nil, false
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DTimeTZ) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DTimeTZ) Format(buf *bytes.Buffer, f FmtFlags) {
+	if !f.encodeFlags.BareStrings {
+		buf.WriteByte('\'')
+	}
+	d.TimeOfDay.Format(buf)
+	// Offsets are printed as in Postgres: the hours are always present, the
+	// minutes and seconds only when they are non-zero.
+	offset := d.OffsetSecs
+	if offset < 0 {
+		buf.WriteByte('-')
+		offset = -offset
+	} else {
+		buf.WriteByte('+')
+	}
+	fmt.Fprintf(buf, "%02d", offset/3600)
+	if mins, secs := (offset/60)%60, offset%60; mins != 0 || secs != 0 {
+		fmt.Fprintf(buf, ":%02d", mins)
+		if secs != 0 {
+			fmt.Fprintf(buf, ":%02d", secs)
+		}
+	}
+	if !f.encodeFlags.BareStrings {
+		buf.WriteByte('\'')
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DTimeTZ) Size() uintptr {
+	return unsafe.Sizeof(*d)
+}
+
 // DInterval is the interval Datum.
 type DInterval struct {
 	duration.Duration
@@ -2375,6 +2765,14 @@ func (d dNull) Size() uintptr {
 type DArray struct {
 	ParamTyp types.T
 	Array    Datums
+	// Dims holds the length of each dimension of a multidimensional array,
+	// outermost dimension first, in which case Array holds the elements in
+	// row-major order. It is nil for empty and one-dimensional arrays.
+	Dims []int
+	// LowerBounds holds the subscript of the first element along each
+	// dimension. It is nil if every dimension starts at the default subscript
+	// of 1.
+	LowerBounds []int
 	// HasNulls is set to true if any of the datums within the array are null.
 	// This is used in the binary array serialization format.
 	HasNulls bool
@@ -2434,10 +2832,33 @@ func (d *DArray) Compare(ctx *EvalContext, other Datum) int {
 			return c
 		}
 	}
-	if d.Len() < v.Len() {
+	if c := compareInts(d.Len(), v.Len()); c != 0 {
+		return c
+	}
+	// As in Postgres, arrays with the same elements are ordered by their
+	// number of dimensions, then by the length of each dimension and finally
+	// by the lower bound of each dimension.
+	if c := compareInts(d.NumDims(), v.NumDims()); c != 0 {
+		return c
+	}
+	for i := 0; i < d.NumDims(); i++ {
+		if c := compareInts(d.DimLen(i), v.DimLen(i)); c != 0 {
+			return c
+		}
+	}
+	for i := 0; i < d.NumDims(); i++ {
+		if c := compareInts(d.LowerBound(i), v.LowerBound(i)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	if a < b {
 		return -1
 	}
-	if d.Len() > v.Len() {
+	if a > b {
 		return 1
 	}
 	return 0
@@ -2448,12 +2869,11 @@ func (d *DArray) Prev(_ *EvalContext) (Datum, bool) {
 	return nil, false
 }
 
-// Next implements the Datum interface.
+// Next implements the Datum interface. Arrays with other dimensions or lower
+// bounds can sort between an array and the array with an extra NULL element,
+// so there is no simple successor.
 func (d *DArray) Next(_ *EvalContext) (Datum, bool) {
-	a := DArray{ParamTyp: d.ParamTyp, Array: make(Datums, d.Len()+1)}
-	copy(a.Array, d.Array)
-	a.Array[len(a.Array)-1] = DNull
-	return &a, true
+	return nil, false
 }
 
 // Max implements the Datum interface.
@@ -2481,6 +2901,22 @@ func (*DArray) AmbiguousFormat() bool { return false }
 
 // Format implements the NodeFormatter interface.
 func (d *DArray) Format(buf *bytes.Buffer, f FmtFlags) {
+	if d.Dims != nil || d.LowerBounds != nil {
+		// The ARRAY constructor can't express these arrays, so they are
+		// formatted as a cast of their text representation instead.
+		var text bytes.Buffer
+		d.FormatText(&text)
+		lex.EncodeSQLStringWithFlags(buf, text.String(), f.encodeFlags)
+		if !f.encodeFlags.BareStrings {
+			colType, err := coltypes.DatumTypeToColumnType(d.ResolvedType())
+			if err != nil {
+				panic(err)
+			}
+			buf.WriteString("::")
+			colType.Format(buf, f.encodeFlags)
+		}
+		return
+	}
 	buf.WriteString("ARRAY[")
 	for i, v := range d.Array {
 		if i > 0 {
@@ -2491,6 +2927,55 @@ func (d *DArray) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteByte(']')
 }
 
+// FormatText writes the Postgres text representation of the array to buf,
+// e.g. {{1,2},{3,NULL}}. If any dimension has a lower bound other than 1, the
+// representation is prefixed with the bounds of each dimension, e.g.
+// [0:1]={1,2}.
+func (d *DArray) FormatText(buf *bytes.Buffer) {
+	if d.LowerBounds != nil {
+		for i := 0; i < d.NumDims(); i++ {
+			lb := d.LowerBound(i)
+			fmt.Fprintf(buf, "[%d:%d]", lb, lb+d.DimLen(i)-1)
+		}
+		buf.WriteByte('=')
+	}
+	d.formatTextDim(buf, 0, d.Array)
+}
+
+// formatTextDim writes the text representation of the sub-array made up of
+// elems, whose outermost dimension is dim.
+func (d *DArray) formatTextDim(buf *bytes.Buffer, dim int, elems Datums) {
+	buf.WriteByte('{')
+	if dim >= d.NumDims()-1 {
+		for i, e := range elems {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			FormatNode(buf, FmtArrays, e)
+		}
+	} else {
+		n := d.DimLen(dim)
+		stride := len(elems) / n
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			d.formatTextDim(buf, dim+1, elems[i*stride:(i+1)*stride])
+		}
+	}
+	buf.WriteByte('}')
+}
+
+// IsComposite implements the CompositeDatum interface.
+func (d *DArray) IsComposite() bool {
+	for _, e := range d.Array {
+		if cdatum, ok := e.(CompositeDatum); ok && cdatum.IsComposite() {
+			return true
+		}
+	}
+	return false
+}
+
 const maxArrayLength = math.MaxInt32
 
 var arrayTooLongError = pgerror.NewErrorf(
@@ -2510,9 +2995,104 @@ func (d *DArray) Len() int {
 	return len(d.Array)
 }
 
+// MaxArrayDims is the maximum number of dimensions of an array, as in
+// Postgres.
+const MaxArrayDims = 6
+
+// NumDims returns the number of dimensions of the array. Empty arrays have no
+// dimensions.
+func (d *DArray) NumDims() int {
+	if d.Len() == 0 {
+		return 0
+	}
+	if d.Dims == nil {
+		return 1
+	}
+	return len(d.Dims)
+}
+
+// DimLen returns the length of the array along dimension i, where the
+// outermost dimension is 0.
+func (d *DArray) DimLen(i int) int {
+	if d.Dims == nil {
+		return d.Len()
+	}
+	return d.Dims[i]
+}
+
+// LowerBound returns the subscript of the first element of the array along
+// dimension i, where the outermost dimension is 0.
+func (d *DArray) LowerBound(i int) int {
+	if d.LowerBounds == nil {
+		return 1
+	}
+	return d.LowerBounds[i]
+}
+
+// Shape returns the length and the lower bound of each dimension of the
+// array.
+func (d *DArray) Shape() (dims []int, lowerBounds []int) {
+	n := d.NumDims()
+	dims, lowerBounds = make([]int, n), make([]int, n)
+	for i := 0; i < n; i++ {
+		dims[i], lowerBounds[i] = d.DimLen(i), d.LowerBound(i)
+	}
+	return dims, lowerBounds
+}
+
+// SetShape sets the length and the lower bound of each dimension of the
+// array, whose elements must already be in place. A nil lowerBounds sets the
+// default lower bound of 1 for every dimension.
+func (d *DArray) SetShape(dims []int, lowerBounds []int) error {
+	if len(dims) > MaxArrayDims {
+		return pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"number of array dimensions (%d) exceeds the maximum allowed (%d)", len(dims), MaxArrayDims)
+	}
+	if lowerBounds != nil && len(lowerBounds) != len(dims) {
+		return pgerror.NewErrorf(pgerror.CodeInternalError,
+			"array has %d dimensions but %d lower bounds", len(dims), len(lowerBounds))
+	}
+	n := 0
+	if len(dims) > 0 {
+		n = 1
+	}
+	for _, l := range dims {
+		if l < 0 || (l > 0 && n > d.Len()/l) {
+			n = -1
+			break
+		}
+		n *= l
+	}
+	if n != d.Len() {
+		return pgerror.NewErrorf(pgerror.CodeArraySubscriptError,
+			"array dimensions %v do not match its %d elements", dims, d.Len())
+	}
+	for i, lb := range lowerBounds {
+		if lb < math.MinInt32 || lb+dims[i]-1 > math.MaxInt32 {
+			return pgerror.NewError(pgerror.CodeProgramLimitExceededError,
+				"array upper bound is too large")
+		}
+	}
+	d.Dims, d.LowerBounds = nil, nil
+	if n == 0 {
+		return nil
+	}
+	if len(dims) > 1 {
+		d.Dims = append([]int(nil), dims...)
+	}
+	for _, lb := range lowerBounds {
+		if lb != 1 {
+			d.LowerBounds = append([]int(nil), lowerBounds...)
+			break
+		}
+	}
+	return nil
+}
+
 // Size implements the Datum interface.
 func (d *DArray) Size() uintptr {
 	sz := unsafe.Sizeof(*d)
+	sz += uintptr(len(d.Dims)+len(d.LowerBounds)) * unsafe.Sizeof(int(0))
 	for _, e := range d.Array {
 		dsz := e.Size()
 		sz += dsz
@@ -2522,8 +3102,62 @@ func (d *DArray) Size() uintptr {
 
 var errNonHomogeneousArray = pgerror.NewError(pgerror.CodeArraySubscriptError, "multidimensional arrays must have array expressions with matching dimensions")
 
-// Append appends a Datum to the array, whose parameterized type must be
-// consistent with the type of the Datum.
+// NewMultidimensionalDArray returns the array with the given element type
+// made up of the given sub-arrays, which has one more dimension than them. As
+// in Postgres, the sub-arrays must have the same dimensions, NULL sub-arrays
+// are treated as empty arrays, and an array of empty sub-arrays is empty.
+func NewMultidimensionalDArray(paramTyp types.T, subArrays Datums) (*DArray, error) {
+	d := NewDArray(paramTyp)
+	var first *DArray
+	hasEmpty := false
+	for _, sub := range subArrays {
+		arr, ok := AsDArray(sub)
+		if !ok || arr.Len() == 0 {
+			hasEmpty = true
+			continue
+		}
+		if first == nil {
+			first = arr
+		} else if !first.sameShape(arr) {
+			return nil, errNonHomogeneousArray
+		}
+		if d.Len()+arr.Len() > maxArrayLength {
+			return nil, arrayTooLongError
+		}
+		d.Array = append(d.Array, arr.Array...)
+		d.HasNulls = d.HasNulls || arr.HasNulls
+	}
+	if first == nil {
+		return d, nil
+	}
+	if hasEmpty {
+		return nil, errNonHomogeneousArray
+	}
+	dims, lowerBounds := first.Shape()
+	dims = append([]int{len(subArrays)}, dims...)
+	lowerBounds = append([]int{1}, lowerBounds...)
+	if err := d.SetShape(dims, lowerBounds); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// sameShape returns whether the arrays have the same dimensions and lower
+// bounds.
+func (d *DArray) sameShape(other *DArray) bool {
+	if d.NumDims() != other.NumDims() {
+		return false
+	}
+	for i := 0; i < d.NumDims(); i++ {
+		if d.DimLen(i) != other.DimLen(i) || d.LowerBound(i) != other.LowerBound(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Append appends a Datum to a one-dimensional array, whose parameterized
+// type must be consistent with the type of the Datum.
 func (d *DArray) Append(v Datum) error {
 	if v != DNull && !d.ParamTyp.Equivalent(v.ResolvedType()) {
 		return pgerror.NewErrorf(
@@ -2958,6 +3592,9 @@ func DatumTypeSize(t types.T) (uintptr, bool) {
 	case types.TArray:
 		// TODO(jordan,justin): This seems suspicious.
 		return unsafe.Sizeof(DString("")), variableSize
+
+	case types.TEnum:
+		return unsafe.Sizeof(DEnum{}), variableSize
 	}
 
 	// All the primary types have fixed size information.
@@ -2987,6 +3624,8 @@ var baseDatumTypeSizes = map[types.T]struct {
 	types.Date:        {unsafe.Sizeof(DDate(0)), fixedSize},
 	types.Timestamp:   {unsafe.Sizeof(DTimestamp{}), fixedSize},
 	types.TimestampTZ: {unsafe.Sizeof(DTimestampTZ{}), fixedSize},
+	types.Time:        {unsafe.Sizeof(DTime(0)), fixedSize},
+	types.TimeTZ:      {unsafe.Sizeof(DTimeTZ{}), fixedSize},
 	types.Interval:    {unsafe.Sizeof(DInterval{}), fixedSize},
 	types.JSON:        {unsafe.Sizeof(DJSON{}), variableSize},
 	types.UUID:        {unsafe.Sizeof(DUuid{}), fixedSize},
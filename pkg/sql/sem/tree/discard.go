@@ -29,6 +29,8 @@ type DiscardMode int
 const (
 	// DiscardModeAll represents a DISCARD ALL statement.
 	DiscardModeAll DiscardMode = iota
+	// DiscardModeTemp represents a DISCARD TEMP statement.
+	DiscardModeTemp
 )
 
 // Format implements the NodeFormatter interface.
@@ -36,6 +38,8 @@ func (node *Discard) Format(buf *bytes.Buffer, f FmtFlags) {
 	switch node.Mode {
 	case DiscardModeAll:
 		buf.WriteString("DISCARD ALL")
+	case DiscardModeTemp:
+		buf.WriteString("DISCARD TEMPORARY")
 	}
 }
 
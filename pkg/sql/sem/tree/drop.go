@@ -62,6 +62,46 @@ func (node *DropDatabase) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// DropSchema represents a DROP SCHEMA statement.
+type DropSchema struct {
+	Names        SchemaNames
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropSchema) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP SCHEMA ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Names)
+	if node.DropBehavior != DropDefault {
+		buf.WriteByte(' ')
+		buf.WriteString(node.DropBehavior.String())
+	}
+}
+
+// DropType represents a DROP TYPE statement.
+type DropType struct {
+	Names        NameList
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP TYPE ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Names)
+	if node.DropBehavior != DropDefault {
+		buf.WriteByte(' ')
+		buf.WriteString(node.DropBehavior.String())
+	}
+}
+
 // DropIndex represents a DROP INDEX statement.
 type DropIndex struct {
 	IndexList    TableNameWithIndexList
@@ -102,16 +142,21 @@ func (node *DropTable) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
-// DropView represents a DROP VIEW statement.
+// DropView represents a DROP [MATERIALIZED] VIEW statement.
 type DropView struct {
 	Names        TableNameReferences
 	IfExists     bool
 	DropBehavior DropBehavior
+	Materialized bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *DropView) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("DROP VIEW ")
+	buf.WriteString("DROP ")
+	if node.Materialized {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
 	if node.IfExists {
 		buf.WriteString("IF EXISTS ")
 	}
@@ -39,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -202,16 +203,24 @@ func (BinOp) preferred() bool {
 	return false
 }
 
-// AppendToMaybeNullArray appends an element to an array. If the first
-// argument is NULL, an array of one element is created.
+var errNotOneDimensionalArray = pgerror.NewError(
+	pgerror.CodeDataExceptionError, "argument must be empty or one-dimensional array")
+
+// AppendToMaybeNullArray appends an element to an array, keeping its lower
+// bound. If the first argument is NULL, an array of one element is created.
 func AppendToMaybeNullArray(typ types.T, left Datum, right Datum) (Datum, error) {
 	result := NewDArray(typ)
 	if left != DNull {
-		for _, e := range MustBeDArray(left).Array {
+		arr := MustBeDArray(left)
+		if arr.NumDims() > 1 {
+			return nil, errNotOneDimensionalArray
+		}
+		for _, e := range arr.Array {
 			if err := result.Append(e); err != nil {
 				return nil, err
 			}
 		}
+		result.LowerBounds = arr.LowerBounds
 	}
 	if err := result.Append(right); err != nil {
 		return nil, err
@@ -219,19 +228,25 @@ func AppendToMaybeNullArray(typ types.T, left Datum, right Datum) (Datum, error)
 	return result, nil
 }
 
-// PrependToMaybeNullArray prepends an element in the front of an arrray.
-// If the argument is NULL, an array of one element is created.
+// PrependToMaybeNullArray prepends an element in the front of an arrray,
+// keeping its lower bound. If the argument is NULL, an array of one element
+// is created.
 func PrependToMaybeNullArray(typ types.T, left Datum, right Datum) (Datum, error) {
 	result := NewDArray(typ)
 	if err := result.Append(left); err != nil {
 		return nil, err
 	}
 	if right != DNull {
-		for _, e := range MustBeDArray(right).Array {
+		arr := MustBeDArray(right)
+		if arr.NumDims() > 1 {
+			return nil, errNotOneDimensionalArray
+		}
+		for _, e := range arr.Array {
 			if err := result.Append(e); err != nil {
 				return nil, err
 			}
 		}
+		result.LowerBounds = arr.LowerBounds
 	}
 	return result, nil
 }
@@ -265,29 +280,81 @@ func initArrayElementConcatenation() {
 	}
 }
 
-// ConcatArrays concatenates two arrays.
+// ConcatArrays concatenates two arrays. As in Postgres, arrays with the same
+// number of dimensions are concatenated along their outermost dimension,
+// while an array with one dimension less than the other is added to it as a
+// single element of its outermost dimension. The result keeps the lower
+// bounds of the left array, unless it only extends the right one.
 func ConcatArrays(typ types.T, left Datum, right Datum) (Datum, error) {
 	if left == DNull && right == DNull {
 		return DNull, nil
 	}
-	result := NewDArray(typ)
+	leftArr, rightArr := NewDArray(typ), NewDArray(typ)
 	if left != DNull {
-		for _, e := range MustBeDArray(left).Array {
-			if err := result.Append(e); err != nil {
-				return nil, err
-			}
-		}
+		leftArr = MustBeDArray(left)
 	}
 	if right != DNull {
-		for _, e := range MustBeDArray(right).Array {
-			if err := result.Append(e); err != nil {
-				return nil, err
-			}
+		rightArr = MustBeDArray(right)
+	}
+	dims, lowerBounds, err := concatArrayShapes(leftArr, rightArr)
+	if err != nil {
+		return nil, err
+	}
+	result := NewDArray(typ)
+	for _, e := range leftArr.Array {
+		if err := result.Append(e); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range rightArr.Array {
+		if err := result.Append(e); err != nil {
+			return nil, err
 		}
 	}
+	if err := result.SetShape(dims, lowerBounds); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
+var errIncompatibleArrays = pgerror.NewError(
+	pgerror.CodeArraySubscriptError, "cannot concatenate incompatible arrays")
+
+// concatArrayShapes returns the dimensions and lower bounds of the
+// concatenation of two arrays.
+func concatArrayShapes(left, right *DArray) (dims []int, lowerBounds []int, _ error) {
+	leftDims, leftLowerBounds := left.Shape()
+	rightDims, rightLowerBounds := right.Shape()
+	switch {
+	case len(leftDims) == 0:
+		return rightDims, rightLowerBounds, nil
+	case len(rightDims) == 0:
+		return leftDims, leftLowerBounds, nil
+	case len(leftDims) == len(rightDims) && intsEqual(leftDims[1:], rightDims[1:]):
+		dims = append([]int{leftDims[0] + rightDims[0]}, leftDims[1:]...)
+		return dims, leftLowerBounds, nil
+	case len(leftDims)+1 == len(rightDims) && intsEqual(leftDims, rightDims[1:]):
+		dims = append([]int{rightDims[0] + 1}, rightDims[1:]...)
+		return dims, rightLowerBounds, nil
+	case len(leftDims) == len(rightDims)+1 && intsEqual(leftDims[1:], rightDims):
+		dims = append([]int{leftDims[0] + 1}, leftDims[1:]...)
+		return dims, leftLowerBounds, nil
+	}
+	return nil, nil, errIncompatibleArrays
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func initArrayToArrayConcatenation() {
 	for _, t := range types.AnyNonArray {
 		typ := t
@@ -531,6 +598,58 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				return MakeDTimestampTZ(t, time.Microsecond), nil
 			},
 		},
+		BinOp{
+			LeftType:   types.Date,
+			RightType:  types.Time,
+			ReturnType: types.Timestamp,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return makeDTimestampFromDateAndTime(left.(*DDate), right.(*DTime)), nil
+			},
+		},
+		BinOp{
+			LeftType:   types.Time,
+			RightType:  types.Date,
+			ReturnType: types.Timestamp,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return makeDTimestampFromDateAndTime(right.(*DDate), left.(*DTime)), nil
+			},
+		},
+		BinOp{
+			LeftType:   types.Time,
+			RightType:  types.Interval,
+			ReturnType: types.Time,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := timeofday.TimeOfDay(*left.(*DTime))
+				return MakeDTime(t.Add(right.(*DInterval).Duration)), nil
+			},
+		},
+		BinOp{
+			LeftType:   types.Interval,
+			RightType:  types.Time,
+			ReturnType: types.Time,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := timeofday.TimeOfDay(*right.(*DTime))
+				return MakeDTime(t.Add(left.(*DInterval).Duration)), nil
+			},
+		},
+		BinOp{
+			LeftType:   types.TimeTZ,
+			RightType:  types.Interval,
+			ReturnType: types.TimeTZ,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := left.(*DTimeTZ)
+				return NewDTimeTZ(t.TimeOfDay.Add(right.(*DInterval).Duration), t.OffsetSecs), nil
+			},
+		},
+		BinOp{
+			LeftType:   types.Interval,
+			RightType:  types.TimeTZ,
+			ReturnType: types.TimeTZ,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := right.(*DTimeTZ)
+				return NewDTimeTZ(t.TimeOfDay.Add(left.(*DInterval).Duration), t.OffsetSecs), nil
+			},
+		},
 	},
 
 	Minus: {
@@ -674,6 +793,34 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				return MakeDTimestampTZ(t, time.Microsecond), nil
 			},
 		},
+		BinOp{
+			LeftType:   types.Time,
+			RightType:  types.Time,
+			ReturnType: types.Interval,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t1 := timeofday.TimeOfDay(*left.(*DTime))
+				t2 := timeofday.TimeOfDay(*right.(*DTime))
+				return &DInterval{Duration: timeofday.Difference(t1, t2)}, nil
+			},
+		},
+		BinOp{
+			LeftType:   types.Time,
+			RightType:  types.Interval,
+			ReturnType: types.Time,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := timeofday.TimeOfDay(*left.(*DTime))
+				return MakeDTime(t.Add(right.(*DInterval).Duration.Mul(-1))), nil
+			},
+		},
+		BinOp{
+			LeftType:   types.TimeTZ,
+			RightType:  types.Interval,
+			ReturnType: types.TimeTZ,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := left.(*DTimeTZ)
+				return NewDTimeTZ(t.TimeOfDay.Add(right.(*DInterval).Duration.Mul(-1)), t.OffsetSecs), nil
+			},
+		},
 		BinOp{
 			LeftType:   types.Interval,
 			RightType:  types.Interval,
@@ -1392,6 +1539,16 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: types.Date,
 			fn:        cmpOpScalarEQFn,
 		},
+		CmpOp{
+			LeftType:  types.Time,
+			RightType: types.Time,
+			fn:        cmpOpScalarEQFn,
+		},
+		CmpOp{
+			LeftType:  types.TimeTZ,
+			RightType: types.TimeTZ,
+			fn:        cmpOpScalarEQFn,
+		},
 		CmpOp{
 			LeftType:  types.Interval,
 			RightType: types.Interval,
@@ -1407,6 +1564,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: types.UUID,
 			fn:        cmpOpScalarEQFn,
 		},
+		CmpOp{
+			LeftType:  types.FamEnum,
+			RightType: types.FamEnum,
+			fn:        cmpOpScalarEQFn,
+		},
 		CmpOp{
 			LeftType:  types.INet,
 			RightType: types.INet,
@@ -1537,6 +1699,16 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: types.Date,
 			fn:        cmpOpScalarLTFn,
 		},
+		CmpOp{
+			LeftType:  types.Time,
+			RightType: types.Time,
+			fn:        cmpOpScalarLTFn,
+		},
+		CmpOp{
+			LeftType:  types.TimeTZ,
+			RightType: types.TimeTZ,
+			fn:        cmpOpScalarLTFn,
+		},
 		CmpOp{
 			LeftType:  types.Interval,
 			RightType: types.Interval,
@@ -1547,6 +1719,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: types.UUID,
 			fn:        cmpOpScalarLTFn,
 		},
+		CmpOp{
+			LeftType:  types.FamEnum,
+			RightType: types.FamEnum,
+			fn:        cmpOpScalarLTFn,
+		},
 		CmpOp{
 			LeftType:  types.INet,
 			RightType: types.INet,
@@ -1672,6 +1849,16 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: types.Date,
 			fn:        cmpOpScalarLEFn,
 		},
+		CmpOp{
+			LeftType:  types.Time,
+			RightType: types.Time,
+			fn:        cmpOpScalarLEFn,
+		},
+		CmpOp{
+			LeftType:  types.TimeTZ,
+			RightType: types.TimeTZ,
+			fn:        cmpOpScalarLEFn,
+		},
 		CmpOp{
 			LeftType:  types.Interval,
 			RightType: types.Interval,
@@ -1682,6 +1869,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: types.UUID,
 			fn:        cmpOpScalarLEFn,
 		},
+		CmpOp{
+			LeftType:  types.FamEnum,
+			RightType: types.FamEnum,
+			fn:        cmpOpScalarLEFn,
+		},
 		CmpOp{
 			LeftType:  types.INet,
 			RightType: types.INet,
@@ -1703,10 +1895,13 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeEvalTupleIn(types.Decimal),
 		makeEvalTupleIn(types.String),
 		makeEvalTupleIn(types.FamCollatedString),
+		makeEvalTupleIn(types.FamEnum),
 		makeEvalTupleIn(types.Bytes),
 		makeEvalTupleIn(types.Date),
 		makeEvalTupleIn(types.Timestamp),
 		makeEvalTupleIn(types.TimestampTZ),
+		makeEvalTupleIn(types.Time),
+		makeEvalTupleIn(types.TimeTZ),
 		makeEvalTupleIn(types.Interval),
 		makeEvalTupleIn(types.JSON),
 		makeEvalTupleIn(types.UUID),
@@ -2060,6 +2255,9 @@ type EvalContext struct {
 	// unqualified table name. Names in the search path are normalized already.
 	// This must not be modified (this is shared from the session).
 	SearchPath SearchPath
+	// Vectorize is set if the DistSQL processors should use the vectorized
+	// execution engine when they support it.
+	Vectorize bool
 
 	// Placeholders relates placeholder names to their type and, later, value.
 	// This pointer should always be set to the location of the PlaceholderInfo
@@ -2611,7 +2809,7 @@ func performCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 		switch t := d.(type) {
 		case *DBool, *DInt, *DFloat, *DDecimal, dNull:
 			s = d.String()
-		case *DTimestamp, *DTimestampTZ, *DDate:
+		case *DTimestamp, *DTimestampTZ, *DDate, *DTime, *DTimeTZ:
 			s = AsStringWithFlags(d, FmtBareStrings)
 		case *DInterval:
 			// When converting an interval to string, we need a string representation
@@ -2633,6 +2831,8 @@ func performCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 			s = buf.String()
 		case *DOid:
 			s = t.name
+		case *DEnum:
+			s = t.LogicalRep
 		}
 		switch c := t.(type) {
 		case *coltypes.TString:
@@ -2740,6 +2940,40 @@ func performCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 			return d, nil
 		}
 
+	case *coltypes.TTime:
+		switch d := d.(type) {
+		case *DString:
+			return ParseDTime(string(*d))
+		case *DCollatedString:
+			return ParseDTime(d.Contents)
+		case *DTime:
+			return d, nil
+		case *DTimeTZ:
+			return MakeDTime(d.TimeOfDay), nil
+		case *DTimestamp:
+			return MakeDTime(timeofday.FromTime(d.Time)), nil
+		case *DTimestampTZ:
+			return MakeDTime(timeofday.FromTime(d.Time.In(ctx.GetLocation()))), nil
+		case *DInterval:
+			return MakeDTime(timeofday.Min.Add(d.Duration)), nil
+		}
+
+	case *coltypes.TTimeTZ:
+		switch d := d.(type) {
+		case *DString:
+			return ParseDTimeTZ(string(*d), ctx.GetLocation())
+		case *DCollatedString:
+			return ParseDTimeTZ(d.Contents, ctx.GetLocation())
+		case *DTime:
+			// Like Postgres, use the current offset of the session time zone.
+			_, offset := timeutil.Now().In(ctx.GetLocation()).Zone()
+			return NewDTimeTZ(timeofday.TimeOfDay(*d), int32(offset)), nil
+		case *DTimeTZ:
+			return d, nil
+		case *DTimestampTZ:
+			return NewDTimeTZFromTime(d.Time.In(ctx.GetLocation())), nil
+		}
+
 	case *coltypes.TInterval:
 		// TODO(knz): Interval from float, decimal.
 		switch v := d.(type) {
@@ -2750,6 +2984,8 @@ func performCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 		case *DInt:
 			// An integer duration represents a duration in microseconds.
 			return &DInterval{Duration: duration.Duration{Nanos: int64(*v) * 1000}}, nil
+		case *DTime:
+			return &DInterval{Duration: timeofday.Difference(timeofday.TimeOfDay(*v), timeofday.Min)}, nil
 		case *DInterval:
 			return d, nil
 		}
@@ -2764,6 +3000,22 @@ func performCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 		if s, ok := d.(*DString); ok {
 			return ParseDArrayFromString(ctx, string(*s), typ.ParamType)
 		}
+	case *coltypes.TUserDefined:
+		enumTyp, ok := typ.Typ.(types.TEnum)
+		if !ok || enumTyp.Values == nil {
+			return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+				"type %q does not exist", typ.Name)
+		}
+		switch v := d.(type) {
+		case *DString:
+			return MakeDEnumFromLogicalRepresentation(enumTyp, string(*v))
+		case *DCollatedString:
+			return MakeDEnumFromLogicalRepresentation(enumTyp, v.Contents)
+		case *DEnum:
+			if v.EnumTyp.ID == enumTyp.ID {
+				return d, nil
+			}
+		}
 	case *coltypes.TOid:
 		switch v := d.(type) {
 		case *DOid:
@@ -2875,14 +3127,11 @@ func performCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 
 // Eval implements the TypedExpr interface.
 func (expr *IndirectionExpr) Eval(ctx *EvalContext) (Datum, error) {
-	var subscriptIdx int
+	subscripts := make([]int, len(expr.Indirection))
 	for i, t := range expr.Indirection {
 		if t.Slice {
 			return nil, pgerror.UnimplementedWithIssueErrorf(2115, "ARRAY slicing in %s", expr)
 		}
-		if i > 0 {
-			return nil, pgerror.UnimplementedWithIssueErrorf(2115, "multidimensional ARRAY %s", expr)
-		}
 
 		d, err := t.Begin.(TypedExpr).Eval(ctx)
 		if err != nil {
@@ -2891,7 +3140,7 @@ func (expr *IndirectionExpr) Eval(ctx *EvalContext) (Datum, error) {
 		if d == DNull {
 			return d, nil
 		}
-		subscriptIdx = int(MustBeDInt(d))
+		subscripts[i] = int(MustBeDInt(d))
 	}
 
 	d, err := expr.Expr.(TypedExpr).Eval(ctx)
@@ -2902,17 +3151,28 @@ func (expr *IndirectionExpr) Eval(ctx *EvalContext) (Datum, error) {
 		return d, nil
 	}
 
-	// Index into the DArray, using 1-indexing.
+	// Index into the DArray, relative to the lower bound of each dimension
+	// (which is 1 unless specified otherwise).
 	arr := MustBeDArray(d)
 
 	// INT2VECTOR uses 0-indexing.
 	if w, ok := d.(*DOidWrapper); ok && w.Oid == oid.T_int2vector {
-		subscriptIdx++
+		subscripts[0]++
 	}
-	if subscriptIdx < 1 || subscriptIdx > arr.Len() {
+	// As in Postgres, using the wrong number of subscripts or a subscript
+	// which is out of bounds yields NULL.
+	if len(subscripts) != arr.NumDims() {
 		return DNull, nil
 	}
-	return arr.Array[subscriptIdx-1], nil
+	offset := 0
+	for i, s := range subscripts {
+		idx := s - arr.LowerBound(i)
+		if idx < 0 || idx >= arr.DimLen(i) {
+			return DNull, nil
+		}
+		offset = offset*arr.DimLen(i) + idx
+	}
+	return arr.Array[offset], nil
 }
 
 // Eval implements the TypedExpr interface.
@@ -3211,6 +3471,16 @@ func (t *Array) Eval(ctx *EvalContext) (Datum, error) {
 		return nil, err
 	}
 
+	if t.isMultidimensional(array.ParamTyp) {
+		subArrays := make(Datums, len(t.Exprs))
+		for i, v := range t.Exprs {
+			if subArrays[i], err = v.(TypedExpr).Eval(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return NewMultidimensionalDArray(array.ParamTyp, subArrays)
+	}
+
 	for _, v := range t.Exprs {
 		d, err := v.(TypedExpr).Eval(ctx)
 		if err != nil {
@@ -3223,6 +3493,22 @@ func (t *Array) Eval(ctx *EvalContext) (Datum, error) {
 	return array, nil
 }
 
+// isMultidimensional returns whether the type-checked ARRAY constructor, whose
+// elements have the given type, builds a multidimensional array out of
+// sub-arrays. The right operand of ANY and ALL is typed as an array of
+// arrays instead, to be compared with each sub-array.
+func (t *Array) isMultidimensional(paramTyp types.T) bool {
+	if _, ok := paramTyp.(types.TArray); ok {
+		return false
+	}
+	for _, v := range t.Exprs {
+		if _, ok := v.(TypedExpr).ResolvedType().(types.TArray); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // Eval implements the TypedExpr interface.
 func (t *ArrayFlatten) Eval(ctx *EvalContext) (Datum, error) {
 	array, err := arrayOfType(t.ResolvedType())
@@ -3264,6 +3550,11 @@ func (t *DIPAddr) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DEnum) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t *DDate) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
@@ -3319,6 +3610,16 @@ func (t *DTimestampTZ) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DTime) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (t *DTimeTZ) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t *DTuple) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
@@ -26,6 +26,10 @@ type Explain struct {
 	// sql/explain.go for details.
 	Options []string
 
+	// Analyze is set for EXPLAIN ANALYZE, which executes the statement and
+	// reports the statistics collected during its execution.
+	Analyze bool
+
 	// Statement is the statement being EXPLAINed.
 	Statement Statement
 }
@@ -33,6 +37,9 @@ type Explain struct {
 // Format implements the NodeFormatter interface.
 func (node *Explain) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("EXPLAIN ")
+	if node.Analyze {
+		buf.WriteString("ANALYZE ")
+	}
 	if len(node.Options) > 0 {
 		buf.WriteByte('(')
 		for i, opt := range node.Options {
@@ -1124,16 +1124,20 @@ var (
 	decimalCastTypes = []types.T{types.Null, types.Bool, types.Int, types.Float, types.Decimal, types.String, types.FamCollatedString,
 		types.Timestamp, types.TimestampTZ, types.Date, types.Interval}
 	stringCastTypes = []types.T{types.Null, types.Bool, types.Int, types.Float, types.Decimal, types.String, types.FamCollatedString,
-		types.Bytes, types.Timestamp, types.TimestampTZ, types.Interval, types.UUID, types.Date, types.Oid, types.INet}
+		types.Bytes, types.Timestamp, types.TimestampTZ, types.Interval, types.UUID, types.Date, types.Oid, types.INet, types.FamEnum,
+		types.Time, types.TimeTZ}
 	bytesCastTypes     = []types.T{types.Null, types.String, types.FamCollatedString, types.Bytes, types.UUID}
 	dateCastTypes      = []types.T{types.Null, types.String, types.FamCollatedString, types.Date, types.Timestamp, types.TimestampTZ, types.Int}
 	timestampCastTypes = []types.T{types.Null, types.String, types.FamCollatedString, types.Date, types.Timestamp, types.TimestampTZ, types.Int}
-	intervalCastTypes  = []types.T{types.Null, types.String, types.FamCollatedString, types.Int, types.Interval}
+	timeCastTypes      = []types.T{types.Null, types.String, types.FamCollatedString, types.Time, types.TimeTZ, types.Timestamp, types.TimestampTZ, types.Interval}
+	timeTZCastTypes    = []types.T{types.Null, types.String, types.FamCollatedString, types.Time, types.TimeTZ, types.TimestampTZ}
+	intervalCastTypes  = []types.T{types.Null, types.String, types.FamCollatedString, types.Int, types.Time, types.Interval}
 	oidCastTypes       = []types.T{types.Null, types.String, types.FamCollatedString, types.Int, types.Oid}
 	uuidCastTypes      = []types.T{types.Null, types.String, types.FamCollatedString, types.Bytes, types.UUID}
 	inetCastTypes      = []types.T{types.Null, types.String, types.FamCollatedString, types.INet}
 	arrayCastTypes     = []types.T{types.Null, types.String}
 	jsonCastTypes      = []types.T{types.Null, types.String, types.JSON}
+	enumCastTypes      = []types.T{types.Null, types.String, types.FamCollatedString, types.FamEnum}
 )
 
 // validCastTypes returns a set of types that can be cast into the provided type.
@@ -1155,6 +1159,10 @@ func validCastTypes(t types.T) []types.T {
 		return dateCastTypes
 	case types.Timestamp, types.TimestampTZ:
 		return timestampCastTypes
+	case types.Time:
+		return timeCastTypes
+	case types.TimeTZ:
+		return timeTZCastTypes
 	case types.Interval:
 		return intervalCastTypes
 	case types.JSON:
@@ -1172,6 +1180,8 @@ func validCastTypes(t types.T) []types.T {
 			return stringCastTypes
 		} else if t.FamilyEqual(types.FamArray) {
 			return arrayCastTypes
+		} else if t.FamilyEqual(types.FamEnum) {
+			return enumCastTypes
 		}
 		return nil
 	}
@@ -1280,10 +1290,13 @@ func (node *DInterval) String() string        { return AsString(node) }
 func (node *DJSON) String() string            { return AsString(node) }
 func (node *DUuid) String() string            { return AsString(node) }
 func (node *DIPAddr) String() string          { return AsString(node) }
+func (node *DEnum) String() string            { return AsString(node) }
 func (node *DString) String() string          { return AsString(node) }
 func (node *DCollatedString) String() string  { return AsString(node) }
 func (node *DTimestamp) String() string       { return AsString(node) }
 func (node *DTimestampTZ) String() string     { return AsString(node) }
+func (node *DTime) String() string            { return AsString(node) }
+func (node *DTimeTZ) String() string          { return AsString(node) }
 func (node *DTuple) String() string           { return AsString(node) }
 func (node *DArray) String() string           { return AsString(node) }
 func (node *DTable) String() string           { return AsString(node) }
@@ -37,6 +37,7 @@ type Grant struct {
 // Only one field may be non-nil.
 type TargetList struct {
 	Databases NameList
+	Schemas   SchemaNames
 	Tables    TablePatterns
 }
 
@@ -45,6 +46,9 @@ func (tl TargetList) Format(buf *bytes.Buffer, f FmtFlags) {
 	if tl.Databases != nil {
 		buf.WriteString("DATABASE ")
 		FormatNode(buf, f, tl.Databases)
+	} else if tl.Schemas != nil {
+		buf.WriteString("SCHEMA ")
+		FormatNode(buf, f, tl.Schemas)
 	} else {
 		FormatNode(buf, f, tl.Tables)
 	}
@@ -16,6 +16,7 @@ package tree
 
 import (
 	"bytes"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -26,8 +27,10 @@ import (
 
 var enclosingError = pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError, "array must be enclosed in { and }")
 var extraTextError = pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError, "extra text after closing right brace")
-var nestedArraysNotSupportedError = pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError, "nested arrays not supported")
 var malformedError = pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError, "malformed array")
+var nonRectangularError = pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError, "multidimensional arrays must have sub-arrays with matching dimensions")
+var dimensionMismatchError = pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError, "specified array dimensions do not match array contents")
+var boundsOrderError = pgerror.NewErrorf(pgerror.CodeArraySubscriptError, "upper bound cannot be less than lower bound")
 
 var isQuoteChar = func(ch byte) bool {
 	return ch == '"'
@@ -77,6 +80,12 @@ type parseState struct {
 	evalCtx *EvalContext
 	result  *DArray
 	t       coltypes.T
+	// dims holds the length of each dimension of the array, or -1 for a
+	// dimension none of whose sub-arrays have been closed yet.
+	dims []int
+	// leafDepth is the nesting depth at which elements have been found, or -1
+	// if no element has been found yet.
+	leafDepth int
 }
 
 func (p *parseState) advance() {
@@ -111,13 +120,121 @@ func (p *parseState) parseUnquotedString() (string, error) {
 	return strings.TrimSpace(out), nil
 }
 
-func (p *parseState) parseElement() error {
+// parseArray parses a brace-enclosed array, or a brace-enclosed sub-array
+// found at the given nesting depth of a multidimensional array. The elements
+// are appended to the result in row-major order.
+func (p *parseState) parseArray(depth int) error {
+	if depth >= MaxArrayDims {
+		return pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"number of array dimensions (%d) exceeds the maximum allowed (%d)", depth+1, MaxArrayDims)
+	}
+	// Skip the opening brace.
+	p.advance()
+	p.eatWhitespace()
+	n := 0
+	if p.peek() != '}' {
+		for {
+			var err error
+			if p.peek() == '{' {
+				err = p.parseArray(depth + 1)
+			} else {
+				err = p.parseElement(depth)
+			}
+			if err != nil {
+				return err
+			}
+			n++
+			p.eatWhitespace()
+			if p.peek() != ',' {
+				break
+			}
+			p.advance()
+			p.eatWhitespace()
+		}
+	}
+	if p.eof() {
+		return enclosingError
+	}
+	if p.peek() != '}' {
+		return malformedError
+	}
+	p.advance()
+	// All the sub-arrays at the same depth must have the same length.
+	for len(p.dims) <= depth {
+		p.dims = append(p.dims, -1)
+	}
+	if p.dims[depth] == -1 {
+		p.dims[depth] = n
+	} else if p.dims[depth] != n {
+		return nonRectangularError
+	}
+	return nil
+}
+
+// parseDimensions parses the optional decoration which specifies the bounds
+// of each dimension of the array, e.g. [0:1][1:2]=, returning the lower and
+// upper bound of each dimension. A bound written without a lower bound, e.g.
+// [2], has the default lower bound of 1.
+func (p *parseState) parseDimensions() (lowerBounds, upperBounds []int, err error) {
+	for p.peek() == '[' {
+		p.advance()
+		lb, ub := 1, 0
+		if ub, err = p.parseBound(); err != nil {
+			return nil, nil, err
+		}
+		if p.peek() == ':' {
+			p.advance()
+			lb = ub
+			if ub, err = p.parseBound(); err != nil {
+				return nil, nil, err
+			}
+		}
+		if p.peek() != ']' {
+			return nil, nil, malformedError
+		}
+		p.advance()
+		if ub < lb {
+			return nil, nil, boundsOrderError
+		}
+		lowerBounds = append(lowerBounds, lb)
+		upperBounds = append(upperBounds, ub)
+		p.eatWhitespace()
+	}
+	if lowerBounds != nil {
+		if p.peek() != '=' {
+			return nil, nil, malformedError
+		}
+		p.advance()
+		p.eatWhitespace()
+	}
+	return lowerBounds, upperBounds, nil
+}
+
+func (p *parseState) parseBound() (int, error) {
+	p.eatWhitespace()
+	i := 0
+	for i < len(p.s) && (p.s[i] >= '0' && p.s[i] <= '9' || (i == 0 && (p.s[i] == '-' || p.s[i] == '+'))) {
+		i++
+	}
+	n, err := strconv.ParseInt(p.s[:i], 10, 32)
+	if err != nil {
+		return 0, malformedError
+	}
+	p.s = p.s[i:]
+	p.eatWhitespace()
+	return int(n), nil
+}
+
+func (p *parseState) parseElement(depth int) error {
+	if p.leafDepth == -1 {
+		p.leafDepth = depth
+	} else if p.leafDepth != depth {
+		return nonRectangularError
+	}
 	var next string
 	var err error
 	r := p.peek()
 	switch r {
-	case '{':
-		return nestedArraysNotSupportedError
 	case '"':
 		p.advance()
 		next, err = p.parseQuotedString()
@@ -161,6 +278,10 @@ func StringToColType(s string) (coltypes.T, error) {
 		return coltypes.Timestamp, nil
 	case "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE":
 		return coltypes.TimestampWithTZ, nil
+	case "TIME":
+		return coltypes.Time, nil
+	case "TIMETZ", "TIME WITH TIME ZONE":
+		return coltypes.TimeWithTZ, nil
 	case "INTERVAL":
 		return coltypes.Interval, nil
 	case "UUID":
@@ -181,46 +302,53 @@ func StringToColType(s string) (coltypes.T, error) {
 }
 
 // ParseDArrayFromString parses the string-form of constructing arrays, handling
-// cases such as `'{1,2,3}'::INT[]`.
+// cases such as `'{1,2,3}'::INT[]`, `'{{1,2},{3,4}}'::INT[]` and
+// `'[0:1]={1,2}'::INT[]`.
 func ParseDArrayFromString(evalCtx *EvalContext, s string, t coltypes.T) (*DArray, error) {
 	parser := parseState{
-		s:       s,
-		evalCtx: evalCtx,
-		result:  NewDArray(coltypes.CastTargetToDatumType(t)),
-		t:       t,
+		s:         s,
+		evalCtx:   evalCtx,
+		result:    NewDArray(coltypes.CastTargetToDatumType(t)),
+		t:         t,
+		leafDepth: -1,
 	}
 
 	parser.eatWhitespace()
-	if parser.peek() != '{' {
-		return nil, enclosingError
-	}
-	parser.advance()
-	parser.eatWhitespace()
-	if parser.peek() != '}' {
-		if err := parser.parseElement(); err != nil {
-			return nil, err
-		}
-		parser.eatWhitespace()
-		for parser.peek() == ',' {
-			parser.advance()
-			parser.eatWhitespace()
-			if err := parser.parseElement(); err != nil {
-				return nil, err
-			}
-		}
+	lowerBounds, upperBounds, err := parser.parseDimensions()
+	if err != nil {
+		return nil, err
 	}
-	parser.eatWhitespace()
-	if parser.eof() {
+	if parser.peek() != '{' {
 		return nil, enclosingError
 	}
-	if parser.peek() != '}' {
-		return nil, malformedError
+	if err := parser.parseArray(0); err != nil {
+		return nil, err
 	}
-	parser.advance()
 	parser.eatWhitespace()
 	if !parser.eof() {
 		return nil, extraTextError
 	}
 
+	// An array made up only of empty sub-arrays is empty.
+	var dims []int
+	if parser.leafDepth != -1 {
+		if len(parser.dims) != parser.leafDepth+1 {
+			return nil, nonRectangularError
+		}
+		dims = parser.dims
+	}
+	if lowerBounds != nil {
+		if len(lowerBounds) != len(dims) {
+			return nil, dimensionMismatchError
+		}
+		for i := range dims {
+			if upperBounds[i]-lowerBounds[i]+1 != dims[i] {
+				return nil, dimensionMismatchError
+			}
+		}
+	}
+	if err := parser.result.SetShape(dims, lowerBounds); err != nil {
+		return nil, err
+	}
 	return parser.result, nil
 }
@@ -17,6 +17,7 @@ package tree
 import (
 	"bytes"
 	"math/rand"
+	"reflect"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
@@ -99,6 +100,42 @@ lo}`, coltypes.String, Datums{NewDString(`hel`), NewDString(`lo`)}},
 	}
 }
 
+func TestParseArrayDimensions(t *testing.T) {
+	testData := []struct {
+		str         string
+		dims        []int
+		lowerBounds []int
+		expected    string
+	}{
+		{`{{1,2},{3,4}}`, []int{2, 2}, []int{1, 1}, `{{1,2},{3,4}}`},
+		{` { { 1 } , { NULL } } `, []int{2, 1}, []int{1, 1}, `{{1},{NULL}}`},
+		{`{{{1,2,3}},{{4,5,6}}}`, []int{2, 1, 3}, []int{1, 1, 1}, `{{{1,2,3}},{{4,5,6}}}`},
+		{`{{},{}}`, []int{}, []int{}, `{}`},
+		{`[0:2]={1,2,3}`, []int{3}, []int{0}, `[0:2]={1,2,3}`},
+		{`[3]={1,2,3}`, []int{3}, []int{1}, `{1,2,3}`},
+		{` [ 1 : 2 ] = {1,2}`, []int{2}, []int{1}, `{1,2}`},
+		{`[1:1][-1:0]={{1,2}}`, []int{1, 2}, []int{1, -1}, `[1:1][-1:0]={{1,2}}`},
+	}
+	for _, td := range testData {
+		t.Run(td.str, func(t *testing.T) {
+			actual, err := ParseDArrayFromString(NewTestingEvalContext(), td.str, coltypes.Int)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dims, lowerBounds := actual.Shape()
+			if !reflect.DeepEqual(dims, td.dims) || !reflect.DeepEqual(lowerBounds, td.lowerBounds) {
+				t.Fatalf("expected dimensions %v and lower bounds %v, got %v and %v",
+					td.dims, td.lowerBounds, dims, lowerBounds)
+			}
+			var buf bytes.Buffer
+			actual.FormatText(&buf)
+			if buf.String() != td.expected {
+				t.Fatalf("expected %s, got %s", td.expected, buf.String())
+			}
+		})
+	}
+}
+
 const randomArrayIterations = 10000
 const randomArrayMaxLength = 10
 const randomStringMaxLength = 1000
@@ -165,8 +202,17 @@ func TestParseArrayError(t *testing.T) {
 		{`{,}`, coltypes.Int, "malformed array"},
 		{`{}{}`, coltypes.Int, "extra text after closing right brace"},
 		{`{} {}`, coltypes.Int, "extra text after closing right brace"},
-		{`{{}}`, coltypes.Int, "nested arrays not supported"},
-		{`{1, {1}}`, coltypes.Int, "nested arrays not supported"},
+		{`{1, {1}}`, coltypes.Int, "multidimensional arrays must have sub-arrays with matching dimensions"},
+		{`{{1}, 1}`, coltypes.Int, "multidimensional arrays must have sub-arrays with matching dimensions"},
+		{`{{1,2},{3}}`, coltypes.Int, "multidimensional arrays must have sub-arrays with matching dimensions"},
+		{`{{1},{}}`, coltypes.Int, "multidimensional arrays must have sub-arrays with matching dimensions"},
+		{`{{{{{{{1}}}}}}}`, coltypes.Int, "number of array dimensions (7) exceeds the maximum allowed (6)"},
+		{`[1:2]={1}`, coltypes.Int, "specified array dimensions do not match array contents"},
+		{`[1:1]={{1}}`, coltypes.Int, "specified array dimensions do not match array contents"},
+		{`[1:1]={}`, coltypes.Int, "specified array dimensions do not match array contents"},
+		{`[2:1]={1}`, coltypes.Int, "upper bound cannot be less than lower bound"},
+		{`[1:1]{1}`, coltypes.Int, "malformed array"},
+		{`[a]={1}`, coltypes.Int, "malformed array"},
 		{`{hello}`, coltypes.Int, `could not parse "hello" as type int: strconv.ParseInt: parsing "hello": invalid syntax`},
 		{`{"hello}`, coltypes.String, `malformed array`},
 		// It might be unnecessary to disallow this, but Postgres does.
@@ -36,6 +36,20 @@ func (node *RenameDatabase) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.NewName)
 }
 
+// RenameSchema represents an ALTER SCHEMA ... RENAME TO statement.
+type RenameSchema struct {
+	Schema  SchemaName
+	NewName Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RenameSchema) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER SCHEMA ")
+	FormatNode(buf, f, &node.Schema)
+	buf.WriteString(" RENAME TO ")
+	FormatNode(buf, f, node.NewName)
+}
+
 // RenameTable represents a RENAME TABLE or RENAME VIEW statement.
 // Whether the user has asked to rename a table or view is indicated
 // by the IsView field.
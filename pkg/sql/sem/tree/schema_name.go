@@ -0,0 +1,67 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "bytes"
+
+// SchemaName is the name of a user-defined schema, used in statements like
+// CREATE SCHEMA, DROP SCHEMA, etc.
+// General syntax:
+//    [ <database-name> '.' ] <schema-name>
+type SchemaName struct {
+	// DatabaseName is empty if the database was not specified, in which
+	// case the schema belongs to the session database.
+	DatabaseName Name
+	SchemaName   Name
+}
+
+// Format implements the NodeFormatter interface.
+func (n *SchemaName) Format(buf *bytes.Buffer, f FmtFlags) {
+	if n.DatabaseName != "" {
+		FormatNode(buf, f, n.DatabaseName)
+		buf.WriteByte('.')
+	}
+	FormatNode(buf, f, n.SchemaName)
+}
+func (n *SchemaName) String() string { return AsString(n) }
+
+// Database returns the name of the database containing the schema, using
+// the provided session database if the name does not specify one.
+func (n *SchemaName) Database(sessionDatabase string) string {
+	if n.DatabaseName != "" {
+		return string(n.DatabaseName)
+	}
+	return sessionDatabase
+}
+
+// Schema retrieves the unqualified schema name.
+func (n *SchemaName) Schema() string {
+	return string(n.SchemaName)
+}
+
+// SchemaNames represents a comma separated list (see the Format method)
+// of schema names.
+type SchemaNames []SchemaName
+
+// Format implements the NodeFormatter interface.
+func (ns SchemaNames) Format(buf *bytes.Buffer, f FmtFlags) {
+	for i := range ns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, &ns[i])
+	}
+}
+func (ns SchemaNames) String() string { return AsString(ns) }
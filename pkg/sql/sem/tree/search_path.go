@@ -21,6 +21,10 @@ import (
 // PgCatalogName is the name of the pg_catalog system database.
 const PgCatalogName = "pg_catalog"
 
+// PublicSchemaName is the name of the implicit schema of every database,
+// which contains the tables that do not belong to a user-defined schema.
+const PublicSchemaName = "public"
+
 // SearchPath represents a list of namespaces to search builtins in.
 // The names must be normalized (as per Name.Normalize) already.
 type SearchPath struct {
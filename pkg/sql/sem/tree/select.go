@@ -38,10 +38,10 @@ func (*ValuesClause) selectStatement() {}
 
 // Select represents a SelectStatement with an ORDER and/or LIMIT.
 type Select struct {
-	Select        SelectStatement
-	OrderBy       OrderBy
-	Limit         *Limit
-	LockForUpdate bool
+	Select  SelectStatement
+	OrderBy OrderBy
+	Limit   *Limit
+	Locking LockingClause
 }
 
 // Format implements the NodeFormatter interface.
@@ -49,8 +49,89 @@ func (node *Select) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.Select)
 	FormatNode(buf, f, node.OrderBy)
 	FormatNode(buf, f, node.Limit)
-	if node.LockForUpdate {
-		buf.WriteString(" FOR UPDATE")
+	FormatNode(buf, f, &node.Locking)
+}
+
+// LockingStrength represents the strength of the row-level locks requested
+// by a SELECT's locking clause. Strengths are ordered from weakest to
+// strongest.
+type LockingStrength byte
+
+// The row-level lock strengths, see
+// https://www.postgresql.org/docs/10/static/explicit-locking.html#LOCKING-ROWS
+const (
+	// ForNone indicates that no locking clause was specified.
+	ForNone LockingStrength = iota
+	// ForKeyShare is FOR KEY SHARE.
+	ForKeyShare
+	// ForShare is FOR SHARE.
+	ForShare
+	// ForNoKeyUpdate is FOR NO KEY UPDATE.
+	ForNoKeyUpdate
+	// ForUpdate is FOR UPDATE.
+	ForUpdate
+)
+
+var lockingStrengthName = [...]string{
+	ForNone:        "",
+	ForKeyShare:    "FOR KEY SHARE",
+	ForShare:       "FOR SHARE",
+	ForNoKeyUpdate: "FOR NO KEY UPDATE",
+	ForUpdate:      "FOR UPDATE",
+}
+
+func (s LockingStrength) String() string {
+	return lockingStrengthName[s]
+}
+
+// IsExclusive returns whether the strength requires exclusive locks on the
+// selected rows, i.e. whether it conflicts with other locking readers.
+func (s LockingStrength) IsExclusive() bool {
+	return s >= ForNoKeyUpdate
+}
+
+// LockingWaitPolicy represents how a locking SELECT behaves when it
+// encounters a row locked by another transaction.
+type LockingWaitPolicy byte
+
+const (
+	// LockWaitBlock waits for conflicting locks to be released.
+	LockWaitBlock LockingWaitPolicy = iota
+	// LockWaitSkip skips rows that are locked (SKIP LOCKED).
+	LockWaitSkip
+	// LockWaitError returns an error upon encountering a locked row
+	// (NOWAIT).
+	LockWaitError
+)
+
+var lockingWaitPolicyName = [...]string{
+	LockWaitBlock: "",
+	LockWaitSkip:  "SKIP LOCKED",
+	LockWaitError: "NOWAIT",
+}
+
+func (p LockingWaitPolicy) String() string {
+	return lockingWaitPolicyName[p]
+}
+
+// LockingClause represents a locking clause such as FOR UPDATE or
+// FOR SHARE NOWAIT. The zero value indicates the absence of a locking
+// clause.
+type LockingClause struct {
+	Strength   LockingStrength
+	WaitPolicy LockingWaitPolicy
+}
+
+// Format implements the NodeFormatter interface.
+func (node *LockingClause) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Strength == ForNone {
+		return
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(node.Strength.String())
+	if node.WaitPolicy != LockWaitBlock {
+		buf.WriteByte(' ')
+		buf.WriteString(node.WaitPolicy.String())
 	}
 }
 
@@ -117,6 +117,12 @@ func (*AlterTable) StatementTag() string { return "ALTER TABLE" }
 
 func (*AlterTable) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*AlterTypeAddValue) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterTypeAddValue) StatementTag() string { return "ALTER TYPE" }
+
 // StatementType implements the Statement interface.
 func (*AlterUserSetPassword) StatementType() StatementType { return RowsAffected }
 
@@ -179,6 +185,18 @@ func (*CreateIndex) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*CreateIndex) StatementTag() string { return "CREATE INDEX" }
 
+// StatementType implements the Statement interface.
+func (*CreateSchema) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateSchema) StatementTag() string { return "CREATE SCHEMA" }
+
+// StatementType implements the Statement interface.
+func (*CreateType) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateType) StatementTag() string { return "CREATE TYPE" }
+
 // StatementType implements the Statement interface.
 func (*CreateTable) StatementType() StatementType { return DDL }
 
@@ -202,7 +220,12 @@ func (*CreateUser) hiddenFromShowQueries() {}
 func (*CreateView) StatementType() StatementType { return DDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*CreateView) StatementTag() string { return "CREATE VIEW" }
+func (n *CreateView) StatementTag() string {
+	if n.Materialized {
+		return "CREATE MATERIALIZED VIEW"
+	}
+	return "CREATE VIEW"
+}
 
 // StatementType implements the Statement interface.
 func (*Deallocate) StatementType() StatementType { return Ack }
@@ -242,6 +265,18 @@ func (*DropIndex) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*DropIndex) StatementTag() string { return "DROP INDEX" }
 
+// StatementType implements the Statement interface.
+func (*DropSchema) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropSchema) StatementTag() string { return "DROP SCHEMA" }
+
+// StatementType implements the Statement interface.
+func (*DropType) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropType) StatementTag() string { return "DROP TYPE" }
+
 // StatementType implements the Statement interface.
 func (*DropTable) StatementType() StatementType { return DDL }
 
@@ -252,7 +287,12 @@ func (*DropTable) StatementTag() string { return "DROP TABLE" }
 func (*DropView) StatementType() StatementType { return DDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*DropView) StatementTag() string { return "DROP VIEW" }
+func (n *DropView) StatementTag() string {
+	if n.Materialized {
+		return "DROP MATERIALIZED VIEW"
+	}
+	return "DROP VIEW"
+}
 
 // StatementType implements the Statement interface.
 func (*DropUser) StatementType() StatementType { return RowsAffected }
@@ -314,6 +354,12 @@ func (*Prepare) StatementTag() string { return "PREPARE" }
 
 func (*Prepare) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*RefreshMaterializedView) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*RefreshMaterializedView) StatementTag() string { return "REFRESH MATERIALIZED VIEW" }
+
 // StatementType implements the Statement interface.
 func (*ReleaseSavepoint) StatementType() StatementType { return Ack }
 
@@ -340,6 +386,12 @@ func (*RenameIndex) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*RenameIndex) StatementTag() string { return "RENAME INDEX" }
 
+// StatementType implements the Statement interface.
+func (*RenameSchema) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*RenameSchema) StatementTag() string { return "RENAME SCHEMA" }
+
 // StatementType implements the Statement interface.
 func (*RenameTable) StatementType() StatementType { return DDL }
 
@@ -673,6 +725,7 @@ func (n *AlterTableDropColumn) String() string     { return AsString(n) }
 func (n *AlterTableDropConstraint) String() string { return AsString(n) }
 func (n *AlterTableDropNotNull) String() string    { return AsString(n) }
 func (n *AlterTableSetDefault) String() string     { return AsString(n) }
+func (n *AlterTypeAddValue) String() string        { return AsString(n) }
 func (n *AlterUserSetPassword) String() string     { return AsString(n) }
 func (n *Backup) String() string                   { return AsString(n) }
 func (n *BeginTransaction) String() string         { return AsString(n) }
@@ -682,14 +735,18 @@ func (n *CommitTransaction) String() string        { return AsString(n) }
 func (n *CopyFrom) String() string                 { return AsString(n) }
 func (n *CreateDatabase) String() string           { return AsString(n) }
 func (n *CreateIndex) String() string              { return AsString(n) }
+func (n *CreateSchema) String() string             { return AsString(n) }
 func (n *CreateTable) String() string              { return AsString(n) }
+func (n *CreateType) String() string               { return AsString(n) }
 func (n *CreateUser) String() string               { return AsString(n) }
 func (n *CreateView) String() string               { return AsString(n) }
 func (n *Deallocate) String() string               { return AsString(n) }
 func (n *Delete) String() string                   { return AsString(n) }
 func (n *DropDatabase) String() string             { return AsString(n) }
 func (n *DropIndex) String() string                { return AsString(n) }
+func (n *DropSchema) String() string               { return AsString(n) }
 func (n *DropTable) String() string                { return AsString(n) }
+func (n *DropType) String() string                 { return AsString(n) }
 func (n *DropView) String() string                 { return AsString(n) }
 func (n *DropUser) String() string                 { return AsString(n) }
 func (n *Execute) String() string                  { return AsString(n) }
@@ -700,11 +757,13 @@ func (n *Import) String() string                   { return AsString(n) }
 func (n *ParenSelect) String() string              { return AsString(n) }
 func (n *PauseJob) String() string                 { return AsString(n) }
 func (n *Prepare) String() string                  { return AsString(n) }
+func (n *RefreshMaterializedView) String() string  { return AsString(n) }
 func (n *ReleaseSavepoint) String() string         { return AsString(n) }
 func (n *TestingRelocate) String() string          { return AsString(n) }
 func (n *RenameColumn) String() string             { return AsString(n) }
 func (n *RenameDatabase) String() string           { return AsString(n) }
 func (n *RenameIndex) String() string              { return AsString(n) }
+func (n *RenameSchema) String() string             { return AsString(n) }
 func (n *RenameTable) String() string              { return AsString(n) }
 func (n *Restore) String() string                  { return AsString(n) }
 func (n *ResumeJob) String() string                { return AsString(n) }
@@ -24,7 +24,7 @@ import (
 // Table names are used in statements like CREATE TABLE,
 // INSERT INTO, etc.
 // General syntax:
-//    [ <database-name> '.' ] <table-name>
+//    [ <database-name> '.' ] [ <schema-name> '.' ] <table-name>
 //
 // The other syntax nodes hold a mutable NormalizableTableName
 // attribute.  This is populated during parsing with an
@@ -98,7 +98,12 @@ type TableNameReference interface {
 type TableName struct {
 	PrefixName   Name
 	DatabaseName Name
-	TableName    Name
+	// SchemaName is the name of the user-defined schema containing the
+	// table, if any. It is only populated once the name has been resolved
+	// against the schemas of the database, as the parser cannot tell
+	// apart a schema-qualified name from a database-qualified one.
+	SchemaName Name
+	TableName  Name
 
 	// DBNameOriginallyOmitted, when set to true, causes the
 	// String()/Format() methods to omit the database name even if one
@@ -110,6 +115,10 @@ type TableName struct {
 	// PrefixOriginallySpecified indicates whether a prefix was
 	// explicitly indicated in the input syntax.
 	PrefixOriginallySpecified bool
+
+	// sessionDatabase is the database the name was qualified with by
+	// QualifyWithDatabase, if any. See SessionDatabase().
+	sessionDatabase Name
 }
 
 // Format implements the NodeFormatter interface.
@@ -122,6 +131,10 @@ func (t *TableName) Format(buf *bytes.Buffer, f FmtFlags) {
 		FormatNode(buf, f, t.DatabaseName)
 		buf.WriteByte('.')
 	}
+	if t.SchemaName != "" {
+		FormatNode(buf, f, t.SchemaName)
+		buf.WriteByte('.')
+	}
 	FormatNode(buf, f, t.TableName)
 }
 func (t *TableName) String() string { return AsString(t) }
@@ -139,6 +152,20 @@ func (t *TableName) Database() string {
 	return string(t.DatabaseName)
 }
 
+// Schema retrieves the name of the user-defined schema containing the
+// table, or the empty string if the table is in the public schema.
+func (t *TableName) Schema() string {
+	return string(t.SchemaName)
+}
+
+// SessionDatabase returns the session database that the name was
+// qualified with, even if the name was already qualified. A two-part name
+// whose first part does not designate a database is looked up as
+// <schema>.<table> in this database.
+func (t *TableName) SessionDatabase() string {
+	return string(t.sessionDatabase)
+}
+
 // NewInvalidNameErrorf initializes an error carrying the pg code CodeInvalidNameError.
 func NewInvalidNameErrorf(fmt string, args ...interface{}) error {
 	return pgerror.NewErrorf(pgerror.CodeInvalidNameError, fmt, args...)
@@ -204,6 +231,7 @@ func (n UnresolvedName) NormalizeTableName() (*TableName, error) {
 // table       -> database.table
 // table@index -> database.table@index
 func (t *TableName) QualifyWithDatabase(database string) error {
+	t.sessionDatabase = Name(database)
 	if !t.DBNameOriginallyOmitted {
 		return nil
 	}
@@ -173,19 +173,16 @@ func (node *RollbackTransaction) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("ROLLBACK TRANSACTION")
 }
 
-// RestartSavepointName is the only savepoint name that we accept, modulo
-// capitalization.
+// RestartSavepointName is the name of the savepoint used to declare the
+// intention to retry a transaction, modulo capitalization.
 const RestartSavepointName string = "COCKROACH_RESTART"
 
-// ValidateRestartCheckpoint checks that a checkpoint name is our magic restart
-// value.
+// IsRestartSavepoint returns true if a savepoint name designates our magic
+// restart savepoint.
 // We accept everything with the desired prefix because at least the C++ libpqxx
 // appends sequence numbers to the savepoint name specified by the user.
-func ValidateRestartCheckpoint(savepoint string) error {
-	if !strings.HasPrefix(strings.ToUpper(savepoint), RestartSavepointName) {
-		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError, "SAVEPOINT not supported except for %s", RestartSavepointName)
-	}
-	return nil
+func IsRestartSavepoint(savepoint string) bool {
+	return strings.HasPrefix(strings.ToUpper(savepoint), RestartSavepointName)
 }
 
 // Savepoint represents a SAVEPOINT <name> statement.
@@ -46,6 +46,17 @@ type SemaContext struct {
 	// the root user.
 	// TODO(knz): this attribute can be moved to EvalContext pending #15363.
 	privileged bool
+
+	// TypeResolver resolves the names of user-defined types. If nil, no
+	// user-defined type can be used.
+	TypeResolver TypeResolver
+}
+
+// TypeResolver is the interface used to resolve the names of user-defined
+// types during semantic analysis.
+type TypeResolver interface {
+	// ResolveTypeName returns the type with the given name.
+	ResolveTypeName(name string) (types.T, error)
 }
 
 // MakeSemaContext initializes a simple SemaContext suitable
@@ -67,6 +78,30 @@ func (sc *SemaContext) isUnresolvedPlaceholder(expr Expr) bool {
 	return sc.Placeholders.IsUnresolvedPlaceholder(expr)
 }
 
+// ResolveType resolves the type named by a cast target, if it is a
+// user-defined type. The resolution is performed every time the expression is
+// type checked, because the values of a type can change between executions of
+// a prepared statement.
+func (sc *SemaContext) ResolveType(t coltypes.CastTargetType) error {
+	ud, ok := t.(*coltypes.TUserDefined)
+	if !ok {
+		return nil
+	}
+	if sc == nil || sc.TypeResolver == nil {
+		if ud.Typ != nil {
+			return nil
+		}
+		return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"type %q does not exist", ud.Name)
+	}
+	typ, err := sc.TypeResolver.ResolveTypeName(ud.Name)
+	if err != nil {
+		return err
+	}
+	ud.Typ = typ
+	return nil
+}
+
 // GetLocation returns the session timezone.
 func (sc *SemaContext) getLocation() *time.Location {
 	if sc == nil || sc.Location == nil || *sc.Location == nil {
@@ -263,6 +298,9 @@ func (expr *CaseExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, e
 
 // TypeCheck implements the Expr interface.
 func (expr *CastExpr) TypeCheck(ctx *SemaContext, _ types.T) (TypedExpr, error) {
+	if err := ctx.ResolveType(expr.Type); err != nil {
+		return nil, err
+	}
 	returnType := expr.castType()
 
 	// The desired type provided to a CastExpr is ignored. Instead,
@@ -281,7 +319,7 @@ func (expr *CastExpr) TypeCheck(ctx *SemaContext, _ types.T) (TypedExpr, error)
 			// precision), the CastExpr becomes a no-op and can be elided.
 			switch expr.Type.(type) {
 			case *coltypes.TBool, *coltypes.TDate, *coltypes.TTimestamp, *coltypes.TTimestampTZ,
-				*coltypes.TInterval, *coltypes.TBytes:
+				*coltypes.TInterval, *coltypes.TBytes, *coltypes.TUserDefined:
 				return expr.Expr.TypeCheck(ctx, returnType)
 			}
 		}
@@ -312,13 +350,10 @@ func (expr *CastExpr) TypeCheck(ctx *SemaContext, _ types.T) (TypedExpr, error)
 
 // TypeCheck implements the Expr interface.
 func (expr *IndirectionExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, error) {
-	for i, t := range expr.Indirection {
+	for _, t := range expr.Indirection {
 		if t.Slice {
 			return nil, pgerror.UnimplementedWithIssueErrorf(2115, "ARRAY slicing in %s", expr)
 		}
-		if i > 0 {
-			return nil, pgerror.UnimplementedWithIssueErrorf(2115, "multidimensional ARRAY %s", expr)
-		}
 
 		beginExpr, err := typeCheckAndRequire(ctx, t.Begin, types.Int, "ARRAY subscript")
 		if err != nil {
@@ -343,6 +378,9 @@ func (expr *IndirectionExpr) TypeCheck(ctx *SemaContext, desired types.T) (Typed
 
 // TypeCheck implements the Expr interface.
 func (expr *AnnotateTypeExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, error) {
+	if err := ctx.ResolveType(expr.Type); err != nil {
+		return nil, err
+	}
 	annotType := expr.annotationType()
 	subExpr, err := typeCheckAndRequire(ctx, expr.Expr, annotType,
 		fmt.Sprintf("type annotation for %v as %s, found", expr.Expr, annotType))
@@ -798,6 +836,14 @@ func (expr *Array) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, erro
 	desiredParam := types.Any
 	if arr, ok := desired.(types.TArray); ok {
 		desiredParam = arr.Typ
+		// The elements of a multidimensional ARRAY constructor are arrays of
+		// the same type as the constructor.
+		for _, e := range expr.Exprs {
+			if _, ok := StripParens(e).(*Array); ok {
+				desiredParam = arr
+				break
+			}
+		}
 	}
 
 	if len(expr.Exprs) == 0 {
@@ -813,7 +859,13 @@ func (expr *Array) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, erro
 		return nil, err
 	}
 
-	expr.typ = types.TArray{Typ: typ}
+	if arr, ok := typ.(types.TArray); ok {
+		// As in Postgres, an array of arrays is a multidimensional array,
+		// whose type is the type of its sub-arrays.
+		expr.typ = arr
+	} else {
+		expr.typ = types.TArray{Typ: typ}
+	}
 	for i := range typedSubExprs {
 		expr.Exprs[i] = typedSubExprs[i]
 	}
@@ -910,6 +962,10 @@ func (d *DUuid) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return
 // identity function for Datum.
 func (d *DIPAddr) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DEnum) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DDate) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
@@ -922,6 +978,14 @@ func (d *DTimestamp) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { r
 // identity function for Datum.
 func (d *DTimestampTZ) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTime) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
+
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTimeTZ) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DInterval) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
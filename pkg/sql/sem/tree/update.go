@@ -26,6 +26,7 @@ import "bytes"
 type Update struct {
 	Table     TableExpr
 	Exprs     UpdateExprs
+	From      TableExprs
 	Where     *Where
 	Returning ReturningClause
 }
@@ -36,6 +37,7 @@ func (node *Update) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.Table)
 	buf.WriteString(" SET ")
 	FormatNode(buf, f, node.Exprs)
+	FormatNode(buf, f, node.From)
 	FormatNode(buf, f, node.Where)
 	FormatNode(buf, f, node.Returning)
 }
@@ -543,6 +543,9 @@ func (expr *DUuid) Walk(_ Visitor) Expr { return expr }
 // Walk implements the Expr interface.
 func (expr *DIPAddr) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr *DEnum) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr dNull) Walk(_ Visitor) Expr { return expr }
 
@@ -558,6 +561,12 @@ func (expr *DTimestamp) Walk(_ Visitor) Expr { return expr }
 // Walk implements the Expr interface.
 func (expr *DTimestampTZ) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr *DTime) Walk(_ Visitor) Expr { return expr }
+
+// Walk implements the Expr interface.
+func (expr *DTimeTZ) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr *DTuple) Walk(_ Visitor) Expr { return expr }
 
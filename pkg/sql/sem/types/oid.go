@@ -96,6 +96,10 @@ var OidToType = map[oid.Oid]T{
 	// TODO(jordan): I think this entry for T_record is out of place.
 	oid.T_record:       FamTuple,
 	oid.T_text:         String,
+	oid.T_time:         Time,
+	oid.T__time:        TArray{Time},
+	oid.T_timetz:       TimeTZ,
+	oid.T__timetz:      TArray{TimeTZ},
 	oid.T_timestamp:    Timestamp,
 	oid.T__timestamp:   TArray{Timestamp},
 	oid.T_timestamptz:  TimestampTZ,
@@ -140,6 +144,8 @@ var aliasedOidToName = map[oid.Oid]string{
 	oid.T__oid:         "_oid",
 	oid.T__timestamp:   "_timestamp",
 	oid.T__timestamptz: "_timestamptz",
+	oid.T__time:        "_time",
+	oid.T__timetz:      "_timetz",
 	oid.T__uuid:        "_uuid",
 	oid.T__inet:        "_inet",
 	oid.T__varchar:     "_varchar",
@@ -162,6 +168,8 @@ var oidToArrayOid = map[oid.Oid]oid.Oid{
 	oid.T_date:        oid.T__date,
 	oid.T_timestamp:   oid.T__timestamp,
 	oid.T_timestamptz: oid.T__timestamptz,
+	oid.T_time:        oid.T__time,
+	oid.T_timetz:      oid.T__timetz,
 	oid.T_interval:    oid.T__interval,
 	oid.T_numeric:     oid.T__numeric,
 	oid.T_uuid:        oid.T__uuid,
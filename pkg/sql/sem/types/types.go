@@ -66,6 +66,10 @@ var (
 	Timestamp T = tTimestamp{}
 	// TimestampTZ is the type of a DTimestampTZ. Can be compared with ==.
 	TimestampTZ T = tTimestampTZ{}
+	// Time is the type of a DTime. Can be compared with ==.
+	Time T = tTime{}
+	// TimeTZ is the type of a DTimeTZ. Can be compared with ==.
+	TimeTZ T = tTimeTZ{}
 	// Interval is the type of a DInterval. Can be compared with ==.
 	Interval T = tInterval{}
 	// JSON is the type of a DJSON. Can be compared with ==.
@@ -91,6 +95,8 @@ var (
 		Date,
 		Timestamp,
 		TimestampTZ,
+		Time,
+		TimeTZ,
 		Interval,
 		UUID,
 		INet,
@@ -107,6 +113,8 @@ var (
 	FamArray T = TArray{}
 	// FamTable is the type family of a DTable. CANNOT be compared with ==.
 	FamTable T = TTable{}
+	// FamEnum is the type family of a DEnum. CANNOT be compared with ==.
+	FamEnum T = TEnum{Name: "anyenum"}
 	// FamPlaceholder is the type family of a placeholder. CANNOT be compared
 	// with ==.
 	FamPlaceholder T = TPlaceholder{}
@@ -252,6 +260,27 @@ func (tTimestampTZ) Oid() oid.Oid             { return oid.T_timestamptz }
 func (tTimestampTZ) SQLName() string          { return "timestamp with time zone" }
 func (tTimestampTZ) IsAmbiguous() bool        { return false }
 
+type tTime struct{}
+
+func (tTime) String() string           { return "time" }
+func (tTime) Equivalent(other T) bool  { return UnwrapType(other) == Time || other == Any }
+func (tTime) FamilyEqual(other T) bool { return UnwrapType(other) == Time }
+func (tTime) Oid() oid.Oid             { return oid.T_time }
+func (tTime) SQLName() string          { return "time without time zone" }
+func (tTime) IsAmbiguous() bool        { return false }
+
+type tTimeTZ struct{}
+
+func (tTimeTZ) String() string { return "timetz" }
+func (tTimeTZ) Equivalent(other T) bool {
+	return UnwrapType(other) == TimeTZ || other == Any
+}
+
+func (tTimeTZ) FamilyEqual(other T) bool { return UnwrapType(other) == TimeTZ }
+func (tTimeTZ) Oid() oid.Oid             { return oid.T_timetz }
+func (tTimeTZ) SQLName() string          { return "time with time zone" }
+func (tTimeTZ) IsAmbiguous() bool        { return false }
+
 type tInterval struct{}
 
 func (tInterval) String() string { return "interval" }
@@ -470,6 +499,98 @@ func (a TTable) IsAmbiguous() bool {
 	return a.Cols == nil || a.Cols.IsAmbiguous()
 }
 
+// TEnum is the type of a DEnum, a value of a user-defined ENUM type.
+type TEnum struct {
+	// ID is the ID of the type's descriptor.
+	ID uint32
+	// Name is the name of the type.
+	Name string
+	// Values holds the values of the type. It is nil in FamEnum.
+	Values *EnumValues
+}
+
+// EnumValues holds the values of an ENUM type, in declaration order.
+type EnumValues struct {
+	// Labels are the names of the values.
+	Labels []string
+	// PhysicalReps are the byte strings representing the values in the
+	// encodings. They sort in the same order as the values.
+	PhysicalReps [][]byte
+	// AddingLabels are the labels of the values that are being added to the
+	// type. These values can be decoded, but not yet produced.
+	AddingLabels []string
+}
+
+// UserDefinedTypeOIDOffset is added to the ID of a user-defined type to form
+// its OID, so that it cannot collide with the OIDs of the built-in types.
+const UserDefinedTypeOIDOffset = 100000
+
+func (t TEnum) String() string { return t.Name }
+
+// Equivalent implements the T interface.
+func (t TEnum) Equivalent(other T) bool {
+	if other == Any {
+		return true
+	}
+	if u, ok := UnwrapType(other).(TEnum); ok {
+		return t.ID == 0 || u.ID == 0 || t.ID == u.ID
+	}
+	return false
+}
+
+// FamilyEqual implements the T interface.
+func (TEnum) FamilyEqual(other T) bool {
+	_, ok := UnwrapType(other).(TEnum)
+	return ok
+}
+
+// Oid implements the T interface.
+func (t TEnum) Oid() oid.Oid {
+	if t.ID == 0 {
+		return oid.T_anyenum
+	}
+	return oid.Oid(UserDefinedTypeOIDOffset + t.ID)
+}
+
+// SQLName implements the T interface.
+func (t TEnum) SQLName() string { return t.Name }
+
+// IsAmbiguous implements the T interface.
+func (t TEnum) IsAmbiguous() bool { return t.ID == 0 }
+
+// LabelIndex returns the position of the value with the given label, or -1
+// if the type has no such value.
+func (t TEnum) LabelIndex(label string) int {
+	for i, l := range t.Values.Labels {
+		if l == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsAdding returns true if the value with the given label is being added to
+// the type.
+func (t TEnum) IsAdding(label string) bool {
+	for _, l := range t.Values.AddingLabels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// PhysicalRepIndex returns the position of the value with the given physical
+// representation, or -1 if the type has no such value.
+func (t TEnum) PhysicalRepIndex(rep []byte) int {
+	for i, r := range t.Values.PhysicalReps {
+		if bytes.Equal(r, rep) {
+			return i
+		}
+	}
+	return -1
+}
+
 type tAny struct{}
 
 func (tAny) String() string           { return "anyelement" }
@@ -493,6 +614,9 @@ func IsStringType(t T) bool {
 // IsValidArrayElementType returns true if the T
 // can be used in TArray.
 func IsValidArrayElementType(t T) bool {
+	if _, ok := t.(TEnum); ok {
+		return false
+	}
 	switch t {
 	case JSON:
 		return false
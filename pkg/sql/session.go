@@ -211,6 +211,9 @@ type Session struct {
 	// SafeUpdates causes errors when the client
 	// sends syntax that may have unwanted side effects.
 	SafeUpdates bool
+	// Vectorize indicates whether DistSQL flows should use the vectorized
+	// execution engine for the processors which support it.
+	Vectorize bool
 
 	//
 	// Session parameters, non-user-configurable.
@@ -296,10 +299,26 @@ type Session struct {
 	// bufferedResultWriter for internal uses.
 	ResultsWriter ResultsWriter
 
+	// portalExec is set while the statement of a portal is executed by
+	// ExecutePortal.
+	portalExec portalExec
+
 	Tracing SessionTracing
 
 	tables TableCollection
 
+	// tempSchemaName is the name of the schema holding the temporary tables
+	// of the session. It is assigned when the session starts and never
+	// changes.
+	tempSchemaName string
+	// tempSchemaDBs contains the IDs of the databases in which the session
+	// has created its temporary schema.
+	tempSchemaDBs map[sqlbase.ID]struct{}
+	// tempTablesOnCommit maps the temporary tables of the session created
+	// with an ON COMMIT clause to the action to carry out at the end of each
+	// transaction.
+	tempTablesOnCommit map[sqlbase.ID]tree.OnCommitAction
+
 	// If set, contains the in progress COPY FROM columns.
 	copyFrom *copyNode
 
@@ -472,10 +491,12 @@ func NewSession(
 			applicationName: args.ApplicationName,
 			database:        args.Database,
 		},
-		tables: TableCollection{
-			leaseMgr:      e.cfg.LeaseManager,
-			databaseCache: e.getDatabaseCache(),
-		},
+	}
+	s.tempSchemaName = makeTempSchemaName(e.cfg.NodeID.Get())
+	s.tables = TableCollection{
+		leaseMgr:       e.cfg.LeaseManager,
+		databaseCache:  e.getDatabaseCache(),
+		tempSchemaName: s.tempSchemaName,
 	}
 	s.phaseTimes[sessionInit] = timeutil.Now()
 	s.resetApplicationName(args.ApplicationName)
@@ -539,8 +560,10 @@ func (s *Session) Finish(e *Executor) {
 
 	// If we're inside a txn, roll it back.
 	if s.TxnState.State().kvTxnIsOpen() {
+		s.TxnState.savepoints = nil
 		_ = s.TxnState.updateStateAndCleanupOnErr(fmt.Errorf("session closing"), e)
 	}
+	s.TxnState.rollbackHeldTxn()
 	if s.TxnState.State() != NoTxn {
 		s.TxnState.finishSQLTxn(s)
 	}
@@ -550,6 +573,9 @@ func (s *Session) Finish(e *Executor) {
 	// addressed, there might be leases accumulated by preparing statements.
 	s.tables.releaseTables(s.context)
 
+	// Temporary tables do not outlive the session.
+	s.dropTempSchemas(e)
+
 	s.ClearStatementsAndPortals(s.context)
 	s.sessionMon.Stop(s.context)
 	s.mon.Stop(s.context)
@@ -631,6 +657,7 @@ func (s *Session) resetPlanner(p *planner, e *Executor, txn *client.Txn) {
 	p.semaCtx = tree.MakeSemaContext(s.User == security.RootUser)
 	p.semaCtx.Location = &s.Location
 	p.semaCtx.SearchPath = s.SearchPath
+	p.semaCtx.TypeResolver = p
 
 	p.evalCtx = s.evalCtx()
 	p.evalCtx.Planner = p
@@ -682,6 +709,7 @@ func (s *Session) evalCtx() tree.EvalContext {
 		Database:    s.Database,
 		User:        s.User,
 		SearchPath:  s.SearchPath,
+		Vectorize:   s.Vectorize,
 		CtxProvider: s,
 		Mon:         &s.TxnState.mon,
 	}
@@ -969,10 +997,21 @@ type txnState struct {
 	// single statement.
 	implicitTxn bool
 
+	// heldOpen is set if the transaction is implicit but wasn't committed after
+	// its statement because the execution of a portal was suspended in it. The
+	// transaction is ended when the client sends Sync.
+	heldOpen bool
+
 	// If set, the user declared the intention to retry the txn in case of retriable
 	// errors. The txn will enter a RestartWait state in case of such errors.
 	retryIntent bool
 
+	// The savepoints created in this txn, other than the restart savepoint, in
+	// order of creation. While there are any, a non-retryable error moves the
+	// txn to the Aborted state without rolling back the KV txn, so that the
+	// client can resume it by rolling back to one of them.
+	savepoints []savepoint
+
 	// A COMMIT statement has been processed. Useful for allowing the txn to
 	// survive retriable errors if it will be auto-retried (BEGIN; ... COMMIT; in
 	// the same batch), but not if the error needs to be reported to the user.
@@ -1044,9 +1083,11 @@ func (ts *txnState) resetForNewSQLTxn(
 
 	ts.retryIntent = retryIntent
 	// Reset state vars to defaults.
+	ts.savepoints = nil
 	ts.commitSeen = false
 	ts.sqlTimestamp = sqlTimestamp
 	ts.implicitTxn = implicitTxn
+	ts.heldOpen = false
 	ts.txnResults = s.ResultsWriter.NewResultsGroup()
 
 	// Create a context for this transaction. It will include a
@@ -1160,6 +1201,9 @@ func (ts *txnState) finishSQLTxn(s *Session) {
 		panic("No span in context? Was resetForNewSQLTxn() called previously?")
 	}
 
+	// Portals can't outlive their transaction.
+	s.PreparedPortals.closeSuspended(s.context)
+
 	// Finalize the transaction's results.
 	ts.txnResults.Close()
 	ts.txnResults = nil
@@ -1212,6 +1256,15 @@ func (ts *txnState) updateStateAndCleanupOnErr(err error, e *Executor) error {
 			"updateStateAndCleanupOnErr called in state with no KV txn. State: %s",
 			ts.State()))
 	}
+	if ts.canRollBackToSavepoint(err) {
+		// The txn is aborted, but we keep the KV txn open so that the client can
+		// resume it by rolling back to a savepoint.
+		ts.SetState(Aborted)
+		return err
+	}
+	// Whatever happens next, the KV txn is either rolled back or restarted,
+	// and the savepoints go with it.
+	ts.savepoints = nil
 	if retErr, ok := err.(*roachpb.HandledRetryableTxnError); !ok ||
 		!ts.willBeRetried() ||
 		!ts.mu.txn.IsRetryableErrMeantForTxn(*retErr) ||
@@ -1299,6 +1352,10 @@ type schemaChangerCollection struct {
 		epoch int
 		sc    SchemaChanger
 	}
+
+	// The ENUM types to which values were added, which are published once
+	// the schema changers have run.
+	enumTypes []enumTypeRef
 }
 
 func (scc *schemaChangerCollection) queueSchemaChanger(schemaChanger SchemaChanger) {
@@ -1310,9 +1367,19 @@ func (scc *schemaChangerCollection) queueSchemaChanger(schemaChanger SchemaChang
 		}{scc.curGroupNum, schemaChanger})
 }
 
+func (scc *schemaChangerCollection) queueEnumType(ref enumTypeRef) {
+	for _, r := range scc.enumTypes {
+		if r == ref {
+			return
+		}
+	}
+	scc.enumTypes = append(scc.enumTypes, ref)
+}
+
 // execSchemaChanges releases schema leases and runs the queued
-// schema changers. This needs to be run after the transaction
-// scheduling the schema change has finished.
+// schema changers, and then publishes the values added to ENUM types.
+// This needs to be run after the transaction scheduling the schema
+// change has finished.
 //
 // The list of closures is cleared after (attempting) execution.
 func (scc *schemaChangerCollection) execSchemaChanges(
@@ -1336,7 +1403,7 @@ func (scc *schemaChangerCollection) execSchemaChanges(
 					log.Warningf(ctx, "error executing schema change: %s", err)
 				}
 				if err == sqlbase.ErrDescriptorNotFound {
-				} else if sqlbase.IsPermanentSchemaChangeError(err) {
+				} else if _, ok := err.(*refreshFailedError); ok || sqlbase.IsPermanentSchemaChangeError(err) {
 					// All constraint violations can be reported; we report it as the result
 					// corresponding to the statement that enqueued this changer.
 					// There's some sketchiness here: we assume there's a single result
@@ -1357,6 +1424,18 @@ func (scc *schemaChangerCollection) execSchemaChanges(
 		}
 	}
 	scc.schemaChangers = scc.schemaChangers[:0]
+
+	// The values remain marked as being added if the transaction didn't
+	// commit, in which case this is a no-op.
+	for _, ref := range scc.enumTypes {
+		if err := publishEnumValues(ctx, e, ref); err != nil {
+			log.Warningf(ctx, "error publishing the new values of type %d: %s", ref.typeID, err)
+			if firstError == nil {
+				firstError = err
+			}
+		}
+	}
+	scc.enumTypes = scc.enumTypes[:0]
 	return firstError
 }
 
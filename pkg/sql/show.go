@@ -190,11 +190,11 @@ func (p *planner) ShowColumns(ctx context.Context, n *tree.ShowColumns) (planNod
 						 FROM
 								 (SELECT COLUMN_NAME, DATA_TYPE, IS_NULLABLE, COLUMN_DEFAULT, ORDINAL_POSITION
 										FROM "".information_schema.columns
-									 WHERE TABLE_SCHEMA=%[1]s AND TABLE_NAME=%[2]s)
+									 WHERE TABLE_CATALOG=%[5]s AND TABLE_SCHEMA=%[6]s AND TABLE_NAME=%[2]s)
 								 LEFT OUTER JOIN
 								 (SELECT COLUMN_NAME, INDEX_NAME
 										FROM "".information_schema.statistics
-									 WHERE TABLE_SCHEMA=%[1]s AND TABLE_NAME=%[2]s)
+									 WHERE TABLE_CATALOG=%[5]s AND TABLE_SCHEMA=%[6]s AND TABLE_NAME=%[2]s)
 								 USING(COLUMN_NAME)
 						GROUP BY COLUMN_NAME, DATA_TYPE, IS_NULLABLE, COLUMN_DEFAULT, ORDINAL_POSITION
 					 )
@@ -209,6 +209,9 @@ func (p *planner) ShowColumns(ctx context.Context, n *tree.ShowColumns) (planNod
 // %[2]s the unqualified table name as SQL string literal.
 // %[3]s the given table name as SQL string literal.
 // %[4]s the database name as SQL identifier.
+// %[5]s the catalog of the table in information_schema as SQL string literal.
+// %[6]s the schema of the table in information_schema as SQL string literal.
+// %[7]s the schema name, "public" by default, as SQL string literal.
 func (p *planner) showTableDetails(
 	ctx context.Context, showType string, t tree.NormalizableTableName, query string,
 ) (planNode, error) {
@@ -216,8 +219,20 @@ func (p *planner) showTableDetails(
 	if err != nil {
 		return nil, err
 	}
+	// The name is normalized so that its SchemaName field is set if the
+	// table belongs to a user-defined schema. Errors are reported by
+	// initialCheck below.
+	_, _, _ = resolveTableSchema(ctx, p.txn, p.getVirtualTabler(), tn)
 	db := tn.Database()
 
+	// information_schema presents databases as schemas of the "def"
+	// catalog, and user-defined schemas as schemas of the catalog named
+	// after their database.
+	catalog, schema, scName := "def", db, tree.PublicSchemaName
+	if tn.Schema() != "" {
+		catalog, schema, scName = db, tn.Schema(), tn.Schema()
+	}
+
 	initialCheck := func(ctx context.Context) error {
 		if err := checkDBExists(ctx, p, db); err != nil {
 			return err
@@ -234,7 +249,10 @@ func (p *planner) showTableDetails(
 			lex.EscapeSQLString(db),
 			lex.EscapeSQLString(tn.Table()),
 			lex.EscapeSQLString(tn.String()),
-			tn.DatabaseName.String()),
+			tn.DatabaseName.String(),
+			lex.EscapeSQLString(catalog),
+			lex.EscapeSQLString(schema),
+			lex.EscapeSQLString(scName)),
 		initialCheck, nil)
 }
 
@@ -252,7 +270,8 @@ func (p *planner) ShowCreateTable(ctx context.Context, n *tree.ShowCreateTable)
                                              %[1]s || '.' || %[2]s || ' is not a table')::string
             ) AS "CreateTable"
        FROM (SELECT create_statement FROM %[4]s.crdb_internal.create_statements
-              WHERE database_name = %[1]s AND descriptor_name = %[2]s AND descriptor_type = 'table'
+              WHERE database_name = %[1]s AND schema_name = %[7]s
+                AND descriptor_name = %[2]s AND descriptor_type = 'table'
               UNION ALL VALUES (NULL) ORDER BY 1 DESC) LIMIT 1
   `
 	return p.showTableDetails(ctx, "SHOW CREATE TABLE", n.Table, showCreateTableQuery)
@@ -272,7 +291,8 @@ func (p *planner) ShowCreateView(ctx context.Context, n *tree.ShowCreateView) (p
                                              %[1]s || '.' || %[2]s || ' is not a view')::string
             ) AS "CreateView"
        FROM (SELECT create_statement FROM %[4]s.crdb_internal.create_statements
-              WHERE database_name = %[1]s AND descriptor_name = %[2]s AND descriptor_type = 'view'
+              WHERE database_name = %[1]s AND schema_name = %[7]s
+                AND descriptor_name = %[2]s AND descriptor_type = 'view'
               UNION ALL VALUES (NULL) ORDER BY 1 DESC) LIMIT 1
   `
 	return p.showTableDetails(ctx, "SHOW CREATE VIEW", n.View, showCreateViewQuery)
@@ -394,7 +414,8 @@ WHERE message LIKE 'fetched: %'
 //          mysql has a "SHOW DATABASES" permission, but we have no system-level permissions.
 func (p *planner) ShowDatabases(ctx context.Context, n *tree.ShowDatabases) (planNode, error) {
 	return p.delegateQuery(ctx, "SHOW DATABASES",
-		`SELECT SCHEMA_NAME AS "Database" FROM information_schema.schemata ORDER BY "Database"`,
+		`SELECT SCHEMA_NAME AS "Database" FROM information_schema.schemata `+
+			`WHERE CATALOG_NAME = 'def' ORDER BY "Database"`,
 		nil, nil)
 }
 
@@ -406,16 +427,51 @@ func (p *planner) ShowGrants(ctx context.Context, n *tree.ShowGrants) (planNode,
 	var params []string
 	var initCheck func(context.Context) error
 
+	// information_schema presents databases as schemas of the "def"
+	// catalog, and user-defined schemas as schemas of the catalog named
+	// after their database. The tables of a user-defined schema are shown
+	// with their schema-qualified name.
 	const dbPrivQuery = `SELECT TABLE_SCHEMA AS "Database", GRANTEE AS "User", PRIVILEGE_TYPE AS "Privileges" ` +
-		`FROM "".information_schema.schema_privileges`
-	const tablePrivQuery = `SELECT TABLE_SCHEMA AS "Database", TABLE_NAME AS "Table", GRANTEE AS "User", PRIVILEGE_TYPE AS "Privileges" ` +
+		`FROM "".information_schema.schema_privileges WHERE TABLE_CATALOG = 'def'`
+	const schemaPrivQuery = `SELECT TABLE_CATALOG AS "Database", TABLE_SCHEMA AS "Schema", GRANTEE AS "User", PRIVILEGE_TYPE AS "Privileges" ` +
+		`FROM "".information_schema.schema_privileges WHERE TABLE_CATALOG != 'def'`
+	const tablePrivQuery = `SELECT IF(TABLE_CATALOG = 'def', TABLE_SCHEMA, TABLE_CATALOG) AS "Database", ` +
+		`IF(TABLE_CATALOG = 'def', TABLE_NAME, TABLE_SCHEMA || '.' || TABLE_NAME) AS "Table", ` +
+		`GRANTEE AS "User", PRIVILEGE_TYPE AS "Privileges" ` +
 		`FROM "".information_schema.table_privileges`
 
 	var source bytes.Buffer
 	var cond bytes.Buffer
 	var orderBy string
 
-	if n.Targets != nil && n.Targets.Databases != nil {
+	if n.Targets != nil && n.Targets.Schemas != nil {
+		// Get grants of schema from information_schema.schema_privileges
+		// if the type of target is schema.
+		schemas := n.Targets.Schemas
+		initCheck = func(ctx context.Context) error {
+			for _, sn := range schemas {
+				dbDesc, err := MustGetDatabaseDesc(
+					ctx, p.txn, p.getVirtualTabler(), sn.Database(p.session.Database))
+				if err != nil {
+					return err
+				}
+				if _, err := findSchema(dbDesc, sn.Schema()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		for _, sn := range schemas {
+			params = append(params, fmt.Sprintf("(%s,%s)",
+				lex.EscapeSQLString(sn.Database(p.session.Database)),
+				lex.EscapeSQLString(sn.Schema())))
+		}
+
+		fmt.Fprint(&source, schemaPrivQuery)
+		orderBy = "1,2,3,4"
+		fmt.Fprintf(&cond, `WHERE ("Database", "Schema") IN (%s)`, strings.Join(params, ","))
+	} else if n.Targets != nil && n.Targets.Databases != nil {
 		// Get grants of database from information_schema.schema_privileges
 		// if the type of target is database.
 		dbNames := n.Targets.Databases.ToStrings()
@@ -474,9 +530,13 @@ func (p *planner) ShowGrants(ctx context.Context, n *tree.ShowGrants) (planNode,
 			}
 
 			for i := range allTables {
+				table := allTables[i].Table()
+				if sc := allTables[i].Schema(); sc != "" {
+					table = sc + "." + table
+				}
 				params = append(params, fmt.Sprintf("(%s,%s)",
 					lex.EscapeSQLString(allTables[i].Database()),
-					lex.EscapeSQLString(allTables[i].Table())))
+					lex.EscapeSQLString(table)))
 			}
 
 			if len(params) == 0 {
@@ -524,7 +584,7 @@ func (p *planner) ShowIndex(ctx context.Context, n *tree.ShowIndex) (planNode, e
 					STORING AS "Storing",
 					IMPLICIT AS "Implicit"
 				FROM "".information_schema.statistics
-				WHERE TABLE_SCHEMA=%[1]s AND TABLE_NAME=%[2]s`
+				WHERE TABLE_CATALOG=%[5]s AND TABLE_SCHEMA=%[6]s AND TABLE_NAME=%[2]s`
 	return p.showTableDetails(ctx, "SHOW INDEX", n.Table, getIndexes)
 }
 
@@ -649,7 +709,7 @@ func (p *planner) ShowTables(ctx context.Context, n *tree.ShowTables) (planNode,
 	const getTablesQuery = `
 				SELECT TABLE_NAME AS "Table"
 				FROM "".information_schema.tables
-				WHERE tables.TABLE_SCHEMA=%[1]s
+				WHERE tables.TABLE_CATALOG='def' AND tables.TABLE_SCHEMA=%[1]s
 				ORDER BY tables.TABLE_NAME`
 
 	return p.delegateQuery(ctx, "SHOW TABLES",
@@ -27,13 +27,19 @@ import (
 // showCreateView returns a valid SQL representation of the CREATE
 // VIEW statement used to create the given view.
 func (p *planner) showCreateView(
-	ctx context.Context, tn tree.Name, desc *sqlbase.TableDescriptor,
+	ctx context.Context, tn *tree.TableName, desc *sqlbase.TableDescriptor,
 ) (string, error) {
 	var buf bytes.Buffer
-	buf.WriteString("CREATE VIEW ")
+	buf.WriteString("CREATE ")
+	if desc.MaterializedView() {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
 	tn.Format(&buf, tree.FmtSimple)
 	buf.WriteString(" (")
-	for i, col := range desc.Columns {
+	// The hidden columns of a materialized view are not part of its
+	// definition.
+	for i, col := range desc.VisibleColumns() {
 		if i > 0 {
 			buf.WriteString(", ")
 		}
@@ -52,7 +58,7 @@ func (p *planner) showCreateView(
 // the prefix when the given table references other tables in the
 // current database.
 func (p *planner) showCreateTable(
-	ctx context.Context, tn tree.Name, dbPrefix string, desc *sqlbase.TableDescriptor,
+	ctx context.Context, tn *tree.TableName, dbPrefix string, desc *sqlbase.TableDescriptor,
 ) (string, error) {
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf, "CREATE TABLE %s (", tn)
@@ -91,6 +97,9 @@ func (p *planner) showCreateTable(
 				TableName:               tree.Name(fkTable.Name),
 				DBNameOriginallyOmitted: fkDb.Name == dbPrefix,
 			}
+			if scDesc, ok := fkDb.FindSchemaByID(fkTable.ParentSchemaID); ok {
+				fkTableName.SchemaName = tree.Name(scDesc.Name)
+			}
 			fmt.Fprintf(&buf, ",\n\tCONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
 				tree.Name(fk.Name),
 				quoteNames(idx.ColumnNames[0:idx.ForeignKey.SharedPrefixLen]...),
@@ -106,18 +115,23 @@ func (p *planner) showCreateTable(
 		}
 		if idx.ID != desc.PrimaryIndex.ID {
 			// Showing the primary index is handled above.
-			fmt.Fprintf(&buf, ",\n\t%s", idx.SQLString(""))
+			fmt.Fprintf(&buf, ",\n\t%s", desc.IndexSQLString(&idx))
 			// Showing the INTERLEAVE for the primary index is handled last.
 			if err := p.showCreateInterleave(ctx, &idx, &buf, dbPrefix); err != nil {
 				return "", err
 			}
+			if idx.IsPartial() {
+				fmt.Fprintf(&buf, " WHERE %s", idx.Predicate)
+			}
 		}
 	}
 
 	for _, fam := range desc.Families {
 		activeColumnNames := make([]string, 0, len(fam.ColumnNames))
 		for i, colID := range fam.ColumnIDs {
-			if _, err := desc.FindActiveColumnByID(colID); err == nil {
+			// The hidden columns of expression indexes are created by the
+			// indexes.
+			if col, err := desc.FindActiveColumnByID(colID); err == nil && !col.IsIndexExpr() {
 				activeColumnNames = append(activeColumnNames, fam.ColumnNames[i])
 			}
 		}
@@ -179,6 +193,9 @@ func (p *planner) showCreateInterleave(
 		TableName:               tree.Name(parentTable.Name),
 		DBNameOriginallyOmitted: parentDbDesc.Name == dbPrefix,
 	}
+	if scDesc, ok := parentDbDesc.FindSchemaByID(parentTable.ParentSchemaID); ok {
+		parentName.SchemaName = tree.Name(scDesc.Name)
+	}
 	var sharedPrefixLen int
 	for _, ancestor := range intl.Ancestors {
 		sharedPrefixLen += int(ancestor.SharedPrefixLen)
@@ -0,0 +1,222 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// IndexExprColumnName is the base name of the hidden computed columns which
+// back the expression elements of indexes.
+const IndexExprColumnName = "crdb_idx_expr"
+
+// IsComputed returns true if the value of the column is computed from the
+// other columns of the row.
+func (desc *ColumnDescriptor) IsComputed() bool {
+	return desc.ComputeExpr != nil
+}
+
+// IsIndexExpr returns true if the column is the hidden computed column backing
+// an expression element of an index.
+func (desc *ColumnDescriptor) IsIndexExpr() bool {
+	return desc.Hidden && desc.IsComputed()
+}
+
+// MakeIndexExprColumn returns the hidden computed column which backs the
+// given index expression. If the table already has such a column, it is
+// returned and added is false; otherwise a new column, without an ID, is
+// returned, which the caller must add to the table.
+func (desc *TableDescriptor) MakeIndexExprColumn(
+	expr tree.Expr, searchPath tree.SearchPath,
+) (col ColumnDescriptor, added bool, _ error) {
+	var t transform.ExprTransformContext
+	if err := t.AssertNoAggregationOrWindowing(expr, "index expressions", searchPath); err != nil {
+		return ColumnDescriptor{}, false, err
+	}
+	typedExpr, err := makeRowExpr(
+		desc, desc.Columns, expr, nil /* container */, types.Any, "index expression",
+	)
+	if err != nil {
+		return ColumnDescriptor{}, false, err
+	}
+	computeExpr := tree.Serialize(expr)
+	if err := desc.checkNoComputedColumnRefs(computeExpr, "index expression"); err != nil {
+		return ColumnDescriptor{}, false, err
+	}
+	cols := desc.Columns
+	for _, m := range desc.Mutations {
+		if col := m.GetColumn(); col != nil && m.Direction == DescriptorMutation_ADD {
+			cols = append(cols[:len(cols):len(cols)], *col)
+		}
+	}
+	for _, c := range cols {
+		if c.IsIndexExpr() && *c.ComputeExpr == computeExpr {
+			return c, false, nil
+		}
+	}
+
+	colType, err := DatumTypeToColumnType(typedExpr.ResolvedType())
+	if err != nil {
+		return ColumnDescriptor{}, false, err
+	}
+	if !columnTypeIsIndexable(colType) {
+		return ColumnDescriptor{}, false, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"index expression %s of type %s is not indexable", expr, colType.SQLString())
+	}
+	col = ColumnDescriptor{
+		Name:        IndexExprColumnName,
+		Type:        colType,
+		Nullable:    true,
+		Hidden:      true,
+		ComputeExpr: &computeExpr,
+	}
+	for i := 1; ; i++ {
+		if _, _, err := desc.FindColumnByName(tree.Name(col.Name)); err != nil {
+			break
+		}
+		col.Name = fmt.Sprintf("%s_%d", IndexExprColumnName, i)
+	}
+	return col, true, nil
+}
+
+// ValidateComputedColumn checks the expression of a stored computed column of
+// the table. The expression must have the type of the column and only
+// reference columns of the table which aren't computed; it cannot contain
+// subqueries, aggregate or window functions, or impure functions.
+func (desc *TableDescriptor) ValidateComputedColumn(
+	col *ColumnDescriptor, searchPath tree.SearchPath,
+) error {
+	expr, err := parser.ParseExpr(*col.ComputeExpr)
+	if err != nil {
+		return err
+	}
+	var t transform.ExprTransformContext
+	if err := t.AssertNoAggregationOrWindowing(expr, "computed columns", searchPath); err != nil {
+		return err
+	}
+	if _, err := makeRowExpr(
+		desc, desc.Columns, expr, nil /* container */, col.Type.ToDatumType(), "computed column",
+	); err != nil {
+		return err
+	}
+	return desc.checkNoComputedColumnRefs(*col.ComputeExpr, "computed column")
+}
+
+// checkNoComputedColumnRefs checks that the given serialized expression
+// doesn't reference computed columns, whose values may not be computed yet
+// when the expression is evaluated.
+func (desc *TableDescriptor) checkNoComputedColumnRefs(exprStr string, context string) error {
+	colIDs, err := rowExprColumnIDs(desc, exprStr, context)
+	if err != nil {
+		return err
+	}
+	for _, colID := range colIDs {
+		col, err := desc.FindColumnByID(colID)
+		if err != nil {
+			return err
+		}
+		if col.IsComputed() {
+			return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+				"computed column %q is not allowed in %s", col.Name, context)
+		}
+	}
+	return nil
+}
+
+// ComputedColumnDeps returns the IDs of the columns of the table from which
+// the value of the given computed column is computed.
+func (desc *TableDescriptor) ComputedColumnDeps(col *ColumnDescriptor) ([]ColumnID, error) {
+	if !col.IsComputed() {
+		return nil, nil
+	}
+	return rowExprColumnIDs(desc, *col.ComputeExpr, "computed column")
+}
+
+// ComputedColumnExpr returns the typed expression of the given computed
+// column. The column references are replaced with IndexedVars, linked to
+// container, whose indexes are ordinals in cols; cols must contain all the
+// columns from which the value of the column is computed.
+func (desc *TableDescriptor) ComputedColumnExpr(
+	col *ColumnDescriptor, cols []ColumnDescriptor, container tree.IndexedVarContainer,
+) (tree.TypedExpr, error) {
+	expr, err := parser.ParseExpr(*col.ComputeExpr)
+	if err != nil {
+		return nil, err
+	}
+	return makeRowExpr(desc, cols, expr, container, col.Type.ToDatumType(), "computed column")
+}
+
+// ComputedColumns evaluates the computed columns of a table on the rows which
+// are written to it.
+type ComputedColumns struct {
+	row exprRow
+	// Cols are the computed columns.
+	Cols  []ColumnDescriptor
+	exprs []tree.TypedExpr
+	// The expressions are immutable, so they don't depend on the session.
+	evalCtx tree.EvalContext
+}
+
+// MakeComputedColumns parses the expressions of the computed columns among
+// the given columns of the table. It returns nil if none of the columns are
+// computed.
+func MakeComputedColumns(desc *TableDescriptor, cols []ColumnDescriptor) (*ComputedColumns, error) {
+	var c *ComputedColumns
+	for i := range cols {
+		if !cols[i].IsComputed() {
+			continue
+		}
+		if c == nil {
+			c = &ComputedColumns{row: exprRow{cols: allTableColumns(desc)}}
+		}
+		expr, err := desc.ComputedColumnExpr(&cols[i], c.row.cols, &c.row)
+		if err != nil {
+			return nil, errors.Wrapf(err, "computed column %q", cols[i].Name)
+		}
+		c.Cols = append(c.Cols, cols[i])
+		c.exprs = append(c.exprs, expr)
+	}
+	return c, nil
+}
+
+// Eval computes the values of the computed columns of the row with the given
+// values, and stores them in values; colMap must contain all the computed
+// columns and the columns they are computed from.
+func (c *ComputedColumns) Eval(colMap map[ColumnID]int, values []tree.Datum) error {
+	if c == nil {
+		return nil
+	}
+	c.row.colMap = colMap
+	c.row.values = values
+	for i, e := range c.exprs {
+		d, err := e.Eval(&c.evalCtx)
+		if err != nil {
+			return err
+		}
+		if d == tree.DNull && !c.Cols[i].Nullable {
+			return NewNonNullViolationError(c.Cols[i].Name)
+		}
+		values[colMap[c.Cols[i].ID]] = d
+	}
+	return nil
+}
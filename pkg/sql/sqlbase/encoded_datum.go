@@ -17,6 +17,7 @@ package sqlbase
 import (
 	"bytes"
 	"fmt"
+	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
@@ -50,6 +51,8 @@ type EncDatum struct {
 	Datum tree.Datum
 }
 
+const sizeOfEncDatum = unsafe.Sizeof(EncDatum{})
+
 func (ed *EncDatum) stringWithAlloc(typ *ColumnType, a *DatumAlloc) string {
 	if ed.Datum == nil {
 		if ed.encoded == nil {
@@ -125,6 +128,16 @@ func DatumToEncDatum(ctyp ColumnType, d tree.Datum) EncDatum {
 	return EncDatum{Datum: d}
 }
 
+// Size returns a lower bound on the total size of the receiver in bytes,
+// including the memory referenced by the receiver.
+func (ed *EncDatum) Size() uintptr {
+	size := sizeOfEncDatum + uintptr(len(ed.encoded))
+	if ed.Datum != nil {
+		size += ed.Datum.Size()
+	}
+	return size
+}
+
 // UnsetDatum ensures subsequent IsUnset() calls return false.
 func (ed *EncDatum) UnsetDatum() {
 	ed.encoded = nil
@@ -258,6 +271,16 @@ func (ed *EncDatum) Compare(
 // EncDatumRow is a row of EncDatums.
 type EncDatumRow []EncDatum
 
+// Size returns a lower bound on the total size of the EncDatums in the
+// receiver, including the memory they reference.
+func (r EncDatumRow) Size() uintptr {
+	var size uintptr
+	for i := range r {
+		size += r[i].Size()
+	}
+	return size
+}
+
 func (r EncDatumRow) stringToBuf(types []ColumnType, a *DatumAlloc, b *bytes.Buffer) {
 	if len(types) != len(r) {
 		panic(fmt.Sprintf("mismatched types (%v) and row (%v)", types, r))
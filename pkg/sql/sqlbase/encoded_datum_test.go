@@ -208,6 +208,9 @@ func TestEncDatumCompare(t *testing.T) {
 		if kind == ColumnType_COLLATEDSTRING {
 			typ.Locale = RandCollationLocale(rng)
 		}
+		if kind == ColumnType_ENUM {
+			typ = RandEnumColumnType(rng)
+		}
 
 		// Generate two datums d1 < d2
 		var d1, d2 tree.Datum
@@ -0,0 +1,72 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+// The values of an ENUM type are encoded using their physical
+// representation: a byte string chosen so that the physical representations
+// of the values sort in the order in which the values were declared. Values
+// can be added anywhere in the type without rewriting the existing data, by
+// choosing a physical representation between the ones of the neighboring
+// values. Physical representations never end with a zero byte, so that there
+// is always room between two of them.
+
+// maxEvenlySpacedEnumValues is the largest number of values whose physical
+// representations can be spaced evenly with a single byte each.
+const maxEvenlySpacedEnumValues = 254
+
+// GenerateEnumPhysicalReps returns the physical representations of the n
+// values of a new ENUM type. The representations are spread evenly so that
+// values can later be added anywhere in the type with short
+// representations.
+func GenerateEnumPhysicalReps(n int) [][]byte {
+	reps := make([][]byte, n)
+	if n <= maxEvenlySpacedEnumValues {
+		for i := range reps {
+			reps[i] = []byte{byte((i + 1) * 256 / (n + 1))}
+		}
+		return reps
+	}
+	var prev []byte
+	for i := range reps {
+		reps[i] = EnumPhysicalRepBetween(prev, nil)
+		prev = reps[i]
+	}
+	return reps
+}
+
+// EnumPhysicalRepBetween returns a physical representation that sorts
+// strictly between prev and next. A nil prev stands for the beginning of the
+// type and a nil next for its end. prev must sort before next.
+func EnumPhysicalRepBetween(prev, next []byte) []byte {
+	var res []byte
+	for i := 0; ; i++ {
+		low, high := 0, 256
+		if i < len(prev) {
+			low = int(prev[i])
+		}
+		if next != nil && i < len(next) {
+			high = int(next[i])
+		}
+		if high-low > 1 {
+			return append(res, byte((low+high)/2))
+		}
+		res = append(res, byte(low))
+		if high == low+1 {
+			// res now sorts before next regardless of the bytes that follow;
+			// only prev constrains them.
+			next = nil
+		}
+	}
+}
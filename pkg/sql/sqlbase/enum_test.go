@@ -0,0 +1,86 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func checkEnumPhysicalReps(t *testing.T, reps [][]byte) {
+	for i, rep := range reps {
+		if len(rep) == 0 || rep[len(rep)-1] == 0 {
+			t.Fatalf("%d: invalid physical representation %x", i, rep)
+		}
+		if i > 0 && bytes.Compare(reps[i-1], rep) >= 0 {
+			t.Fatalf("%d: physical representations out of order: %x >= %x", i, reps[i-1], rep)
+		}
+	}
+}
+
+func TestGenerateEnumPhysicalReps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	for _, n := range []int{0, 1, 2, 3, 10, 253, 254, 255, 1000} {
+		reps := GenerateEnumPhysicalReps(n)
+		if len(reps) != n {
+			t.Fatalf("%d: expected %d physical representations, found %d", n, n, len(reps))
+		}
+		checkEnumPhysicalReps(t, reps)
+	}
+}
+
+func TestEnumPhysicalRepBetween(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	testCases := []struct {
+		prev, next, expected []byte
+	}{
+		{nil, nil, []byte{0x80}},
+		{[]byte{0x80}, nil, []byte{0xc0}},
+		{nil, []byte{0x80}, []byte{0x40}},
+		{[]byte{0x40}, []byte{0x80}, []byte{0x60}},
+		{[]byte{0x80}, []byte{0x81}, []byte{0x80, 0x80}},
+		{nil, []byte{0x01}, []byte{0x00, 0x80}},
+		{[]byte{0xff}, nil, []byte{0xff, 0x80}},
+		{[]byte{0x80}, []byte{0x80, 0x01}, []byte{0x80, 0x00, 0x80}},
+		{[]byte{0x80, 0x05}, []byte{0x81}, []byte{0x80, 0x82}},
+		{[]byte{0x80, 0x05}, []byte{0x80, 0x07}, []byte{0x80, 0x06}},
+	}
+	for i, tc := range testCases {
+		if res := EnumPhysicalRepBetween(tc.prev, tc.next); !bytes.Equal(res, tc.expected) {
+			t.Errorf("%d: expected %x, found %x", i, tc.expected, res)
+		}
+	}
+
+	// Repeatedly insert values at random positions.
+	reps := GenerateEnumPhysicalReps(3)
+	for i := 0; i < 1000; i++ {
+		pos := rand.Intn(len(reps) + 1)
+		var prev, next []byte
+		if pos > 0 {
+			prev = reps[pos-1]
+		}
+		if pos < len(reps) {
+			next = reps[pos]
+		}
+		rep := EnumPhysicalRepBetween(prev, next)
+		reps = append(reps, nil)
+		copy(reps[pos+1:], reps[pos:])
+		reps[pos] = rep
+		checkEnumPhysicalReps(t, reps)
+	}
+}
@@ -123,6 +123,26 @@ func IsUndefinedDatabaseError(err error) bool {
 	return errHasCode(err, pgerror.CodeInvalidCatalogNameError)
 }
 
+// NewUndefinedSchemaError creates an error for a missing schema.
+func NewUndefinedSchemaError(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeInvalidSchemaNameError, "schema %q does not exist", name)
+}
+
+// IsUndefinedSchemaError returns true if the error is for an undefined schema.
+func IsUndefinedSchemaError(err error) bool {
+	return errHasCode(err, pgerror.CodeInvalidSchemaNameError)
+}
+
+// NewUndefinedTypeError creates an error for a missing user-defined type.
+func NewUndefinedTypeError(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError, "type %q does not exist", name)
+}
+
+// IsUndefinedTypeError returns true if the error is for an undefined type.
+func IsUndefinedTypeError(err error) bool {
+	return errHasCode(err, pgerror.CodeUndefinedObjectError)
+}
+
 // NewUndefinedRelationError creates an error that represents a missing database table or view.
 func NewUndefinedRelationError(name tree.NodeFormatter) error {
 	return pgerror.NewErrorf(pgerror.CodeUndefinedTableError,
@@ -139,6 +159,16 @@ func NewDatabaseAlreadyExistsError(name string) error {
 	return pgerror.NewErrorf(pgerror.CodeDuplicateDatabaseError, "database %q already exists", name)
 }
 
+// NewSchemaAlreadyExistsError creates an error for a preexisting schema.
+func NewSchemaAlreadyExistsError(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeDuplicateSchemaError, "schema %q already exists", name)
+}
+
+// NewTypeAlreadyExistsError creates an error for a preexisting type.
+func NewTypeAlreadyExistsError(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError, "type %q already exists", name)
+}
+
 // NewRelationAlreadyExistsError creates an error for a preexisting relation.
 func NewRelationAlreadyExistsError(name string) error {
 	return pgerror.NewErrorf(pgerror.CodeDuplicateRelationError, "relation %q already exists", name)
@@ -271,7 +301,7 @@ func ConvertBatchError(ctx context.Context, tableDesc *TableDescriptor, b *clien
 			cols[i] = *col
 			valNeededForCol[i] = true
 		}
-		if err := rf.Init(tableDesc, colIdxMap, index, false /* reverse */, false, /* lockForUpdate */
+		if err := rf.Init(tableDesc, colIdxMap, index, false /* reverse */, ScanLocking{},
 			indexID != tableDesc.PrimaryIndex.ID /* isSecondaryIndex */, cols, valNeededForCol,
 			false /* returnRangeInfo */, &DatumAlloc{}); err != nil {
 			return err
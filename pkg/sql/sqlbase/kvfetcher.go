@@ -24,6 +24,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
@@ -80,6 +81,20 @@ func SetKVBatchSize(val int64) func() {
 	return func() { kvBatchSize = oldVal }
 }
 
+// ScanLocking describes the row-level locking performed by a scan, as
+// requested by a SELECT's locking clause (FOR UPDATE etc).
+type ScanLocking struct {
+	// ForUpdate, if set, causes the scan to acquire exclusive locks on the rows
+	// it returns, after they've passed its filter (see RowFetcher.LockRow).
+	// Locked rows are not rewritten. Concurrent transactions
+	// that want to read or write those rows are queued behind the scanning
+	// transaction instead of forcing it to restart at commit time.
+	ForUpdate bool
+	// NoWait, if set, causes the scan to return an error instead of waiting
+	// when it encounters a row locked by another transaction.
+	NoWait bool
+}
+
 // txnKVFetcher handles retrieval of key/values.
 type txnKVFetcher struct {
 	// "Constant" fields, provided by the caller.
@@ -88,7 +103,7 @@ type txnKVFetcher struct {
 	firstBatchLimit int64
 	useBatchLimit   bool
 	reverse         bool
-	lockForUpdate   bool
+	locking         ScanLocking
 	// returnRangeInfo, if set, causes the kvFetcher to populate rangeInfos.
 	// See also rowFetcher.returnRangeInfo.
 	returnRangeInfo bool
@@ -104,6 +119,9 @@ type txnKVFetcher struct {
 	// rangeInfos are deduped, so they're not ordered in any particular way and
 	// they don't map to kvFetcher.spans in any particular way.
 	rangeInfos []roachpb.RangeInfo
+
+	// batchCount, if set, is incremented for every KV batch sent.
+	batchCount *int64
 }
 
 func (f *txnKVFetcher) getRangesInfo() []roachpb.RangeInfo {
@@ -166,7 +184,7 @@ func makeKVFetcher(
 	txn *client.Txn,
 	spans roachpb.Spans,
 	reverse bool,
-	lockForUpdate bool,
+	locking ScanLocking,
 	useBatchLimit bool,
 	firstBatchLimit int64,
 	returnRangeInfo bool,
@@ -200,22 +218,27 @@ func makeKVFetcher(
 		txn:             txn,
 		spans:           copySpans,
 		reverse:         reverse,
-		lockForUpdate:   lockForUpdate,
+		locking:         locking,
 		useBatchLimit:   useBatchLimit,
 		firstBatchLimit: firstBatchLimit,
 		returnRangeInfo: returnRangeInfo,
 	}, nil
 }
 
+// countBatch records that a KV batch is about to be sent.
+func (f *txnKVFetcher) countBatch() {
+	if f.batchCount != nil {
+		*f.batchCount++
+	}
+}
+
 // fetch retrieves spans from the kv
 func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	var ba roachpb.BatchRequest
 	ba.Header.MaxSpanRequestKeys = f.getBatchSize()
 	ba.Header.ReturnRangeInfo = f.returnRangeInfo
+	ba.Header.NoWait = f.locking.NoWait
 	ba.Requests = make([]roachpb.RequestUnion, len(f.spans))
-	if f.lockForUpdate {
-		return errors.Errorf("explicit locking with FOR UPDATE is not yet supported")
-	}
 
 	if f.reverse {
 		scans := make([]roachpb.ReverseScanRequest, len(f.spans))
@@ -245,9 +268,10 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	// Reset spans in preparation for adding resume-spans below.
 	f.spans = f.spans[:0]
 
+	f.countBatch()
 	br, err := f.txn.Send(ctx, ba)
 	if err != nil {
-		return err.GoError()
+		return convertLockingError(err.GoError(), f.locking)
 	}
 	f.responses = br.Responses
 
@@ -289,6 +313,16 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	return nil
 }
 
+// convertLockingError turns the conflict reported when a NoWait scan runs
+// into a row locked by another transaction into the error returned by
+// Postgres in the same situation.
+func convertLockingError(err error, locking ScanLocking) error {
+	if _, ok := err.(*roachpb.WriteIntentError); ok && locking.NoWait {
+		return pgerror.NewErrorf(pgerror.CodeLockNotAvailableError, "could not obtain lock on row: %s", err)
+	}
+	return err
+}
+
 // nextKV returns the next key/value (initiating fetches as necessary). When
 // there are no more keys, returns false and an empty key/value.
 func (f *txnKVFetcher) nextKV(ctx context.Context) (bool, roachpb.KeyValue, error) {
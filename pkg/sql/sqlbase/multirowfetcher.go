@@ -133,7 +133,7 @@ type MultiRowFetcher struct {
 	// or not when StartScan is invoked.
 	reverse bool
 
-	lockForUpdate bool
+	locking ScanLocking
 
 	// maxKeysPerRow memoizes the maximum number of keys per row
 	// out of all the tables. This is used to calculate the kvFetcher's
@@ -183,7 +183,9 @@ type MultiRowFetcher struct {
 // index.
 func (mrf *MultiRowFetcher) Init(
 	tables []MultiRowFetcherTableArgs,
-	reverse, lockForUpdate, returnRangeInfo bool,
+	reverse bool,
+	locking ScanLocking,
+	returnRangeInfo bool,
 	alloc *DatumAlloc,
 ) error {
 	if len(tables) == 0 {
@@ -191,7 +193,7 @@ func (mrf *MultiRowFetcher) Init(
 	}
 
 	mrf.reverse = reverse
-	mrf.lockForUpdate = lockForUpdate
+	mrf.locking = locking
 	mrf.returnRangeInfo = returnRangeInfo
 	mrf.alloc = alloc
 	mrf.allEquivSignatures = make(map[string]int, len(tables))
@@ -355,7 +357,7 @@ func (mrf *MultiRowFetcher) StartScan(
 		firstBatchLimit++
 	}
 
-	f, err := makeKVFetcher(txn, spans, mrf.reverse, mrf.lockForUpdate, limitBatches, firstBatchLimit, mrf.returnRangeInfo)
+	f, err := makeKVFetcher(txn, spans, mrf.reverse, mrf.locking, limitBatches, firstBatchLimit, mrf.returnRangeInfo)
 	if err != nil {
 		return err
 	}
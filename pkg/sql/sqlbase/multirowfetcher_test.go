@@ -70,7 +70,7 @@ func initFetcher(
 		}
 	}
 
-	if err := fetcher.Init(fetcherArgs, reverseScan, false /*reverse*/, false /*lockForUpdate*/, alloc); err != nil {
+	if err := fetcher.Init(fetcherArgs, reverseScan, ScanLocking{}, false /*returnRangeInfo*/, alloc); err != nil {
 		return nil, err
 	}
 
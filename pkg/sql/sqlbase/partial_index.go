@@ -0,0 +1,322 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// IsPartial returns true if the index is a partial index, i.e. if it only
+// contains entries for the rows which satisfy its predicate.
+func (desc *IndexDescriptor) IsPartial() bool {
+	return desc.Predicate != ""
+}
+
+// MakeIndexPredicate validates the predicate of a partial index on the table
+// and returns its serialized form, to be stored in IndexDescriptor.Predicate.
+// The predicate must be a boolean expression which only references columns of
+// the table; it cannot contain subqueries, aggregate or window functions, or
+// impure functions.
+func (desc *TableDescriptor) MakeIndexPredicate(
+	expr tree.Expr, searchPath tree.SearchPath,
+) (string, error) {
+	var t transform.ExprTransformContext
+	if err := t.AssertNoAggregationOrWindowing(expr, "index predicates", searchPath); err != nil {
+		return "", err
+	}
+	if _, err := makeRowExpr(
+		desc, desc.Columns, expr, nil /* container */, types.Bool, "index predicate",
+	); err != nil {
+		return "", err
+	}
+	return tree.Serialize(expr), nil
+}
+
+// IndexPredicateColumnIDs returns the IDs of the columns referenced by the
+// predicate of the given partial index.
+func (desc *TableDescriptor) IndexPredicateColumnIDs(index *IndexDescriptor) ([]ColumnID, error) {
+	if !index.IsPartial() {
+		return nil, nil
+	}
+	return rowExprColumnIDs(desc, index.Predicate, "index predicate")
+}
+
+// rowExprColumnIDs returns the IDs of the columns of the table referenced by
+// the given serialized expression.
+func rowExprColumnIDs(desc *TableDescriptor, exprStr string, context string) ([]ColumnID, error) {
+	expr, err := parser.ParseExpr(exprStr)
+	if err != nil {
+		return nil, err
+	}
+	cols := allTableColumns(desc)
+	var res []ColumnID
+	var seen util.FastIntSet
+	_, err = tree.SimpleVisit(expr, func(expr tree.Expr) (error, bool, tree.Expr) {
+		colIdx, ok, err := resolveRowExprColumn(desc, cols, expr, context)
+		if err != nil || !ok {
+			return err, err == nil, expr
+		}
+		if id := cols[colIdx].ID; !seen.Contains(int(id)) {
+			seen.Add(int(id))
+			res = append(res, id)
+		}
+		return nil, false, expr
+	})
+	return res, err
+}
+
+// IndexPredicateExpr returns the typed predicate of the given partial index.
+// The column references are replaced with IndexedVars, linked to container,
+// whose indexes are ordinals in cols; cols must contain all the columns
+// referenced by the predicate.
+func (desc *TableDescriptor) IndexPredicateExpr(
+	index *IndexDescriptor, cols []ColumnDescriptor, container tree.IndexedVarContainer,
+) (tree.TypedExpr, error) {
+	expr, err := parser.ParseExpr(index.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	return makeRowExpr(desc, cols, expr, container, types.Bool, "index predicate")
+}
+
+// allTableColumns returns the columns of the table, including the columns
+// being added or dropped.
+func allTableColumns(desc *TableDescriptor) []ColumnDescriptor {
+	cols := desc.Columns
+	for _, m := range desc.Mutations {
+		if col := m.GetColumn(); col != nil {
+			if len(cols) == len(desc.Columns) {
+				cols = append([]ColumnDescriptor(nil), desc.Columns...)
+			}
+			cols = append(cols, *col)
+		}
+	}
+	return cols
+}
+
+// resolveRowExprColumn returns the ordinal in cols of the column referenced
+// by expr, if expr is a column reference.
+func resolveRowExprColumn(
+	desc *TableDescriptor, cols []ColumnDescriptor, expr tree.Expr, context string,
+) (colIdx int, ok bool, _ error) {
+	vBase, ok := expr.(tree.VarName)
+	if !ok {
+		return 0, false, nil
+	}
+	v, err := vBase.NormalizeVarName()
+	if err != nil {
+		return 0, false, err
+	}
+	c, ok := v.(*tree.ColumnItem)
+	if !ok {
+		return 0, false, pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+			"%s is not allowed in %s", v, context)
+	}
+	if c.TableName.TableName != "" && string(c.TableName.TableName) != desc.Name {
+		return 0, false, pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+			"column %q does not exist", tree.ErrString(c))
+	}
+	for i := range cols {
+		if cols[i].Name == string(c.ColumnName) {
+			return i, true, nil
+		}
+	}
+	return 0, false, pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+		"column %q does not exist", c.ColumnName)
+}
+
+// makeRowExpr type checks an expression evaluated on the rows of the table,
+// such as the predicate of a partial index. The column references are replaced
+// with IndexedVars, linked to container, whose indexes are ordinals in cols. If
+// container is nil, the expression can only be used for type checking. The
+// context describes the expression in error messages.
+func makeRowExpr(
+	desc *TableDescriptor,
+	cols []ColumnDescriptor,
+	expr tree.Expr,
+	container tree.IndexedVarContainer,
+	desired types.T,
+	context string,
+) (tree.TypedExpr, error) {
+	if container == nil {
+		container = &exprRow{cols: cols}
+	}
+	ivarHelper := tree.MakeIndexedVarHelper(container, len(cols))
+	expr, err := tree.SimpleVisit(expr, func(expr tree.Expr) (error, bool, tree.Expr) {
+		if _, ok := expr.(*tree.Subquery); ok {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"subqueries are not allowed in %s", context), false, expr
+		}
+		colIdx, ok, err := resolveRowExprColumn(desc, cols, expr, context)
+		if err != nil || !ok {
+			return err, err == nil, expr
+		}
+		return nil, false, ivarHelper.IndexedVar(colIdx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var typedExpr tree.TypedExpr
+	if desired == types.Any {
+		typedExpr, err = tree.TypeCheck(expr, &tree.SemaContext{}, desired)
+	} else {
+		typedExpr, err = tree.TypeCheckAndRequire(expr, &tree.SemaContext{}, desired, context)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var v impureFuncVisitor
+	tree.WalkExprConst(&v, typedExpr)
+	if v.impure != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"impure functions are not allowed in %s: %s", context, v.impure)
+	}
+	return typedExpr, nil
+}
+
+// impureFuncVisitor finds the impure function applications in an expression.
+type impureFuncVisitor struct {
+	impure *tree.FuncExpr
+}
+
+var _ tree.Visitor = &impureFuncVisitor{}
+
+func (v *impureFuncVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if f, ok := expr.(*tree.FuncExpr); ok && f.IsImpure() && v.impure == nil {
+		v.impure = f
+	}
+	return v.impure == nil, expr
+}
+
+func (*impureFuncVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// exprRow is the IndexedVarContainer for the expressions evaluated on the
+// rows of a table. It holds the row on which the expressions are evaluated;
+// the columns which are not part of the row are NULL.
+type exprRow struct {
+	cols   []ColumnDescriptor
+	colMap map[ColumnID]int
+	values []tree.Datum
+}
+
+var _ tree.IndexedVarContainer = &exprRow{}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (r *exprRow) IndexedVarEval(idx int, ctx *tree.EvalContext) (tree.Datum, error) {
+	if i, ok := r.colMap[r.cols[idx].ID]; ok {
+		return r.values[i].Eval(ctx)
+	}
+	return tree.DNull, nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (r *exprRow) IndexedVarResolvedType(idx int) types.T {
+	return r.cols[idx].Type.ToDatumType()
+}
+
+// IndexedVarFormat implements the tree.IndexedVarContainer interface.
+func (r *exprRow) IndexedVarFormat(buf *bytes.Buffer, f tree.FmtFlags, idx int) {
+	tree.FormatNode(buf, f, tree.Name(r.cols[idx].Name))
+}
+
+// PartialIndexPredicates evaluates the predicates of the partial indexes
+// among a list of indexes of a table.
+type PartialIndexPredicates struct {
+	row exprRow
+	// exprs contains the predicate of each index; it is nil for the indexes
+	// which are not partial.
+	exprs []tree.TypedExpr
+	// The predicates are immutable, so they don't depend on the session.
+	evalCtx tree.EvalContext
+}
+
+// MakePartialIndexPredicates parses the predicates of the partial indexes
+// among the given indexes of the table. It returns nil if none of the indexes
+// are partial.
+func MakePartialIndexPredicates(
+	desc *TableDescriptor, indexes []IndexDescriptor,
+) (*PartialIndexPredicates, error) {
+	var p *PartialIndexPredicates
+	for i := range indexes {
+		if !indexes[i].IsPartial() {
+			continue
+		}
+		if p == nil {
+			p = &PartialIndexPredicates{
+				row:   exprRow{cols: allTableColumns(desc)},
+				exprs: make([]tree.TypedExpr, len(indexes)),
+			}
+		}
+		expr, err := parser.ParseExpr(indexes[i].Predicate)
+		if err != nil {
+			return nil, err
+		}
+		pred, err := makeRowExpr(desc, p.row.cols, expr, &p.row, types.Bool, "index predicate")
+		if err != nil {
+			return nil, errors.Wrapf(err, "predicate of index %q", indexes[i].Name)
+		}
+		p.exprs[i] = pred
+	}
+	return p, nil
+}
+
+// Satisfied returns whether the row with the given values satisfies the
+// predicate of the i-th index; rows which don't must not have entries in the
+// index. It always returns true for indexes which are not partial. Like a
+// WHERE clause, a predicate which evaluates to NULL is not satisfied.
+func (p *PartialIndexPredicates) Satisfied(
+	i int, colMap map[ColumnID]int, values []tree.Datum,
+) (bool, error) {
+	if p == nil || p.exprs[i] == nil {
+		return true, nil
+	}
+	p.row.colMap = colMap
+	p.row.values = values
+	d, err := p.exprs[i].Eval(&p.evalCtx)
+	if err != nil {
+		return false, err
+	}
+	return d == tree.DBoolTrue, nil
+}
+
+// FilterIndexEntries clears the entries (as encoded by EncodeSecondaryIndexes)
+// of the indexes whose predicate the row doesn't satisfy. The cleared entries
+// have a nil Key.
+func (p *PartialIndexPredicates) FilterIndexEntries(
+	colMap map[ColumnID]int, values []tree.Datum, entries []IndexEntry,
+) error {
+	if p == nil {
+		return nil
+	}
+	for i := range entries {
+		ok, err := p.Satisfied(i, colMap, values)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			entries[i] = IndexEntry{}
+		}
+	}
+	return nil
+}
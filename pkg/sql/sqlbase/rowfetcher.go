@@ -52,7 +52,7 @@ type RowFetcher struct {
 	desc             *TableDescriptor
 	index            *IndexDescriptor
 	reverse          bool
-	lockForUpdate    bool
+	locking          ScanLocking
 	isSecondaryIndex bool
 	indexColumnDirs  []encoding.Direction
 
@@ -86,6 +86,19 @@ type RowFetcher struct {
 	// when beginning a new scan.
 	traceKV bool
 
+	// kvBatches is the number of KV batches sent by the scans started through
+	// StartScan.
+	kvBatches int64
+
+	// The following fields are used to lock rows when locking.ForUpdate is
+	// set; see LockRow. lockColIdx contains the index (into cols) of each
+	// primary key column.
+	txn           *client.Txn
+	lockKeyPrefix []byte
+	lockColIdx    []int
+	lockKeyTypes  []ColumnType
+	lockKeyVals   EncDatumRow
+
 	// -- Fields updated during a scan --
 
 	kvFetcher      kvFetcher
@@ -123,7 +136,9 @@ func (rf *RowFetcher) Init(
 	desc *TableDescriptor,
 	colIdxMap map[ColumnID]int,
 	index *IndexDescriptor,
-	reverse, lockForUpdate, isSecondaryIndex bool,
+	reverse bool,
+	locking ScanLocking,
+	isSecondaryIndex bool,
 	cols []ColumnDescriptor,
 	valNeededForCol []bool,
 	returnRangeInfo bool,
@@ -133,7 +148,7 @@ func (rf *RowFetcher) Init(
 	rf.colIdxMap = colIdxMap
 	rf.index = index
 	rf.reverse = reverse
-	rf.lockForUpdate = lockForUpdate
+	rf.locking = locking
 	rf.isSecondaryIndex = isSecondaryIndex
 	rf.cols = cols
 	rf.returnRangeInfo = returnRangeInfo
@@ -155,6 +170,14 @@ func (rf *RowFetcher) Init(
 		}
 	}
 
+	if locking.ForUpdate {
+		// Rows are locked through their primary key, so we need to decode it
+		// even if none of its columns is otherwise needed.
+		if err := rf.initLocking(); err != nil {
+			return err
+		}
+	}
+
 	// If there are interleaves, we need to read the index key in order to
 	// determine whether this row is actually part of the index we're scanning.
 	// If we need to return any values from the row, we also have to read the
@@ -207,6 +230,27 @@ func (rf *RowFetcher) Init(
 	return nil
 }
 
+func (rf *RowFetcher) initLocking() error {
+	pkIndex := &rf.desc.PrimaryIndex
+	rf.lockKeyPrefix = MakeIndexKeyPrefix(rf.desc, pkIndex.ID)
+	rf.lockColIdx = make([]int, len(pkIndex.ColumnIDs))
+	for i, id := range pkIndex.ColumnIDs {
+		idx, ok := rf.colIdxMap[id]
+		if !ok {
+			return errors.Errorf("primary key column %s not fetched", pkIndex.ColumnNames[i])
+		}
+		rf.lockColIdx[i] = idx
+		rf.neededCols.Add(int(id))
+	}
+	var err error
+	rf.lockKeyTypes, err = GetColumnTypes(rf.desc, pkIndex.ColumnIDs)
+	if err != nil {
+		return err
+	}
+	rf.lockKeyVals = make(EncDatumRow, len(pkIndex.ColumnIDs))
+	return nil
+}
+
 // StartScan initializes and starts the key-value scan. Can be used multiple
 // times.
 func (rf *RowFetcher) StartScan(
@@ -222,6 +266,7 @@ func (rf *RowFetcher) StartScan(
 	}
 
 	rf.traceKV = traceKV
+	rf.txn = txn
 
 	// If we have a limit hint, we limit the first batch size. Subsequent
 	// batches get larger to avoid making things too slow (e.g. in case we have
@@ -235,10 +280,11 @@ func (rf *RowFetcher) StartScan(
 		firstBatchLimit++
 	}
 
-	f, err := makeKVFetcher(txn, spans, rf.reverse, rf.lockForUpdate, limitBatches, firstBatchLimit, rf.returnRangeInfo)
+	f, err := makeKVFetcher(txn, spans, rf.reverse, rf.locking, limitBatches, firstBatchLimit, rf.returnRangeInfo)
 	if err != nil {
 		return err
 	}
+	f.batchCount = &rf.kvBatches
 	return rf.StartScanFrom(ctx, &f)
 }
 
@@ -642,12 +688,55 @@ func (rf *RowFetcher) finalizeRow() {
 	}
 }
 
+// LockRow acquires an exclusive lock on the row last returned by NextRow or
+// NextRowDecoded on behalf of the scan's transaction. It can only be used if
+// the RowFetcher was initialized with locking.ForUpdate.
+//
+// All the column families of the row are locked in the primary index, which
+// makes any concurrent write to the row wait for the transaction to finish.
+// The locks don't add new versions of the row (see engine.MVCCLock).
+func (rf *RowFetcher) LockRow(ctx context.Context) error {
+	if !rf.locking.ForUpdate {
+		return errors.Errorf("row locking not requested for index %s", rf.index.Name)
+	}
+	for i, idx := range rf.lockColIdx {
+		rf.lockKeyVals[i] = rf.row[idx]
+	}
+	key, err := MakeKeyFromEncDatums(
+		rf.lockKeyTypes, rf.lockKeyVals, rf.desc, &rf.desc.PrimaryIndex, rf.lockKeyPrefix, rf.alloc,
+	)
+	if err != nil {
+		return err
+	}
+	b := rf.txn.NewBatch()
+	b.Header.NoWait = rf.locking.NoWait
+	for _, family := range rf.desc.Families {
+		// Cap the key so that each family key gets its own copy.
+		familyKey := keys.MakeFamilyKey(key[:len(key):len(key)], uint32(family.ID))
+		if rf.traceKV {
+			log.VEventf(ctx, 2, "Lock %s", keys.PrettyPrint(familyKey))
+		}
+		b.AddRawRequest(roachpb.NewLock(familyKey))
+	}
+	rf.kvBatches++
+	if err := rf.txn.Run(ctx, b); err != nil {
+		return convertLockingError(err, rf.locking)
+	}
+	return nil
+}
+
 // Key returns the next key (the key that follows the last returned row).
 // Key returns nil when there are no more rows.
 func (rf *RowFetcher) Key() roachpb.Key {
 	return rf.kv.Key
 }
 
+// KVBatches returns the number of KV batches sent so far by the scans started
+// through StartScan.
+func (rf *RowFetcher) KVBatches() int64 {
+	return rf.kvBatches
+}
+
 // GetRangeInfo returns information about the ranges where the rows came from.
 // The RangeInfo's are deduped and not ordered.
 func (rf *RowFetcher) GetRangeInfo() []roachpb.RangeInfo {
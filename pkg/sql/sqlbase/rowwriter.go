@@ -43,12 +43,24 @@ type rowHelper struct {
 	Indexes      []IndexDescriptor
 	indexEntries []IndexEntry
 
+	// predicates evaluates the predicates of the partial indexes among
+	// Indexes; it is nil if there are none.
+	predicates *PartialIndexPredicates
+
 	// Computed and cached.
 	primaryIndexKeyPrefix []byte
 	primaryIndexCols      map[ColumnID]struct{}
 	sortedColumnFamilies  map[FamilyID][]ColumnID
 }
 
+func makeRowHelper(tableDesc *TableDescriptor, indexes []IndexDescriptor) (rowHelper, error) {
+	predicates, err := MakePartialIndexPredicates(tableDesc, indexes)
+	if err != nil {
+		return rowHelper{}, err
+	}
+	return rowHelper{TableDesc: tableDesc, Indexes: indexes, predicates: predicates}, nil
+}
+
 // encodeIndexes encodes the primary and secondary index keys. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
 // encodeSecondaryIndexes.
@@ -74,6 +86,9 @@ func (rh *rowHelper) encodeIndexes(
 // encodeSecondaryIndexes encodes the secondary index keys. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
 // encodeSecondaryIndexes.
+//
+// The entries of the partial indexes whose predicate the row doesn't satisfy
+// have a nil Key.
 func (rh *rowHelper) encodeSecondaryIndexes(
 	colIDtoRowIndex map[ColumnID]int, values []tree.Datum,
 ) (secondaryIndexEntries []IndexEntry, err error) {
@@ -85,6 +100,9 @@ func (rh *rowHelper) encodeSecondaryIndexes(
 	if err != nil {
 		return nil, err
 	}
+	if err := rh.predicates.FilterIndexEntries(colIDtoRowIndex, values, rh.indexEntries); err != nil {
+		return nil, err
+	}
 	return rh.indexEntries, nil
 }
 
@@ -144,7 +162,16 @@ type RowInserter struct {
 	InsertColIDtoRowIndex map[ColumnID]int
 	Fks                   fkInsertHelper
 
+	// The computed columns of the table are computed from the inserted values
+	// and written along with them. rowCols and rowColIDtoRowIndex describe the
+	// written rows: InsertCols followed by the computed columns which are not
+	// part of it.
+	computed           *ComputedColumns
+	rowCols            []ColumnDescriptor
+	rowColIDtoRowIndex map[ColumnID]int
+
 	// For allocation avoidance.
+	rowBuf     []tree.Datum
 	marshalled []roachpb.Value
 	key        roachpb.Key
 	valueBuf   []byte
@@ -152,9 +179,30 @@ type RowInserter struct {
 	value      roachpb.Value
 }
 
+// writableComputedColumns returns the computed columns of the table which are
+// written along with the rows, including the columns being added in the
+// DELETE_AND_WRITE_ONLY state.
+func writableComputedColumns(tableDesc *TableDescriptor) []ColumnDescriptor {
+	var cols []ColumnDescriptor
+	for _, col := range tableDesc.Columns {
+		if col.IsComputed() {
+			cols = append(cols, col)
+		}
+	}
+	for _, m := range tableDesc.Mutations {
+		if col := m.GetColumn(); col != nil && col.IsComputed() &&
+			m.State == DescriptorMutation_DELETE_AND_WRITE_ONLY {
+			cols = append(cols, *col)
+		}
+	}
+	return cols
+}
+
 // MakeRowInserter creates a RowInserter for the given table.
 //
-// insertCols must contain every column in the primary key.
+// insertCols must contain every column in the primary key. The values of the
+// computed columns of the table don't need to be provided: they are computed
+// from the inserted values.
 func MakeRowInserter(
 	txn *client.Txn,
 	tableDesc *TableDescriptor,
@@ -174,12 +222,30 @@ func MakeRowInserter(
 		}
 	}
 
+	helper, err := makeRowHelper(tableDesc, indexes)
+	if err != nil {
+		return RowInserter{}, err
+	}
 	ri := RowInserter{
-		Helper:                rowHelper{TableDesc: tableDesc, Indexes: indexes},
+		Helper:                helper,
 		InsertCols:            insertCols,
 		InsertColIDtoRowIndex: ColIDtoRowIndexFromCols(insertCols),
-		marshalled:            make([]roachpb.Value, len(insertCols)),
 	}
+	ri.rowCols, ri.rowColIDtoRowIndex = ri.InsertCols, ri.InsertColIDtoRowIndex
+	if computedCols := writableComputedColumns(tableDesc); len(computedCols) > 0 {
+		if ri.computed, err = MakeComputedColumns(tableDesc, computedCols); err != nil {
+			return RowInserter{}, err
+		}
+		ri.rowCols = insertCols[:len(insertCols):len(insertCols)]
+		for _, col := range computedCols {
+			if _, ok := ri.InsertColIDtoRowIndex[col.ID]; !ok {
+				ri.rowCols = append(ri.rowCols, col)
+			}
+		}
+		ri.rowColIDtoRowIndex = ColIDtoRowIndexFromCols(ri.rowCols)
+		ri.rowBuf = make([]tree.Datum, len(ri.rowCols))
+	}
+	ri.marshalled = make([]roachpb.Value, len(ri.rowCols))
 
 	for i, col := range tableDesc.PrimaryIndex.ColumnIDs {
 		if _, ok := ri.InsertColIDtoRowIndex[col]; !ok {
@@ -188,7 +254,6 @@ func MakeRowInserter(
 	}
 
 	if checkFKs {
-		var err error
 		if ri.Fks, err = makeFKInsertHelper(txn, *tableDesc, fkTables,
 			ri.InsertColIDtoRowIndex, alloc); err != nil {
 			return ri, err
@@ -225,6 +290,23 @@ type putter interface {
 	Put(key, value interface{})
 }
 
+// rowValues returns the values of the row which is written for the given
+// inserted values, including the values of the computed columns. The returned
+// slice is only valid until the next call to rowValues.
+func (ri *RowInserter) rowValues(values []tree.Datum) ([]tree.Datum, error) {
+	if ri.computed == nil {
+		return values, nil
+	}
+	n := copy(ri.rowBuf, values)
+	for i := n; i < len(ri.rowBuf); i++ {
+		ri.rowBuf[i] = tree.DNull
+	}
+	if err := ri.computed.Eval(ri.rowColIDtoRowIndex, ri.rowBuf); err != nil {
+		return nil, err
+	}
+	return ri.rowBuf, nil
+}
+
 // InsertRow adds to the batch the kv operations necessary to insert a table row
 // with the given values.
 func (ri *RowInserter) InsertRow(
@@ -233,6 +315,10 @@ func (ri *RowInserter) InsertRow(
 	if len(values) != len(ri.InsertCols) {
 		return errors.Errorf("got %d values but expected %d", len(values), len(ri.InsertCols))
 	}
+	values, err := ri.rowValues(values)
+	if err != nil {
+		return err
+	}
 
 	putFn := insertCPutFn
 	if ignoreConflicts {
@@ -244,8 +330,7 @@ func (ri *RowInserter) InsertRow(
 	// cannot be used as index values.
 	for i, val := range values {
 		// Make sure the value can be written to the column before proceeding.
-		var err error
-		if ri.marshalled[i], err = MarshalColumnValue(ri.InsertCols[i], val); err != nil {
+		if ri.marshalled[i], err = MarshalColumnValue(ri.rowCols[i], val); err != nil {
 			return err
 		}
 	}
@@ -254,7 +339,7 @@ func (ri *RowInserter) InsertRow(
 		return err
 	}
 
-	primaryIndexKey, secondaryIndexEntries, err := ri.Helper.encodeIndexes(ri.InsertColIDtoRowIndex, values)
+	primaryIndexKey, secondaryIndexEntries, err := ri.Helper.encodeIndexes(ri.rowColIDtoRowIndex, values)
 	if err != nil {
 		return err
 	}
@@ -275,7 +360,7 @@ func (ri *RowInserter) InsertRow(
 			// Storage optimization to store DefaultColumnID directly as a value. Also
 			// backwards compatible with the original BaseFormatVersion.
 
-			idx, ok := ri.InsertColIDtoRowIndex[family.DefaultColumnID]
+			idx, ok := ri.rowColIDtoRowIndex[family.DefaultColumnID]
 			if !ok {
 				continue
 			}
@@ -302,7 +387,7 @@ func (ri *RowInserter) InsertRow(
 			panic("invalid family sorted column id map")
 		}
 		for _, colID := range familySortedColumnIDs {
-			idx, ok := ri.InsertColIDtoRowIndex[colID]
+			idx, ok := ri.rowColIDtoRowIndex[colID]
 			if !ok || values[idx] == tree.DNull {
 				// Column not being inserted.
 				continue
@@ -314,7 +399,7 @@ func (ri *RowInserter) InsertRow(
 				continue
 			}
 
-			col := ri.InsertCols[idx]
+			col := ri.rowCols[idx]
 
 			if lastColID > col.ID {
 				panic(fmt.Errorf("cannot write column id %d after %d", col.ID, lastColID))
@@ -337,6 +422,10 @@ func (ri *RowInserter) InsertRow(
 
 	for i := range secondaryIndexEntries {
 		e := &secondaryIndexEntries[i]
+		if e.Key == nil {
+			// The row is not part of this partial index.
+			continue
+		}
 		putFn(ctx, b, &e.Key, &e.Value, traceKV)
 	}
 
@@ -345,11 +434,15 @@ func (ri *RowInserter) InsertRow(
 
 // EncodeIndexesForRow encodes the provided values into their primary and
 // secondary index keys. The secondaryIndexEntries are only valid until the next
-// call to EncodeIndexesForRow.
+// call to EncodeIndexesForRow. The entries of the partial indexes which don't
+// contain the row have a nil Key.
 func (ri *RowInserter) EncodeIndexesForRow(
 	values []tree.Datum,
 ) (primaryIndexKey []byte, secondaryIndexEntries []IndexEntry, err error) {
-	return ri.Helper.encodeIndexes(ri.InsertColIDtoRowIndex, values)
+	if values, err = ri.rowValues(values); err != nil {
+		return nil, nil, err
+	}
+	return ri.Helper.encodeIndexes(ri.rowColIDtoRowIndex, values)
 }
 
 // RowUpdater abstracts the key/value operations for updating table rows.
@@ -362,6 +455,13 @@ type RowUpdater struct {
 	deleteOnlyIndex       map[int]struct{}
 	primaryKeyColChange   bool
 
+	// The computed columns of the table which depend on the updated columns
+	// are recomputed and written along with them. updateCols contains
+	// UpdateCols followed by the computed columns which are not part of it;
+	// updateColIDtoRowIndex refers to updateCols.
+	computed   *ComputedColumns
+	updateCols []ColumnDescriptor
+
 	// rd and ri are used when the update this RowUpdater is created for modifies
 	// the primary key of the table. In that case, rows must be deleted and
 	// re-added instead of merely updated, since the keys are changing.
@@ -373,6 +473,7 @@ type RowUpdater struct {
 	// For allocation avoidance.
 	marshalled      []roachpb.Value
 	newValues       []tree.Datum
+	updateValues    []tree.Datum
 	key             roachpb.Key
 	indexEntriesBuf []IndexEntry
 	valueBuf        []byte
@@ -399,6 +500,9 @@ const (
 // The returned RowUpdater contains a FetchCols field that defines the
 // expectation of which values are passed as oldValues to UpdateRow. Any column
 // passed in requestedCols will be included in FetchCols.
+//
+// The computed columns which depend on UpdateCols are recomputed; their new
+// values don't need to be provided.
 func MakeRowUpdater(
 	txn *client.Txn,
 	tableDesc *TableDescriptor,
@@ -408,8 +512,40 @@ func MakeRowUpdater(
 	updateType rowUpdaterType,
 	alloc *DatumAlloc,
 ) (RowUpdater, error) {
+	callerUpdateCols := updateCols
+	updateCols = updateCols[:len(updateCols):len(updateCols)]
 	updateColIDtoRowIndex := ColIDtoRowIndexFromCols(updateCols)
 
+	// Find the computed columns which need recomputing. They need to be
+	// fetched, along with the columns they are computed from.
+	var computedCols []ColumnDescriptor
+	var computedFetchCols []ColumnID
+	for _, col := range writableComputedColumns(tableDesc) {
+		deps, err := tableDesc.ComputedColumnDeps(&col)
+		if err != nil {
+			return RowUpdater{}, err
+		}
+		_, recompute := updateColIDtoRowIndex[col.ID]
+		for _, id := range deps {
+			if _, ok := updateColIDtoRowIndex[id]; ok {
+				recompute = true
+			}
+		}
+		if !recompute {
+			continue
+		}
+		computedCols = append(computedCols, col)
+		computedFetchCols = append(append(computedFetchCols, col.ID), deps...)
+		if _, ok := updateColIDtoRowIndex[col.ID]; !ok {
+			updateColIDtoRowIndex[col.ID] = len(updateCols)
+			updateCols = append(updateCols, col)
+		}
+	}
+	computed, err := MakeComputedColumns(tableDesc, computedCols)
+	if err != nil {
+		return RowUpdater{}, err
+	}
+
 	primaryIndexCols := make(map[ColumnID]struct{}, len(tableDesc.PrimaryIndex.ColumnIDs))
 	for _, colID := range tableDesc.PrimaryIndex.ColumnIDs {
 		primaryIndexCols[colID] = struct{}{}
@@ -423,6 +559,35 @@ func MakeRowUpdater(
 		}
 	}
 
+	// The partial indexes whose predicate references an updated column need
+	// updating too, as the updated rows can move in or out of them.
+	updatesPredicate := make(map[IndexID]bool)
+	checkPredicate := func(index *IndexDescriptor) error {
+		colIDs, err := tableDesc.IndexPredicateColumnIDs(index)
+		if err != nil {
+			return err
+		}
+		for _, id := range colIDs {
+			if _, ok := updateColIDtoRowIndex[id]; ok {
+				updatesPredicate[index.ID] = true
+				break
+			}
+		}
+		return nil
+	}
+	for i := range tableDesc.Indexes {
+		if err := checkPredicate(&tableDesc.Indexes[i]); err != nil {
+			return RowUpdater{}, err
+		}
+	}
+	for _, m := range tableDesc.Mutations {
+		if index := m.GetIndex(); index != nil {
+			if err := checkPredicate(index); err != nil {
+				return RowUpdater{}, err
+			}
+		}
+	}
+
 	// Secondary indexes needing updating.
 	needsUpdate := func(index IndexDescriptor) bool {
 		if updateType == RowUpdaterOnlyColumns {
@@ -438,7 +603,7 @@ func MakeRowUpdater(
 				return returnTruePseudoError
 			}
 			return nil
-		}) != nil
+		}) != nil || updatesPredicate[index.ID]
 	}
 
 	indexes := make([]IndexDescriptor, 0, len(tableDesc.Indexes)+len(tableDesc.Mutations))
@@ -477,21 +642,27 @@ func MakeRowUpdater(
 		}
 	}
 
+	helper, err := makeRowHelper(tableDesc, indexes)
+	if err != nil {
+		return RowUpdater{}, err
+	}
 	ru := RowUpdater{
-		Helper:                rowHelper{TableDesc: tableDesc, Indexes: indexes},
-		UpdateCols:            updateCols,
+		Helper:                helper,
+		UpdateCols:            callerUpdateCols,
 		updateColIDtoRowIndex: updateColIDtoRowIndex,
 		deleteOnlyIndex:       deleteOnlyIndex,
 		primaryKeyColChange:   primaryKeyColChange,
+		computed:              computed,
+		updateCols:            updateCols,
 		marshalled:            make([]roachpb.Value, len(updateCols)),
 		newValues:             make([]tree.Datum, len(tableCols)),
+		updateValues:          make([]tree.Datum, len(updateCols)),
 	}
 
 	if primaryKeyColChange {
 		// These fields are only used when the primary key is changing.
 		// When changing the primary key, we delete the old values and reinsert
 		// them, so request them all.
-		var err error
 		if ru.rd, err = MakeRowDeleter(txn, tableDesc, fkTables,
 			tableCols, SkipFKs, alloc); err != nil {
 			return RowUpdater{}, err
@@ -538,14 +709,30 @@ func MakeRowUpdater(
 				}
 			}
 		}
+		// The computed columns are recomputed from the new row.
+		for _, colID := range computedFetchCols {
+			if err := maybeAddCol(colID); err != nil {
+				return RowUpdater{}, err
+			}
+		}
 		for _, index := range indexes {
 			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
 				return RowUpdater{}, err
 			}
+			// The columns of the predicate of a partial index are needed to
+			// determine whether the old and new rows are part of the index.
+			colIDs, err := tableDesc.IndexPredicateColumnIDs(&index)
+			if err != nil {
+				return RowUpdater{}, err
+			}
+			for _, colID := range colIDs {
+				if err := maybeAddCol(colID); err != nil {
+					return RowUpdater{}, err
+				}
+			}
 		}
 	}
 
-	var err error
 	if ru.Fks, err = makeFKUpdateHelper(txn, *tableDesc, fkTables,
 		ru.FetchColIDtoRowIndex, alloc); err != nil {
 		return RowUpdater{}, err
@@ -585,21 +772,30 @@ func (ru *RowUpdater) UpdateRow(
 	secondaryIndexEntries = append(ru.indexEntriesBuf[:0], secondaryIndexEntries...)
 	ru.indexEntriesBuf = secondaryIndexEntries
 
+	// Update the row values, and recompute the computed columns.
+	copy(ru.newValues, oldValues)
+	for i, updateCol := range ru.UpdateCols {
+		ru.newValues[ru.FetchColIDtoRowIndex[updateCol.ID]] = updateValues[i]
+	}
+	if ru.computed != nil {
+		if err := ru.computed.Eval(ru.FetchColIDtoRowIndex, ru.newValues); err != nil {
+			return nil, err
+		}
+		for i, updateCol := range ru.updateCols {
+			ru.updateValues[i] = ru.newValues[ru.FetchColIDtoRowIndex[updateCol.ID]]
+		}
+		updateValues = ru.updateValues
+	}
+
 	// Check that the new value types match the column types. This needs to
 	// happen before index encoding because certain datum types (i.e. tuple)
 	// cannot be used as index values.
 	for i, val := range updateValues {
-		if ru.marshalled[i], err = MarshalColumnValue(ru.UpdateCols[i], val); err != nil {
+		if ru.marshalled[i], err = MarshalColumnValue(ru.updateCols[i], val); err != nil {
 			return nil, err
 		}
 	}
 
-	// Update the row values.
-	copy(ru.newValues, oldValues)
-	for i, updateCol := range ru.UpdateCols {
-		ru.newValues[ru.FetchColIDtoRowIndex[updateCol.ID]] = updateValues[i]
-	}
-
 	rowPrimaryKeyChanged := false
 	var newSecondaryIndexEntries []IndexEntry
 	if ru.primaryKeyColChange {
@@ -743,7 +939,8 @@ func (ru *RowUpdater) UpdateRow(
 		ru.key = nil
 	}
 
-	// Update secondary indexes.
+	// Update secondary indexes. A nil key means that the old or new row is not
+	// part of the (partial) index.
 	for i, newSecondaryIndexEntry := range newSecondaryIndexEntries {
 		secondaryIndexEntry := secondaryIndexEntries[i]
 		var expValue interface{}
@@ -752,10 +949,19 @@ func (ru *RowUpdater) UpdateRow(
 				return nil, err
 			}
 
-			if traceKV {
-				log.VEventf(ctx, 2, "Del %s", secondaryIndexEntry.Key)
+			if secondaryIndexEntry.Key != nil {
+				if traceKV {
+					log.VEventf(ctx, 2, "Del %s", secondaryIndexEntry.Key)
+				}
+				b.Del(secondaryIndexEntry.Key)
 			}
-			b.Del(secondaryIndexEntry.Key)
+			if newSecondaryIndexEntry.Key == nil {
+				// The row moved out of the partial index.
+				continue
+			}
+		} else if newSecondaryIndexEntry.Key == nil {
+			// The row is not part of the partial index.
+			continue
 		} else if !bytes.Equal(newSecondaryIndexEntry.Value.RawBytes, secondaryIndexEntry.Value.RawBytes) {
 			expValue = &secondaryIndexEntry.Value
 		} else {
@@ -850,15 +1056,29 @@ func MakeRowDeleter(
 				return RowDeleter{}, err
 			}
 		}
+		// The predicate columns are needed to determine whether the row is part
+		// of a partial index.
+		colIDs, err := tableDesc.IndexPredicateColumnIDs(&index)
+		if err != nil {
+			return RowDeleter{}, err
+		}
+		for _, colID := range colIDs {
+			if err := maybeAddCol(colID); err != nil {
+				return RowDeleter{}, err
+			}
+		}
 	}
 
+	helper, err := makeRowHelper(tableDesc, indexes)
+	if err != nil {
+		return RowDeleter{}, err
+	}
 	rd := RowDeleter{
-		Helper:               rowHelper{TableDesc: tableDesc, Indexes: indexes},
+		Helper:               helper,
 		FetchCols:            fetchCols,
 		FetchColIDtoRowIndex: fetchColIDtoRowIndex,
 	}
 	if checkFKs {
-		var err error
 		if rd.Fks, err = makeFKDeleteHelper(txn, *tableDesc, fkTables,
 			fetchColIDtoRowIndex, alloc); err != nil {
 			return RowDeleter{}, err
@@ -883,6 +1103,10 @@ func (rd *RowDeleter) DeleteRow(
 	}
 
 	for _, secondaryIndexEntry := range secondaryIndexEntries {
+		if secondaryIndexEntry.Key == nil {
+			// The row is not part of this partial index.
+			continue
+		}
 		if traceKV {
 			log.VEventf(ctx, 2, "Del %s", secondaryIndexEntry.Key)
 		}
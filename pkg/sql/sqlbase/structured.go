@@ -216,6 +216,10 @@ func (desc *IndexDescriptor) FillColumns(elems tree.IndexElemList) error {
 	desc.ColumnNames = make([]string, 0, len(elems))
 	desc.ColumnDirections = make([]IndexDescriptor_Direction, 0, len(elems))
 	for _, c := range elems {
+		if c.Expr != nil {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"index expression %s is not supported here", c.Expr)
+		}
 		desc.ColumnNames = append(desc.ColumnNames, string(c.Column))
 		switch c.Direction {
 		case tree.Ascending, tree.DefaultDirection:
@@ -286,11 +290,39 @@ func (desc *IndexDescriptor) ColNamesString() string {
 	return buf.String()
 }
 
+// IndexColNamesString returns the column names of the index, like
+// ColNamesString, except that the hidden computed columns of expression
+// indexes are shown as their expressions.
+func (desc *TableDescriptor) IndexColNamesString(idx *IndexDescriptor) string {
+	var buf bytes.Buffer
+	for i, name := range idx.ColumnNames {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if col, err := desc.FindColumnByID(idx.ColumnIDs[i]); err == nil && col.IsIndexExpr() {
+			fmt.Fprintf(&buf, "(%s) %s", *col.ComputeExpr, idx.ColumnDirections[i])
+			continue
+		}
+		fmt.Fprintf(&buf, "%s %s", tree.Name(name), idx.ColumnDirections[i])
+	}
+	return buf.String()
+}
+
 var isUnique = map[bool]string{true: "UNIQUE "}
 
 // SQLString returns the SQL string describing this index. If non-empty,
 // "ON tableName" is included in the output in the correct place.
 func (desc *IndexDescriptor) SQLString(tableName string) string {
+	return desc.sqlString(tableName, desc.ColNamesString())
+}
+
+// IndexSQLString returns the SQL string describing the index of the table,
+// showing the expressions of expression indexes.
+func (desc *TableDescriptor) IndexSQLString(idx *IndexDescriptor) string {
+	return idx.sqlString("", desc.IndexColNamesString(idx))
+}
+
+func (desc *IndexDescriptor) sqlString(tableName, colNames string) string {
 	var storing string
 	if len(desc.StoreColumnNames) > 0 {
 		colNames := make(tree.NameList, len(desc.StoreColumnNames))
@@ -307,7 +339,7 @@ func (desc *IndexDescriptor) SQLString(tableName string) string {
 		isUnique[desc.Unique],
 		onTable,
 		tree.AsString(tree.Name(desc.Name)),
-		desc.ColNamesString(),
+		colNames,
 		storing,
 	)
 }
@@ -345,6 +377,52 @@ func (desc *TableDescriptor) IsView() bool {
 	return desc.ViewQuery != ""
 }
 
+// MaterializedView returns true if the TableDescriptor describes a
+// materialized View, whose results are stored like a Table's rows.
+func (desc *TableDescriptor) MaterializedView() bool {
+	return desc.IsView() && desc.IsMaterializedView
+}
+
+// MakeMaterializedViewRefresh allocates the indexes to which a refresh of the
+// materialized view writes its new results: copies of the indexes of the view
+// with new IDs.
+func (desc *TableDescriptor) MakeMaterializedViewRefresh(
+	jobID int64,
+) *TableDescriptor_MaterializedViewRefresh {
+	newIndex := func(idx IndexDescriptor) IndexDescriptor {
+		idx.ID = desc.NextIndexID
+		desc.NextIndexID++
+		return idx
+	}
+	r := &TableDescriptor_MaterializedViewRefresh{
+		NewPrimaryIndex: newIndex(desc.PrimaryIndex),
+		JobID:           jobID,
+	}
+	for _, idx := range desc.Indexes {
+		r.NewIndexes = append(r.NewIndexes, newIndex(idx))
+	}
+	return r
+}
+
+// SwapMaterializedViewIndexes replaces the indexes of a materialized view with
+// those of its refresh in progress, which then holds the previous indexes.
+// The references to the indexes of the view are updated.
+func (desc *TableDescriptor) SwapMaterializedViewIndexes() {
+	r := desc.Refresh
+	newIDs := map[IndexID]IndexID{desc.PrimaryIndex.ID: r.NewPrimaryIndex.ID}
+	for i := range desc.Indexes {
+		newIDs[desc.Indexes[i].ID] = r.NewIndexes[i].ID
+	}
+	for i := range desc.DependedOnBy {
+		if id, ok := newIDs[desc.DependedOnBy[i].IndexID]; ok {
+			desc.DependedOnBy[i].IndexID = id
+		}
+	}
+	desc.PrimaryIndex, r.NewPrimaryIndex = r.NewPrimaryIndex, desc.PrimaryIndex
+	desc.Indexes, r.NewIndexes = r.NewIndexes, desc.Indexes
+	r.Swapped = true
+}
+
 // IsVirtualTable returns true if the TableDescriptor describes a
 // virtual Table (like the information_schema tables) and thus doesn't
 // need to be physically stored.
@@ -356,8 +434,9 @@ func (desc *TableDescriptor) IsVirtualTable() bool {
 // physical Table that needs to be stored in the kv layer, as opposed to a
 // different resource like a view or a virtual table. Physical tables have
 // primary keys, column families, and indexes (unlike virtual tables).
+// Materialized views are stored like physical tables.
 func (desc *TableDescriptor) IsPhysicalTable() bool {
-	return desc.IsTable() && !desc.IsVirtualTable()
+	return (desc.IsTable() || desc.MaterializedView()) && !desc.IsVirtualTable()
 }
 
 // KeysPerRow returns the maximum number of keys used to encode a row for the
@@ -588,7 +667,12 @@ func HasCompositeKeyEncoding(semanticType ColumnType_SemanticType) bool {
 	switch semanticType {
 	case ColumnType_COLLATEDSTRING,
 		ColumnType_FLOAT,
-		ColumnType_DECIMAL:
+		ColumnType_DECIMAL,
+		// Arrays are composite when any of their elements are. In particular,
+		// the arrays of collated strings that have non-NULL elements, which
+		// can't be decoded from their keys, are always decoded from the
+		// composite values.
+		ColumnType_ARRAY:
 		return true
 	}
 	return false
@@ -602,9 +686,9 @@ func DatumTypeHasCompositeKeyEncoding(typ types.T) bool {
 }
 
 // MustBeValueEncoded returns true if columns of the given kind can only be value
-// encoded.
+// encoded. Columns of every kind currently have a key encoding.
 func MustBeValueEncoded(semanticType ColumnType_SemanticType) bool {
-	return semanticType == ColumnType_ARRAY
+	return false
 }
 
 // HasOldStoredColumns returns whether the index has stored columns in the old
@@ -846,6 +930,20 @@ func (desc *TableDescriptor) validateCrossReferences(ctx context.Context, txn *c
 		if !res.Exists() {
 			return errors.Errorf("parentID %d does not exist", desc.ParentID)
 		}
+		if desc.ParentSchemaID != 0 && !desc.Dropped() {
+			var parent Descriptor
+			if err := res.ValueProto(&parent); err != nil {
+				return err
+			}
+			db := parent.GetDatabase()
+			if db == nil {
+				return errors.Errorf("parentID %d is not a database", desc.ParentID)
+			}
+			if _, ok := db.FindSchemaByID(desc.ParentSchemaID); !ok {
+				return errors.Errorf("parentSchemaID %d does not exist in database %q",
+					desc.ParentSchemaID, db.Name)
+			}
+		}
 	}
 
 	tablesByID := map[ID]*TableDescriptor{desc.ID: desc}
@@ -1085,12 +1183,42 @@ func (desc *TableDescriptor) ValidateTable() error {
 		if err := desc.validatePartitioning(); err != nil {
 			return err
 		}
+		if err := desc.validateRefresh(); err != nil {
+			return err
+		}
 	}
 
 	// Validate the privilege descriptor.
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+// validateRefresh validates the refresh of the materialized view in progress,
+// if any: its indexes must match the indexes of the view, with unused IDs.
+func (desc *TableDescriptor) validateRefresh() error {
+	r := desc.Refresh
+	if r == nil {
+		return nil
+	}
+	if !desc.MaterializedView() {
+		return fmt.Errorf("%q is refreshed but is not a materialized view", desc.Name)
+	}
+	if len(r.NewIndexes) != len(desc.Indexes) {
+		return fmt.Errorf("mismatched refresh indexes (%d) and indexes (%d)",
+			len(r.NewIndexes), len(desc.Indexes))
+	}
+	indexIDs := map[IndexID]struct{}{}
+	for _, index := range desc.AllNonDropIndexes() {
+		indexIDs[index.ID] = struct{}{}
+	}
+	for _, index := range append([]IndexDescriptor{r.NewPrimaryIndex}, r.NewIndexes...) {
+		if _, ok := indexIDs[index.ID]; ok || index.ID == 0 || index.ID >= desc.NextIndexID {
+			return fmt.Errorf("refresh index %q has invalid ID %d", index.Name, index.ID)
+		}
+		indexIDs[index.ID] = struct{}{}
+	}
+	return nil
+}
+
 func (desc *TableDescriptor) validateColumnFamilies(
 	columnIDs map[ColumnID]string,
 ) (map[ColumnID]FamilyID, error) {
@@ -1546,13 +1674,16 @@ func upperBoundColumnValueEncodedSize(col ColumnDescriptor) (int, bool) {
 	case ColumnType_BOOL:
 		typ = encoding.True
 	case ColumnType_INT, ColumnType_DATE, ColumnType_TIMESTAMP,
-		ColumnType_TIMESTAMPTZ, ColumnType_OID:
+		ColumnType_TIMESTAMPTZ, ColumnType_OID, ColumnType_TIME:
 		typ, size = encoding.Int, int(col.Type.Width)
+	case ColumnType_TIMETZ:
+		typ = encoding.TimeTZ
 	case ColumnType_FLOAT:
 		typ = encoding.Float
 	case ColumnType_INTERVAL:
 		typ = encoding.Duration
-	case ColumnType_STRING, ColumnType_BYTES, ColumnType_COLLATEDSTRING, ColumnType_NAME, ColumnType_UUID, ColumnType_INET:
+	case ColumnType_STRING, ColumnType_BYTES, ColumnType_COLLATEDSTRING, ColumnType_NAME, ColumnType_UUID, ColumnType_INET,
+		ColumnType_ENUM:
 		// STRINGs are counted as runes, so this isn't totally correct, but this
 		// seems better than always assuming the maximum rune width.
 		typ, size = encoding.Bytes, int(col.Type.Width)
@@ -2096,6 +2227,8 @@ func colTypeSQLString(c *ColumnType, semType ColumnType_SemanticType) string {
 		}
 	case ColumnType_TIMESTAMPTZ:
 		return "TIMESTAMP WITH TIME ZONE"
+	case ColumnType_TIMETZ:
+		return "TIME WITH TIME ZONE"
 	case ColumnType_COLLATEDSTRING:
 		if c.Locale == nil {
 			panic("locale is required for COLLATEDSTRING")
@@ -2106,6 +2239,8 @@ func colTypeSQLString(c *ColumnType, semType ColumnType_SemanticType) string {
 		return fmt.Sprintf("%s COLLATE %s", ColumnType_STRING.String(), *c.Locale)
 	case ColumnType_ARRAY:
 		return colTypeSQLString(c, *c.ArrayContents) + "[]"
+	case ColumnType_ENUM:
+		return tree.AsString(tree.Name(c.EnumTypeName))
 	}
 	if c.VisibleType != ColumnType_NONE {
 		return c.VisibleType.String()
@@ -2202,6 +2337,10 @@ func DatumTypeToColumnSemanticType(ptyp types.T) (ColumnType_SemanticType, error
 		return ColumnType_TIMESTAMP, nil
 	case types.TimestampTZ:
 		return ColumnType_TIMESTAMPTZ, nil
+	case types.Time:
+		return ColumnType_TIME, nil
+	case types.TimeTZ:
+		return ColumnType_TIMETZ, nil
 	case types.Interval:
 		return ColumnType_INTERVAL, nil
 	case types.UUID:
@@ -2218,6 +2357,9 @@ func DatumTypeToColumnSemanticType(ptyp types.T) (ColumnType_SemanticType, error
 		if ptyp.FamilyEqual(types.FamCollatedString) {
 			return ColumnType_COLLATEDSTRING, nil
 		}
+		if ptyp.FamilyEqual(types.FamEnum) {
+			return ColumnType_ENUM, nil
+		}
 		return -1, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError, "unsupported result type: %s", ptyp)
 	}
 }
@@ -2240,6 +2382,17 @@ func DatumTypeToColumnType(ptyp types.T) (ColumnType, error) {
 			cs := t.Typ.(types.TCollatedString)
 			ctyp.Locale = &cs.Locale
 		}
+	case types.TEnum:
+		if t.Values == nil {
+			return ColumnType{}, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"unsupported result type: %s", ptyp)
+		}
+		ctyp.SemanticType = ColumnType_ENUM
+		ctyp.EnumTypeID = ID(t.ID)
+		ctyp.EnumTypeName = t.Name
+		ctyp.EnumLabels = t.Values.Labels
+		ctyp.EnumPhysicalReps = t.Values.PhysicalReps
+		ctyp.EnumAddingLabels = t.Values.AddingLabels
 	default:
 		semanticType, err := DatumTypeToColumnSemanticType(ptyp)
 		if err != nil {
@@ -2270,6 +2423,10 @@ func columnSemanticTypeToDatumType(c *ColumnType, k ColumnType_SemanticType) typ
 		return types.Timestamp
 	case ColumnType_TIMESTAMPTZ:
 		return types.TimestampTZ
+	case ColumnType_TIME:
+		return types.Time
+	case ColumnType_TIMETZ:
+		return types.TimeTZ
 	case ColumnType_INTERVAL:
 		return types.Interval
 	case ColumnType_UUID:
@@ -2289,6 +2446,16 @@ func columnSemanticTypeToDatumType(c *ColumnType, k ColumnType_SemanticType) typ
 		return types.Null
 	case ColumnType_INT2VECTOR:
 		return types.IntVector
+	case ColumnType_ENUM:
+		return types.TEnum{
+			ID:   uint32(c.EnumTypeID),
+			Name: c.EnumTypeName,
+			Values: &types.EnumValues{
+				Labels:       c.EnumLabels,
+				PhysicalReps: c.EnumPhysicalReps,
+				AddingLabels: c.EnumAddingLabels,
+			},
+		}
 	}
 	return nil
 }
@@ -2329,10 +2496,160 @@ func (desc *DatabaseDescriptor) Validate() error {
 	if desc.ID == 0 {
 		return fmt.Errorf("invalid database ID %d", desc.ID)
 	}
+	schemaNames := make(map[string]struct{}, len(desc.Schemas))
+	for i := range desc.Schemas {
+		schema := &desc.Schemas[i]
+		if err := schema.Validate(); err != nil {
+			return err
+		}
+		if _, ok := schemaNames[schema.Name]; ok {
+			return fmt.Errorf("duplicate schema name: %q", schema.Name)
+		}
+		schemaNames[schema.Name] = struct{}{}
+	}
+	typeNames := make(map[string]struct{}, len(desc.Types))
+	for i := range desc.Types {
+		typ := &desc.Types[i]
+		if err := typ.Validate(); err != nil {
+			return err
+		}
+		if _, ok := typeNames[typ.Name]; ok {
+			return fmt.Errorf("duplicate type name: %q", typ.Name)
+		}
+		typeNames[typ.Name] = struct{}{}
+	}
 	// Validate the privilege descriptor.
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+// FindSchema returns the user-defined schema of the database with the given
+// name, if any.
+func (desc *DatabaseDescriptor) FindSchema(name string) (*SchemaDescriptor, bool) {
+	for i := range desc.Schemas {
+		if desc.Schemas[i].Name == name {
+			return &desc.Schemas[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindSchemaByID returns the user-defined schema of the database with the
+// given ID, if any.
+func (desc *DatabaseDescriptor) FindSchemaByID(id ID) (*SchemaDescriptor, bool) {
+	for i := range desc.Schemas {
+		if desc.Schemas[i].ID == id {
+			return &desc.Schemas[i], true
+		}
+	}
+	return nil, false
+}
+
+// RemoveSchema removes the user-defined schema with the given ID from the
+// database.
+func (desc *DatabaseDescriptor) RemoveSchema(id ID) {
+	for i := range desc.Schemas {
+		if desc.Schemas[i].ID == id {
+			desc.Schemas = append(desc.Schemas[:i], desc.Schemas[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetID implements the DescriptorProto interface.
+func (desc *SchemaDescriptor) SetID(id ID) {
+	desc.ID = id
+}
+
+// TypeName returns the plain type of this descriptor.
+func (desc *SchemaDescriptor) TypeName() string {
+	return "schema"
+}
+
+// SetName implements the DescriptorProto interface.
+func (desc *SchemaDescriptor) SetName(name string) {
+	desc.Name = name
+}
+
+// Validate validates that the schema descriptor is well formed.
+func (desc *SchemaDescriptor) Validate() error {
+	if err := validateName(desc.Name, "schema"); err != nil {
+		return err
+	}
+	if desc.Name == tree.PublicSchemaName {
+		return fmt.Errorf("schema name %q is reserved", desc.Name)
+	}
+	if desc.ID == 0 {
+		return fmt.Errorf("invalid schema ID %d", desc.ID)
+	}
+	return desc.Privileges.Validate(desc.GetID())
+}
+
+// FindType returns the user-defined type of the database with the given
+// name, if any.
+func (desc *DatabaseDescriptor) FindType(name string) (*TypeDescriptor, bool) {
+	for i := range desc.Types {
+		if desc.Types[i].Name == name {
+			return &desc.Types[i], true
+		}
+	}
+	return nil, false
+}
+
+// RemoveType removes the user-defined type with the given ID from the
+// database.
+func (desc *DatabaseDescriptor) RemoveType(id ID) {
+	for i := range desc.Types {
+		if desc.Types[i].ID == id {
+			desc.Types = append(desc.Types[:i], desc.Types[i+1:]...)
+			return
+		}
+	}
+}
+
+// Validate validates that the type descriptor is well formed.
+func (desc *TypeDescriptor) Validate() error {
+	if err := validateName(desc.Name, "type"); err != nil {
+		return err
+	}
+	if desc.ID == 0 {
+		return fmt.Errorf("invalid type ID %d", desc.ID)
+	}
+	if len(desc.EnumLabels) != len(desc.EnumPhysicalReps) {
+		return fmt.Errorf("type %q has %d labels but %d physical representations",
+			desc.Name, len(desc.EnumLabels), len(desc.EnumPhysicalReps))
+	}
+	labels := make(map[string]struct{}, len(desc.EnumLabels))
+	for i, label := range desc.EnumLabels {
+		if _, ok := labels[label]; ok {
+			return fmt.Errorf("type %q has duplicate label %q", desc.Name, label)
+		}
+		labels[label] = struct{}{}
+		if i > 0 && bytes.Compare(desc.EnumPhysicalReps[i-1], desc.EnumPhysicalReps[i]) >= 0 {
+			return fmt.Errorf("physical representations of type %q are not in increasing order",
+				desc.Name)
+		}
+	}
+	for _, label := range desc.EnumAddingLabels {
+		if _, ok := labels[label]; !ok {
+			return fmt.Errorf("type %q is adding unknown label %q", desc.Name, label)
+		}
+	}
+	return nil
+}
+
+// DatumType returns the type of the values of the user-defined type.
+func (desc *TypeDescriptor) DatumType() types.TEnum {
+	return types.TEnum{
+		ID:   uint32(desc.ID),
+		Name: desc.Name,
+		Values: &types.EnumValues{
+			Labels:       desc.EnumLabels,
+			PhysicalReps: desc.EnumPhysicalReps,
+			AddingLabels: desc.EnumAddingLabels,
+		},
+	}
+}
+
 // GetID returns the ID of the descriptor.
 func (desc *Descriptor) GetID() ID {
 	switch t := desc.Union.(type) {
@@ -2413,9 +2730,19 @@ func (desc TableDescriptor) GetDescMetadataKey() roachpb.Key {
 	return MakeDescMetadataKey(desc.ID)
 }
 
+// NamespaceParentID returns the parent ID of the table's namespace entry:
+// the ID of the user-defined schema containing the table if there is one,
+// otherwise the ID of the parent database.
+func (desc *TableDescriptor) NamespaceParentID() ID {
+	if desc.ParentSchemaID != 0 {
+		return desc.ParentSchemaID
+	}
+	return desc.ParentID
+}
+
 // GetNameMetadataKey returns the namespace key for the table.
 func (desc TableDescriptor) GetNameMetadataKey() roachpb.Key {
-	return MakeNameMetadataKey(desc.ParentID, desc.Name)
+	return MakeNameMetadataKey(desc.NamespaceParentID(), desc.Name)
 }
 
 // SQLString returns the SQL statement describing the column.
@@ -2430,6 +2757,9 @@ func (desc *ColumnDescriptor) SQLString() string {
 	if desc.DefaultExpr != nil {
 		fmt.Fprintf(&buf, " DEFAULT %s", *desc.DefaultExpr)
 	}
+	if desc.IsComputed() {
+		fmt.Fprintf(&buf, " AS (%s) STORED", *desc.ComputeExpr)
+	}
 	return buf.String()
 }
 
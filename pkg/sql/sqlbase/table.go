@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -97,6 +98,11 @@ func MakeColumnDefDescs(
 		Nullable: d.Nullable.Nullability != tree.NotNull && !d.PrimaryKey,
 	}
 
+	// Resolve the type if it is user-defined.
+	if err := semaCtx.ResolveType(d.Type); err != nil {
+		return nil, nil, err
+	}
+
 	// Set Type.SemanticType and Type.Locale.
 	colDatumType := coltypes.CastTargetToDatumType(d.Type)
 	colTyp, err := DatumTypeToColumnType(colDatumType)
@@ -144,6 +150,8 @@ func MakeColumnDefDescs(
 	case *coltypes.TDate:
 	case *coltypes.TTimestamp:
 	case *coltypes.TTimestampTZ:
+	case *coltypes.TTime:
+	case *coltypes.TTimeTZ:
 	case *coltypes.TInterval:
 	case *coltypes.TUUID:
 	case *coltypes.TIPAddr:
@@ -160,6 +168,7 @@ func MakeColumnDefDescs(
 			return nil, nil, errors.Errorf("vectors of type %s are unsupported", t.ParamType)
 		}
 	case *coltypes.TOid:
+	case *coltypes.TUserDefined:
 	default:
 		return nil, nil, errors.Errorf("unexpected type %T", t)
 	}
@@ -208,6 +217,16 @@ func MakeColumnDefDescs(
 		col.DefaultExpr = &s
 	}
 
+	if d.IsComputed() {
+		if col.DefaultExpr != nil {
+			return nil, nil, fmt.Errorf("computed column %q cannot have a default value", col.Name)
+		}
+		// The expression is validated once all the columns of the table are
+		// known; see TableDescriptor.ValidateComputedColumn.
+		s := tree.Serialize(d.Computed.Expr)
+		col.ComputeExpr = &s
+	}
+
 	var idx *IndexDescriptor
 	if d.PrimaryKey || d.Unique {
 		idx = &IndexDescriptor{
@@ -394,7 +413,9 @@ func appendEncDatumsToKey(
 
 // MakeKeyFromEncDatums creates a key by concatenating keyPrefix with the
 // encodings of the given EncDatum values. The values correspond to
-// index.ColumnIDs.
+// index.ColumnIDs; they can also correspond to a prefix of index.ColumnIDs, in
+// which case the result is a prefix of the keys of all the index entries with
+// these values.
 //
 // If a table or index is interleaved, `encoding.encodedNullDesc` is used in
 // place of the family id (a varint) to signal the next component of the key.
@@ -412,12 +433,13 @@ func MakeKeyFromEncDatums(
 	alloc *DatumAlloc,
 ) (roachpb.Key, error) {
 	dirs := index.ColumnDirections
-	if len(values) != len(dirs) {
+	if len(values) > len(dirs) {
 		return nil, errors.Errorf("%d values, %d directions", len(values), len(dirs))
 	}
 	if len(values) != len(types) {
 		return nil, errors.Errorf("%d values, %d types", len(values), len(types))
 	}
+	dirs = dirs[:len(values)]
 	// We know we will append to the key which will cause the capacity to grow
 	// so make it bigger from the get-go.
 	key := make(roachpb.Key, len(keyPrefix), len(keyPrefix)*2)
@@ -432,6 +454,11 @@ func MakeKeyFromEncDatums(
 			}
 
 			length := int(ancestor.SharedPrefixLen)
+			if length > len(values) {
+				// The values end within the part of the key shared with this
+				// ancestor.
+				return appendEncDatumsToKey(key, types, values, dirs, alloc)
+			}
 			var err error
 			key, err = appendEncDatumsToKey(key, types[:length], values[:length], dirs[:length], alloc)
 			if err != nil {
@@ -536,6 +563,16 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 			return encoding.EncodeTimeAscending(b, t.Time), nil
 		}
 		return encoding.EncodeTimeDescending(b, t.Time), nil
+	case *tree.DTime:
+		if dir == encoding.Ascending {
+			return encoding.EncodeVarintAscending(b, int64(*t)), nil
+		}
+		return encoding.EncodeVarintDescending(b, int64(*t)), nil
+	case *tree.DTimeTZ:
+		if dir == encoding.Ascending {
+			return encoding.EncodeTimeTZAscending(b, t.TimeOfDay, t.OffsetSecs), nil
+		}
+		return encoding.EncodeTimeTZDescending(b, t.TimeOfDay, t.OffsetSecs), nil
 	case *tree.DInterval:
 		if dir == encoding.Ascending {
 			return encoding.EncodeDurationAscending(b, t.Duration)
@@ -552,6 +589,11 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 			return encoding.EncodeBytesAscending(b, data), nil
 		}
 		return encoding.EncodeBytesDescending(b, data), nil
+	case *tree.DEnum:
+		if dir == encoding.Ascending {
+			return encoding.EncodeBytesAscending(b, t.PhysicalRep), nil
+		}
+		return encoding.EncodeBytesDescending(b, t.PhysicalRep), nil
 	case *tree.DTuple:
 		for _, datum := range t.D {
 			var err error
@@ -567,14 +609,20 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 		}
 		return encoding.EncodeBytesDescending(b, t.Key), nil
 	case *tree.DArray:
+		b = encoding.EncodeArrayKeyMarker(b, dir)
 		for _, datum := range t.Array {
+			if datum == tree.DNull {
+				b = encoding.EncodeNullWithinArrayKey(b, dir)
+				continue
+			}
 			var err error
 			b, err = EncodeTableKey(b, datum, dir)
 			if err != nil {
 				return nil, err
 			}
 		}
-		return b, nil
+		dims, lowerBounds := t.Shape()
+		return encoding.EncodeArrayKeyTerminator(b, dims, lowerBounds, dir), nil
 	case *tree.DOid:
 		if dir == encoding.Ascending {
 
@@ -614,12 +662,18 @@ func EncodeTableValue(
 		return encoding.EncodeTimeValue(appendTo, uint32(colID), t.Time), nil
 	case *tree.DTimestampTZ:
 		return encoding.EncodeTimeValue(appendTo, uint32(colID), t.Time), nil
+	case *tree.DTime:
+		return encoding.EncodeIntValue(appendTo, uint32(colID), int64(*t)), nil
+	case *tree.DTimeTZ:
+		return encoding.EncodeTimeTZValue(appendTo, uint32(colID), t.TimeOfDay, t.OffsetSecs), nil
 	case *tree.DInterval:
 		return encoding.EncodeDurationValue(appendTo, uint32(colID), t.Duration), nil
 	case *tree.DUuid:
 		return encoding.EncodeUUIDValue(appendTo, uint32(colID), t.UUID), nil
 	case *tree.DIPAddr:
 		return encoding.EncodeIPAddrValue(appendTo, uint32(colID), t.IPAddr), nil
+	case *tree.DEnum:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), t.PhysicalRep), nil
 	case *tree.DArray:
 		a, err := encodeArray(t, scratch)
 		if err != nil {
@@ -936,6 +990,8 @@ type DatumAlloc struct {
 	ddateAlloc        []tree.DDate
 	dtimestampAlloc   []tree.DTimestamp
 	dtimestampTzAlloc []tree.DTimestampTZ
+	dtimeAlloc        []tree.DTime
+	dtimeTZAlloc      []tree.DTimeTZ
 	dintervalAlloc    []tree.DInterval
 	duuidAlloc        []tree.DUuid
 	dipnetAlloc       []tree.DIPAddr
@@ -1045,6 +1101,30 @@ func (a *DatumAlloc) NewDTimestampTZ(v tree.DTimestampTZ) *tree.DTimestampTZ {
 	return r
 }
 
+// NewDTime allocates a DTime.
+func (a *DatumAlloc) NewDTime(v tree.DTime) *tree.DTime {
+	buf := &a.dtimeAlloc
+	if len(*buf) == 0 {
+		*buf = make([]tree.DTime, datumAllocSize)
+	}
+	r := &(*buf)[0]
+	*r = v
+	*buf = (*buf)[1:]
+	return r
+}
+
+// NewDTimeTZ allocates a DTimeTZ.
+func (a *DatumAlloc) NewDTimeTZ(v tree.DTimeTZ) *tree.DTimeTZ {
+	buf := &a.dtimeTZAlloc
+	if len(*buf) == 0 {
+		*buf = make([]tree.DTimeTZ, datumAllocSize)
+	}
+	r := &(*buf)[0]
+	*r = v
+	*buf = (*buf)[1:]
+	return r
+}
+
 // NewDInterval allocates a DInterval.
 func (a *DatumAlloc) NewDInterval(v tree.DInterval) *tree.DInterval {
 	buf := &a.dintervalAlloc
@@ -1093,6 +1173,39 @@ func (a *DatumAlloc) NewDOid(v tree.DOid) tree.Datum {
 	return r
 }
 
+// decodeArrayKey decodes an array encoded by EncodeTableKey.
+func decodeArrayKey(
+	a *DatumAlloc, t types.TArray, key []byte, dir encoding.Direction,
+) (tree.Datum, []byte, error) {
+	key, err := encoding.DecodeArrayKeyMarker(key, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := tree.NewDArray(t.Typ)
+	for !encoding.IsArrayKeyDone(key, dir) {
+		var isNull bool
+		if key, isNull = encoding.DecodeIfNullWithinArrayKey(key, dir); isNull {
+			result.Array = append(result.Array, tree.DNull)
+			result.HasNulls = true
+			continue
+		}
+		var d tree.Datum
+		d, key, err = DecodeTableKey(a, t.Typ, key, dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Array = append(result.Array, d)
+	}
+	key, dims, lowerBounds, err := encoding.DecodeArrayKeyTerminator(key, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := result.SetShape(dims, lowerBounds); err != nil {
+		return nil, nil, err
+	}
+	return result, key, nil
+}
+
 // DecodeTableKey decodes a table key/value.
 func DecodeTableKey(
 	a *DatumAlloc, valType types.T, key []byte, dir encoding.Direction,
@@ -1190,6 +1303,23 @@ func DecodeTableKey(
 			rkey, t, err = encoding.DecodeTimeDescending(key)
 		}
 		return a.NewDTimestampTZ(tree.DTimestampTZ{Time: t}), rkey, err
+	case types.Time:
+		var t int64
+		if dir == encoding.Ascending {
+			rkey, t, err = encoding.DecodeVarintAscending(key)
+		} else {
+			rkey, t, err = encoding.DecodeVarintDescending(key)
+		}
+		return a.NewDTime(tree.DTime(t)), rkey, err
+	case types.TimeTZ:
+		var t timeofday.TimeOfDay
+		var offsetSecs int32
+		if dir == encoding.Ascending {
+			rkey, t, offsetSecs, err = encoding.DecodeTimeTZAscending(key)
+		} else {
+			rkey, t, offsetSecs, err = encoding.DecodeTimeTZDescending(key)
+		}
+		return a.NewDTimeTZ(tree.DTimeTZ{TimeOfDay: t, OffsetSecs: offsetSecs}), rkey, err
 	case types.Interval:
 		var d duration.Duration
 		if dir == encoding.Ascending {
@@ -1240,6 +1370,22 @@ func DecodeTableKey(
 			}
 			return nil, nil, errors.Errorf("TODO(eisen): cannot decode collation key: %q", r)
 		}
+		if t, ok := valType.(types.TEnum); ok {
+			var r []byte
+			if dir == encoding.Ascending {
+				rkey, r, err = encoding.DecodeBytesAscending(key, nil)
+			} else {
+				rkey, r, err = encoding.DecodeBytesDescending(key, nil)
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			d, err := tree.MakeDEnumFromPhysicalRepresentation(t, r)
+			return d, rkey, err
+		}
+		if t, ok := valType.(types.TArray); ok {
+			return decodeArrayKey(a, t, key, dir)
+		}
 		return nil, nil, errors.Errorf("TODO(pmattis): decoded index key: %s", valType)
 	}
 }
@@ -1265,8 +1411,15 @@ type arrayHeader struct {
 	hasNulls      bool
 	numDimensions int
 	elementType   encoding.Type
-	length        uint64
-	nullBitmap    []byte
+	// length is the total number of elements in the array.
+	length uint64
+	// dims holds the length of each dimension of an array with more than one
+	// dimension, and is nil otherwise.
+	dims []int
+	// lowerBounds holds the lower bound of each dimension, and is nil if all
+	// of them are 1.
+	lowerBounds []int
+	nullBitmap  []byte
 }
 
 func (h arrayHeader) isNull(i uint64) bool {
@@ -1287,6 +1440,8 @@ func decodeArrayHeader(b []byte) (arrayHeader, []byte, error) {
 		return arrayHeader{}, b, errors.Errorf("buffer too small")
 	}
 	hasNulls := b[0]&hasNullFlag != 0
+	hasLowerBounds := b[0]&hasLowerBoundsFlag != 0
+	numDimensions := int(b[0] & numDimensionsMask)
 	b = b[1:]
 	_, dataOffset, _, encType, err := encoding.DecodeValueTag(b)
 	if err != nil {
@@ -1297,16 +1452,41 @@ func decodeArrayHeader(b []byte) (arrayHeader, []byte, error) {
 	if err != nil {
 		return arrayHeader{}, b, err
 	}
+	var dims []int
+	if numDimensions > 1 {
+		dims = make([]int, numDimensions)
+		for i := range dims {
+			var l uint64
+			b, _, l, err = encoding.DecodeNonsortingUvarint(b)
+			if err != nil {
+				return arrayHeader{}, b, err
+			}
+			dims[i] = int(l)
+		}
+	}
+	var lowerBounds []int
+	if hasLowerBounds {
+		lowerBounds = make([]int, numDimensions)
+		for i := range lowerBounds {
+			var lb int64
+			b, _, lb, err = encoding.DecodeNonsortingStdlibVarint(b)
+			if err != nil {
+				return arrayHeader{}, b, err
+			}
+			lowerBounds[i] = int(lb)
+		}
+	}
 	nullBitmap := []byte(nil)
 	if hasNulls {
 		b, nullBitmap = makeBitVec(b, int(length))
 	}
 	return arrayHeader{
-		hasNulls: hasNulls,
-		// TODO(justin): support multiple dimensions.
-		numDimensions: 1,
+		hasNulls:      hasNulls,
+		numDimensions: numDimensions,
 		elementType:   encType,
 		length:        length,
+		dims:          dims,
+		lowerBounds:   lowerBounds,
 		nullBitmap:    nullBitmap,
 	}, b, nil
 }
@@ -1336,6 +1516,13 @@ func decodeArray(a *DatumAlloc, elementType types.T, b []byte) (tree.Datum, []by
 			result.Array[i] = val
 		}
 	}
+	dims := header.dims
+	if dims == nil && header.length > 0 {
+		dims = []int{int(header.length)}
+	}
+	if err := result.SetShape(dims, header.lowerBounds); err != nil {
+		return nil, b, err
+	}
 	return &result, b, nil
 }
 
@@ -1401,6 +1588,12 @@ func decodeUntaggedDatum(a *DatumAlloc, t types.T, buf []byte) (tree.Datum, []by
 			return nil, b, err
 		}
 		return a.NewDTimestampTZ(tree.DTimestampTZ{Time: data}), b, nil
+	case types.Time:
+		b, data, err := encoding.DecodeUntaggedIntValue(buf)
+		return a.NewDTime(tree.DTime(data)), b, err
+	case types.TimeTZ:
+		b, t, offsetSecs, err := encoding.DecodeUntaggedTimeTZValue(buf)
+		return a.NewDTimeTZ(tree.DTimeTZ{TimeOfDay: t, OffsetSecs: offsetSecs}), b, err
 	case types.Interval:
 		b, data, err := encoding.DecodeUntaggedDurationValue(buf)
 		return a.NewDInterval(tree.DInterval{Duration: data}), b, err
@@ -1420,6 +1613,13 @@ func decodeUntaggedDatum(a *DatumAlloc, t types.T, buf []byte) (tree.Datum, []by
 			return tree.NewDCollatedString(string(data), typ.Locale, &a.env), b, err
 		case types.TArray:
 			return decodeArray(a, typ.Typ, buf)
+		case types.TEnum:
+			b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+			if err != nil {
+				return nil, b, err
+			}
+			d, err := tree.MakeDEnumFromPhysicalRepresentation(typ, data)
+			return d, b, err
 		}
 		return nil, buf, errors.Errorf("couldn't decode type %s", t)
 	}
@@ -1645,6 +1845,16 @@ func MarshalColumnValue(col ColumnDescriptor, val tree.Datum) (roachpb.Value, er
 			r.SetTime(v.Time)
 			return r, nil
 		}
+	case ColumnType_TIME:
+		if v, ok := val.(*tree.DTime); ok {
+			r.SetInt(int64(*v))
+			return r, nil
+		}
+	case ColumnType_TIMETZ:
+		if v, ok := val.(*tree.DTimeTZ); ok {
+			r.SetBytes(encoding.EncodeUntaggedTimeTZValue(nil, v.TimeOfDay, v.OffsetSecs))
+			return r, nil
+		}
 	case ColumnType_INTERVAL:
 		if v, ok := val.(*tree.DInterval); ok {
 			err := r.SetDuration(v.Duration)
@@ -1661,6 +1871,11 @@ func MarshalColumnValue(col ColumnDescriptor, val tree.Datum) (roachpb.Value, er
 			r.SetBytes(data)
 			return r, nil
 		}
+	case ColumnType_ENUM:
+		if v, ok := val.(*tree.DEnum); ok {
+			r.SetBytes(v.PhysicalRep)
+			return r, nil
+		}
 	case ColumnType_ARRAY:
 		if v, ok := val.(*tree.DArray); ok {
 			if err := checkElementType(v.ParamTyp, col.Type); err != nil {
@@ -1697,20 +1912,39 @@ func MarshalColumnValue(col ColumnDescriptor, val tree.Datum) (roachpb.Value, er
 		val.ResolvedType(), col.Type.SemanticType, col.Name)
 }
 
-const hasNullFlag = 1 << 4
+const (
+	numDimensionsMask  = 0x0f
+	hasNullFlag        = 1 << 4
+	hasLowerBoundsFlag = 1 << 5
+)
 
 func encodeArrayHeader(h arrayHeader, buf []byte) ([]byte, error) {
 	// The header byte we append here is formatted as follows:
 	// * The low 4 bits encode the number of dimensions in the array.
 	// * The high 4 bits are flags, with the lowest representing whether the array
-	//   contains NULLs, and the rest reserved.
+	//   contains NULLs, the next whether the lower bounds of the dimensions are
+	//   encoded, and the rest reserved.
+	// The total number of elements follows the element type. Arrays with more
+	// than one dimension then have the length of each dimension, followed by
+	// the lower bound of each dimension if the flag is set.
 	headerByte := h.numDimensions
 	if h.hasNulls {
 		headerByte = headerByte | hasNullFlag
 	}
+	if h.lowerBounds != nil {
+		headerByte = headerByte | hasLowerBoundsFlag
+	}
 	buf = append(buf, byte(headerByte))
 	buf = encoding.EncodeValueTag(buf, encoding.NoColumnID, h.elementType)
 	buf = encoding.EncodeNonsortingUvarint(buf, h.length)
+	if h.numDimensions > 1 {
+		for _, l := range h.dims {
+			buf = encoding.EncodeNonsortingUvarint(buf, uint64(l))
+		}
+	}
+	for _, lb := range h.lowerBounds {
+		buf = encoding.EncodeNonsortingStdlibVarint(buf, int64(lb))
+	}
 	return buf, nil
 }
 
@@ -1731,13 +1965,19 @@ func encodeArray(d *tree.DArray, scratch []byte) ([]byte, error) {
 	}
 	header := arrayHeader{
 		hasNulls: d.HasNulls,
-		// TODO(justin): support multiple dimensions.
+		// Empty arrays are encoded as having one dimension, as they always
+		// have been.
 		numDimensions: 1,
 		elementType:   elementType,
 		length:        uint64(d.Len()),
+		dims:          d.Dims,
+		lowerBounds:   d.LowerBounds,
 		// We don't encode the NULL bitmap in this function because we do it in lockstep with the
 		// main data.
 	}
+	if n := d.NumDims(); n > 1 {
+		header.numDimensions = n
+	}
 	scratch, err = encodeArrayHeader(header, scratch)
 	if err != nil {
 		return nil, err
@@ -1776,6 +2016,10 @@ func parserTypeToEncodingType(t types.T) (encoding.Type, error) {
 		return encoding.Bytes, nil
 	case types.Timestamp, types.TimestampTZ, types.Date:
 		return encoding.Time, nil
+	case types.Time:
+		return encoding.Int, nil
+	case types.TimeTZ:
+		return encoding.TimeTZ, nil
 	case types.Interval:
 		return encoding.Duration, nil
 	case types.Bool:
@@ -1816,6 +2060,10 @@ func encodeArrayElement(b []byte, d tree.Datum) ([]byte, error) {
 		return encoding.EncodeUntaggedTimeValue(b, t.Time), nil
 	case *tree.DTimestampTZ:
 		return encoding.EncodeUntaggedTimeValue(b, t.Time), nil
+	case *tree.DTime:
+		return encoding.EncodeUntaggedIntValue(b, int64(*t)), nil
+	case *tree.DTimeTZ:
+		return encoding.EncodeUntaggedTimeTZValue(b, t.TimeOfDay, t.OffsetSecs), nil
 	case *tree.DInterval:
 		return encoding.EncodeUntaggedDurationValue(b, t.Duration), nil
 	case *tree.DUuid:
@@ -1894,6 +2142,22 @@ func UnmarshalColumnValue(a *DatumAlloc, typ ColumnType, value roachpb.Value) (t
 			return nil, err
 		}
 		return a.NewDTimestampTZ(tree.DTimestampTZ{Time: v}), nil
+	case ColumnType_TIME:
+		v, err := value.GetInt()
+		if err != nil {
+			return nil, err
+		}
+		return a.NewDTime(tree.DTime(v)), nil
+	case ColumnType_TIMETZ:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		_, t, offsetSecs, err := encoding.DecodeUntaggedTimeTZValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return a.NewDTimeTZ(tree.DTimeTZ{TimeOfDay: t, OffsetSecs: offsetSecs}), nil
 	case ColumnType_INTERVAL:
 		d, err := value.GetDuration()
 		if err != nil {
@@ -1939,6 +2203,12 @@ func UnmarshalColumnValue(a *DatumAlloc, typ ColumnType, value roachpb.Value) (t
 			return nil, err
 		}
 		return a.NewDOid(tree.MakeDOid(tree.DInt(v))), nil
+	case ColumnType_ENUM:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		return tree.MakeDEnumFromPhysicalRepresentation(typ.ToDatumType().(types.TEnum), v)
 	default:
 		return nil, errors.Errorf("unsupported column type: %s", typ.SemanticType)
 	}
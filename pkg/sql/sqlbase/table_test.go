@@ -24,6 +24,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
@@ -328,6 +329,32 @@ func TestArrayEncoding(t *testing.T) {
 				HasNulls: true,
 			},
 			[]byte{17, 3, 9, 6, 1, 2, 4, 6, 8, 10, 12},
+		}, {
+			"two-dimensional int array",
+			tree.DArray{
+				ParamTyp: types.Int,
+				Array:    tree.Datums{tree.NewDInt(1), tree.NewDInt(2), tree.NewDInt(3), tree.NewDInt(4)},
+				Dims:     []int{2, 2},
+			},
+			[]byte{2, 3, 4, 2, 2, 2, 4, 6, 8},
+		}, {
+			"int array with a lower bound",
+			tree.DArray{
+				ParamTyp:    types.Int,
+				Array:       tree.Datums{tree.NewDInt(1), tree.NewDInt(2)},
+				LowerBounds: []int{0},
+			},
+			[]byte{33, 3, 2, 0, 2, 4},
+		}, {
+			"two-dimensional array containing a null with lower bounds",
+			tree.DArray{
+				ParamTyp:    types.Int,
+				Array:       tree.Datums{tree.NewDInt(1), tree.DNull},
+				HasNulls:    true,
+				Dims:        []int{2, 1},
+				LowerBounds: []int{1, -1},
+			},
+			[]byte{50, 3, 2, 2, 1, 2, 1, 2, 2},
 		},
 	}
 
@@ -369,6 +396,66 @@ func BenchmarkArrayEncoding(b *testing.B) {
 	}
 }
 
+func TestArrayKeyEncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	evalCtx := tree.NewTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+
+	// The arrays are listed in ascending order.
+	arrays := []string{
+		`{}`,
+		`{NULL}`,
+		`{NULL,1}`,
+		`[0:0]={1}`,
+		`{1}`,
+		`{1,2}`,
+		`{{1,2}}`,
+		`{{1},{2}}`,
+		`{1,2,3}`,
+		`{2}`,
+	}
+	datums := make([]*tree.DArray, len(arrays))
+	for i, s := range arrays {
+		d, err := tree.ParseDArrayFromString(evalCtx, s, coltypes.Int)
+		if err != nil {
+			t.Fatal(err)
+		}
+		datums[i] = d
+	}
+
+	typ := types.TArray{Typ: types.Int}
+	for _, dir := range []encoding.Direction{encoding.Ascending, encoding.Descending} {
+		expected := -1
+		if dir == encoding.Descending {
+			expected = 1
+		}
+		var prev []byte
+		for i, d := range datums {
+			key, err := EncodeTableKey(nil, d, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, rest, err := DecodeTableKey(&DatumAlloc{}, typ, key, dir)
+			if err != nil {
+				t.Fatalf("%s: %v", arrays[i], err)
+			}
+			if len(rest) != 0 {
+				t.Errorf("%s: %d bytes left after decoding", arrays[i], len(rest))
+			}
+			if decoded.Compare(evalCtx, d) != 0 {
+				t.Errorf("%s: decoded to %s", arrays[i], decoded)
+			}
+			if i > 0 {
+				if c := bytes.Compare(prev, key); c != expected {
+					t.Errorf("%s and %s encoded out of order in direction %d", arrays[i-1], arrays[i], dir)
+				}
+			}
+			prev = key
+		}
+	}
+}
+
 func TestMarshalColumnValue(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
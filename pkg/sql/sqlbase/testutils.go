@@ -25,9 +25,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -112,6 +114,12 @@ func RandDatum(rng *rand.Rand, typ ColumnType, nullOk bool) tree.Datum {
 		return tree.NewDBytes(tree.DBytes(p))
 	case ColumnType_TIMESTAMPTZ:
 		return &tree.DTimestampTZ{Time: timeutil.Unix(rng.Int63n(1000000), rng.Int63n(1000000))}
+	case ColumnType_TIME:
+		return tree.MakeDTime(timeofday.FromInt(rng.Int63()))
+	case ColumnType_TIMETZ:
+		// Offsets are within +/- 15 hours.
+		offsetSecs := int32(rng.Intn(2*15*60*60+1) - 15*60*60)
+		return tree.NewDTimeTZ(timeofday.FromInt(rng.Int63()), offsetSecs)
 	case ColumnType_COLLATEDSTRING:
 		if typ.Locale == nil {
 			panic("locale is required for COLLATEDSTRING")
@@ -146,6 +154,11 @@ func RandDatum(rng *rand.Rand, typ ColumnType, nullOk bool) tree.Datum {
 		return tree.DNull
 	case ColumnType_INT2VECTOR:
 		return tree.DNull
+	case ColumnType_ENUM:
+		if len(typ.EnumLabels) == 0 {
+			return tree.DNull
+		}
+		return tree.NewDEnum(typ.ToDatumType().(types.TEnum), rng.Intn(len(typ.EnumLabels)))
 	default:
 		panic(fmt.Sprintf("invalid type %s", typ.String()))
 	}
@@ -173,10 +186,15 @@ func RandColumnType(rng *rand.Rand) ColumnType {
 	if typ.SemanticType == ColumnType_COLLATEDSTRING {
 		typ.Locale = RandCollationLocale(rng)
 	}
+	if typ.SemanticType == ColumnType_ENUM {
+		typ = RandEnumColumnType(rng)
+	}
 	if typ.SemanticType == ColumnType_ARRAY {
 		typ.ArrayContents = &columnSemanticTypes[rng.Intn(len(columnSemanticTypes))]
-		if *typ.ArrayContents == ColumnType_COLLATEDSTRING {
+		switch *typ.ArrayContents {
+		case ColumnType_COLLATEDSTRING, ColumnType_ENUM:
 			// TODO(justin): change this when collated arrays are supported.
+			// Arrays of ENUM values are not supported either.
 			s := ColumnType_STRING
 			typ.ArrayContents = &s
 		}
@@ -184,6 +202,22 @@ func RandColumnType(rng *rand.Rand) ColumnType {
 	return typ
 }
 
+// RandEnumColumnType returns a random ColumnType of kind ENUM, with at least
+// two values.
+func RandEnumColumnType(rng *rand.Rand) ColumnType {
+	n := 2 + rng.Intn(5)
+	typ := ColumnType{
+		SemanticType: ColumnType_ENUM,
+		EnumTypeID:   ID(1 + rng.Intn(100)),
+	}
+	typ.EnumTypeName = fmt.Sprintf("enum%d", typ.EnumTypeID)
+	for i := 0; i < n; i++ {
+		typ.EnumLabels = append(typ.EnumLabels, fmt.Sprintf("v%d", i))
+	}
+	typ.EnumPhysicalReps = GenerateEnumPhysicalReps(n)
+	return typ
+}
+
 // RandColumnTypes returns a slice of numCols random ColumnType value.
 func RandColumnTypes(rng *rand.Rand, numCols int) []ColumnType {
 	types := make([]ColumnType, numCols)
@@ -22,6 +22,8 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -41,6 +43,13 @@ type subquery struct {
 	started  bool
 	plan     planNode
 	result   tree.Datum
+
+	// outer is set for correlated subqueries, which refer to columns of the
+	// enclosing query. outerExprs are the expressions, in the context of the
+	// enclosing query, whose values are assigned to outer.vars before each
+	// evaluation of the subquery.
+	outer      *subqueryScope
+	outerExprs []tree.TypedExpr
 }
 
 type subqueryExecMode int
@@ -69,6 +78,13 @@ var _ tree.TypedExpr = &subquery{}
 var _ tree.VariableExpr = &subquery{}
 
 func (s *subquery) Format(buf *bytes.Buffer, f tree.FmtFlags) {
+	if s.result != nil {
+		// Once the subquery has been evaluated, it stands for its result. This
+		// is how the results of subqueries make their way into the expressions
+		// that are sent to other nodes by DistSQL.
+		tree.FormatNode(buf, f, s.result)
+		return
+	}
 	if s.execMode == execModeExists {
 		buf.WriteString("EXISTS ")
 	}
@@ -81,8 +97,35 @@ func (s *subquery) Format(buf *bytes.Buffer, f tree.FmtFlags) {
 
 func (s *subquery) String() string { return tree.AsString(s) }
 
+var _ distsqlplan.EvaluatedSetExpr = &subquery{}
+
+// EvaluatedSet implements the distsqlplan.EvaluatedSetExpr interface.
+func (s *subquery) EvaluatedSet() (tree.Datums, bool) {
+	if s.execMode != execModeAllRowsNormalized || s.result == nil {
+		return nil, false
+	}
+	return s.result.(*tree.DTuple).D, true
+}
+
 func (s *subquery) Walk(v tree.Visitor) tree.Expr {
-	return s
+	// The references of a correlated subquery to the columns of the
+	// enclosing query are part of the enclosing expression.
+	var exprs []tree.TypedExpr
+	for i, e := range s.outerExprs {
+		newExpr, _ := tree.WalkExpr(v, e)
+		if newExpr != e {
+			if exprs == nil {
+				exprs = append([]tree.TypedExpr(nil), s.outerExprs...)
+			}
+			exprs[i] = newExpr.(tree.TypedExpr)
+		}
+	}
+	if exprs == nil {
+		return s
+	}
+	sCopy := *s
+	sCopy.outerExprs = exprs
+	return &sCopy
 }
 
 func (s *subquery) Variable() {}
@@ -101,7 +144,10 @@ func (s *subquery) TypeCheck(_ *tree.SemaContext, desired types.T) (tree.TypedEx
 
 func (s *subquery) ResolvedType() types.T { return s.typ }
 
-func (s *subquery) Eval(_ *tree.EvalContext) (tree.Datum, error) {
+func (s *subquery) Eval(ctx *tree.EvalContext) (tree.Datum, error) {
+	if s.correlated() {
+		return s.evalCorrelated(ctx)
+	}
 	if s.result == nil {
 		panic("subquery was not pre-evaluated properly")
 	}
@@ -112,30 +158,46 @@ func (s *subquery) doEval(ctx context.Context) (result tree.Datum, err error) {
 	// After evaluation, there is no plan remaining.
 	defer func() { s.plan.Close(ctx); s.plan = nil }()
 
+	return s.evalPlan(ctx, s.plan)
+}
+
+// evalPlan computes the result of the subquery from the rows of the given
+// plan, which must have been started.
+func (s *subquery) evalPlan(ctx context.Context, plan planNode) (tree.Datum, error) {
 	params := runParams{
 		ctx: ctx,
 		p:   s.planner,
 	}
+	return s.evalRows(plan, func() (tree.Datums, bool, error) {
+		next, err := plan.Next(params)
+		if !next || err != nil {
+			return nil, false, err
+		}
+		return plan.Values(), true, nil
+	})
+}
+
+// evalRows computes the result of the subquery from its rows, as returned by
+// nextRow. nextRow returns false once there are no more rows; the rows it
+// returns need only be valid until the next call. plan is the plan producing
+// the rows.
+func (s *subquery) evalRows(
+	plan planNode, nextRow func() (tree.Datums, bool, error),
+) (result tree.Datum, err error) {
 	switch s.execMode {
 	case execModeExists:
 		// For EXISTS expressions, all we want to know is if there is at least one
 		// result.
-		next, err := s.plan.Next(params)
+		_, next, err := nextRow()
 		if err != nil {
 			return result, err
 		}
-		if next {
-			result = tree.MakeDBool(true)
-		}
-		if result == nil {
-			result = tree.MakeDBool(false)
-		}
+		result = tree.MakeDBool(tree.DBool(next))
 
 	case execModeAllRows, execModeAllRowsNormalized:
 		var rows tree.DTuple
-		next, err := s.plan.Next(params)
-		for ; next; next, err = s.plan.Next(params) {
-			values := s.plan.Values()
+		values, next, err := nextRow()
+		for ; next; values, next, err = nextRow() {
 			switch len(values) {
 			case 1:
 				// This seems hokey, but if we don't do this then the subquery expands
@@ -144,8 +206,8 @@ func (s *subquery) doEval(ctx context.Context) (result tree.Datum, err error) {
 				// a single value against a tuple.
 				rows.D = append(rows.D, values[0])
 			default:
-				// The result from plan.Values() is only valid until the next call to
-				// plan.Next(), so make a copy.
+				// The values are only valid until the next call to nextRow(), so
+				// make a copy.
 				valuesCopy := tree.NewDTupleWithLen(len(values))
 				copy(valuesCopy.D, values)
 				rows.D = append(rows.D, valuesCopy)
@@ -155,7 +217,7 @@ func (s *subquery) doEval(ctx context.Context) (result tree.Datum, err error) {
 			return result, err
 		}
 
-		if ok, dir := s.subqueryTupleOrdering(); ok {
+		if ok, dir := subqueryTupleOrdering(plan); ok {
 			if dir == encoding.Descending {
 				rows.D.Reverse()
 			}
@@ -168,12 +230,11 @@ func (s *subquery) doEval(ctx context.Context) (result tree.Datum, err error) {
 
 	case execModeOneRow:
 		result = tree.DNull
-		hasRow, err := s.plan.Next(params)
+		values, hasRow, err := nextRow()
 		if err != nil {
 			return result, err
 		}
 		if hasRow {
-			values := s.plan.Values()
 			switch len(values) {
 			case 1:
 				result = values[0]
@@ -182,7 +243,7 @@ func (s *subquery) doEval(ctx context.Context) (result tree.Datum, err error) {
 				copy(valuesCopy.D, values)
 				result = valuesCopy
 			}
-			another, err := s.plan.Next(params)
+			_, another, err := nextRow()
 			if err != nil {
 				return result, err
 			}
@@ -197,7 +258,7 @@ func (s *subquery) doEval(ctx context.Context) (result tree.Datum, err error) {
 	return result, nil
 }
 
-// subqueryTupleOrdering returns whether the rows of the subquery are ordered
+// subqueryTupleOrdering returns whether the rows of the subquery plan are ordered
 // such that the resulting subquery tuple can be considered fully sorted.
 // For this to happen, the columns in the subquery must be sorted in the same
 // direction and with the same order of precedence that the tuple will have. The
@@ -209,10 +270,10 @@ func (s *subquery) doEval(ctx context.Context) (result tree.Datum, err error) {
 //   SELECT 1 IN (SELECT 1 ORDER BY 1)
 // because even if they are included in an ORDER BY clause, they will not be part
 // of the plan.Ordering().
-func (s *subquery) subqueryTupleOrdering() (bool, encoding.Direction) {
+func subqueryTupleOrdering(plan planNode) (bool, encoding.Direction) {
 	// Columns must be sorted in the order that they appear in the render
 	// and which they will later appear in the resulting tuple.
-	desired := make(sqlbase.ColumnOrdering, len(planColumns(s.plan)))
+	desired := make(sqlbase.ColumnOrdering, len(planColumns(plan)))
 	for i := range desired {
 		desired[i] = sqlbase.ColumnOrderInfo{
 			ColIdx:    i,
@@ -221,7 +282,7 @@ func (s *subquery) subqueryTupleOrdering() (bool, encoding.Direction) {
 	}
 
 	// Check Ascending direction.
-	order := planPhysicalProps(s.plan)
+	order := planPhysicalProps(plan)
 	match := order.computeMatch(desired)
 	if match == len(desired) {
 		return true, encoding.Ascending
@@ -246,6 +307,16 @@ func (v *subqueryPlanVisitor) subqueryNode(ctx context.Context, sq *subquery) er
 	if !sq.expanded {
 		panic("subquery was not expanded properly")
 	}
+	if sq.correlated() {
+		// Correlated subqueries are planned and run again for every
+		// evaluation (see evalCorrelated). The plan built during the
+		// planning of the enclosing query is only used by EXPLAIN.
+		if sq.plan != nil {
+			sq.plan.Close(ctx)
+			sq.plan = nil
+		}
+		return nil
+	}
 	if !sq.started {
 		if err := v.p.startPlan(ctx, sq.plan); err != nil {
 			return err
@@ -314,6 +385,13 @@ func collectSubquerySpans(params runParams, plan planNode) (roachpb.Spans, error
 type subqueryVisitor struct {
 	*planner
 	columns int
+
+	// sources and ivarHelper are used to resolve the references of
+	// correlated subqueries to the columns of the enclosing query. sources
+	// is nil if the expression cannot refer to columns.
+	sources    multiSourceInfo
+	ivarHelper tree.IndexedVarHelper
+
 	path    []tree.Expr // parent expressions
 	pathBuf [4]tree.Expr
 	err     error
@@ -351,10 +429,24 @@ func (v *subqueryVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.E
 
 	v.hasSubqueries = true
 
+	// Names which are not found in the subquery can refer to the columns of
+	// the enclosing query.
+	var scope *subqueryScope
+	if v.sources != nil {
+		scope = &subqueryScope{
+			parent:     v.planner.subqueryScope,
+			sources:    v.sources,
+			ivarHelper: v.ivarHelper,
+		}
+	}
+
 	// Calling newPlan() might recursively invoke expandSubqueries, so we need to preserve
 	// the state of the visitor across the call to newPlan().
 	visitorCopy := v.planner.subqueryVisitor
+	prevScope := v.planner.subqueryScope
+	v.planner.subqueryScope = scope
 	plan, err := v.planner.newPlan(v.ctx, sq.Select, nil)
+	v.planner.subqueryScope = prevScope
 	v.planner.subqueryVisitor = visitorCopy
 	if err != nil {
 		v.err = err
@@ -362,6 +454,10 @@ func (v *subqueryVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.E
 	}
 
 	result := &subquery{planner: v.planner, subquery: sq, plan: plan}
+	if scope != nil && len(scope.vars) > 0 {
+		result.outer = scope
+		result.outerExprs = append([]tree.TypedExpr(nil), scope.exprs...)
+	}
 
 	if exists != nil {
 		result.execMode = execModeExists
@@ -409,10 +505,23 @@ func (v *subqueryVisitor) VisitPost(expr tree.Expr) tree.Expr {
 	return expr
 }
 
+// replaceSubqueries replaces the subqueries in expr by subquery nodes.
+// sources and ivarHelper, if non-nil, are used to resolve the references of
+// correlated subqueries to the columns of the sources of expr.
 func (p *planner) replaceSubqueries(
-	ctx context.Context, expr tree.Expr, columns int,
+	ctx context.Context,
+	expr tree.Expr,
+	columns int,
+	sources multiSourceInfo,
+	ivarHelper tree.IndexedVarHelper,
 ) (tree.Expr, error) {
-	p.subqueryVisitor = subqueryVisitor{planner: p, columns: columns, ctx: ctx}
+	p.subqueryVisitor = subqueryVisitor{
+		planner:    p,
+		columns:    columns,
+		sources:    sources,
+		ivarHelper: ivarHelper,
+		ctx:        ctx,
+	}
 	p.subqueryVisitor.path = p.subqueryVisitor.pathBuf[:0]
 	expr, _ = tree.WalkExpr(&p.subqueryVisitor, expr)
 	return expr, p.subqueryVisitor.err
@@ -471,3 +580,219 @@ func (v *subqueryVisitor) getSubqueryContext() (columns int, execMode subqueryEx
 	// columns.
 	return v.columns, execModeOneRow
 }
+
+// correlatedSubqueryCacheSize is the maximum number of results of a
+// correlated subquery that are remembered, each for a different set of
+// values of the outer columns.
+const correlatedSubqueryCacheSize = 1000
+
+// subqueryScope describes the data sources of the query enclosing a
+// subquery, against which the names that are not found in the subquery are
+// resolved. Each reference to an outer column is replaced in the subquery by
+// an outerVar; the scope collects these outerVars along with the
+// corresponding expressions in the enclosing query.
+type subqueryScope struct {
+	// parent is the scope of the enclosing query, if it is itself a
+	// subquery.
+	parent     *subqueryScope
+	sources    multiSourceInfo
+	ivarHelper tree.IndexedVarHelper
+
+	keys  []outerVarKey
+	vars  []*outerVar
+	exprs []tree.TypedExpr
+
+	// cache remembers the results of the subquery, keyed by the values of
+	// the outer columns.
+	cache map[string]tree.Datum
+	// uncacheable is set if the results of the subquery can't be cached
+	// because it evaluates impure functions, like random().
+	uncacheable bool
+}
+
+// outerVarKey identifies an outer column in a subqueryScope: either a column
+// of the sources of the scope, or an outer column of the parent scope.
+type outerVarKey struct {
+	idx    int
+	parent *outerVar
+}
+
+// resolveColumn looks up a column name, which could not be found in a
+// subquery, in the enclosing queries. It returns nil if the name is not found
+// there either.
+func (s *subqueryScope) resolveColumn(c *tree.ColumnItem) (*outerVar, error) {
+	if s == nil {
+		return nil, nil
+	}
+	var key outerVarKey
+	var typ types.T
+	srcIdx, colIdx, err := s.sources.findColumn(c)
+	if err == nil {
+		key.idx = colIdx
+		for _, src := range s.sources[:srcIdx] {
+			key.idx += len(src.sourceColumns)
+		}
+		typ = s.sources[srcIdx].sourceColumns[colIdx].Typ
+	} else {
+		if !isUnknownNameError(err) {
+			return nil, err
+		}
+		parentVar, err := s.parent.resolveColumn(c)
+		if parentVar == nil || err != nil {
+			return nil, err
+		}
+		key = outerVarKey{idx: invalidColIdx, parent: parentVar}
+		typ = parentVar.typ
+	}
+
+	for i := range s.keys {
+		if s.keys[i] == key {
+			return s.vars[i], nil
+		}
+	}
+	var expr tree.TypedExpr
+	if key.parent != nil {
+		expr = key.parent
+	} else {
+		expr = s.ivarHelper.IndexedVar(key.idx)
+	}
+	v := &outerVar{typ: typ, col: c}
+	s.keys = append(s.keys, key)
+	s.vars = append(s.vars, v)
+	s.exprs = append(s.exprs, expr)
+	return v, nil
+}
+
+// isUnknownNameError returns true if err is the error returned by
+// findColumn when a column or source name is not found.
+func isUnknownNameError(err error) bool {
+	pgErr, ok := pgerror.GetPGCause(err)
+	return ok && (pgErr.Code == pgerror.CodeUndefinedColumnError ||
+		pgErr.Code == pgerror.CodeUndefinedTableError)
+}
+
+// outerVar stands, in a correlated subquery, for a column of an enclosing
+// query. Its value is set before each evaluation of the subquery.
+type outerVar struct {
+	typ types.T
+	col *tree.ColumnItem
+	val tree.Datum
+}
+
+var _ tree.TypedExpr = &outerVar{}
+var _ tree.VariableExpr = &outerVar{}
+
+func (v *outerVar) Format(buf *bytes.Buffer, f tree.FmtFlags) { tree.FormatNode(buf, f, v.col) }
+func (v *outerVar) String() string                            { return tree.AsString(v) }
+func (v *outerVar) Walk(_ tree.Visitor) tree.Expr             { return v }
+func (v *outerVar) Variable()                                 {}
+func (v *outerVar) ResolvedType() types.T                     { return v.typ }
+
+func (v *outerVar) TypeCheck(_ *tree.SemaContext, _ types.T) (tree.TypedExpr, error) {
+	return v, nil
+}
+
+func (v *outerVar) Eval(_ *tree.EvalContext) (tree.Datum, error) {
+	if v.val == nil {
+		panic(fmt.Sprintf("outer column %s was not assigned", v.col))
+	}
+	return v.val, nil
+}
+
+// correlated returns true if the subquery refers to columns of the enclosing
+// query.
+func (s *subquery) correlated() bool {
+	return s.outer != nil
+}
+
+// evalCorrelated computes the result of a correlated subquery for the current
+// values of the outer columns it refers to. The subquery is planned and run
+// again for every new set of values; the results are cached by these values,
+// unless the subquery uses impure functions like random().
+func (s *subquery) evalCorrelated(evalCtx *tree.EvalContext) (tree.Datum, error) {
+	var buf bytes.Buffer
+	for i, e := range s.outerExprs {
+		d, err := e.Eval(evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		s.outer.vars[i].val = d
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		tree.FormatNode(&buf, tree.FmtParsable, d)
+	}
+	key := buf.String()
+	if res, ok := s.outer.cache[key]; ok {
+		return res, nil
+	}
+
+	res, err := s.runCorrelated(evalCtx.Ctx())
+	if err != nil {
+		return nil, err
+	}
+	if s.outer.uncacheable {
+		return res, nil
+	}
+	if s.outer.cache == nil || len(s.outer.cache) >= correlatedSubqueryCacheSize {
+		s.outer.cache = make(map[string]tree.Datum)
+	}
+	s.outer.cache[key] = res
+	return res, nil
+}
+
+// runCorrelated plans and runs the subquery for the current values of the
+// outer columns.
+func (s *subquery) runCorrelated(ctx context.Context) (tree.Datum, error) {
+	p := s.planner
+	// Name resolution finds the outerVars of the scope again.
+	defer func(prev *subqueryScope) { p.subqueryScope = prev }(p.subqueryScope)
+	p.subqueryScope = s.outer
+
+	plan, err := p.newPlan(ctx, s.subquery.Select, nil)
+	if err != nil {
+		return nil, err
+	}
+	plan, err = p.optimizeSubqueryPlan(ctx, s.execMode, plan)
+	defer plan.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.outer.uncacheable = containsImpureExprs(ctx, plan)
+	if err := p.startPlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	return s.evalPlan(ctx, plan)
+}
+
+// containsImpureExprs returns true if the plan evaluates impure functions,
+// or correlated subqueries which might do so.
+func containsImpureExprs(ctx context.Context, plan planNode) bool {
+	var v impureExprVisitor
+	_ = walkPlan(ctx, plan, planObserver{
+		expr: func(_, _ string, _ int, expr tree.Expr) {
+			if !v.impure {
+				tree.WalkExprConst(&v, expr)
+			}
+		},
+	})
+	return v.impure
+}
+
+type impureExprVisitor struct {
+	impure bool
+}
+
+var _ tree.Visitor = &impureExprVisitor{}
+
+func (v *impureExprVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch t := expr.(type) {
+	case *tree.FuncExpr:
+		v.impure = v.impure || t.IsImpure()
+	case *subquery:
+		v.impure = v.impure || t.correlated()
+	}
+	return !v.impure, expr
+}
+
+func (*impureExprVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -84,7 +85,7 @@ func GetKeysForTableDescriptor(
 	tableDesc *sqlbase.TableDescriptor,
 ) (zoneKey roachpb.Key, nameKey roachpb.Key, descKey roachpb.Key) {
 	zoneKey = config.MakeZoneKey(uint32(tableDesc.ID))
-	nameKey = sqlbase.MakeNameMetadataKey(tableDesc.ParentID, tableDesc.GetName())
+	nameKey = sqlbase.MakeNameMetadataKey(tableDesc.NamespaceParentID(), tableDesc.GetName())
 	descKey = sqlbase.MakeDescMetadataKey(tableDesc.ID)
 	return
 }
@@ -129,13 +130,13 @@ func getTableOrViewDesc(
 		return virtual, err
 	}
 
-	dbDesc, err := MustGetDatabaseDesc(ctx, txn, vt, tn.Database())
+	dbDesc, scDesc, err := resolveTableSchema(ctx, txn, vt, tn)
 	if err != nil {
 		return nil, err
 	}
 
 	desc := sqlbase.TableDescriptor{}
-	found, err := getDescriptor(ctx, txn, tableKey{parentID: dbDesc.ID, name: tn.Table()}, &desc)
+	found, err := getDescriptor(ctx, txn, tableKey{parentID: namespaceParentID(dbDesc, scDesc), name: tn.Table()}, &desc)
 	if err != nil {
 		return nil, err
 	}
@@ -224,6 +225,27 @@ func MustGetTableDesc(
 	return desc, nil
 }
 
+// mustGetIndexableTableDesc is like MustGetTableDesc, but also returns the
+// descriptor of a materialized view, whose results can be indexed like the
+// rows of a table. The indexes of a materialized view can't be changed while
+// it is being refreshed.
+func mustGetIndexableTableDesc(
+	ctx context.Context, txn *client.Txn, vt VirtualTabler, tn *tree.TableName, allowAdding bool,
+) (*sqlbase.TableDescriptor, error) {
+	desc, err := MustGetTableOrViewDesc(ctx, txn, vt, tn, allowAdding)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.IsTable() && !desc.MaterializedView() {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "table")
+	}
+	if desc.Refresh != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeObjectInUseError,
+			"materialized view %q is being refreshed", tn.Table())
+	}
+	return desc, nil
+}
+
 var errTableDropped = errors.New("table is being dropped")
 var errTableAdding = errors.New("table is being added")
 
@@ -283,6 +305,10 @@ type TableCollection struct {
 	// TODO(andrei): get rid of it and replace it with a leasing system for
 	// database descriptors.
 	databaseCache *databaseCache
+
+	// tempSchemaName is the name of the temporary schema of the session,
+	// which is the only temporary schema whose tables can be accessed.
+	tempSchemaName string
 }
 
 // Check if the timestamp used so far to pick tables has changed because
@@ -331,25 +357,21 @@ func (tc *TableCollection) getTableVersion(
 		return tbl, nil
 	}
 
-	dbID, err := tc.getUncommittedDatabaseID(tn)
+	// The ID of the parent of the table's namespace entry: either the
+	// database or the user-defined schema the table belongs to.
+	parentID, err := tc.getNamespaceID(ctx, txn, vt, tn)
 	if err != nil {
 		return nil, err
 	}
-
-	if dbID == 0 {
-		// Resolve the database from the database cache when the transaction
-		// hasn't modified the database.
-		dbID, err = tc.databaseCache.getDatabaseID(ctx, tc.leaseMgr.LeaseStore.db.Txn, vt, tn.Database())
-		if err != nil {
-			return nil, err
-		}
+	if IsTempSchemaName(tn.Schema()) && tn.Schema() != tc.tempSchemaName {
+		return nil, errTempTableOfOtherSession()
 	}
 
 	// If the txn has been pushed the table collection is released and
 	// txn deadline is reset.
 	tc.resetForTxnRetry(ctx, txn)
 
-	if table, err := tc.getUncommittedTable(dbID, tn); err != nil {
+	if table, err := tc.getUncommittedTable(parentID, tn); err != nil {
 		return nil, err
 	} else if table != nil {
 		log.VEventf(ctx, 2, "found uncommitted table %d", table.ID)
@@ -362,13 +384,13 @@ func (tc *TableCollection) getTableVersion(
 	// transaction.
 	for _, table := range tc.tables {
 		if table.Name == string(tn.TableName) &&
-			table.ParentID == dbID {
+			table.NamespaceParentID() == parentID {
 			log.VEventf(ctx, 2, "found table in table collection for table '%s'", tn)
 			return table, nil
 		}
 	}
 
-	table, expiration, err := tc.leaseMgr.AcquireByName(ctx, txn.OrigTimestamp(), dbID, tn.Table())
+	table, expiration, err := tc.leaseMgr.AcquireByName(ctx, txn.OrigTimestamp(), parentID, tn.Table())
 	if err != nil {
 		if err == sqlbase.ErrDescriptorNotFound {
 			// Transform the descriptor error into an error that references the
@@ -497,15 +519,28 @@ func (tc *TableCollection) getUncommittedDatabaseID(tn *tree.TableName) (sqlbase
 	return 0, nil
 }
 
+// isUncommittedDatabase returns true if the database with the given ID
+// was modified within the transaction affiliated with the
+// LeaseCollection.
+func (tc *TableCollection) isUncommittedDatabase(id sqlbase.ID) bool {
+	for _, db := range tc.uncommittedDatabases {
+		if db.id == id {
+			return true
+		}
+	}
+	return false
+}
+
 // getUncommittedTable returns a table for the requested tablename
 // if the requested tablename is for a table modified within the transaction
-// affiliated with the LeaseCollection.
+// affiliated with the LeaseCollection. parentID is the ID of the parent
+// of the table's namespace entry.
 func (tc *TableCollection) getUncommittedTable(
-	dbID sqlbase.ID, tn *tree.TableName,
+	parentID sqlbase.ID, tn *tree.TableName,
 ) (*sqlbase.TableDescriptor, error) {
 	for _, table := range tc.uncommittedTables {
 		if table.Name == string(tn.TableName) &&
-			table.ParentID == dbID {
+			table.NamespaceParentID() == parentID {
 			if err := filterTableState(table); err != nil {
 				return nil, sqlbase.NewUndefinedRelationError(tn)
 			}
@@ -518,7 +553,7 @@ func (tc *TableCollection) getUncommittedTable(
 		// effect of it.
 		for _, rename := range table.GetRenames() {
 			if rename.OldName == string(tn.TableName) &&
-				rename.OldParentID == dbID {
+				rename.OldParentID == parentID {
 				return nil, sqlbase.NewUndefinedRelationError(tn)
 			}
 		}
@@ -527,7 +562,7 @@ func (tc *TableCollection) getUncommittedTable(
 }
 
 // getTableNames retrieves the list of qualified names of tables
-// present in the given database.
+// present in the public schema of the given database.
 func getTableNames(
 	ctx context.Context,
 	txn *client.Txn,
@@ -539,7 +574,23 @@ func getTableNames(
 		return e.tableNames(dbNameOriginallyOmitted), nil
 	}
 
-	prefix := sqlbase.MakeNameMetadataKey(dbDesc.ID, "")
+	tableNames, err := scanTableNames(ctx, txn, dbDesc.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tableNames {
+		tableNames[i].DatabaseName = tree.Name(dbDesc.Name)
+		tableNames[i].DBNameOriginallyOmitted = dbNameOriginallyOmitted
+	}
+	return tableNames, nil
+}
+
+// scanTableNames retrieves the unqualified names of the tables whose
+// namespace entries are keyed by the given parent ID.
+func scanTableNames(
+	ctx context.Context, txn *client.Txn, parentID sqlbase.ID,
+) (tree.TableNames, error) {
+	prefix := sqlbase.MakeNameMetadataKey(parentID, "")
 	sr, err := txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0)
 	if err != nil {
 		return nil, err
@@ -552,17 +603,14 @@ func getTableNames(
 		if err != nil {
 			return nil, err
 		}
-		tn := tree.TableName{
-			DatabaseName:            tree.Name(dbDesc.Name),
-			TableName:               tree.Name(tableName),
-			DBNameOriginallyOmitted: dbNameOriginallyOmitted,
-		}
-		tableNames = append(tableNames, tn)
+		tableNames = append(tableNames, tree.TableName{TableName: tree.Name(tableName)})
 	}
 	return tableNames, nil
 }
 
-func (p *planner) getAliasedTableName(n tree.TableExpr) (*tree.TableName, error) {
+func (p *planner) getAliasedTableName(
+	ctx context.Context, n tree.TableExpr,
+) (*tree.TableName, error) {
 	if ate, ok := n.(*tree.AliasedTableExpr); ok {
 		n = ate.Expr
 	}
@@ -570,7 +618,19 @@ func (p *planner) getAliasedTableName(n tree.TableExpr) (*tree.TableName, error)
 	if !ok {
 		return nil, errors.Errorf("TODO(pmattis): unsupported FROM: %s", n)
 	}
-	return table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := table.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+	if tn.DBNameOriginallyOmitted {
+		// Look up unqualified names in the schemas of the search path. If
+		// the table is not found, the name is left to refer to the public
+		// schema so that the caller reports the missing table.
+		if _, err := p.searchSessionSchemas(ctx, tn); err != nil {
+			return nil, err
+		}
+	}
+	return tn, nil
 }
 
 // createSchemaChangeJob finalizes the current mutations in the table
@@ -670,10 +730,24 @@ func expandTableGlob(
 		return nil, err
 	}
 
-	dbDesc, err := MustGetDatabaseDesc(ctx, txn, vt, string(glob.Database))
+	dbDesc, err := getDatabaseDesc(ctx, txn, vt, string(glob.Database))
 	if err != nil {
 		return nil, err
 	}
+	if dbDesc == nil {
+		// The pattern may be of the form <schema>.*, designating the
+		// tables of a schema of the current database.
+		if !glob.DBNameOriginallyOmitted && database != "" {
+			if dbDesc, err := getDatabaseDesc(ctx, txn, vt, database); err != nil {
+				return nil, err
+			} else if dbDesc != nil {
+				if scDesc, ok := dbDesc.FindSchema(string(glob.Database)); ok {
+					return getSchemaTableNames(ctx, txn, dbDesc, scDesc, true)
+				}
+			}
+		}
+		return nil, sqlbase.NewUndefinedDatabaseError(string(glob.Database))
+	}
 
 	tableNames, err := getTableNames(ctx, txn, vt, dbDesc, glob.DBNameOriginallyOmitted)
 	if err != nil {
@@ -700,17 +774,14 @@ func (p *planner) searchAndQualifyDatabase(ctx context.Context, tn *tree.TableNa
 		descFunc = getTableOrViewDesc
 	}
 
-	if p.session.Database != "" {
-		t.DatabaseName = tree.Name(p.session.Database)
-		desc, err := descFunc(ctx, p.txn, p.getVirtualTabler(), &t)
-		if err != nil && !sqlbase.IsUndefinedRelationError(err) && !sqlbase.IsUndefinedDatabaseError(err) {
-			return err
-		}
-		if desc != nil {
-			// Table was found, use this name.
-			*tn = t
-			return nil
-		}
+	// Search the schemas of the session's database, including its
+	// public schema.
+	if found, err := p.searchSessionSchemas(ctx, &t); err != nil {
+		return err
+	} else if found {
+		// Table was found, use this name.
+		*tn = t
+		return nil
 	}
 
 	// Not found using the current session's database, so try
@@ -745,6 +816,9 @@ func (p *planner) getQualifiedTableName(
 		DatabaseName: tree.Name(dbDesc.Name),
 		TableName:    tree.Name(desc.Name),
 	}
+	if scDesc, ok := dbDesc.FindSchemaByID(desc.ParentSchemaID); ok {
+		tbName.SchemaName = tree.Name(scDesc.Name)
+	}
 	return tbName.String(), nil
 }
 
@@ -774,12 +848,16 @@ func (p *planner) findTableContainingIndex(
 	result = nil
 	for i := range tns {
 		tn := &tns[i]
-		tableDesc, err := MustGetTableDesc(
+		tableDesc, err := MustGetTableOrViewDesc(
 			ctx, p.txn, p.getVirtualTabler(), tn, true, /*allowAdding*/
 		)
 		if err != nil {
 			return nil, err
 		}
+		if !tableDesc.IsPhysicalTable() {
+			// Views other than materialized views have no indexes.
+			continue
+		}
 		_, dropped, err := tableDesc.FindIndexByName(string(idxName))
 		if err != nil || dropped {
 			continue
@@ -902,6 +980,9 @@ func resolveTableNameFromID(
 	tn := tree.TableName{TableName: tree.Name(table.Name)}
 	if parentDB, ok := databases[table.ParentID]; ok {
 		tn.DatabaseName = tree.Name(parentDB.Name)
+		if scDesc, ok := parentDB.FindSchemaByID(table.ParentSchemaID); ok {
+			tn.SchemaName = tree.Name(scDesc.Name)
+		}
 	} else {
 		tn.DatabaseName = tree.Name(fmt.Sprintf("[%d]", table.ParentID))
 		log.Errorf(ctx, "relation [%d] (%q) has no parent database (corrupted schema?)",
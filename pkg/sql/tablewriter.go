@@ -343,7 +343,7 @@ func (tu *tableUpserter) init(txn *client.Txn) error {
 
 	return tu.fetcher.Init(
 		tableDesc, tu.fetchColIDtoRowIndex, &tableDesc.PrimaryIndex,
-		false /* reverse */, false /* lockForUpdate */, false, /* isSecondaryIndex */
+		false /* reverse */, sqlbase.ScanLocking{}, false, /* isSecondaryIndex */
 		tu.fetchCols, valNeededForCol, false /*returnRangeInfo*/, tu.alloc)
 }
 
@@ -416,6 +416,15 @@ func (tu *tableUpserter) flush(
 		rowIdxToRetIdx[i] = colIDToRetIndex[col.ID]
 	}
 
+	var computed *sqlbase.ComputedColumns
+	if tu.collectRows {
+		// The computed columns of the inserted rows are computed again for
+		// rowTemplate.
+		if computed, err = sqlbase.MakeComputedColumns(tableDesc, tableDesc.Columns); err != nil {
+			return nil, err
+		}
+	}
+
 	b := tu.txn.NewBatch()
 	for i := 0; i < tu.insertRows.Len(); i++ {
 		insertRow := tu.insertRows.At(i)
@@ -431,6 +440,9 @@ func (tu *tableUpserter) flush(
 				for i, val := range insertRow {
 					rowTemplate[rowIdxToRetIdx[i]] = val
 				}
+				if err := computed.Eval(colIDToRetIndex, rowTemplate); err != nil {
+					return nil, err
+				}
 
 				_, err = tu.rowsUpserted.AddRow(ctx, rowTemplate)
 				if err != nil {
@@ -812,7 +824,7 @@ func (td *tableDeleter) deleteAllRowsScan(
 	var rf sqlbase.RowFetcher
 	err := rf.Init(
 		td.rd.Helper.TableDesc, td.rd.FetchColIDtoRowIndex, &td.rd.Helper.TableDesc.PrimaryIndex,
-		false /* reverse */, false /* lockForUpdate */, false, /* isSecondaryIndex */
+		false /* reverse */, sqlbase.ScanLocking{}, false, /* isSecondaryIndex */
 		td.rd.FetchCols, valNeededForCol, false /* returnRangeInfo */, td.alloc)
 	if err != nil {
 		return resume, err
@@ -900,7 +912,7 @@ func (td *tableDeleter) deleteIndexScan(
 	var rf sqlbase.RowFetcher
 	err := rf.Init(
 		td.rd.Helper.TableDesc, td.rd.FetchColIDtoRowIndex, &td.rd.Helper.TableDesc.PrimaryIndex,
-		false /* reverse */, false /* lockForUpdate */, false, /* isSecondaryIndex */
+		false /* reverse */, sqlbase.ScanLocking{}, false, /* isSecondaryIndex */
 		td.rd.FetchCols, valNeededForCol, false /* returnRangeInfo */, td.alloc)
 	if err != nil {
 		return resume, err
@@ -0,0 +1,533 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// Temporary tables live in a user-defined schema which is private to the
+// session that created them. Every session is assigned a schema name of
+// the form pg_temp_<N> when it starts, where N is a cluster-wide unique
+// integer, and creates a schema with that name in each database where it
+// creates temporary tables. Unqualified table names are looked up in that
+// schema first.
+//
+// The temporary schemas of a session are dropped along with their tables
+// when the session closes. The ID of the node the session runs on is
+// stored in the lower bits of N (see builtins.GenerateUniqueInt), which
+// lets a node recognize, and drop, the temporary schemas left behind by
+// sessions that did not close properly, for example because the node
+// crashed. The temporary schemas of the nodes which are dead according to
+// their liveness record are dropped by the live nodes.
+
+const (
+	// reservedSchemaPrefix is the prefix of the names of the schemas
+	// managed by the system, which cannot be used for user-defined schemas.
+	reservedSchemaPrefix = "pg_"
+	// tempSchemaPrefix is the prefix of the names of temporary schemas.
+	tempSchemaPrefix = "pg_temp_"
+	// pgTempSchemaName designates the temporary schema of the session in
+	// the search_path.
+	pgTempSchemaName = "pg_temp"
+	// tempSchemaNodeIDBits is the number of lower bits of the integers
+	// generated by builtins.GenerateUniqueInt which hold the node ID.
+	tempSchemaNodeIDBits = 15
+	// tempSchemaCleanupInterval is the interval at which each node looks
+	// for orphaned temporary schemas.
+	tempSchemaCleanupInterval = 30 * time.Minute
+)
+
+// makeTempSchemaName generates the name of the temporary schema of a
+// session running on the given node.
+func makeTempSchemaName(nodeID roachpb.NodeID) string {
+	return fmt.Sprintf("%s%d", tempSchemaPrefix, builtins.GenerateUniqueInt(nodeID))
+}
+
+// IsTempSchemaName returns whether the given schema name designates the
+// temporary schema of a session.
+func IsTempSchemaName(name string) bool {
+	return strings.HasPrefix(name, tempSchemaPrefix)
+}
+
+// tempSchemaNodeID returns the ID of the node on which runs, or ran, the
+// session owning the given temporary schema.
+func tempSchemaNodeID(name string) (roachpb.NodeID, bool) {
+	if !IsTempSchemaName(name) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(name, tempSchemaPrefix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return roachpb.NodeID(n & (1<<tempSchemaNodeIDBits - 1)), true
+}
+
+// checkSchemaName verifies that a name can be given to a user-defined
+// schema.
+func checkSchemaName(name string) error {
+	if strings.HasPrefix(name, reservedSchemaPrefix) {
+		return pgerror.NewErrorf(pgerror.CodeReservedNameError,
+			"unacceptable schema name %q", name).SetDetailf(
+			"The prefix %q is reserved for system schemas.", reservedSchemaPrefix)
+	}
+	return nil
+}
+
+func errTempTableOfOtherSession() error {
+	return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+		"cannot access temporary tables of other sessions")
+}
+
+// isTempTable returns whether the given table belongs to a temporary
+// schema.
+func isTempTable(
+	ctx context.Context, txn *client.Txn, desc *sqlbase.TableDescriptor,
+) (bool, error) {
+	if desc.ParentSchemaID == 0 {
+		return false, nil
+	}
+	dbDesc, err := sqlbase.GetDatabaseDescFromID(ctx, txn, desc.ParentID)
+	if err != nil {
+		return false, err
+	}
+	scDesc, ok := dbDesc.FindSchemaByID(desc.ParentSchemaID)
+	return ok && IsTempSchemaName(scDesc.Name), nil
+}
+
+// resolveTempTableSchema resolves the database of a new temporary table,
+// and qualifies its name with the temporary schema of the session. A nil
+// schema descriptor is returned if the session has not created its
+// temporary schema in the database yet.
+func (p *planner) resolveTempTableSchema(
+	ctx context.Context, tn *tree.TableName,
+) (*sqlbase.DatabaseDescriptor, *sqlbase.SchemaDescriptor, error) {
+	if p.session.tempSchemaName == "" {
+		return nil, nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"temporary tables are not supported in this session")
+	}
+	vt := p.getVirtualTabler()
+	normalizeSchemaPrefix(vt, tn)
+	dbDesc, err := getDatabaseDesc(ctx, p.txn, vt, tn.Database())
+	if err != nil {
+		return nil, nil, err
+	}
+	if dbDesc == nil {
+		// The name may be of the form <schema>.<table>.
+		t := *tn
+		if qualifyWithSessionSchema(&t) {
+			dbDesc, err = getDatabaseDesc(ctx, p.txn, vt, t.Database())
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if dbDesc == nil {
+			return nil, nil, sqlbase.NewUndefinedDatabaseError(tn.Database())
+		}
+		*tn = t
+	}
+	if s := tn.Schema(); s != "" && s != p.session.tempSchemaName {
+		return nil, nil, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+			"cannot create temporary relation in non-temporary schema")
+	}
+	if p.session.virtualSchemas.isVirtualDatabase(dbDesc.Name) {
+		return nil, nil, fmt.Errorf("cannot create a temporary table in virtual database %q", dbDesc.Name)
+	}
+	tn.SchemaName = tree.Name(p.session.tempSchemaName)
+	scDesc, _ := dbDesc.FindSchema(p.session.tempSchemaName)
+	return dbDesc, scDesc, nil
+}
+
+// searchTempSchema qualifies an unqualified table name with the temporary
+// schema of the session if it designates one of its temporary tables,
+// which shadow the tables of the other schemas. It is used by the
+// statements which otherwise resolve unqualified names in the public
+// schema.
+func (p *planner) searchTempSchema(ctx context.Context, tn *tree.TableName) error {
+	if !tn.DBNameOriginallyOmitted || tn.SchemaName != "" || len(p.session.tempSchemaDBs) == 0 {
+		return nil
+	}
+	t := *tn
+	t.SchemaName = tree.Name(p.session.tempSchemaName)
+	desc, err := getTableOrViewDesc(ctx, p.txn, p.getVirtualTabler(), &t)
+	if err != nil {
+		if sqlbase.IsUndefinedSchemaError(err) {
+			return nil
+		}
+		return err
+	}
+	if desc != nil {
+		*tn = t
+	}
+	return nil
+}
+
+// createTempSchema creates the temporary schema of the session in the
+// given database.
+func (p *planner) createTempSchema(
+	ctx context.Context, dbDesc *sqlbase.DatabaseDescriptor,
+) (*sqlbase.SchemaDescriptor, error) {
+	id, err := GenerateUniqueDescID(ctx, p.session.execCfg.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	// The tables of the schema inherit its privileges, which are granted to
+	// the session user instead of being inherited from the database.
+	privs := sqlbase.NewDefaultPrivilegeDescriptor()
+	privs.Grant(p.session.User, privilege.List{privilege.ALL})
+	dbDesc.Schemas = append(dbDesc.Schemas, sqlbase.SchemaDescriptor{
+		Name:       p.session.tempSchemaName,
+		ID:         id,
+		Privileges: privs,
+	})
+	if err := p.writeDatabaseDesc(ctx, dbDesc); err != nil {
+		return nil, err
+	}
+	p.session.addTempSchemaDatabase(dbDesc.ID)
+	return &dbDesc.Schemas[len(dbDesc.Schemas)-1], nil
+}
+
+// checkTempTableReferences verifies that the foreign keys and the
+// interleaves of a new table only involve tables of the same kind:
+// temporary tables of the session for a temporary table, and permanent
+// tables otherwise.
+func (p *planner) checkTempTableReferences(
+	ctx context.Context, desc *sqlbase.TableDescriptor, temporary bool,
+) error {
+	var refIDs []sqlbase.ID
+	for _, idx := range desc.AllNonDropIndexes() {
+		if idx.ForeignKey.IsSet() && idx.ForeignKey.Table != desc.ID {
+			refIDs = append(refIDs, idx.ForeignKey.Table)
+		}
+		for _, ancestor := range idx.Interleave.Ancestors {
+			refIDs = append(refIDs, ancestor.TableID)
+		}
+	}
+	for _, id := range refIDs {
+		ref, err := sqlbase.GetTableDescFromID(ctx, p.txn, id)
+		if err != nil {
+			return err
+		}
+		refTemp, err := isTempTable(ctx, p.txn, ref)
+		if err != nil {
+			return err
+		}
+		switch {
+		case temporary && !refTemp:
+			return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"constraints on temporary tables may reference only temporary tables")
+		case !temporary && refTemp:
+			return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"constraints on permanent tables may reference only permanent tables")
+		case temporary && ref.ParentSchemaID != desc.ParentSchemaID:
+			return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"constraints on temporary tables must involve temporary tables of this session")
+		}
+	}
+	return nil
+}
+
+// dropTempSchemas returns a planNode which drops the temporary schema
+// with the given name from each of the given databases, along with its
+// tables. Databases which do not exist anymore, or do not contain the
+// schema, are skipped.
+func (p *planner) dropTempSchemas(
+	ctx context.Context, scName string, dbIDs []sqlbase.ID,
+) (planNode, error) {
+	var names tree.SchemaNames
+	for _, id := range dbIDs {
+		dbDesc, err := sqlbase.GetDatabaseDescFromID(ctx, p.txn, id)
+		if err == sqlbase.ErrDescriptorNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if _, ok := dbDesc.FindSchema(scName); ok {
+			names = append(names, tree.SchemaName{
+				DatabaseName: tree.Name(dbDesc.Name),
+				SchemaName:   tree.Name(scName),
+			})
+		}
+	}
+	if len(names) == 0 {
+		return &zeroNode{}, nil
+	}
+	return p.DropSchema(ctx, &tree.DropSchema{Names: names, DropBehavior: tree.DropCascade})
+}
+
+// DiscardTemp implements DISCARD TEMP, which drops all the temporary
+// tables of the session.
+func (p *planner) DiscardTemp(ctx context.Context) (planNode, error) {
+	return p.dropTempSchemas(ctx, p.session.tempSchemaName, p.session.tempSchemaDatabases())
+}
+
+// runTempTableTxn runs fn in a new transaction, with an internal planner
+// acting as root, and then runs the schema changes fn has scheduled.
+func (e *Executor) runTempTableTxn(
+	ctx context.Context, opName string, fn func(context.Context, *planner) error,
+) error {
+	var schemaChangers schemaChangerCollection
+	if err := e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		p := makeInternalPlanner(opName, txn, security.RootUser, e.cfg.LeaseManager.memMetrics)
+		defer finishInternalPlanner(p)
+		p.session.execCfg = &e.cfg
+		p.session.tables.leaseMgr = e.cfg.LeaseManager
+		p.evalCtx.NodeID = e.cfg.NodeID.Get()
+		if err := fn(ctx, p); err != nil {
+			return err
+		}
+		schemaChangers = p.session.TxnState.schemaChangers
+		return nil
+	}); err != nil {
+		return err
+	}
+	return schemaChangers.execSchemaChanges(ctx, e, nil /* session */)
+}
+
+// dropTempSchemas drops the temporary schema with the given name from each
+// of the given databases, along with its tables.
+func (e *Executor) dropTempSchemas(ctx context.Context, scName string, dbIDs []sqlbase.ID) error {
+	return e.runTempTableTxn(ctx, "drop-temp-schema", func(ctx context.Context, p *planner) error {
+		plan, err := p.dropTempSchemas(ctx, scName, dbIDs)
+		if err != nil {
+			return err
+		}
+		defer plan.Close(ctx)
+		return p.startPlan(ctx, plan)
+	})
+}
+
+// runOnCommitActions carries out the ON COMMIT actions of the temporary
+// tables of the session as part of txn, which is about to commit. It
+// returns the IDs of the tables dropped by the actions, whose actions are
+// to be forgotten once txn has committed.
+func (s *Session) runOnCommitActions(
+	ctx context.Context, e *Executor, txn *client.Txn,
+) ([]sqlbase.ID, error) {
+	if len(s.tempTablesOnCommit) == 0 {
+		return nil, nil
+	}
+	p := s.newPlanner(e, txn)
+	var dropped []sqlbase.ID
+	for id, action := range s.tempTablesOnCommit {
+		desc, err := sqlbase.GetTableDescFromID(ctx, txn, id)
+		if err == sqlbase.ErrDescriptorNotFound {
+			// The table was created by a transaction that was rolled back.
+			delete(s.tempTablesOnCommit, id)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if desc.Dropped() {
+			continue
+		}
+		switch action {
+		case tree.OnCommitDeleteRows:
+			span := desc.TableSpan()
+			if s.Tracing.KVTracingEnabled() {
+				log.VEventf(ctx, 2, "DelRange %s - %s", span.Key, span.EndKey)
+			}
+			if err := txn.DelRange(ctx, span.Key, span.EndKey); err != nil {
+				return nil, err
+			}
+		case tree.OnCommitDrop:
+			if _, err := p.dropTableImpl(ctx, desc); err != nil {
+				return nil, err
+			}
+			dropped = append(dropped, id)
+		}
+	}
+	return dropped, nil
+}
+
+// commitWithOnCommitActions carries out the ON COMMIT actions of the
+// temporary tables of the session as part of txn, and commits txn. The
+// actions thus happen atomically with the transaction: if they fail, the
+// transaction isn't committed.
+func (s *Session) commitWithOnCommitActions(
+	ctx context.Context, e *Executor, txn *client.Txn,
+) error {
+	dropped, err := s.runOnCommitActions(ctx, e, txn)
+	if err != nil {
+		return err
+	}
+	if err := txn.Commit(ctx); err != nil {
+		return err
+	}
+	for _, id := range dropped {
+		delete(s.tempTablesOnCommit, id)
+	}
+	return nil
+}
+
+// addTempSchemaDatabase records that the session has created its
+// temporary schema in the database with the given ID.
+func (s *Session) addTempSchemaDatabase(id sqlbase.ID) {
+	if s.tempSchemaDBs == nil {
+		s.tempSchemaDBs = make(map[sqlbase.ID]struct{})
+	}
+	s.tempSchemaDBs[id] = struct{}{}
+}
+
+// tempSchemaDatabases returns the IDs of the databases in which the
+// session may have created its temporary schema.
+func (s *Session) tempSchemaDatabases() []sqlbase.ID {
+	ids := make([]sqlbase.ID, 0, len(s.tempSchemaDBs))
+	for id := range s.tempSchemaDBs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// dropTempSchemas drops the temporary schemas of a closing session.
+func (s *Session) dropTempSchemas(e *Executor) {
+	if len(s.tempSchemaDBs) == 0 {
+		return
+	}
+	if err := e.dropTempSchemas(s.context, s.tempSchemaName, s.tempSchemaDatabases()); err != nil {
+		// The schemas are eventually dropped by cleanupOrphanedTempSchemas.
+		log.Warningf(s.context, "error dropping temporary schema %s: %s", s.tempSchemaName, err)
+	}
+}
+
+// tempSchemaNames returns the names of the temporary schemas of the
+// registered sessions.
+func (r *SessionRegistry) tempSchemaNames() map[string]struct{} {
+	r.Lock()
+	defer r.Unlock()
+	names := make(map[string]struct{}, len(r.store))
+	for s := range r.store {
+		names[s.tempSchemaName] = struct{}{}
+	}
+	return names
+}
+
+// startTempSchemaCleaner starts a worker which periodically drops the
+// orphaned temporary schemas of the cluster. The first cleanup happens as
+// soon as the system config is available, so that the temporary schemas
+// left behind when the node crashed are dropped upon restart.
+func (e *Executor) startTempSchemaCleaner(ctx context.Context) {
+	gossipUpdateC := e.cfg.Gossip.RegisterSystemConfigChannel()
+	e.stopper.RunWorker(ctx, func(ctx context.Context) {
+		select {
+		case <-gossipUpdateC:
+		case <-e.stopper.ShouldQuiesce():
+			return
+		}
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			e.cleanupOrphanedTempSchemas(ctx)
+			timer.Reset(tempSchemaCleanupInterval)
+			select {
+			case <-timer.C:
+				timer.Read = true
+			case <-e.stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// cleanupOrphanedTempSchemas drops the temporary schemas of the sessions
+// of the node which are not registered anymore, as well as those of the
+// sessions of the dead nodes, which may never restart to drop them.
+// Several nodes may attempt to drop the schemas of a dead node at the same
+// time; dropTempSchemas skips the schemas dropped in the meantime.
+func (e *Executor) cleanupOrphanedTempSchemas(ctx context.Context) {
+	cfg, _ := e.cfg.Gossip.GetSystemConfig()
+	nodeID := e.cfg.NodeID.Get()
+	// The sessions are listed after the descriptors have been read, so
+	// that the schemas of the sessions started in the meantime, which are
+	// not in the system config, are not mistaken for orphans.
+	live := e.cfg.SessionRegistry.tempSchemaNames()
+
+	orphans := make(map[string][]sqlbase.ID)
+	descKeyPrefix := keys.MakeTablePrefix(uint32(sqlbase.DescriptorTable.ID))
+	for _, kv := range cfg.Values {
+		if !bytes.HasPrefix(kv.Key, descKeyPrefix) {
+			continue
+		}
+		var desc sqlbase.Descriptor
+		if err := kv.Value.GetProto(&desc); err != nil {
+			log.Warningf(ctx, "%s: unable to unmarshal descriptor %v", kv.Key, kv.Value)
+			continue
+		}
+		dbDesc := desc.GetDatabase()
+		if dbDesc == nil {
+			continue
+		}
+		for _, scDesc := range dbDesc.Schemas {
+			id, ok := tempSchemaNodeID(scDesc.Name)
+			if !ok {
+				continue
+			}
+			if id == nodeID {
+				if _, ok := live[scDesc.Name]; ok {
+					continue
+				}
+			} else if !e.isNodeDead(id) {
+				continue
+			}
+			orphans[scDesc.Name] = append(orphans[scDesc.Name], dbDesc.ID)
+		}
+	}
+
+	for scName, dbIDs := range orphans {
+		log.Infof(ctx, "dropping orphaned temporary schema %s", scName)
+		if err := e.dropTempSchemas(ctx, scName, dbIDs); err != nil {
+			log.Warningf(ctx, "error dropping temporary schema %s: %s", scName, err)
+		}
+	}
+}
+
+// isNodeDead returns whether the liveness record of the given node expired
+// for longer than server.time_until_store_dead, after which the node is
+// considered dead. A node of which nothing is known is not.
+func (e *Executor) isNodeDead(nodeID roachpb.NodeID) bool {
+	if e.cfg.NodeLiveness == nil {
+		return false
+	}
+	liveness, err := e.cfg.NodeLiveness.GetLiveness(nodeID)
+	if err != nil {
+		return false
+	}
+	deadline := hlc.Timestamp(liveness.Expiration).Add(
+		storage.TimeUntilStoreDead.Get(&e.cfg.Settings.SV).Nanoseconds(), 0)
+	return deadline.Less(e.cfg.Clock.Now())
+}
@@ -0,0 +1,178 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// tempSchemaNamesOf returns the names of the temporary schemas of the given
+// database.
+func tempSchemaNamesOf(r *sqlutils.SQLRunner, db string) []string {
+	rows := r.QueryStr(fmt.Sprintf(
+		`SELECT nspname FROM %s.pg_catalog.pg_namespace WHERE nspname LIKE 'pg_temp_%%' ORDER BY 1`, db))
+	var names []string
+	for _, row := range rows {
+		names = append(names, row[0])
+	}
+	return names
+}
+
+// renameSchema renames a user-defined schema by rewriting the descriptor of
+// its database, which allows giving it the name of a temporary schema.
+func renameSchema(t *testing.T, kvDB *client.DB, db, from, to string) {
+	if err := kvDB.Txn(context.TODO(), func(ctx context.Context, txn *client.Txn) error {
+		if err := txn.SetSystemConfigTrigger(); err != nil {
+			return err
+		}
+		gr, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, db))
+		if err != nil {
+			return err
+		}
+		dbDesc, err := sqlbase.GetDatabaseDescFromID(ctx, txn, sqlbase.ID(gr.ValueInt()))
+		if err != nil {
+			return err
+		}
+		for i := range dbDesc.Schemas {
+			if dbDesc.Schemas[i].Name == from {
+				dbDesc.Schemas[i].Name = to
+				return txn.Put(ctx, sqlbase.MakeDescMetadataKey(dbDesc.ID), sqlbase.WrapDescriptor(dbDesc))
+			}
+		}
+		return errors.Errorf("schema %q not found", from)
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDropTempSchemasOnSessionClose(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	r := sqlutils.MakeSQLRunner(t, sqlDB)
+	r.Exec(`CREATE DATABASE d`)
+	r.Exec(`CREATE DATABASE e`)
+
+	pgURL, cleanupFunc := sqlutils.PGUrl(
+		t, s.ServingAddr(), "TestDropTempSchemasOnSessionClose", url.User(security.RootUser))
+	defer cleanupFunc()
+	conn, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The temporary tables must be created by the same session.
+	conn.SetMaxOpenConns(1)
+	for _, stmt := range []string{
+		`CREATE TEMP TABLE d.t (a INT PRIMARY KEY)`,
+		`CREATE TEMP TABLE d.u (a INT PRIMARY KEY)`,
+		`CREATE TEMP TABLE e.t (a INT PRIMARY KEY)`,
+		`INSERT INTO d.t VALUES (1)`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, db := range []string{"d", "e"} {
+		if names := tempSchemaNamesOf(r, db); len(names) != 1 {
+			t.Fatalf("expected a temporary schema in %s, found %v", db, names)
+		}
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.SucceedsSoon(t, func() error {
+		for _, db := range []string{"d", "e"} {
+			if names := tempSchemaNamesOf(r, db); len(names) != 0 {
+				return errors.Errorf("temporary schemas %v of %s not dropped yet", names, db)
+			}
+		}
+		return nil
+	})
+	r.CheckQueryResults(
+		`SELECT count(*) FROM crdb_internal.tables WHERE database_name IN ('d', 'e') AND state = 'PUBLIC'`,
+		[][]string{{"0"}},
+	)
+}
+
+func TestCleanupOrphanedTempSchemas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numNodes = 3
+	tc := serverutils.StartTestCluster(t, numNodes, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+	})
+	defer tc.Stopper().Stop(context.TODO())
+
+	r := sqlutils.MakeSQLRunner(t, tc.ServerConn(0))
+	kvDB := tc.Server(0).KVClient().(*client.DB)
+	// The nodes are considered dead as soon as their liveness record expires.
+	r.Exec(`SET CLUSTER SETTING server.time_until_store_dead = '1ms'`)
+	r.Exec(`CREATE DATABASE d`)
+
+	// Each node gets a temporary schema, which isn't the one of a session of
+	// the node.
+	var names []string
+	for i := 0; i < numNodes; i++ {
+		sc := fmt.Sprintf("s%d", i)
+		r.Exec(fmt.Sprintf(`CREATE SCHEMA d.%s`, sc))
+		r.Exec(fmt.Sprintf(`CREATE TABLE d.%s.t (a INT PRIMARY KEY)`, sc))
+		name := fmt.Sprintf("%s%d", tempSchemaPrefix, builtins.GenerateUniqueInt(tc.Server(i).NodeID()))
+		renameSchema(t, kvDB, "d", sc, name)
+		names = append(names, name)
+	}
+	if found := tempSchemaNamesOf(r, "d"); !reflect.DeepEqual(found, names) {
+		t.Fatalf("expected temporary schemas %v, found %v", names, found)
+	}
+
+	// The third node dies, and the first one drops its own orphaned schema as
+	// well as the schema of the dead node, but not the schema of the second
+	// node, which may still be in use.
+	tc.StopServer(2)
+
+	e := tc.Server(0).Executor().(*Executor)
+	testutils.SucceedsSoon(t, func() error {
+		e.cleanupOrphanedTempSchemas(context.TODO())
+		if found := tempSchemaNamesOf(r, "d"); !reflect.DeepEqual(found, names[1:2]) {
+			return errors.Errorf("expected temporary schemas %v, found %v", names[1:2], found)
+		}
+		return nil
+	})
+	r.CheckQueryResults(
+		`SELECT schema_name FROM crdb_internal.tables WHERE database_name = 'd' AND state = 'PUBLIC'`,
+		[][]string{{names[1]}},
+	)
+}
@@ -49,6 +49,9 @@ func (p *planner) Truncate(ctx context.Context, n *tree.Truncate) (planNode, err
 		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
 			return nil, err
 		}
+		if err := p.searchTempSchema(ctx, tn); err != nil {
+			return nil, err
+		}
 
 		tableDesc, err := MustGetTableOrViewDesc(
 			ctx, p.txn, p.getVirtualTabler(), tn, true, /* allowAdding */
@@ -107,7 +110,7 @@ func (p *planner) Truncate(ctx context.Context, n *tree.Truncate) (planNode, err
 	// TODO(knz): move truncate logic to Start/Next so it can be used with SHOW TRACE FOR.
 	traceKV := p.session.Tracing.KVTracingEnabled()
 	for id := range toTruncate {
-		if err := p.truncateTable(p.session.Ctx(), id, traceKV); err != nil {
+		if _, err := p.truncateTable(p.session.Ctx(), id, traceKV); err != nil {
 			return nil, err
 		}
 	}
@@ -117,13 +120,16 @@ func (p *planner) Truncate(ctx context.Context, n *tree.Truncate) (planNode, err
 
 // truncateTable truncates the data of a table in a single transaction. It
 // drops the table and recreates it with a new ID. The dropped table is
-// GC-ed later through an asynchrnous schema change.
-func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool) error {
+// GC-ed later through an asynchrnous schema change. The descriptor of the
+// recreated table is returned.
+func (p *planner) truncateTable(
+	ctx context.Context, id sqlbase.ID, traceKV bool,
+) (*sqlbase.TableDescriptor, error) {
 	// Read the table descriptor because it might have changed
 	// while another table in the truncation list was truncated.
 	tableDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	newTableDesc := *tableDesc
 	newTableDesc.SetID(0)
@@ -139,34 +145,34 @@ func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool
 	}
 	b.CPut(nameKey, nil, tableDesc.ID)
 	if err := p.txn.Run(ctx, b); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Drop table.
 	if err := p.initiateDropTable(ctx, tableDesc); err != nil {
-		return err
+		return nil, err
 	}
 
 	newID, err := GenerateUniqueDescID(ctx, p.session.execCfg.DB)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// update all the references to this table.
 	tables, err := p.findAllReferences(ctx, *tableDesc)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := reassignReferencedTables(tables, tableDesc.ID, newID); err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, table := range tables {
 		if err := table.SetUpVersion(); err != nil {
-			return err
+			return nil, err
 		}
 		if err := p.writeTableDesc(ctx, table); err != nil {
-			return err
+			return nil, err
 		}
 		p.notifySchemaChange(table, sqlbase.InvalidMutationID)
 	}
@@ -175,13 +181,13 @@ func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool
 	if err := reassignReferencedTables(
 		[]*sqlbase.TableDescriptor{&newTableDesc}, tableDesc.ID, newID,
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Add new descriptor.
 	newTableDesc.State = sqlbase.TableDescriptor_ADD
 	if err := newTableDesc.SetUpVersion(); err != nil {
-		return err
+		return nil, err
 	}
 	// Resolve all outstanding mutations. Make all new schema elements
 	// public because the table is empty and doesn't need to be backfilled.
@@ -196,10 +202,10 @@ func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool
 		}
 	}
 	newTableDesc.Mutations = nil
-	tKey := tableKey{parentID: newTableDesc.ParentID, name: newTableDesc.Name}
+	tKey := tableKey{parentID: newTableDesc.NamespaceParentID(), name: newTableDesc.Name}
 	key := tKey.Key()
 	if err := p.createDescriptorWithID(ctx, key, newID, &newTableDesc); err != nil {
-		return err
+		return nil, err
 	}
 	p.notifySchemaChange(&newTableDesc, sqlbase.InvalidMutationID)
 
@@ -207,19 +213,21 @@ func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool
 	b = &client.Batch{}
 	b.Get(zoneKey)
 	if err := p.txn.Run(ctx, b); err != nil {
-		return err
+		return nil, err
 	}
 	val := b.Results[0].Rows[0].Value
 	if val == nil {
-		return nil
+		return &newTableDesc, nil
 	}
 	zoneCfg, err := val.GetBytes()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	const insertZoneCfg = `INSERT INTO system.zones (id, config) VALUES ($1, $2)`
-	_, err = p.exec(ctx, insertZoneCfg, newID, zoneCfg)
-	return err
+	if _, err := p.exec(ctx, insertZoneCfg, newID, zoneCfg); err != nil {
+		return nil, err
+	}
+	return &newTableDesc, nil
 }
 
 // For all the references from a table
@@ -44,6 +44,9 @@ type editNodeBase struct {
 	p         *planner
 	rh        *returningHelper
 	tableDesc *sqlbase.TableDescriptor
+	// join is set when the target table is joined with other data sources,
+	// as in UPDATE ... FROM and DELETE ... USING.
+	join *editJoinHelper
 }
 
 func (p *planner) makeEditNode(
@@ -89,7 +92,12 @@ func (r *editNodeRun) initEditNode(
 	r.rows = rows
 	r.tw = tw
 
-	rh, err := en.p.newReturningHelper(ctx, re, desiredTypes, tn, en.tableDesc.Columns)
+	var joined *dataSourceInfo
+	if en.join != nil {
+		tn = &en.join.targetName
+		joined = en.join.sourceInfo
+	}
+	rh, err := en.p.newReturningHelper(ctx, re, desiredTypes, tn, en.tableDesc.Columns, joined)
 	if err != nil {
 		return err
 	}
@@ -109,6 +117,25 @@ func (r *editNodeRun) startEditNode(params runParams, en *editNodeBase) error {
 	return r.rows.Start(params)
 }
 
+// nextRow advances the source plan to the next row to modify. When the
+// target table is joined with other data sources, the target rows already
+// modified by the statement are skipped.
+func (r *editNodeRun) nextRow(params runParams, en *editNodeBase, numFetchCols int) (bool, error) {
+	for {
+		next, err := r.rows.Next(params)
+		if !next || en.join == nil {
+			return next, err
+		}
+		seen, err := en.join.seenRow(params, r.rows.Values()[:numFetchCols])
+		if err != nil {
+			return false, err
+		}
+		if !seen {
+			return true, nil
+		}
+	}
+}
+
 type updateNode struct {
 	// The following fields are populated during makePlan.
 	editNodeBase
@@ -212,7 +239,7 @@ func (p *planner) Update(
 
 	tracing.AnnotateTrace()
 
-	tn, err := p.getAliasedTableName(n.Table)
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -225,7 +252,9 @@ func (p *planner) Update(
 	setExprs := make([]*tree.UpdateExpr, len(n.Exprs))
 	for i, expr := range n.Exprs {
 		// Replace the sub-query nodes.
-		newExpr, err := p.replaceSubqueries(ctx, expr.Expr, len(expr.Names))
+		newExpr, err := p.replaceSubqueries(
+			ctx, expr.Expr, len(expr.Names), nil /* sources */, tree.IndexedVarHelper{},
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -248,8 +277,9 @@ func (p *planner) Update(
 		return nil, err
 	}
 
+	_, retExprs := n.Returning.(*tree.ReturningExprs)
 	var requestedCols []sqlbase.ColumnDescriptor
-	if _, retExprs := n.Returning.(*tree.ReturningExprs); retExprs || len(en.tableDesc.Checks) > 0 {
+	if retExprs || len(en.tableDesc.Checks) > 0 {
 		// TODO(dan): This could be made tighter, just the rows needed for RETURNING
 		// exprs.
 		requestedCols = en.tableDesc.Columns
@@ -270,11 +300,17 @@ func (p *planner) Update(
 
 	// We construct a query containing the columns being updated, and then later merge the values
 	// they are being updated with into that renderNode to ideally reuse some of the queries.
+	// With a FROM clause, the query joins the table with the other data sources.
+	targetName := editTargetName(n.Table, tn)
+	fetchExprs := sqlbase.ColumnsSelectors(ru.FetchCols)
+	if len(n.From) > 0 {
+		fetchExprs = editColumnsSelectors(ru.FetchCols, targetName)
+	}
 	rows, err := p.SelectClause(ctx, &tree.SelectClause{
-		Exprs: sqlbase.ColumnsSelectors(ru.FetchCols),
-		From:  &tree.From{Tables: []tree.TableExpr{n.Table}},
+		Exprs: fetchExprs,
+		From:  &tree.From{Tables: append(tree.TableExprs{n.Table}, n.From...)},
 		Where: n.Where,
-	}, nil /* orderBy */, nil /* limit */, false, /* lockForUpdate */
+	}, nil /* orderBy */, nil /* limit */, sqlbase.ScanLocking{},
 		nil /* desiredTypes */, publicAndNonPublicColumns)
 	if err != nil {
 		return nil, err
@@ -358,6 +394,14 @@ func (p *planner) Update(
 		}
 	}
 
+	if len(n.From) > 0 {
+		en.join, err = newEditJoinHelper(
+			p, render, targetName, en.tableDesc, ru.FetchColIDtoRowIndex, retExprs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	updateColsIdx := make(map[sqlbase.ColumnID]int, len(ru.UpdateCols))
 	for i, col := range ru.UpdateCols {
 		updateColsIdx[col.ID] = i
@@ -392,12 +436,15 @@ func (u *updateNode) Start(params runParams) error {
 func (u *updateNode) Close(ctx context.Context) {
 	u.run.rows.Close(ctx)
 	u.tw.close(ctx)
+	if u.join != nil {
+		u.join.close(ctx, u.p.session)
+	}
 	*u = updateNode{}
 	updateNodePool.Put(u)
 }
 
 func (u *updateNode) Next(params runParams) (bool, error) {
-	next, err := u.run.rows.Next(params)
+	next, err := u.run.nextRow(params, &u.editNodeBase, len(u.tw.ru.FetchCols))
 	if !next {
 		if err == nil {
 			if err := params.p.cancelChecker.Check(); err != nil {
@@ -412,6 +459,10 @@ func (u *updateNode) Next(params runParams) (bool, error) {
 	tracing.AnnotateTrace()
 
 	entireRow := u.run.rows.Values()
+	var sourceVals tree.Datums
+	if u.join != nil {
+		sourceVals = u.join.sourceValues(entireRow)
+	}
 
 	// Our updated value expressions occur immediately after the plain
 	// columns in the output.
@@ -459,6 +510,9 @@ func (u *updateNode) Next(params runParams) (bool, error) {
 		return false, err
 	}
 
+	if u.join != nil {
+		newValues = u.join.returningRow(newValues, len(u.tableDesc.Columns), sourceVals)
+	}
 	resultRow, err := u.rh.cookResultRow(newValues)
 	if err != nil {
 		return false, err
@@ -229,7 +229,7 @@ func upsertExprsAndIndex(
 	}
 
 	indexMatch := func(index sqlbase.IndexDescriptor) bool {
-		if !index.Unique {
+		if !index.Unique || index.IsPartial() {
 			return false
 		}
 		if len(index.ColumnNames) != len(onConflict.Columns) {
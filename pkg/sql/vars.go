@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/pkg/errors"
 )
@@ -302,7 +303,7 @@ var varGen = map[string]sessionVar{
 			// and not a standard name, then we use a magic format in the Location's
 			// name. We attempt to parse that here and retrieve the original offset
 			// specified by the user.
-			_, origRepr, parsed := sqlbase.ParseFixedOffsetTimeZone(session.Location.String())
+			_, origRepr, parsed := timeutil.ParseFixedOffsetTimeZone(session.Location.String())
 			if parsed {
 				return origRepr
 			}
@@ -366,6 +367,34 @@ var varGen = map[string]sessionVar{
 			return enableTracing(session, values)
 		},
 	},
+
+	`vectorize`: {
+		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
+			s, err := getStringVal(session, `vectorize`, values)
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(s) {
+			case "off":
+				session.Vectorize = false
+			case "on":
+				session.Vectorize = true
+			default:
+				return fmt.Errorf("set vectorize: \"%s\" not supported", s)
+			}
+			return nil
+		},
+		Get: func(session *Session) string {
+			if session.Vectorize {
+				return "on"
+			}
+			return "off"
+		},
+		Reset: func(session *Session) error {
+			session.Vectorize = false
+			return nil
+		},
+	},
 }
 
 func enableTracing(session *Session, values []tree.TypedExpr) error {
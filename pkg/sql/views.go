@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -106,6 +107,100 @@ func (p *planner) analyzeViewQuery(
 	return p.planDeps, planColumns(sourcePlan), nil
 }
 
+// materializedViewChunkSize is the maximum number of rows of a materialized
+// view written in a single batch. A refresh writes each batch in its own
+// transaction.
+const materializedViewChunkSize = 1000
+
+// populateMaterializedView computes the results of the query of the
+// materialized view described by desc and writes them to the view's
+// indexes, which must be empty. The rows are written in batches of
+// materializedViewChunkSize rows.
+func (p *planner) populateMaterializedView(
+	ctx context.Context, desc *sqlbase.TableDescriptor,
+) error {
+	ri, err := sqlbase.MakeRowInserter(p.txn, desc, nil /* fkTables */, desc.Columns, sqlbase.SkipFKs, &p.alloc)
+	if err != nil {
+		return err
+	}
+
+	traceKV := p.session.Tracing.KVTracingEnabled()
+	b := p.txn.NewBatch()
+	numRows := 0
+	flush := func() error {
+		if err := p.txn.Run(ctx, b); err != nil {
+			return sqlbase.ConvertBatchError(ctx, desc, b)
+		}
+		b = p.txn.NewBatch()
+		numRows = 0
+		return nil
+	}
+	if err := p.forEachMaterializedViewRow(ctx, desc, func(row tree.Datums) error {
+		if numRows == materializedViewChunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		numRows++
+		return ri.InsertRow(ctx, b, row, false /* ignoreConflicts */, traceKV)
+	}); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// forEachMaterializedViewRow computes the results of the query of the
+// materialized view described by desc and calls fn with each row to store.
+// The view's columns come first, followed by the hidden columns added to
+// store its results, such as the rowid column of its primary key, which are
+// filled with their default values. The row passed to fn is reused.
+func (p *planner) forEachMaterializedViewRow(
+	ctx context.Context, desc *sqlbase.TableDescriptor, fn func(tree.Datums) error,
+) error {
+	stmt, err := parser.ParseOne(desc.ViewQuery)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse underlying query from view %q", desc.Name)
+	}
+	sel, ok := stmt.(*tree.Select)
+	if !ok {
+		return errors.Errorf("failed to parse underlying query from view %q as a select", desc.Name)
+	}
+
+	plan, err := p.Select(ctx, sel, nil /* desiredTypes */)
+	if err != nil {
+		return err
+	}
+	defer plan.Close(ctx)
+	plan, err = p.optimizePlan(ctx, plan, allColumns(plan))
+	if err != nil {
+		return err
+	}
+	if err := p.startPlan(ctx, plan); err != nil {
+		return err
+	}
+
+	cols := desc.Columns
+	numViewCols := len(planColumns(plan))
+	defaultExprs, err := sqlbase.MakeDefaultExprs(cols[numViewCols:], &p.txCtx, &p.evalCtx)
+	if err != nil {
+		return err
+	}
+	row := make(tree.Datums, len(cols))
+	return forEachRow(runParams{ctx: ctx, p: p}, plan, func(values tree.Datums) error {
+		copy(row, values)
+		for i := numViewCols; i < len(cols); i++ {
+			row[i] = tree.DNull
+			if defaultExprs != nil {
+				var err error
+				if row[i], err = defaultExprs[i-numViewCols].Eval(&p.evalCtx); err != nil {
+					return err
+				}
+			}
+		}
+		return fn(row)
+	})
+}
+
 // RecomputeViewDependencies does the work of CREATE VIEW w.r.t.
 // dependencies over again. Used by a migration to fix existing
 // view descriptors created prior to fixing #17269 and #17306;
@@ -186,6 +186,10 @@ func (v *planVisitor) visit(plan planNode) {
 				jType = "right outer"
 			case joinTypeFullOuter:
 				jType = "full outer"
+			case joinTypeSemi:
+				jType = "semi"
+			case joinTypeAnti:
+				jType = "anti"
 			}
 			v.observer.attr(name, "type", jType)
 
@@ -519,13 +523,16 @@ func nodeName(plan planNode) string {
 // be changed without changing the output of "EXPLAIN".
 var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterTableNode{}):           "alter table",
+	reflect.TypeOf(&alterTypeNode{}):            "alter type",
 	reflect.TypeOf(&alterUserSetPasswordNode{}): "alter user",
 	reflect.TypeOf(&cancelQueryNode{}):          "cancel query",
 	reflect.TypeOf(&controlJobNode{}):           "control job",
 	reflect.TypeOf(&copyNode{}):                 "copy",
 	reflect.TypeOf(&createDatabaseNode{}):       "create database",
 	reflect.TypeOf(&createIndexNode{}):          "create index",
+	reflect.TypeOf(&createSchemaNode{}):         "create schema",
 	reflect.TypeOf(&createTableNode{}):          "create table",
+	reflect.TypeOf(&createTypeNode{}):           "create type",
 	reflect.TypeOf(&createUserNode{}):           "create user",
 	reflect.TypeOf(&createViewNode{}):           "create view",
 	reflect.TypeOf(&delayedNode{}):              "virtual table",
@@ -533,7 +540,9 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&distinctNode{}):             "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):         "drop database",
 	reflect.TypeOf(&dropIndexNode{}):            "drop index",
+	reflect.TypeOf(&dropSchemaNode{}):           "drop schema",
 	reflect.TypeOf(&dropTableNode{}):            "drop table",
+	reflect.TypeOf(&dropTypeNode{}):             "drop type",
 	reflect.TypeOf(&dropViewNode{}):             "drop view",
 	reflect.TypeOf(&dropUserNode{}):             "drop user",
 	reflect.TypeOf(&explainDistSQLNode{}):       "explain dist_sql",
@@ -548,6 +557,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&joinNode{}):                 "join",
 	reflect.TypeOf(&limitNode{}):                "limit",
 	reflect.TypeOf(&ordinalityNode{}):           "ordinality",
+	reflect.TypeOf(&refreshViewNode{}):          "refresh materialized view",
 	reflect.TypeOf(&testingRelocateNode{}):      "testingRelocate",
 	reflect.TypeOf(&renderNode{}):               "render",
 	reflect.TypeOf(&scanNode{}):                 "scan",
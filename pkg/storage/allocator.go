@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/coreos/etcd/raft"
@@ -136,9 +137,10 @@ const (
 // can be retried quickly as soon as new stores come online, or additional
 // space frees up.
 type allocatorError struct {
-	required         []config.Constraint
-	relaxConstraints bool
-	aliveStoreCount  int
+	required           []config.Constraint
+	relaxConstraints   bool
+	aliveStoreCount    int
+	replicaConstraints []config.Constraints
 }
 
 func (ae *allocatorError) Error() string {
@@ -152,9 +154,18 @@ func (ae *allocatorError) Error() string {
 	if ae.relaxConstraints || len(ae.required) == 0 {
 		auxInfo = "; likely not enough nodes in cluster"
 	}
-	return fmt.Sprintf("0 of %d store%s with %s matching %s%s",
+	var replicaConstraints string
+	if len(ae.replicaConstraints) > 0 {
+		conjunctions := make([]string, len(ae.replicaConstraints))
+		for i, c := range ae.replicaConstraints {
+			conjunctions[i] = fmt.Sprintf("%s:%d", c.ConjunctionString(), c.NumReplicas)
+		}
+		replicaConstraints = fmt.Sprintf(" and per-replica constraints {%s}",
+			strings.Join(conjunctions, ", "))
+	}
+	return fmt.Sprintf("0 of %d store%s with %s matching %s%s%s",
 		ae.aliveStoreCount, util.Pluralize(int64(ae.aliveStoreCount)),
-		anyAll, ae.required, auxInfo)
+		anyAll, ae.required, replicaConstraints, auxInfo)
 }
 
 func (*allocatorError) purgatoryErrorMarker() {}
@@ -287,7 +298,7 @@ func (a *Allocator) ComputeAction(
 			// we'll up-replicate to, just an indication that such a target exists.
 			if _, _, err := a.AllocateTarget(
 				ctx,
-				zone.Constraints,
+				zone,
 				liveReplicas,
 				rangeInfo,
 				true, /* relaxConstraints */
@@ -344,7 +355,7 @@ type decisionDetails struct {
 // allocate a target.
 func (a *Allocator) AllocateTarget(
 	ctx context.Context,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	existing []roachpb.ReplicaDescriptor,
 	rangeInfo RangeInfo,
 	relaxConstraints bool,
@@ -352,9 +363,10 @@ func (a *Allocator) AllocateTarget(
 ) (*roachpb.StoreDescriptor, string, error) {
 	sl, _, throttledStoreCount := a.storePool.getStoreList(rangeInfo.Desc.RangeID, storeFilterThrottled)
 
+	analyzedConstraints := analyzeConstraints(a.storePool.getStoreDescriptor, existing, zone)
 	options := a.scorerOptions(disableStatsBasedRebalancing)
 	candidates := allocateCandidates(
-		sl, constraints, existing, rangeInfo, a.storePool.getLocalities(existing), options,
+		sl, analyzedConstraints, existing, rangeInfo, a.storePool.getLocalities(existing), options,
 	)
 	log.VEventf(ctx, 3, "allocate candidates: %s", candidates)
 	if target := candidates.selectGood(a.randGen); target != nil {
@@ -376,14 +388,15 @@ func (a *Allocator) AllocateTarget(
 		return nil, "", errors.Errorf("%d matching stores are currently throttled", throttledStoreCount)
 	}
 	return nil, "", &allocatorError{
-		required: constraints.Constraints,
+		required:           zone.Constraints.Constraints,
+		replicaConstraints: zone.ReplicaConstraints,
 	}
 }
 
 func (a Allocator) simulateRemoveTarget(
 	ctx context.Context,
 	targetStore roachpb.StoreID,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	candidates []roachpb.ReplicaDescriptor,
 	rangeInfo RangeInfo,
 	disableStatsBasedRebalancing bool,
@@ -397,7 +410,7 @@ func (a Allocator) simulateRemoveTarget(
 	defer func() {
 		a.storePool.updateLocalStoreAfterRebalance(targetStore, rangeInfo, roachpb.REMOVE_REPLICA)
 	}()
-	return a.RemoveTarget(ctx, constraints, candidates, rangeInfo, disableStatsBasedRebalancing)
+	return a.RemoveTarget(ctx, zone, candidates, rangeInfo, disableStatsBasedRebalancing)
 }
 
 // RemoveTarget returns a suitable replica to remove from the provided replica
@@ -407,7 +420,7 @@ func (a Allocator) simulateRemoveTarget(
 // replicas.
 func (a Allocator) RemoveTarget(
 	ctx context.Context,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	candidates []roachpb.ReplicaDescriptor,
 	rangeInfo RangeInfo,
 	disableStatsBasedRebalancing bool,
@@ -423,10 +436,12 @@ func (a Allocator) RemoveTarget(
 	}
 	sl, _, _ := a.storePool.getStoreListFromIDs(existingStoreIDs, roachpb.RangeID(0), storeFilterNone)
 
+	analyzedConstraints := analyzeConstraints(
+		a.storePool.getStoreDescriptor, rangeInfo.Desc.Replicas, zone)
 	options := a.scorerOptions(disableStatsBasedRebalancing)
 	rankedCandidates := removeCandidates(
 		sl,
-		constraints,
+		analyzedConstraints,
 		rangeInfo,
 		a.storePool.getLocalities(rangeInfo.Desc.Replicas),
 		options,
@@ -472,7 +487,7 @@ func (a Allocator) RemoveTarget(
 // under-utilized store.
 func (a Allocator) RebalanceTarget(
 	ctx context.Context,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	raftStatus *raft.Status,
 	rangeInfo RangeInfo,
 	filter storeFilter,
@@ -480,11 +495,13 @@ func (a Allocator) RebalanceTarget(
 ) (*roachpb.StoreDescriptor, string) {
 	sl, _, _ := a.storePool.getStoreList(rangeInfo.Desc.RangeID, filter)
 
+	analyzedConstraints := analyzeConstraints(
+		a.storePool.getStoreDescriptor, rangeInfo.Desc.Replicas, zone)
 	options := a.scorerOptions(disableStatsBasedRebalancing)
 	existingCandidates, candidates := rebalanceCandidates(
 		ctx,
 		sl,
-		constraints,
+		analyzedConstraints,
 		rangeInfo.Desc.Replicas,
 		rangeInfo,
 		a.storePool.getLocalities(rangeInfo.Desc.Replicas),
@@ -545,7 +562,7 @@ func (a Allocator) RebalanceTarget(
 		removeReplica, _, err := a.simulateRemoveTarget(
 			ctx,
 			target.store.StoreID,
-			constraints,
+			zone,
 			replicaCandidates,
 			rangeInfo,
 			disableStatsBasedRebalancing)
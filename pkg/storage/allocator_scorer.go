@@ -102,6 +102,7 @@ func (bd balanceDimensions) String() string {
 type candidate struct {
 	store           roachpb.StoreDescriptor
 	valid           bool
+	necessary       bool
 	constraintScore float64
 	convergesScore  int
 	balanceScore    balanceDimensions
@@ -110,9 +111,10 @@ type candidate struct {
 }
 
 func (c candidate) String() string {
-	return fmt.Sprintf("s%d, valid:%t, constraint:%.2f, converges:%d, balance:%s, rangeCount:%d, "+
-		"logicalBytes:%s, writesPerSecond:%.2f, details:(%s)",
-		c.store.StoreID, c.valid, c.constraintScore, c.convergesScore, c.balanceScore, c.rangeCount,
+	return fmt.Sprintf("s%d, valid:%t, necessary:%t, constraint:%.2f, converges:%d, balance:%s, "+
+		"rangeCount:%d, logicalBytes:%s, writesPerSecond:%.2f, details:(%s)",
+		c.store.StoreID, c.valid, c.necessary, c.constraintScore, c.convergesScore, c.balanceScore,
+		c.rangeCount,
 		humanizeutil.IBytes(c.store.Capacity.LogicalBytes), c.store.Capacity.WritesPerSecond, c.details)
 }
 
@@ -124,6 +126,9 @@ func (c candidate) less(o candidate) bool {
 	if !c.valid {
 		return true
 	}
+	if c.necessary != o.necessary {
+		return o.necessary
+	}
 	if c.constraintScore != o.constraintScore {
 		return c.constraintScore < o.constraintScore
 	}
@@ -172,6 +177,7 @@ func (c byScoreAndID) Less(i, j int) bool {
 		c[i].convergesScore == c[j].convergesScore &&
 		c[i].balanceScore.totalScore() == c[j].balanceScore.totalScore() &&
 		c[i].rangeCount == c[j].rangeCount &&
+		c[i].necessary == c[j].necessary &&
 		c[i].valid == c[j].valid {
 		return c[i].store.StoreID < c[j].store.StoreID
 	}
@@ -198,7 +204,8 @@ func (cl candidateList) best() candidateList {
 		return cl
 	}
 	for i := 1; i < len(cl); i++ {
-		if cl[i].constraintScore < cl[0].constraintScore ||
+		if cl[i].necessary != cl[0].necessary ||
+			cl[i].constraintScore < cl[0].constraintScore ||
 			(cl[i].constraintScore == cl[len(cl)-1].constraintScore &&
 				cl[i].convergesScore < cl[len(cl)-1].convergesScore) {
 			return cl[:i]
@@ -223,7 +230,8 @@ func (cl candidateList) worst() candidateList {
 	}
 	// Find the worst constraint values.
 	for i := len(cl) - 2; i >= 0; i-- {
-		if cl[i].constraintScore > cl[len(cl)-1].constraintScore ||
+		if cl[i].necessary != cl[len(cl)-1].necessary ||
+			cl[i].constraintScore > cl[len(cl)-1].constraintScore ||
 			(cl[i].constraintScore == cl[len(cl)-1].constraintScore &&
 				cl[i].convergesScore > cl[len(cl)-1].convergesScore) {
 			return cl[i+1:]
@@ -303,7 +311,7 @@ func (cl candidateList) removeCandidate(c candidate) candidateList {
 // stores that meet the criteria are included in the list.
 func allocateCandidates(
 	sl StoreList,
-	constraints config.Constraints,
+	constraints analyzedConstraints,
 	existing []roachpb.ReplicaDescriptor,
 	rangeInfo RangeInfo,
 	existingNodeLocalities map[roachpb.NodeID]roachpb.Locality,
@@ -314,10 +322,14 @@ func allocateCandidates(
 		if !preexistingReplicaCheck(s.Node.NodeID, existing) {
 			continue
 		}
-		constraintsOk, preferredMatched := constraintCheck(s, constraints)
+		constraintsOk, preferredMatched := constraintCheck(s, constraints.constraints)
 		if !constraintsOk {
 			continue
 		}
+		replicaConstraintsOk, necessary := allocateReplicaConstraintsCheck(s, constraints)
+		if !replicaConstraintsOk {
+			continue
+		}
 		if !maxCapacityCheck(s) {
 			continue
 		}
@@ -326,6 +338,7 @@ func allocateCandidates(
 		candidates = append(candidates, candidate{
 			store:           s,
 			valid:           true,
+			necessary:       necessary,
 			constraintScore: diversityScore + float64(preferredMatched),
 			balanceScore:    balanceScore,
 			rangeCount:      int(s.Capacity.RangeCount),
@@ -345,14 +358,14 @@ func allocateCandidates(
 // marked as not valid, are in violation of a required criteria.
 func removeCandidates(
 	sl StoreList,
-	constraints config.Constraints,
+	constraints analyzedConstraints,
 	rangeInfo RangeInfo,
 	existingNodeLocalities map[roachpb.NodeID]roachpb.Locality,
 	options scorerOptions,
 ) candidateList {
 	var candidates candidateList
 	for _, s := range sl.stores {
-		constraintsOk, preferredMatched := constraintCheck(s, constraints)
+		constraintsOk, preferredMatched := constraintCheck(s, constraints.constraints)
 		if !constraintsOk {
 			candidates = append(candidates, candidate{
 				store:   s,
@@ -361,6 +374,15 @@ func removeCandidates(
 			})
 			continue
 		}
+		replicaConstraintsOk, necessary := removeReplicaConstraintsCheck(s, constraints)
+		if !replicaConstraintsOk {
+			candidates = append(candidates, candidate{
+				store:   s,
+				valid:   false,
+				details: "replica constraint check fail",
+			})
+			continue
+		}
 		if !maxCapacityCheck(s) {
 			candidates = append(candidates, candidate{
 				store:   s,
@@ -383,6 +405,7 @@ func removeCandidates(
 		candidates = append(candidates, candidate{
 			store:           s,
 			valid:           true,
+			necessary:       necessary,
 			constraintScore: diversityScore + float64(preferredMatched),
 			convergesScore:  convergesScore,
 			balanceScore:    balanceScore,
@@ -405,7 +428,7 @@ func removeCandidates(
 func rebalanceCandidates(
 	ctx context.Context,
 	sl StoreList,
-	constraints config.Constraints,
+	constraints analyzedConstraints,
 	existing []roachpb.ReplicaDescriptor,
 	rangeInfo RangeInfo,
 	existingNodeLocalities map[roachpb.NodeID]roachpb.Locality,
@@ -423,15 +446,26 @@ func rebalanceCandidates(
 	var constraintsOkStoreDescriptors []roachpb.StoreDescriptor
 
 	type constraintInfo struct {
-		ok      bool
-		matched int
+		ok        bool
+		necessary bool
+		matched   int
 	}
 	storeInfos := make(map[roachpb.StoreID]constraintInfo)
 	var rebalanceConstraintsCheck bool
 	for _, s := range sl.stores {
-		constraintsOk, preferredMatched := constraintCheck(s, constraints)
-		storeInfos[s.StoreID] = constraintInfo{ok: constraintsOk, matched: preferredMatched}
+		constraintsOk, preferredMatched := constraintCheck(s, constraints.constraints)
 		_, exists := existingStoreIDs[s.StoreID]
+		var necessary bool
+		if constraintsOk {
+			if exists {
+				constraintsOk, necessary = removeReplicaConstraintsCheck(s, constraints)
+			} else {
+				constraintsOk, necessary = allocateReplicaConstraintsCheck(s, constraints)
+			}
+		}
+		storeInfos[s.StoreID] = constraintInfo{
+			ok: constraintsOk, necessary: necessary, matched: preferredMatched,
+		}
 		if constraintsOk {
 			constraintsOkStoreDescriptors = append(constraintsOkStoreDescriptors, s)
 		} else if exists {
@@ -439,6 +473,11 @@ func rebalanceCandidates(
 			log.VEventf(ctx, 2, "must rebalance from s%d due to constraint check", s.StoreID)
 		}
 	}
+	if i, ok := constraints.unsatisfiedReplicaConstraints(); ok {
+		rebalanceConstraintsCheck = true
+		log.VEventf(ctx, 2, "must rebalance to satisfy per-replica constraints %s",
+			constraints.replicaConstraints[i].ConjunctionString())
+	}
 
 	constraintsOkStoreList := makeStoreList(constraintsOkStoreDescriptors)
 	var shouldRebalanceCheck bool
@@ -495,6 +534,7 @@ func rebalanceCandidates(
 			existingCandidates = append(existingCandidates, candidate{
 				store:           s,
 				valid:           true,
+				necessary:       storeInfo.necessary,
 				constraintScore: diversityScore + float64(storeInfo.matched),
 				convergesScore:  convergesScore,
 				balanceScore:    balanceScore,
@@ -523,6 +563,7 @@ func rebalanceCandidates(
 			candidates = append(candidates, candidate{
 				store:           s,
 				valid:           true,
+				necessary:       storeInfo.necessary,
 				constraintScore: diversityScore + float64(storeInfo.matched),
 				convergesScore:  convergesScore,
 				balanceScore:    balanceScore,
@@ -654,27 +695,6 @@ func preexistingReplicaCheck(nodeID roachpb.NodeID, existing []roachpb.ReplicaDe
 	return true
 }
 
-// storeHasConstraint returns whether a store's attributes or node's locality
-// matches the key value pair in the constraint.
-func storeHasConstraint(store roachpb.StoreDescriptor, c config.Constraint) bool {
-	if c.Key == "" {
-		for _, attrs := range []roachpb.Attributes{store.Attrs, store.Node.Attrs} {
-			for _, attr := range attrs.Attrs {
-				if attr == c.Value {
-					return true
-				}
-			}
-		}
-	} else {
-		for _, tier := range store.Node.Locality.Tiers {
-			if c.Key == tier.Key && c.Value == tier.Value {
-				return true
-			}
-		}
-	}
-	return false
-}
-
 // constraintCheck returns true iff all required and prohibited constraints are
 // satisfied. Stores with attributes or localities that match the most positive
 // constraints return higher scores.
@@ -684,7 +704,7 @@ func constraintCheck(store roachpb.StoreDescriptor, constraints config.Constrain
 	}
 	positive := 0
 	for _, constraint := range constraints.Constraints {
-		hasConstraint := storeHasConstraint(store, constraint)
+		hasConstraint := config.StoreHasConstraint(store, constraint)
 		switch {
 		case constraint.Type == config.Constraint_REQUIRED && !hasConstraint:
 			return false, 0
@@ -697,6 +717,120 @@ func constraintCheck(store roachpb.StoreDescriptor, constraints config.Constrain
 	return true, positive
 }
 
+// analyzedConstraints is the result of checking a zone's per-replica
+// constraints against the existing replicas of a range.
+type analyzedConstraints struct {
+	// constraints are the zone's constraints that apply to all replicas.
+	constraints config.Constraints
+	// replicaConstraints are the zone's per-replica constraints, each of which
+	// should be satisfied by exactly NumReplicas replicas.
+	replicaConstraints []config.Constraints
+	// unconstrainedReplicas is true iff the zone wants more replicas than are
+	// pinned by its per-replica constraints, which means that some replicas may
+	// be placed on stores that satisfy none of them.
+	unconstrainedReplicas bool
+	// satisfiedBy contains, for each per-replica constraint, the stores of the
+	// existing replicas that satisfy it.
+	satisfiedBy [][]roachpb.StoreID
+	// satisfies maps the store of each existing replica to the indexes of the
+	// per-replica constraints that it satisfies.
+	satisfies map[roachpb.StoreID][]int
+}
+
+// analyzeConstraints determines which of the zone's per-replica constraints
+// are satisfied by the existing replicas. Replicas whose store descriptors
+// are unknown are considered to satisfy none of them.
+func analyzeConstraints(
+	getStoreDescFn func(roachpb.StoreID) (roachpb.StoreDescriptor, bool),
+	existing []roachpb.ReplicaDescriptor,
+	zone config.ZoneConfig,
+) analyzedConstraints {
+	result := analyzedConstraints{
+		constraints:        zone.Constraints,
+		replicaConstraints: zone.ReplicaConstraints,
+	}
+	if len(zone.ReplicaConstraints) == 0 {
+		return result
+	}
+	var constrainedReplicas int32
+	for _, constraints := range zone.ReplicaConstraints {
+		constrainedReplicas += constraints.NumReplicas
+	}
+	result.unconstrainedReplicas = constrainedReplicas < zone.NumReplicas
+	result.satisfiedBy = make([][]roachpb.StoreID, len(zone.ReplicaConstraints))
+	result.satisfies = make(map[roachpb.StoreID][]int)
+	for _, repl := range existing {
+		store, ok := getStoreDescFn(repl.StoreID)
+		if !ok {
+			continue
+		}
+		for i, constraints := range zone.ReplicaConstraints {
+			if config.StoreSatisfiesConstraints(store, constraints) {
+				result.satisfiedBy[i] = append(result.satisfiedBy[i], store.StoreID)
+				result.satisfies[store.StoreID] = append(result.satisfies[store.StoreID], i)
+			}
+		}
+	}
+	return result
+}
+
+// unsatisfiedReplicaConstraints returns the index of the first per-replica
+// constraint that is satisfied by fewer replicas than it requires, if any.
+func (ac analyzedConstraints) unsatisfiedReplicaConstraints() (int, bool) {
+	for i, constraints := range ac.replicaConstraints {
+		if len(ac.satisfiedBy[i]) < int(constraints.NumReplicas) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// allocateReplicaConstraintsCheck checks the per-replica constraints for a
+// store that doesn't yet hold a replica of the range. It returns whether a new
+// replica may be placed on the store and whether doing so is necessary to
+// satisfy a per-replica constraint that doesn't have enough replicas yet.
+func allocateReplicaConstraintsCheck(
+	store roachpb.StoreDescriptor, constraints analyzedConstraints,
+) (valid bool, necessary bool) {
+	if len(constraints.replicaConstraints) == 0 {
+		return true, false
+	}
+	for i, replicaConstraints := range constraints.replicaConstraints {
+		if !config.StoreSatisfiesConstraints(store, replicaConstraints) {
+			continue
+		}
+		valid = true
+		if len(constraints.satisfiedBy[i]) < int(replicaConstraints.NumReplicas) {
+			return true, true
+		}
+	}
+	return valid || constraints.unconstrainedReplicas, false
+}
+
+// removeReplicaConstraintsCheck checks the per-replica constraints for a store
+// that holds one of the range's existing replicas. It returns whether the
+// replica is allowed to stay on the store and whether removing it would leave a
+// per-replica constraint with too few replicas.
+func removeReplicaConstraintsCheck(
+	store roachpb.StoreDescriptor, constraints analyzedConstraints,
+) (valid bool, necessary bool) {
+	if len(constraints.replicaConstraints) == 0 {
+		return true, false
+	}
+	satisfies := constraints.satisfies[store.StoreID]
+	if len(satisfies) == 0 {
+		// The replica doesn't count towards any per-replica constraint, so it is
+		// only allowed if the zone wants replicas beyond those pinned by them.
+		return constraints.unconstrainedReplicas, false
+	}
+	for _, i := range satisfies {
+		if len(constraints.satisfiedBy[i]) <= int(constraints.replicaConstraints[i].NumReplicas) {
+			return true, true
+		}
+	}
+	return true, false
+}
+
 // diversityScore returns a score between 1 and 0 where higher scores are stores
 // with the fewest locality tiers in common with already existing replicas.
 func diversityScore(
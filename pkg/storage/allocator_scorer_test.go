@@ -488,6 +488,127 @@ func TestConstraintCheck(t *testing.T) {
 	}
 }
 
+func TestReplicaConstraintsCheck(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	getStoreDesc := func(storeID roachpb.StoreID) (roachpb.StoreDescriptor, bool) {
+		for _, s := range testStores {
+			if s.StoreID == storeID {
+				return s, true
+			}
+		}
+		return roachpb.StoreDescriptor{}, false
+	}
+	zone := config.ZoneConfig{
+		NumReplicas: 3,
+		ReplicaConstraints: []config.Constraints{
+			{
+				NumReplicas: 2,
+				Constraints: []config.Constraint{
+					{Type: config.Constraint_REQUIRED, Key: "datacenter", Value: "us"},
+					{Type: config.Constraint_REQUIRED, Value: "b"},
+				},
+			},
+			{
+				NumReplicas: 1,
+				Constraints: []config.Constraint{
+					{Type: config.Constraint_REQUIRED, Key: "datacenter", Value: "eur"},
+				},
+			},
+		},
+	}
+	type result struct {
+		valid, necessary bool
+	}
+
+	testCases := []struct {
+		name     string
+		existing []roachpb.StoreID
+		// allocate contains the expected results for stores that don't have a
+		// replica, while remove contains those for stores that do.
+		allocate map[roachpb.StoreID]result
+		remove   map[roachpb.StoreID]result
+	}{
+		{
+			name:     "no existing replicas",
+			existing: nil,
+			allocate: map[roachpb.StoreID]result{
+				testStoreUSa15:     {false, false},
+				testStoreUSa15Dupe: {false, false},
+				testStoreUSa1:      {true, true},
+				testStoreUSb:       {true, true},
+				testStoreEurope:    {true, true},
+			},
+		},
+		{
+			name:     "one constraint satisfied",
+			existing: []roachpb.StoreID{testStoreUSa1, testStoreUSb},
+			allocate: map[roachpb.StoreID]result{
+				testStoreUSa15:     {false, false},
+				testStoreUSa15Dupe: {false, false},
+				testStoreEurope:    {true, true},
+			},
+			remove: map[roachpb.StoreID]result{
+				testStoreUSa1: {true, true},
+				testStoreUSb:  {true, true},
+			},
+		},
+		{
+			name:     "replica satisfying no constraint",
+			existing: []roachpb.StoreID{testStoreUSa15, testStoreUSb, testStoreEurope},
+			allocate: map[roachpb.StoreID]result{
+				testStoreUSa15Dupe: {false, false},
+				testStoreUSa1:      {true, true},
+			},
+			remove: map[roachpb.StoreID]result{
+				testStoreUSa15:  {false, false},
+				testStoreUSb:    {true, true},
+				testStoreEurope: {true, true},
+			},
+		},
+		{
+			name:     "all constraints satisfied",
+			existing: []roachpb.StoreID{testStoreUSa1, testStoreUSb, testStoreEurope},
+			allocate: map[roachpb.StoreID]result{
+				testStoreUSa15:     {false, false},
+				testStoreUSa15Dupe: {false, false},
+			},
+			remove: map[roachpb.StoreID]result{
+				testStoreUSa1:   {true, true},
+				testStoreUSb:    {true, true},
+				testStoreEurope: {true, true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var existing []roachpb.ReplicaDescriptor
+			for _, storeID := range tc.existing {
+				existing = append(existing, roachpb.ReplicaDescriptor{
+					NodeID:  roachpb.NodeID(storeID),
+					StoreID: storeID,
+				})
+			}
+			analyzed := analyzeConstraints(getStoreDesc, existing, zone)
+			for _, s := range testStores {
+				if expected, ok := tc.allocate[s.StoreID]; ok {
+					valid, necessary := allocateReplicaConstraintsCheck(s, analyzed)
+					if actual := (result{valid, necessary}); actual != expected {
+						t.Errorf("allocate s%d: expected %+v, but got %+v", s.StoreID, expected, actual)
+					}
+				}
+				if expected, ok := tc.remove[s.StoreID]; ok {
+					valid, necessary := removeReplicaConstraintsCheck(s, analyzed)
+					if actual := (result{valid, necessary}); actual != expected {
+						t.Errorf("remove s%d: expected %+v, but got %+v", s.StoreID, expected, actual)
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestDiversityScore(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
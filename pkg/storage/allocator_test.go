@@ -266,7 +266,7 @@ func TestAllocatorSimpleRetrieval(t *testing.T) {
 	gossiputil.NewStoreGossiper(g).GossipStores(singleStore, t)
 	result, _, err := a.AllocateTarget(
 		context.Background(),
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -301,7 +301,7 @@ func TestAllocatorCorruptReplica(t *testing.T) {
 
 	result, _, err := a.AllocateTarget(
 		context.Background(),
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		true,
@@ -322,7 +322,7 @@ func TestAllocatorNoAvailableDisks(t *testing.T) {
 	defer stopper.Stop(context.Background())
 	result, _, err := a.AllocateTarget(
 		context.Background(),
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -345,7 +345,7 @@ func TestAllocatorTwoDatacenters(t *testing.T) {
 	ctx := context.Background()
 	result1, _, err := a.AllocateTarget(
 		ctx,
-		multiDCConfig.Constraints,
+		multiDCConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -356,7 +356,7 @@ func TestAllocatorTwoDatacenters(t *testing.T) {
 	}
 	result2, _, err := a.AllocateTarget(
 		ctx,
-		multiDCConfig.Constraints,
+		multiDCConfig,
 		[]roachpb.ReplicaDescriptor{{
 			NodeID:  result1.Node.NodeID,
 			StoreID: result1.StoreID,
@@ -376,7 +376,7 @@ func TestAllocatorTwoDatacenters(t *testing.T) {
 	// Verify that no result is forthcoming if we already have a replica.
 	result3, _, err := a.AllocateTarget(
 		ctx,
-		multiDCConfig.Constraints,
+		multiDCConfig,
 		[]roachpb.ReplicaDescriptor{
 			{
 				NodeID:  result1.Node.NodeID,
@@ -404,10 +404,12 @@ func TestAllocatorExistingReplica(t *testing.T) {
 	gossiputil.NewStoreGossiper(g).GossipStores(sameDCStores, t)
 	result, _, err := a.AllocateTarget(
 		context.Background(),
-		config.Constraints{
-			Constraints: []config.Constraint{
-				{Value: "a"},
-				{Value: "hdd"},
+		config.ZoneConfig{
+			Constraints: config.Constraints{
+				Constraints: []config.Constraint{
+					{Value: "a"},
+					{Value: "hdd"},
+				},
 			},
 		},
 		[]roachpb.ReplicaDescriptor{
@@ -589,7 +591,7 @@ func TestAllocatorRelaxConstraints(t *testing.T) {
 			}
 			result, _, err := a.AllocateTarget(
 				context.Background(),
-				config.Constraints{Constraints: test.constraints},
+				config.ZoneConfig{Constraints: config.Constraints{Constraints: test.constraints}},
 				existing,
 				firstRangeInfo,
 				false,
@@ -665,7 +667,7 @@ func TestAllocatorRebalance(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		result, _ := a.RebalanceTarget(
 			ctx,
-			config.Constraints{},
+			config.ZoneConfig{},
 			nil,
 			testRangeInfo([]roachpb.ReplicaDescriptor{{StoreID: 3}}, firstRange),
 			storeFilterThrottled,
@@ -815,7 +817,7 @@ func TestAllocatorRebalanceTarget(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		result, _ := a.RebalanceTarget(
 			context.Background(),
-			config.Constraints{},
+			config.ZoneConfig{},
 			status,
 			rangeInfo,
 			storeFilterThrottled,
@@ -896,7 +898,7 @@ func TestAllocatorRebalanceDeadNodes(t *testing.T) {
 	for _, c := range testCases {
 		t.Run("", func(t *testing.T) {
 			result, _ := a.RebalanceTarget(
-				ctx, config.Constraints{}, nil, testRangeInfo(c.existing, firstRange), storeFilterThrottled, false)
+				ctx, config.ZoneConfig{}, nil, testRangeInfo(c.existing, firstRange), storeFilterThrottled, false)
 			if c.expected > 0 {
 				if result == nil {
 					t.Fatalf("expected %d, but found nil", c.expected)
@@ -1090,7 +1092,7 @@ func TestAllocatorRebalanceByCount(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		result, _ := a.RebalanceTarget(
 			ctx,
-			config.Constraints{},
+			config.ZoneConfig{},
 			nil,
 			testRangeInfo([]roachpb.ReplicaDescriptor{{StoreID: stores[0].StoreID}}, firstRange),
 			storeFilterThrottled,
@@ -1646,7 +1648,7 @@ func TestAllocatorRemoveTarget(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		targetRepl, _, err := a.RemoveTarget(
 			ctx,
-			config.Constraints{},
+			config.ZoneConfig{},
 			replicas,
 			testRangeInfo(replicas, firstRange),
 			false,
@@ -2134,7 +2136,7 @@ func TestAllocatorRebalanceTargetDisableStatsRebalance(t *testing.T) {
 	for i := 0; i < 50; i++ {
 		target, _ := a.RebalanceTarget(
 			context.Background(),
-			config.Constraints{},
+			config.ZoneConfig{},
 			nil,
 			testRangeInfo(desc.Replicas, desc.RangeID),
 			storeFilterThrottled,
@@ -2148,7 +2150,7 @@ func TestAllocatorRebalanceTargetDisableStatsRebalance(t *testing.T) {
 	for i := 0; i < 50; i++ {
 		target, _ := a.RebalanceTarget(
 			context.Background(),
-			config.Constraints{},
+			config.ZoneConfig{},
 			nil,
 			testRangeInfo(desc.Replicas, desc.RangeID),
 			storeFilterThrottled,
@@ -2451,29 +2453,46 @@ func TestAllocatorError(t *testing.T) {
 
 	constraint := []config.Constraint{{Value: "one"}}
 	constraints := []config.Constraint{{Value: "one"}, {Value: "two"}}
+	replicaConstraints := []config.Constraints{
+		{
+			NumReplicas: 1,
+			Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Key: "region", Value: "us"}},
+		},
+		{
+			NumReplicas: 2,
+			Constraints: []config.Constraint{
+				{Type: config.Constraint_REQUIRED, Key: "region", Value: "eu"},
+				{Type: config.Constraint_PROHIBITED, Value: "ssd"},
+			},
+		},
+	}
 
 	testCases := []struct {
 		ae       allocatorError
 		expected string
 	}{
-		{allocatorError{nil, false, 1},
+		{allocatorError{nil, false, 1, nil},
 			"0 of 1 store with all attributes matching []; likely not enough nodes in cluster"},
-		{allocatorError{constraint, false, 1},
+		{allocatorError{constraint, false, 1, nil},
 			"0 of 1 store with all attributes matching [one]"},
-		{allocatorError{constraint, true, 1},
+		{allocatorError{constraint, true, 1, nil},
 			"0 of 1 store with an attribute matching [one]; likely not enough nodes in cluster"},
-		{allocatorError{constraint, false, 2},
+		{allocatorError{constraint, false, 2, nil},
 			"0 of 2 stores with all attributes matching [one]"},
-		{allocatorError{constraint, true, 2},
+		{allocatorError{constraint, true, 2, nil},
 			"0 of 2 stores with an attribute matching [one]; likely not enough nodes in cluster"},
-		{allocatorError{constraints, false, 1},
+		{allocatorError{constraints, false, 1, nil},
 			"0 of 1 store with all attributes matching [one two]"},
-		{allocatorError{constraints, true, 1},
+		{allocatorError{constraints, true, 1, nil},
 			"0 of 1 store with an attribute matching [one two]; likely not enough nodes in cluster"},
-		{allocatorError{constraints, false, 2},
+		{allocatorError{constraints, false, 2, nil},
 			"0 of 2 stores with all attributes matching [one two]"},
-		{allocatorError{constraints, true, 2},
+		{allocatorError{constraints, true, 2, nil},
 			"0 of 2 stores with an attribute matching [one two]; likely not enough nodes in cluster"},
+		{allocatorError{constraint, false, 3, replicaConstraints},
+			"0 of 3 stores with all attributes matching [one] and per-replica constraints {+region=us:1, +region=eu,-ssd:2}"},
+		{allocatorError{nil, false, 1, replicaConstraints[:1]},
+			"0 of 1 store with all attributes matching [] and per-replica constraints {+region=us:1}; likely not enough nodes in cluster"},
 	}
 
 	for i, testCase := range testCases {
@@ -2495,7 +2514,7 @@ func TestAllocatorThrottled(t *testing.T) {
 	// First test to make sure we would send the replica to purgatory.
 	_, _, err := a.AllocateTarget(
 		ctx,
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -2509,7 +2528,7 @@ func TestAllocatorThrottled(t *testing.T) {
 	gossiputil.NewStoreGossiper(g).GossipStores(singleStore, t)
 	result, _, err := a.AllocateTarget(
 		ctx,
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -2533,7 +2552,7 @@ func TestAllocatorThrottled(t *testing.T) {
 	a.storePool.detailsMu.Unlock()
 	_, _, err = a.AllocateTarget(
 		ctx,
-		simpleZoneConfig.Constraints,
+		simpleZoneConfig,
 		[]roachpb.ReplicaDescriptor{},
 		firstRangeInfo,
 		false,
@@ -2793,15 +2812,17 @@ func TestAllocatorRebalanceAway(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.constraint.String(), func(t *testing.T) {
-			constraints := config.Constraints{
-				Constraints: []config.Constraint{
-					tc.constraint,
+			zone := config.ZoneConfig{
+				Constraints: config.Constraints{
+					Constraints: []config.Constraint{
+						tc.constraint,
+					},
 				},
 			}
 
 			actual, _ := a.RebalanceTarget(
 				ctx,
-				constraints,
+				zone,
 				nil,
 				testRangeInfo(existingReplicas, firstRange),
 				storeFilterThrottled,
@@ -2923,7 +2944,7 @@ func Example_rebalancing() {
 			ts := &testStores[j]
 			target, _ := alloc.RebalanceTarget(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				nil,
 				testRangeInfo([]roachpb.ReplicaDescriptor{{NodeID: ts.Node.NodeID, StoreID: ts.StoreID}}, firstRange),
 				storeFilterThrottled,
@@ -0,0 +1,95 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// ClearRangeBytesThreshold is the size of the data to clear above which
+// ClearRange uses a RocksDB range deletion tombstone. Below it, the keys are
+// cleared individually, which avoids the read overhead of having many small
+// range tombstones in the engine.
+const ClearRangeBytesThreshold = 512 << 10 // 512KiB
+
+// ClearRange wipes all MVCC versions of keys covered by the specified
+// span, adjusting the MVCC stats accordingly.
+//
+// Note that "correct" use of this command is only possible for key
+// spans consisting of user data that we know is not being written to
+// or queried any more, such as after a DROP or TRUNCATE table, once the
+// GC TTL has elapsed.
+func ClearRange(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	if cArgs.Header.Txn != nil {
+		return result.Result{}, errors.New("cannot execute ClearRange within a transaction")
+	}
+	log.VEventf(ctx, 2, "ClearRange %+v", cArgs.Args)
+
+	args := cArgs.Args.(*roachpb.ClearRangeRequest)
+	from := engine.MVCCKey{Key: args.Key}
+	to := engine.MVCCKey{Key: args.EndKey}
+
+	// Before clearing, compute the delta in MVCCStats.
+	statsDelta, err := computeClearRangeStatsDelta(batch, cArgs, from, to)
+	if err != nil {
+		return result.Result{}, err
+	}
+	cArgs.Stats.Subtract(statsDelta)
+
+	// If the total size of data to be cleared is below the threshold, clear
+	// the individual values instead of writing a range tombstone.
+	if total := statsDelta.KeyBytes + statsDelta.ValBytes; total < ClearRangeBytesThreshold {
+		log.VEventf(ctx, 2, "delta=%d < threshold=%d; using non-range clear",
+			total, ClearRangeBytesThreshold)
+		iter := batch.NewIterator(false /* prefix */)
+		defer iter.Close()
+		return result.Result{}, batch.ClearIterRange(iter, from, to)
+	}
+
+	// Note that the batch cannot be read from after this.
+	return result.Result{}, batch.ClearRange(from, to)
+}
+
+// computeClearRangeStatsDelta returns the MVCC stats of the data in the span
+// [from, to), which ClearRange removes.
+func computeClearRangeStatsDelta(
+	batch engine.ReadWriter, cArgs CommandArgs, from, to engine.MVCCKey,
+) (enginepb.MVCCStats, error) {
+	desc := cArgs.EvalCtx.Desc()
+
+	// If the whole range is cleared, its stats can be used directly instead of
+	// scanning the span. This is safe because the command holds the entire
+	// user keyspace of the range in the command queue. Range-local keys are
+	// not cleared, so the system stats are left untouched.
+	fast := desc.StartKey.Equal(from.Key) && desc.EndKey.Equal(to.Key)
+	if fast {
+		delta := cArgs.EvalCtx.GetMVCCStats()
+		delta.SysCount, delta.SysBytes = 0, 0
+		return delta, nil
+	}
+
+	iter := batch.NewIterator(false /* prefix */)
+	defer iter.Close()
+	return iter.ComputeStats(from, to, cArgs.Header.Timestamp.WallTime)
+}
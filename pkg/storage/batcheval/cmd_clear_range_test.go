@@ -0,0 +1,153 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// clearRangeEvalContext is an EvalContext which only provides the range
+// descriptor and the MVCC stats used by ClearRange.
+type clearRangeEvalContext struct {
+	EvalContext
+	desc  roachpb.RangeDescriptor
+	stats enginepb.MVCCStats
+}
+
+func (c *clearRangeEvalContext) Desc() *roachpb.RangeDescriptor { return &c.desc }
+
+func (c *clearRangeEvalContext) GetMVCCStats() enginepb.MVCCStats { return c.stats }
+
+func clearRangeTestKey(i int) roachpb.Key {
+	return roachpb.Key(fmt.Sprintf("k%04d", i))
+}
+
+// TestClearRangeStats verifies that the stats delta computed by ClearRange,
+// both from the range's stats and by scanning the cleared span, matches a
+// recomputation of the stats after the data is cleared.
+func TestClearRangeStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	const numKeys = 1000
+	desc := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKey("k"),
+		EndKey:   roachpb.RKey("l"),
+	}
+	ts1 := hlc.Timestamp{WallTime: 1e9}
+	ts2 := hlc.Timestamp{WallTime: 2e9}
+	now := hlc.Timestamp{WallTime: 10e9}
+	value := roachpb.MakeValueFromBytes(bytes.Repeat([]byte("v"), 1<<10))
+
+	// writeData writes more than ClearRangeBytesThreshold of data, made of
+	// values with several versions, deletion tombstones and an intent.
+	writeData := func(t *testing.T, eng engine.Engine) {
+		for i := 0; i < numKeys; i++ {
+			key := clearRangeTestKey(i)
+			if err := engine.MVCCPut(ctx, eng, nil, key, ts1, value, nil); err != nil {
+				t.Fatal(err)
+			}
+			switch i % 3 {
+			case 1:
+				if err := engine.MVCCPut(ctx, eng, nil, key, ts2, value, nil); err != nil {
+					t.Fatal(err)
+				}
+			case 2:
+				if err := engine.MVCCDelete(ctx, eng, nil, key, ts2, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		txn := roachpb.MakeTransaction(
+			"test", clearRangeTestKey(numKeys), roachpb.NormalUserPriority, enginepb.SERIALIZABLE, ts2, 0,
+		)
+		if err := engine.MVCCPut(ctx, eng, nil, clearRangeTestKey(numKeys), ts2, value, &txn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	computeStats := func(t *testing.T, eng engine.Engine, from, to roachpb.Key) enginepb.MVCCStats {
+		iter := eng.NewIterator(false /* prefix */)
+		defer iter.Close()
+		ms, err := iter.ComputeStats(
+			engine.MVCCKey{Key: from}, engine.MVCCKey{Key: to}, now.WallTime,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ms
+	}
+
+	testCases := []struct {
+		name     string
+		from, to roachpb.Key
+	}{
+		// The whole range: the delta is the range's stats.
+		{"whole range", roachpb.Key(desc.StartKey), roachpb.Key(desc.EndKey)},
+		// Part of the range, above the threshold for range tombstones.
+		{"large partial range", clearRangeTestKey(100), roachpb.Key(desc.EndKey)},
+		// Part of the range, below the threshold: the keys are cleared
+		// individually.
+		{"small partial range", clearRangeTestKey(100), clearRangeTestKey(110)},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+			defer eng.Close()
+			writeData(t, eng)
+
+			before := computeStats(t, eng, roachpb.Key(desc.StartKey), roachpb.Key(desc.EndKey))
+			var delta enginepb.MVCCStats
+			cArgs := CommandArgs{
+				EvalCtx: &clearRangeEvalContext{desc: desc, stats: before},
+				Header:  roachpb.Header{Timestamp: now},
+				Args: &roachpb.ClearRangeRequest{
+					Span: roachpb.Span{Key: test.from, EndKey: test.to},
+				},
+				Stats: &delta,
+			}
+			batch := eng.NewBatch()
+			defer batch.Close()
+			if _, err := ClearRange(ctx, batch, cArgs, &roachpb.ClearRangeResponse{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := batch.Commit(false /* sync */); err != nil {
+				t.Fatal(err)
+			}
+
+			if ms := computeStats(t, eng, test.from, test.to); ms.KeyCount != 0 {
+				t.Fatalf("expected the span to be cleared; found stats %+v", ms)
+			}
+			expected := before
+			expected.Add(delta)
+			after := computeStats(t, eng, roachpb.Key(desc.StartKey), roachpb.Key(desc.EndKey))
+			if !reflect.DeepEqual(expected, after) {
+				t.Errorf("expected stats %+v after ClearRange; recomputed %+v", expected, after)
+			}
+		})
+	}
+}
@@ -23,7 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
-// Put sets the value for a specified key.
+// Put sets the value for a specified key, or locks it (see engine.MVCCLock).
 func Put(
 	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
 ) (result.Result, error) {
@@ -44,6 +44,9 @@ func Put(
 			defer batch.Close()
 		}
 	}
+	if args.Lock {
+		return result.Result{}, engine.MVCCLock(ctx, batch, ms, args.Key, ts, h.Txn)
+	}
 	if args.Blind {
 		return result.Result{}, engine.MVCCBlindPut(ctx, batch, ms, args.Key, ts, args.Value, h.Txn)
 	}
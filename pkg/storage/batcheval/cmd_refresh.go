@@ -0,0 +1,40 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+)
+
+// Refresh checks the key for more recently written values than the
+// transaction's original timestamp, returning an error if any are
+// found. A successful refresh allows the transaction's reads of the
+// key to be treated as having been served at its refreshed timestamp.
+func Refresh(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.RefreshRequest)
+	h := cArgs.Header
+
+	if h.Txn == nil {
+		return result.Result{}, errors.Errorf("no transaction specified to %s", args.Method())
+	}
+	return result.Result{}, refreshSpan(batch, args.Span, h.Txn, h.Timestamp)
+}
@@ -0,0 +1,108 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// RefreshRange scans the key range specified by start key through
+// end key and returns an error on any keys written by other
+// transactions after the transaction's original timestamp and at or
+// below its refreshed timestamp. Intents written by other
+// transactions are considered conflicting writes regardless of their
+// timestamp.
+func RefreshRange(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.RefreshRangeRequest)
+	h := cArgs.Header
+
+	if h.Txn == nil {
+		return result.Result{}, errors.Errorf("no transaction specified to %s", args.Method())
+	}
+	return result.Result{}, refreshSpan(batch, args.Span, h.Txn, h.Timestamp)
+}
+
+// refreshSpan verifies that no writes by transactions other than txn
+// were made in the span in the interval (txn.OrigTimestamp, refreshTS].
+//
+// Reads of the transaction which were previously refreshed were served
+// at a timestamp above txn.OrigTimestamp, so checking the full interval
+// is conservative: a refresh may fail on a write that the transaction
+// had in fact already observed.
+func refreshSpan(
+	reader engine.Reader, span roachpb.Span, txn *roachpb.Transaction, refreshTS hlc.Timestamp,
+) error {
+	endKey := span.EndKey
+	if len(endKey) == 0 {
+		endKey = span.Key.Next()
+	}
+
+	iter := reader.NewIterator(false /* prefix */)
+	defer iter.Close()
+
+	var meta enginepb.MVCCMetadata
+	// ownIntentKey and ownIntentTS identify the provisional value
+	// belonging to an intent of txn, which must not be mistaken for a
+	// conflicting write.
+	var ownIntentKey roachpb.Key
+	var ownIntentTS hlc.Timestamp
+	for iter.Seek(engine.MakeMVCCMetadataKey(span.Key)); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+		key := iter.UnsafeKey()
+		if key.Key.Compare(endKey) >= 0 {
+			return nil
+		}
+
+		if !key.IsValue() {
+			if err := protoutil.Unmarshal(iter.UnsafeValue(), &meta); err != nil {
+				return errors.Wrapf(err, "unable to decode MVCCMetadata for key %s", key.Key)
+			}
+			if meta.Txn == nil {
+				// Inline values are not versioned and are never written
+				// transactionally.
+				continue
+			}
+			if meta.Txn.ID != txn.ID {
+				return errors.Errorf("encountered recently written intent %s @%s",
+					key.Key, meta.Timestamp)
+			}
+			ownIntentKey = append(ownIntentKey[:0], key.Key...)
+			ownIntentTS = hlc.Timestamp(meta.Timestamp)
+			continue
+		}
+
+		if refreshTS.Less(key.Timestamp) || !txn.OrigTimestamp.Less(key.Timestamp) {
+			continue
+		}
+		if key.Timestamp == ownIntentTS && key.Key.Equal(ownIntentKey) {
+			continue
+		}
+		return errors.Errorf("encountered recently written key %s @%s", key.Key, key.Timestamp)
+	}
+}
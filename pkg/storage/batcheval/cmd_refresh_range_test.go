@@ -0,0 +1,100 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestRefreshRangeOwnIntent verifies that a refresh over an intent written
+// by the refreshing transaction after its original timestamp doesn't mistake
+// the intent's provisional value for a conflicting write.
+func TestRefreshRangeOwnIntent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer eng.Close()
+
+	ts1 := hlc.Timestamp{WallTime: 1}
+	ts2 := hlc.Timestamp{WallTime: 2}
+	ts3 := hlc.Timestamp{WallTime: 3}
+
+	// The transaction starts at ts1 and is pushed to ts2 before writing an
+	// intent on "a", so the provisional value is above its original timestamp.
+	txn := roachpb.MakeTransaction(
+		"test", roachpb.Key("a"), roachpb.NormalUserPriority, enginepb.SERIALIZABLE, ts1, 0,
+	)
+	txn.Timestamp = ts2
+	if err := engine.MVCCPut(
+		ctx, eng, nil, roachpb.Key("a"), txn.Timestamp, roachpb.MakeValueFromString("txn"), &txn,
+	); err != nil {
+		t.Fatal(err)
+	}
+	// A non-transactional write to "b" at ts2 conflicts with the refresh.
+	if err := engine.MVCCPut(
+		ctx, eng, nil, roachpb.Key("b"), ts2, roachpb.MakeValueFromString("other"), nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		span   roachpb.Span
+		expErr string
+	}{
+		{roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}, ""},
+		{roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("c")}, "encountered recently written key"},
+		{roachpb.Span{Key: roachpb.Key("b"), EndKey: roachpb.Key("c")}, "encountered recently written key"},
+	}
+	for i, test := range testCases {
+		cArgs := CommandArgs{
+			Header: roachpb.Header{Txn: &txn, Timestamp: ts3},
+			Args:   &roachpb.RefreshRangeRequest{Span: test.span},
+		}
+		_, err := RefreshRange(ctx, eng, cArgs, &roachpb.RefreshRangeResponse{})
+		if !testutils.IsError(err, test.expErr) {
+			t.Errorf("%d: expected error %q; got %v", i, test.expErr, err)
+		}
+	}
+
+	// The refresh also fails on an intent of another transaction, whatever
+	// its timestamp.
+	otherTxn := roachpb.MakeTransaction(
+		"other", roachpb.Key("c"), roachpb.NormalUserPriority, enginepb.SERIALIZABLE, ts1, 0,
+	)
+	if err := engine.MVCCPut(
+		ctx, eng, nil, roachpb.Key("c"), otherTxn.Timestamp, roachpb.MakeValueFromString("other"), &otherTxn,
+	); err != nil {
+		t.Fatal(err)
+	}
+	cArgs := CommandArgs{
+		Header: roachpb.Header{Txn: &txn, Timestamp: ts3},
+		Args:   &roachpb.RefreshRangeRequest{Span: roachpb.Span{Key: roachpb.Key("c"), EndKey: roachpb.Key("d")}},
+	}
+	if _, err := RefreshRange(ctx, eng, cArgs, &roachpb.RefreshRangeResponse{}); !testutils.IsError(
+		err, "encountered recently written intent",
+	) {
+		t.Errorf("expected intent error; got %v", err)
+	}
+}
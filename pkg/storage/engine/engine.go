@@ -155,7 +155,9 @@ type Writer interface {
 	Clear(key MVCCKey) error
 	// ClearRange removes a set of entries, from start (inclusive) to end
 	// (exclusive). Similar to Clear, this method actually removes entries from
-	// the storage engine.
+	// the storage engine. The entries are removed using a range deletion
+	// tombstone; a readable Batch cannot be read from after ClearRange has been
+	// called on it.
 	ClearRange(start, end MVCCKey) error
 	// ClearIterRange removes a set of entries, from start (inclusive) to end
 	// (exclusive). Similar to Clear and ClearRange, this method actually removes
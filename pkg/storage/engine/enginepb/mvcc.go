@@ -19,6 +19,18 @@ func (t TxnMeta) Short() string {
 	return t.ID.Short()
 }
 
+// IsSeqIgnored returns true if the writes performed by the transaction at
+// the given write sequence number were rolled back by rolling back to a
+// savepoint.
+func (t TxnMeta) IsSeqIgnored(seq int32) bool {
+	for _, r := range t.IgnoredSeqNums {
+		if r.Start <= seq && seq <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
 // Total returns the range size as the sum of the key and value
 // bytes. This includes all non-live keys and all versioned values.
 func (ms MVCCStats) Total() int64 {
@@ -123,3 +135,21 @@ func (ms *MVCCStats) Subtract(oms MVCCStats) {
 func (meta MVCCMetadata) IsInline() bool {
 	return meta.RawBytes != nil
 }
+
+// IsLockOnly returns true if the metadata describes an intent which only
+// locks the key (see MVCCLock).
+func (meta MVCCMetadata) IsLockOnly() bool {
+	return meta.LockOnly != nil && *meta.LockOnly
+}
+
+// LatestUnignoredValue returns the value most recently written to the
+// intent's history by txn at a write sequence number which has not been
+// rolled back, or false if there is none.
+func (meta MVCCMetadata) LatestUnignoredValue(txn TxnMeta) ([]byte, int32, bool) {
+	for i := len(meta.IntentHistory) - 1; i >= 0; i-- {
+		if h := meta.IntentHistory[i]; !txn.IsSeqIgnored(h.Sequence) {
+			return h.Value, h.Sequence, true
+		}
+	}
+	return nil, 0, false
+}
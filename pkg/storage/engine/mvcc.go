@@ -23,6 +23,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -737,6 +738,20 @@ func mvccGetInternal(
 					txn.Epoch, meta.Txn.Epoch)
 			}
 			seekKey = seekKey.Next()
+		} else if ownIntent && txn.IsSeqIgnored(meta.Txn.WriteSeq) {
+			// The intent was written after a savepoint which the txn has
+			// since rolled back to. Read the latest value from the intent's
+			// history which was not rolled back or, failing that, the
+			// committed value below the intent.
+			if rawBytes, _, ok := meta.LatestUnignoredValue(txn.TxnMeta); ok {
+				value := &buf.value
+				*value = roachpb.Value{RawBytes: rawBytes, Timestamp: metaTimestamp}
+				if err := value.Verify(metaKey.Key); err != nil {
+					return nil, nil, safeValue, err
+				}
+				return value, ignoredIntents, safeValue, nil
+			}
+			seekKey = seekKey.Next()
 		}
 	} else if txn != nil && timestamp.Less(txn.MaxTimestamp) {
 		// In this branch, the latest timestamp is ahead, and so the read of an
@@ -935,6 +950,48 @@ func MVCCDelete(
 
 var noValue = roachpb.Value{}
 
+// MVCCLock acquires an exclusive lock on the specified key on behalf of a
+// transaction, without writing to the key. The lock is an intent holding
+// the current value of the key (or a deletion tombstone if there is none):
+// other transactions run into it like into any other intent and have to
+// wait for the transaction to finish. Unlike other intents, the lock is
+// removed rather than committed when the transaction commits, so that it
+// doesn't add a version to the key.
+//
+// Locking a key on which the transaction already has an intent is a no-op;
+// writing to a locked key replaces the lock with a regular intent.
+func MVCCLock(
+	ctx context.Context,
+	engine ReadWriter,
+	ms *enginepb.MVCCStats,
+	key roachpb.Key,
+	timestamp hlc.Timestamp,
+	txn *roachpb.Transaction,
+) error {
+	if txn == nil {
+		return errors.Errorf("%q: locks can only be acquired within transactions", key)
+	}
+	iter := engine.NewIterator(true)
+	defer iter.Close()
+	buf := newPutBuffer()
+	defer buf.release()
+
+	ok, _, _, err := mvccGetMetadata(iter, MakeMVCCMetadataKey(key), &buf.meta)
+	if err != nil {
+		return err
+	}
+	if ok && buf.meta.Txn != nil && buf.meta.Txn.ID == txn.ID && buf.meta.Txn.Epoch == txn.Epoch {
+		return nil
+	}
+	return mvccPutInternal(ctx, engine, iter, ms, key, timestamp, nil, txn, buf,
+		func(existing *roachpb.Value) ([]byte, error) {
+			if existing == nil || len(existing.RawBytes) == 0 {
+				return nil, nil
+			}
+			return existing.RawBytes, nil
+		}, true /* lockOnly */)
+}
+
 // mvccPutUsingIter sets the value for a specified key using the provided
 // Iterator. The function takes a value and a valueFn, only one of which
 // should be provided. If the valueFn is nil, value's raw bytes will be set
@@ -961,7 +1018,7 @@ func mvccPutUsingIter(
 	buf := newPutBuffer()
 
 	err := mvccPutInternal(ctx, engine, iter, ms, key, timestamp, rawBytes,
-		txn, buf, valueFn)
+		txn, buf, valueFn, false /* lockOnly */)
 
 	// Using defer would be more convenient, but it is measurably slower.
 	buf.release()
@@ -999,13 +1056,75 @@ func maybeGetValue(
 	return valueFn(exVal)
 }
 
+// mvccGetIntentValue returns the raw bytes of the versioned value written
+// by the intent at the given key and timestamp.
+func mvccGetIntentValue(iter Iterator, metaKey MVCCKey, timestamp hlc.Timestamp) ([]byte, error) {
+	versionKey := metaKey
+	versionKey.Timestamp = timestamp
+	iter.Seek(versionKey)
+	if ok, err := iter.Valid(); err != nil {
+		return nil, err
+	} else if !ok || !iter.UnsafeKey().Equal(versionKey) {
+		return nil, errors.Errorf("intent value missing for key %s at %s", metaKey.Key, timestamp)
+	}
+	return iter.Value(), nil
+}
+
+// mvccRestoreIntentValue rewrites the intent described by meta to hold the
+// latest value from its history which txn did not roll back, updating meta
+// in place. It returns false if txn rolled back all of the values, and
+// otherwise the sizes of the rewritten metadata.
+func mvccRestoreIntentValue(
+	engine Writer,
+	ms *enginepb.MVCCStats,
+	metaKey MVCCKey,
+	meta *enginepb.MVCCMetadata,
+	origMetaKeySize, origMetaValSize int64,
+	txn enginepb.TxnMeta,
+	buf *putBuffer,
+) (bool, int64, int64, error) {
+	value, seq, ok := meta.LatestUnignoredValue(txn)
+	if !ok {
+		return false, origMetaKeySize, origMetaValSize, nil
+	}
+	versionKey := metaKey
+	versionKey.Timestamp = hlc.Timestamp(meta.Timestamp)
+	if err := engine.Put(versionKey, value); err != nil {
+		return false, 0, 0, err
+	}
+
+	newTxn := *meta.Txn
+	newTxn.WriteSeq = seq
+	newMeta := *meta
+	newMeta.Txn = &newTxn
+	newMeta.ValBytes = int64(len(value))
+	newMeta.Deleted = len(value) == 0
+	for i := range newMeta.IntentHistory {
+		if newMeta.IntentHistory[i].Sequence >= seq {
+			newMeta.IntentHistory = newMeta.IntentHistory[:i]
+			break
+		}
+	}
+	metaKeySize, metaValSize, err := buf.putMeta(engine, metaKey, &newMeta)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	if ms != nil {
+		ms.Add(updateStatsOnPut(metaKey.Key, origMetaKeySize, origMetaValSize,
+			metaKeySize, metaValSize, meta, &newMeta))
+	}
+	*meta = newMeta
+	return true, metaKeySize, metaValSize, nil
+}
+
 // mvccPutInternal adds a new timestamped value to the specified key.
 // If value is nil, creates a deletion tombstone value. valueFn is
 // an optional alternative to supplying value directly. It is passed
 // the existing value (or nil if none exists) and returns the value
 // to write or an error. If valueFn is supplied, value should be nil
 // and vice versa. valueFn can delete by returning nil. Returning
-// []byte{} will write an empty value, not delete.
+// []byte{} will write an empty value, not delete. If lockOnly is set, the
+// intent written is marked as only locking the key (see MVCCLock).
 func mvccPutInternal(
 	ctx context.Context,
 	engine Writer,
@@ -1017,6 +1136,7 @@ func mvccPutInternal(
 	txn *roachpb.Transaction,
 	buf *putBuffer,
 	valueFn func(*roachpb.Value) ([]byte, error),
+	lockOnly bool,
 ) error {
 	if len(key) == 0 {
 		return emptyKeyError()
@@ -1058,6 +1178,7 @@ func mvccPutInternal(
 
 	var meta *enginepb.MVCCMetadata
 	var maybeTooOldErr error
+	var intentHistory []enginepb.MVCCMetadata_SequencedIntent
 	if ok {
 		// There is existing metadata for this key; ensure our write is permitted.
 		meta = &buf.meta
@@ -1086,6 +1207,24 @@ func mvccPutInternal(
 				ctx, iter, metaKey, value, ok, timestamp, txn, buf, valueFn); err != nil {
 				return err
 			}
+			if txn.Epoch == meta.Txn.Epoch {
+				// Keep the values written by the txn in earlier savepoint
+				// scopes, so that rolling back to a savepoint can restore them.
+				// The value of a lock is the committed value below the intent,
+				// which is read when there's nothing to restore.
+				intentHistory = meta.IntentHistory
+				if meta.Txn.WriteSeq != txn.WriteSeq && !txn.IsSeqIgnored(meta.Txn.WriteSeq) &&
+					!meta.IsLockOnly() {
+					oldValue, err := mvccGetIntentValue(iter, metaKey, metaTimestamp)
+					if err != nil {
+						return err
+					}
+					intentHistory = append(intentHistory, enginepb.MVCCMetadata_SequencedIntent{
+						Sequence: meta.Txn.WriteSeq,
+						Value:    oldValue,
+					})
+				}
+			}
 			// We are replacing our own older write intent. If we are
 			// writing at the same timestamp we can simply overwrite it;
 			// otherwise we must explicitly delete the obsolete intent.
@@ -1140,11 +1279,19 @@ func mvccPutInternal(
 	{
 		var txnMeta *enginepb.TxnMeta
 		if txn != nil {
-			txnMeta = &txn.TxnMeta
+			// The ignored write sequence numbers are not persisted with the
+			// intent; readers and intent resolution supply their own.
+			buf.newTxn = txn.TxnMeta
+			buf.newTxn.IgnoredSeqNums = nil
+			txnMeta = &buf.newTxn
 		}
 		buf.newMeta = enginepb.MVCCMetadata{
-			Txn:       txnMeta,
-			Timestamp: hlc.LegacyTimestamp(timestamp),
+			Txn:           txnMeta,
+			Timestamp:     hlc.LegacyTimestamp(timestamp),
+			IntentHistory: intentHistory,
+		}
+		if lockOnly {
+			buf.newMeta.LockOnly = proto.Bool(true)
 		}
 	}
 	newMeta := &buf.newMeta
@@ -1443,7 +1590,9 @@ func MVCCDeleteRange(
 			resumeSpan = &roachpb.Span{Key: kv.Key, EndKey: endKey}
 			return true, nil
 		}
-		if err := mvccPutInternal(ctx, engine, iter, ms, kv.Key, timestamp, nil, txn, buf, nil); err != nil {
+		if err := mvccPutInternal(
+			ctx, engine, iter, ms, kv.Key, timestamp, nil, txn, buf, nil, false, /* lockOnly */
+		); err != nil {
 			return true, err
 		}
 		if returnKeys {
@@ -1920,7 +2069,23 @@ func mvccResolveWriteIntent(
 	// restart in EndTransaction, so the replay won't resolve intents.
 	epochsMatch := meta.Txn.Epoch == intent.Txn.Epoch
 	timestampsValid := !intent.Txn.Timestamp.Less(hlc.Timestamp(meta.Timestamp))
-	commit := intent.Status == roachpb.COMMITTED && epochsMatch && timestampsValid
+	// A lock is released like an aborted intent when its transaction
+	// commits; it leaves the key as it was.
+	commit := intent.Status == roachpb.COMMITTED && epochsMatch && timestampsValid &&
+		!meta.IsLockOnly()
+
+	// If the committing txn rolled back the write which produced the
+	// intent's value, commit the latest value it did not roll back instead.
+	// If it rolled back all of its writes to the key, the intent is aborted.
+	if commit && intent.Txn.IsSeqIgnored(meta.Txn.WriteSeq) {
+		var restored bool
+		restored, origMetaKeySize, origMetaValSize, err = mvccRestoreIntentValue(
+			engine, ms, metaKey, meta, origMetaKeySize, origMetaValSize, intent.Txn, buf)
+		if err != nil {
+			return err
+		}
+		commit = restored
+	}
 
 	// Note the small difference to commit epoch handling here: We allow a push
 	// from a previous epoch to move a newer intent. That's not necessary, but
@@ -1963,8 +2128,12 @@ func mvccResolveWriteIntent(
 		var metaKeySize, metaValSize int64
 		var err error
 		if pushed {
-			// Keep intent if we're pushing timestamp.
+			// Keep intent if we're pushing timestamp. The intent's write
+			// sequence number identifies the savepoint scope of its value
+			// and must survive the push.
 			buf.newTxn = intent.Txn
+			buf.newTxn.WriteSeq = meta.Txn.WriteSeq
+			buf.newTxn.IgnoredSeqNums = nil
 			buf.newMeta.Txn = &buf.newTxn
 			metaKeySize, metaValSize, err = buf.putMeta(engine, metaKey, &buf.newMeta)
 		} else {
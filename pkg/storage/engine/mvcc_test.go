@@ -869,6 +869,99 @@ func TestMVCCGetAndDeleteInTxn(t *testing.T) {
 	}
 }
 
+// TestMVCCIgnoredSeqNums verifies that the writes performed by a transaction
+// at ignored write sequence numbers are skipped by the transaction's reads
+// and discarded when its intents are committed.
+func TestMVCCIgnoredSeqNums(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	engine := createTestEngine()
+	defer engine.Close()
+
+	ctx := context.Background()
+	ms := &enginepb.MVCCStats{}
+	txn := txn1.Clone()
+	ts := hlc.Timestamp{WallTime: 1}
+	txn.Timestamp = ts
+
+	// Write value1, value2 and value3 to testKey1 at write sequence numbers 0,
+	// 1 and 2, and value2 to testKey2 at write sequence number 2 only.
+	for i, v := range []roachpb.Value{value1, value2, value3} {
+		txn.Sequence++
+		txn.WriteSeq = int32(i)
+		if err := MVCCPut(ctx, engine, ms, testKey1, ts, v, &txn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	txn.Sequence++
+	if err := MVCCPut(ctx, engine, ms, testKey2, ts, value2, &txn); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		ignored  []enginepb.IgnoredSeqNumRange
+		expected []byte
+	}{
+		{nil, value3.RawBytes},
+		{[]enginepb.IgnoredSeqNumRange{{Start: 2, End: 2}}, value2.RawBytes},
+		{[]enginepb.IgnoredSeqNumRange{{Start: 1, End: 1}}, value3.RawBytes},
+		{[]enginepb.IgnoredSeqNumRange{{Start: 1, End: 2}}, value1.RawBytes},
+		{[]enginepb.IgnoredSeqNumRange{{Start: 2, End: 2}, {Start: 0, End: 1}}, nil},
+	}
+	for i, c := range testCases {
+		txn.IgnoredSeqNums = c.ignored
+		value, _, err := MVCCGet(ctx, engine, testKey1, ts, true, &txn)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if c.expected == nil {
+			if value != nil {
+				t.Errorf("%d: expected no value; got %s", i, value)
+			}
+		} else if value == nil || !bytes.Equal(value.RawBytes, c.expected) {
+			t.Errorf("%d: expected %q; got %v", i, c.expected, value)
+		}
+	}
+
+	// Scans skip the ignored writes too.
+	txn.IgnoredSeqNums = []enginepb.IgnoredSeqNumRange{{Start: 1, End: 2}}
+	kvs, _, _, err := MVCCScan(ctx, engine, testKey1, keyMax, math.MaxInt64, ts, true, &txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || !kvs[0].Key.Equal(testKey1) || !bytes.Equal(kvs[0].Value.RawBytes, value1.RawBytes) {
+		t.Fatalf("unexpected scan results: %v", kvs)
+	}
+
+	// Committing the intents keeps the latest value which wasn't rolled back,
+	// and removes the keys whose writes were all rolled back.
+	txn.Status = roachpb.COMMITTED
+	for _, key := range []roachpb.Key{testKey1, testKey2} {
+		if err := MVCCResolveWriteIntent(ctx, engine, ms, roachpb.Intent{
+			Span: roachpb.Span{Key: key}, Status: txn.Status, Txn: txn.TxnMeta,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if value, _, err := MVCCGet(ctx, engine, testKey1, ts, true, nil); err != nil {
+		t.Fatal(err)
+	} else if value == nil || !bytes.Equal(value.RawBytes, value1.RawBytes) {
+		t.Fatalf("expected %q; got %v", value1.RawBytes, value)
+	}
+	if value, _, err := MVCCGet(ctx, engine, testKey2, ts, true, nil); err != nil {
+		t.Fatal(err)
+	} else if value != nil {
+		t.Fatalf("expected no value; got %s", value)
+	}
+
+	iter := engine.NewIterator(false)
+	defer iter.Close()
+	expMS, err := ComputeStatsGo(iter, mvccKey(roachpb.KeyMin), mvccKey(roachpb.KeyMax), ms.LastUpdateNanos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyStats("after commit", ms, &expMS, t)
+}
+
 func TestMVCCGetWriteIntentError(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	engine := createTestEngine()
@@ -2383,6 +2476,122 @@ func TestMVCCResolveTxn(t *testing.T) {
 	}
 }
 
+// TestMVCCLock verifies that a lock acquired with MVCCLock conflicts with
+// other transactions like an intent, and that it is removed without adding a
+// version to the key when its transaction commits.
+func TestMVCCLock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	engine := createTestEngine()
+	defer engine.Close()
+
+	ts1 := hlc.Timestamp{WallTime: 1}
+	ts2 := hlc.Timestamp{WallTime: 2}
+	ts3 := hlc.Timestamp{WallTime: 3}
+	txn := makeTxn(*txn1, ts2)
+	commit := roachpb.Intent{Txn: txn.TxnMeta, Status: roachpb.COMMITTED}
+
+	numKVs := func(key roachpb.Key) int {
+		var n int
+		if err := engine.Iterate(
+			MakeMVCCMetadataKey(key), MakeMVCCMetadataKey(key.Next()),
+			func(MVCCKeyValue) (bool, error) {
+				n++
+				return false, nil
+			},
+		); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	if err := MVCCLock(ctx, engine, nil, testKey1, ts2, nil); !testutils.IsError(err, "locks can only be acquired within transactions") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := MVCCPut(ctx, engine, nil, testKey1, ts1, value1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCLock(ctx, engine, nil, testKey1, ts2, txn); err != nil {
+		t.Fatal(err)
+	}
+	// Locking the key again is a no-op.
+	if err := MVCCLock(ctx, engine, nil, testKey1, ts2, txn); err != nil {
+		t.Fatal(err)
+	}
+	// The metadata, the lock and the original version.
+	if n := numKVs(testKey1); n != 3 {
+		t.Fatalf("expected 3 kvs, found %d", n)
+	}
+
+	// Other transactions run into the lock.
+	if _, _, err := MVCCGet(ctx, engine, testKey1, ts3, true, nil); err == nil {
+		t.Fatal("expected a conflict with the lock")
+	} else if _, ok := err.(*roachpb.WriteIntentError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := MVCCPut(ctx, engine, nil, testKey1, ts3, value2, makeTxn(*txn2, ts3)); err == nil {
+		t.Fatal("expected a conflict with the lock")
+	} else if _, ok := err.(*roachpb.WriteIntentError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The lock holder sees the value of the key.
+	if value, _, err := MVCCGet(ctx, engine, testKey1, ts2, true, txn); err != nil {
+		t.Fatal(err)
+	} else if value == nil || !bytes.Equal(value.RawBytes, value1.RawBytes) {
+		t.Fatalf("expected %s, got %v", value1, value)
+	}
+
+	// Committing releases the lock and leaves the key as it was.
+	commit.Span = roachpb.Span{Key: testKey1}
+	if err := MVCCResolveWriteIntent(ctx, engine, nil, commit); err != nil {
+		t.Fatal(err)
+	}
+	if value, _, err := MVCCGet(ctx, engine, testKey1, ts3, true, nil); err != nil {
+		t.Fatal(err)
+	} else if value == nil || !bytes.Equal(value.RawBytes, value1.RawBytes) || value.Timestamp != ts1 {
+		t.Fatalf("expected %s at %s, got %v", value1, ts1, value)
+	}
+	if n := numKVs(testKey1); n != 1 {
+		t.Fatalf("expected 1 kv, found %d", n)
+	}
+
+	// Locking a key that doesn't exist doesn't leave anything behind either.
+	if err := MVCCLock(ctx, engine, nil, testKey2, ts2, txn); err != nil {
+		t.Fatal(err)
+	}
+	if value, _, err := MVCCGet(ctx, engine, testKey2, ts2, true, txn); err != nil {
+		t.Fatal(err)
+	} else if value != nil {
+		t.Fatalf("expected no value, got %v", value)
+	}
+	commit.Span = roachpb.Span{Key: testKey2}
+	if err := MVCCResolveWriteIntent(ctx, engine, nil, commit); err != nil {
+		t.Fatal(err)
+	}
+	if n := numKVs(testKey2); n != 0 {
+		t.Fatalf("expected no kvs, found %d", n)
+	}
+
+	// A write to a locked key replaces the lock and is committed.
+	if err := MVCCLock(ctx, engine, nil, testKey3, ts2, txn); err != nil {
+		t.Fatal(err)
+	}
+	if err := MVCCPut(ctx, engine, nil, testKey3, ts2, value2, txn); err != nil {
+		t.Fatal(err)
+	}
+	commit.Span = roachpb.Span{Key: testKey3}
+	if err := MVCCResolveWriteIntent(ctx, engine, nil, commit); err != nil {
+		t.Fatal(err)
+	}
+	if value, _, err := MVCCGet(ctx, engine, testKey3, ts3, true, nil); err != nil {
+		t.Fatal(err)
+	} else if value == nil || !bytes.Equal(value.RawBytes, value2.RawBytes) {
+		t.Fatalf("expected %s, got %v", value2, value)
+	}
+}
+
 // TestMVCCResolveNewerIntent verifies that resolving a newer intent
 // than the committing transaction aborts the intent.
 func TestMVCCResolveNewerIntent(t *testing.T) {
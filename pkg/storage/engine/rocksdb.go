@@ -306,6 +306,9 @@ type RocksDBConfig struct {
 	WarnLargeBatchThreshold time.Duration
 	// Settings instance for cluster-wide knobs.
 	Settings *cluster.Settings
+	// ExtraOptions is a serialized protobuf set by Go CCL code and passed
+	// through to C CCL code.
+	ExtraOptions []byte
 }
 
 // RocksDB is a wrapper around a RocksDB database instance.
@@ -439,6 +442,7 @@ func (r *RocksDB) open() error {
 			logging_enabled: C.bool(log.V(3)),
 			num_cpu:         C.int(runtime.NumCPU()),
 			max_open_files:  C.int(maxOpenFiles),
+			extra_options:   goToCSlice(r.cfg.ExtraOptions),
 		})
 	if err := statusToError(status); err != nil {
 		return errors.Errorf("could not open rocksdb instance: %s", err)
@@ -1059,9 +1063,6 @@ func (r *distinctBatch) Clear(key MVCCKey) error {
 }
 
 func (r *distinctBatch) ClearRange(start, end MVCCKey) error {
-	if !r.writeOnly {
-		panic("readable batch")
-	}
 	r.flushMutations()
 	r.flushes++ // make sure that Repr() doesn't take a shortcut
 	r.ensureBatch()
@@ -1309,9 +1310,6 @@ func (r *rocksDBBatch) Clear(key MVCCKey) error {
 }
 
 func (r *rocksDBBatch) ClearRange(start, end MVCCKey) error {
-	if !r.writeOnly {
-		panic("readable batch")
-	}
 	if r.distinctOpen {
 		panic("distinct batch open")
 	}
@@ -2191,7 +2191,7 @@ func (r *Replica) beginCmds(
 			switch scope {
 			case spanset.SpanGlobal:
 				if txn := ba.Txn; txn != nil {
-					return txn.OrigTimestamp
+					return txn.ReadTimestamp()
 				}
 				return ba.Timestamp
 			case spanset.SpanLocal:
@@ -2297,7 +2297,7 @@ func (r *Replica) beginCmds(
 	//   directly into the local range they're servicing.
 	if ba.Timestamp == (hlc.Timestamp{}) {
 		if txn := ba.Txn; txn != nil {
-			ba.Timestamp = txn.OrigTimestamp
+			ba.Timestamp = txn.ReadTimestamp()
 		} else {
 			ba.Timestamp = r.store.Clock().Now()
 		}
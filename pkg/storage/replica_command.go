@@ -101,6 +101,9 @@ var commands = map[roachpb.Method]Command{
 	roachpb.WriteBatch:         writeBatchCmd,
 	roachpb.Export:             exportCmd,
 	roachpb.AddSSTable:         addSSTableCmd,
+	roachpb.Refresh:            {DeclareKeys: batcheval.DefaultDeclareKeys, Eval: batcheval.Refresh},
+	roachpb.RefreshRange:       {DeclareKeys: batcheval.DefaultDeclareKeys, Eval: batcheval.RefreshRange},
+	roachpb.ClearRange:         {DeclareKeys: declareKeysClearRange, Eval: batcheval.ClearRange},
 
 	roachpb.DeprecatedVerifyChecksum: {
 		DeclareKeys: batcheval.DefaultDeclareKeys,
@@ -431,6 +434,11 @@ func evalEndTransaction(
 	// a transaction is always set to the txn's original timestamp.
 	reply.Txn.Timestamp.Forward(h.Txn.Timestamp)
 
+	// Only the requester knows which writes were rolled back to savepoints;
+	// record them so that resolving the intents discards those writes.
+	reply.Txn.WriteSeq = h.Txn.WriteSeq
+	reply.Txn.IgnoredSeqNums = h.Txn.IgnoredSeqNums
+
 	// Set transaction status to COMMITTED or ABORTED as per the
 	// args.Commit parameter.
 	if args.Commit {
@@ -521,7 +529,9 @@ func isEndTransactionTriggeringRetryError(
 		return true, roachpb.RETRY_WRITE_TOO_OLD
 	}
 
-	isTxnPushed := currentTxn.Timestamp != headerTxn.OrigTimestamp
+	// The transaction's reads are valid up to its read timestamp, which
+	// is ahead of its original timestamp if the reads have been refreshed.
+	isTxnPushed := currentTxn.Timestamp != headerTxn.ReadTimestamp()
 
 	// If the isolation level is SERIALIZABLE, return a transaction
 	// retry error if the commit timestamp isn't equal to the txn
@@ -780,6 +790,17 @@ func declareKeysHeartbeatTransaction(
 	}
 }
 
+func declareKeysClearRange(
+	desc roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *spanset.SpanSet,
+) {
+	batcheval.DefaultDeclareKeys(desc, header, req, spans)
+	// The range descriptor and stats are consulted to determine whether the
+	// whole range is being cleared, in which case the stats delta doesn't have
+	// to be computed by scanning the span.
+	spans.Add(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeDescriptorKey(desc.StartKey)})
+	spans.Add(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeStatsKey(header.RangeID)})
+}
+
 func declareKeysGC(
 	desc roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *spanset.SpanSet,
 ) {
@@ -5669,6 +5669,62 @@ func TestReplicaResolveIntentRange(t *testing.T) {
 	}
 }
 
+// TestReplicaRefreshRequests verifies that Refresh and RefreshRange requests
+// fail only if a key in their span was written between the transaction's
+// original timestamp and its refreshed timestamp.
+func TestReplicaRefreshRequests(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	txn := newTransaction("test", roachpb.Key("a"), 1, enginepb.SERIALIZABLE, tc.Clock())
+
+	// A write to "b" after the transaction's original timestamp.
+	pArgs := putArgs(roachpb.Key("b"), []byte("value"))
+	if _, pErr := tc.SendWrapped(&pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	// An intent written by the transaction itself on "c".
+	pArgs = putArgs(roachpb.Key("c"), []byte("value"))
+	txn.Sequence++
+	if _, pErr := tc.SendWrappedWith(roachpb.Header{Txn: txn}, &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	// An intent written by another transaction on "d".
+	otherTxn := newTransaction("other", roachpb.Key("d"), 1, enginepb.SERIALIZABLE, tc.Clock())
+	pArgs = putArgs(roachpb.Key("d"), []byte("value"))
+	otherTxn.Sequence++
+	if _, pErr := tc.SendWrappedWith(roachpb.Header{Txn: otherTxn}, &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	refreshTxn := txn.Clone()
+	refreshTxn.Timestamp = tc.Clock().Now()
+	refreshTxn.RefreshedTimestamp = refreshTxn.Timestamp
+
+	testCases := []struct {
+		req    roachpb.Request
+		expErr string
+	}{
+		{&roachpb.RefreshRequest{Span: roachpb.Span{Key: roachpb.Key("a")}}, ""},
+		{&roachpb.RefreshRequest{Span: roachpb.Span{Key: roachpb.Key("b")}}, "encountered recently written key"},
+		{&roachpb.RefreshRequest{Span: roachpb.Span{Key: roachpb.Key("c")}}, ""},
+		{&roachpb.RefreshRequest{Span: roachpb.Span{Key: roachpb.Key("d")}}, "encountered recently written intent"},
+		{&roachpb.RefreshRangeRequest{Span: roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}}, ""},
+		{&roachpb.RefreshRangeRequest{Span: roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("c")}}, "encountered recently written key"},
+		{&roachpb.RefreshRangeRequest{Span: roachpb.Span{Key: roachpb.Key("c"), EndKey: roachpb.Key("d")}}, ""},
+		{&roachpb.RefreshRangeRequest{Span: roachpb.Span{Key: roachpb.Key("c"), EndKey: roachpb.Key("e")}}, "encountered recently written intent"},
+	}
+	for i, test := range testCases {
+		_, pErr := tc.SendWrappedWith(roachpb.Header{Txn: &refreshTxn}, test.req)
+		if !testutils.IsPError(pErr, test.expErr) {
+			t.Errorf("%d: expected error %q; got %v", i, test.expErr, pErr)
+		}
+	}
+}
+
 func verifyRangeStats(eng engine.Reader, rangeID roachpb.RangeID, expMS enginepb.MVCCStats) error {
 	ms, err := engine.MVCCGetRangeStats(context.Background(), eng, rangeID)
 	if err != nil {
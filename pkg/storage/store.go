@@ -2604,6 +2604,11 @@ func (s *Store) Send(
 				} else {
 					pushType = roachpb.PUSH_TIMESTAMP
 				}
+				if ba.NoWait {
+					// Don't queue up behind live conflicting transactions, but still
+					// clean up after abandoned or finished ones.
+					pushType = roachpb.PUSH_TOUCH
+				}
 
 				index := pErr.Index
 				args := ba.Requests[index.Index].GetInner()
@@ -2625,7 +2630,14 @@ func (s *Store) Send(
 					clonedTxn := h.Txn.Clone()
 					h.Txn = &clonedTxn
 				}
+				wiPErr := pErr
 				if pErr = s.intentResolver.processWriteIntentError(ctx, pErr, args, h, pushType); pErr != nil {
+					if _, ok := pErr.GetDetail().(*roachpb.TransactionPushError); ok && ba.NoWait {
+						// The conflicting transaction is still live. Hand the original
+						// conflict back to the client rather than the push failure,
+						// which the client would otherwise treat as retryable.
+						pErr = wiPErr
+					}
 					// Do not propagate ambiguous results; assume success and retry original op.
 					if _, ok := pErr.GetDetail().(*roachpb.AmbiguousResultError); !ok {
 						// Preserve the error index.
@@ -1306,6 +1306,51 @@ func TestStoreResolveWriteIntent(t *testing.T) {
 	}
 }
 
+// TestStoreWriteIntentErrorNoWait verifies that a request with the NoWait
+// header flag returns a WriteIntentError instead of waiting for a live
+// conflicting transaction, and succeeds once that transaction has finished.
+func TestStoreWriteIntentErrorNoWait(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store, _ := createTestStore(t, stopper)
+
+	key := roachpb.Key("a")
+	pushee := newTransaction("test", key, 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+	pusher := newTransaction("test", key, 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+	pushee.Priority = roachpb.MaxTxnPriority
+	pusher.Priority = roachpb.MinTxnPriority // Pusher would have to wait.
+
+	// First lay down intent using the pushee's txn.
+	pArgs := putArgs(key, []byte("value"))
+	h := roachpb.Header{Txn: pushee}
+	pushee.Sequence++
+	if _, pErr := maybeWrapWithBeginTransaction(context.Background(), store.testSender(), h, &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Both reads and writes fail immediately with NoWait.
+	h = roachpb.Header{Txn: pusher, NoWait: true}
+	gArgs := getArgs(key)
+	for _, args := range []roachpb.Request{&pArgs, &gArgs} {
+		_, pErr := client.SendWrappedWith(context.Background(), store.testSender(), h, args)
+		if _, ok := pErr.GetDetail().(*roachpb.WriteIntentError); !ok {
+			t.Fatalf("%s: expected WriteIntentError; got %v", args.Method(), pErr)
+		}
+	}
+
+	// Once the pushee has committed, its intent is resolved and the request
+	// goes through.
+	etArgs, etH := endTxnArgs(pushee, true)
+	pushee.Sequence++
+	if _, pErr := client.SendWrappedWith(context.Background(), store.testSender(), etH, &etArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if _, pErr := client.SendWrappedWith(context.Background(), store.testSender(), h, &gArgs); pErr != nil {
+		t.Fatalf("expected read to succeed after pushee committed; got %s", pErr)
+	}
+}
+
 // TestStoreResolveWriteIntentRollback verifies that resolving a write
 // intent by aborting it yields the previous value.
 func TestStoreResolveWriteIntentRollback(t *testing.T) {
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -70,6 +71,22 @@ const (
 	decimalNaNDesc          = decimalInfinity + 1 // NaN encoded descendingly
 	decimalTerminator       = 0x00
 
+	timeTZMarker = decimalNaNDesc + 1
+
+	arrayKeyMarker     = timeTZMarker + 1
+	arrayKeyDescMarker = arrayKeyMarker + 1
+
+	// The elements of a key-encoded array are followed by a terminator which
+	// sorts before (or, descendingly, after) every element, so that an array
+	// sorts before any longer array it is a prefix of. NULL elements use their
+	// own marker, which sorts between the terminator and the other elements.
+	// These bytes overlap with the NULL and not-NULL markers, but they are not
+	// ambiguous because they only appear within an array.
+	arrayKeyTerminator           = 0x00
+	arrayKeyDescTerminator       = 0xff
+	ascendingNullWithinArrayKey  = 0x01
+	descendingNullWithinArrayKey = 0xfe
+
 	// IntMin is chosen such that the range of int tags does not overlap the
 	// ascii character set that is frequently used in testing.
 	IntMin      = 0x80 // 128
@@ -754,6 +771,224 @@ func decodeTime(b []byte) (r []byte, sec int64, nsec int64, err error) {
 	return b, sec, nsec, nil
 }
 
+// EncodeTimeTZAscending encodes a time of day with a zone offset (in seconds
+// east of UTC), appends it to the supplied buffer, and returns the final
+// buffer. Values are ordered by the time of day in UTC and then, for equal
+// UTC times, by descending offset.
+func EncodeTimeTZAscending(b []byte, t timeofday.TimeOfDay, offsetSecs int32) []byte {
+	utcMicros, negOffset := timeTZSortKey(t, offsetSecs)
+	return encodeTimeTZ(b, utcMicros, negOffset)
+}
+
+// EncodeTimeTZDescending is the descending version of EncodeTimeTZAscending.
+func EncodeTimeTZDescending(b []byte, t timeofday.TimeOfDay, offsetSecs int32) []byte {
+	utcMicros, negOffset := timeTZSortKey(t, offsetSecs)
+	return encodeTimeTZ(b, ^utcMicros, ^negOffset)
+}
+
+func timeTZSortKey(t timeofday.TimeOfDay, offsetSecs int32) (utcMicros int64, negOffset int64) {
+	utcMicros = int64(t) - int64(offsetSecs)*int64(time.Second/time.Microsecond)
+	return utcMicros, -int64(offsetSecs)
+}
+
+func encodeTimeTZ(b []byte, utcMicros, negOffset int64) []byte {
+	b = append(b, timeTZMarker)
+	b = EncodeVarintAscending(b, utcMicros)
+	b = EncodeVarintAscending(b, negOffset)
+	return b
+}
+
+// DecodeTimeTZAscending decodes a time of day and zone offset which were
+// encoded using EncodeTimeTZAscending.
+func DecodeTimeTZAscending(b []byte) ([]byte, timeofday.TimeOfDay, int32, error) {
+	b, utcMicros, negOffset, err := decodeTimeTZ(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	t, offsetSecs := timeTZFromSortKey(utcMicros, negOffset)
+	return b, t, offsetSecs, nil
+}
+
+// DecodeTimeTZDescending is the descending version of DecodeTimeTZAscending.
+func DecodeTimeTZDescending(b []byte) ([]byte, timeofday.TimeOfDay, int32, error) {
+	b, utcMicros, negOffset, err := decodeTimeTZ(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	t, offsetSecs := timeTZFromSortKey(^utcMicros, ^negOffset)
+	return b, t, offsetSecs, nil
+}
+
+func timeTZFromSortKey(utcMicros, negOffset int64) (timeofday.TimeOfDay, int32) {
+	offsetSecs := int32(-negOffset)
+	t := timeofday.FromInt(utcMicros + int64(offsetSecs)*int64(time.Second/time.Microsecond))
+	return t, offsetSecs
+}
+
+func decodeTimeTZ(b []byte) (r []byte, utcMicros int64, negOffset int64, err error) {
+	if PeekType(b) != TimeTZ {
+		return nil, 0, 0, errors.Errorf("did not find marker")
+	}
+	b = b[1:]
+	b, utcMicros, err = DecodeVarintAscending(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	b, negOffset, err = DecodeVarintAscending(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	return b, utcMicros, negOffset, nil
+}
+
+// EncodeArrayKeyMarker appends the marker which begins an array encoded in a
+// key with the given direction, and returns the final buffer. The marker is
+// followed by the key encoding of each element in the same direction (using
+// EncodeNullWithinArrayKey for NULL elements) and then by
+// EncodeArrayKeyTerminator. Arrays encoded this way are ordered element by
+// element; arrays with equal elements are ordered by their number of
+// elements, their number of dimensions, the lengths of their dimensions and
+// finally the lower bounds of their dimensions.
+func EncodeArrayKeyMarker(b []byte, dir Direction) []byte {
+	if dir == Descending {
+		return append(b, arrayKeyDescMarker)
+	}
+	return append(b, arrayKeyMarker)
+}
+
+// EncodeNullWithinArrayKey appends the encoding of a NULL element of an array
+// encoded in a key with the given direction, and returns the final buffer.
+func EncodeNullWithinArrayKey(b []byte, dir Direction) []byte {
+	if dir == Descending {
+		return append(b, descendingNullWithinArrayKey)
+	}
+	return append(b, ascendingNullWithinArrayKey)
+}
+
+// EncodeArrayKeyTerminator appends the terminator of an array encoded in a
+// key with the given direction, followed by the shape of the array: its
+// number of dimensions and the length and lower bound of each dimension.
+// dims and lowerBounds must have the same length.
+func EncodeArrayKeyTerminator(b []byte, dims, lowerBounds []int, dir Direction) []byte {
+	terminator, encodeVarint := byte(arrayKeyTerminator), EncodeVarintAscending
+	if dir == Descending {
+		terminator, encodeVarint = arrayKeyDescTerminator, EncodeVarintDescending
+	}
+	b = append(b, terminator)
+	b = encodeVarint(b, int64(len(dims)))
+	for _, d := range dims {
+		b = encodeVarint(b, int64(d))
+	}
+	for _, lb := range lowerBounds {
+		b = encodeVarint(b, int64(lb))
+	}
+	return b
+}
+
+// DecodeArrayKeyMarker decodes the marker which begins an array encoded in a
+// key with the given direction. The remainder of the input buffer is
+// returned.
+func DecodeArrayKeyMarker(b []byte, dir Direction) ([]byte, error) {
+	marker := byte(arrayKeyMarker)
+	if dir == Descending {
+		marker = arrayKeyDescMarker
+	}
+	if len(b) == 0 || b[0] != marker {
+		return nil, errors.Errorf("did not find array key marker %x", b)
+	}
+	return b[1:], nil
+}
+
+// DecodeIfNullWithinArrayKey decodes a NULL element of an array encoded in a
+// key with the given direction. If the input buffer starts with such an
+// element, it is removed from the buffer and true is returned for the second
+// result. Otherwise, the buffer is returned unchanged and false is returned.
+func DecodeIfNullWithinArrayKey(b []byte, dir Direction) ([]byte, bool) {
+	null := byte(ascendingNullWithinArrayKey)
+	if dir == Descending {
+		null = descendingNullWithinArrayKey
+	}
+	if len(b) > 0 && b[0] == null {
+		return b[1:], true
+	}
+	return b, false
+}
+
+// IsArrayKeyDone returns whether the input buffer starts with the terminator
+// of an array encoded in a key with the given direction.
+func IsArrayKeyDone(b []byte, dir Direction) bool {
+	terminator := byte(arrayKeyTerminator)
+	if dir == Descending {
+		terminator = arrayKeyDescTerminator
+	}
+	return len(b) > 0 && b[0] == terminator
+}
+
+// DecodeArrayKeyTerminator decodes the terminator and shape of an array
+// encoded in a key with the given direction. The remainder of the input
+// buffer and the length and lower bound of each dimension of the array are
+// returned.
+func DecodeArrayKeyTerminator(
+	b []byte, dir Direction,
+) (_ []byte, dims []int, lowerBounds []int, err error) {
+	decodeVarint := DecodeVarintAscending
+	if dir == Descending {
+		decodeVarint = DecodeVarintDescending
+	}
+	if !IsArrayKeyDone(b, dir) {
+		return nil, nil, nil, errors.Errorf("did not find array key terminator %x", b)
+	}
+	b = b[1:]
+	var numDims int64
+	b, numDims, err = decodeVarint(b)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Each dimension takes up at least two bytes.
+	if numDims < 0 || 2*numDims > int64(len(b)) {
+		return nil, nil, nil, errors.Errorf("invalid number of array dimensions: %d", numDims)
+	}
+	shape := make([]int, 2*numDims)
+	for i := range shape {
+		var v int64
+		b, v, err = decodeVarint(b)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		shape[i] = int(v)
+	}
+	return b, shape[:numDims:numDims], shape[numDims:], nil
+}
+
+// getArrayKeyLength returns the length of the array encoded in a key with
+// the given direction at the start of b.
+func getArrayKeyLength(b []byte, dir Direction) (int, error) {
+	// Skip the marker.
+	p := 1
+	for {
+		if p >= len(b) {
+			return 0, errors.Errorf("did not find array key terminator in buffer %x", b)
+		}
+		if IsArrayKeyDone(b[p:], dir) {
+			break
+		}
+		if _, isNull := DecodeIfNullWithinArrayKey(b[p:], dir); isNull {
+			p++
+			continue
+		}
+		n, err := PeekLength(b[p:])
+		if err != nil {
+			return 0, err
+		}
+		p += n
+	}
+	rest, _, _, err := DecodeArrayKeyTerminator(b[p:], dir)
+	if err != nil {
+		return 0, err
+	}
+	return len(b) - len(rest), nil
+}
+
 // EncodeDurationAscending encodes a duration.Duration value, appends it to the
 // supplied buffer, and returns the final buffer. The encoding is guaranteed to
 // be ordered such that if t1.Compare(t2) < 0 (or = 0 or > 0) then bytes.Compare
@@ -865,6 +1100,9 @@ const (
 	// Do not change SentinelType from 15. This value is specifically used for bit
 	// manipulation in EncodeValueTag.
 	SentinelType Type = 15 // Used in the Value encoding.
+	TimeTZ       Type = 16
+	ArrayKeyAsc  Type = 17 // Array key encoding
+	ArrayKeyDesc Type = 18 // Array key encoded descendingly
 )
 
 // PeekType peeks at the type of the value encoded at the start of b.
@@ -882,6 +1120,12 @@ func PeekType(b []byte) Type {
 			return BytesDesc
 		case m == timeMarker:
 			return Time
+		case m == timeTZMarker:
+			return TimeTZ
+		case m == arrayKeyMarker:
+			return ArrayKeyAsc
+		case m == arrayKeyDescMarker:
+			return ArrayKeyDesc
 		case m == durationBigNegMarker, m == durationMarker, m == durationBigPosMarker:
 			return Duration
 		case m >= IntMin && m <= IntMax:
@@ -938,8 +1182,12 @@ func PeekLength(b []byte) (int, error) {
 		return getBytesLength(b, ascendingEscapes)
 	case bytesDescMarker:
 		return getBytesLength(b, descendingEscapes)
-	case timeMarker:
+	case timeMarker, timeTZMarker:
 		return GetMultiVarintLen(b, 2)
+	case arrayKeyMarker:
+		return getArrayKeyLength(b, Ascending)
+	case arrayKeyDescMarker:
+		return getArrayKeyLength(b, Descending)
 	case durationBigNegMarker, durationMarker, durationBigPosMarker:
 		return GetMultiVarintLen(b, 3)
 	case floatNeg, floatPos:
@@ -1028,6 +1276,14 @@ func prettyPrintFirstValue(b []byte) ([]byte, string, error) {
 			return b, "", err
 		}
 		return b, t.UTC().Format(time.RFC3339Nano), nil
+	case TimeTZ:
+		var t timeofday.TimeOfDay
+		var offsetSecs int32
+		b, t, offsetSecs, err = DecodeTimeTZAscending(b)
+		if err != nil {
+			return b, "", err
+		}
+		return b, prettyPrintTimeTZ(t, offsetSecs), nil
 	case Duration:
 		var d duration.Duration
 		b, d, err = DecodeDurationAscending(b)
@@ -1035,12 +1291,65 @@ func prettyPrintFirstValue(b []byte) ([]byte, string, error) {
 			return b, "", err
 		}
 		return b, d.String(), nil
+	case ArrayKeyAsc:
+		return prettyPrintArrayKey(b, Ascending)
+	case ArrayKeyDesc:
+		return prettyPrintArrayKey(b, Descending)
 	default:
 		// This shouldn't ever happen, but if it does, return an empty slice.
 		return nil, strconv.Quote(string(b)), nil
 	}
 }
 
+// prettyPrintArrayKey returns a string representation of the elements of the
+// key-encoded array at the start of b, along with the remaining byte slice
+// after decoding.
+func prettyPrintArrayKey(b []byte, dir Direction) ([]byte, string, error) {
+	b, err := DecodeArrayKeyMarker(b, dir)
+	if err != nil {
+		return b, "", err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("ARRAY[")
+	for i := 0; !IsArrayKeyDone(b, dir); i++ {
+		if len(b) == 0 {
+			return b, "", errors.Errorf("did not find array key terminator")
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		var isNull bool
+		if b, isNull = DecodeIfNullWithinArrayKey(b, dir); isNull {
+			buf.WriteString("NULL")
+			continue
+		}
+		var s string
+		b, s, err = prettyPrintFirstValue(b)
+		if err != nil {
+			return b, "", err
+		}
+		buf.WriteString(s)
+	}
+	buf.WriteByte(']')
+	b, _, _, err = DecodeArrayKeyTerminator(b, dir)
+	return b, buf.String(), err
+}
+
+// prettyPrintTimeTZ formats a time of day and zone offset as
+// HH:MM:SS[.ffffff]+HH:MM[:SS].
+func prettyPrintTimeTZ(t timeofday.TimeOfDay, offsetSecs int32) string {
+	sign := '+'
+	if offsetSecs < 0 {
+		sign = '-'
+		offsetSecs = -offsetSecs
+	}
+	s := fmt.Sprintf("%s%c%02d:%02d", t, sign, offsetSecs/3600, (offsetSecs%3600)/60)
+	if secs := offsetSecs % 60; secs != 0 {
+		s += fmt.Sprintf(":%02d", secs)
+	}
+	return s
+}
+
 // NonsortingVarintMaxLen is the maximum length of an EncodeNonsortingVarint
 // encoded value.
 const NonsortingVarintMaxLen = binary.MaxVarintLen64
@@ -1255,6 +1564,22 @@ func EncodeUntaggedTimeValue(appendTo []byte, t time.Time) []byte {
 	return EncodeNonsortingStdlibVarint(appendTo, int64(t.Nanosecond()))
 }
 
+// EncodeTimeTZValue encodes a time of day and zone offset with its value tag,
+// appends it to the supplied buffer, and returns the final buffer.
+func EncodeTimeTZValue(
+	appendTo []byte, colID uint32, t timeofday.TimeOfDay, offsetSecs int32,
+) []byte {
+	appendTo = EncodeValueTag(appendTo, colID, TimeTZ)
+	return EncodeUntaggedTimeTZValue(appendTo, t, offsetSecs)
+}
+
+// EncodeUntaggedTimeTZValue encodes a time of day and zone offset, appends it
+// to the supplied buffer, and returns the final buffer.
+func EncodeUntaggedTimeTZValue(appendTo []byte, t timeofday.TimeOfDay, offsetSecs int32) []byte {
+	appendTo = EncodeNonsortingStdlibVarint(appendTo, int64(t))
+	return EncodeNonsortingStdlibVarint(appendTo, int64(offsetSecs))
+}
+
 // EncodeDecimalValue encodes an apd.Decimal value with its value tag, appends
 // it to the supplied buffer, and returns the final buffer.
 func EncodeDecimalValue(appendTo []byte, colID uint32, d *apd.Decimal) []byte {
@@ -1460,6 +1785,34 @@ func DecodeUntaggedTimeValue(b []byte) (remaining []byte, t time.Time, err error
 	return b, timeutil.Unix(sec, nsec), nil
 }
 
+// DecodeTimeTZValue decodes a value encoded by EncodeTimeTZValue.
+func DecodeTimeTZValue(
+	b []byte,
+) (remaining []byte, t timeofday.TimeOfDay, offsetSecs int32, err error) {
+	b, err = decodeValueTypeAssert(b, TimeTZ)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	return DecodeUntaggedTimeTZValue(b)
+}
+
+// DecodeUntaggedTimeTZValue decodes a value encoded by
+// EncodeUntaggedTimeTZValue.
+func DecodeUntaggedTimeTZValue(
+	b []byte,
+) (remaining []byte, t timeofday.TimeOfDay, offsetSecs int32, err error) {
+	var micros, offset int64
+	b, _, micros, err = DecodeNonsortingStdlibVarint(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	b, _, offset, err = DecodeNonsortingStdlibVarint(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	return b, timeofday.TimeOfDay(micros), int32(offset), nil
+}
+
 // DecodeDecimalValue decodes a value encoded by EncodeDecimalValue.
 func DecodeDecimalValue(b []byte) (remaining []byte, d apd.Decimal, err error) {
 	b, err = decodeValueTypeAssert(b, Decimal)
@@ -1593,7 +1946,7 @@ func PeekValueLength(b []byte) (typeOffset int, length int, err error) {
 	case Decimal:
 		_, n, i, err := DecodeNonsortingStdlibUvarint(b)
 		return typeOffset, dataOffset + n + int(i), err
-	case Time:
+	case Time, TimeTZ:
 		n, err := getMultiNonsortingVarintLen(b, 2)
 		return typeOffset, dataOffset + n, err
 	case Duration:
@@ -1637,7 +1990,7 @@ func UpperBoundValueEncodingSize(colID uint32, typ Type, size int) (int, bool) {
 			return len(encodedTag) + maxBinaryUvarintSize + upperBoundNonsortingDecimalUnscaledSize(size), true
 		}
 		return 0, false
-	case Time:
+	case Time, TimeTZ:
 		return len(encodedTag) + 2*maxVarintSize, true
 	case Duration:
 		return len(encodedTag) + 3*maxVarintSize, true
@@ -1703,6 +2056,14 @@ func PrettyPrintValueEncoded(b []byte) ([]byte, string, error) {
 			return b, "", err
 		}
 		return b, t.UTC().Format(time.RFC3339Nano), nil
+	case TimeTZ:
+		var t timeofday.TimeOfDay
+		var offsetSecs int32
+		b, t, offsetSecs, err = DecodeTimeTZValue(b)
+		if err != nil {
+			return b, "", err
+		}
+		return b, prettyPrintTimeTZ(t, offsetSecs), nil
 	case Duration:
 		var d duration.Duration
 		b, d, err = DecodeDurationValue(b)
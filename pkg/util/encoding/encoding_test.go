@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"math"
 	"math/rand"
+	"reflect"
 	"regexp"
 	"testing"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -766,6 +768,137 @@ func TestEncodeDecodeTime(t *testing.T) {
 	}
 }
 
+func TestEncodeDecodeTimeTZ(t *testing.T) {
+	// Test cases are in ascending order: by time of day in UTC first and then,
+	// for equal UTC times, by descending offset.
+	testCases := []struct {
+		t          timeofday.TimeOfDay
+		offsetSecs int32
+	}{
+		{timeofday.New(1, 0, 0, 0), 14 * 60 * 60},
+		{timeofday.New(5, 0, 0, 0), 5 * 60 * 60},
+		{timeofday.New(0, 0, 0, 0), 0},
+		{timeofday.New(0, 0, 0, 1), 0},
+		{timeofday.New(12, 0, 0, 0), 60},
+		{timeofday.New(11, 59, 0, 0), 0},
+		{timeofday.New(12, 0, 0, 0), 0},
+		{timeofday.New(7, 0, 0, 0), -5 * 60 * 60},
+		{timeofday.New(12, 0, 0, 0), -1},
+		{timeofday.Max, 0},
+		{timeofday.New(23, 0, 0, 0), -14 * 60 * 60},
+	}
+	for _, dir := range []Direction{Ascending, Descending} {
+		var lastEncoded []byte
+		for i, test := range testCases {
+			var b []byte
+			var decoded timeofday.TimeOfDay
+			var decodedOffset int32
+			var err error
+			if dir == Ascending {
+				b = EncodeTimeTZAscending(b, test.t, test.offsetSecs)
+				_, decoded, decodedOffset, err = DecodeTimeTZAscending(b)
+			} else {
+				b = EncodeTimeTZDescending(b, test.t, test.offsetSecs)
+				_, decoded, decodedOffset, err = DecodeTimeTZDescending(b)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded != test.t || decodedOffset != test.offsetSecs {
+				t.Fatalf("lossy transport: before (%s, %d) vs after (%s, %d)",
+					test.t, test.offsetSecs, decoded, decodedOffset)
+			}
+			testPeekLength(t, b)
+			if i > 0 {
+				if (bytes.Compare(lastEncoded, b) >= 0 && dir == Ascending) ||
+					(bytes.Compare(lastEncoded, b) <= 0 && dir == Descending) {
+					t.Fatalf("%d: encodings not in order: [% x], [% x]", i, lastEncoded, b)
+				}
+			}
+			lastEncoded = b
+		}
+	}
+}
+
+func TestEncodeDecodeArrayKey(t *testing.T) {
+	type arrayKey struct {
+		// A nil element represents a NULL.
+		elems       []interface{}
+		dims        []int
+		lowerBounds []int
+	}
+	// Test cases are in ascending order.
+	testCases := []arrayKey{
+		{nil, []int{}, []int{}},
+		{[]interface{}{nil}, []int{1}, []int{1}},
+		{[]interface{}{nil, int64(1)}, []int{2}, []int{1}},
+		{[]interface{}{int64(-5)}, []int{1}, []int{1}},
+		{[]interface{}{int64(1)}, []int{1}, []int{1}},
+		{[]interface{}{int64(1)}, []int{1}, []int{2}},
+		{[]interface{}{int64(1), int64(2)}, []int{2}, []int{1}},
+		{[]interface{}{int64(1), int64(2)}, []int{1, 2}, []int{1, 1}},
+		{[]interface{}{int64(1), int64(2)}, []int{2, 1}, []int{0, 1}},
+		{[]interface{}{int64(1), int64(2)}, []int{2, 1}, []int{1, 1}},
+		{[]interface{}{int64(1), int64(2), nil}, []int{3}, []int{1}},
+		{[]interface{}{int64(1), int64(3)}, []int{2}, []int{-1}},
+		{[]interface{}{int64(2)}, []int{1}, []int{1}},
+	}
+	for _, dir := range []Direction{Ascending, Descending} {
+		encodeVarint, decodeVarint := EncodeVarintAscending, DecodeVarintAscending
+		if dir == Descending {
+			encodeVarint, decodeVarint = EncodeVarintDescending, DecodeVarintDescending
+		}
+		var lastEncoded []byte
+		for i, test := range testCases {
+			b := EncodeArrayKeyMarker(nil, dir)
+			for _, e := range test.elems {
+				if e == nil {
+					b = EncodeNullWithinArrayKey(b, dir)
+				} else {
+					b = encodeVarint(b, e.(int64))
+				}
+			}
+			b = EncodeArrayKeyTerminator(b, test.dims, test.lowerBounds, dir)
+
+			var decoded arrayKey
+			rem, err := DecodeArrayKeyMarker(b, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for !IsArrayKeyDone(rem, dir) {
+				var isNull bool
+				if rem, isNull = DecodeIfNullWithinArrayKey(rem, dir); isNull {
+					decoded.elems = append(decoded.elems, nil)
+					continue
+				}
+				var v int64
+				if rem, v, err = decodeVarint(rem); err != nil {
+					t.Fatal(err)
+				}
+				decoded.elems = append(decoded.elems, v)
+			}
+			rem, decoded.dims, decoded.lowerBounds, err = DecodeArrayKeyTerminator(rem, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rem) != 0 {
+				t.Fatalf("%d: unexpected remaining bytes: [% x]", i, rem)
+			}
+			if !reflect.DeepEqual(decoded, test) {
+				t.Fatalf("lossy transport: before %v vs after %v", test, decoded)
+			}
+			testPeekLength(t, b)
+			if i > 0 {
+				if (bytes.Compare(lastEncoded, b) >= 0 && dir == Ascending) ||
+					(bytes.Compare(lastEncoded, b) <= 0 && dir == Descending) {
+					t.Fatalf("%d: encodings not in order: [% x], [% x]", i, lastEncoded, b)
+				}
+			}
+			lastEncoded = b
+		}
+	}
+}
+
 type testCaseDuration struct {
 	value  duration.Duration
 	expEnc []byte
@@ -1367,6 +1500,27 @@ func TestValueEncodeDecodeTime(t *testing.T) {
 	}
 }
 
+func TestValueEncodeDecodeTimeTZ(t *testing.T) {
+	rng, seed := randutil.NewPseudoRand()
+	for i := 0; i < 1000; i++ {
+		tod := timeofday.FromInt(rng.Int63())
+		offsetSecs := int32(rng.Intn(2*14*60*60+1) - 14*60*60)
+		buf := EncodeTimeTZValue(nil, NoColumnID, tod, offsetSecs)
+		_, x, offset, err := DecodeTimeTZValue(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x != tod || offset != offsetSecs {
+			t.Errorf("seed %d: expected (%s, %d) got (%s, %d)", seed, tod, offsetSecs, x, offset)
+		}
+		if _, l, err := PeekValueLength(buf); err != nil {
+			t.Fatal(err)
+		} else if l != len(buf) {
+			t.Errorf("seed %d: expected length %d got %d", seed, len(buf), l)
+		}
+	}
+}
+
 func TestValueEncodeDecodeDuration(t *testing.T) {
 	rng, seed := randutil.NewPseudoRand()
 	rd := randData{rng}
@@ -1769,6 +1923,7 @@ func TestUpperBoundValueEncodingSize(t *testing.T) {
 		{colID: 0, typ: Decimal, width: 100, size: 69},
 		{colID: 0, typ: Time, size: 19},
 		{colID: 0, typ: Duration, size: 28},
+		{colID: 0, typ: TimeTZ, size: 20},
 		{colID: 0, typ: Bytes, size: -1},
 		{colID: 0, typ: Bytes, width: 100, size: 110},
 
@@ -1817,6 +1972,8 @@ func TestPrettyPrintValueEncoded(t *testing.T) {
 			time.Date(2016, 6, 29, 16, 2, 50, 5, time.UTC)), "2016-06-29T16:02:50.000000005Z"},
 		{EncodeDurationValue(nil, NoColumnID,
 			duration.Duration{Months: 1, Days: 2, Nanos: 3}), "1mon2d3ns"},
+		{EncodeTimeTZValue(nil, NoColumnID,
+			timeofday.New(16, 2, 50, 5), -(5*60*60 + 30*60)), "16:02:50.000005-05:30"},
 		{EncodeBytesValue(nil, NoColumnID, []byte{0x1, 0x2, 0xF, 0xFF}), "01020fff"},
 		{EncodeBytesValue(nil, NoColumnID, []byte("foo")), "foo"},
 		{EncodeIPAddrValue(nil, NoColumnID, ipAddr), ip},
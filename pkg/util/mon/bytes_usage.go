@@ -365,6 +365,14 @@ func MakeUnlimitedMonitor(
 	}
 }
 
+// MaximumBytes returns the maximum number of bytes that were allocated by this
+// monitor at one time since it was started.
+func (mm *BytesMonitor) MaximumBytes() int64 {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return mm.mu.maxAllocated
+}
+
 // EmergencyStop completes a monitoring region, and disables checking
 // that all accounts have been closed.
 func (mm *BytesMonitor) EmergencyStop(ctx context.Context) {
@@ -0,0 +1,98 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeofday
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+)
+
+func TestString(t *testing.T) {
+	expected := "10:11:12.0136"
+	actual := New(10, 11, 12, 13600).String()
+	if actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+	expected = "00:00:00"
+	actual = Min.String()
+	if actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+	expected = "23:59:59.999999"
+	actual = Max.String()
+	if actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestFromAndToTime(t *testing.T) {
+	testData := []struct {
+		s   string
+		exp string
+	}{
+		{"0000-01-01T00:00:00Z", "1970-01-01T00:00:00Z"},
+		{"2017-01-01T12:00:00.5Z", "1970-01-01T12:00:00.5Z"},
+		{"9999-12-31T23:59:59.999999Z", "1970-01-01T23:59:59.999999Z"},
+		{"2017-01-01T12:00:00-05:00", "1970-01-01T12:00:00Z"},
+		{"1969-12-31T03:04:05Z", "1970-01-01T03:04:05Z"},
+	}
+	for _, td := range testData {
+		t.Run(td.s, func(t *testing.T) {
+			fromTime, err := time.Parse(time.RFC3339Nano, td.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := FromTime(fromTime).ToTime().Format(time.RFC3339Nano)
+			if actual != td.exp {
+				t.Errorf("expected %s, got %s", td.exp, actual)
+			}
+		})
+	}
+}
+
+func TestAddAndDifference(t *testing.T) {
+	testData := []struct {
+		t      TimeOfDay
+		d      duration.Duration
+		exp    TimeOfDay
+		expStr string
+	}{
+		{New(12, 0, 0, 0), duration.Duration{Nanos: int64(time.Hour)}, New(13, 0, 0, 0), "13:00:00"},
+		{New(23, 0, 0, 0), duration.Duration{Nanos: int64(2 * time.Hour)}, New(1, 0, 0, 0), "01:00:00"},
+		{New(1, 0, 0, 0), duration.Duration{Nanos: -int64(2 * time.Hour)}, New(23, 0, 0, 0), "23:00:00"},
+		{New(1, 0, 0, 0), duration.Duration{Days: 3, Months: 2}, New(1, 0, 0, 0), "01:00:00"},
+		{Max, duration.Duration{Nanos: int64(time.Microsecond)}, Min, "00:00:00"},
+	}
+	for _, td := range testData {
+		t.Run(td.expStr, func(t *testing.T) {
+			actual := td.t.Add(td.d)
+			if actual != td.exp {
+				t.Errorf("expected %s, got %s", td.exp, actual)
+			}
+			if actual.String() != td.expStr {
+				t.Errorf("expected %s, got %s", td.expStr, actual)
+			}
+		})
+	}
+
+	if d := Difference(New(13, 0, 0, 0), New(12, 30, 0, 0)); d.Nanos != int64(30*time.Minute) {
+		t.Errorf("expected 30m, got %s", d)
+	}
+	if d := Difference(New(12, 30, 0, 0), New(13, 0, 0, 0)); d.Nanos != -int64(30*time.Minute) {
+		t.Errorf("expected -30m, got %s", d)
+	}
+}